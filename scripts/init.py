@@ -118,6 +118,17 @@ spec = 00:30  # 00:00 - 23:59
 [level]
 businessTopoMax = 7
 
+[cache]
+# the custom objects whose instances are cached in redis, separated by comma, e.g. switch,database
+instanceObjects =
+
+[trash]
+# the objects whose instances are soft deleted into the trash bin and can be restored, separated by comma,
+# only custom objects and host are supported, e.g. switch,host
+objects =
+# the days the soft deleted instances are kept before they are purged
+retentionDays = 30
+
 [es]
 full_text_search = $full_text_search
 url=$es_url
@@ -146,6 +157,23 @@ agent_app_url = ${agent_url}/console/?app=bk_agent_setup
 authscheme = $auth_scheme
 [login]
 version=$loginVersion
+# set login version to oidc or ldap and uncomment the matching section to login with them
+#[oidc]
+#issuer = https://idp.example.com
+#client_id = bk_cmdb
+#client_secret =
+#scopes = openid profile email
+#groups_claim = groups
+#group_supplier_accounts = cmdb-users:0
+#group_roles = cmdb-admins:admin
+#[ldap]
+#url = ldap://ldap.example.com:389
+#bind_dn = cn=admin,dc=example,dc=com
+#bind_password =
+#base_dn = ou=users,dc=example,dc=com
+#group_base_dn = ou=groups,dc=example,dc=com
+#group_supplier_accounts = cmdb-users:0
+#group_roles = cmdb-admins:admin
 
 [biz]
 default_app_name=蓝鲸
@@ -0,0 +1,86 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package businessarchive
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+func (b *businessArchive) CreateBusinessArchive(ctx context.Context, header http.Header, archive metadata.BusinessArchive) (metadata.BusinessArchive, errors.CCErrorCoder) {
+	ret := new(metadata.BusinessArchiveResult)
+	err := b.client.Post().
+		WithContext(ctx).
+		Body(archive).
+		SubResourcef("/create/business_archive").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("CreateBusinessArchive failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (b *businessArchive) UpdateBusinessArchive(ctx context.Context, header http.Header, archiveID int64, option metadata.UpdateBusinessArchiveOption) (metadata.BusinessArchive, errors.CCErrorCoder) {
+	ret := new(metadata.BusinessArchiveResult)
+	err := b.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/update/business_archive/%d", archiveID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("UpdateBusinessArchive failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (b *businessArchive) ListBusinessArchive(ctx context.Context, header http.Header, option metadata.ListBusinessArchiveOption) (metadata.MultipleBusinessArchive, errors.CCErrorCoder) {
+	ret := new(metadata.MultipleBusinessArchiveResult)
+	err := b.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/business_archive").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("ListBusinessArchive failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
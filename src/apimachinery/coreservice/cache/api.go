@@ -30,6 +30,9 @@ type Interface interface {
 	SearchSet(ctx context.Context, h http.Header, setID int64) (jsonString string, err error)
 	SearchModule(ctx context.Context, h http.Header, moduleID int64) (jsonString string, err error)
 	SearchCustomLayer(ctx context.Context, h http.Header, objID string, instID int64) (jsonString string, err error)
+	SearchInstWithInstID(ctx context.Context, h http.Header, opt *metadata.SearchInstWithIDOption) (jsonString string, err error)
+	SearchInstWithUniqueKey(ctx context.Context, h http.Header, opt *metadata.SearchInstWithUniqueKeyOption) (jsonString string, err error)
+	CheckCacheConsistency(ctx context.Context, h http.Header, opt *metadata.CheckCacheOption) ([]metadata.CheckCacheResult, error)
 }
 
 func NewCacheClient(client rest.ClientInterface) Interface {
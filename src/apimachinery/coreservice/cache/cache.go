@@ -179,3 +179,72 @@ func (b *baseCache) SearchCustomLayer(ctx context.Context, h http.Header, objID
 	}
 	return resp.Data, nil
 }
+
+func (b *baseCache) SearchInstWithInstID(ctx context.Context, h http.Header, opt *metadata.SearchInstWithIDOption) (jsonString string, err error) {
+
+	resp, err := b.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/find/cache/instance/with_inst_id").
+		WithHeaders(h).
+		Do().
+		IntoJsonString()
+
+	if err != nil {
+		return "", errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return "", errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp.Data, nil
+}
+
+func (b *baseCache) SearchInstWithUniqueKey(ctx context.Context, h http.Header, opt *metadata.SearchInstWithUniqueKeyOption) (jsonString string, err error) {
+
+	resp, err := b.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/find/cache/instance/with_unique_key").
+		WithHeaders(h).
+		Do().
+		IntoJsonString()
+
+	if err != nil {
+		return "", errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return "", errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp.Data, nil
+}
+
+func (b *baseCache) CheckCacheConsistency(ctx context.Context, h http.Header, opt *metadata.CheckCacheOption) ([]metadata.CheckCacheResult, error) {
+	type CheckResult struct {
+		metadata.BaseResp `json:",inline"`
+		Data              []metadata.CheckCacheResult `json:"data"`
+	}
+
+	resp := new(CheckResult)
+
+	err := b.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/check/cache/consistency").
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return nil, errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp.Data, nil
+}
@@ -0,0 +1,106 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package changerequest
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+func (c *changeRequest) CreateChangeRequest(ctx context.Context, header http.Header, option metadata.CreateChangeRequestOption) (metadata.ChangeRequest, errors.CCErrorCoder) {
+	ret := new(metadata.ChangeRequestResult)
+	err := c.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/create/change_request").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("CreateChangeRequest failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (c *changeRequest) GetChangeRequest(ctx context.Context, header http.Header, requestID int64) (metadata.ChangeRequest, errors.CCErrorCoder) {
+	ret := new(metadata.ChangeRequestResult)
+	err := c.client.Get().
+		WithContext(ctx).
+		SubResourcef("/find/change_request/%d", requestID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("GetChangeRequest failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (c *changeRequest) UpdateChangeRequestStatus(ctx context.Context, header http.Header, requestID int64, option metadata.UpdateChangeRequestStatusOption) (metadata.ChangeRequest, errors.CCErrorCoder) {
+	ret := new(metadata.ChangeRequestResult)
+	err := c.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/update/change_request/%d/status", requestID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("UpdateChangeRequestStatus failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (c *changeRequest) ListChangeRequest(ctx context.Context, header http.Header, option metadata.ListChangeRequestOption) (metadata.MultipleChangeRequest, errors.CCErrorCoder) {
+	ret := new(metadata.MultipleChangeRequestResult)
+	err := c.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/change_request").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("ListChangeRequest failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
@@ -0,0 +1,37 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package changerequest
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+type ChangeRequestInterface interface {
+	CreateChangeRequest(ctx context.Context, header http.Header, option metadata.CreateChangeRequestOption) (metadata.ChangeRequest, errors.CCErrorCoder)
+	GetChangeRequest(ctx context.Context, header http.Header, requestID int64) (metadata.ChangeRequest, errors.CCErrorCoder)
+	UpdateChangeRequestStatus(ctx context.Context, header http.Header, requestID int64, option metadata.UpdateChangeRequestStatusOption) (metadata.ChangeRequest, errors.CCErrorCoder)
+	ListChangeRequest(ctx context.Context, header http.Header, option metadata.ListChangeRequestOption) (metadata.MultipleChangeRequest, errors.CCErrorCoder)
+}
+
+func NewChangeRequestClient(client rest.ClientInterface) ChangeRequestInterface {
+	return &changeRequest{client: client}
+}
+
+type changeRequest struct {
+	client rest.ClientInterface
+}
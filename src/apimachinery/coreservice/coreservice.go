@@ -17,8 +17,11 @@ import (
 
 	"configcenter/src/apimachinery/coreservice/association"
 	"configcenter/src/apimachinery/coreservice/auditlog"
+	"configcenter/src/apimachinery/coreservice/businessarchive"
 	"configcenter/src/apimachinery/coreservice/count"
 	"configcenter/src/apimachinery/coreservice/cache"
+	"configcenter/src/apimachinery/coreservice/changerequest"
+	"configcenter/src/apimachinery/coreservice/export"
 	"configcenter/src/apimachinery/coreservice/host"
 	"configcenter/src/apimachinery/coreservice/hostapplyrule"
 	"configcenter/src/apimachinery/coreservice/instance"
@@ -26,12 +29,16 @@ import (
 	"configcenter/src/apimachinery/coreservice/mainline"
 	"configcenter/src/apimachinery/coreservice/model"
 	"configcenter/src/apimachinery/coreservice/operation"
+	"configcenter/src/apimachinery/coreservice/permissionrole"
 	"configcenter/src/apimachinery/coreservice/process"
+	"configcenter/src/apimachinery/coreservice/quota"
+	"configcenter/src/apimachinery/coreservice/serviceaccount"
 	"configcenter/src/apimachinery/coreservice/settemplate"
 	"configcenter/src/apimachinery/coreservice/synchronize"
 	ccSystem "configcenter/src/apimachinery/coreservice/system"
 	"configcenter/src/apimachinery/coreservice/topographics"
 	"configcenter/src/apimachinery/coreservice/transaction"
+	"configcenter/src/apimachinery/coreservice/trash"
 	"configcenter/src/apimachinery/rest"
 	"configcenter/src/apimachinery/util"
 )
@@ -50,6 +57,13 @@ type CoreServiceClientInterface interface {
 	TopoGraphics() topographics.TopoGraphicsInterface
 	SetTemplate() settemplate.SetTemplateInterface
 	HostApplyRule() hostapplyrule.HostApplyRuleInterface
+	PermissionRole() permissionrole.PermissionRoleInterface
+	ChangeRequest() changerequest.ChangeRequestInterface
+	BusinessArchive() businessarchive.BusinessArchiveInterface
+	ServiceAccount() serviceaccount.ServiceAccountInterface
+	Trash() trash.TrashInterface
+	Quota() quota.QuotaInterface
+	Export() export.ExportInterface
 	System() ccSystem.SystemClientInterface
 	Txn() transaction.Interface
 	Count() count.CountClientInterface
@@ -124,6 +138,34 @@ func (c *coreService) HostApplyRule() hostapplyrule.HostApplyRuleInterface {
 	return hostapplyrule.NewHostApplyRuleClient(c.restCli)
 }
 
+func (c *coreService) PermissionRole() permissionrole.PermissionRoleInterface {
+	return permissionrole.NewPermissionRoleClient(c.restCli)
+}
+
+func (c *coreService) ChangeRequest() changerequest.ChangeRequestInterface {
+	return changerequest.NewChangeRequestClient(c.restCli)
+}
+
+func (c *coreService) BusinessArchive() businessarchive.BusinessArchiveInterface {
+	return businessarchive.NewBusinessArchiveClient(c.restCli)
+}
+
+func (c *coreService) ServiceAccount() serviceaccount.ServiceAccountInterface {
+	return serviceaccount.NewServiceAccountClient(c.restCli)
+}
+
+func (c *coreService) Trash() trash.TrashInterface {
+	return trash.NewTrashClient(c.restCli)
+}
+
+func (c *coreService) Quota() quota.QuotaInterface {
+	return quota.NewQuotaClient(c.restCli)
+}
+
+func (c *coreService) Export() export.ExportInterface {
+	return export.NewExportClient(c.restCli)
+}
+
 func (c *coreService) Txn() transaction.Interface {
 	return transaction.NewTxn(c.restCli)
 }
@@ -0,0 +1,39 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// ExportInterface exports the resources as the streams of new line delimited json, the caller must close
+// the returned stream.
+type ExportInterface interface {
+	ExportInstances(ctx context.Context, header http.Header, objID string, option metadata.ExportOption) (io.ReadCloser, errors.CCErrorCoder)
+	ExportHosts(ctx context.Context, header http.Header, option metadata.ExportOption) (io.ReadCloser, errors.CCErrorCoder)
+	ExportInstanceAssociations(ctx context.Context, header http.Header, objID string, option metadata.ExportOption) (io.ReadCloser, errors.CCErrorCoder)
+}
+
+func NewExportClient(client rest.ClientInterface) ExportInterface {
+	return &export{client: client}
+}
+
+type export struct {
+	client rest.ClientInterface
+}
@@ -0,0 +1,86 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/json"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+func (e *export) ExportInstances(ctx context.Context, header http.Header, objID string, option metadata.ExportOption) (io.ReadCloser, errors.CCErrorCoder) {
+	req := e.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/export/model/%s/instances", objID).
+		WithHeaders(header)
+	return stream(req, header)
+}
+
+func (e *export) ExportHosts(ctx context.Context, header http.Header, option metadata.ExportOption) (io.ReadCloser, errors.CCErrorCoder) {
+	req := e.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/export/hosts").
+		WithHeaders(header)
+	return stream(req, header)
+}
+
+func (e *export) ExportInstanceAssociations(ctx context.Context, header http.Header, objID string, option metadata.ExportOption) (io.ReadCloser, errors.CCErrorCoder) {
+	req := e.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/export/model/%s/instance_associations", objID).
+		WithHeaders(header)
+	return stream(req, header)
+}
+
+// stream returns the response body if the export is started, otherwise the export fails before the stream is
+// responded, and the error is responded as a json response.
+func stream(req *rest.Request, header http.Header) (io.ReadCloser, errors.CCErrorCoder) {
+	rid := util.GetHTTPCCRequestID(header)
+	resp, err := req.Stream()
+	if err != nil {
+		blog.Errorf("export failed, http request failed, err: %+v, rid: %s", err, rid)
+		return nil, errors.CCHttpError
+	}
+	if resp.StatusCode == http.StatusOK && strings.HasPrefix(resp.Header.Get("Content-Type"), metadata.ExportContentType) {
+		return resp.Body, nil
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		blog.Errorf("export failed, read response failed, err: %+v, rid: %s", err, rid)
+		return nil, errors.CCHttpError
+	}
+	ret := new(metadata.BaseResp)
+	if err := json.Unmarshal(body, ret); err != nil {
+		blog.Errorf("export failed, status: %s, response: %s, rid: %s", resp.Status, body, rid)
+		return nil, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return nil, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+	blog.Errorf("export failed, got unexpected response: %s, rid: %s", body, rid)
+	return nil, errors.CCHttpError
+}
@@ -245,3 +245,27 @@ func (p *hostApplyRule) RunHostApplyOnHosts(ctx context.Context, header http.Hea
 	}
 	return ret.Data, nil
 }
+
+func (p *hostApplyRule) ListHostApplyDrift(ctx context.Context, header http.Header, bizID int64, option metadata.ListHostApplyDriftOption) (metadata.MultipleHostApplyDrift, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp
+		Data metadata.MultipleHostApplyDrift `json:"data"`
+	}{}
+
+	err := p.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/host_apply_drift/bk_biz_id/%d", bizID).
+		WithHeaders(header).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("ListHostApplyDrift failed, http request failed, err: %+v", err)
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+	return ret.Data, nil
+}
@@ -16,7 +16,10 @@ import (
 	"context"
 	"net/http"
 
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
 )
 
 func (inst *instance) CreateInstance(ctx context.Context, h http.Header, objID string, input *metadata.CreateModelInstance) (resp *metadata.CreatedOneOptionResult, err error) {
@@ -116,3 +119,44 @@ func (inst *instance) DeleteInstanceCascade(ctx context.Context, h http.Header,
 		Into(resp)
 	return
 }
+
+func (inst *instance) AggregateInstance(ctx context.Context, h http.Header, objID string, option metadata.AggregateOption) (*metadata.AggregateResult, errors.CCErrorCoder) {
+	ret := new(metadata.AggregateResponse)
+	err := inst.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/read/model/%s/instances/aggregation", objID).
+		WithHeaders(h).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("AggregateInstance failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(h))
+		return nil, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return nil, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return &ret.Data, nil
+}
+
+func (inst *instance) RefreshExpressionFields(ctx context.Context, h http.Header, objID string) errors.CCErrorCoder {
+	ret := new(metadata.BaseResp)
+	err := inst.client.Post().
+		WithContext(ctx).
+		SubResourcef("/update/model/%s/instance/expression/refresh", objID).
+		WithHeaders(h).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("RefreshExpressionFields failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(h))
+		return errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 	"net/http"
 
 	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
 	"configcenter/src/common/metadata"
 )
 
@@ -28,6 +29,8 @@ type InstanceClientInterface interface {
 	ReadInstance(ctx context.Context, h http.Header, objID string, input *metadata.QueryCondition) (resp *metadata.QueryConditionResult, err error)
 	DeleteInstance(ctx context.Context, h http.Header, objID string, input *metadata.DeleteOption) (resp *metadata.DeletedOptionResult, err error)
 	DeleteInstanceCascade(ctx context.Context, h http.Header, objID string, input *metadata.DeleteOption) (resp *metadata.DeletedOptionResult, err error)
+	AggregateInstance(ctx context.Context, h http.Header, objID string, option metadata.AggregateOption) (*metadata.AggregateResult, errors.CCErrorCoder)
+	RefreshExpressionFields(ctx context.Context, h http.Header, objID string) errors.CCErrorCoder
 }
 
 func NewInstanceClientInterface(client rest.ClientInterface) InstanceClientInterface {
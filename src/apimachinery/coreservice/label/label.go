@@ -24,6 +24,7 @@ import (
 type LabelInterface interface {
 	AddLabel(ctx context.Context, h http.Header, tableName string, option selector.LabelAddOption) errors.CCErrorCoder
 	RemoveLabel(ctx context.Context, h http.Header, tableName string, option selector.LabelRemoveOption) errors.CCErrorCoder
+	AggregateLabels(ctx context.Context, h http.Header, tableName string, condition map[string]interface{}) (selector.LabelAggregation, errors.CCErrorCoder)
 }
 
 func NewLabelInterfaceClient(client rest.ClientInterface) LabelInterface {
@@ -0,0 +1,107 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package permissionrole
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+func (p *permissionRole) CreatePermissionRole(ctx context.Context, header http.Header, option metadata.CreatePermissionRoleOption) (metadata.PermissionRole, errors.CCErrorCoder) {
+	ret := new(metadata.PermissionRoleResult)
+	err := p.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/create/permission_role").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("CreatePermissionRole failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (p *permissionRole) UpdatePermissionRole(ctx context.Context, header http.Header, roleID int64, option metadata.UpdatePermissionRoleOption) (metadata.PermissionRole, errors.CCErrorCoder) {
+	ret := new(metadata.PermissionRoleResult)
+	err := p.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/update/permission_role/%d", roleID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("UpdatePermissionRole failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (p *permissionRole) DeletePermissionRole(ctx context.Context, header http.Header, option metadata.DeletePermissionRoleOption) errors.CCErrorCoder {
+	ret := new(metadata.BaseResp)
+	err := p.client.Delete().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/deletemany/permission_role").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("DeletePermissionRole failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return nil
+}
+
+func (p *permissionRole) ListPermissionRole(ctx context.Context, header http.Header, option metadata.ListPermissionRoleOption) (metadata.MultiplePermissionRole, errors.CCErrorCoder) {
+	ret := new(metadata.MultiplePermissionRoleResult)
+	err := p.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/permission_role").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("ListPermissionRole failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
@@ -0,0 +1,37 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package permissionrole
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+type PermissionRoleInterface interface {
+	CreatePermissionRole(ctx context.Context, header http.Header, option metadata.CreatePermissionRoleOption) (metadata.PermissionRole, errors.CCErrorCoder)
+	UpdatePermissionRole(ctx context.Context, header http.Header, roleID int64, option metadata.UpdatePermissionRoleOption) (metadata.PermissionRole, errors.CCErrorCoder)
+	DeletePermissionRole(ctx context.Context, header http.Header, option metadata.DeletePermissionRoleOption) errors.CCErrorCoder
+	ListPermissionRole(ctx context.Context, header http.Header, option metadata.ListPermissionRoleOption) (metadata.MultiplePermissionRole, errors.CCErrorCoder)
+}
+
+func NewPermissionRoleClient(client rest.ClientInterface) PermissionRoleInterface {
+	return &permissionRole{client: client}
+}
+
+type permissionRole struct {
+	client rest.ClientInterface
+}
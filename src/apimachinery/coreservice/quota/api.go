@@ -0,0 +1,36 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package quota
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+type QuotaInterface interface {
+	SetQuota(ctx context.Context, header http.Header, option metadata.SetQuotaOption) (*metadata.Quota, errors.CCErrorCoder)
+	DeleteQuota(ctx context.Context, header http.Header, key metadata.QuotaKey) errors.CCErrorCoder
+	ListQuotaUsage(ctx context.Context, header http.Header, option metadata.ListQuotaUsageOption) ([]metadata.QuotaUsage, errors.CCErrorCoder)
+}
+
+func NewQuotaClient(client rest.ClientInterface) QuotaInterface {
+	return &quota{client: client}
+}
+
+type quota struct {
+	client rest.ClientInterface
+}
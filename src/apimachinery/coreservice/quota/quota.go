@@ -0,0 +1,86 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package quota
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+func (q *quota) SetQuota(ctx context.Context, header http.Header, option metadata.SetQuotaOption) (*metadata.Quota, errors.CCErrorCoder) {
+	ret := new(metadata.QuotaResult)
+	err := q.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/update/quota").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("SetQuota failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return nil, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return nil, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return &ret.Data, nil
+}
+
+func (q *quota) DeleteQuota(ctx context.Context, header http.Header, key metadata.QuotaKey) errors.CCErrorCoder {
+	ret := new(metadata.BaseResp)
+	err := q.client.Delete().
+		WithContext(ctx).
+		Body(key).
+		SubResourcef("/delete/quota").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("DeleteQuota failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return nil
+}
+
+func (q *quota) ListQuotaUsage(ctx context.Context, header http.Header, option metadata.ListQuotaUsageOption) ([]metadata.QuotaUsage, errors.CCErrorCoder) {
+	ret := new(metadata.QuotaUsageResult)
+	err := q.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/quota/usage").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("ListQuotaUsage failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return nil, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return nil, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
@@ -0,0 +1,189 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serviceaccount
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+func (s *serviceAccount) CreateServiceAccount(ctx context.Context, header http.Header, option metadata.CreateServiceAccountOption) (metadata.ServiceAccount, errors.CCErrorCoder) {
+	ret := new(metadata.ServiceAccountResult)
+	err := s.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/create/service_account").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("CreateServiceAccount failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (s *serviceAccount) UpdateServiceAccount(ctx context.Context, header http.Header, accountID int64, option metadata.UpdateServiceAccountOption) (metadata.ServiceAccount, errors.CCErrorCoder) {
+	ret := new(metadata.ServiceAccountResult)
+	err := s.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/update/service_account/%d", accountID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("UpdateServiceAccount failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (s *serviceAccount) DeleteServiceAccount(ctx context.Context, header http.Header, accountID int64) errors.CCErrorCoder {
+	ret := new(metadata.BaseResp)
+	err := s.client.Delete().
+		WithContext(ctx).
+		SubResourcef("/delete/service_account/%d", accountID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("DeleteServiceAccount failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return nil
+}
+
+func (s *serviceAccount) ListServiceAccount(ctx context.Context, header http.Header, option metadata.ListServiceAccountOption) (metadata.MultipleServiceAccount, errors.CCErrorCoder) {
+	ret := new(metadata.MultipleServiceAccountResult)
+	err := s.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/service_account").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("ListServiceAccount failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (s *serviceAccount) CreateAPIToken(ctx context.Context, header http.Header, accountID int64, option metadata.CreateAPITokenOption) (metadata.CreateAPITokenResult, errors.CCErrorCoder) {
+	ret := new(metadata.CreateAPITokenResponse)
+	err := s.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/create/service_account/%d/api_token", accountID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("CreateAPIToken failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (s *serviceAccount) RevokeAPIToken(ctx context.Context, header http.Header, accountID int64, tokenID int64) errors.CCErrorCoder {
+	ret := new(metadata.BaseResp)
+	err := s.client.Put().
+		WithContext(ctx).
+		SubResourcef("/update/service_account/%d/api_token/%d/revoke", accountID, tokenID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("RevokeAPIToken failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return nil
+}
+
+func (s *serviceAccount) ListAPIToken(ctx context.Context, header http.Header, option metadata.ListAPITokenOption) (metadata.MultipleAPIToken, errors.CCErrorCoder) {
+	ret := new(metadata.MultipleAPITokenResult)
+	err := s.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/api_token").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("ListAPIToken failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (s *serviceAccount) ValidateAPIToken(ctx context.Context, header http.Header, option metadata.ValidateAPITokenOption) (metadata.ValidAPIToken, errors.CCErrorCoder) {
+	ret := new(metadata.ValidAPITokenResult)
+	err := s.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/find/api_token/validate").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("ValidateAPIToken failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
@@ -0,0 +1,41 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serviceaccount
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+type ServiceAccountInterface interface {
+	CreateServiceAccount(ctx context.Context, header http.Header, option metadata.CreateServiceAccountOption) (metadata.ServiceAccount, errors.CCErrorCoder)
+	UpdateServiceAccount(ctx context.Context, header http.Header, accountID int64, option metadata.UpdateServiceAccountOption) (metadata.ServiceAccount, errors.CCErrorCoder)
+	DeleteServiceAccount(ctx context.Context, header http.Header, accountID int64) errors.CCErrorCoder
+	ListServiceAccount(ctx context.Context, header http.Header, option metadata.ListServiceAccountOption) (metadata.MultipleServiceAccount, errors.CCErrorCoder)
+	CreateAPIToken(ctx context.Context, header http.Header, accountID int64, option metadata.CreateAPITokenOption) (metadata.CreateAPITokenResult, errors.CCErrorCoder)
+	RevokeAPIToken(ctx context.Context, header http.Header, accountID int64, tokenID int64) errors.CCErrorCoder
+	ListAPIToken(ctx context.Context, header http.Header, option metadata.ListAPITokenOption) (metadata.MultipleAPIToken, errors.CCErrorCoder)
+	ValidateAPIToken(ctx context.Context, header http.Header, option metadata.ValidateAPITokenOption) (metadata.ValidAPIToken, errors.CCErrorCoder)
+}
+
+func NewServiceAccountClient(client rest.ClientInterface) ServiceAccountInterface {
+	return &serviceAccount{client: client}
+}
+
+type serviceAccount struct {
+	client rest.ClientInterface
+}
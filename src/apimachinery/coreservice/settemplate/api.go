@@ -333,3 +333,52 @@ func (p *setTemplate) ListSetTemplateSyncHistory(ctx context.Context, header htt
 
 	return ret.Data, nil
 }
+
+func (p *setTemplate) UpdateSetTemplateSyncPolicy(ctx context.Context, header http.Header, bizID int64, setTemplateID int64, policy metadata.SetTemplateSyncPolicy) (metadata.SetTemplate, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp
+		Data metadata.SetTemplate `json:"data"`
+	}{}
+	subPath := "/update/topo/set_template_sync_policy/%d/bk_biz_id/%d"
+
+	err := p.client.Put().
+		WithContext(ctx).
+		Body(policy).
+		SubResourcef(subPath, setTemplateID, bizID).
+		WithHeaders(header).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, http request failed, err: %+v", err)
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (p *setTemplate) ListSetTemplateSyncPolicy(ctx context.Context, header http.Header, option metadata.ListSetTemplateSyncPolicyOption) ([]metadata.SetTemplate, errors.CCErrorCoder) {
+	ret := metadata.ListSetTemplateSyncPolicyResult{}
+	subPath := "/findmany/topo/set_template_sync_policy"
+
+	err := p.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("ListSetTemplateSyncPolicy failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return nil, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
@@ -34,6 +34,8 @@ type SetTemplateInterface interface {
 	ListSetTemplateSyncStatus(ctx context.Context, header http.Header, bizID int64, option metadata.ListSetTemplateSyncStatusOption) (metadata.MultipleSetTemplateSyncStatus, errors.CCErrorCoder)
 	DeleteSetTemplateSyncStatus(ctx context.Context, header http.Header, bizID int64, setIDs []int64) errors.CCErrorCoder
 	ListSetTemplateSyncHistory(ctx context.Context, header http.Header, bizID int64, option metadata.ListSetTemplateSyncStatusOption) (metadata.MultipleSetTemplateSyncStatus, errors.CCErrorCoder)
+	UpdateSetTemplateSyncPolicy(ctx context.Context, header http.Header, bizID int64, setTemplateID int64, policy metadata.SetTemplateSyncPolicy) (metadata.SetTemplate, errors.CCErrorCoder)
+	ListSetTemplateSyncPolicy(ctx context.Context, header http.Header, option metadata.ListSetTemplateSyncPolicyOption) ([]metadata.SetTemplate, errors.CCErrorCoder)
 }
 
 func NewSetTemplateInterfaceClient(client rest.ClientInterface) SetTemplateInterface {
@@ -0,0 +1,86 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trash
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+func (t *trash) ListTrash(ctx context.Context, header http.Header, objID string, option metadata.ListTrashOption) (metadata.MultipleTrashItem, errors.CCErrorCoder) {
+	ret := new(metadata.MultipleTrashItemResult)
+	err := t.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/trash/object/%s", objID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("ListTrash failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (t *trash) RestoreTrash(ctx context.Context, header http.Header, objID string, option metadata.RestoreTrashOption) (metadata.RestoreTrashResult, errors.CCErrorCoder) {
+	ret := new(metadata.RestoreTrashResponse)
+	err := t.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/update/trash/object/%s/restore", objID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("RestoreTrash failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (t *trash) DeleteTrash(ctx context.Context, header http.Header, objID string, option metadata.RestoreTrashOption) errors.CCErrorCoder {
+	ret := new(metadata.BaseResp)
+	err := t.client.Delete().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/deletemany/trash/object/%s", objID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("DeleteTrash failed, http request failed, err: %+v, rid: %s", err, util.GetHTTPCCRequestID(header))
+		return errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return nil
+}
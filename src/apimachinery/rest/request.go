@@ -347,6 +347,59 @@ func (r *Request) Do() *Result {
 	return result
 }
 
+// Stream sends the request and returns the response without reading the body, so that the large response can
+// be read as a stream, the caller must close the response body. the request is not retried once it's sent, as
+// the response may have been partially consumed by the caller.
+func (r *Request) Stream() (*http.Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	client := r.capability.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	hosts, err := r.capability.Discover.GetServers()
+	if err != nil {
+		return nil, err
+	}
+
+	rid := commonUtil.ExtractRequestIDFromContext(r.ctx)
+	if rid == "" {
+		rid = commonUtil.GetHTTPCCRequestID(r.headers)
+	}
+	for _, host := range hosts {
+		url := host + r.WrapURL().String()
+		req, err := http.NewRequest(string(r.verb), url, bytes.NewReader(r.body))
+		if err != nil {
+			return nil, err
+		}
+		if r.ctx != nil {
+			req = req.WithContext(r.ctx)
+		}
+
+		req.Header = commonUtil.CloneHeader(r.headers)
+		if len(req.Header) == 0 {
+			req.Header = make(http.Header)
+		}
+		req.Header.Del("Accept-Encoding")
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			blog.Errorf("[apimachinery][stream] %s %s with body %s, but %v, rid: %s", string(r.verb), url, r.body, err, rid)
+			if isConnectionReset(err) {
+				continue
+			}
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	return nil, errors.New("no available server")
+}
+
 const maxLatency = 100 * time.Millisecond
 
 func (r *Request) tryThrottle(url string) {
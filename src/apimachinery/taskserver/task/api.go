@@ -24,6 +24,12 @@ type TaskClientInterface interface {
 	// Create  新加任务， name 任务名，flag:任务标识，留给业务方做识别任务, data 每一项任务需要的参数
 	Create(ctx context.Context, header http.Header, name, flag string, data []interface{}) (resp *metadata.CreateTaskResponse, err error)
 
+	// CreateTask 新加任务, 可以指定任务的优先级以及依赖的任务
+	CreateTask(ctx context.Context, header http.Header, input *metadata.CreateTaskRequest) (resp *metadata.CreateTaskResponse, err error)
+
+	// Cancel 取消任务, 尚未执行的子任务不再执行
+	Cancel(ctx context.Context, header http.Header, taskID string) (resp *metadata.Response, err error)
+
 	ListTask(ctx context.Context, header http.Header, name string, data *metadata.ListAPITaskRequest) (resp *metadata.ListAPITaskResponse, err error)
 
 	TaskDetail(ctx context.Context, header http.Header, taskID string) (resp *metadata.TaskDetailResponse, err error)
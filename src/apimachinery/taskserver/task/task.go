@@ -21,13 +21,18 @@ import (
 
 // Create  新加任务， name 任务名，flag:任务标识，留给业务方做识别任务, data 每一项任务需要的参数
 func (t *task) Create(ctx context.Context, header http.Header, name, flag string, data []interface{}) (resp *metadata.CreateTaskResponse, err error) {
-	resp = new(metadata.CreateTaskResponse)
-	subPath := "/task/create"
-	body := metadata.CreateTaskRequest{
+	body := &metadata.CreateTaskRequest{
 		Name: name,
 		Flag: flag,
 		Data: data,
 	}
+	return t.CreateTask(ctx, header, body)
+}
+
+// CreateTask 新加任务, 可以指定任务的优先级以及依赖的任务
+func (t *task) CreateTask(ctx context.Context, header http.Header, body *metadata.CreateTaskRequest) (resp *metadata.CreateTaskResponse, err error) {
+	resp = new(metadata.CreateTaskResponse)
+	subPath := "/task/create"
 
 	err = t.client.Post().
 		WithContext(ctx).
@@ -67,6 +72,21 @@ func (t *task) TaskDetail(ctx context.Context, header http.Header, taskID string
 	return
 }
 
+// Cancel 取消任务, 尚未执行的子任务不再执行
+func (t *task) Cancel(ctx context.Context, header http.Header, taskID string) (resp *metadata.Response, err error) {
+	resp = new(metadata.Response)
+	subPath := "/task/cancel/id/%s"
+
+	err = t.client.Put().
+		WithContext(ctx).
+		Body(nil).
+		SubResourcef(subPath, taskID).
+		WithHeaders(header).
+		Do().
+		Into(resp)
+	return
+}
+
 func (t *task) TaskStatusToSuccess(ctx context.Context, header http.Header, taskID, subTaskID string) (resp *metadata.Response, err error) {
 	resp = new(metadata.Response)
 	subPath := "/task/set/status/sucess/id/%s/sub_id/%s"
@@ -101,6 +121,7 @@ func (t *task) TaskStatusToFailure(ctx context.Context, header http.Header, task
  http.MethodPost, Path: "/task/create", Handler: s.CreateTask})
  http.MethodPost, Path: "/task/findmany/list/{name}", Handler: s.ListTask})
  http.MethodPost, Path: "/task/findone/detail/{task_id}", Handler: s.DetailTask})
+ http.MethodPut, Path: "/task/cancel/id/{task_id}", Handler: s.CancelTask})
  http.MethodPut, Path: "/task/set/status/sucess/id/{task_id}/sub_id/{sub_task_id}", Handler: s.StatusToSuccess})
  http.MethodPut, Path: "/task/set/status/failure/id/{task_id}/sub_id/{sub_task_id}", Handler: s.StatusToFailure})
 
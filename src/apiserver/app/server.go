@@ -88,7 +88,14 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		return err
 	}
 
-	svc.SetConfig(engine, client, engine.Discovery(), authorize, cache, limiter)
+	changeRequestConf, err := service.ParseChangeRequestConfig(apiSvr.Config)
+	if err != nil {
+		return fmt.Errorf("parse change request config failed, err: %v", err)
+	}
+
+	tenantMetric := service.NewTenantRequestMetric(apiSvr.Config, engine.Metric().Registry())
+
+	svc.SetConfig(engine, client, engine.Discovery(), authorize, cache, limiter, changeRequestConf, tenantMetric)
 
 	ctnr := restful.NewContainer()
 	ctnr.Router(restful.CurlyRouter{})
@@ -0,0 +1,422 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"configcenter/src/auth/authcenter/permit"
+	"configcenter/src/auth/parser"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/rdapi"
+	"configcenter/src/common/util"
+
+	"github.com/emicklei/go-restful"
+)
+
+const (
+	serviceAccountRootPath = rootPath + "/service_account"
+	bearerAuthPrefix       = "Bearer "
+)
+
+// getAPIToken returns the api token in the request's authorization header, other kinds of credentials
+// in the header are ignored.
+func getAPIToken(header http.Header) string {
+	authorization := header.Get(common.BKHTTPAuthorization)
+	if !strings.HasPrefix(authorization, bearerAuthPrefix) {
+		return ""
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(authorization, bearerAuthPrefix))
+	if !strings.HasPrefix(token, metadata.APITokenPrefix) {
+		return ""
+	}
+	return token
+}
+
+// apiTokenFilter authenticate the requests with api tokens as the token's service account, the request's
+// supplier account, businesses and read/write scope must be allowed by the token. it runs before the other
+// filters, so that the user header is set to the service account before it's checked and authorized.
+func (s *service) apiTokenFilter(errFunc func() errors.CCErrorIf) func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
+	return func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
+		// the header is only set by api server when the request is authenticated by an api token.
+		req.Request.Header.Del(common.BKHTTPServiceAccountID)
+
+		token := getAPIToken(req.Request.Header)
+		if len(token) == 0 {
+			fchain.ProcessFilter(req, resp)
+			return
+		}
+
+		rdapi.GenerateHttpHeaderRID(req.Request, resp.ResponseWriter)
+		rid := util.GetHTTPCCRequestID(req.Request.Header)
+		defErr := errFunc().CreateDefaultCCErrorIf(util.GetLanguage(req.Request.Header))
+
+		header := make(http.Header)
+		header.Set(common.BKHTTPCCRequestID, rid)
+		header.Set(common.BKHTTPLanguage, util.GetLanguage(req.Request.Header))
+		header.Set(common.BKHTTPHeaderUser, common.CCSystemOperatorUserName)
+		// the token may belong to any supplier account, validate it as the system which is not scoped to a tenant.
+		header.Set(common.BKHTTPOwnerID, common.BKSuperOwnerID)
+		option := metadata.ValidateAPITokenOption{TokenHash: metadata.HashAPIToken(token)}
+		valid, ccErr := s.engine.CoreAPI.CoreService().ServiceAccount().ValidateAPIToken(req.Request.Context(), header, option)
+		if ccErr != nil {
+			blog.Errorf("validate api token %s failed, err: %v, rid: %s", metadata.APITokenDisplayPrefix(token), ccErr, rid)
+			status := http.StatusUnauthorized
+			if ccErr.GetCode() != common.CCErrCommAPITokenInvalid {
+				status = http.StatusInternalServerError
+			}
+			resp.WriteHeaderAndJson(status, metadata.BaseResp{Code: ccErr.GetCode(), ErrMsg: ccErr.Error()}, restful.MIME_JSON)
+			return
+		}
+
+		util.SetOwnerIDAndAccount(req)
+		supplierAccount := util.GetOwnerID(req.Request.Header)
+		if len(supplierAccount) == 0 && len(valid.Token.SupplierAccounts) == 1 {
+			supplierAccount = valid.Token.SupplierAccounts[0]
+		}
+		if !valid.Token.AllowSupplierAccount(supplierAccount) {
+			s.denyAPIToken(resp, defErr, valid, fmt.Sprintf("use supplier account %s", supplierAccount), rid)
+			return
+		}
+		req.Request.Header.Set(common.BKHTTPOwnerID, supplierAccount)
+		req.Request.Header.Set(common.BKHTTPHeaderUser, valid.ServiceAccount.UserName())
+		req.Request.Header.Set(common.BKHTTPServiceAccountID, strconv.FormatInt(valid.ServiceAccount.ID, 10))
+
+		if denied, allowed := s.checkAPITokenScope(req, valid.Token); !allowed {
+			s.denyAPIToken(resp, defErr, valid, denied, rid)
+			return
+		}
+
+		blog.V(5).Infof("request %s %s is authenticated as service account %s by api token %d, rid: %s",
+			req.Request.Method, req.Request.URL.Path, valid.ServiceAccount.Name, valid.Token.ID, rid)
+		fchain.ProcessFilter(req, resp)
+	}
+}
+
+func (s *service) denyAPIToken(resp *restful.Response, defErr errors.DefaultCCErrorIf, valid metadata.ValidAPIToken, denied, rid string) {
+	blog.Errorf("api token %d of service account %s has no permission to %s, rid: %s", valid.Token.ID, valid.ServiceAccount.Name, denied, rid)
+	rsp := metadata.BaseResp{
+		Code:   common.CCErrCommAPITokenScopeDenied,
+		ErrMsg: defErr.Errorf(common.CCErrCommAPITokenScopeDenied, denied).Error(),
+	}
+	resp.WriteHeaderAndJson(http.StatusForbidden, rsp, restful.MIME_JSON)
+}
+
+// checkAPITokenScope check if the request is allowed by the token's scopes and businesses, returns what
+// is denied if it's not allowed. a request only finding resources needs the read scope, others need the
+// write scope. the token restricted to businesses can only change the resources in the businesses, and
+// the requests whose resources can not be parsed are denied for it.
+func (s *service) checkAPITokenScope(req *restful.Request, token metadata.APIToken) (string, bool) {
+	attribute, err := parser.ParseAttribute(req, s.engine)
+	if err != nil {
+		blog.V(5).Infof("parse auth attribute for %s %s failed, check api token scope by method, err: %v, rid: %s",
+			req.Request.Method, req.Request.URL.Path, err, util.GetHTTPCCRequestID(req.Request.Header))
+		if len(token.BizIDs) != 0 {
+			return "operate resources out of the businesses", false
+		}
+		scope := metadata.APITokenScopeWrite
+		if req.Request.Method == http.MethodGet {
+			scope = metadata.APITokenScopeRead
+		}
+		return string(scope), token.HasScope(scope)
+	}
+
+	scope := metadata.APITokenScopeRead
+	for _, resource := range attribute.Resources {
+		if !permit.IsReadAction(resource.Action) {
+			scope = metadata.APITokenScopeWrite
+			break
+		}
+	}
+	if !token.HasScope(scope) {
+		return string(scope), false
+	}
+
+	if len(token.BizIDs) == 0 {
+		return "", true
+	}
+	for _, resource := range attribute.Resources {
+		if resource.BusinessID == 0 {
+			if !permit.IsReadAction(resource.Action) {
+				return "operate resources out of the businesses", false
+			}
+			continue
+		}
+		if !token.AllowBiz(resource.BusinessID) {
+			return fmt.Sprintf("operate business %d", resource.BusinessID), false
+		}
+	}
+	return "", true
+}
+
+// forbidServiceAccount returns true and responses an error if the request is authenticated by an api token,
+// service accounts can not manage service accounts and api tokens.
+func forbidServiceAccount(req *restful.Request, resp *restful.Response, defErr errors.DefaultCCErrorIf) bool {
+	if len(req.Request.Header.Get(common.BKHTTPServiceAccountID)) == 0 {
+		return false
+	}
+	resp.WriteError(http.StatusForbidden, &metadata.RespError{
+		Msg:     defErr.Error(common.CCErrCommServiceAccountForbidden),
+		ErrCode: common.CCErrCommServiceAccountForbidden,
+	})
+	return true
+}
+
+// getOwnServiceAccount get the service account which is created by the request's user, only the creator can
+// change the service account and it's api tokens, so that others can not act as the service account.
+func (s *service) getOwnServiceAccount(req *restful.Request, accountID int64) (metadata.ServiceAccount, errors.CCErrorCoder) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	option := metadata.ListServiceAccountOption{IDs: []int64{accountID}}
+	result, ccErr := s.engine.CoreAPI.CoreService().ServiceAccount().ListServiceAccount(req.Request.Context(), header, option)
+	if ccErr != nil {
+		return metadata.ServiceAccount{}, ccErr
+	}
+	if len(result.Info) == 0 {
+		return metadata.ServiceAccount{}, defErr.CCError(common.CCErrCommNotFound)
+	}
+	if result.Info[0].Creator != util.GetUser(header) {
+		return result.Info[0], errors.NewCCError(common.CCNoPermission, defErr.Error(common.CCErrCommAuthNotHavePermission).Error())
+	}
+	return result.Info[0], nil
+}
+
+func (s *service) CreateServiceAccount(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+	if forbidServiceAccount(req, resp, defErr) {
+		return
+	}
+
+	option := metadata.CreateServiceAccountOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("create service account, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	result, ccErr := s.engine.CoreAPI.CoreService().ServiceAccount().CreateServiceAccount(req.Request.Context(), header, option)
+	if ccErr != nil {
+		blog.Errorf("create service account %s failed, err: %v, rid: %s", option.Name, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+func (s *service) ListServiceAccount(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+	if forbidServiceAccount(req, resp, defErr) {
+		return
+	}
+
+	option := metadata.ListServiceAccountOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("list service account, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	// only the creator can see the service account, the same as the other operations.
+	option.Creator = util.GetUser(header)
+
+	result, ccErr := s.engine.CoreAPI.CoreService().ServiceAccount().ListServiceAccount(req.Request.Context(), header, option)
+	if ccErr != nil {
+		blog.Errorf("list service account failed, option: %+v, err: %v, rid: %s", option, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+func (s *service) UpdateServiceAccount(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+	if forbidServiceAccount(req, resp, defErr) {
+		return
+	}
+
+	accountID, err := strconv.ParseInt(req.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, common.BKFieldID)})
+		return
+	}
+
+	option := metadata.UpdateServiceAccountOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("update service account, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	if _, ccErr := s.getOwnServiceAccount(req, accountID); ccErr != nil {
+		blog.Errorf("update service account %d failed, err: %v, rid: %s", accountID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+
+	result, ccErr := s.engine.CoreAPI.CoreService().ServiceAccount().UpdateServiceAccount(req.Request.Context(), header, accountID, option)
+	if ccErr != nil {
+		blog.Errorf("update service account %d failed, err: %v, rid: %s", accountID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+func (s *service) DeleteServiceAccount(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+	if forbidServiceAccount(req, resp, defErr) {
+		return
+	}
+
+	accountID, err := strconv.ParseInt(req.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, common.BKFieldID)})
+		return
+	}
+
+	if _, ccErr := s.getOwnServiceAccount(req, accountID); ccErr != nil {
+		blog.Errorf("delete service account %d failed, err: %v, rid: %s", accountID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+
+	if ccErr := s.engine.CoreAPI.CoreService().ServiceAccount().DeleteServiceAccount(req.Request.Context(), header, accountID); ccErr != nil {
+		blog.Errorf("delete service account %d failed, err: %v, rid: %s", accountID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(nil))
+}
+
+// CreateAPIToken create an api token of the service account, the token is only returned in the response.
+func (s *service) CreateAPIToken(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+	if forbidServiceAccount(req, resp, defErr) {
+		return
+	}
+
+	accountID, err := strconv.ParseInt(req.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, common.BKFieldID)})
+		return
+	}
+
+	option := metadata.CreateAPITokenOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("create api token, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	if _, ccErr := s.getOwnServiceAccount(req, accountID); ccErr != nil {
+		blog.Errorf("create api token of service account %d failed, err: %v, rid: %s", accountID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+
+	result, ccErr := s.engine.CoreAPI.CoreService().ServiceAccount().CreateAPIToken(req.Request.Context(), header, accountID, option)
+	if ccErr != nil {
+		blog.Errorf("create api token of service account %d failed, err: %v, rid: %s", accountID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	blog.Infof("api token %d of service account %d is created by %s, rid: %s", result.Info.ID, accountID, util.GetUser(header), rid)
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+func (s *service) ListAPIToken(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+	if forbidServiceAccount(req, resp, defErr) {
+		return
+	}
+
+	accountID, err := strconv.ParseInt(req.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, common.BKFieldID)})
+		return
+	}
+
+	option := metadata.ListAPITokenOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("list api token, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	option.ServiceAccountID = accountID
+
+	if _, ccErr := s.getOwnServiceAccount(req, accountID); ccErr != nil {
+		blog.Errorf("list api token of service account %d failed, err: %v, rid: %s", accountID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+
+	result, ccErr := s.engine.CoreAPI.CoreService().ServiceAccount().ListAPIToken(req.Request.Context(), header, option)
+	if ccErr != nil {
+		blog.Errorf("list api token of service account %d failed, err: %v, rid: %s", accountID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+func (s *service) RevokeAPIToken(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+	if forbidServiceAccount(req, resp, defErr) {
+		return
+	}
+
+	accountID, err := strconv.ParseInt(req.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, common.BKFieldID)})
+		return
+	}
+	tokenID, err := strconv.ParseInt(req.PathParameter("token_id"), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "token_id")})
+		return
+	}
+
+	if _, ccErr := s.getOwnServiceAccount(req, accountID); ccErr != nil {
+		blog.Errorf("revoke api token %d of service account %d failed, err: %v, rid: %s", tokenID, accountID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+
+	if ccErr := s.engine.CoreAPI.CoreService().ServiceAccount().RevokeAPIToken(req.Request.Context(), header, accountID, tokenID); ccErr != nil {
+		blog.Errorf("revoke api token %d of service account %d failed, err: %v, rid: %s", tokenID, accountID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	blog.Infof("api token %d of service account %d is revoked by %s, rid: %s", tokenID, accountID, util.GetUser(header), rid)
+	resp.WriteEntity(metadata.NewSuccessResp(nil))
+}
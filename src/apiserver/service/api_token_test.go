@@ -0,0 +1,490 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/apimachinery/coreservice"
+	"configcenter/src/apimachinery/coreservice/model"
+	"configcenter/src/apimachinery/coreservice/serviceaccount"
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+
+	"github.com/emicklei/go-restful"
+)
+
+const (
+	readToken      = "cmdb_read"
+	writeToken     = "cmdb_write"
+	bizToken       = "cmdb_biz"
+	tenantsToken   = "cmdb_tenants"
+	expiredToken   = "cmdb_expired"
+	revokedToken   = "cmdb_revoked"
+	brokenToken    = "cmdb_broken"
+	testAccountID  = 10
+	testAccountStr = "10"
+)
+
+// mockServiceAccountClient validate the api tokens like the core service, and records the option of the
+// service account listing.
+type mockServiceAccountClient struct {
+	serviceaccount.ServiceAccountInterface
+	tokens      map[string]metadata.APIToken
+	listOption  metadata.ListServiceAccountOption
+	listAccount []metadata.ServiceAccount
+}
+
+func (m *mockServiceAccountClient) ValidateAPIToken(ctx context.Context, header http.Header,
+	option metadata.ValidateAPITokenOption) (metadata.ValidAPIToken, errors.CCErrorCoder) {
+
+	if option.TokenHash == metadata.HashAPIToken(brokenToken) {
+		return metadata.ValidAPIToken{}, errors.NewCCError(common.CCErrCommDBSelectFailed, "db select failed")
+	}
+	token, exists := m.tokens[option.TokenHash]
+	if !exists || !token.IsValid(time.Now()) {
+		return metadata.ValidAPIToken{}, errors.NewCCError(common.CCErrCommAPITokenInvalid, "api token is invalid")
+	}
+	account := metadata.ServiceAccount{ID: testAccountID, Name: "robot", Enabled: true, Creator: "admin"}
+	return metadata.ValidAPIToken{Token: token, ServiceAccount: account}, nil
+}
+
+func (m *mockServiceAccountClient) ListServiceAccount(ctx context.Context, header http.Header,
+	option metadata.ListServiceAccountOption) (metadata.MultipleServiceAccount, errors.CCErrorCoder) {
+
+	m.listOption = option
+	return metadata.MultipleServiceAccount{Count: int64(len(m.listAccount)), Info: m.listAccount}, nil
+}
+
+// mockModelClient returns the cloud area model, which is needed to parse the requests.
+type mockModelClient struct {
+	model.ModelClientInterface
+}
+
+func (m *mockModelClient) ReadModel(ctx context.Context, h http.Header, input *metadata.QueryCondition) (
+	*metadata.ReadModelResult, error) {
+
+	plat := metadata.SearchModelInfo{Spec: metadata.Object{ID: 1, ObjectID: common.BKInnerObjIDPlat}}
+	return &metadata.ReadModelResult{
+		BaseResp: metadata.SuccessBaseResp,
+		Data:     metadata.QueryModelWithAttributeDataResult{Count: 1, Info: []metadata.SearchModelInfo{plat}},
+	}, nil
+}
+
+type mockCoreService struct {
+	coreservice.CoreServiceClientInterface
+	serviceAccount *mockServiceAccountClient
+}
+
+func (m *mockCoreService) ServiceAccount() serviceaccount.ServiceAccountInterface {
+	return m.serviceAccount
+}
+
+func (m *mockCoreService) Model() model.ModelClientInterface {
+	return &mockModelClient{}
+}
+
+type mockClientSet struct {
+	apimachinery.ClientSetInterface
+	coreService *mockCoreService
+}
+
+func (m *mockClientSet) CoreService() coreservice.CoreServiceClientInterface {
+	return m.coreService
+}
+
+func newAPITokenService(t *testing.T) (*service, *mockServiceAccountClient, errors.CCErrorIf) {
+	errIf, err := errors.NewFactory("../../../resources/errors/")
+	if err != nil {
+		t.Fatalf("new error factory failed, err: %v", err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	newToken := func(token string, id int64, scope metadata.APITokenScope) metadata.APIToken {
+		return metadata.APIToken{
+			ID:               id,
+			ServiceAccountID: testAccountID,
+			SupplierAccounts: []string{common.BKDefaultOwnerID},
+			Scopes:           []metadata.APITokenScope{scope},
+			ExpireTime:       future,
+			SupplierAccount:  common.BKDefaultOwnerID,
+		}
+	}
+	tokens := map[string]metadata.APIToken{
+		readToken:    newToken(readToken, 1, metadata.APITokenScopeRead),
+		writeToken:   newToken(writeToken, 2, metadata.APITokenScopeWrite),
+		bizToken:     newToken(bizToken, 3, metadata.APITokenScopeWrite),
+		tenantsToken: newToken(tenantsToken, 4, metadata.APITokenScopeWrite),
+		expiredToken: newToken(expiredToken, 5, metadata.APITokenScopeWrite),
+		revokedToken: newToken(revokedToken, 6, metadata.APITokenScopeWrite),
+	}
+	biz := tokens[bizToken]
+	biz.BizIDs = []int64{2}
+	tokens[bizToken] = biz
+	tenants := tokens[tenantsToken]
+	tenants.SupplierAccounts = []string{common.BKDefaultOwnerID, "tenant1"}
+	tokens[tenantsToken] = tenants
+	expired := tokens[expiredToken]
+	expired.ExpireTime = time.Now().Add(-time.Minute)
+	tokens[expiredToken] = expired
+	revoked := tokens[revokedToken]
+	revoked.Revoked = true
+	tokens[revokedToken] = revoked
+
+	client := &mockServiceAccountClient{tokens: make(map[string]metadata.APIToken)}
+	for token, info := range tokens {
+		client.tokens[metadata.HashAPIToken(token)] = info
+	}
+
+	s := &service{engine: &backbone.Engine{
+		CoreAPI: &mockClientSet{coreService: &mockCoreService{serviceAccount: client}},
+		CCErr:   errIf,
+	}}
+	return s, client, errIf
+}
+
+func TestGetAPIToken(t *testing.T) {
+	tests := []struct {
+		authorization string
+		expect        string
+	}{
+		{authorization: "", expect: ""},
+		{authorization: "Bearer cmdb_abc", expect: "cmdb_abc"},
+		{authorization: "Bearer   cmdb_abc ", expect: "cmdb_abc"},
+		{authorization: "Basic cmdb_abc", expect: ""},
+		{authorization: "Bearer other_abc", expect: ""},
+		{authorization: "bearer cmdb_abc", expect: ""},
+	}
+
+	for _, test := range tests {
+		header := http.Header{}
+		header.Set(common.BKHTTPAuthorization, test.authorization)
+		if token := getAPIToken(header); token != test.expect {
+			t.Errorf("token of authorization %q should be %q, got %q", test.authorization, test.expect, token)
+		}
+	}
+}
+
+func TestAPITokenFilter(t *testing.T) {
+	s, _, errIf := newAPITokenService(t)
+	filter := s.apiTokenFilter(func() errors.CCErrorIf { return errIf })
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		header map[string]string
+
+		status int
+		code   int
+		// the headers the next filter gets when the request is passed.
+		owner     string
+		user      string
+		accountID string
+	}{
+		{
+			name:   "no token passes as it is and the client supplied service account is stripped",
+			method: http.MethodPost,
+			path:   "/api/v3/biz/0",
+			header: map[string]string{common.BKHTTPServiceAccountID: testAccountStr, common.BKHTTPHeaderUser: "admin",
+				common.BKHTTPOwnerID: "tenant1"},
+			status: http.StatusOK,
+			owner:  "tenant1",
+			user:   "admin",
+		},
+		{
+			name:   "non api token credential is ignored",
+			method: http.MethodPost,
+			path:   "/api/v3/biz/0",
+			header: map[string]string{common.BKHTTPAuthorization: "Basic " + writeToken,
+				common.BKHTTPHeaderUser: "admin", common.BKHTTPOwnerID: common.BKDefaultOwnerID},
+			status: http.StatusOK,
+			owner:  common.BKDefaultOwnerID,
+			user:   "admin",
+		},
+		{
+			name:   "unknown token",
+			method: http.MethodPost,
+			path:   "/api/v3/biz/search/0",
+			token:  "cmdb_unknown",
+			status: http.StatusUnauthorized,
+			code:   common.CCErrCommAPITokenInvalid,
+		},
+		{
+			name:   "expired token",
+			method: http.MethodPost,
+			path:   "/api/v3/biz/search/0",
+			token:  expiredToken,
+			status: http.StatusUnauthorized,
+			code:   common.CCErrCommAPITokenInvalid,
+		},
+		{
+			name:   "revoked token",
+			method: http.MethodPost,
+			path:   "/api/v3/biz/search/0",
+			token:  revokedToken,
+			status: http.StatusUnauthorized,
+			code:   common.CCErrCommAPITokenInvalid,
+		},
+		{
+			name:   "token validation failure is not taken as unauthorized",
+			method: http.MethodPost,
+			path:   "/api/v3/biz/search/0",
+			token:  brokenToken,
+			status: http.StatusInternalServerError,
+			code:   common.CCErrCommDBSelectFailed,
+		},
+		{
+			name:      "the only supplier account is pinned and the user is replaced by the service account",
+			method:    http.MethodPost,
+			path:      "/api/v3/biz/search/0",
+			token:     readToken,
+			header:    map[string]string{common.BKHTTPHeaderUser: "admin", common.BKHTTPServiceAccountID: "99"},
+			status:    http.StatusOK,
+			owner:     common.BKDefaultOwnerID,
+			user:      metadata.ServiceAccountUserPrefix + "robot",
+			accountID: testAccountStr,
+		},
+		{
+			name:   "supplier account out of the token",
+			method: http.MethodPost,
+			path:   "/api/v3/biz/search/tenant1",
+			token:  readToken,
+			header: map[string]string{common.BKHTTPOwnerID: "tenant1"},
+			status: http.StatusForbidden,
+			code:   common.CCErrCommAPITokenScopeDenied,
+		},
+		{
+			name:   "supplier account must be set for the token of several supplier accounts",
+			method: http.MethodPost,
+			path:   "/api/v3/biz/search/0",
+			token:  tenantsToken,
+			status: http.StatusForbidden,
+			code:   common.CCErrCommAPITokenScopeDenied,
+		},
+		{
+			name:      "supplier account in the token of several supplier accounts",
+			method:    http.MethodPost,
+			path:      "/api/v3/biz/search/tenant1",
+			token:     tenantsToken,
+			header:    map[string]string{common.BKHTTPOwnerID: "tenant1"},
+			status:    http.StatusOK,
+			owner:     "tenant1",
+			user:      metadata.ServiceAccountUserPrefix + "robot",
+			accountID: testAccountStr,
+		},
+		{
+			name:      "read token can find",
+			method:    http.MethodPost,
+			path:      "/api/v3/biz/search/0",
+			token:     readToken,
+			status:    http.StatusOK,
+			owner:     common.BKDefaultOwnerID,
+			user:      metadata.ServiceAccountUserPrefix + "robot",
+			accountID: testAccountStr,
+		},
+		{
+			name:   "read token can not create",
+			method: http.MethodPost,
+			path:   "/api/v3/biz/0",
+			token:  readToken,
+			status: http.StatusForbidden,
+			code:   common.CCErrCommAPITokenScopeDenied,
+		},
+		{
+			name:      "write token can create",
+			method:    http.MethodPost,
+			path:      "/api/v3/biz/0",
+			token:     writeToken,
+			status:    http.StatusOK,
+			owner:     common.BKDefaultOwnerID,
+			user:      metadata.ServiceAccountUserPrefix + "robot",
+			accountID: testAccountStr,
+		},
+		{
+			name:      "biz token can find in it's business",
+			method:    http.MethodPost,
+			path:      "/api/v3/findmany/topo/set_template/bk_biz_id/2",
+			token:     bizToken,
+			status:    http.StatusOK,
+			owner:     common.BKDefaultOwnerID,
+			user:      metadata.ServiceAccountUserPrefix + "robot",
+			accountID: testAccountStr,
+		},
+		{
+			name:      "biz token can change it's business",
+			method:    http.MethodPost,
+			path:      "/api/v3/create/host_apply_rule/bk_biz_id/2",
+			token:     bizToken,
+			status:    http.StatusOK,
+			owner:     common.BKDefaultOwnerID,
+			user:      metadata.ServiceAccountUserPrefix + "robot",
+			accountID: testAccountStr,
+		},
+		{
+			name:   "biz token can not find in other businesses",
+			method: http.MethodPost,
+			path:   "/api/v3/findmany/topo/set_template/bk_biz_id/3",
+			token:  bizToken,
+			status: http.StatusForbidden,
+			code:   common.CCErrCommAPITokenScopeDenied,
+		},
+		{
+			name:      "biz token can find the resources out of businesses",
+			method:    http.MethodPost,
+			path:      "/api/v3/biz/search/0",
+			token:     bizToken,
+			status:    http.StatusOK,
+			owner:     common.BKDefaultOwnerID,
+			user:      metadata.ServiceAccountUserPrefix + "robot",
+			accountID: testAccountStr,
+		},
+		{
+			name:   "biz token can not change the resources out of businesses",
+			method: http.MethodPost,
+			path:   "/api/v3/biz/0",
+			token:  bizToken,
+			status: http.StatusForbidden,
+			code:   common.CCErrCommAPITokenScopeDenied,
+		},
+		{
+			name:   "biz token is denied when the request can not be parsed",
+			method: http.MethodGet,
+			path:   "/api/v3/no/such/api",
+			token:  bizToken,
+			status: http.StatusForbidden,
+			code:   common.CCErrCommAPITokenScopeDenied,
+		},
+		{
+			name:      "read token can get when the request can not be parsed",
+			method:    http.MethodGet,
+			path:      "/api/v3/no/such/api",
+			token:     readToken,
+			status:    http.StatusOK,
+			owner:     common.BKDefaultOwnerID,
+			user:      metadata.ServiceAccountUserPrefix + "robot",
+			accountID: testAccountStr,
+		},
+		{
+			name:   "read token can not post when the request can not be parsed",
+			method: http.MethodPost,
+			path:   "/api/v3/no/such/api",
+			token:  readToken,
+			status: http.StatusForbidden,
+			code:   common.CCErrCommAPITokenScopeDenied,
+		},
+		{
+			name:      "write token can post when the request can not be parsed",
+			method:    http.MethodPost,
+			path:      "/api/v3/no/such/api",
+			token:     writeToken,
+			status:    http.StatusOK,
+			owner:     common.BKDefaultOwnerID,
+			user:      metadata.ServiceAccountUserPrefix + "robot",
+			accountID: testAccountStr,
+		},
+	}
+
+	for _, test := range tests {
+		httpReq := httptest.NewRequest(test.method, test.path, strings.NewReader("{}"))
+		for key, value := range test.header {
+			httpReq.Header.Set(key, value)
+		}
+		if len(test.token) != 0 {
+			httpReq.Header.Set(common.BKHTTPAuthorization, bearerAuthPrefix+test.token)
+		}
+		recorder := httptest.NewRecorder()
+
+		var passed *restful.Request
+		chain := &restful.FilterChain{Target: func(req *restful.Request, resp *restful.Response) {
+			passed = req
+		}}
+		filter(restful.NewRequest(httpReq), restful.NewResponse(recorder), chain)
+
+		if test.status == http.StatusOK {
+			if passed == nil {
+				t.Errorf("%s: request should be passed, got status %d, body: %s", test.name, recorder.Code,
+					recorder.Body.String())
+				continue
+			}
+			header := passed.Request.Header
+			if header.Get(common.BKHTTPOwnerID) != test.owner || header.Get(common.BKHTTPHeaderUser) != test.user ||
+				header.Get(common.BKHTTPServiceAccountID) != test.accountID {
+				t.Errorf("%s: passed request should be supplier account %q, user %q and service account %q, got %q, %q "+
+					"and %q", test.name, test.owner, test.user, test.accountID, header.Get(common.BKHTTPOwnerID),
+					header.Get(common.BKHTTPHeaderUser), header.Get(common.BKHTTPServiceAccountID))
+			}
+			continue
+		}
+
+		if passed != nil {
+			t.Errorf("%s: request should be rejected", test.name)
+			continue
+		}
+		rsp := metadata.BaseResp{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &rsp); err != nil {
+			t.Errorf("%s: unmarshal response %s failed, err: %v", test.name, recorder.Body.String(), err)
+			continue
+		}
+		if recorder.Code != test.status || rsp.Code != test.code {
+			t.Errorf("%s: response should be status %d and code %d, got %d and %d", test.name, test.status, test.code,
+				recorder.Code, rsp.Code)
+		}
+	}
+}
+
+func newJSONResponse(recorder *httptest.ResponseRecorder) *restful.Response {
+	resp := restful.NewResponse(recorder)
+	resp.SetRequestAccepts(restful.MIME_JSON)
+	return resp
+}
+
+func TestListServiceAccountByCreator(t *testing.T) {
+	s, client, _ := newAPITokenService(t)
+	client.listAccount = []metadata.ServiceAccount{{ID: testAccountID, Name: "robot", Creator: "admin"}}
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/v3/findmany/service_account",
+		strings.NewReader(`{"name":"robot","creator":"other"}`))
+	httpReq.Header.Set(common.BKHTTPHeaderUser, "admin")
+	httpReq.Header.Set(common.BKHTTPOwnerID, common.BKDefaultOwnerID)
+	recorder := httptest.NewRecorder()
+	s.ListServiceAccount(restful.NewRequest(httpReq), newJSONResponse(recorder))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("list service account failed, status: %d, body: %s", recorder.Code, recorder.Body.String())
+	}
+	if client.listOption.Creator != "admin" || client.listOption.Name != "robot" {
+		t.Errorf("service accounts should be listed by the request user, got option %+v", client.listOption)
+	}
+
+	// the service accounts can not be listed by a service account.
+	client.listOption = metadata.ListServiceAccountOption{}
+	httpReq = httptest.NewRequest(http.MethodPost, "/api/v3/findmany/service_account", strings.NewReader(`{}`))
+	httpReq.Header.Set(common.BKHTTPHeaderUser, metadata.ServiceAccountUserPrefix+"robot")
+	httpReq.Header.Set(common.BKHTTPServiceAccountID, testAccountStr)
+	recorder = httptest.NewRecorder()
+	s.ListServiceAccount(restful.NewRequest(httpReq), newJSONResponse(recorder))
+	if recorder.Code != http.StatusForbidden || len(client.listOption.Creator) != 0 {
+		t.Errorf("service account should not list service accounts, got status %d", recorder.Code)
+	}
+}
@@ -0,0 +1,434 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/auth/meta"
+	"configcenter/src/auth/parser"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/emicklei/go-restful"
+)
+
+const (
+	changeRequestRootPath = rootPath + "/change_request/"
+	// defaultChangeRequestExpireHours is the default hours a change request can be approved in.
+	defaultChangeRequestExpireHours = 72
+)
+
+// ChangeRequestConfig is the config of the operations which need approval.
+type ChangeRequestConfig struct {
+	// Actions is the auth resource type and action of the operations which need approval,
+	// keyed by resourceType:action, like business:archive.
+	Actions map[string]bool
+	// Expire is the duration a change request can be approved in.
+	Expire time.Duration
+	// Approvers is the users who can view and approve all the change requests.
+	Approvers map[string]bool
+}
+
+// ParseChangeRequestConfig parse the change request config from the api server's config, the config is like:
+//
+//	[changeRequest]
+//	actions=business:archive,model:delete,modelInstance:deleteMany
+//	expireHours=72
+//	approvers=admin
+func ParseChangeRequestConfig(config map[string]string) (ChangeRequestConfig, error) {
+	conf := ChangeRequestConfig{
+		Actions:   make(map[string]bool),
+		Expire:    defaultChangeRequestExpireHours * time.Hour,
+		Approvers: make(map[string]bool),
+	}
+
+	for _, action := range strings.Split(config["changeRequest.actions"], ",") {
+		action = strings.TrimSpace(action)
+		if len(action) == 0 {
+			continue
+		}
+		if parts := strings.Split(action, ":"); len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return conf, fmt.Errorf("invalid change request action %s, it should be resourceType:action", action)
+		}
+		conf.Actions[action] = true
+	}
+
+	if hours, exists := config["changeRequest.expireHours"]; exists && len(hours) != 0 {
+		expire, err := strconv.Atoi(hours)
+		if err != nil || expire <= 0 {
+			return conf, fmt.Errorf("invalid change request expire hours %s", hours)
+		}
+		conf.Expire = time.Duration(expire) * time.Hour
+	}
+
+	for _, approver := range strings.Split(config["changeRequest.approvers"], ",") {
+		if approver = strings.TrimSpace(approver); len(approver) != 0 {
+			conf.Approvers[approver] = true
+		}
+	}
+	return conf, nil
+}
+
+// match returns the first resource of the operation which needs approval.
+func (c ChangeRequestConfig) match(attribute *meta.AuthAttribute) (meta.ResourceAttribute, bool) {
+	for _, resource := range attribute.Resources {
+		if c.Actions[string(resource.Type)+":"+string(resource.Action)] {
+			return resource, true
+		}
+	}
+	return meta.ResourceAttribute{}, false
+}
+
+// changeRequestFilter capture the operations which need approval as pending change requests,
+// the operations are executed after they are approved by another user.
+func (s *service) changeRequestFilter(errFunc func() errors.CCErrorIf) func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
+	return func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
+		// the header is only set by api server when executing an approved change request.
+		req.Request.Header.Del(common.BKHTTPChangeRequestID)
+
+		if len(s.changeRequestConf.Actions) == 0 || req.Request.Method == http.MethodGet ||
+			strings.HasPrefix(req.Request.URL.Path, changeRequestRootPath) {
+			fchain.ProcessFilter(req, resp)
+			return
+		}
+
+		rid := util.GetHTTPCCRequestID(req.Request.Header)
+		attribute, err := parser.ParseAttribute(req, s.engine)
+		if err != nil {
+			// the request which can not be parsed is not a sensitive operation.
+			blog.V(5).Infof("parse auth attribute for %s %s failed, skip change request check, err: %v, rid: %s", req.Request.Method, req.Request.URL.Path, err, rid)
+			fchain.ProcessFilter(req, resp)
+			return
+		}
+
+		resource, matched := s.changeRequestConf.match(attribute)
+		if !matched {
+			fchain.ProcessFilter(req, resp)
+			return
+		}
+
+		defErr := errFunc().CreateDefaultCCErrorIf(util.GetLanguage(req.Request.Header))
+		body, err := util.PeekRequest(req.Request)
+		if err != nil {
+			blog.Errorf("read request body of %s %s failed, err: %v, rid: %s", req.Request.Method, req.Request.URL.Path, err, rid)
+			resp.WriteAsJson(metadata.BaseResp{Code: common.CCErrCommHTTPReadBodyFailed, ErrMsg: defErr.Error(common.CCErrCommHTTPReadBodyFailed).Error()})
+			return
+		}
+
+		header := make(map[string]string)
+		for _, key := range metadata.ChangeRequestHeaders {
+			if value := req.Request.Header.Get(key); len(value) != 0 {
+				header[key] = value
+			}
+		}
+		option := metadata.CreateChangeRequestOption{
+			ResourceType: string(resource.Type),
+			Action:       string(resource.Action),
+			BizID:        resource.BusinessID,
+			Method:       req.Request.Method,
+			URI:          req.Request.RequestURI,
+			Header:       header,
+			Body:         string(body),
+			ExpireTime:   time.Now().Add(s.changeRequestConf.Expire),
+		}
+		changeRequest, ccErr := s.engine.CoreAPI.CoreService().ChangeRequest().CreateChangeRequest(req.Request.Context(), req.Request.Header, option)
+		if ccErr != nil {
+			blog.Errorf("create change request for %s %s failed, err: %v, rid: %s", req.Request.Method, req.Request.URL.Path, ccErr, rid)
+			resp.WriteAsJson(metadata.BaseResp{Code: ccErr.GetCode(), ErrMsg: ccErr.Error()})
+			return
+		}
+
+		blog.Infof("%s %s needs approval, change request %d is created, rid: %s", req.Request.Method, req.Request.URL.Path, changeRequest.ID, rid)
+		resp.WriteAsJson(metadata.Response{
+			BaseResp: metadata.BaseResp{
+				Result: false,
+				Code:   common.CCErrCommChangeRequestPending,
+				ErrMsg: defErr.Errorf(common.CCErrCommChangeRequestPending, changeRequest.ID).Error(),
+			},
+			Data: changeRequest,
+		})
+	}
+}
+
+func (s *service) ListChangeRequest(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+
+	option := metadata.ListChangeRequestOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("list change request, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	// users can only list the change requests they applied or approved, except the configured approvers.
+	if user := util.GetUser(header); !s.changeRequestConf.Approvers[user] {
+		option.Participant = user
+	}
+
+	result, err := s.engine.CoreAPI.CoreService().ChangeRequest().ListChangeRequest(req.Request.Context(), header, option)
+	if err != nil {
+		blog.Errorf("list change request failed, option: %+v, err: %v, rid: %s", option, err, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: err, ErrCode: err.GetCode()})
+		return
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+func (s *service) GetChangeRequest(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+
+	requestID, err := strconv.ParseInt(req.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, common.BKFieldID)})
+		return
+	}
+
+	result, ccErr := s.engine.CoreAPI.CoreService().ChangeRequest().GetChangeRequest(req.Request.Context(), header, requestID)
+	if ccErr != nil {
+		blog.Errorf("get change request %d failed, err: %v, rid: %s", requestID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+
+	// the change request can be viewed by it's applicant, approver and the users who can approve it.
+	user := util.GetUser(header)
+	if user != result.Applicant && user != result.Approver {
+		if !s.checkApprovePermission(req, resp, result) {
+			return
+		}
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+func (s *service) RejectChangeRequest(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+
+	requestID, err := strconv.ParseInt(req.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, common.BKFieldID)})
+		return
+	}
+
+	option := metadata.ApproveChangeRequestOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("reject change request, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	changeRequest, ccErr := s.engine.CoreAPI.CoreService().ChangeRequest().GetChangeRequest(req.Request.Context(), header, requestID)
+	if ccErr != nil {
+		blog.Errorf("reject change request, but get change request %d failed, err: %v, rid: %s", requestID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	if !s.checkApprovePermission(req, resp, changeRequest) {
+		return
+	}
+
+	status := metadata.UpdateChangeRequestStatusOption{Status: metadata.ChangeRequestRejected, Comment: &option.Comment}
+	result, ccErr := s.engine.CoreAPI.CoreService().ChangeRequest().UpdateChangeRequestStatus(req.Request.Context(), header, requestID, status)
+	if ccErr != nil {
+		blog.Errorf("reject change request %d failed, err: %v, rid: %s", requestID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+// ApproveChangeRequest approve the change request and execute it's operation with the applicant's identity,
+// the audit logs of the operation are labeled with the change request's id.
+func (s *service) ApproveChangeRequest(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+	ctx := req.Request.Context()
+
+	requestID, err := strconv.ParseInt(req.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, common.BKFieldID)})
+		return
+	}
+
+	option := metadata.ApproveChangeRequestOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("approve change request, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	changeRequest, ccErr := s.engine.CoreAPI.CoreService().ChangeRequest().GetChangeRequest(ctx, header, requestID)
+	if ccErr != nil {
+		blog.Errorf("approve change request, but get change request %d failed, err: %v, rid: %s", requestID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+
+	if !s.checkApprovePermission(req, resp, changeRequest) {
+		return
+	}
+
+	executeRid := util.GenerateRID()
+	status := metadata.UpdateChangeRequestStatusOption{Status: metadata.ChangeRequestExecuting, Comment: &option.Comment, Rid: &executeRid}
+	changeRequest, ccErr = s.engine.CoreAPI.CoreService().ChangeRequest().UpdateChangeRequestStatus(ctx, header, requestID, status)
+	if ccErr != nil {
+		blog.Errorf("approve change request %d failed, err: %v, rid: %s", requestID, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+
+	blog.Infof("change request %d is approved by %s, execute it with rid %s, rid: %s", requestID, util.GetUser(header), executeRid, rid)
+	response, succeed := s.executeChangeRequest(changeRequest, executeRid)
+	status = metadata.UpdateChangeRequestStatusOption{Status: metadata.ChangeRequestExecuted, Response: &response}
+	if !succeed {
+		status.Status = metadata.ChangeRequestFailed
+	}
+	changeRequest, ccErr = s.engine.CoreAPI.CoreService().ChangeRequest().UpdateChangeRequestStatus(ctx, header, requestID, status)
+	if ccErr != nil {
+		blog.Errorf("change request %d is executed, but update status to %s failed, err: %v, rid: %s", requestID, status.Status, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(changeRequest))
+}
+
+// newChangeRequestRequest generate the request of the change request's operation.
+func newChangeRequestRequest(changeRequest metadata.ChangeRequest) (*restful.Request, error) {
+	httpReq, err := http.NewRequest(changeRequest.Method, changeRequest.URI, bytes.NewBufferString(changeRequest.Body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.RequestURI = changeRequest.URI
+	for key, value := range changeRequest.Header {
+		httpReq.Header.Set(key, value)
+	}
+	return restful.NewRequest(httpReq), nil
+}
+
+// checkApprovePermission check if the user can approve the change request, writes the error response if not.
+// the configured approvers can approve all the change requests, the others need the permission of the operation,
+// which can not be granted when auth is disabled.
+func (s *service) checkApprovePermission(req *restful.Request, resp *restful.Response, changeRequest metadata.ChangeRequest) bool {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+	user := util.GetUser(header)
+
+	if s.changeRequestConf.Approvers[user] {
+		return true
+	}
+
+	authorized := false
+	if s.authorizer.Enabled() {
+		var err error
+		authorized, err = s.authorizeChangeRequest(req, changeRequest)
+		if err != nil {
+			blog.Errorf("authorize change request %d failed, user: %s, err: %v, rid: %s", changeRequest.ID, user, err, rid)
+			resp.WriteError(http.StatusOK, &metadata.RespError{Msg: defErr.Error(common.CCErrCommCheckAuthorizeFailed), ErrCode: common.CCErrCommCheckAuthorizeFailed})
+			return false
+		}
+	}
+	if !authorized {
+		blog.Errorf("user %s has no permission to approve change request %d, rid: %s", user, changeRequest.ID, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: defErr.Error(common.CCErrCommAuthNotHavePermission), ErrCode: common.CCNoPermission})
+		return false
+	}
+	return true
+}
+
+// authorizeChangeRequest check if the approver has the permission of the change request's operation.
+func (s *service) authorizeChangeRequest(req *restful.Request, changeRequest metadata.ChangeRequest) (bool, error) {
+	authReq, err := newChangeRequestRequest(changeRequest)
+	if err != nil {
+		return false, err
+	}
+	authReq.Request.Header.Set(common.BKHTTPHeaderUser, util.GetUser(req.Request.Header))
+	authReq.Request.Header.Set(common.BKHTTPCCRequestID, util.GetHTTPCCRequestID(req.Request.Header))
+	authReq.Request = authReq.Request.WithContext(req.Request.Context())
+
+	attribute, err := parser.ParseAttribute(authReq, s.engine)
+	if err != nil {
+		return false, err
+	}
+	decision, err := s.authorizer.Authorize(req.Request.Context(), attribute)
+	if err != nil {
+		return false, err
+	}
+	return decision.Authorized, nil
+}
+
+// executeChangeRequest execute the change request's operation, returns the response and if it's succeed.
+func (s *service) executeChangeRequest(changeRequest metadata.ChangeRequest, rid string) (string, bool) {
+	req, err := newChangeRequestRequest(changeRequest)
+	if err != nil {
+		blog.Errorf("generate request of change request %d failed, err: %v, rid: %s", changeRequest.ID, err, rid)
+		return err.Error(), false
+	}
+	req.Request.Header.Set(common.BKHTTPCCRequestID, rid)
+	req.Request.Header.Set(common.BKHTTPChangeRequestID, strconv.FormatInt(changeRequest.ID, 10))
+
+	kind, err := URLPath(req.Request.RequestURI).FilterChain(req)
+	if err != nil {
+		blog.Errorf("rewrite url of change request %d failed, uri: %s, err: %v, rid: %s", changeRequest.ID, changeRequest.URI, err, rid)
+		return err.Error(), false
+	}
+	if err := s.routeToBackend(req.Request, kind); err != nil {
+		blog.Errorf("get backend server of change request %d failed, err: %v, rid: %s", changeRequest.ID, err, rid)
+		return err.Error(), false
+	}
+
+	url := req.Request.URL.Scheme + "://" + req.Request.URL.Host + req.Request.RequestURI
+	proxyReq, err := http.NewRequest(changeRequest.Method, url, bytes.NewBufferString(changeRequest.Body))
+	if err != nil {
+		blog.Errorf("new request of change request %d failed, url: %s, err: %v, rid: %s", changeRequest.ID, url, err, rid)
+		return err.Error(), false
+	}
+	proxyReq.Header = req.Request.Header
+
+	response, err := s.client.Do(proxyReq)
+	if err != nil {
+		blog.Errorf("execute change request %d failed, url: %s, err: %v, rid: %s", changeRequest.ID, url, err, rid)
+		return err.Error(), false
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		blog.Errorf("read response of change request %d failed, err: %v, rid: %s", changeRequest.ID, err, rid)
+		return err.Error(), false
+	}
+
+	result := metadata.BaseResp{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		blog.Errorf("unmarshal response of change request %d failed, body: %s, err: %v, rid: %s", changeRequest.ID, body, err, rid)
+		return string(body), false
+	}
+	return string(body), response.StatusCode == http.StatusOK && result.Result
+}
@@ -0,0 +1,103 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/emicklei/go-restful"
+)
+
+type exporter func(ctx context.Context, header http.Header, option metadata.ExportOption) (io.ReadCloser, errors.CCErrorCoder)
+
+// ExportInstances exports the instances of the object as new line delimited json
+func (s *service) ExportInstances(req *restful.Request, resp *restful.Response) {
+	objID := req.PathParameter(common.BKObjIDField)
+	s.export(req, resp, func(ctx context.Context, header http.Header, option metadata.ExportOption) (io.ReadCloser, errors.CCErrorCoder) {
+		return s.engine.CoreAPI.CoreService().Export().ExportInstances(ctx, header, objID, option)
+	})
+}
+
+// ExportHosts exports the hosts with their topology as new line delimited json
+func (s *service) ExportHosts(req *restful.Request, resp *restful.Response) {
+	s.export(req, resp, s.engine.CoreAPI.CoreService().Export().ExportHosts)
+}
+
+// ExportInstanceAssociations exports the instance associations of the object as new line delimited json
+func (s *service) ExportInstanceAssociations(req *restful.Request, resp *restful.Response) {
+	objID := req.PathParameter(common.BKObjIDField)
+	s.export(req, resp, func(ctx context.Context, header http.Header, option metadata.ExportOption) (io.ReadCloser, errors.CCErrorCoder) {
+		return s.engine.CoreAPI.CoreService().Export().ExportInstanceAssociations(ctx, header, objID, option)
+	})
+}
+
+// export copies the exported stream to the client, the stream is gzipped if the client accepts it. the data is
+// read from db with a cursor and is never held in memory as a whole, so there's no limit of the exported rows.
+// the fields the user can not read are removed from the exported lines by core service.
+func (s *service) export(req *restful.Request, resp *restful.Response, export exporter) {
+	header := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	rid := util.GetHTTPCCRequestID(header)
+
+	option := metadata.ExportOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil && err != io.EOF {
+		blog.Errorf("export %s, but decode body failed, err: %v, rid: %s", req.Request.URL.Path, err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if key, err := option.Validate(); err != nil {
+		blog.Errorf("export %s, but option is invalid, key: %s, err: %v, rid: %s", req.Request.URL.Path, key, err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, key)})
+		return
+	}
+
+	stream, ccErr := export(req.Request.Context(), header, option)
+	if ccErr != nil {
+		blog.Errorf("export %s failed, err: %v, rid: %s", req.Request.URL.Path, ccErr, rid)
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: ccErr, ErrCode: ccErr.GetCode()})
+		return
+	}
+	defer stream.Close()
+
+	resp.Header().Set("Content-Type", metadata.ExportContentType)
+	var writer io.Writer = resp
+	var gzipWriter *gzip.Writer
+	if strings.Contains(header.Get("Accept-Encoding"), "gzip") {
+		resp.Header().Set("Content-Encoding", "gzip")
+		gzipWriter = gzip.NewWriter(resp)
+		writer = gzipWriter
+	}
+	resp.WriteHeader(http.StatusOK)
+
+	_, err := io.Copy(writer, stream)
+	if err == nil && gzipWriter != nil {
+		err = gzipWriter.Close()
+	}
+	if err != nil {
+		// the response is committed, abort the connection so that the client knows the export is incomplete,
+		// and it can resume the export after the id of the last exported line.
+		blog.Errorf("export %s failed, copy the stream failed, err: %v, rid: %s", req.Request.URL.Path, err, rid)
+		panic(http.ErrAbortHandler)
+	}
+}
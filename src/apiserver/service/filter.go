@@ -75,7 +75,17 @@ func (s *service) URLFilterChan(req *restful.Request, resp *restful.Response, ch
 		}
 	}()
 
-	servers := make([]string, 0)
+	if err = s.routeToBackend(req.Request, kind); err != nil {
+		return
+	}
+
+	chain.ProcessFilter(req, resp)
+}
+
+// routeToBackend set the request's host and scheme to the backend server of the request type.
+func (s *service) routeToBackend(req *http.Request, kind RequestType) error {
+	var servers []string
+	var err error
 	switch kind {
 	case TopoType:
 		servers, err = s.discovery.TopoServer().GetServers()
@@ -99,18 +109,20 @@ func (s *service) URLFilterChan(req *restful.Request, resp *restful.Response, ch
 	}
 
 	if err != nil {
-		return
+		return err
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("no %s server can be found", kind)
 	}
 
 	if strings.HasPrefix(servers[0], "https://") {
-		req.Request.URL.Host = servers[0][8:]
-		req.Request.URL.Scheme = "https"
+		req.URL.Host = servers[0][8:]
+		req.URL.Scheme = "https"
 	} else {
-		req.Request.URL.Host = servers[0][7:]
-		req.Request.URL.Scheme = "http"
+		req.URL.Host = servers[0][7:]
+		req.URL.Scheme = "http"
 	}
-
-	chain.ProcessFilter(req, resp)
+	return nil
 }
 
 func (s *service) authFilter(errFunc func() errors.CCErrorIf) func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
@@ -149,6 +161,18 @@ func (s *service) authFilter(errFunc func() errors.CCErrorIf) func(req *restful.
 			return
 		}
 
+		// the change requests can only be viewed and approved by the applicant and the approvers, which is checked by the handlers.
+		if strings.HasPrefix(path, changeRequestRootPath) {
+			fchain.ProcessFilter(req, resp)
+			return
+		}
+
+		// service accounts can only be managed by their creators, which is checked by the handlers.
+		if path == serviceAccountRootPath || strings.HasPrefix(path, serviceAccountRootPath+"/") {
+			fchain.ProcessFilter(req, resp)
+			return
+		}
+
 		// if common.BKSuperOwnerID == util.GetOwnerID(req.Request.Header) {
 		// 	blog.Errorf("authFilter failed, can not use super supplier account, rid: %s", rid)
 		// 	rsp := metadata.BaseResp{
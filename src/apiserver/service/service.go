@@ -18,6 +18,7 @@ import (
 	"configcenter/src/auth/authcenter"
 	"configcenter/src/common/backbone"
 	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
 	"configcenter/src/common/rdapi"
 
 	"github.com/emicklei/go-restful"
@@ -27,7 +28,7 @@ import (
 // Service service methods
 type Service interface {
 	WebServices(auth authcenter.AuthConfig) []*restful.WebService
-	SetConfig(engine *backbone.Engine, httpClient HTTPClient, discovery discovery.DiscoveryInterface, authorize auth.Authorize, cache *redis.Client, limiter *Limiter)
+	SetConfig(engine *backbone.Engine, httpClient HTTPClient, discovery discovery.DiscoveryInterface, authorize auth.Authorize, cache *redis.Client, limiter *Limiter, changeRequestConf ChangeRequestConfig, tenantMetric *TenantRequestMetric)
 }
 
 // NewService create a new service instance
@@ -42,15 +43,20 @@ type service struct {
 	authorizer auth.Authorizer
 	cache      *redis.Client
 	limiter    *Limiter
+
+	changeRequestConf ChangeRequestConfig
+	tenantMetric      *TenantRequestMetric
 }
 
-func (s *service) SetConfig(engine *backbone.Engine, httpClient HTTPClient, discovery discovery.DiscoveryInterface, authorize auth.Authorize, cache *redis.Client, limiter *Limiter) {
+func (s *service) SetConfig(engine *backbone.Engine, httpClient HTTPClient, discovery discovery.DiscoveryInterface, authorize auth.Authorize, cache *redis.Client, limiter *Limiter, changeRequestConf ChangeRequestConfig, tenantMetric *TenantRequestMetric) {
 	s.engine = engine
 	s.client = httpClient
 	s.discovery = discovery
 	s.authorizer = authorize
 	s.cache = cache
 	s.limiter = limiter
+	s.changeRequestConf = changeRequestConf
+	s.tenantMetric = tenantMetric
 }
 
 func (s *service) WebServices(auth authcenter.AuthConfig) []*restful.WebService {
@@ -61,6 +67,7 @@ func (s *service) WebServices(auth authcenter.AuthConfig) []*restful.WebService
 	ws := &restful.WebService{}
 	ws.Path(rootPath)
 	ws.Filter(s.engine.Metric().RestfulMiddleWare)
+	ws.Filter(s.apiTokenFilter(getErrFun))
 	ws.Filter(rdapi.AllGlobalFilter(getErrFun))
 	ws.Filter(rdapi.RequestLogFilter())
 	ws.Filter(s.LimiterFilter())
@@ -68,11 +75,28 @@ func (s *service) WebServices(auth authcenter.AuthConfig) []*restful.WebService
 	if s.authorizer.Enabled() == true {
 		ws.Filter(s.authFilter(getErrFun))
 	}
+	ws.Filter(s.tenantMetric.Filter)
+	ws.Filter(s.changeRequestFilter(getErrFun))
 	ws.Route(ws.POST("/auth/verify").To(s.AuthVerify))
 	ws.Route(ws.GET("/auth/business_list").To(s.GetAnyAuthorizedAppList))
 	ws.Route(ws.GET("/auth/admin_entrance").To(s.GetAdminEntrance))
 	ws.Route(ws.POST("/auth/skip_url").To(s.GetUserNoAuthSkipURL))
 	ws.Route(ws.POST("/auth/convert").To(s.GetCmdbConvertResources))
+	ws.Route(ws.POST("/change_request/search").To(s.ListChangeRequest))
+	ws.Route(ws.GET("/change_request/{id}").To(s.GetChangeRequest))
+	ws.Route(ws.POST("/change_request/{id}/approve").To(s.ApproveChangeRequest))
+	ws.Route(ws.POST("/change_request/{id}/reject").To(s.RejectChangeRequest))
+	ws.Route(ws.POST("/service_account").To(s.CreateServiceAccount))
+	ws.Route(ws.POST("/service_account/search").To(s.ListServiceAccount))
+	ws.Route(ws.PUT("/service_account/{id}").To(s.UpdateServiceAccount))
+	ws.Route(ws.DELETE("/service_account/{id}").To(s.DeleteServiceAccount))
+	ws.Route(ws.POST("/service_account/{id}/api_token").To(s.CreateAPIToken))
+	ws.Route(ws.POST("/service_account/{id}/api_token/search").To(s.ListAPIToken))
+	ws.Route(ws.POST("/service_account/{id}/api_token/{token_id}/revoke").To(s.RevokeAPIToken))
+	ws.Route(ws.POST("/export/instance/object/{bk_obj_id}").To(s.ExportInstances).Produces(metadata.ExportContentType, restful.MIME_JSON))
+	ws.Route(ws.POST("/export/host").To(s.ExportHosts).Produces(metadata.ExportContentType, restful.MIME_JSON))
+	ws.Route(ws.POST("/export/instance_association/object/{bk_obj_id}").To(s.ExportInstanceAssociations).
+		Produces(metadata.ExportContentType, restful.MIME_JSON))
 	ws.Route(ws.GET("{.*}").Filter(s.URLFilterChan).To(s.Get))
 	ws.Route(ws.POST("{.*}").Filter(s.URLFilterChan).To(s.Post))
 	ws.Route(ws.PUT("{.*}").Filter(s.URLFilterChan).To(s.Put))
@@ -0,0 +1,72 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metrics"
+	"configcenter/src/common/util"
+
+	"github.com/emicklei/go-restful"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// otherSupplierAccount is the label of the supplier accounts which are not configured, so that the
+// clients can not create unlimited label values with random supplier accounts.
+const otherSupplierAccount = "other"
+
+// TenantRequestMetric counts the api calls of each tenant, it's only used by the api server so that
+// an api call is counted once instead of once for each service it passes through.
+type TenantRequestMetric struct {
+	supplierAccounts map[string]bool
+	requestTotal     *prometheus.CounterVec
+}
+
+// NewTenantRequestMetric register the tenant request metric with the supplier accounts in the api server's
+// config, the default supplier account is always counted, the config is like:
+//
+//	[tenantMetric]
+//	supplierAccounts=0,tenant1
+func NewTenantRequestMetric(config map[string]string, registry prometheus.Registerer) *TenantRequestMetric {
+	m := &TenantRequestMetric{
+		supplierAccounts: map[string]bool{common.BKDefaultOwnerID: true},
+		requestTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "cmdb_tenant_http_request_total",
+				Help: "api request total of each tenant.",
+			},
+			[]string{metrics.LabelSupplierAccount},
+		),
+	}
+	registry.MustRegister(m.requestTotal)
+
+	for _, supplierAccount := range strings.Split(config["tenantMetric.supplierAccounts"], ",") {
+		if supplierAccount = strings.TrimSpace(supplierAccount); len(supplierAccount) != 0 {
+			m.supplierAccounts[supplierAccount] = true
+		}
+	}
+	return m
+}
+
+// Filter counts the request by its supplier account, it must be placed after the auth filters so that
+// only the authenticated requests are counted.
+func (m *TenantRequestMetric) Filter(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
+	supplierAccount := util.GetOwnerID(req.Request.Header)
+	if !m.supplierAccounts[supplierAccount] {
+		supplierAccount = otherSupplierAccount
+	}
+	m.requestTotal.WithLabelValues(supplierAccount).Inc()
+	fchain.ProcessFilter(req, resp)
+}
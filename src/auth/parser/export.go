@@ -0,0 +1,84 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+
+	"configcenter/src/auth/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+)
+
+// ExportAuthConfigs exports the hosts and instance associations as the find many operations
+var ExportAuthConfigs = []AuthConfig{
+	{
+		Name:           "ExportHostsPattern",
+		Description:    "导出主机",
+		Pattern:        "/api/v3/export/host",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.HostInstance,
+		ResourceAction: meta.FindMany,
+	}, {
+		Name:           "ExportInstanceAssociationsRegex",
+		Description:    "导出实例关联关系",
+		Regex:          regexp.MustCompile(`^/api/v3/export/instance_association/object/[^\s/]+/?$`),
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ModelInstanceAssociation,
+		ResourceAction: meta.FindMany,
+	},
+}
+
+var exportObjectInstancesRegexp = regexp.MustCompile(`^/api/v3/export/instance/object/[^\s/]+/?$`)
+
+func (ps *parseStream) export() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// export the object's instances, it's authorized as finding the instances of the model
+	if ps.hitRegexp(exportObjectInstancesRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 6 {
+			ps.err = errors.New("export object instances, but got invalid url")
+			return ps
+		}
+
+		objectID := ps.RequestCtx.Elements[5]
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: objectID})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		instanceType, err := ps.getInstanceTypeByObject(objectID)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   instanceType,
+					Action: meta.FindMany,
+				},
+				Layers: []meta.Item{{Type: meta.Model, InstanceID: model.ID}},
+			},
+		}
+		return ps
+	}
+
+	return ParseStreamWithFramework(ps, ExportAuthConfigs)
+}
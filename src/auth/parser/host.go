@@ -27,6 +27,7 @@ func (ps *parseStream) hostRelated() *parseStream {
 		userAPI().
 		userCustom().
 		hostFavorite().
+		hostTrash().
 		cloudResourceSync().
 		hostSnapshot().
 		findObjectIdentifier().
@@ -1043,6 +1044,59 @@ func (ps *parseStream) hostSnapshot() *parseStream {
 	return ps
 }
 
+const (
+	findHostTrashPattern    = "/api/v3/hosts/trash/search"
+	restoreHostTrashPattern = "/api/v3/hosts/trash/restore"
+	purgeHostTrashPattern   = "/api/v3/hosts/trash"
+)
+
+func (ps *parseStream) hostTrash() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// find the hosts in the trash bin.
+	if ps.hitPattern(findHostTrashPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.HostInstance,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
+	// restore the hosts in the trash bin to the resource pool.
+	if ps.hitPattern(restoreHostTrashPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.HostInstance,
+					Action: meta.AddHostToResourcePool,
+				},
+			},
+		}
+		return ps
+	}
+
+	// purge the hosts in the trash bin.
+	if ps.hitPattern(purgeHostTrashPattern, http.MethodDelete) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.HostInstance,
+					Action: meta.DeleteMany,
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
+
 var (
 	findIdentifierAPIRegexp = regexp.MustCompile(`^/api/v3/identifier/[^\s/]+/search/?$`)
 )
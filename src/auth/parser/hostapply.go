@@ -92,6 +92,14 @@ var HostApplyAuthConfigs = []AuthConfig{
 		BizIDGetter:    BizIDFromURLGetter,
 		ResourceType:   meta.MainlineInstanceTopology,
 		ResourceAction: meta.SkipAction,
+	}, {
+		Name:           "FindHostApplyDriftRegex",
+		Description:    "查询主机属性自动应用的属性漂移",
+		Regex:          regexp.MustCompile(`^/api/v3/findmany/host_apply_drift/bk_biz_id/([0-9]+)/?$`),
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    BizIDFromURLGetter,
+		ResourceType:   meta.MainlineInstanceTopology,
+		ResourceAction: meta.SkipAction,
 	},
 }
 
@@ -0,0 +1,69 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"net/http"
+	"regexp"
+
+	"configcenter/src/auth/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+)
+
+const (
+	createHostSnapFieldMappingPattern = "/api/v3/collector/hostsnap/field_mapping/action/create"
+	deleteHostSnapFieldMappingPattern = "/api/v3/collector/hostsnap/field_mapping/action/delete"
+	searchHostSnapFieldMappingPattern = "/api/v3/collector/hostsnap/field_mapping/action/search"
+	dryRunHostSnapFieldMappingPattern = "/api/v3/collector/hostsnap/field_mapping/action/dry_run"
+)
+
+var updateHostSnapFieldMappingRegexp = regexp.MustCompile(`^/api/v3/collector/hostsnap/field_mapping/[0-9]+/action/update/?$`)
+
+// hostSnapFieldMapping the mappings decide how the host snapshot updates the host's attributes,
+// so they are authorized as the attributes of the host model.
+func (ps *parseStream) hostSnapFieldMapping() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	var action meta.Action
+	switch {
+	case ps.hitPattern(createHostSnapFieldMappingPattern, http.MethodPost),
+		ps.hitRegexp(updateHostSnapFieldMappingRegexp, http.MethodPost),
+		ps.hitPattern(deleteHostSnapFieldMappingPattern, http.MethodDelete):
+		action = meta.Update
+	case ps.hitPattern(searchHostSnapFieldMappingPattern, http.MethodPost),
+		ps.hitPattern(dryRunHostSnapFieldMappingPattern, http.MethodPost):
+		action = meta.FindMany
+	default:
+		return ps
+	}
+
+	model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: common.BKInnerObjIDHost})
+	if err != nil {
+		ps.err = err
+		return ps
+	}
+
+	ps.Attribute.Resources = []meta.ResourceAttribute{
+		{
+			Basic: meta.Basic{
+				Type:   meta.ModelAttribute,
+				Action: action,
+			},
+			Layers: []meta.Item{{Type: meta.Model, InstanceID: model.ID}},
+		},
+	}
+	return ps
+}
@@ -15,7 +15,8 @@ func (ps *parseStream) netCollectorRelated() *parseStream {
 	ps.netCollector().
 		netDevice().
 		netProperty().
-		netReport()
+		netReport().
+		hostSnapFieldMapping()
 
 	return ps
 }
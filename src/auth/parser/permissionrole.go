@@ -0,0 +1,56 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"net/http"
+	"regexp"
+
+	"configcenter/src/auth/meta"
+)
+
+var PermissionRoleAuthConfigs = []AuthConfig{
+	{
+		Name:           "CreatePermissionRolePattern",
+		Description:    "创建字段权限角色",
+		Pattern:        "/api/v3/create/topo/permission_role",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.SystemBase,
+		ResourceAction: meta.Create,
+	}, {
+		Name:           "UpdatePermissionRoleRegex",
+		Description:    "更新字段权限角色",
+		Regex:          regexp.MustCompile(`^/api/v3/update/topo/permission_role/([0-9]+)/?$`),
+		HTTPMethod:     http.MethodPut,
+		ResourceType:   meta.SystemBase,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "DeletePermissionRolePattern",
+		Description:    "删除字段权限角色",
+		Pattern:        "/api/v3/deletemany/topo/permission_role",
+		HTTPMethod:     http.MethodDelete,
+		ResourceType:   meta.SystemBase,
+		ResourceAction: meta.Delete,
+	}, {
+		Name:           "ListPermissionRolePattern",
+		Description:    "查询字段权限角色",
+		Pattern:        "/api/v3/findmany/topo/permission_role",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.SystemBase,
+		ResourceAction: meta.FindMany,
+	},
+}
+
+func (ps *parseStream) permissionRole() *parseStream {
+	return ParseStreamWithFramework(ps, PermissionRoleAuthConfigs)
+}
@@ -61,6 +61,22 @@ var ProcessInstanceAuthConfigs = []AuthConfig{
 		BizIDGetter:    DefaultBizIDGetter,
 		ResourceType:   ProcessInstanceIAMResourceType,
 		ResourceAction: meta.Find,
+	}, {
+		Name:           "checkProcessPortConflict",
+		Description:    "检查主机上的进程端口冲突",
+		Pattern:        "/api/v3/find/proc/process_instance/port_conflict",
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    DefaultBizIDGetter,
+		ResourceType:   ProcessInstanceIAMResourceType,
+		ResourceAction: meta.Find,
+	}, {
+		Name:           "findFreePortOnHost",
+		Description:    "查找主机上的空闲端口",
+		Pattern:        "/api/v3/find/proc/process_instance/free_port",
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    DefaultBizIDGetter,
+		ResourceType:   ProcessInstanceIAMResourceType,
+		ResourceAction: meta.Find,
 	},
 }
 
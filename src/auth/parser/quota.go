@@ -0,0 +1,49 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"net/http"
+
+	"configcenter/src/auth/meta"
+)
+
+// QuotaAuthConfigs the quotas are managed by the system administrator
+var QuotaAuthConfigs = []AuthConfig{
+	{
+		Name:           "SetQuotaPattern",
+		Description:    "设置资源配额",
+		Pattern:        "/api/v3/update/topo/quota",
+		HTTPMethod:     http.MethodPut,
+		ResourceType:   meta.SystemBase,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "DeleteQuotaPattern",
+		Description:    "删除资源配额",
+		Pattern:        "/api/v3/delete/topo/quota",
+		HTTPMethod:     http.MethodDelete,
+		ResourceType:   meta.SystemBase,
+		ResourceAction: meta.Delete,
+	}, {
+		Name:           "ListQuotaUsagePattern",
+		Description:    "查询资源配额及使用量",
+		Pattern:        "/api/v3/findmany/topo/quota/usage",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.SystemBase,
+		ResourceAction: meta.FindMany,
+	},
+}
+
+func (ps *parseStream) quota() *parseStream {
+	return ParseStreamWithFramework(ps, QuotaAuthConfigs)
+}
@@ -100,6 +100,30 @@ var ServiceInstanceAuthConfigs = []AuthConfig{
 		BizIDGetter:    DefaultBizIDGetter,
 		ResourceType:   meta.ProcessServiceInstance,
 		ResourceAction: meta.Update,
+	}, {
+		Name:           "createSyncServiceInstanceTaskPattern",
+		Description:    "创建用服务模板更新服务实例的任务",
+		Pattern:        "/api/v3/createmany/proc/service_instance/sync_task",
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    DefaultBizIDGetter,
+		ResourceType:   meta.ProcessServiceInstance,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "getSyncServiceInstanceTaskRegexp",
+		Description:    "查询用服务模板更新服务实例的任务",
+		Regex:          regexp.MustCompile(`^/api/v3/find/proc/service_instance/sync_task/[^\s/]+/bk_biz_id/([0-9]+)/?$`),
+		HTTPMethod:     http.MethodGet,
+		BizIDGetter:    BizIDFromURLGetter,
+		ResourceType:   meta.ProcessServiceInstance,
+		ResourceAction: meta.Find,
+	}, {
+		Name:           "cancelSyncServiceInstanceTaskRegexp",
+		Description:    "取消用服务模板更新服务实例的任务",
+		Regex:          regexp.MustCompile(`^/api/v3/update/proc/service_instance/sync_task/[^\s/]+/bk_biz_id/([0-9]+)/cancel/?$`),
+		HTTPMethod:     http.MethodPut,
+		BizIDGetter:    BizIDFromURLGetter,
+		ResourceType:   meta.ProcessServiceInstance,
+		ResourceAction: meta.Update,
 	}, {
 		Name:           "listServiceInstanceWithHostPattern",
 		Description:    "根据主机查询服务实例",
@@ -152,6 +152,77 @@ var SetTemplateAuthConfigs = []AuthConfig{
 			}
 			return data.SetIDs, nil
 		},
+	}, {
+		Name:           "CancelSyncSetTplToInstRegex",
+		Description:    "取消集群模板同步集群的任务",
+		Regex:          regexp.MustCompile(`^/api/v3/updatemany/topo/set_template/([0-9]+)/bk_biz_id/([0-9]+)/cancel_sync/?$`),
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    BizIDFromURLGetter,
+		ResourceType:   meta.ModelSet,
+		ResourceAction: meta.UpdateMany,
+		InstanceIDGetter: func(request *RequestContext, re *regexp.Regexp) (int64s []int64, e error) {
+			data := &struct {
+				SetIDs []int64 `json:"bk_set_ids" mapstructure:"bk_set_ids"`
+			}{}
+			if err := json.Unmarshal(request.Body, data); err != nil {
+				return nil, fmt.Errorf("unmarshal failed, err: %+v", err)
+			}
+			return data.SetIDs, nil
+		},
+	}, {
+		Name:           "InstantiateSetTemplateRegex",
+		Description:    "用集群模板批量创建集群",
+		Regex:          regexp.MustCompile(`^/api/v3/createmany/topo/set_template/([0-9]+)/bk_biz_id/([0-9]+)/instantiate/?$`),
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    BizIDFromURLGetter,
+		ResourceType:   meta.ModelSet,
+		ResourceAction: meta.Create,
+	}, {
+		Name:           "GetSetTemplateInstantiateTaskRegex",
+		Description:    "查询集群模板批量创建集群的任务",
+		Regex:          regexp.MustCompile(`^/api/v3/find/topo/set_template/([0-9]+)/bk_biz_id/([0-9]+)/instantiate_task/[^\s/]+/?$`),
+		HTTPMethod:     http.MethodGet,
+		BizIDGetter:    BizIDFromURLGetter,
+		ResourceType:   meta.ModelSet,
+		ResourceAction: meta.FindMany,
+	}, {
+		Name:           "UpdateSetTemplateSyncPolicyRegex",
+		Description:    "更新集群模板的同步策略",
+		Regex:          regexp.MustCompile(`^/api/v3/update/topo/set_template/([0-9]+)/bk_biz_id/([0-9]+)/sync_policy/?$`),
+		HTTPMethod:     http.MethodPut,
+		BizIDGetter:    BizIDFromURLGetter,
+		ResourceType:   meta.SetTemplate,
+		ResourceAction: meta.Update,
+		InstanceIDGetter: func(request *RequestContext, re *regexp.Regexp) (int64s []int64, e error) {
+			ss := re.FindStringSubmatch(request.URI)
+			if len(ss) < 2 {
+				return nil, errors.New("UpdateSetTemplateSyncPolicyRegex regex doesn't match")
+			}
+			id, err := strconv.ParseInt(ss[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("UpdateSetTemplateSyncPolicyRegex regex parse match to int failed, err: %+v", err)
+			}
+			return []int64{id}, nil
+		},
+	}, {
+		Name:           "GetSetTemplateSyncPolicyRegex",
+		Description:    "获取集群模板的同步策略",
+		Regex:          regexp.MustCompile(`^/api/v3/find/topo/set_template/([0-9]+)/bk_biz_id/([0-9]+)/sync_policy/?$`),
+		HTTPMethod:     http.MethodGet,
+		BizIDGetter:    BizIDFromURLGetter,
+		ResourceType:   meta.SetTemplate,
+		ResourceAction: meta.Find,
+		InstanceIDGetter: func(request *RequestContext, re *regexp.Regexp) (int64s []int64, e error) {
+			ss := re.FindStringSubmatch(request.URI)
+			if len(ss) < 2 {
+				return nil, errors.New("GetSetTemplateSyncPolicyRegex regex doesn't match")
+			}
+			id, err := strconv.ParseInt(ss[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("GetSetTemplateSyncPolicyRegex regex parse match to int failed, err: %+v", err)
+			}
+			return []int64{id}, nil
+		},
 	}, {
 		Name:           "GetSetSyncStatusRegex",
 		Description:    "获取集群同步状态",
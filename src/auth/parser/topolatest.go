@@ -43,12 +43,18 @@ func (ps *parseStream) topologyLatest() *parseStream {
 		objectAssociationLatest().
 		objectInstanceAssociationLatest().
 		objectInstanceLatest().
+		objectInstanceLabelLatest().
+		objectInstanceAggregationLatest().
+		objectInstanceTrashLatest().
 		objectLatest().
 		objectClassificationLatest().
 		objectAttributeGroupLatest().
 		objectAttributeLatest().
 		mainlineLatest().
 		setTemplate().
+		permissionRole().
+		quota().
+		export().
 		cache()
 
 	return ps
@@ -465,6 +471,7 @@ func (ps *parseStream) objectAssociationLatest() *parseStream {
 const (
 	findObjectInstanceAssociationLatestPattern   = "/api/v3/find/instassociation"
 	createObjectInstanceAssociationLatestPattern = "/api/v3/create/instassociation"
+	traverseObjectInstanceAssociationPattern     = "/api/v3/find/inst/association/traversal"
 )
 
 var (
@@ -691,6 +698,19 @@ func (ps *parseStream) objectInstanceAssociationLatest() *parseStream {
 		return ps
 	}
 
+	// traverse object instance's association graph operation.
+	if ps.hitPattern(traverseObjectInstanceAssociationPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.ModelInstanceAssociation,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
 	// find object instance's association object instance info operation.
 	if ps.hitRegexp(findInstAssociationObjInstInfoLatestRegexp, http.MethodPost) {
 		bizID, err := metadata.BizIDFromMetadata(ps.RequestCtx.Metadata)
@@ -2202,3 +2222,202 @@ func (ps *parseStream) cache() *parseStream {
 	}
 	return ps
 }
+
+var (
+	createObjectInstanceLabelLatestRegexp    = regexp.MustCompile(`^/api/v3/createmany/instance/object/[^\s/]+/labels/?$`)
+	deleteObjectInstanceLabelLatestRegexp    = regexp.MustCompile(`^/api/v3/deletemany/instance/object/[^\s/]+/labels/?$`)
+	aggregateObjectInstanceLabelLatestRegexp = regexp.MustCompile(`^/api/v3/findmany/instance/object/[^\s/]+/labels/aggregation/?$`)
+)
+
+func (ps *parseStream) objectInstanceLabelLatest() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// add or remove the instances' labels
+	if ps.hitRegexp(createObjectInstanceLabelLatestRegexp, http.MethodPost) ||
+		ps.hitRegexp(deleteObjectInstanceLabelLatestRegexp, http.MethodDelete) {
+		if len(ps.RequestCtx.Elements) != 7 {
+			ps.err = errors.New("update object instance labels, but got invalid url")
+			return ps
+		}
+
+		bizID, err := metadata.BizIDFromMetadata(ps.RequestCtx.Metadata)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		objectID := ps.RequestCtx.Elements[5]
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: objectID})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		instanceType, err := ps.getInstanceTypeByObject(objectID)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		for _, instID := range gjson.GetBytes(ps.RequestCtx.Body, "instance_ids").Array() {
+			ps.Attribute.Resources = append(ps.Attribute.Resources, meta.ResourceAttribute{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:       instanceType,
+					Action:     meta.Update,
+					InstanceID: instID.Int(),
+				},
+				Layers: []meta.Item{{Type: meta.Model, InstanceID: model.ID}},
+			})
+		}
+		return ps
+	}
+
+	// aggregate the instances' labels
+	if ps.hitRegexp(aggregateObjectInstanceLabelLatestRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 8 {
+			ps.err = errors.New("aggregate object instance labels, but got invalid url")
+			return ps
+		}
+
+		bizID, err := metadata.BizIDFromMetadata(ps.RequestCtx.Metadata)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		objectID := ps.RequestCtx.Elements[5]
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: objectID})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		instanceType, err := ps.getInstanceTypeByObject(objectID)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:   instanceType,
+					Action: meta.FindMany,
+				},
+				Layers: []meta.Item{{Type: meta.Model, InstanceID: model.ID}},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
+
+var aggregateObjectInstanceLatestRegexp = regexp.MustCompile(`^/api/v3/findmany/instance/object/[^\s/]+/aggregation/?$`)
+
+func (ps *parseStream) objectInstanceAggregationLatest() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// aggregate the instances, the hosts, sets and modules can be aggregated in a business
+	if ps.hitRegexp(aggregateObjectInstanceLatestRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 7 {
+			ps.err = errors.New("aggregate object instances, but got invalid url")
+			return ps
+		}
+
+		bizID := gjson.GetBytes(ps.RequestCtx.Body, common.BKAppIDField).Int()
+		objectID := ps.RequestCtx.Elements[5]
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: objectID})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		instanceType, err := ps.getInstanceTypeByObject(objectID)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:   instanceType,
+					Action: meta.FindMany,
+				},
+				Layers: []meta.Item{{Type: meta.Model, InstanceID: model.ID}},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
+
+var (
+	findObjectInstanceTrashLatestRegexp    = regexp.MustCompile(`^/api/v3/findmany/trash/object/[^\s/]+/?$`)
+	restoreObjectInstanceTrashLatestRegexp = regexp.MustCompile(`^/api/v3/updatemany/trash/object/[^\s/]+/restore/?$`)
+	deleteObjectInstanceTrashLatestRegexp  = regexp.MustCompile(`^/api/v3/deletemany/trash/object/[^\s/]+/?$`)
+)
+
+func (ps *parseStream) objectInstanceTrashLatest() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// the instances in the trash bin are no longer registered to iam, so they are authorized with the model.
+	var action meta.Action
+	switch {
+	case ps.hitRegexp(findObjectInstanceTrashLatestRegexp, http.MethodPost):
+		action = meta.FindMany
+	case ps.hitRegexp(restoreObjectInstanceTrashLatestRegexp, http.MethodPost):
+		action = meta.Create
+	case ps.hitRegexp(deleteObjectInstanceTrashLatestRegexp, http.MethodDelete):
+		action = meta.DeleteMany
+	default:
+		return ps
+	}
+
+	if len(ps.RequestCtx.Elements) < 6 {
+		ps.err = errors.New("operate object instance trash, but got invalid url")
+		return ps
+	}
+
+	bizID, err := metadata.BizIDFromMetadata(ps.RequestCtx.Metadata)
+	if err != nil {
+		ps.err = err
+		return ps
+	}
+
+	objectID := ps.RequestCtx.Elements[5]
+	model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: objectID})
+	if err != nil {
+		ps.err = err
+		return ps
+	}
+
+	instanceType, err := ps.getInstanceTypeByObject(objectID)
+	if err != nil {
+		ps.err = err
+		return ps
+	}
+
+	ps.Attribute.Resources = []meta.ResourceAttribute{
+		{
+			BusinessID: bizID,
+			Basic: meta.Basic{
+				Type:   instanceType,
+				Action: action,
+			},
+			Layers: []meta.Item{{Type: meta.Model, InstanceID: model.ID}},
+		},
+	}
+	return ps
+}
@@ -63,10 +63,13 @@ var (
 	findResourcePoolBusinessRegexp   = regexp.MustCompile(`^/api/v3/biz/default/[^\s/]+/search/?$`)
 	createResourcePoolBusinessRegexp = regexp.MustCompile(`^/api/v3/biz/default/[^\s/]+/?$`)
 	updateBusinessStatusRegexp       = regexp.MustCompile(`^/api/v3/biz/status/[^\s/]+/[^\s/]+/[0-9]+/?$`)
+	archiveBusinessRegexp            = regexp.MustCompile(`^/api/v3/biz/archive/[0-9]+/?$`)
+	restoreBusinessRegexp            = regexp.MustCompile(`^/api/v3/biz/restore/[0-9]+/?$`)
 )
 
 const findReducedBusinessList = `/api/v3/biz/with_reduced`
 const findSimplifiedBusinessList = `/api/v3/biz/simplify`
+const findBusinessArchivePattern = `/api/v3/biz/archives/search`
 
 func (ps *parseStream) business() *parseStream {
 	if ps.shouldReturn() {
@@ -256,6 +259,38 @@ func (ps *parseStream) business() *parseStream {
 		return ps
 	}
 
+	// archive business with a snapshot of it's topology, or restore it with the archive.
+	if ps.hitRegexp(archiveBusinessRegexp, http.MethodPost) || ps.hitRegexp(restoreBusinessRegexp, http.MethodPost) {
+		bizID, err := strconv.ParseInt(ps.RequestCtx.Elements[4], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("archive or restore business, but got invalid business id %s", ps.RequestCtx.Elements[4])
+			return ps
+		}
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:       meta.Business,
+					Action:     meta.Archive,
+					InstanceID: bizID,
+				},
+			},
+		}
+		return ps
+	}
+
+	// find business archives
+	if ps.hitPattern(findBusinessArchivePattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.Business,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
 	return ps
 }
 
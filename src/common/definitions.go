@@ -37,6 +37,8 @@ const (
 	BKNoLimit = 999999999
 	// max limit of a page
 	BKMaxPageSize = 1000
+	// max start of a page, the deeper pages should be paged with the page cursor
+	BKMaxPageStart = 10000
 
 	// 一次最大操作记录数
 	BKMaxRecordsAtOnce = 2000
@@ -147,6 +149,9 @@ const (
 	// BKDBAND the db operator
 	BKDBAND = "$and"
 
+	// BKDBNOR the db operator
+	BKDBNOR = "$nor"
+
 	// BKDBLIKE the db operator
 	BKDBLIKE = "$regex"
 
@@ -190,6 +195,9 @@ const (
 	// BKDBMatch the db opeartor
 	BKDBMatch = "$match"
 
+	// BKDBElemMatch the db opeartor
+	BKDBElemMatch = "$elemMatch"
+
 	// BKDBSum the db opeartor
 	BKDBSum = "$sum"
 
@@ -253,6 +261,12 @@ const (
 	// BKHostOuterIPField the host outerip field
 	BKHostOuterIPField = "bk_host_outerip"
 
+	// BKHostInnerIPNormalizedField the normalized ips of the host innerip field, which is used to search by ip range
+	BKHostInnerIPNormalizedField = "bk_host_innerip_normalized"
+
+	// BKHostOuterIPNormalizedField the normalized ips of the host outerip field, which is used to search by ip range
+	BKHostOuterIPNormalizedField = "bk_host_outerip_normalized"
+
 	// TimeTransferModel the time transferModel field
 	TimeTransferModel = "2006-01-02 15:04:05"
 
@@ -310,11 +324,17 @@ const (
 	// BKModuleIDField the module id field
 	BKModuleIDField = "bk_module_id"
 
+	// BKModuleIDsField the module ids field
+	BKModuleIDsField = "bk_module_ids"
+
 	// BKModuleNameField the module name field
 	BKModuleNameField = "bk_module_name"
 
 	HostApplyEnabledField = "host_apply_enabled"
 
+	// HostApplyDriftActionField the module's action on the hosts' attributes drifting from the host apply rules
+	HostApplyDriftActionField = "host_apply_drift_action"
+
 	// BKSubscriptionIDField the subscription id field
 	BKSubscriptionIDField = "subscription_id"
 	// BKSubscriptionNameField the subscription name field
@@ -344,6 +364,21 @@ const (
 	// BKCloudNameField the cloud name field
 	BKCloudNameField = "bk_cloud_name"
 
+	// BKCloudVendorField the cloud vendor field of the cloud area
+	BKCloudVendorField = "bk_cloud_vendor"
+
+	// BKCloudRegionField the region field of the cloud area
+	BKCloudRegionField = "bk_region"
+
+	// BKCloudVpcIDField the vpc id field of the cloud area
+	BKCloudVpcIDField = "bk_vpc_id"
+
+	// BKCloudProxyField the ips of the agent proxy hosts of the cloud area, separated by comma
+	BKCloudProxyField = "bk_cloud_proxy"
+
+	// BKCloudHostLimitField the max count of the hosts in the cloud area, 0 means no limit
+	BKCloudHostLimitField = "bk_host_limit"
+
 	// BKObjIDField the obj id field
 	BKObjIDField = "bk_obj_id"
 
@@ -505,6 +540,24 @@ const (
 
 	HostApplyRuleIDField = "host_apply_rule_id"
 
+	// BKRoleNameField the attribute permission role's name field
+	BKRoleNameField = "bk_role_name"
+	// BKMembersField the members of a permission role
+	BKMembersField = "members"
+
+	// BKChangeRequestIDField the id of the change request which the operation is executed by
+	BKChangeRequestIDField = "bk_change_request_id"
+	// BKExpireTimeField the expire time field
+	BKExpireTimeField = "expire_time"
+
+	// BKServiceAccountIDField the id of the service account which the api token belongs to
+	BKServiceAccountIDField = "service_account_id"
+	// BKTokenHashField the hashed api token
+	BKTokenHashField = "token_hash"
+
+	// BKDeleteTimeField the time when the instance is soft deleted into the trash bin
+	BKDeleteTimeField = "delete_time"
+
 	BKParentIDField = "bk_parent_id"
 	BKRootIDField   = "bk_root_id"
 
@@ -578,6 +631,9 @@ const (
 	// BKTimeZoneField the time zone field
 	BKTimeZoneField = "time_zone"
 
+	// BKPortConflictPolicyField the business's policy of the process port conflicts on the same host
+	BKPortConflictPolicyField = "bk_port_conflict_policy"
+
 	// BKIsRequiredField the required field
 	BKIsRequiredField = "isrequired"
 
@@ -631,6 +687,8 @@ const (
 	BKGroupField               = "group"
 
 	BKAttributeIDField = "bk_attribute_id"
+	BKPriorityField    = "priority"
+	BKConditionsField  = "conditions"
 )
 
 const (
@@ -764,6 +822,9 @@ const (
 	// FieldTypeOrganization the organization field type
 	FieldTypeOrganization string = "organization"
 
+	// FieldTypeExpression the expression field type, it's value is computed by the expression in option
+	FieldTypeExpression string = "expression"
+
 	// FieldTypeSingleLenChar the single char length limit
 	FieldTypeSingleLenChar int = 256
 
@@ -876,6 +937,12 @@ const (
 // KvMap the map definition
 type KvMap map[string]interface{}
 
+// HostIPNormalizedFields the host ip fields and their normalized fields
+var HostIPNormalizedFields = map[string]string{
+	BKHostInnerIPField: BKHostInnerIPNormalizedField,
+	BKHostOuterIPField: BKHostOuterIPNormalizedField,
+}
+
 const (
 	// CCSystemOperatorUserName the system user
 	CCSystemOperatorUserName  = "cc_system"
@@ -904,6 +971,8 @@ const (
 	EventCacheEventTxnCommitQueueKey = BKCacheKeyV3Prefix + "event:inst_txn_commit_queue"
 	EventCacheEventTxnAbortQueueKey  = BKCacheKeyV3Prefix + "event:inst_txn_abort_queue"
 	RedisSnapKeyPrefix               = BKCacheKeyV3Prefix + "snapshot:"
+	// RedisCloudSnapReportKeyPrefix the key of the last time that a host in the cloud area reported its snapshot
+	RedisCloudSnapReportKeyPrefix = BKCacheKeyV3Prefix + "snapshot_cloud_report:"
 )
 
 // api cache keys
@@ -929,6 +998,12 @@ const (
 	BKHTTPCCRequestID = "Cc_Request_Id"
 	// BKHTTPOtherRequestID esb request id  X-Bkapi-Request-Id
 	BKHTTPOtherRequestID = "X-Bkapi-Request-Id"
+	// BKHTTPChangeRequestID the id of the approved change request which the request is executed for
+	BKHTTPChangeRequestID = "Cc_Change_Request_Id"
+	// BKHTTPAuthorization the authorization header which carries the api token as "Bearer <token>"
+	BKHTTPAuthorization = "Authorization"
+	// BKHTTPServiceAccountID the id of the service account which the request is authenticated as by api token
+	BKHTTPServiceAccountID = "Cc_Service_Account_Id"
 )
 
 // transaction related
@@ -1011,6 +1086,8 @@ const (
 	BKBluekingLoginPluginVersion   = "blueking"
 	BKOpenSourceLoginPluginVersion = "opensource"
 	BKSkipLoginPluginVersion       = "skip-login"
+	BKOIDCLoginPluginVersion       = "oidc"
+	BKLDAPLoginPluginVersion       = "ldap"
 
 	HTTPCookieBKToken = "bk_token"
 
@@ -1024,6 +1101,9 @@ const (
 	WEBSessionAvatarUrlKey     = "avatar_url"
 	WEBSessionMultiSupplierKey = "multisupplier"
 	WEBSessionSupplierID       = "supplier_id"
+	// WEBSessionExpireTimeKey the unix time the login session expires, the login plugin is asked to refresh
+	// the session once it expires, 0 means the session never expires
+	WEBSessionExpireTimeKey = "expire_time"
 
 	LoginSystemMultiSupplierTrue  = "1"
 	LoginSystemMultiSupplierFalse = "0"
@@ -1153,6 +1233,10 @@ const (
 	OptionOther          = "其他"
 	TimerPattern         = "^[\\d]+\\:[\\d]+$"
 	SyncSetTaskName      = "sync-settemplate2set"
+	// InstantiateSetTaskName the task which creates sets from the set template
+	InstantiateSetTaskName = "instantiate-settemplate2set"
+	// SyncServiceInstanceTaskName the task which synchronizes service instances with their service template
+	SyncServiceInstanceTaskName = "sync-service-instance"
 
 	BKHostState = "bk_state"
 )
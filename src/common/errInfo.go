@@ -222,6 +222,26 @@ const (
 	CCErrCommOPInProgressErr = 1199087
 	// CCErrCommRedisOPErr operate redis error.
 	CCErrCommRedisOPErr = 1199088
+	// CCErrCommFieldWritePermissionDenied no permission to edit the fields: %s
+	CCErrCommFieldWritePermissionDenied = 1199089
+	// CCErrCommExpressionEvalFailed evaluate the expression of attribute %s failed, err: %s
+	CCErrCommExpressionEvalFailed = 1199090
+	// CCErrCommChangeRequestPending the operation needs approval, change request %d is created
+	CCErrCommChangeRequestPending = 1199091
+	// CCErrCommChangeRequestStatusInvalid the status of change request %d is %s, it can not be operated
+	CCErrCommChangeRequestStatusInvalid = 1199092
+	// CCErrCommChangeRequestSelfApprove the change request can not be approved or rejected by the applicant
+	CCErrCommChangeRequestSelfApprove = 1199093
+	// CCErrCommAPITokenInvalid the api token is invalid, expired or revoked
+	CCErrCommAPITokenInvalid = 1199094
+	// CCErrCommAPITokenScopeDenied the api token has no permission to %s
+	CCErrCommAPITokenScopeDenied = 1199095
+	// CCErrCommServiceAccountForbidden the operation can not be done by a service account
+	CCErrCommServiceAccountForbidden = 1199096
+	// CCErrCommQuotaExceeded the %s quota of %s is exceeded, limit: %d, used: %d
+	CCErrCommQuotaExceeded = 1199097
+	// CCErrCommFieldReadPermissionDenied no permission to read the fields: %s
+	CCErrCommFieldReadPermissionDenied = 1199098
 
 	// too many requests
 	CCErrTooManyRequestErr = 1199997
@@ -463,6 +483,21 @@ const (
 	CCErrorTopoSearchModelAttriFailedPleaseRefresh = 1101101
 
 	CCErrorModelNotFound = 1101102
+
+	// CCErrTopoBusinessAlreadyArchived the business %d is already archived
+	CCErrTopoBusinessAlreadyArchived = 1101103
+	// CCErrTopoBusinessNotArchived the business %d is not archived
+	CCErrTopoBusinessNotArchived = 1101104
+	// CCErrTopoRestoreBusinessNameDuplicated the business name %s is used by another business
+	CCErrTopoRestoreBusinessNameDuplicated = 1101105
+	// CCErrTopoResourcePoolBusinessCanNotArchive the resource pool business can not be archived
+	CCErrTopoResourcePoolBusinessCanNotArchive = 1101106
+	// CCErrTopoCloudAreaHostLimitExceeded the cloud area %d can hold at most %d hosts
+	CCErrTopoCloudAreaHostLimitExceeded = 1101107
+	// CCErrTopoCloudAreaHostIPConflict the ips %s already exist in the cloud area %d
+	CCErrTopoCloudAreaHostIPConflict = 1101108
+	// CCErrTopoCloudAreaHostLimitTooSmall the host limit %d is less than the count %d of the hosts in the cloud area
+	CCErrTopoCloudAreaHostLimitTooSmall = 1101109
 	// object controller 1102XXX
 
 	// CCErrObjectPropertyGroupInsertFailed failed to save the property group
@@ -600,6 +635,10 @@ const (
 
 	CCErrHostTransferFinalModuleConflict = 1108044
 
+	CCErrProcPortConflict      = 1108045
+	CCErrProcNoFreePort        = 1108046
+	CCErrProcPortFormatInvalid = 1108047
+
 	// audit log 1109XXX
 	CCErrAuditSaveLogFailed      = 1109001
 	CCErrAuditTakeSnapshotFailed = 1109002
@@ -687,6 +726,12 @@ const (
 	CCErrWebNoUsernamePasswd            = 1111015
 	CCErrWebUserinfoFormatWrong         = 1111016
 	CCErrWebUnknownLoginVersion         = 1111017
+	// CCErrWebLoginSystemUnavailable the login system can not be connected
+	CCErrWebLoginSystemUnavailable = 1111018
+	// CCErrWebLoginCallbackInvalid the login callback is invalid or expired
+	CCErrWebLoginCallbackInvalid = 1111019
+	// CCErrWebLoginConfigInvalid the login plugin config is invalid
+	CCErrWebLoginConfigInvalid = 1111020
 
 	// datacollection 1112xxx
 	CCErrCollectNetDeviceCreateFail            = 1112000
@@ -708,6 +753,12 @@ const (
 	CCErrCollectNetHistorySearchFail           = 1112016
 	CCErrCollectNetDeviceUpdateFail            = 1112017
 	CCErrCollectNetPropertyUpdateFail          = 1112018
+	CCErrCollectHostSnapMappingCreateFail      = 1112019
+	CCErrCollectHostSnapMappingUpdateFail      = 1112020
+	CCErrCollectHostSnapMappingDeleteFail      = 1112021
+	CCErrCollectHostSnapMappingSearchFail      = 1112022
+	// CCErrCollectHostSnapMappingDuplicated the mapping of the host property [%s] already exists
+	CCErrCollectHostSnapMappingDuplicated = 1112023
 
 	// coreservice 1113xxx
 	// CCErrorModelAttributeGroupHasSomeAttributes the group has some attributes
@@ -804,6 +855,10 @@ const (
 	CCErrTaskLockedTaskFail       = 1117005
 	CCErrTaskUnLockedTaskFail     = 1117006
 	CCErrTaskListTaskFail         = 1117007
+	// CCErrTaskDependencyFailed the dependent task failed or is canceled
+	CCErrTaskDependencyFailed = 1117008
+	// CCErrTaskCanceled the task is canceled
+	CCErrTaskCanceled = 1117009
 
 	/** TODO: 以下错误码需要改造 **/
 
@@ -0,0 +1,442 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func eval(n node, env map[string]interface{}) (interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+	case *fieldNode:
+		return lookup(env, n.path), nil
+	case *unaryNode:
+		return evalUnary(n, env)
+	case *binaryNode:
+		return evalBinary(n, env)
+	case *conditionalNode:
+		cond, err := eval(n.cond, env)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(cond) {
+			return eval(n.then, env)
+		}
+		return eval(n.els, env)
+	case *callNode:
+		args := make([]interface{}, 0, len(n.args))
+		for _, arg := range n.args {
+			value, err := eval(arg, env)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, value)
+		}
+		result, err := n.fn.call(args)
+		if err != nil {
+			return nil, fmt.Errorf("call function %s failed, err: %v", n.name, err)
+		}
+		return checkString(result)
+	default:
+		return nil, fmt.Errorf("unknown expression node %T", n)
+	}
+}
+
+// lookup get the field's value from the environment, the value is normalized so that
+// numbers from json and from db are handled in the same way.
+func lookup(env map[string]interface{}, path []string) interface{} {
+	var value interface{} = env
+	for _, key := range path {
+		rv := reflect.ValueOf(value)
+		if !rv.IsValid() || rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		item := rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()))
+		if !item.IsValid() {
+			return nil
+		}
+		value = item.Interface()
+	}
+	return normalize(value)
+}
+
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, string, int64, float64:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	default:
+		// composite values like list or map can only be compared with null or printed.
+		return toString(v)
+	}
+}
+
+func evalUnary(n *unaryNode, env map[string]interface{}) (interface{}, error) {
+	operand, err := eval(n.operand, env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "!":
+		return !truthy(operand), nil
+	case "-":
+		switch v := operand.(type) {
+		case int64:
+			return -v, nil
+		case float64:
+			return -v, nil
+		}
+		return nil, fmt.Errorf("can not negate %s value", typeName(operand))
+	}
+	return nil, fmt.Errorf("unsupported operator %s", n.op)
+}
+
+func evalBinary(n *binaryNode, env map[string]interface{}) (interface{}, error) {
+	left, err := eval(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+
+	// logical operators are short-circuit evaluated.
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := eval(n.right, env)
+		if err != nil {
+			return nil, err
+		}
+		return truthy(right), nil
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := eval(n.right, env)
+		if err != nil {
+			return nil, err
+		}
+		return truthy(right), nil
+	}
+
+	right, err := eval(n.right, env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	case "+":
+		if _, ok := left.(string); ok {
+			return checkString(toString(left) + toString(right))
+		}
+		if _, ok := right.(string); ok {
+			return checkString(toString(left) + toString(right))
+		}
+		return arithmetic(n.op, left, right)
+	default:
+		return arithmetic(n.op, left, right)
+	}
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	if !isNumber(left) || !isNumber(right) {
+		return nil, fmt.Errorf("operator %s is not supported between %s and %s", op, typeName(left), typeName(right))
+	}
+
+	li, lIsInt := left.(int64)
+	ri, rIsInt := right.(int64)
+	if lIsInt && rIsInt && op != "/" {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "%":
+			if ri == 0 {
+				return nil, fmt.Errorf("modulo by zero")
+			}
+			return li % ri, nil
+		}
+	}
+
+	lf, rf := toFloat(left), toFloat(right)
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return lf / rf, nil
+	case "%":
+		if rf == 0 {
+			return nil, fmt.Errorf("modulo by zero")
+		}
+		return math.Mod(lf, rf), nil
+	}
+	return nil, fmt.Errorf("unsupported operator %s", op)
+}
+
+func compare(op string, left, right interface{}) (interface{}, error) {
+	var result int
+	switch {
+	case isNumber(left) && isNumber(right):
+		lf, rf := toFloat(left), toFloat(right)
+		switch {
+		case lf < rf:
+			result = -1
+		case lf > rf:
+			result = 1
+		}
+	default:
+		ls, lok := left.(string)
+		rs, rok := right.(string)
+		if !lok || !rok {
+			return nil, fmt.Errorf("operator %s is not supported between %s and %s", op, typeName(left), typeName(right))
+		}
+		result = strings.Compare(ls, rs)
+	}
+
+	switch op {
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	default:
+		return result >= 0, nil
+	}
+}
+
+func equal(left, right interface{}) bool {
+	if isNumber(left) && isNumber(right) {
+		return toFloat(left) == toFloat(right)
+	}
+	return left == right
+}
+
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return len(v) != 0
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	default:
+		return true
+	}
+}
+
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int64, float64:
+		return true
+	}
+	return false
+}
+
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		js, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(js)
+	}
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case int64, float64:
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// checkString limits the length of the produced strings, so that an expression can not
+// generate a huge value.
+func checkString(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok && utf8.RuneCountInString(s) > MaxStringLength {
+		return nil, fmt.Errorf("string value exceeds max length %d", MaxStringLength)
+	}
+	return value, nil
+}
+
+type function struct {
+	minArgs int
+	// maxArgs is the max count of the arguments, -1 means unlimited.
+	maxArgs int
+	call    func(args []interface{}) (interface{}, error)
+}
+
+func stringFunc(fn func(s string) interface{}) function {
+	return function{minArgs: 1, maxArgs: 1, call: func(args []interface{}) (interface{}, error) {
+		return fn(toString(args[0])), nil
+	}}
+}
+
+func toInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("%s value is not a number", typeName(value))
+}
+
+// functions is the builtin functions, they are the only functions can be called in the expression.
+var functions = map[string]function{
+	"lower": stringFunc(func(s string) interface{} { return strings.ToLower(s) }),
+	"upper": stringFunc(func(s string) interface{} { return strings.ToUpper(s) }),
+	"trim":  stringFunc(func(s string) interface{} { return strings.TrimSpace(s) }),
+	"len":   stringFunc(func(s string) interface{} { return int64(utf8.RuneCountInString(s)) }),
+	"string": {minArgs: 1, maxArgs: 1, call: func(args []interface{}) (interface{}, error) {
+		return toString(args[0]), nil
+	}},
+	"substr": {minArgs: 2, maxArgs: 3, call: func(args []interface{}) (interface{}, error) {
+		runes := []rune(toString(args[0]))
+		start, err := toInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if start < 0 {
+			start = 0
+		}
+		if start > int64(len(runes)) {
+			start = int64(len(runes))
+		}
+		end := int64(len(runes))
+		if len(args) == 3 {
+			length, err := toInt(args[2])
+			if err != nil {
+				return nil, err
+			}
+			// compare with the rest length rather than start+length, which may overflow
+			if length >= 0 && length < end-start {
+				end = start + length
+			}
+		}
+		return string(runes[start:end]), nil
+	}},
+	"replace": {minArgs: 3, maxArgs: 3, call: func(args []interface{}) (interface{}, error) {
+		old := toString(args[1])
+		if len(old) == 0 {
+			return toString(args[0]), nil
+		}
+		return strings.Replace(toString(args[0]), old, toString(args[2]), -1), nil
+	}},
+	"contains": {minArgs: 2, maxArgs: 2, call: func(args []interface{}) (interface{}, error) {
+		return strings.Contains(toString(args[0]), toString(args[1])), nil
+	}},
+	"starts_with": {minArgs: 2, maxArgs: 2, call: func(args []interface{}) (interface{}, error) {
+		return strings.HasPrefix(toString(args[0]), toString(args[1])), nil
+	}},
+	"ends_with": {minArgs: 2, maxArgs: 2, call: func(args []interface{}) (interface{}, error) {
+		return strings.HasSuffix(toString(args[0]), toString(args[1])), nil
+	}},
+	"concat": {minArgs: 1, maxArgs: -1, call: func(args []interface{}) (interface{}, error) {
+		var builder strings.Builder
+		for _, arg := range args {
+			builder.WriteString(toString(arg))
+		}
+		return builder.String(), nil
+	}},
+	"default": {minArgs: 2, maxArgs: 2, call: func(args []interface{}) (interface{}, error) {
+		if args[0] == nil || args[0] == "" {
+			return args[1], nil
+		}
+		return args[0], nil
+	}},
+}
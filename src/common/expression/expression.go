@@ -0,0 +1,104 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package expression implements the expression language used by the expression attributes,
+// whose values are computed from the other fields of the instance and it's mainline parents.
+//
+// the language is sandboxed, it can only read the fields in the environment and call the
+// builtin functions, it has no loops, assignments or side effects. the syntax is:
+//
+//	literals:    "string", 'string', 10, 1.5, true, false, null
+//	fields:      bk_host_innerip, set.bk_set_name, biz.bk_biz_name
+//	arithmetic:  + - * / %, + concatenates the values if any of them is a string
+//	comparison:  == != < <= > >=
+//	logical:     && || !
+//	conditional: cond ? value1 : value2
+//	functions:   lower(s) upper(s) trim(s) len(s) substr(s, start[, length]) replace(s, old, new)
+//	             contains(s, sub) starts_with(s, prefix) ends_with(s, suffix) concat(v...)
+//	             default(v, fallback) string(v)
+//
+// a field which is not in the environment evaluates to null, null is concatenated as an empty string.
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+const (
+	// MaxExpressionLength the max characters of an expression.
+	MaxExpressionLength = 1024
+	// MaxNestingDepth the max nesting depth of parentheses, unary operators and function calls.
+	MaxNestingDepth = 32
+	// MaxFieldPathLength the max parts of a field path, like set.bk_set_name.
+	MaxFieldPathLength = 2
+	// MaxStringLength the max characters of a string produced when evaluating.
+	MaxStringLength = 4096
+)
+
+// Expression is a parsed expression which can be evaluated many times concurrently.
+type Expression struct {
+	raw    string
+	root   node
+	fields []string
+}
+
+// Parse parse the expression, returns error if the expression is invalid.
+func Parse(expr string) (*Expression, error) {
+	if len(expr) == 0 {
+		return nil, errors.New("expression is empty")
+	}
+	if utf8.RuneCountInString(expr) > MaxExpressionLength {
+		return nil, fmt.Errorf("expression exceeds max length %d", MaxExpressionLength)
+	}
+
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, fields: make(map[string]struct{})}
+	root, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.value, t.pos)
+	}
+
+	fields := make([]string, 0, len(p.fields))
+	for field := range p.fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return &Expression{raw: expr, root: root, fields: fields}, nil
+}
+
+// String returns the raw expression.
+func (e *Expression) String() string {
+	return e.raw
+}
+
+// Fields returns the sorted field paths referenced by the expression, like bk_host_name or set.bk_set_name.
+func (e *Expression) Fields() []string {
+	return e.fields
+}
+
+// Eval evaluate the expression with the fields in the environment, the environment's values are
+// the instance's fields, and the fields of a parent can be put in it as a map keyed by it's object id.
+// the result is nil, bool, int64, float64 or string.
+func (e *Expression) Eval(env map[string]interface{}) (interface{}, error) {
+	return eval(e.root, env)
+}
@@ -0,0 +1,121 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package expression_test
+
+import (
+	"strings"
+	"testing"
+
+	"configcenter/src/common/expression"
+	"configcenter/src/common/mapstr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEval(t *testing.T) {
+	env := map[string]interface{}{
+		"hostname":  "web01",
+		"domain":    "example.com",
+		"cpu":       4,
+		"mem":       float64(8192),
+		"empty":     "",
+		"set":       mapstr.MapStr{"bk_set_name": "prod-web"},
+		"biz":       map[string]interface{}{"bk_biz_name": "Blueking"},
+		"is_online": true,
+	}
+
+	cases := []struct {
+		expr   string
+		expect interface{}
+	}{
+		{`hostname + "." + domain`, "web01.example.com"},
+		{`upper(hostname)`, "WEB01"},
+		{`starts_with(set.bk_set_name, "prod") ? "production" : "test"`, "production"},
+		{`lower(biz.bk_biz_name) + "-" + substr(set.bk_set_name, 5)`, "blueking-web"},
+		{`cpu * 2 + 1`, int64(9)},
+		{`mem / 1024`, float64(8)},
+		{`cpu % 3`, int64(1)},
+		{`-cpu`, int64(-4)},
+		{`default(empty, "none")`, "none"},
+		{`default(not_exist, 1)`, int64(1)},
+		{`not_exist == null`, true},
+		{`"cpu:" + cpu`, "cpu:4"},
+		{`concat(hostname, "-", cpu, "-", is_online)`, "web01-4-true"},
+		{`len(hostname) >= 5 && !empty`, true},
+		{`cpu > 8 || mem < 1024`, false},
+		{`replace(domain, ".", "_")`, "example_com"},
+		{`contains(domain, "ample")`, true},
+		{`(1 + 2) * 3`, int64(9)},
+		{`'single\'quote'`, "single'quote"},
+		{`set.not_exist + "x"`, "x"},
+		{`substr("abc", 1, 9223372036854775807)`, "bc"},
+		{`substr("abc", 1, 1)`, "b"},
+	}
+
+	for _, c := range cases {
+		expr, err := expression.Parse(c.expr)
+		if !assert.NoError(t, err, c.expr) {
+			continue
+		}
+		result, err := expr.Eval(env)
+		assert.NoError(t, err, c.expr)
+		assert.Equal(t, c.expect, result, c.expr)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	invalid := []string{
+		``,
+		`hostname +`,
+		`(hostname`,
+		`hostname)`,
+		`exec("rm")`,
+		`lower()`,
+		`a.b.c`,
+		`"unterminated`,
+		`hostname = 1`,
+		`a ? b`,
+		strings.Repeat("(", expression.MaxNestingDepth+1) + "1" + strings.Repeat(")", expression.MaxNestingDepth+1),
+		strings.Repeat("a", expression.MaxExpressionLength+1),
+	}
+	for _, expr := range invalid {
+		_, err := expression.Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestEvalError(t *testing.T) {
+	env := map[string]interface{}{"name": "a", "num": 1}
+	invalid := []string{
+		`num / 0`,
+		`num % 0`,
+		`name - 1`,
+		`-name`,
+		`name < 1`,
+		`substr(name, "x")`,
+	}
+	for _, item := range invalid {
+		expr, err := expression.Parse(item)
+		if !assert.NoError(t, err, item) {
+			continue
+		}
+		_, err = expr.Eval(env)
+		assert.Error(t, err, item)
+	}
+}
+
+func TestFields(t *testing.T) {
+	expr, err := expression.Parse(`hostname + "." + set.bk_set_name + hostname + upper(biz.bk_biz_name)`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"biz.bk_biz_name", "hostname", "set.bk_set_name"}, expr.Fields())
+}
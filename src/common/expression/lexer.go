@@ -0,0 +1,128 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	// pos is the offset of the token in the expression, used in error messages.
+	pos int
+}
+
+// operators sorted by length, so that the longest operator is matched first.
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")", ",", "."}
+
+func tokenize(expr string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(expr)
+	for pos := 0; pos < len(runes); {
+		r := runes[pos]
+		switch {
+		case unicode.IsSpace(r):
+			pos++
+
+		case r == '_' || unicode.IsLetter(r):
+			start := pos
+			for pos < len(runes) && (runes[pos] == '_' || unicode.IsLetter(runes[pos]) || unicode.IsDigit(runes[pos])) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:pos]), pos: start})
+
+		case unicode.IsDigit(r):
+			start := pos
+			hasDot := false
+			for pos < len(runes) && (unicode.IsDigit(runes[pos]) || (runes[pos] == '.' && !hasDot)) {
+				if runes[pos] == '.' {
+					// a dot not followed by digit is not part of the number.
+					if pos+1 >= len(runes) || !unicode.IsDigit(runes[pos+1]) {
+						break
+					}
+					hasDot = true
+				}
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[start:pos]), pos: start})
+
+		case r == '"' || r == '\'':
+			start := pos
+			value, next, err := readString(runes, pos)
+			if err != nil {
+				return nil, err
+			}
+			pos = next
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: start})
+
+		default:
+			matched := ""
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[pos:]), op) {
+					matched = op
+					break
+				}
+			}
+			if matched == "" {
+				return nil, fmt.Errorf("unexpected character %q at %d", r, pos)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: matched, pos: pos})
+			pos += len([]rune(matched))
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// readString read a quoted string starting at pos, returns the unquoted value and the position after it.
+func readString(runes []rune, pos int) (string, int, error) {
+	quote := runes[pos]
+	var builder strings.Builder
+	for i := pos + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case quote:
+			return builder.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(runes) {
+				return "", 0, fmt.Errorf("unterminated string at %d", pos)
+			}
+			i++
+			switch runes[i] {
+			case 'n':
+				builder.WriteRune('\n')
+			case 't':
+				builder.WriteRune('\t')
+			case '\\', '"', '\'':
+				builder.WriteRune(runes[i])
+			default:
+				return "", 0, fmt.Errorf("unsupported escape character %q at %d", runes[i], i)
+			}
+		default:
+			builder.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string at %d", pos)
+}
@@ -0,0 +1,290 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type node interface{}
+
+type literalNode struct {
+	value interface{}
+}
+
+// fieldNode reference a field of the instance, or a field of it's parent when the path has two parts.
+type fieldNode struct {
+	path []string
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+type conditionalNode struct {
+	cond, then, els node
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+	fields map[string]struct{}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(values ...string) bool {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return false
+	}
+	for _, value := range values {
+		if t.value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.isOperator(op) {
+		t := p.peek()
+		return fmt.Errorf("expect %q at %d, but got %q", op, t.pos, t.value)
+	}
+	p.next()
+	return nil
+}
+
+// enter limits the nesting depth of the expression, so that a malicious expression can not exhaust the stack.
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > MaxNestingDepth {
+		return fmt.Errorf("expression nesting exceeds max depth %d", MaxNestingDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseConditional() (node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOperator("?") {
+		return cond, nil
+	}
+	p.next()
+
+	then, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	els, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalNode{cond: cond, then: then, els: els}, nil
+}
+
+// binaryPrecedence is the binary operators from the lowest precedence to the highest.
+var binaryPrecedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level >= len(binaryPrecedence) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator(binaryPrecedence[level]...) {
+		op := p.next().value
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if !p.isOperator("!", "-") {
+		return p.parsePrimary()
+	}
+
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	op := p.next().value
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &unaryNode{op: op, operand: operand}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		if strings.Contains(t.value, ".") {
+			value, err := strconv.ParseFloat(t.value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s at %d", t.value, t.pos)
+			}
+			return &literalNode{value: value}, nil
+		}
+		value, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at %d", t.value, t.pos)
+		}
+		return &literalNode{value: value}, nil
+
+	case tokenString:
+		return &literalNode{value: t.value}, nil
+
+	case tokenIdent:
+		switch t.value {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+
+		if p.isOperator("(") {
+			return p.parseCall(t)
+		}
+		return p.parseField(t)
+
+	case tokenOperator:
+		if t.value == "(" {
+			inner, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+
+	if t.kind == tokenEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.value, t.pos)
+}
+
+func (p *parser) parseField(first token) (node, error) {
+	path := []string{first.value}
+	for p.isOperator(".") {
+		p.next()
+		t := p.next()
+		if t.kind != tokenIdent {
+			return nil, fmt.Errorf("expect field name at %d, but got %q", t.pos, t.value)
+		}
+		path = append(path, t.value)
+	}
+	if len(path) > MaxFieldPathLength {
+		return nil, fmt.Errorf("field %s at %d exceeds max path length %d", strings.Join(path, "."), first.pos, MaxFieldPathLength)
+	}
+
+	p.fields[strings.Join(path, ".")] = struct{}{}
+	return &fieldNode{path: path}, nil
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, exists := functions[name.value]
+	if !exists {
+		return nil, fmt.Errorf("unsupported function %s at %d", name.value, name.pos)
+	}
+
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	// skip the "("
+	p.next()
+	args := make([]node, 0)
+	if !p.isOperator(")") {
+		for {
+			arg, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.isOperator(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("function %s at %d got invalid arguments count %d", name.value, name.pos, len(args))
+	}
+	return &callNode{name: name.value, fn: fn, args: args}, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fieldpermission
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// FieldPermissionInterface get the attribute level permissions of the request user.
+type FieldPermissionInterface interface {
+	// GetObjectFieldPermission get the field permissions of the object's attributes.
+	GetObjectFieldPermission(ctx context.Context, objID string) (*metadata.ObjectFieldPermission, error)
+	// GetUserRoles get the permission roles the user belongs to.
+	GetUserRoles(ctx context.Context) ([]string, error)
+}
+
+type fieldPermission struct {
+	clientSet apimachinery.ClientSetInterface
+	header    http.Header
+	rid       string
+	ccErr     errors.DefaultCCErrorIf
+
+	// roles and permissions are loaded only once in one request.
+	roles       []string
+	permissions map[string]*metadata.ObjectFieldPermission
+}
+
+// NewFieldPermission new a field permission getter of the request user, it's not goroutine safe.
+func NewFieldPermission(clientSet apimachinery.ClientSetInterface, header http.Header) FieldPermissionInterface {
+	return &fieldPermission{
+		clientSet:   clientSet,
+		header:      header,
+		rid:         util.GetHTTPCCRequestID(header),
+		ccErr:       util.GetDefaultCCError(header),
+		permissions: make(map[string]*metadata.ObjectFieldPermission),
+	}
+}
+
+func (f *fieldPermission) GetObjectFieldPermission(ctx context.Context, objID string) (*metadata.ObjectFieldPermission, error) {
+	if permission, exists := f.permissions[objID]; exists {
+		return permission, nil
+	}
+
+	cond := map[string]interface{}{
+		common.BKObjIDField: objID,
+		metadata.AttributeFieldPermission: map[string]interface{}{
+			common.BKDBExists: true,
+			common.BKDBNE:     nil,
+		},
+	}
+	rsp, err := f.clientSet.CoreService().Model().ReadModelAttr(ctx, f.header, objID, &metadata.QueryCondition{Condition: cond})
+	if err != nil {
+		blog.Errorf("get object %s attributes with permission failed, err: %v, rid: %s", objID, err, f.rid)
+		return nil, f.ccErr.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !rsp.Result {
+		blog.Errorf("get object %s attributes with permission failed, err: %s, rid: %s", objID, rsp.ErrMsg, f.rid)
+		return nil, rsp.CCError()
+	}
+
+	var roles []string
+	if len(rsp.Data.Info) > 0 {
+		roles, err = f.GetUserRoles(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	permission := metadata.NewObjectFieldPermission(objID, rsp.Data.Info, roles)
+	f.permissions[objID] = permission
+	return permission, nil
+}
+
+func (f *fieldPermission) GetUserRoles(ctx context.Context) ([]string, error) {
+	if f.roles != nil {
+		return f.roles, nil
+	}
+
+	option := metadata.ListPermissionRoleOption{
+		Member: util.GetUser(f.header),
+		Page:   metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	result, err := f.clientSet.CoreService().PermissionRole().ListPermissionRole(ctx, f.header, option)
+	if err != nil {
+		blog.Errorf("list the permission roles of user %s failed, err: %v, rid: %s", option.Member, err, f.rid)
+		return nil, err
+	}
+
+	f.roles = make([]string, 0)
+	for _, role := range result.Info {
+		f.roles = append(f.roles, role.Name)
+	}
+	return f.roles, nil
+}
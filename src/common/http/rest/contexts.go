@@ -13,9 +13,11 @@
 package rest
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 
@@ -72,6 +74,16 @@ func (c *Contexts) RespEntity(data interface{}) {
 	c.writeAsJson(metadata.NewSuccessResp(data))
 }
 
+// RespBody responses the body which embeds a metadata.Response, it's used when some extra fields need to be
+// returned beside the data.
+func (c *Contexts) RespBody(body interface{}) {
+	if c.respStatusCode != 0 {
+		c.resp.WriteHeader(c.respStatusCode)
+	}
+	c.resp.Header().Set("Content-Type", "application/json")
+	c.writeAsJson(body)
+}
+
 // RespString response the data format to a json string.
 // the data is a string, and do not need marshal, can return directly.
 func (c *Contexts) RespString(data string) {
@@ -112,6 +124,29 @@ func (c *Contexts) RespStringArray(jsonArray []string) {
 	c.resp.Write(jsonBuffer.Bytes())
 }
 
+// streamBufferSize is the size of the buffer of the stream response, the buffered data is sent to the
+// client when the buffer is full.
+const streamBufferSize = 32 * 1024
+
+// RespStream responses the data written by the handler as a stream of the content type, the response is
+// committed before the handler is called, so the handler's error can not be responded as an error response,
+// the connection is aborted instead so that the client gets an incomplete response rather than a truncated one.
+func (c *Contexts) RespStream(contentType string, handler func(w io.Writer) error) {
+	c.resp.Header().Set("Content-Type", contentType)
+	c.resp.Header().Add(common.BKHTTPCCRequestID, c.Kit.Rid)
+	c.resp.WriteHeader(http.StatusOK)
+
+	writer := bufio.NewWriterSize(c.resp, streamBufferSize)
+	err := handler(writer)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		blog.ErrorfDepthf(1, "response stream failed, err: %v, rid: %s", err, c.Kit.Rid)
+		panic(http.ErrAbortHandler)
+	}
+}
+
 func (c *Contexts) RespEntityWithError(data interface{}, err error) {
 	if c.respStatusCode != 0 {
 		c.resp.WriteHeader(c.respStatusCode)
@@ -306,7 +341,7 @@ func (c *Contexts) RespErrorCodeOnly(errCode int, format string, args ...interfa
 	c.writeAsJson(&body)
 }
 
-func (c *Contexts) writeAsJson(resp *metadata.Response) {
+func (c *Contexts) writeAsJson(resp interface{}) {
 	body, err := json.Marshal(resp)
 	if err != nil {
 		blog.ErrorfDepthf(2, "marshal json response failed, err: %v, rid: %s", err, c.Kit.Rid)
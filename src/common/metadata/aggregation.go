@@ -0,0 +1,163 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/querybuilder"
+)
+
+const (
+	// AggregateGroupByMaxNum is the max number of the fields the instances can be grouped by
+	AggregateGroupByMaxNum = 3
+	// AggregateMetricMaxNum is the max number of the metrics calculated for each group
+	AggregateMetricMaxNum = 5
+	// AggregatePageMaxLimit is the max number of the groups returned at one time
+	AggregatePageMaxLimit = 500
+)
+
+// AggregateMetricType is the type of the metric calculated for the instances of a group
+type AggregateMetricType string
+
+const (
+	AggregateCount         AggregateMetricType = "count"
+	AggregateSum           AggregateMetricType = "sum"
+	AggregateMin           AggregateMetricType = "min"
+	AggregateMax           AggregateMetricType = "max"
+	AggregateDistinctCount AggregateMetricType = "distinct_count"
+)
+
+// AggregateMetric is a metric of the group, the field is not needed by the count metric
+type AggregateMetric struct {
+	Type  AggregateMetricType `json:"type"`
+	Field string              `json:"field"`
+}
+
+// Name is the key of the metric in the aggregate result, such as count, sum_bk_cpu
+func (m AggregateMetric) Name() string {
+	if m.Type == AggregateCount {
+		return string(AggregateCount)
+	}
+	return string(m.Type) + "_" + m.Field
+}
+
+// IsNumeric returns whether the metric can only be calculated with the numeric fields
+func (m AggregateMetric) IsNumeric() bool {
+	return m.Type == AggregateSum || m.Type == AggregateMin || m.Type == AggregateMax
+}
+
+func (m AggregateMetric) Validate() (string, error) {
+	switch m.Type {
+	case AggregateCount:
+		return "", nil
+	case AggregateSum, AggregateMin, AggregateMax, AggregateDistinctCount:
+		if len(m.Field) == 0 {
+			return "field", errors.New("field is not set")
+		}
+		return "", nil
+	default:
+		return "type", fmt.Errorf("invalid metric type %s", m.Type)
+	}
+}
+
+// AggregateOption groups the instances matched by the filter and calculates the metrics of each group,
+// the hosts can also be grouped by the topology fields bk_biz_id, bk_set_id and bk_module_id, then a host
+// is counted once in each of the topology nodes it belongs to.
+type AggregateOption struct {
+	// BizID limits the set, module and host instances to the business
+	BizID   int64                     `json:"bk_biz_id"`
+	Filter  *querybuilder.QueryFilter `json:"filter"`
+	GroupBy []string                  `json:"group_by"`
+	Metrics []AggregateMetric         `json:"metrics"`
+	// Page.Sort can be one of the group by fields or the metric names, such as -count
+	Page BasePage `json:"page"`
+}
+
+func (o *AggregateOption) Validate() (string, error) {
+	if o.Filter != nil && o.Filter.Rule != nil {
+		if key, err := o.Filter.Validate(); err != nil {
+			return "filter." + key, err
+		}
+		if o.Filter.GetDeep() > querybuilder.MaxDeep {
+			return "filter.rules", fmt.Errorf("exceed max query condition deepth: %d", querybuilder.MaxDeep)
+		}
+	}
+
+	if len(o.GroupBy) == 0 || len(o.GroupBy) > AggregateGroupByMaxNum {
+		return "group_by", fmt.Errorf("the number of group by fields should be in 1-%d", AggregateGroupByMaxNum)
+	}
+	keys := make(map[string]bool)
+	for _, field := range o.GroupBy {
+		if len(field) == 0 || keys[field] {
+			return "group_by", fmt.Errorf("group by field %s is empty or duplicated", field)
+		}
+		keys[field] = true
+	}
+
+	if len(o.Metrics) == 0 || len(o.Metrics) > AggregateMetricMaxNum {
+		return "metrics", fmt.Errorf("the number of metrics should be in 1-%d", AggregateMetricMaxNum)
+	}
+	for idx, metric := range o.Metrics {
+		if key, err := metric.Validate(); err != nil {
+			return fmt.Sprintf("metrics[%d].%s", idx, key), err
+		}
+		if keys[metric.Name()] {
+			return fmt.Sprintf("metrics[%d]", idx), fmt.Errorf("metric %s is duplicated", metric.Name())
+		}
+		keys[metric.Name()] = true
+	}
+
+	if o.Page.Limit <= 0 || o.Page.Limit > AggregatePageMaxLimit {
+		return "page.limit", fmt.Errorf("page limit should be in 1-%d", AggregatePageMaxLimit)
+	}
+	if len(o.Page.Sort) > 0 && !keys[strings.TrimPrefix(o.Page.Sort, "-")] {
+		return "page.sort", errors.New("sort should be one of the group by fields or the metric names")
+	}
+	return "", nil
+}
+
+// IsTopoField returns whether the field is a topology field of the object's instances which are not
+// the attributes of the object.
+func IsTopoField(objID string, field string) bool {
+	switch objID {
+	case common.BKInnerObjIDHost:
+		return field == common.BKAppIDField || field == common.BKSetIDField || field == common.BKModuleIDField
+	case common.BKInnerObjIDModule:
+		return field == common.BKAppIDField || field == common.BKSetIDField
+	case common.BKInnerObjIDSet:
+		return field == common.BKAppIDField
+	}
+	return false
+}
+
+// AggregateGroup is a group of the instances, the group contains the values of the group by fields
+// and the metrics contains the calculated metrics keyed by their names.
+type AggregateGroup struct {
+	Group   map[string]interface{} `json:"group" bson:"group"`
+	Metrics map[string]interface{} `json:"metrics" bson:"metrics"`
+}
+
+// AggregateResult is the paged groups, count is the total number of the groups
+type AggregateResult struct {
+	Count int64            `json:"count"`
+	Info  []AggregateGroup `json:"info"`
+}
+
+type AggregateResponse struct {
+	BaseResp `json:",inline"`
+	Data     AggregateResult `json:"data"`
+}
@@ -0,0 +1,126 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+)
+
+func TestAggregateOptionValidate(t *testing.T) {
+	count := AggregateMetric{Type: AggregateCount}
+	page := BasePage{Limit: 10}
+	tests := []struct {
+		name      string
+		option    AggregateOption
+		wantField string
+	}{
+		{
+			name:   "valid",
+			option: AggregateOption{GroupBy: []string{"bk_os_type"}, Metrics: []AggregateMetric{count}, Page: page},
+		},
+		{
+			name: "sort by group by field and metric",
+			option: AggregateOption{
+				GroupBy: []string{"bk_os_type"},
+				Metrics: []AggregateMetric{count, {Type: AggregateDistinctCount, Field: "bk_cloud_id"}},
+				Page:    BasePage{Limit: 10, Sort: "-distinct_count_bk_cloud_id"},
+			},
+		},
+		{
+			name:      "no group by field",
+			option:    AggregateOption{Metrics: []AggregateMetric{count}, Page: page},
+			wantField: "group_by",
+		},
+		{
+			name: "too many group by fields",
+			option: AggregateOption{GroupBy: []string{"a", "b", "c", "d"},
+				Metrics: []AggregateMetric{count}, Page: page},
+			wantField: "group_by",
+		},
+		{
+			name: "duplicated group by fields",
+			option: AggregateOption{GroupBy: []string{"bk_os_type", "bk_os_type"},
+				Metrics: []AggregateMetric{count}, Page: page},
+			wantField: "group_by",
+		},
+		{
+			name:      "no metric",
+			option:    AggregateOption{GroupBy: []string{"bk_os_type"}, Page: page},
+			wantField: "metrics",
+		},
+		{
+			name: "too many metrics",
+			option: AggregateOption{GroupBy: []string{"bk_os_type"}, Metrics: []AggregateMetric{count,
+				{Type: AggregateSum, Field: "a"}, {Type: AggregateSum, Field: "b"},
+				{Type: AggregateSum, Field: "c"}, {Type: AggregateSum, Field: "d"},
+				{Type: AggregateSum, Field: "e"}}, Page: page},
+			wantField: "metrics",
+		},
+		{
+			name: "duplicated metrics",
+			option: AggregateOption{GroupBy: []string{"bk_os_type"},
+				Metrics: []AggregateMetric{count, count}, Page: page},
+			wantField: "metrics[1]",
+		},
+		{
+			name: "metric name same as group by field",
+			option: AggregateOption{GroupBy: []string{"count"},
+				Metrics: []AggregateMetric{count}, Page: page},
+			wantField: "metrics[0]",
+		},
+		{
+			name: "metric without field",
+			option: AggregateOption{GroupBy: []string{"bk_os_type"},
+				Metrics: []AggregateMetric{{Type: AggregateSum}}, Page: page},
+			wantField: "metrics[0].field",
+		},
+		{
+			name: "invalid metric type",
+			option: AggregateOption{GroupBy: []string{"bk_os_type"},
+				Metrics: []AggregateMetric{{Type: "avg", Field: "bk_cpu"}}, Page: page},
+			wantField: "metrics[0].type",
+		},
+		{
+			name:      "no page limit",
+			option:    AggregateOption{GroupBy: []string{"bk_os_type"}, Metrics: []AggregateMetric{count}},
+			wantField: "page.limit",
+		},
+		{
+			name: "exceed page limit",
+			option: AggregateOption{GroupBy: []string{"bk_os_type"}, Metrics: []AggregateMetric{count},
+				Page: BasePage{Limit: AggregatePageMaxLimit + 1}},
+			wantField: "page.limit",
+		},
+		{
+			name: "sort by other field",
+			option: AggregateOption{GroupBy: []string{"bk_os_type"}, Metrics: []AggregateMetric{count},
+				Page: BasePage{Limit: 10, Sort: "bk_cpu"}},
+			wantField: "page.sort",
+		},
+		{
+			name: "sort by metric field instead of metric name",
+			option: AggregateOption{GroupBy: []string{"bk_os_type"},
+				Metrics: []AggregateMetric{{Type: AggregateSum, Field: "bk_cpu"}},
+				Page:    BasePage{Limit: 10, Sort: "-bk_cpu"}},
+			wantField: "page.sort",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, err := tt.option.Validate()
+			if field != tt.wantField || (err != nil) != (tt.wantField != "") {
+				t.Errorf("Validate() = %s, %v, want field %s", field, err, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+)
+
+// TraversalDirection defines which side of an instance association is followed when traversing.
+type TraversalDirection string
+
+const (
+	// TraversalDirectionOut follow the association from source instance to destination instance.
+	TraversalDirectionOut TraversalDirection = "out"
+	// TraversalDirectionIn follow the association from destination instance to source instance.
+	TraversalDirectionIn TraversalDirection = "in"
+	// TraversalDirectionBoth follow the association on both sides.
+	TraversalDirectionBoth TraversalDirection = "both"
+)
+
+const (
+	// TraversalMaxDepth the max hops can be traversed from the start instances.
+	TraversalMaxDepth = 10
+	// TraversalMaxStartNodes the max start instances of one traversal.
+	TraversalMaxStartNodes = 100
+	// TraversalMaxNodes the max instances can be visited by one traversal, traversal
+	// stops and the result is marked as truncated when it is exceeded.
+	TraversalMaxNodes = 10000
+)
+
+// TraversalInstNode is an instance identified by it's object id and instance id.
+type TraversalInstNode struct {
+	ObjectID string `json:"bk_obj_id"`
+	InstID   int64  `json:"bk_inst_id"`
+}
+
+func (n TraversalInstNode) String() string {
+	return fmt.Sprintf("%s:%d", n.ObjectID, n.InstID)
+}
+
+// TraversalHopFilter filter the instances which can be reached at a hop.
+type TraversalHopFilter struct {
+	// Depth is the hop this filter applies to, start from 1. 0 means it applies to all the hops.
+	Depth int `json:"depth"`
+	// Objects is the objects which can be reached at this hop, empty means all objects are allowed.
+	Objects []string `json:"bk_obj_ids"`
+	// Condition is the attribute condition of the instance reached at this hop, keyed by object id.
+	// instances of object which do not have a condition are not filtered by attributes.
+	Condition map[string]mapstr.MapStr `json:"condition"`
+}
+
+// InstAssociationTraversalRequest traverse the instance association graph from a set of start instances.
+type InstAssociationTraversalRequest struct {
+	Start []TraversalInstNode `json:"start"`
+	// End is optional, when it's set, the shortest path between the start instances and it is searched.
+	End *TraversalInstNode `json:"end"`
+	// AssociationKinds is the allowed association kind ids(bk_asst_id), empty means all kinds are allowed.
+	AssociationKinds []string             `json:"bk_asst_ids"`
+	Direction        TraversalDirection   `json:"direction"`
+	MaxDepth         int                  `json:"max_depth"`
+	Filters          []TraversalHopFilter `json:"filters"`
+	Page             BasePage             `json:"page"`
+}
+
+// Validate validate the traversal request and fill the default values,
+// returns the invalid field's name if the request is invalid.
+func (r *InstAssociationTraversalRequest) Validate() (string, error) {
+	if len(r.Start) == 0 {
+		return "start", fmt.Errorf("start instances is empty")
+	}
+	if len(r.Start) > TraversalMaxStartNodes {
+		return "start", fmt.Errorf("start instances exceed max count %d", TraversalMaxStartNodes)
+	}
+	for _, node := range r.Start {
+		if len(node.ObjectID) == 0 || node.InstID <= 0 {
+			return "start", fmt.Errorf("start instance %s is invalid", node)
+		}
+	}
+
+	if r.End != nil && (len(r.End.ObjectID) == 0 || r.End.InstID <= 0) {
+		return "end", fmt.Errorf("end instance %s is invalid", r.End)
+	}
+
+	switch r.Direction {
+	case "":
+		r.Direction = TraversalDirectionBoth
+	case TraversalDirectionOut, TraversalDirectionIn, TraversalDirectionBoth:
+	default:
+		return "direction", fmt.Errorf("unsupported direction %s", r.Direction)
+	}
+
+	if r.MaxDepth <= 0 || r.MaxDepth > TraversalMaxDepth {
+		return "max_depth", fmt.Errorf("max depth should be in range [1, %d]", TraversalMaxDepth)
+	}
+
+	for _, filter := range r.Filters {
+		if filter.Depth < 0 || filter.Depth > r.MaxDepth {
+			return "filters.depth", fmt.Errorf("filter depth %d exceeds max depth", filter.Depth)
+		}
+	}
+
+	if r.Page.Limit == 0 {
+		r.Page.Limit = common.BKDefaultLimit
+	}
+	if r.Page.IsIllegal() {
+		return "page.limit", fmt.Errorf("exceed max page size: %d", common.BKMaxPageSize)
+	}
+
+	return "", nil
+}
+
+// HopFilters returns the filters apply to the hop of the depth.
+func (r *InstAssociationTraversalRequest) HopFilters(depth int) []TraversalHopFilter {
+	filters := make([]TraversalHopFilter, 0)
+	for _, filter := range r.Filters {
+		if filter.Depth == 0 || filter.Depth == depth {
+			filters = append(filters, filter)
+		}
+	}
+	return filters
+}
+
+// TraversalNode is an instance visited by traversal.
+type TraversalNode struct {
+	TraversalInstNode `json:",inline"`
+	InstName          string `json:"bk_inst_name"`
+	// Depth is the hops from the nearest start instance to this instance.
+	Depth int `json:"depth"`
+}
+
+// TraversalEdge is an instance association followed by traversal.
+type TraversalEdge struct {
+	// ID is the instance association's id.
+	ID                int64             `json:"id"`
+	ObjectAsstID      string            `json:"bk_obj_asst_id"`
+	AssociationKindID string            `json:"bk_asst_id"`
+	Src               TraversalInstNode `json:"src"`
+	Dest              TraversalInstNode `json:"dest"`
+}
+
+// InstAssociationTraversalResult the result of instance association traversal. in shortest path
+// mode, nodes and edges are the path from the start instance to the end instance in order.
+type InstAssociationTraversalResult struct {
+	Count int             `json:"count"`
+	Nodes []TraversalNode `json:"nodes"`
+	// Edges is the associations between the returned nodes and the other visited instances.
+	Edges []TraversalEdge `json:"edges"`
+	// Truncated is set when the traversal stopped because too many instances are visited.
+	Truncated bool `json:"truncated"`
+}
+
+type InstAssociationTraversalResponse struct {
+	BaseResp `json:",inline"`
+	Data     InstAssociationTraversalResult `json:"data"`
+}
@@ -53,6 +53,7 @@ const (
 	AttributeFieldCreator         = "creator"
 	AttributeFieldCreateTime      = "create_time"
 	AttributeFieldLastTime        = "last_time"
+	AttributeFieldPermission      = "bk_permission"
 )
 
 // Attribute attribute metadata definition
@@ -83,6 +84,9 @@ type Attribute struct {
 	Creator    string `field:"creator" json:"creator" bson:"creator"`
 	CreateTime *Time  `json:"create_time" bson:"create_time"`
 	LastTime   *Time  `json:"last_time" bson:"last_time"`
+
+	// Permission is the attribute's read/write policy, nil means everyone can read and edit it.
+	Permission *AttributePermission `json:"bk_permission,omitempty" bson:"bk_permission,omitempty"`
 }
 
 // AttributeGroup attribute metadata definition
@@ -105,6 +109,13 @@ func (attribute *Attribute) Parse(data mapstr.MapStr) (*Attribute, error) {
 		return nil, err
 	}
 
+	if data.Exists(AttributeFieldPermission) {
+		attribute.Permission, err = ParseAttributePermission(data[AttributeFieldPermission])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return attribute, err
 }
 
@@ -151,6 +162,8 @@ func (attribute *Attribute) Validate(ctx context.Context, data interface{}, key
 		rawError = attribute.validList(ctx, data, key)
 	case common.FieldTypeOrganization:
 		rawError = attribute.validOrganization(ctx, data, key)
+	case common.FieldTypeExpression:
+		// the value is computed from the expression by the server, it's not set by the user.
 	case "foreignkey", "singleasst", "multiasst":
 		// TODO what validation should do on these types
 	default:
@@ -1011,6 +1024,8 @@ func (attribute Attribute) PrettyValue(ctx context.Context, val interface{}) (st
 			}
 		}
 		return "", fmt.Errorf("invalid value for list, value: %s, options: %+v", strVal, listOption)
+	case common.FieldTypeExpression:
+		return fmt.Sprintf("%v", val), nil
 	default:
 		blog.V(3).Infof("unexpected property type: %s", fieldType)
 		return fmt.Sprintf("%#v", val), nil
@@ -0,0 +1,352 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+)
+
+// FieldHiddenMode defines how an attribute's value is hidden from the user who has no read permission.
+type FieldHiddenMode string
+
+const (
+	// FieldHiddenModeStrip remove the field from the instance.
+	FieldHiddenModeStrip FieldHiddenMode = "strip"
+	// FieldHiddenModeMask keep the field but replace it's value with FieldMaskValue.
+	FieldHiddenModeMask FieldHiddenMode = "mask"
+)
+
+// FieldMaskValue the value of a masked field.
+const FieldMaskValue = "******"
+
+// AttributePermission is the read/write policy of an attribute, it is granted to permission roles.
+type AttributePermission struct {
+	// ReadRoles is the roles who can read the attribute's value, empty means everyone can read it.
+	ReadRoles []string `json:"read_roles" bson:"read_roles" mapstructure:"read_roles"`
+	// WriteRoles is the roles who can edit the attribute's value, empty means everyone can edit it.
+	WriteRoles []string `json:"write_roles" bson:"write_roles" mapstructure:"write_roles"`
+	// HiddenMode is how the value is hidden from the user who can not read it, default is strip.
+	HiddenMode FieldHiddenMode `json:"hidden_mode" bson:"hidden_mode" mapstructure:"hidden_mode"`
+}
+
+// ParseAttributePermission parse the attribute permission from the data of an attribute's bk_permission field.
+func ParseAttributePermission(data interface{}) (*AttributePermission, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	js, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	permission := new(AttributePermission)
+	if err := json.Unmarshal(js, permission); err != nil {
+		return nil, err
+	}
+	return permission, nil
+}
+
+// Validate validate the attribute permission and fill the default values.
+func (p *AttributePermission) Validate() error {
+	switch p.HiddenMode {
+	case "":
+		p.HiddenMode = FieldHiddenModeStrip
+	case FieldHiddenModeStrip, FieldHiddenModeMask:
+	default:
+		return fmt.Errorf("unsupported hidden mode %s", p.HiddenMode)
+	}
+
+	for _, role := range append(p.ReadRoles, p.WriteRoles...) {
+		if len(role) == 0 {
+			return errors.New("role name can not be empty")
+		}
+	}
+	return nil
+}
+
+// CanRead check if the user with these roles can read the attribute.
+func (p *AttributePermission) CanRead(roles []string) bool {
+	if p == nil || len(p.ReadRoles) == 0 {
+		return true
+	}
+	return hasAnyRole(p.ReadRoles, roles)
+}
+
+// CanWrite check if the user with these roles can edit the attribute,
+// the user who can not read the attribute can not edit it either.
+func (p *AttributePermission) CanWrite(roles []string) bool {
+	if !p.CanRead(roles) {
+		return false
+	}
+	if p == nil || len(p.WriteRoles) == 0 {
+		return true
+	}
+	return hasAnyRole(p.WriteRoles, roles)
+}
+
+func hasAnyRole(granted []string, roles []string) bool {
+	for _, role := range roles {
+		if util.InStrArr(granted, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// ObjectFieldPermission is the field permissions of an object's attributes for a user.
+// a nil ObjectFieldPermission means the user has all the field permissions.
+type ObjectFieldPermission struct {
+	ObjectID string
+	// Roles is the permission roles of the user.
+	Roles []string
+	// Permissions is the attributes' permissions keyed by property id,
+	// attributes without permission are not included.
+	Permissions map[string]*AttributePermission
+}
+
+// NewObjectFieldPermission generate the field permissions of the object for the user with these roles.
+func NewObjectFieldPermission(objID string, attrs []Attribute, roles []string) *ObjectFieldPermission {
+	permission := &ObjectFieldPermission{
+		ObjectID:    objID,
+		Roles:       roles,
+		Permissions: make(map[string]*AttributePermission),
+	}
+	for idx := range attrs {
+		if attrs[idx].Permission == nil {
+			continue
+		}
+		permission.Permissions[attrs[idx].PropertyID] = attrs[idx].Permission
+	}
+	return permission
+}
+
+// IsEmpty returns true if none of the object's attributes has permission policy.
+func (o *ObjectFieldPermission) IsEmpty() bool {
+	return o == nil || len(o.Permissions) == 0
+}
+
+// CanRead check if the user can read the field.
+func (o *ObjectFieldPermission) CanRead(field string) bool {
+	if o.IsEmpty() {
+		return true
+	}
+	return o.Permissions[field].CanRead(o.Roles)
+}
+
+// CanWrite check if the user can edit the field.
+func (o *ObjectFieldPermission) CanWrite(field string) bool {
+	if o.IsEmpty() {
+		return true
+	}
+	return o.Permissions[field].CanWrite(o.Roles)
+}
+
+// FilterData strip or mask the fields in the data which can not be read by the user.
+func (o *ObjectFieldPermission) FilterData(data mapstr.MapStr) {
+	if o.IsEmpty() || data == nil {
+		return
+	}
+
+	for field, permission := range o.Permissions {
+		if _, exists := data[field]; !exists || permission.CanRead(o.Roles) {
+			continue
+		}
+		if permission.HiddenMode == FieldHiddenModeMask {
+			data[field] = FieldMaskValue
+			continue
+		}
+		delete(data, field)
+	}
+}
+
+// UnwritableFields returns the sorted fields in the data which can not be edited by the user.
+func (o *ObjectFieldPermission) UnwritableFields(data mapstr.MapStr) []string {
+	fields := make([]string, 0)
+	if o.IsEmpty() {
+		return fields
+	}
+
+	for field := range data {
+		if !o.CanWrite(field) {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// UnreadableFields returns the sorted fields which can not be read by the user.
+func (o *ObjectFieldPermission) UnreadableFields(fields []string) []string {
+	unreadable := make([]string, 0)
+	if o.IsEmpty() {
+		return unreadable
+	}
+
+	for _, field := range util.StrArrayUnique(fields) {
+		if !o.CanRead(field) {
+			unreadable = append(unreadable, field)
+		}
+	}
+	sort.Strings(unreadable)
+	return unreadable
+}
+
+// UnreadableConditionFields returns the sorted fields which can not be read by the user in the query condition
+// and the sort, so that the user can not find out the hidden values by filtering or sorting the instances on
+// them. the operators which can refer to any field, such as $where, are unreadable if any field is hidden.
+func (o *ObjectFieldPermission) UnreadableConditionFields(cond interface{}, sortStr string) []string {
+	unreadable := make([]string, 0)
+	if o.IsEmpty() {
+		return unreadable
+	}
+
+	hasUnreadable := false
+	for _, permission := range o.Permissions {
+		if !permission.CanRead(o.Roles) {
+			hasUnreadable = true
+			break
+		}
+	}
+	if !hasUnreadable {
+		return unreadable
+	}
+
+	for _, field := range util.StrArrayUnique(append(ConditionFields(cond), SortFields(sortStr)...)) {
+		if strings.HasPrefix(field, "$") || !o.CanRead(field) {
+			unreadable = append(unreadable, field)
+		}
+	}
+	sort.Strings(unreadable)
+	return unreadable
+}
+
+// ConditionFields returns the fields the mongodb style query condition filters on, the logical operators are
+// expanded, and the sub field like labels.env is taken as it's top level field labels. the other top level
+// operators are returned as they are, since the fields they refer to can not be parsed.
+func ConditionFields(cond interface{}) []string {
+	fields := make([]string, 0)
+	switch value := cond.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			switch key {
+			case common.BKDBAND, common.BKDBOR, common.BKDBNOR:
+				fields = append(fields, ConditionFields(item)...)
+			default:
+				fields = append(fields, strings.SplitN(key, ".", 2)[0])
+			}
+		}
+	case mapstr.MapStr:
+		fields = append(fields, ConditionFields(map[string]interface{}(value))...)
+	case []interface{}:
+		for _, item := range value {
+			fields = append(fields, ConditionFields(item)...)
+		}
+	case []map[string]interface{}:
+		for _, item := range value {
+			fields = append(fields, ConditionFields(item)...)
+		}
+	case []mapstr.MapStr:
+		for _, item := range value {
+			fields = append(fields, ConditionFields(map[string]interface{}(item))...)
+		}
+	}
+	return fields
+}
+
+// SortFields returns the fields of the sort like "-bk_inst_name,bk_inst_id:1".
+func SortFields(sort string) []string {
+	fields := make([]string, 0)
+	for _, item := range strings.Split(sort, common.BKDBSortFieldSep) {
+		field := strings.TrimLeft(strings.TrimSpace(strings.Split(item, ":")[0]), "+-")
+		if len(field) != 0 {
+			fields = append(fields, strings.SplitN(field, ".", 2)[0])
+		}
+	}
+	return fields
+}
+
+// PermissionRole is a group of users who are granted attributes' read or write permission.
+type PermissionRole struct {
+	ID          int64    `json:"id" bson:"id" mapstructure:"id"`
+	Name        string   `json:"bk_role_name" bson:"bk_role_name" mapstructure:"bk_role_name"`
+	Members     []string `json:"members" bson:"members" mapstructure:"members"`
+	Description string   `json:"description" bson:"description" mapstructure:"description"`
+
+	Creator         string    `json:"creator" bson:"creator" mapstructure:"creator"`
+	Modifier        string    `json:"modifier" bson:"modifier" mapstructure:"modifier"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time" mapstructure:"create_time"`
+	LastTime        time.Time `json:"last_time" bson:"last_time" mapstructure:"last_time"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account" mapstructure:"bk_supplier_account"`
+}
+
+// Validate validate the permission role, returns the invalid field's name if it's invalid.
+func (r *PermissionRole) Validate() (string, error) {
+	if len(r.Name) == 0 {
+		return common.BKRoleNameField, errors.New("role name can not be empty")
+	}
+	if len(r.Name) > common.NameFieldMaxLength {
+		return common.BKRoleNameField, fmt.Errorf("role name exceeds max length %d", common.NameFieldMaxLength)
+	}
+	for _, member := range r.Members {
+		if len(member) == 0 {
+			return common.BKMembersField, errors.New("member can not be empty")
+		}
+	}
+	return "", nil
+}
+
+type CreatePermissionRoleOption struct {
+	Name        string   `json:"bk_role_name" mapstructure:"bk_role_name"`
+	Members     []string `json:"members" mapstructure:"members"`
+	Description string   `json:"description" mapstructure:"description"`
+}
+
+type UpdatePermissionRoleOption struct {
+	Members     []string `json:"members" mapstructure:"members"`
+	Description *string  `json:"description" mapstructure:"description"`
+}
+
+type DeletePermissionRoleOption struct {
+	RoleIDs []int64 `json:"ids" mapstructure:"ids"`
+}
+
+type ListPermissionRoleOption struct {
+	Names []string `json:"bk_role_names" mapstructure:"bk_role_names"`
+	// Member list the roles which the member belongs to.
+	Member string   `json:"member" mapstructure:"member"`
+	Page   BasePage `json:"page" mapstructure:"page"`
+}
+
+type MultiplePermissionRole struct {
+	Count int64            `json:"count" mapstructure:"count"`
+	Info  []PermissionRole `json:"info" mapstructure:"info"`
+}
+
+type PermissionRoleResult struct {
+	BaseResp `json:",inline"`
+	Data     PermissionRole `json:"data"`
+}
+
+type MultiplePermissionRoleResult struct {
+	BaseResp `json:",inline"`
+	Data     MultiplePermissionRole `json:"data"`
+}
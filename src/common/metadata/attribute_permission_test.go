@@ -0,0 +1,252 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"configcenter/src/common/mapstr"
+)
+
+// newTestFieldPermission returns the host field permission of the user with the roles, the password can only be
+// read by the admin, the asset id can be read by everyone but only be edited by the admin, and the comment is
+// masked for the users who are not operators.
+func newTestFieldPermission(roles ...string) *ObjectFieldPermission {
+	attrs := []Attribute{
+		{PropertyID: "bk_host_name"},
+		{PropertyID: "password", Permission: &AttributePermission{ReadRoles: []string{"admin"}}},
+		{PropertyID: "bk_asset_id", Permission: &AttributePermission{WriteRoles: []string{"admin"}}},
+		{PropertyID: "bk_comment", Permission: &AttributePermission{ReadRoles: []string{"admin", "operator"},
+			HiddenMode: FieldHiddenModeMask}},
+	}
+	return NewObjectFieldPermission("host", attrs, roles)
+}
+
+func TestAttributePermissionValidate(t *testing.T) {
+	permission := &AttributePermission{ReadRoles: []string{"admin"}}
+	if err := permission.Validate(); err != nil {
+		t.Fatalf("permission should be valid, err: %v", err)
+	}
+	if permission.HiddenMode != FieldHiddenModeStrip {
+		t.Errorf("hidden mode should be default to %s, got %s", FieldHiddenModeStrip, permission.HiddenMode)
+	}
+
+	invalids := []*AttributePermission{
+		{HiddenMode: "hash"},
+		{ReadRoles: []string{""}},
+		{WriteRoles: []string{"admin", ""}},
+	}
+	for _, p := range invalids {
+		if err := p.Validate(); err == nil {
+			t.Errorf("permission should be invalid, %#v", p)
+		}
+	}
+}
+
+func TestObjectFieldPermissionCanReadWrite(t *testing.T) {
+	tests := []struct {
+		roles    []string
+		field    string
+		canRead  bool
+		canWrite bool
+	}{
+		{roles: nil, field: "bk_host_name", canRead: true, canWrite: true},
+		{roles: nil, field: "not_exist", canRead: true, canWrite: true},
+		{roles: nil, field: "password", canRead: false, canWrite: false},
+		{roles: []string{"admin"}, field: "password", canRead: true, canWrite: true},
+		{roles: []string{"operator"}, field: "bk_asset_id", canRead: true, canWrite: false},
+		{roles: []string{"operator", "admin"}, field: "bk_asset_id", canRead: true, canWrite: true},
+		{roles: []string{"operator"}, field: "bk_comment", canRead: true, canWrite: true},
+		{roles: []string{"guest"}, field: "bk_comment", canRead: false, canWrite: false},
+	}
+
+	for _, test := range tests {
+		permission := newTestFieldPermission(test.roles...)
+		if permission.CanRead(test.field) != test.canRead {
+			t.Errorf("roles %v read field %s should be %v", test.roles, test.field, test.canRead)
+		}
+		if permission.CanWrite(test.field) != test.canWrite {
+			t.Errorf("roles %v write field %s should be %v", test.roles, test.field, test.canWrite)
+		}
+	}
+
+	var empty *ObjectFieldPermission
+	if !empty.IsEmpty() || !empty.CanRead("password") || !empty.CanWrite("password") {
+		t.Errorf("nil field permission should grant all the fields")
+	}
+}
+
+func TestObjectFieldPermissionFilterData(t *testing.T) {
+	tests := []struct {
+		roles  []string
+		expect mapstr.MapStr
+	}{
+		{
+			roles:  nil,
+			expect: mapstr.MapStr{"bk_host_name": "host1", "bk_asset_id": "a1", "bk_comment": FieldMaskValue},
+		},
+		{
+			roles:  []string{"operator"},
+			expect: mapstr.MapStr{"bk_host_name": "host1", "bk_asset_id": "a1", "bk_comment": "c1"},
+		},
+		{
+			roles: []string{"admin"},
+			expect: mapstr.MapStr{"bk_host_name": "host1", "password": "p1", "bk_asset_id": "a1",
+				"bk_comment": "c1"},
+		},
+	}
+
+	for _, test := range tests {
+		data := mapstr.MapStr{"bk_host_name": "host1", "password": "p1", "bk_asset_id": "a1", "bk_comment": "c1"}
+		newTestFieldPermission(test.roles...).FilterData(data)
+		if !reflect.DeepEqual(data, test.expect) {
+			t.Errorf("roles %v filter data should be %v, got %v", test.roles, test.expect, data)
+		}
+	}
+
+	// the hidden fields which are not in the data are not added as masked.
+	data := mapstr.MapStr{"bk_host_name": "host1"}
+	newTestFieldPermission().FilterData(data)
+	if !reflect.DeepEqual(data, mapstr.MapStr{"bk_host_name": "host1"}) {
+		t.Errorf("absent fields should not be added, got %v", data)
+	}
+}
+
+func TestObjectFieldPermissionUnwritableFields(t *testing.T) {
+	data := mapstr.MapStr{"bk_host_name": "host1", "password": "p1", "bk_asset_id": "a1", "bk_comment": "c1"}
+	tests := []struct {
+		roles  []string
+		expect []string
+	}{
+		{roles: nil, expect: []string{"bk_asset_id", "bk_comment", "password"}},
+		{roles: []string{"operator"}, expect: []string{"bk_asset_id", "password"}},
+		{roles: []string{"admin"}, expect: []string{}},
+	}
+
+	for _, test := range tests {
+		fields := newTestFieldPermission(test.roles...).UnwritableFields(data)
+		if !reflect.DeepEqual(fields, test.expect) {
+			t.Errorf("roles %v unwritable fields should be %v, got %v", test.roles, test.expect, fields)
+		}
+	}
+
+	// an update which only contains the writable fields is allowed.
+	fields := newTestFieldPermission("operator").UnwritableFields(mapstr.MapStr{"bk_host_name": "h", "bk_comment": "c"})
+	if len(fields) != 0 {
+		t.Errorf("the writable fields should be allowed, got %v", fields)
+	}
+}
+
+func TestObjectFieldPermissionUnreadableFields(t *testing.T) {
+	fields := newTestFieldPermission().UnreadableFields([]string{"password", "bk_host_name", "bk_comment", "password"})
+	if !reflect.DeepEqual(fields, []string{"bk_comment", "password"}) {
+		t.Errorf("unexpected unreadable fields %v", fields)
+	}
+}
+
+func TestConditionFields(t *testing.T) {
+	tests := []struct {
+		cond   interface{}
+		expect []string
+	}{
+		{cond: nil, expect: []string{}},
+		{cond: map[string]interface{}{"bk_host_name": "h1", "labels.env": "prod"},
+			expect: []string{"bk_host_name", "labels"}},
+		{cond: mapstr.MapStr{"$and": []interface{}{
+			map[string]interface{}{"password": map[string]interface{}{"$regex": "^a"}},
+			mapstr.MapStr{"$or": []map[string]interface{}{{"bk_comment": "c"}, {"$nor": []mapstr.MapStr{{"bk_asset_id": 1}}}}},
+		}}, expect: []string{"bk_asset_id", "bk_comment", "password"}},
+		{cond: map[string]interface{}{"$where": "this.password == 'a'"}, expect: []string{"$where"}},
+	}
+
+	for _, test := range tests {
+		fields := ConditionFields(test.cond)
+		sort.Strings(fields)
+		if !reflect.DeepEqual(fields, test.expect) {
+			t.Errorf("condition %v fields should be %v, got %v", test.cond, test.expect, fields)
+		}
+	}
+}
+
+func TestSortFields(t *testing.T) {
+	tests := []struct {
+		sort   string
+		expect []string
+	}{
+		{sort: "", expect: []string{}},
+		{sort: "bk_host_id", expect: []string{"bk_host_id"}},
+		{sort: "-password,bk_host_id:1, +labels.env", expect: []string{"password", "bk_host_id", "labels"}},
+	}
+
+	for _, test := range tests {
+		if fields := SortFields(test.sort); !reflect.DeepEqual(fields, test.expect) {
+			t.Errorf("sort %s fields should be %v, got %v", test.sort, test.expect, fields)
+		}
+	}
+}
+
+func TestObjectFieldPermissionUnreadableConditionFields(t *testing.T) {
+	tests := []struct {
+		roles  []string
+		cond   interface{}
+		sort   string
+		expect []string
+	}{
+		{
+			roles:  nil,
+			cond:   map[string]interface{}{"bk_host_name": "h1"},
+			sort:   "bk_host_id",
+			expect: []string{},
+		},
+		{
+			roles:  nil,
+			cond:   map[string]interface{}{"$or": []interface{}{map[string]interface{}{"password": "p1"}}},
+			expect: []string{"password"},
+		},
+		{
+			roles:  nil,
+			cond:   map[string]interface{}{"bk_host_name": "h1"},
+			sort:   "-bk_comment",
+			expect: []string{"bk_comment"},
+		},
+		{
+			roles:  nil,
+			cond:   map[string]interface{}{"$where": "this.password == 'a'"},
+			expect: []string{"$where"},
+		},
+		{
+			// the operator can read the comment, but not the password.
+			roles:  []string{"operator"},
+			cond:   map[string]interface{}{"bk_comment": "c1", "bk_asset_id": "a1"},
+			sort:   "password",
+			expect: []string{"password"},
+		},
+		{
+			// the admin can read all the fields, so even the opaque operators are allowed.
+			roles:  []string{"admin"},
+			cond:   map[string]interface{}{"password": "p1", "$where": "this.password == 'a'"},
+			sort:   "bk_comment",
+			expect: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		fields := newTestFieldPermission(test.roles...).UnreadableConditionFields(test.cond, test.sort)
+		if !reflect.DeepEqual(fields, test.expect) {
+			t.Errorf("roles %v condition %v sort %s unreadable fields should be %v, got %v", test.roles, test.cond,
+				test.sort, test.expect, fields)
+		}
+	}
+}
@@ -377,6 +377,9 @@ const (
 	// - cloud synchronize job
 	// - others
 	CloudResourceType AuditType = "cloud_resource"
+
+	// QuotaType represent the operation audit of the resource quotas.
+	QuotaType AuditType = "quota"
 )
 
 type ResourceType string
@@ -412,6 +415,9 @@ const (
 
 	// host related operation type
 	HostRes ResourceType = "host"
+
+	// quota related operation type
+	QuotaRes ResourceType = "quota"
 )
 
 type OperateFromType string
@@ -425,6 +431,8 @@ const (
 	FromDataCollection OperateFromType = "data_collection"
 	// FromSynchronizer means this audit is created by the data synchronizer.
 	FromSynchronizer OperateFromType = "synchronizer"
+	// FromHostApply means this audit is created by host apply rules which correct the drifted host attributes.
+	FromHostApply OperateFromType = "host_apply"
 )
 
 // ActionType defines all the user's operation type
@@ -0,0 +1,158 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"time"
+
+	"configcenter/src/common/mapstr"
+)
+
+// BusinessArchiveStatus is the status of a business archive.
+type BusinessArchiveStatus string
+
+const (
+	// BusinessArchived the business is archived, and it can be restored with the archive.
+	BusinessArchived BusinessArchiveStatus = "archived"
+	// BusinessRestored the business is already restored with the archive.
+	BusinessRestored BusinessArchiveStatus = "restored"
+)
+
+// BusinessArchive is the snapshot of a business's topology and host relations when it's archived.
+type BusinessArchive struct {
+	ID    int64 `json:"id" bson:"id" mapstructure:"id"`
+	BizID int64 `json:"bk_biz_id" bson:"bk_biz_id" mapstructure:"bk_biz_id"`
+	// BizName is the business's name before it's archived, ArchivedName is the name after it's archived.
+	BizName      string                `json:"bk_biz_name" bson:"bk_biz_name" mapstructure:"bk_biz_name"`
+	ArchivedName string                `json:"archived_name" bson:"archived_name" mapstructure:"archived_name"`
+	Status       BusinessArchiveStatus `json:"status" bson:"status" mapstructure:"status"`
+
+	// Topology is the mainline instances under the business, parents are always in front of their children.
+	Topology         []ArchivedTopoInstance    `json:"topology" bson:"topology" mapstructure:"topology"`
+	ServiceInstances []ArchivedServiceInstance `json:"service_instances" bson:"service_instances" mapstructure:"service_instances"`
+	HostApplyRules   []HostApplyRule           `json:"host_apply_rules" bson:"host_apply_rules" mapstructure:"host_apply_rules"`
+	HostRelations    []ArchivedHostRelation    `json:"host_relations" bson:"host_relations" mapstructure:"host_relations"`
+
+	// RestoreReport is the result of the restoration, it's set when the business is restored.
+	RestoreReport *BusinessRestoreReport `json:"restore_report,omitempty" bson:"restore_report,omitempty" mapstructure:"restore_report"`
+
+	Operator        string    `json:"operator" bson:"operator" mapstructure:"operator"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time" mapstructure:"create_time"`
+	LastTime        time.Time `json:"last_time" bson:"last_time" mapstructure:"last_time"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account" mapstructure:"bk_supplier_account"`
+}
+
+// ArchivedTopoInstance is an archived mainline instance, such as set, module and custom level instance.
+type ArchivedTopoInstance struct {
+	ObjectID string `json:"bk_obj_id" bson:"bk_obj_id" mapstructure:"bk_obj_id"`
+	InstID   int64  `json:"bk_inst_id" bson:"bk_inst_id" mapstructure:"bk_inst_id"`
+	// ParentObjectID is the object id of the parent instance, it's the business object for the topmost instances.
+	ParentObjectID string `json:"bk_parent_obj_id" bson:"bk_parent_obj_id" mapstructure:"bk_parent_obj_id"`
+	ParentID       int64  `json:"bk_parent_id" bson:"bk_parent_id" mapstructure:"bk_parent_id"`
+	// Default is the instance's default flag, the built in set and modules are not deleted when archived.
+	Default int64         `json:"default" bson:"default" mapstructure:"default"`
+	Data    mapstr.MapStr `json:"data" bson:"data" mapstructure:"data"`
+}
+
+// ArchivedServiceInstance is an archived service instance with it's processes.
+type ArchivedServiceInstance struct {
+	ServiceInstance `json:",inline" bson:",inline" mapstructure:",squash"`
+	Processes       []ArchivedProcess `json:"processes" bson:"processes" mapstructure:"processes"`
+}
+
+// ArchivedProcess is an archived process, the processes created by template are matched by the template id when restored.
+type ArchivedProcess struct {
+	ProcessTemplateID int64         `json:"process_template_id" bson:"process_template_id" mapstructure:"process_template_id"`
+	Data              mapstr.MapStr `json:"data" bson:"data" mapstructure:"data"`
+}
+
+// ArchivedHostRelation is the modules a host belongs to when the business is archived.
+type ArchivedHostRelation struct {
+	HostID    int64   `json:"bk_host_id" bson:"bk_host_id" mapstructure:"bk_host_id"`
+	ModuleIDs []int64 `json:"bk_module_ids" bson:"bk_module_ids" mapstructure:"bk_module_ids"`
+}
+
+// RestoredIDMapping is the id of the recreated instance of an archived instance.
+type RestoredIDMapping struct {
+	ObjectID  string `json:"bk_obj_id" bson:"bk_obj_id" mapstructure:"bk_obj_id"`
+	OriginID  int64  `json:"origin_id" bson:"origin_id" mapstructure:"origin_id"`
+	CurrentID int64  `json:"current_id" bson:"current_id" mapstructure:"current_id"`
+}
+
+// RestoreConflict is an archived resource which can not be restored as it is, ObjectID is the object id of
+// the mainline instances and hosts, or one of the RestoreResource* of other resources.
+type RestoreConflict struct {
+	ObjectID string `json:"bk_obj_id" bson:"bk_obj_id" mapstructure:"bk_obj_id"`
+	OriginID int64  `json:"origin_id" bson:"origin_id" mapstructure:"origin_id"`
+	Name     string `json:"name" bson:"name" mapstructure:"name"`
+	Reason   string `json:"reason" bson:"reason" mapstructure:"reason"`
+}
+
+const (
+	RestoreResourceServiceInstance = "service_instance"
+	RestoreResourceHostApplyRule   = "host_apply_rule"
+)
+
+// BusinessRestoreReport is the result of restoring a business with an archive.
+type BusinessRestoreReport struct {
+	ArchiveID  int64               `json:"archive_id" bson:"archive_id" mapstructure:"archive_id"`
+	BizID      int64               `json:"bk_biz_id" bson:"bk_biz_id" mapstructure:"bk_biz_id"`
+	BizName    string              `json:"bk_biz_name" bson:"bk_biz_name" mapstructure:"bk_biz_name"`
+	IDMappings []RestoredIDMapping `json:"id_mappings" bson:"id_mappings" mapstructure:"id_mappings"`
+	Conflicts  []RestoreConflict   `json:"conflicts" bson:"conflicts" mapstructure:"conflicts"`
+}
+
+// AddConflict record an archived resource which can not be restored.
+func (r *BusinessRestoreReport) AddConflict(objID string, originID int64, name string, reason string) {
+	r.Conflicts = append(r.Conflicts, RestoreConflict{
+		ObjectID: objID,
+		OriginID: originID,
+		Name:     name,
+		Reason:   reason,
+	})
+}
+
+// RestoreBusinessOption is the option to restore an archived business, the latest archive is used if
+// the ArchiveID is not set, and the business's original name is used if the BizName is not set.
+type RestoreBusinessOption struct {
+	ArchiveID int64  `json:"archive_id" mapstructure:"archive_id"`
+	BizName   string `json:"bk_biz_name" mapstructure:"bk_biz_name"`
+}
+
+type ListBusinessArchiveOption struct {
+	IDs    []int64                 `json:"ids" mapstructure:"ids"`
+	BizIDs []int64                 `json:"bk_biz_ids" mapstructure:"bk_biz_ids"`
+	Status []BusinessArchiveStatus `json:"status" mapstructure:"status"`
+	Page   BasePage                `json:"page" mapstructure:"page"`
+}
+
+// UpdateBusinessArchiveOption mark the business archive as restored with the restore report.
+type UpdateBusinessArchiveOption struct {
+	Status        BusinessArchiveStatus  `json:"status" mapstructure:"status"`
+	RestoreReport *BusinessRestoreReport `json:"restore_report" mapstructure:"restore_report"`
+}
+
+type MultipleBusinessArchive struct {
+	Count int64             `json:"count" mapstructure:"count"`
+	Info  []BusinessArchive `json:"info" mapstructure:"info"`
+}
+
+type BusinessArchiveResult struct {
+	BaseResp `json:",inline"`
+	Data     BusinessArchive `json:"data"`
+}
+
+type MultipleBusinessArchiveResult struct {
+	BaseResp `json:",inline"`
+	Data     MultipleBusinessArchive `json:"data"`
+}
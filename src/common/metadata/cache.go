@@ -12,6 +12,13 @@
 
 package metadata
 
+import (
+	"errors"
+	"fmt"
+
+	"configcenter/src/common/util"
+)
+
 type SearchHostWithInnerIPOption struct {
 	InnerIP string `json:"bk_host_innerip"`
 	CloudID int64  `json:"bk_cloud_id"`
@@ -36,3 +43,84 @@ type DeleteArchive struct {
 	Oid    string      `json:"oid" bson:"oid"`
 	Detail interface{} `json:"detail" bson:"detail"`
 }
+
+type SearchInstWithIDOption struct {
+	ObjID  string `json:"bk_obj_id"`
+	InstID int64  `json:"bk_inst_id"`
+	// only return these fields in instance.
+	Fields []string `json:"fields"`
+}
+
+type SearchInstWithUniqueKeyOption struct {
+	ObjID string `json:"bk_obj_id"`
+	// Keys is the instance's value of the properties of one of the object's unique rules,
+	// the properties must be the same with the unique rule.
+	Keys map[string]interface{} `json:"keys"`
+	// only return these fields in instance.
+	Fields []string `json:"fields"`
+}
+
+// the cache resources which can be checked with the db.
+const (
+	CacheResourceHost     = "host"
+	CacheResourceHostIP   = "host_ip"
+	CacheResourceBiz      = "biz"
+	CacheResourceSet      = "set"
+	CacheResourceModule   = "module"
+	CacheResourceTopology = "topology"
+)
+
+// CacheResources is all the cache resources which can be checked.
+var CacheResources = []string{CacheResourceHost, CacheResourceHostIP, CacheResourceBiz, CacheResourceSet,
+	CacheResourceModule, CacheResourceTopology}
+
+// the reasons why the cached key is not consistent with the db.
+const (
+	// the data of the key has already been deleted in db.
+	CacheMismatchNotInDB = "not_in_db"
+	// the cached value is different from the data in db.
+	CacheMismatchDiffer = "differ"
+	// the key or the cached value can not be parsed.
+	CacheMismatchInvalid = "invalid"
+)
+
+// CheckCacheMaxDetails is the max count of the mismatched keys returned for each resource.
+const CheckCacheMaxDetails = 100
+
+type CheckCacheOption struct {
+	// resources to be checked, check all the resources if not set.
+	Resources []string `json:"resources"`
+	// SampleSize is the max count of keys checked for each resource, scan all the keys if it's 0.
+	SampleSize int64 `json:"sample_size"`
+	// Repair rewrites the mismatched keys with the data in db, or deletes them if the data not exist.
+	Repair bool `json:"repair"`
+}
+
+func (o CheckCacheOption) Validate() (string, error) {
+	for _, resource := range o.Resources {
+		if !util.InStrArr(CacheResources, resource) {
+			return "resources", fmt.Errorf("unsupported resource %s", resource)
+		}
+	}
+	if o.SampleSize < 0 {
+		return "sample_size", errors.New("sample size can not be negative")
+	}
+	return "", nil
+}
+
+type CacheMismatch struct {
+	Key      string `json:"key"`
+	Reason   string `json:"reason"`
+	Repaired bool   `json:"repaired"`
+}
+
+type CheckCacheResult struct {
+	Resource   string `json:"resource"`
+	Checked    int64  `json:"checked"`
+	Mismatched int64  `json:"mismatched"`
+	Repaired   int64  `json:"repaired"`
+	// mismatched count of each reason
+	Reasons map[string]int64 `json:"reasons"`
+	// at most CheckCacheMaxDetails mismatched keys
+	Details []CacheMismatch `json:"details"`
+}
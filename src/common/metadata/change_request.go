@@ -0,0 +1,176 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"errors"
+	"time"
+
+	"configcenter/src/common"
+)
+
+// ChangeRequestStatus is the status of a change request.
+type ChangeRequestStatus string
+
+const (
+	// ChangeRequestPending the change request is waiting for approval.
+	ChangeRequestPending ChangeRequestStatus = "pending"
+	// ChangeRequestExecuting the change request is approved and it's operation is being executed.
+	ChangeRequestExecuting ChangeRequestStatus = "executing"
+	// ChangeRequestExecuted the change request is approved and it's operation is executed successfully.
+	ChangeRequestExecuted ChangeRequestStatus = "executed"
+	// ChangeRequestFailed the change request is approved but it's operation is failed.
+	ChangeRequestFailed ChangeRequestStatus = "failed"
+	// ChangeRequestRejected the change request is rejected by the approver.
+	ChangeRequestRejected ChangeRequestStatus = "rejected"
+	// ChangeRequestExpired the change request is not approved before it's expire time.
+	ChangeRequestExpired ChangeRequestStatus = "expired"
+)
+
+// ChangeRequestEventType is the event object type of change requests, users can subscribe the change request
+// events through event subscription with the types change_requestcreate and change_requestupdate.
+const ChangeRequestEventType = "change_request"
+
+// ChangeRequestHeaders is the request headers which are saved in the change request, and used when the request is executed.
+var ChangeRequestHeaders = []string{
+	common.BKHTTPHeaderUser,
+	common.BKHTTPOwnerID,
+	common.BKHTTPLanguage,
+	common.BKHTTPRequestAppCode,
+	"Content-Type",
+}
+
+// ChangeRequest is a sensitive operation which is captured and waiting for approval,
+// the operation is executed with the applicant's identity after it's approved.
+type ChangeRequest struct {
+	ID int64 `json:"id" bson:"id" mapstructure:"id"`
+	// ResourceType and Action is the auth resource type and action of the operation, like business:archive.
+	ResourceType string `json:"resource_type" bson:"resource_type" mapstructure:"resource_type"`
+	Action       string `json:"action" bson:"action" mapstructure:"action"`
+	BizID        int64  `json:"bk_biz_id" bson:"bk_biz_id" mapstructure:"bk_biz_id"`
+
+	// Method, URI, Header and Body is the captured request.
+	Method string            `json:"method" bson:"method" mapstructure:"method"`
+	URI    string            `json:"uri" bson:"uri" mapstructure:"uri"`
+	Header map[string]string `json:"header" bson:"header" mapstructure:"header"`
+	Body   string            `json:"body" bson:"body" mapstructure:"body"`
+
+	Status    ChangeRequestStatus `json:"status" bson:"status" mapstructure:"status"`
+	Applicant string              `json:"applicant" bson:"applicant" mapstructure:"applicant"`
+	Approver  string              `json:"approver" bson:"approver" mapstructure:"approver"`
+	Comment   string              `json:"comment" bson:"comment" mapstructure:"comment"`
+	// Response is the response body of the executed request.
+	Response string `json:"response" bson:"response" mapstructure:"response"`
+	// Rid is the request id the operation is executed with, the audit logs of the execution are
+	// labeled with the change request's id.
+	Rid string `json:"rid" bson:"rid" mapstructure:"rid"`
+
+	ExpireTime      time.Time `json:"expire_time" bson:"expire_time" mapstructure:"expire_time"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time" mapstructure:"create_time"`
+	LastTime        time.Time `json:"last_time" bson:"last_time" mapstructure:"last_time"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account" mapstructure:"bk_supplier_account"`
+}
+
+// IsExpired returns true if the change request is pending and it's expire time is passed.
+func (c *ChangeRequest) IsExpired(now time.Time) bool {
+	return c.Status == ChangeRequestPending && !c.ExpireTime.IsZero() && c.ExpireTime.Before(now)
+}
+
+type CreateChangeRequestOption struct {
+	ResourceType string            `json:"resource_type" mapstructure:"resource_type"`
+	Action       string            `json:"action" mapstructure:"action"`
+	BizID        int64             `json:"bk_biz_id" mapstructure:"bk_biz_id"`
+	Method       string            `json:"method" mapstructure:"method"`
+	URI          string            `json:"uri" mapstructure:"uri"`
+	Header       map[string]string `json:"header" mapstructure:"header"`
+	Body         string            `json:"body" mapstructure:"body"`
+	ExpireTime   time.Time         `json:"expire_time" mapstructure:"expire_time"`
+}
+
+// Validate validate the option, returns the invalid field's name if it's invalid.
+func (o *CreateChangeRequestOption) Validate() (string, error) {
+	if len(o.ResourceType) == 0 {
+		return "resource_type", errors.New("resource type can not be empty")
+	}
+	if len(o.Action) == 0 {
+		return "action", errors.New("action can not be empty")
+	}
+	if len(o.Method) == 0 {
+		return "method", errors.New("method can not be empty")
+	}
+	if len(o.URI) == 0 {
+		return "uri", errors.New("uri can not be empty")
+	}
+	if o.ExpireTime.IsZero() {
+		return "expire_time", errors.New("expire time can not be empty")
+	}
+	return "", nil
+}
+
+// UpdateChangeRequestStatusOption change the status of a change request, the status can only be changed
+// from pending to executing or rejected, and from executing to executed or failed.
+type UpdateChangeRequestStatusOption struct {
+	Status   ChangeRequestStatus `json:"status" mapstructure:"status"`
+	Comment  *string             `json:"comment" mapstructure:"comment"`
+	Response *string             `json:"response" mapstructure:"response"`
+	Rid      *string             `json:"rid" mapstructure:"rid"`
+}
+
+// ChangeRequestStatusTransitions is the allowed status transitions of change requests.
+var ChangeRequestStatusTransitions = map[ChangeRequestStatus][]ChangeRequestStatus{
+	ChangeRequestPending:   {ChangeRequestExecuting, ChangeRequestRejected, ChangeRequestExpired},
+	ChangeRequestExecuting: {ChangeRequestExecuted, ChangeRequestFailed},
+}
+
+// CanTransitTo check if the status can be changed to the target status.
+func (s ChangeRequestStatus) CanTransitTo(target ChangeRequestStatus) bool {
+	for _, status := range ChangeRequestStatusTransitions[s] {
+		if status == target {
+			return true
+		}
+	}
+	return false
+}
+
+type ListChangeRequestOption struct {
+	IDs          []int64               `json:"ids" mapstructure:"ids"`
+	Status       []ChangeRequestStatus `json:"status" mapstructure:"status"`
+	ResourceType string                `json:"resource_type" mapstructure:"resource_type"`
+	Action       string                `json:"action" mapstructure:"action"`
+	BizID        int64                 `json:"bk_biz_id" mapstructure:"bk_biz_id"`
+	Applicant    string                `json:"applicant" mapstructure:"applicant"`
+	Approver     string                `json:"approver" mapstructure:"approver"`
+	// Participant only returns the change requests whose applicant or approver is the participant.
+	Participant string   `json:"participant" mapstructure:"participant"`
+	Page        BasePage `json:"page" mapstructure:"page"`
+}
+
+// ApproveChangeRequestOption is the approver's decision of a change request.
+type ApproveChangeRequestOption struct {
+	Comment string `json:"comment" mapstructure:"comment"`
+}
+
+type MultipleChangeRequest struct {
+	Count int64           `json:"count" mapstructure:"count"`
+	Info  []ChangeRequest `json:"info" mapstructure:"info"`
+}
+
+type ChangeRequestResult struct {
+	BaseResp `json:",inline"`
+	Data     ChangeRequest `json:"data"`
+}
+
+type MultipleChangeRequestResult struct {
+	BaseResp `json:",inline"`
+	Data     MultipleChangeRequest `json:"data"`
+}
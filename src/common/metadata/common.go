@@ -98,8 +98,9 @@ type ResponseInstData struct {
 
 // InstDataInfo response instance data result Data field
 type InstDataInfo struct {
-	Count int             `json:"count"`
-	Info  []mapstr.MapStr `json:"info"`
+	Count      int             `json:"count"`
+	Info       []mapstr.MapStr `json:"info"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
 
 type ResponseDataMapStr struct {
@@ -113,6 +114,7 @@ type QueryInput struct {
 	Start     int                    `json:"start,omitempty"`
 	Limit     int                    `json:"limit,omitempty"`
 	Sort      string                 `json:"sort,omitempty"`
+	Cursor    string                 `json:"cursor,omitempty"`
 }
 
 // Validate validates the input param
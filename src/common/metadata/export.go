@@ -0,0 +1,66 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+
+	"configcenter/src/common/querybuilder"
+)
+
+const (
+	// ExportContentType is the content type of the exported data, each line of it is a json object
+	ExportContentType = "application/x-ndjson"
+	// ExportBatchSize is the number of the exported hosts whose topology are read from db together
+	ExportBatchSize = 500
+)
+
+// ExportOption exports the resources matched by the filter as new line delimited json, the resources are
+// exported in the order of their ids, so that an interrupted export can be resumed after the last exported id.
+type ExportOption struct {
+	Filter *querybuilder.QueryFilter `json:"filter"`
+	// Fields are the exported fields of the resources, all the fields are exported if it's not set
+	Fields []string `json:"fields"`
+	// AfterID resumes the export after the resource with the id
+	AfterID int64 `json:"after_id"`
+}
+
+func (o *ExportOption) Validate() (string, error) {
+	if o.Filter != nil && o.Filter.Rule != nil {
+		if key, err := o.Filter.Validate(); err != nil {
+			return "filter." + key, err
+		}
+		if o.Filter.GetDeep() > querybuilder.MaxDeep {
+			return "filter.rules", fmt.Errorf("exceed max query condition deepth: %d", querybuilder.MaxDeep)
+		}
+	}
+	if o.AfterID < 0 {
+		return "after_id", errors.New("invalid after id")
+	}
+	return "", nil
+}
+
+// ExportHostTopo is a topology node the exported host belongs to
+type ExportHostTopo struct {
+	BizID    int64 `json:"bk_biz_id"`
+	SetID    int64 `json:"bk_set_id"`
+	ModuleID int64 `json:"bk_module_id"`
+}
+
+// ExportHost is a line of the exported hosts, the filter and fields of the export option are applied to the
+// host's attributes.
+type ExportHost struct {
+	Host map[string]interface{} `json:"host"`
+	Topo []ExportHostTopo       `json:"topo"`
+}
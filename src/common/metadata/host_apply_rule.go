@@ -12,8 +12,12 @@
 package metadata
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"configcenter/src/common"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/querybuilder"
 )
@@ -25,13 +29,22 @@ const (
 )
 
 // HostApplyRule represent one rule of host property auto apply
+// a rule is attached to one of module, set or set template, the rules attached to set or set template
+// are inherited by all the modules under them.
 type HostApplyRule struct {
-	ID       int64 `field:"id" json:"id" bson:"id" mapstructure:"id"`
-	BizID    int64 `field:"bk_biz_id" json:"bk_biz_id" bson:"bk_biz_id" mapstructure:"bk_biz_id"`
-	ModuleID int64 `field:"bk_module_id" json:"bk_module_id" bson:"bk_module_id" mapstructure:"bk_module_id"`
+	ID            int64 `field:"id" json:"id" bson:"id" mapstructure:"id"`
+	BizID         int64 `field:"bk_biz_id" json:"bk_biz_id" bson:"bk_biz_id" mapstructure:"bk_biz_id"`
+	ModuleID      int64 `field:"bk_module_id" json:"bk_module_id" bson:"bk_module_id" mapstructure:"bk_module_id"`
+	SetID         int64 `field:"bk_set_id" json:"bk_set_id" bson:"bk_set_id" mapstructure:"bk_set_id"`
+	SetTemplateID int64 `field:"set_template_id" json:"set_template_id" bson:"set_template_id" mapstructure:"set_template_id"`
 	// `id` field of table: `cc_AsstDes`, not the same with bk_property_id
 	AttributeID   int64       `field:"bk_attribute_id" json:"bk_attribute_id" bson:"bk_attribute_id" mapstructure:"bk_attribute_id"`
 	PropertyValue interface{} `field:"bk_property_value" json:"bk_property_value" bson:"bk_property_value" mapstructure:"bk_property_value"`
+	// Conditions is optional, the rule only applies to the hosts matching all of the conditions if it's set.
+	Conditions []HostApplyRuleCondition `field:"conditions" json:"conditions,omitempty" bson:"conditions,omitempty" mapstructure:"conditions"`
+	// Priority is used to resolve the conflicts when a host gets different values from several rules,
+	// the rule with the highest priority wins.
+	Priority int64 `field:"priority" json:"priority" bson:"priority" mapstructure:"priority"`
 
 	// 通用字段
 	Creator         string    `field:"creator" json:"creator" bson:"creator" mapstructure:"creator"`
@@ -42,17 +55,178 @@ type HostApplyRule struct {
 }
 
 func (h *HostApplyRule) Validate() (string, error) {
+	scopeCount := 0
+	for _, id := range []int64{h.ModuleID, h.SetID, h.SetTemplateID} {
+		if id < 0 {
+			return common.BKModuleIDField, fmt.Errorf("invalid rule scope id %d", id)
+		}
+		if id > 0 {
+			scopeCount++
+		}
+	}
+	if scopeCount != 1 {
+		return common.BKModuleIDField, fmt.Errorf("rule must be attached to exactly one of module, set and set template")
+	}
+	for index, condition := range h.Conditions {
+		if key, err := condition.Validate(); err != nil {
+			return fmt.Sprintf("%s[%d].%s", common.BKConditionsField, index, key), err
+		}
+	}
+	return "", nil
+}
+
+const (
+	HostApplyRuleScopeModule      = "module"
+	HostApplyRuleScopeSet         = "set"
+	HostApplyRuleScopeSetTemplate = "set_template"
+)
+
+// GetScope returns which kind of topology node the rule is attached to.
+func (h *HostApplyRule) GetScope() string {
+	switch {
+	case h.SetID > 0:
+		return HostApplyRuleScopeSet
+	case h.SetTemplateID > 0:
+		return HostApplyRuleScopeSetTemplate
+	default:
+		return HostApplyRuleScopeModule
+	}
+}
+
+// ScopeRank returns how specific the rule's scope is, the module rules are more specific than the inherited ones.
+func (h *HostApplyRule) ScopeRank() int {
+	switch h.GetScope() {
+	case HostApplyRuleScopeSet:
+		return 2
+	case HostApplyRuleScopeSetTemplate:
+		return 1
+	default:
+		return 3
+	}
+}
+
+// AppliesTo check whether the rule applies to a host in the module, the module's set and set template
+// are used to match the inherited rules.
+func (h *HostApplyRule) AppliesTo(module ModuleInst, host map[string]interface{}) bool {
+	switch h.GetScope() {
+	case HostApplyRuleScopeSet:
+		if h.SetID != module.ParentID {
+			return false
+		}
+	case HostApplyRuleScopeSetTemplate:
+		if h.SetTemplateID != module.SetTemplateID {
+			return false
+		}
+	default:
+		if h.ModuleID != module.ModuleID {
+			return false
+		}
+	}
+	for _, condition := range h.Conditions {
+		if !condition.Match(host) {
+			return false
+		}
+	}
+	return true
+}
+
+// ConditionKey returns a canonical representation of the rule's conditions, two rules with the same scope,
+// attribute and condition key are duplicated.
+func (h *HostApplyRule) ConditionKey() string {
+	items := make([]string, 0)
+	for _, condition := range h.Conditions {
+		items = append(items, fmt.Sprintf("%s %s %v", condition.PropertyID, condition.Operator, condition.Value))
+	}
+	sort.Strings(items)
+	return strings.Join(items, " && ")
+}
+
+const (
+	HostApplyConditionEqual    = "equal"
+	HostApplyConditionNotEqual = "not_equal"
+	HostApplyConditionIn       = "in"
+	HostApplyConditionNotIn    = "not_in"
+	HostApplyConditionContains = "contains"
+	HostApplyConditionExist    = "exist"
+	HostApplyConditionNotExist = "not_exist"
+)
+
+// HostApplyRuleCondition is a match condition on the host's own attribute, e.g. bk_os_type equal "1"
+type HostApplyRuleCondition struct {
+	PropertyID string      `field:"bk_property_id" json:"bk_property_id" bson:"bk_property_id" mapstructure:"bk_property_id"`
+	Operator   string      `field:"operator" json:"operator" bson:"operator" mapstructure:"operator"`
+	Value      interface{} `field:"value" json:"value" bson:"value" mapstructure:"value"`
+}
+
+func (c HostApplyRuleCondition) Validate() (string, error) {
+	if len(c.PropertyID) == 0 {
+		return common.BKPropertyIDField, fmt.Errorf("condition property id can not be empty")
+	}
+	switch c.Operator {
+	case HostApplyConditionEqual, HostApplyConditionNotEqual:
+		if c.Value == nil {
+			return "value", fmt.Errorf("condition value can not be empty")
+		}
+	case HostApplyConditionIn, HostApplyConditionNotIn:
+		if _, ok := c.Value.([]interface{}); !ok {
+			return "value", fmt.Errorf("condition value of operator %s must be an array", c.Operator)
+		}
+	case HostApplyConditionContains:
+		if value, ok := c.Value.(string); !ok || len(value) == 0 {
+			return "value", fmt.Errorf("condition value of operator %s must be a non empty string", c.Operator)
+		}
+	case HostApplyConditionExist, HostApplyConditionNotExist:
+	default:
+		return "operator", fmt.Errorf("unsupported condition operator: %s", c.Operator)
+	}
 	return "", nil
 }
 
+// Match check whether the host's attribute matches the condition, the values are compared by their string
+// representations, as the numbers may be decoded as different types from db and from request.
+func (c HostApplyRuleCondition) Match(host map[string]interface{}) bool {
+	hostValue, exist := host[c.PropertyID]
+	switch c.Operator {
+	case HostApplyConditionExist:
+		return exist && hostValue != nil
+	case HostApplyConditionNotExist:
+		return !exist || hostValue == nil
+	case HostApplyConditionEqual:
+		return exist && fmt.Sprint(hostValue) == fmt.Sprint(c.Value)
+	case HostApplyConditionNotEqual:
+		return !exist || fmt.Sprint(hostValue) != fmt.Sprint(c.Value)
+	case HostApplyConditionIn, HostApplyConditionNotIn:
+		in := false
+		values, _ := c.Value.([]interface{})
+		for _, value := range values {
+			if exist && fmt.Sprint(hostValue) == fmt.Sprint(value) {
+				in = true
+				break
+			}
+		}
+		return in == (c.Operator == HostApplyConditionIn)
+	case HostApplyConditionContains:
+		value, ok := hostValue.(string)
+		return ok && strings.Contains(value, fmt.Sprint(c.Value))
+	default:
+		return false
+	}
+}
+
 type CreateHostApplyRuleOption struct {
-	AttributeID   int64       `field:"bk_attribute_id" json:"bk_attribute_id" bson:"bk_attribute_id" mapstructure:"bk_attribute_id"`
-	ModuleID      int64       `field:"bk_module_id" json:"bk_module_id" bson:"bk_module_id" mapstructure:"bk_module_id"`
-	PropertyValue interface{} `field:"bk_property_value" json:"bk_property_value" bson:"bk_property_value" mapstructure:"bk_property_value"`
+	AttributeID   int64                    `field:"bk_attribute_id" json:"bk_attribute_id" bson:"bk_attribute_id" mapstructure:"bk_attribute_id"`
+	ModuleID      int64                    `field:"bk_module_id" json:"bk_module_id" bson:"bk_module_id" mapstructure:"bk_module_id"`
+	SetID         int64                    `field:"bk_set_id" json:"bk_set_id" bson:"bk_set_id" mapstructure:"bk_set_id"`
+	SetTemplateID int64                    `field:"set_template_id" json:"set_template_id" bson:"set_template_id" mapstructure:"set_template_id"`
+	PropertyValue interface{}              `field:"bk_property_value" json:"bk_property_value" bson:"bk_property_value" mapstructure:"bk_property_value"`
+	Conditions    []HostApplyRuleCondition `field:"conditions" json:"conditions" bson:"conditions" mapstructure:"conditions"`
+	Priority      int64                    `field:"priority" json:"priority" bson:"priority" mapstructure:"priority"`
 }
 
 type UpdateHostApplyRuleOption struct {
 	PropertyValue interface{} `field:"bk_property_value" json:"bk_property_value" bson:"bk_property_value" mapstructure:"bk_property_value"`
+	// Priority is optional, the rule's priority is not changed if it's not set.
+	Priority *int64 `field:"priority" json:"priority" bson:"priority" mapstructure:"priority"`
 }
 
 type MultipleHostApplyRuleResult struct {
@@ -61,9 +235,11 @@ type MultipleHostApplyRuleResult struct {
 }
 
 type ListHostApplyRuleOption struct {
-	ModuleIDs    []int64  `field:"bk_module_ids" json:"bk_module_ids" bson:"bk_module_ids" mapstructure:"bk_module_ids"`
-	AttributeIDs []int64  `field:"bk_attribute_ids" json:"bk_attribute_ids" bson:"bk_attribute_ids" mapstructure:"bk_attribute_ids"`
-	Page         BasePage `field:"page" json:"page" bson:"page" mapstructure:"page"`
+	ModuleIDs      []int64  `field:"bk_module_ids" json:"bk_module_ids" bson:"bk_module_ids" mapstructure:"bk_module_ids"`
+	SetIDs         []int64  `field:"bk_set_ids" json:"bk_set_ids" bson:"bk_set_ids" mapstructure:"bk_set_ids"`
+	SetTemplateIDs []int64  `field:"set_template_ids" json:"set_template_ids" bson:"set_template_ids" mapstructure:"set_template_ids"`
+	AttributeIDs   []int64  `field:"bk_attribute_ids" json:"bk_attribute_ids" bson:"bk_attribute_ids" mapstructure:"bk_attribute_ids"`
+	Page           BasePage `field:"page" json:"page" bson:"page" mapstructure:"page"`
 }
 
 type ListHostRelatedApplyRuleOption struct {
@@ -83,10 +259,14 @@ type UpdateHostByHostApplyRuleOption struct {
 	HostIDs []int64 `field:"bk_host_ids" json:"bk_host_ids" bson:"bk_host_ids" mapstructure:"bk_host_ids"`
 }
 
+// CreateOrUpdateApplyRuleOption create or update the unconditional rule of the module, set or set template
 type CreateOrUpdateApplyRuleOption struct {
 	AttributeID   int64       `field:"bk_attribute_id" json:"bk_attribute_id" bson:"bk_attribute_id" mapstructure:"bk_attribute_id"`
 	ModuleID      int64       `field:"bk_module_id" json:"bk_module_id" bson:"bk_module_id" mapstructure:"bk_module_id"`
+	SetID         int64       `field:"bk_set_id" json:"bk_set_id" bson:"bk_set_id" mapstructure:"bk_set_id"`
+	SetTemplateID int64       `field:"set_template_id" json:"set_template_id" bson:"set_template_id" mapstructure:"set_template_id"`
 	PropertyValue interface{} `field:"bk_property_value" json:"bk_property_value" bson:"bk_property_value" mapstructure:"bk_property_value"`
+	Priority      int64       `field:"priority" json:"priority" bson:"priority" mapstructure:"priority"`
 }
 
 type BatchCreateOrUpdateHostApplyRuleResult struct {
@@ -137,10 +317,30 @@ type HostApplyConflictField struct {
 	UnresolvedConflictExist bool `field:"unresolved_conflict_exist" json:"unresolved_conflict_exist" mapstructure:"unresolved_conflict_exist"`
 }
 
+const (
+	// HostApplyWinByOnlyRule only one rule applies to the host's attribute
+	HostApplyWinByOnlyRule = "only_rule"
+	// HostApplyWinBySameValue all the rules apply the same value to the host's attribute
+	HostApplyWinBySameValue = "same_value"
+	// HostApplyWinByPriority the winner rule has the highest priority
+	HostApplyWinByPriority = "priority"
+	// HostApplyWinByScope the winner rule is attached to a more specific topology node, module > set > set template
+	HostApplyWinByScope = "scope"
+	// HostApplyWinByCondition the winner rule has conditions matching the host, while the others don't have
+	HostApplyWinByCondition = "condition"
+	// HostApplyWinByResolver the conflict is resolved by the conflict resolver
+	HostApplyWinByResolver = "conflict_resolver"
+)
+
 type HostApplyUpdateField struct {
 	AttributeID   int64       `field:"bk_attribute_id" json:"bk_attribute_id" bson:"bk_attribute_id" mapstructure:"bk_attribute_id"`
 	PropertyID    string      `field:"bk_property_id" json:"bk_property_id" mapstructure:"bk_property_id"`
 	PropertyValue interface{} `field:"bk_property_value" json:"bk_property_value" mapstructure:"bk_property_value"`
+
+	// WinnerRule is the rule whose value is applied, it's nil if the value is from a conflict resolver,
+	// and WinReason explains why the rule wins, it's one of the HostApplyWinBy*.
+	WinnerRule *HostApplyRule `field:"winner_rule" json:"winner_rule,omitempty" mapstructure:"winner_rule"`
+	WinReason  string         `field:"win_reason" json:"win_reason" mapstructure:"win_reason"`
 }
 
 type OneHostApplyPlan struct {
@@ -221,4 +421,47 @@ type SearchRuleRelatedModulesOption struct {
 type UpdateModuleHostApplyEnableStatusOption struct {
 	Enable     bool `json:"enable" mapstructure:"enable"`
 	ClearRules bool `json:"clear_rules" mapstructure:"clear_rules"`
+	// DriftAction is optional, the module's drift action is not changed if it's empty.
+	DriftAction string `json:"host_apply_drift_action" mapstructure:"host_apply_drift_action"`
+}
+
+const (
+	// HostApplyDriftActionRecord record the drifted attributes of the hosts, it's the default action
+	HostApplyDriftActionRecord = "record"
+	// HostApplyDriftActionAutoCorrect re-apply the rules on the drifted attributes of the hosts
+	HostApplyDriftActionAutoCorrect = "auto_correct"
+)
+
+func ValidateHostApplyDriftAction(action string) bool {
+	return action == HostApplyDriftActionRecord || action == HostApplyDriftActionAutoCorrect
+}
+
+// HostApplyDrift represent a host's attribute whose value is changed after the host apply rule is applied
+type HostApplyDrift struct {
+	ID          int64   `field:"id" json:"id" bson:"id" mapstructure:"id"`
+	BizID       int64   `field:"bk_biz_id" json:"bk_biz_id" bson:"bk_biz_id" mapstructure:"bk_biz_id"`
+	HostID      int64   `field:"bk_host_id" json:"bk_host_id" bson:"bk_host_id" mapstructure:"bk_host_id"`
+	ModuleIDs   []int64 `field:"bk_module_ids" json:"bk_module_ids" bson:"bk_module_ids" mapstructure:"bk_module_ids"`
+	AttributeID int64   `field:"bk_attribute_id" json:"bk_attribute_id" bson:"bk_attribute_id" mapstructure:"bk_attribute_id"`
+	PropertyID  string  `field:"bk_property_id" json:"bk_property_id" bson:"bk_property_id" mapstructure:"bk_property_id"`
+	// RuleID is the id of the rule whose value should be applied on the attribute
+	RuleID      int64       `field:"host_apply_rule_id" json:"host_apply_rule_id" bson:"host_apply_rule_id" mapstructure:"host_apply_rule_id"`
+	ExpectValue interface{} `field:"expect_value" json:"expect_value" bson:"expect_value" mapstructure:"expect_value"`
+	ActualValue interface{} `field:"actual_value" json:"actual_value" bson:"actual_value" mapstructure:"actual_value"`
+
+	SupplierAccount string    `field:"bk_supplier_account" json:"bk_supplier_account" bson:"bk_supplier_account" mapstructure:"bk_supplier_account"`
+	CreateTime      time.Time `field:"create_time" json:"create_time" bson:"create_time" mapstructure:"create_time"`
+	LastTime        time.Time `field:"last_time" json:"last_time" bson:"last_time" mapstructure:"last_time"`
+}
+
+type ListHostApplyDriftOption struct {
+	HostIDs      []int64  `field:"bk_host_ids" json:"bk_host_ids" bson:"bk_host_ids" mapstructure:"bk_host_ids"`
+	ModuleIDs    []int64  `field:"bk_module_ids" json:"bk_module_ids" bson:"bk_module_ids" mapstructure:"bk_module_ids"`
+	AttributeIDs []int64  `field:"bk_attribute_ids" json:"bk_attribute_ids" bson:"bk_attribute_ids" mapstructure:"bk_attribute_ids"`
+	Page         BasePage `field:"page" json:"page" bson:"page" mapstructure:"page"`
+}
+
+type MultipleHostApplyDrift struct {
+	Count int64            `json:"count" mapstructure:"count"`
+	Info  []HostApplyDrift `json:"info" mapstructure:"info"`
 }
@@ -0,0 +1,95 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+)
+
+func TestHostApplyRuleValidate(t *testing.T) {
+	rules := []HostApplyRule{
+		{},
+		{ModuleID: 1, SetID: 2},
+		{ModuleID: -1},
+		{SetID: 1, Conditions: []HostApplyRuleCondition{{PropertyID: "bk_os_type", Operator: "gt", Value: 1}}},
+	}
+	for _, rule := range rules {
+		if _, err := rule.Validate(); err == nil {
+			t.Errorf("rule should be invalid, %#v", rule)
+		}
+	}
+
+	rule := HostApplyRule{SetTemplateID: 1, Conditions: []HostApplyRuleCondition{
+		{PropertyID: "bk_os_type", Operator: HostApplyConditionEqual, Value: "1"},
+	}}
+	if key, err := rule.Validate(); err != nil {
+		t.Errorf("rule should be valid, key: %s, err: %v", key, err)
+	}
+}
+
+func TestHostApplyRuleAppliesTo(t *testing.T) {
+	module := ModuleInst{ModuleID: 3, ParentID: 2, SetTemplateID: 1}
+	linux := map[string]interface{}{"bk_os_type": "1", "bk_host_name": "db-master-01"}
+	windows := map[string]interface{}{"bk_os_type": "2"}
+
+	testCases := []struct {
+		rule   HostApplyRule
+		host   map[string]interface{}
+		expect bool
+	}{
+		{HostApplyRule{ModuleID: 3}, windows, true},
+		{HostApplyRule{ModuleID: 4}, windows, false},
+		{HostApplyRule{SetID: 2}, windows, true},
+		{HostApplyRule{SetID: 3}, windows, false},
+		{HostApplyRule{SetTemplateID: 1}, windows, true},
+		{HostApplyRule{SetTemplateID: 2}, windows, false},
+		{HostApplyRule{ModuleID: 3, Conditions: []HostApplyRuleCondition{
+			{PropertyID: "bk_os_type", Operator: HostApplyConditionEqual, Value: "1"},
+		}}, linux, true},
+		{HostApplyRule{ModuleID: 3, Conditions: []HostApplyRuleCondition{
+			{PropertyID: "bk_os_type", Operator: HostApplyConditionEqual, Value: "1"},
+		}}, windows, false},
+		{HostApplyRule{SetID: 2, Conditions: []HostApplyRuleCondition{
+			{PropertyID: "bk_os_type", Operator: HostApplyConditionIn, Value: []interface{}{"1", "3"}},
+			{PropertyID: "bk_host_name", Operator: HostApplyConditionContains, Value: "master"},
+		}}, linux, true},
+		{HostApplyRule{SetID: 2, Conditions: []HostApplyRuleCondition{
+			{PropertyID: "bk_host_name", Operator: HostApplyConditionNotExist},
+		}}, windows, true},
+		{HostApplyRule{SetID: 2, Conditions: []HostApplyRuleCondition{
+			{PropertyID: "bk_os_type", Operator: HostApplyConditionNotIn, Value: []interface{}{"2"}},
+		}}, windows, false},
+	}
+	for index, testCase := range testCases {
+		if result := testCase.rule.AppliesTo(module, testCase.host); result != testCase.expect {
+			t.Errorf("case %d: expect %v, got %v", index, testCase.expect, result)
+		}
+	}
+}
+
+func TestHostApplyRuleConditionKey(t *testing.T) {
+	a := HostApplyRule{Conditions: []HostApplyRuleCondition{
+		{PropertyID: "bk_os_type", Operator: HostApplyConditionEqual, Value: "1"},
+		{PropertyID: "bk_cpu", Operator: HostApplyConditionExist},
+	}}
+	b := HostApplyRule{Conditions: []HostApplyRuleCondition{
+		{PropertyID: "bk_cpu", Operator: HostApplyConditionExist},
+		{PropertyID: "bk_os_type", Operator: HostApplyConditionEqual, Value: "1"},
+	}}
+	if a.ConditionKey() != b.ConditionKey() {
+		t.Errorf("condition key should be the same, %s, %s", a.ConditionKey(), b.ConditionKey())
+	}
+	if (&HostApplyRule{}).ConditionKey() == a.ConditionKey() {
+		t.Errorf("condition key should be different")
+	}
+}
@@ -75,8 +75,9 @@ type GetHistoryResult struct {
 }
 
 type HostInfo struct {
-	Count int             `json:"count"`
-	Info  []mapstr.MapStr `json:"info"`
+	Count      int             `json:"count"`
+	Info       []mapstr.MapStr `json:"info"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
 
 type GetHostsResult struct {
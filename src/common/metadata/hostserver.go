@@ -21,6 +21,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/querybuilder"
+	"configcenter/src/common/selector"
 	"configcenter/src/common/util"
 )
 
@@ -135,6 +136,9 @@ func (option ListHostsParameter) Validate() (string, error) {
 	if key, err := option.Page.Validate(false); err != nil {
 		return fmt.Sprintf("page.%s", key), err
 	}
+	if key, err := option.Page.ValidateCursor(); err != nil {
+		return fmt.Sprintf("page.%s", key), err
+	}
 
 	if option.HostPropertyFilter != nil {
 		if key, err := option.HostPropertyFilter.Validate(); err != nil {
@@ -158,6 +162,9 @@ func (option ListHostsWithNoBizParameter) Validate() (string, error) {
 	if key, err := option.Page.Validate(false); err != nil {
 		return fmt.Sprintf("page.%s", key), err
 	}
+	if key, err := option.Page.ValidateCursor(); err != nil {
+		return fmt.Sprintf("page.%s", key), err
+	}
 
 	if option.HostPropertyFilter != nil {
 		if key, err := option.HostPropertyFilter.Validate(); err != nil {
@@ -196,6 +203,9 @@ func (option ListHosts) Validate() (errKey string, err error) {
 	if key, err := option.Page.Validate(false); err != nil {
 		return fmt.Sprintf("page.%s", key), err
 	}
+	if key, err := option.Page.ValidateCursor(); err != nil {
+		return fmt.Sprintf("page.%s", key), err
+	}
 
 	if option.HostPropertyFilter != nil {
 		if key, err := option.HostPropertyFilter.Validate(); err != nil {
@@ -225,6 +235,21 @@ type IPInfo struct {
 	Data  []string `json:"data"`
 	Exact int64    `json:"exact"`
 	Flag  string   `json:"flag"`
+	// CIDR matches the hosts which have at least one ip in one of the cidr blocks, such as 10.12.0.0/16 or fe80::/64
+	CIDR []string `json:"cidr"`
+	// Range matches the hosts which have at least one ip in one of the ip ranges
+	Range []IPRange `json:"range"`
+}
+
+// HasCondition returns whether the hosts need to be filtered by ip.
+func (ip IPInfo) HasCondition() bool {
+	return len(ip.Data) > 0 || len(ip.CIDR) > 0 || len(ip.Range) > 0
+}
+
+// IPRange is the range of ip address, both the start and end ip are included.
+type IPRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
 }
 
 // search condition
@@ -232,16 +257,46 @@ type SearchCondition struct {
 	Fields    []string        `json:"fields"`
 	Condition []ConditionItem `json:"condition"`
 	ObjectID  string          `json:"bk_obj_id"`
+	// Selectors filter the object's instances by their labels
+	Selectors selector.Selectors `json:"selectors"`
+}
+
+// LabelConditions convert the label selectors to condition items on the labels field
+func (s SearchCondition) LabelConditions() ([]ConditionItem, error) {
+	if field, err := s.Selectors.Validate(); err != nil {
+		return nil, fmt.Errorf("selectors.%s invalid, err: %v", field, err)
+	}
+
+	conditions := make([]ConditionItem, 0)
+	for _, labelSelector := range s.Selectors {
+		filter, err := labelSelector.ToMgoFilter()
+		if err != nil {
+			return nil, err
+		}
+		for field, value := range filter {
+			operators, ok := value.(map[string]interface{})
+			if !ok {
+				conditions = append(conditions, ConditionItem{Field: field, Operator: common.BKDBEQ, Value: value})
+				continue
+			}
+			for operator, operatorValue := range operators {
+				conditions = append(conditions, ConditionItem{Field: field, Operator: operator, Value: operatorValue})
+			}
+		}
+	}
+	return conditions, nil
 }
 
 type SearchHost struct {
-	Count int             `json:"count"`
-	Info  []mapstr.MapStr `json:"info"`
+	Count      int             `json:"count"`
+	Info       []mapstr.MapStr `json:"info"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
 
 type ListHostResult struct {
-	Count int                      `json:"count"`
-	Info  []map[string]interface{} `json:"info"`
+	Count      int                      `json:"count"`
+	Info       []map[string]interface{} `json:"info"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
 }
 
 type HostTopoResult struct {
@@ -339,6 +394,23 @@ type CloudAreaParameter struct {
 	Page      BasePage      `json:"page" bson:"page" field:"page"`
 }
 
+// CloudAreaStatusOption the option to get the status summary of the cloud areas
+type CloudAreaStatusOption struct {
+	CloudIDs []int64 `json:"bk_cloud_ids"`
+}
+
+// CloudAreaStatus the status summary of a cloud area
+type CloudAreaStatus struct {
+	CloudID   int64  `json:"bk_cloud_id"`
+	CloudName string `json:"bk_cloud_name"`
+	HostCount int64  `json:"host_count"`
+	// HostLimit is the max count of the hosts in the cloud area, 0 means no limit
+	HostLimit int64 `json:"bk_host_limit"`
+	// LastReportTime is the last time that a host in the cloud area reported its snapshot, nil if never reported
+	LastReportTime *time.Time `json:"last_report_time"`
+	Proxies        []string   `json:"proxies"`
+}
+
 type TopoNode struct {
 	ObjectID   string `field:"bk_obj_id" json:"bk_obj_id" mapstructure:"bk_obj_id"`
 	InstanceID int64  `field:"bk_inst_id" json:"bk_inst_id" mapstructure:"bk_inst_id"`
@@ -0,0 +1,163 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+)
+
+const (
+	// HostSnapTransformFirst takes the first element of an array value.
+	HostSnapTransformFirst = "first"
+	// HostSnapTransformSum sums the numeric elements of an array value.
+	HostSnapTransformSum = "sum"
+	// HostSnapTransformRegex extracts the first sub match of the regex, or the whole match if
+	// the regex has no group. it's applied to each element of an array value.
+	HostSnapTransformRegex = "regex"
+	// HostSnapTransformUnit converts a numeric value from one storage unit to another.
+	HostSnapTransformUnit = "unit"
+)
+
+// HostSnapUnits is the storage units supported by the unit transform, with the value of the
+// binary exponent of each unit.
+var HostSnapUnits = map[string]uint{
+	"B":  0,
+	"KB": 10,
+	"MB": 20,
+	"GB": 30,
+	"TB": 40,
+}
+
+// HostSnapTransform is one step to transform the value found in the host snapshot.
+type HostSnapTransform struct {
+	Type     string `json:"type" bson:"type"`
+	Regex    string `json:"regex,omitempty" bson:"regex,omitempty"`
+	FromUnit string `json:"from_unit,omitempty" bson:"from_unit,omitempty"`
+	ToUnit   string `json:"to_unit,omitempty" bson:"to_unit,omitempty"`
+}
+
+func (t HostSnapTransform) Validate() (string, error) {
+	switch t.Type {
+	case HostSnapTransformFirst, HostSnapTransformSum:
+	case HostSnapTransformRegex:
+		if len(t.Regex) == 0 {
+			return "regex", fmt.Errorf("regex can not be empty")
+		}
+		if _, err := regexp.Compile(t.Regex); err != nil {
+			return "regex", err
+		}
+	case HostSnapTransformUnit:
+		if _, ok := HostSnapUnits[strings.ToUpper(t.FromUnit)]; !ok {
+			return "from_unit", fmt.Errorf("unsupported unit %s", t.FromUnit)
+		}
+		if _, ok := HostSnapUnits[strings.ToUpper(t.ToUnit)]; !ok {
+			return "to_unit", fmt.Errorf("unsupported unit %s", t.ToUnit)
+		}
+	default:
+		return "type", fmt.Errorf("unsupported transform type %s", t.Type)
+	}
+	return "", nil
+}
+
+// HostSnapFieldMapping maps a json path of the host snapshot reported by the agent to a host attribute,
+// the value found with the path is converted by the transforms in order before it's saved to the host.
+type HostSnapFieldMapping struct {
+	ID int64 `json:"id" bson:"id"`
+	// PropertyID is the bk_property_id of the host attribute
+	PropertyID string `json:"bk_property_id" bson:"bk_property_id"`
+	// Path is a gjson path of the snapshot, such as data.system.info.kernelVersion
+	Path        string              `json:"path" bson:"path"`
+	Transforms  []HostSnapTransform `json:"transforms" bson:"transforms"`
+	Description string              `json:"description" bson:"description"`
+
+	Creator         string    `json:"creator" bson:"creator"`
+	Modifier        string    `json:"modifier" bson:"modifier"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time"`
+	LastTime        time.Time `json:"last_time" bson:"last_time"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// Validate validates the mapping and returns the invalid field if any
+func (m HostSnapFieldMapping) Validate() (string, error) {
+	if len(m.PropertyID) == 0 {
+		return common.BKPropertyIDField, fmt.Errorf("property id can not be empty")
+	}
+	switch m.PropertyID {
+	case common.BKHostIDField, common.BKHostInnerIPField, common.BKHostOuterIPField, common.BKCloudIDField,
+		common.BKOwnerIDField:
+		return common.BKPropertyIDField, fmt.Errorf("property %s can not be mapped", m.PropertyID)
+	}
+	if len(strings.TrimSpace(m.Path)) == 0 {
+		return "path", fmt.Errorf("path can not be empty")
+	}
+	for index, transform := range m.Transforms {
+		if key, err := transform.Validate(); err != nil {
+			return fmt.Sprintf("transforms[%d].%s", index, key), err
+		}
+	}
+	return "", nil
+}
+
+type CreateHostSnapFieldMappingOption struct {
+	PropertyID  string              `json:"bk_property_id"`
+	Path        string              `json:"path"`
+	Transforms  []HostSnapTransform `json:"transforms"`
+	Description string              `json:"description"`
+}
+
+// UpdateHostSnapFieldMappingOption replaces the mapping's path and transforms, the mapped property can't be changed.
+type UpdateHostSnapFieldMappingOption struct {
+	Path        string              `json:"path"`
+	Transforms  []HostSnapTransform `json:"transforms"`
+	Description string              `json:"description"`
+}
+
+type ListHostSnapFieldMappingOption struct {
+	PropertyIDs []string `json:"bk_property_ids"`
+	Page        BasePage `json:"page"`
+}
+
+type MultipleHostSnapFieldMapping struct {
+	Count int64                  `json:"count"`
+	Info  []HostSnapFieldMapping `json:"info"`
+}
+
+type DeleteHostSnapFieldMappingOption struct {
+	IDs []int64 `json:"ids"`
+}
+
+// HostSnapMappingDryRunOption is used to preview the host update computed from a sample snapshot message.
+type HostSnapMappingDryRunOption struct {
+	// Message is the snapshot message, it can be the json object or the json string reported by the agent.
+	Message json.RawMessage `json:"message"`
+	// Mappings is optional, the saved mappings of the supplier account are used if it's empty,
+	// which is helpful to test the mappings before they are saved.
+	Mappings []HostSnapFieldMapping `json:"mappings"`
+}
+
+type HostSnapMappingDryRunResult struct {
+	// HostID is 0 if the host of the snapshot can't be found, the Update is empty in this case.
+	HostID int64 `json:"bk_host_id"`
+	// Setter is all the host fields computed from the snapshot
+	Setter map[string]interface{} `json:"setter"`
+	// Update is the fields which differ from the host's current value and will be updated
+	Update map[string]interface{} `json:"update"`
+	// Skipped records why the mapped property is not computed, such as the path doesn't exist.
+	Skipped map[string]string `json:"skipped"`
+}
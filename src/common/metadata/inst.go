@@ -49,6 +49,8 @@ type ModuleInst struct {
 	SetTemplateID     int64  `bson:"set_template_id" json:"set_template_id" field:"set_template_id" mapstructure:"set_template_id"`
 	Default           int64  `bson:"default" json:"default" field:"default" mapstructure:"default"`
 	HostApplyEnabled  bool   `bson:"host_apply_enabled" json:"host_apply_enabled" field:"host_apply_enabled" mapstructure:"host_apply_enabled"`
+	// HostApplyDriftAction is one of the HostApplyDriftAction*, empty means HostApplyDriftActionRecord
+	HostApplyDriftAction string `bson:"host_apply_drift_action" json:"host_apply_drift_action" field:"host_apply_drift_action" mapstructure:"host_apply_drift_action"`
 }
 
 type BizInst struct {
@@ -134,8 +134,9 @@ type QueryObjectAssociationResult struct {
 
 // InstResult inst item result
 type InstResult struct {
-	Count int             `json:"count"`
-	Info  []mapstr.MapStr `json:"info"`
+	Count      int             `json:"count"`
+	Info       []mapstr.MapStr `json:"info"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
 
 // QueryInstResult query inst result
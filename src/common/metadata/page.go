@@ -32,6 +32,9 @@ type BasePage struct {
 	Sort  string `json:"sort,omitempty" mapstructure:"sort"`
 	Limit int    `json:"limit,omitempty" mapstructure:"limit"`
 	Start int    `json:"start" mapstructure:"start"`
+	// Cursor is the next_cursor of the previous page, the page starts after the last item of the previous
+	// page, and the count is the number of the items after the cursor then.
+	Cursor string `json:"cursor,omitempty" mapstructure:"cursor"`
 }
 
 func (page BasePage) Validate(allowNoLimit bool) (string, error) {
@@ -73,6 +76,9 @@ func ParsePage(origin interface{}) BasePage {
 			result.Limit = common.BKNoLimit
 		}
 	}
+	if cursor, ok := page["cursor"]; ok && cursor != nil {
+		result.Cursor = fmt.Sprint(cursor)
+	}
 	return result
 }
 
@@ -0,0 +1,308 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/util"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const pageCursorVersion = "1"
+
+// the types of the sort value in the page cursor, so that the value can be compared with the same type in db
+const (
+	cursorValueNull   = "n"
+	cursorValueString = "s"
+	cursorValueInt    = "i"
+	cursorValueFloat  = "f"
+	cursorValueBool   = "b"
+	cursorValueTime   = "t"
+)
+
+// PageCursor is the position of the last item of a page, the items are sorted by the sort field and then by
+// the unique id field, so the next page starts right after the sort value and the id of the last item, and it
+// does not skip or duplicate items when the items before the cursor are created or deleted.
+type PageCursor struct {
+	Sort  string
+	ID    int64
+	Value interface{}
+}
+
+func (c PageCursor) Encode() (string, error) {
+	valueType, value, err := encodeCursorValue(c.Value)
+	if err != nil {
+		return "", err
+	}
+
+	pool := bytes.Buffer{}
+	// version field.
+	pool.WriteString(pageCursorVersion)
+	pool.WriteByte('\r')
+
+	// sort field.
+	pool.WriteString(c.Sort)
+	pool.WriteByte('\r')
+
+	// id field.
+	pool.WriteString(strconv.FormatInt(c.ID, 10))
+	pool.WriteByte('\r')
+
+	// value type and value field, the value is the last field as it may contain any character.
+	pool.WriteString(valueType)
+	pool.WriteByte('\r')
+	pool.WriteString(value)
+
+	return base64.StdEncoding.EncodeToString(pool.Bytes()), nil
+}
+
+func (c *PageCursor) Decode(cursor string) error {
+	byt, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("decode cursor, but base64 decode failed, err: %v", err)
+	}
+
+	elements := strings.SplitN(string(byt), "\r", 5)
+	if len(elements) != 5 {
+		return errors.New("invalid cursor string")
+	}
+
+	if elements[0] != pageCursorVersion {
+		return fmt.Errorf("decode cursor, but got invalid cursor version: %s", elements[0])
+	}
+	c.Sort = elements[1]
+
+	c.ID, err = strconv.ParseInt(elements[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("got invalid id field %s, err: %v", elements[2], err)
+	}
+
+	c.Value, err = decodeCursorValue(elements[3], elements[4])
+	if err != nil {
+		return fmt.Errorf("got invalid value field %s, err: %v", elements[4], err)
+	}
+	return nil
+}
+
+func encodeCursorValue(value interface{}) (string, string, error) {
+	switch val := value.(type) {
+	case nil:
+		return cursorValueNull, "", nil
+	case string:
+		return cursorValueString, val, nil
+	case bool:
+		return cursorValueBool, strconv.FormatBool(val), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		intVal, err := util.GetInt64ByInterface(val)
+		if err != nil {
+			return "", "", err
+		}
+		return cursorValueInt, strconv.FormatInt(intVal, 10), nil
+	case float32:
+		return cursorValueFloat, strconv.FormatFloat(float64(val), 'g', -1, 64), nil
+	case float64:
+		return cursorValueFloat, strconv.FormatFloat(val, 'g', -1, 64), nil
+	case time.Time:
+		return cursorValueTime, val.UTC().Format(time.RFC3339Nano), nil
+	case primitive.DateTime:
+		return cursorValueTime, time.Unix(0, int64(val)*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano), nil
+	}
+	return "", "", fmt.Errorf("unsupported cursor value type %T", value)
+}
+
+func decodeCursorValue(valueType string, value string) (interface{}, error) {
+	switch valueType {
+	case cursorValueNull:
+		return nil, nil
+	case cursorValueString:
+		return value, nil
+	case cursorValueBool:
+		return strconv.ParseBool(value)
+	case cursorValueInt:
+		return strconv.ParseInt(value, 10, 64)
+	case cursorValueFloat:
+		return strconv.ParseFloat(value, 64)
+	case cursorValueTime:
+		return time.Parse(time.RFC3339Nano, value)
+	}
+	return nil, fmt.Errorf("invalid value type %s", valueType)
+}
+
+// ValidateCursor checks the page is not too deep to be paged by start, and the page cursor is generated
+// with the same sort of the page.
+func (page BasePage) ValidateCursor() (string, error) {
+	if page.Start > common.BKMaxPageStart {
+		return "start", fmt.Errorf("exceed max page start: %d, please page with the cursor", common.BKMaxPageStart)
+	}
+	if len(page.Cursor) == 0 {
+		return "", nil
+	}
+
+	if page.Start != 0 {
+		return "start", errors.New("start can not be used with the cursor")
+	}
+	if strings.Contains(page.Sort, common.BKDBSortFieldSep) {
+		return "sort", errors.New("the cursor can only be used with one sort field")
+	}
+	cursor := PageCursor{}
+	if err := cursor.Decode(page.Cursor); err != nil {
+		return "cursor", err
+	}
+	if cursor.Sort != page.Sort {
+		return "cursor", errors.New("the cursor is generated with another sort")
+	}
+	return "", nil
+}
+
+// sortField returns the sort field of the page and whether it's sorted descending, the id field is the
+// sort field if the page has no sort.
+func (page BasePage) sortField(idField string) (string, bool) {
+	if len(page.Sort) == 0 {
+		return idField, false
+	}
+	if items := strings.Split(page.Sort, ":"); len(items) == 2 {
+		return strings.TrimLeft(items[0], "+-"), strings.TrimSpace(items[1]) == "-1"
+	}
+	return strings.TrimLeft(page.Sort, "+-"), strings.HasPrefix(page.Sort, "-")
+}
+
+// CursorSort returns the sort of the page which makes the cursor available, the items with the same sort
+// value are sorted by the id field, the sort with multiple fields is returned as it is.
+func (page BasePage) CursorSort(idField string) string {
+	if strings.Contains(page.Sort, common.BKDBSortFieldSep) {
+		return page.Sort
+	}
+	field, desc := page.sortField(idField)
+	if field == idField {
+		if desc {
+			return "-" + idField
+		}
+		return idField
+	}
+	if desc {
+		return "-" + field + common.BKDBSortFieldSep + "-" + idField
+	}
+	return field + common.BKDBSortFieldSep + idField
+}
+
+// CursorFields adds the sort field and the id field to the fields if the fields are specified, so that the
+// cursor of the next page can be generated with the last item.
+func (page BasePage) CursorFields(fields []string, idField string) []string {
+	if len(fields) == 0 || (len(fields) == 1 && len(fields[0]) == 0) {
+		return fields
+	}
+	field, _ := page.sortField(idField)
+	return util.StrArrayUnique(append(fields, field, idField))
+}
+
+// CursorFilter returns the filter of the items after the page cursor, returns nil if the cursor is not set.
+func (page BasePage) CursorFilter(idField string) (map[string]interface{}, error) {
+	if len(page.Cursor) == 0 {
+		return nil, nil
+	}
+	if key, err := page.ValidateCursor(); err != nil {
+		return nil, fmt.Errorf("invalid page.%s, err: %v", key, err)
+	}
+	cursor := PageCursor{}
+	if err := cursor.Decode(page.Cursor); err != nil {
+		return nil, err
+	}
+
+	field, desc := page.sortField(idField)
+	operator := common.BKDBGT
+	if desc {
+		operator = common.BKDBLT
+	}
+	idFilter := map[string]interface{}{operator: cursor.ID}
+	if field == idField {
+		return map[string]interface{}{idField: idFilter}, nil
+	}
+
+	// the null values are the smallest, they are at the beginning of the ascending sort and the end of the
+	// descending sort.
+	if cursor.Value == nil {
+		if desc {
+			return map[string]interface{}{field: nil, idField: idFilter}, nil
+		}
+		return map[string]interface{}{common.BKDBOR: []interface{}{
+			map[string]interface{}{field: map[string]interface{}{common.BKDBNE: nil}},
+			map[string]interface{}{field: nil, idField: idFilter},
+		}}, nil
+	}
+
+	filters := []interface{}{
+		map[string]interface{}{field: map[string]interface{}{operator: cursor.Value}},
+		map[string]interface{}{field: cursor.Value, idField: idFilter},
+	}
+	if desc {
+		filters = append(filters, map[string]interface{}{field: nil})
+	}
+	return map[string]interface{}{common.BKDBOR: filters}, nil
+}
+
+// CursorCondition returns the condition of the items after the page cursor, the cursor filter is added to
+// the $and condition so that the top level conditions such as the supplier account are kept as they are.
+func (page BasePage) CursorCondition(cond map[string]interface{}, idField string) (map[string]interface{}, error) {
+	filter, err := page.CursorFilter(idField)
+	if err != nil || filter == nil {
+		return cond, err
+	}
+
+	result := make(map[string]interface{}, len(cond)+1)
+	for key, value := range cond {
+		result[key] = value
+	}
+	and, exists := result[common.BKDBAND]
+	if !exists {
+		result[common.BKDBAND] = []interface{}{filter}
+		return result, nil
+	}
+	if items, ok := and.([]interface{}); ok {
+		result[common.BKDBAND] = append(append(make([]interface{}, 0, len(items)+1), items...), filter)
+		return result, nil
+	}
+	return map[string]interface{}{common.BKDBAND: []interface{}{cond, filter}}, nil
+}
+
+// NextCursor returns the cursor of the next page if the page is full, count is the number of the items in
+// the page and last is the last item of the page. returns an error if the sort value of the last item can
+// not be used in the cursor, such as an array, a map or an object id, even if the page is requested without
+// a cursor, so that an empty cursor always means the last page for the clients paging with the cursor.
+func (page BasePage) NextCursor(idField string, count int, last map[string]interface{}) (string, error) {
+	if page.Limit <= 0 || count < page.Limit || last == nil || strings.Contains(page.Sort, common.BKDBSortFieldSep) {
+		return "", nil
+	}
+
+	id, err := util.GetInt64ByInterface(last[idField])
+	if err != nil {
+		return "", fmt.Errorf("invalid %s value %v of the last item, err: %v", idField, last[idField], err)
+	}
+	cursor := PageCursor{Sort: page.Sort, ID: id}
+	if field, _ := page.sortField(idField); field != idField {
+		cursor.Value = last[field]
+	}
+	encoded, err := cursor.Encode()
+	if err != nil {
+		return "", fmt.Errorf("the sort field %s can not be used with the cursor, err: %v", page.Sort, err)
+	}
+	return encoded, nil
+}
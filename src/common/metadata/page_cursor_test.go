@@ -0,0 +1,211 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPageCursorEncodeDecode(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{"null", nil, nil},
+		{"string", "a\rb", "a\rb"},
+		{"int", 12, int64(12)},
+		{"float", 1.5, 1.5},
+		{"bool", true, true},
+		{"time", now, now},
+		{"mongo time", primitive.NewDateTimeFromTime(now), now.Truncate(time.Millisecond)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := PageCursor{Sort: "-bk_inst_name", ID: 3, Value: tt.value}.Encode()
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			got := PageCursor{}
+			if err := got.Decode(encoded); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			want := PageCursor{Sort: "-bk_inst_name", ID: 3, Value: tt.want}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Decode() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestPageCursorEncodeUnsupportedValue(t *testing.T) {
+	values := []interface{}{
+		[]interface{}{"a"},
+		map[string]interface{}{"a": 1},
+		primitive.NewObjectID(),
+	}
+	for _, value := range values {
+		if _, err := (PageCursor{Sort: "field", ID: 1, Value: value}).Encode(); err == nil {
+			t.Errorf("Encode() with %T value should fail", value)
+		}
+	}
+}
+
+func TestValidateCursor(t *testing.T) {
+	cursor, _ := PageCursor{Sort: "bk_inst_name", ID: 1, Value: "a"}.Encode()
+	tests := []struct {
+		name    string
+		page    BasePage
+		wantKey string
+	}{
+		{"no cursor", BasePage{Sort: "bk_inst_name", Start: 10}, ""},
+		{"cursor", BasePage{Sort: "bk_inst_name", Cursor: cursor}, ""},
+		{"too deep", BasePage{Start: common.BKMaxPageStart + 1}, "start"},
+		{"start with cursor", BasePage{Sort: "bk_inst_name", Start: 1, Cursor: cursor}, "start"},
+		{"multiple sort fields", BasePage{Sort: "bk_inst_name,bk_inst_id", Cursor: cursor}, "sort"},
+		{"another sort", BasePage{Sort: "-bk_inst_name", Cursor: cursor}, "cursor"},
+		{"invalid cursor", BasePage{Sort: "bk_inst_name", Cursor: "invalid"}, "cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := tt.page.ValidateCursor()
+			if key != tt.wantKey || (err != nil) != (tt.wantKey != "") {
+				t.Errorf("ValidateCursor() = %s, %v, want key %s", key, err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestCursorSort(t *testing.T) {
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"", "bk_inst_id"},
+		{"-bk_inst_id", "-bk_inst_id"},
+		{"bk_inst_name", "bk_inst_name,bk_inst_id"},
+		{"-bk_inst_name", "-bk_inst_name,-bk_inst_id"},
+		{"bk_inst_name:-1", "-bk_inst_name,-bk_inst_id"},
+		{"bk_inst_name,create_time", "bk_inst_name,create_time"},
+	}
+	for _, tt := range tests {
+		if got := (BasePage{Sort: tt.sort}).CursorSort("bk_inst_id"); got != tt.want {
+			t.Errorf("CursorSort() with sort %s = %s, want %s", tt.sort, got, tt.want)
+		}
+	}
+}
+
+func TestCursorFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		sort  string
+		value interface{}
+		want  map[string]interface{}
+	}{
+		{
+			name: "id ascending",
+			want: map[string]interface{}{"bk_inst_id": map[string]interface{}{common.BKDBGT: int64(5)}},
+		},
+		{
+			name: "id descending",
+			sort: "-bk_inst_id",
+			want: map[string]interface{}{"bk_inst_id": map[string]interface{}{common.BKDBLT: int64(5)}},
+		},
+		{
+			name:  "ascending",
+			sort:  "bk_inst_name",
+			value: "a",
+			want: map[string]interface{}{common.BKDBOR: []interface{}{
+				map[string]interface{}{"bk_inst_name": map[string]interface{}{common.BKDBGT: "a"}},
+				map[string]interface{}{"bk_inst_name": "a", "bk_inst_id": map[string]interface{}{common.BKDBGT: int64(5)}},
+			}},
+		},
+		{
+			name:  "descending",
+			sort:  "-bk_inst_name",
+			value: "a",
+			want: map[string]interface{}{common.BKDBOR: []interface{}{
+				map[string]interface{}{"bk_inst_name": map[string]interface{}{common.BKDBLT: "a"}},
+				map[string]interface{}{"bk_inst_name": "a", "bk_inst_id": map[string]interface{}{common.BKDBLT: int64(5)}},
+				map[string]interface{}{"bk_inst_name": nil},
+			}},
+		},
+		{
+			name: "null ascending",
+			sort: "bk_inst_name",
+			want: map[string]interface{}{common.BKDBOR: []interface{}{
+				map[string]interface{}{"bk_inst_name": map[string]interface{}{common.BKDBNE: nil}},
+				map[string]interface{}{"bk_inst_name": nil, "bk_inst_id": map[string]interface{}{common.BKDBGT: int64(5)}},
+			}},
+		},
+		{
+			name: "null descending",
+			sort: "-bk_inst_name",
+			want: map[string]interface{}{"bk_inst_name": nil, "bk_inst_id": map[string]interface{}{common.BKDBLT: int64(5)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := PageCursor{Sort: tt.sort, ID: 5, Value: tt.value}.Encode()
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			got, err := BasePage{Sort: tt.sort, Cursor: cursor}.CursorFilter("bk_inst_id")
+			if err != nil {
+				t.Fatalf("CursorFilter() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CursorFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	page := BasePage{Sort: "-bk_inst_name", Limit: 2}
+	last := map[string]interface{}{"bk_inst_id": 7, "bk_inst_name": "b"}
+
+	if next, err := page.NextCursor("bk_inst_id", 1, last); next != "" || err != nil {
+		t.Errorf("NextCursor() of the last page = %s, %v, want no cursor", next, err)
+	}
+
+	next, err := page.NextCursor("bk_inst_id", 2, last)
+	if err != nil {
+		t.Fatalf("NextCursor() error = %v", err)
+	}
+	cursor := PageCursor{}
+	if err := cursor.Decode(next); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want := PageCursor{Sort: "-bk_inst_name", ID: 7, Value: "b"}
+	if !reflect.DeepEqual(cursor, want) {
+		t.Errorf("NextCursor() = %+v, want %+v", cursor, want)
+	}
+
+	last["bk_inst_name"] = []interface{}{"b"}
+	if next, err := page.NextCursor("bk_inst_id", 2, last); next != "" || err == nil {
+		t.Errorf("NextCursor() of the first page with an array sort value = %s, %v, want an error", next, err)
+	}
+
+	page.Cursor = next
+	if _, err := page.NextCursor("bk_inst_id", 2, last); err == nil {
+		t.Errorf("NextCursor() with an array sort value should fail when the page is requested with a cursor")
+	}
+}
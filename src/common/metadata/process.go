@@ -269,6 +269,19 @@ type SyncModuleServiceInstanceByTemplateOption struct {
 	ModuleID int64     `json:"bk_module_id"`
 }
 
+// SyncServiceInstanceTaskOption 用于创建服务实例同步任务, 每个模块对应一个子任务
+type SyncServiceInstanceTaskOption struct {
+	BizID     int64   `json:"bk_biz_id"`
+	ModuleIDs []int64 `json:"bk_module_ids"`
+	// Priority 任务优先级, 默认为TaskPriorityNormal
+	Priority int64 `json:"priority"`
+}
+
+// GetServiceInstanceSyncIndex 返回task_server中服务实例同步任务的检索值(flag)
+func GetServiceInstanceSyncIndex(bizID int64) string {
+	return fmt.Sprintf("service_instance_sync:%d", bizID)
+}
+
 type ListServiceInstancesWithHostInput struct {
 	Metadata  *Metadata          `json:"metadata"`
 	BizID     int64              `json:"bk_biz_id"`
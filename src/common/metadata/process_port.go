@@ -0,0 +1,103 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"errors"
+)
+
+// PortConflictPolicy is the business's policy when the ports of processes on the same host overlap.
+type PortConflictPolicy string
+
+const (
+	// PortConflictPolicyWarn accept the change and log the conflicts, it's the default policy.
+	PortConflictPolicyWarn PortConflictPolicy = "warn"
+	// PortConflictPolicyReject reject the change which makes the ports conflict.
+	PortConflictPolicyReject PortConflictPolicy = "reject"
+)
+
+// ProcessPortConflict describes two processes on the same host listening on overlapping ports.
+type ProcessPortConflict struct {
+	HostID              int64        `json:"bk_host_id"`
+	ProcessID           int64        `json:"bk_process_id"`
+	ProcessName         string       `json:"bk_process_name"`
+	ConflictProcessID   int64        `json:"conflict_process_id"`
+	ConflictProcessName string       `json:"conflict_process_name"`
+	BindIP              string       `json:"bind_ip"`
+	Protocol            ProtocolType `json:"protocol"`
+	// Ports is the overlapped ports, in the same format with the process's port.
+	Ports string `json:"ports"`
+}
+
+// ProcessPortConflictResponse is the response of the process operations, the port conflicts found under the warn
+// policy are returned beside the data, so that the data keeps compatible with the existing callers.
+type ProcessPortConflictResponse struct {
+	Response      `json:",inline"`
+	PortConflicts []ProcessPortConflict `json:"port_conflicts"`
+}
+
+type CheckProcessPortConflictOption struct {
+	BizID   int64   `json:"bk_biz_id"`
+	HostIDs []int64 `json:"bk_host_ids"`
+}
+
+func (o *CheckProcessPortConflictOption) Validate() (string, error) {
+	if o.BizID <= 0 {
+		return "bk_biz_id", errors.New("bk_biz_id should be positive")
+	}
+	if len(o.HostIDs) == 0 {
+		return "bk_host_ids", errors.New("bk_host_ids can not be empty")
+	}
+	if len(o.HostIDs) > 200 {
+		return "bk_host_ids", errors.New("bk_host_ids exceed max length 200")
+	}
+	return "", nil
+}
+
+// FindFreePortOption find the ports which are not used by any process on the host with the bind ip and protocol.
+type FindFreePortOption struct {
+	BizID  int64 `json:"bk_biz_id"`
+	HostID int64 `json:"bk_host_id"`
+	// BindIP and Protocol are the ones the new process is going to use, empty means any.
+	BindIP   string       `json:"bind_ip"`
+	Protocol ProtocolType `json:"protocol"`
+	// PortRange is the range the ports are picked from, such as 8000-9000, in the same format with the process's port.
+	PortRange string `json:"port_range"`
+	// Count is the number of the ports to find, default is 1.
+	Count int `json:"count"`
+}
+
+func (o *FindFreePortOption) Validate() (string, error) {
+	if o.BizID <= 0 {
+		return "bk_biz_id", errors.New("bk_biz_id should be positive")
+	}
+	if o.HostID <= 0 {
+		return "bk_host_id", errors.New("bk_host_id should be positive")
+	}
+	if len(o.Protocol) != 0 {
+		if err := o.Protocol.Validate(); err != nil {
+			return "protocol", err
+		}
+	}
+	if !ProcessPortFormat.MatchString(o.PortRange) {
+		return "port_range", errors.New("port_range format invalid")
+	}
+	if o.Count < 0 || o.Count > 100 {
+		return "count", errors.New("count should be between 0 and 100")
+	}
+	return "", nil
+}
+
+type FindFreePortResult struct {
+	Ports []int `json:"ports"`
+}
@@ -51,8 +51,10 @@ func (qc *QueryCondition) IsIllegal() bool {
 
 // QueryResult common query result
 type QueryResult struct {
-	Count uint64          `json:"count"`
-	Info  []mapstr.MapStr `json:"info"`
+	Count      uint64          `json:"count"`
+	Info       []mapstr.MapStr `json:"info"`
+	// NextCursor is the cursor of the next page, it's empty if the page is the last page
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
 
 type QueryConditionResult ResponseInstData
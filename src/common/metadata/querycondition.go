@@ -31,6 +31,7 @@ type ObjQueryInput struct {
 	Start     int         `json:"start"`
 	Limit     int         `json:"limit"`
 	Sort      string      `json:"sort"`
+	Cursor    string      `json:"cursor,omitempty"`
 }
 
 // ConvTime 将查询条件中字段包含cc_type key ，子节点变为time.Time
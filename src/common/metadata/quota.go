@@ -0,0 +1,159 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"errors"
+	"time"
+
+	"configcenter/src/common"
+)
+
+// QuotaResource is the resource which can be limited by the quota
+type QuotaResource string
+
+const (
+	QuotaResourceHost   QuotaResource = "host"
+	QuotaResourceSet    QuotaResource = "set"
+	QuotaResourceModule QuotaResource = "module"
+	// QuotaResourceInstance is the instances of the custom objects, including the custom mainline objects
+	QuotaResourceInstance  QuotaResource = "instance"
+	QuotaResourceAttribute QuotaResource = "attribute"
+)
+
+// QuotaResources are all the resources which can be limited by the quota
+var QuotaResources = []QuotaResource{
+	QuotaResourceHost,
+	QuotaResourceSet,
+	QuotaResourceModule,
+	QuotaResourceInstance,
+	QuotaResourceAttribute,
+}
+
+// GetQuotaResource returns the quota resource of the object's instances
+func GetQuotaResource(objID string) (QuotaResource, bool) {
+	switch objID {
+	case common.BKInnerObjIDHost:
+		return QuotaResourceHost, true
+	case common.BKInnerObjIDSet:
+		return QuotaResourceSet, true
+	case common.BKInnerObjIDModule:
+		return QuotaResourceModule, true
+	}
+	if common.IsInnerModel(objID) {
+		return "", false
+	}
+	return QuotaResourceInstance, true
+}
+
+// QuotaScope is the scope the quota is counted in
+type QuotaScope string
+
+const (
+	// QuotaScopeTenant limits the resources of the whole supplier account
+	QuotaScopeTenant QuotaScope = "tenant"
+	// QuotaScopeBusiness limits the resources of a business, the quota with business id 0 is the
+	// default quota of all the businesses except the resource pool, the quota of a specified business
+	// overrides the default quota.
+	QuotaScopeBusiness QuotaScope = "business"
+)
+
+// QuotaUnlimited is the limit of an overridden business quota which means the resource is not limited
+const QuotaUnlimited int64 = -1
+
+type Quota struct {
+	ID       int64         `json:"id" bson:"id" mapstructure:"id"`
+	Scope    QuotaScope    `json:"scope" bson:"scope" mapstructure:"scope"`
+	BizID    int64         `json:"bk_biz_id" bson:"bk_biz_id" mapstructure:"bk_biz_id"`
+	Resource QuotaResource `json:"resource" bson:"resource" mapstructure:"resource"`
+	Limit    int64         `json:"limit" bson:"limit" mapstructure:"limit"`
+
+	Creator         string    `json:"creator" bson:"creator" mapstructure:"creator"`
+	Modifier        string    `json:"modifier" bson:"modifier" mapstructure:"modifier"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time" mapstructure:"create_time"`
+	LastTime        time.Time `json:"last_time" bson:"last_time" mapstructure:"last_time"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account" mapstructure:"bk_supplier_account"`
+}
+
+// QuotaKey identifies a quota of a resource in the scope
+type QuotaKey struct {
+	Scope    QuotaScope    `json:"scope" mapstructure:"scope"`
+	BizID    int64         `json:"bk_biz_id" mapstructure:"bk_biz_id"`
+	Resource QuotaResource `json:"resource" mapstructure:"resource"`
+}
+
+func (k *QuotaKey) Validate() (string, error) {
+	switch k.Scope {
+	case QuotaScopeTenant:
+		if k.BizID != 0 {
+			return common.BKAppIDField, errors.New("the tenant quota can not be set to a business")
+		}
+	case QuotaScopeBusiness:
+		if k.BizID < 0 {
+			return common.BKAppIDField, errors.New("invalid business id")
+		}
+	default:
+		return "scope", errors.New("invalid quota scope")
+	}
+
+	for _, resource := range QuotaResources {
+		if k.Resource == resource {
+			return "", nil
+		}
+	}
+	return "resource", errors.New("invalid quota resource")
+}
+
+// SetQuotaOption creates or updates the quota of the key
+type SetQuotaOption struct {
+	QuotaKey `json:",inline" mapstructure:",squash"`
+	Limit    int64 `json:"limit" mapstructure:"limit"`
+}
+
+func (o *SetQuotaOption) Validate() (string, error) {
+	if field, err := o.QuotaKey.Validate(); err != nil {
+		return field, err
+	}
+	if o.Limit < 0 {
+		// only the quota of a specified business can be overridden to be unlimited
+		if o.Limit != QuotaUnlimited || o.Scope != QuotaScopeBusiness || o.BizID == 0 {
+			return "limit", errors.New("invalid limit")
+		}
+	}
+	return "", nil
+}
+
+// ListQuotaUsageOption lists the quotas and usages of the tenant, or the business if the business id is set
+type ListQuotaUsageOption struct {
+	BizID int64 `json:"bk_biz_id" mapstructure:"bk_biz_id"`
+}
+
+// QuotaUsage is the limit and usage of a resource in the scope, the limit of the business is the
+// overridden quota of the business or the default business quota.
+type QuotaUsage struct {
+	QuotaKey `json:",inline" mapstructure:",squash"`
+	// Limit is nil if the resource is not limited
+	Limit      *int64 `json:"limit" mapstructure:"limit"`
+	Used       int64  `json:"used" mapstructure:"used"`
+	Overridden bool   `json:"overridden" mapstructure:"overridden"`
+}
+
+type QuotaResult struct {
+	BaseResp `json:",inline"`
+	Data     Quota `json:"data"`
+}
+
+type QuotaUsageResult struct {
+	BaseResp `json:",inline"`
+	Data     []QuotaUsage `json:"data"`
+}
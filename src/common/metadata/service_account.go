@@ -0,0 +1,269 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"configcenter/src/common/util"
+)
+
+const (
+	// ServiceAccountUserPrefix is the prefix of the user name which the requests authenticated by a service
+	// account's api token are executed as, so that the service accounts can be told from humans in audit logs.
+	ServiceAccountUserPrefix = "service_account:"
+	// APITokenPrefix is the prefix of the api tokens, it helps to find the tokens leaked in logs or codes.
+	APITokenPrefix = "cmdb_"
+	// apiTokenDisplayLength is the length of the token's prefix which is saved to identify the token.
+	apiTokenDisplayLength = 12
+)
+
+var serviceAccountNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_\-.]{0,63}$`)
+
+// ServiceAccount is the identity of automation scripts, it's authenticated by it's api tokens.
+type ServiceAccount struct {
+	ID          int64  `json:"id" bson:"id" mapstructure:"id"`
+	Name        string `json:"name" bson:"name" mapstructure:"name"`
+	Description string `json:"description" bson:"description" mapstructure:"description"`
+	// Enabled is false when the service account is disabled, all of it's api tokens are invalid then.
+	Enabled         bool      `json:"enabled" bson:"enabled" mapstructure:"enabled"`
+	Creator         string    `json:"creator" bson:"creator" mapstructure:"creator"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time" mapstructure:"create_time"`
+	LastTime        time.Time `json:"last_time" bson:"last_time" mapstructure:"last_time"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account" mapstructure:"bk_supplier_account"`
+}
+
+// UserName returns the user name which the service account's requests are executed as.
+func (s *ServiceAccount) UserName() string {
+	return ServiceAccountUserPrefix + s.Name
+}
+
+// APITokenScope is the kind of operations an api token can do.
+type APITokenScope string
+
+const (
+	// APITokenScopeRead allows the token to find resources.
+	APITokenScopeRead APITokenScope = "read"
+	// APITokenScopeWrite allows the token to create, update and delete resources.
+	APITokenScopeWrite APITokenScope = "write"
+)
+
+// APIToken is the credential of a service account, only it's hash is saved.
+type APIToken struct {
+	ID               int64  `json:"id" bson:"id" mapstructure:"id"`
+	ServiceAccountID int64  `json:"service_account_id" bson:"service_account_id" mapstructure:"service_account_id"`
+	Description      string `json:"description" bson:"description" mapstructure:"description"`
+	// TokenHash is the sha256 hash of the token, it's never returned by the api.
+	TokenHash string `json:"-" bson:"token_hash" mapstructure:"-"`
+	// TokenPrefix is the beginning of the token, used to identify the token without revealing it.
+	TokenPrefix string `json:"token_prefix" bson:"token_prefix" mapstructure:"token_prefix"`
+
+	// SupplierAccounts, BizIDs and Scopes restrict the requests which can be done with the token,
+	// empty BizIDs means all businesses are allowed.
+	SupplierAccounts []string        `json:"bk_supplier_accounts" bson:"bk_supplier_accounts" mapstructure:"bk_supplier_accounts"`
+	BizIDs           []int64         `json:"bk_biz_ids" bson:"bk_biz_ids" mapstructure:"bk_biz_ids"`
+	Scopes           []APITokenScope `json:"scopes" bson:"scopes" mapstructure:"scopes"`
+
+	ExpireTime      time.Time `json:"expire_time" bson:"expire_time" mapstructure:"expire_time"`
+	Revoked         bool      `json:"revoked" bson:"revoked" mapstructure:"revoked"`
+	LastUsedTime    time.Time `json:"last_used_time" bson:"last_used_time" mapstructure:"last_used_time"`
+	Creator         string    `json:"creator" bson:"creator" mapstructure:"creator"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time" mapstructure:"create_time"`
+	LastTime        time.Time `json:"last_time" bson:"last_time" mapstructure:"last_time"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account" mapstructure:"bk_supplier_account"`
+}
+
+// IsValid returns true if the token is not revoked and not expired.
+func (t *APIToken) IsValid(now time.Time) bool {
+	return !t.Revoked && t.ExpireTime.After(now)
+}
+
+// HasScope check if the token is granted the scope, the write scope includes the read scope.
+func (t *APIToken) HasScope(scope APITokenScope) bool {
+	for _, s := range t.Scopes {
+		if s == scope || s == APITokenScopeWrite {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowSupplierAccount check if the token can be used with the supplier account.
+func (t *APIToken) AllowSupplierAccount(supplierAccount string) bool {
+	return util.InStrArr(t.SupplierAccounts, supplierAccount)
+}
+
+// AllowBiz check if the token can be used to operate the business.
+func (t *APIToken) AllowBiz(bizID int64) bool {
+	if len(t.BizIDs) == 0 {
+		return true
+	}
+	for _, id := range t.BizIDs {
+		if id == bizID {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAPIToken generate a random api token, returns the token and it's hash.
+func NewAPIToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token := APITokenPrefix + hex.EncodeToString(raw)
+	return token, HashAPIToken(token), nil
+}
+
+// HashAPIToken returns the hash of the api token which is saved instead of the token, the tokens are
+// random enough that a salt is not needed.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// APITokenDisplayPrefix returns the prefix of the token which is saved to identify it.
+func APITokenDisplayPrefix(token string) string {
+	if len(token) <= apiTokenDisplayLength {
+		return token
+	}
+	return token[:apiTokenDisplayLength]
+}
+
+type CreateServiceAccountOption struct {
+	Name        string `json:"name" mapstructure:"name"`
+	Description string `json:"description" mapstructure:"description"`
+}
+
+// Validate validate the option, returns the invalid field's name if it's invalid.
+func (o *CreateServiceAccountOption) Validate() (string, error) {
+	if !serviceAccountNameRegexp.MatchString(o.Name) {
+		return "name", errors.New("name should start with a letter and only contain letters, digits, _, - and .")
+	}
+	return "", nil
+}
+
+type UpdateServiceAccountOption struct {
+	Description *string `json:"description" mapstructure:"description"`
+	Enabled     *bool   `json:"enabled" mapstructure:"enabled"`
+}
+
+type ListServiceAccountOption struct {
+	IDs     []int64 `json:"ids" mapstructure:"ids"`
+	Name    string  `json:"name" mapstructure:"name"`
+	Enabled *bool   `json:"enabled" mapstructure:"enabled"`
+	// Creator filter the service accounts created by the user, it's always the request user in the api server.
+	Creator string   `json:"creator" mapstructure:"creator"`
+	Page    BasePage `json:"page" mapstructure:"page"`
+}
+
+type MultipleServiceAccount struct {
+	Count int64            `json:"count" mapstructure:"count"`
+	Info  []ServiceAccount `json:"info" mapstructure:"info"`
+}
+
+type CreateAPITokenOption struct {
+	Description string `json:"description" mapstructure:"description"`
+	// SupplierAccounts can only be the supplier account of the creator, which is also the default if it's empty.
+	SupplierAccounts []string        `json:"bk_supplier_accounts" mapstructure:"bk_supplier_accounts"`
+	BizIDs           []int64         `json:"bk_biz_ids" mapstructure:"bk_biz_ids"`
+	Scopes           []APITokenScope `json:"scopes" mapstructure:"scopes"`
+	ExpireTime       time.Time       `json:"expire_time" mapstructure:"expire_time"`
+}
+
+// Validate validate the option, returns the invalid field's name if it's invalid.
+func (o *CreateAPITokenOption) Validate(now time.Time) (string, error) {
+	if len(o.Scopes) == 0 {
+		return "scopes", errors.New("scopes can not be empty")
+	}
+	for _, scope := range o.Scopes {
+		if scope != APITokenScopeRead && scope != APITokenScopeWrite {
+			return "scopes", errors.New("scope should be read or write")
+		}
+	}
+	for _, supplierAccount := range o.SupplierAccounts {
+		if len(strings.TrimSpace(supplierAccount)) == 0 {
+			return "bk_supplier_accounts", errors.New("supplier account can not be empty")
+		}
+	}
+	for _, bizID := range o.BizIDs {
+		if bizID <= 0 {
+			return "bk_biz_ids", errors.New("business id should be positive")
+		}
+	}
+	if !o.ExpireTime.After(now) {
+		return "expire_time", errors.New("expire time should be later than now")
+	}
+	return "", nil
+}
+
+// CreateAPITokenResult contains the token which is only returned when it's created.
+type CreateAPITokenResult struct {
+	Token string   `json:"token" mapstructure:"token"`
+	Info  APIToken `json:"info" mapstructure:"info"`
+}
+
+type ListAPITokenOption struct {
+	ServiceAccountID int64    `json:"service_account_id" mapstructure:"service_account_id"`
+	IDs              []int64  `json:"ids" mapstructure:"ids"`
+	Revoked          *bool    `json:"revoked" mapstructure:"revoked"`
+	Page             BasePage `json:"page" mapstructure:"page"`
+}
+
+type MultipleAPIToken struct {
+	Count int64      `json:"count" mapstructure:"count"`
+	Info  []APIToken `json:"info" mapstructure:"info"`
+}
+
+// ValidateAPITokenOption find the api token by it's hash, so that the token itself is not passed around.
+type ValidateAPITokenOption struct {
+	TokenHash string `json:"token_hash" mapstructure:"token_hash"`
+}
+
+// ValidAPIToken is a valid api token and the enabled service account it belongs to.
+type ValidAPIToken struct {
+	Token          APIToken       `json:"token" mapstructure:"token"`
+	ServiceAccount ServiceAccount `json:"service_account" mapstructure:"service_account"`
+}
+
+type ServiceAccountResult struct {
+	BaseResp `json:",inline"`
+	Data     ServiceAccount `json:"data"`
+}
+
+type MultipleServiceAccountResult struct {
+	BaseResp `json:",inline"`
+	Data     MultipleServiceAccount `json:"data"`
+}
+
+type CreateAPITokenResponse struct {
+	BaseResp `json:",inline"`
+	Data     CreateAPITokenResult `json:"data"`
+}
+
+type MultipleAPITokenResult struct {
+	BaseResp `json:",inline"`
+	Data     MultipleAPIToken `json:"data"`
+}
+
+type ValidAPITokenResult struct {
+	BaseResp `json:",inline"`
+	Data     ValidAPIToken `json:"data"`
+}
@@ -0,0 +1,78 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPITokenIsValid(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		token APIToken
+		valid bool
+	}{
+		{token: APIToken{ExpireTime: now.Add(time.Hour)}, valid: true},
+		{token: APIToken{ExpireTime: now.Add(time.Hour), Revoked: true}, valid: false},
+		{token: APIToken{ExpireTime: now.Add(-time.Second)}, valid: false},
+		{token: APIToken{ExpireTime: now}, valid: false},
+		{token: APIToken{}, valid: false},
+	}
+
+	for _, test := range tests {
+		if test.token.IsValid(now) != test.valid {
+			t.Errorf("token expire at %v, revoked %v should be valid %v", test.token.ExpireTime, test.token.Revoked,
+				test.valid)
+		}
+	}
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	read := APIToken{Scopes: []APITokenScope{APITokenScopeRead}}
+	write := APIToken{Scopes: []APITokenScope{APITokenScopeWrite}}
+	if !read.HasScope(APITokenScopeRead) || read.HasScope(APITokenScopeWrite) {
+		t.Errorf("read token should only have the read scope")
+	}
+	if !write.HasScope(APITokenScopeRead) || !write.HasScope(APITokenScopeWrite) {
+		t.Errorf("write token should have both the read and write scopes")
+	}
+	if (&APIToken{}).HasScope(APITokenScopeRead) {
+		t.Errorf("token without scopes should have no scope")
+	}
+
+	token := APIToken{SupplierAccounts: []string{"0"}, BizIDs: []int64{2, 3}}
+	if !token.AllowSupplierAccount("0") || token.AllowSupplierAccount("1") || token.AllowSupplierAccount("") {
+		t.Errorf("token should only allow supplier account 0")
+	}
+	if !token.AllowBiz(2) || !token.AllowBiz(3) || token.AllowBiz(4) {
+		t.Errorf("token should only allow business 2 and 3")
+	}
+	if !(&APIToken{}).AllowBiz(4) {
+		t.Errorf("token without businesses should allow all businesses")
+	}
+}
+
+func TestNewAPIToken(t *testing.T) {
+	token, hash, err := NewAPIToken()
+	if err != nil {
+		t.Fatalf("new api token failed, err: %v", err)
+	}
+	if !strings.HasPrefix(token, APITokenPrefix) || hash != HashAPIToken(token) || hash == HashAPIToken(token+"x") {
+		t.Errorf("unexpected token %s and hash %s", APITokenDisplayPrefix(token), hash)
+	}
+	if another, _, _ := NewAPIToken(); another == token {
+		t.Errorf("the tokens should be random")
+	}
+}
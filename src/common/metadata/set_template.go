@@ -27,6 +27,9 @@ type SetTemplate struct {
 
 	Version int64 `field:"version" json:"version" bson:"version" mapstructure:"version"`
 
+	// 同步策略, 为空时只能手动同步
+	SyncPolicy *SetTemplateSyncPolicy `field:"sync_policy" json:"sync_policy,omitempty" bson:"sync_policy,omitempty"`
+
 	// 通用字段
 	Creator         string    `field:"creator" json:"creator" bson:"creator"`
 	Modifier        string    `field:"modifier" json:"modifier" bson:"modifier"`
@@ -78,9 +81,17 @@ type SetTemplateSyncStatus struct {
 
 	Status SyncStatus `field:"status" json:"status" bson:"status" mapstructure:"status"`
 	TaskID string     `field:"task_id" json:"task_id" bson:"task_id" mapstructure:"task_id"`
+
+	// 触发同步的方式: 手动、模板变更自动同步或定时同步
+	TriggerType SyncTriggerType `field:"trigger_type" json:"trigger_type" bson:"trigger_type" mapstructure:"trigger_type"`
 }
 
 // GetSetTemplateSyncIndex 返回task_server中任务的检索值(flag)
 func GetSetTemplateSyncIndex(setID int64) string {
 	return fmt.Sprintf("set_template_sync:%d", setID)
 }
+
+// GetSetTemplateInstantiateIndex 返回task_server中集群模板实例化任务的检索值(flag)
+func GetSetTemplateInstantiateIndex(bizID, setTemplateID int64) string {
+	return fmt.Sprintf("set_template_instantiate:%d:%d", bizID, setTemplateID)
+}
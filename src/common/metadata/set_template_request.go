@@ -15,6 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"configcenter/src/common"
 )
@@ -90,6 +91,9 @@ type DiffSetTplWithInstOption struct {
 
 type SyncSetTplToInstOption struct {
 	SetIDs []int64 `field:"bk_set_ids" json:"bk_set_ids" bson:"bk_set_ids" mapstructure:"bk_set_ids"`
+
+	// TriggerType is set by the sync policy scheduler, sync requested by users are always manual
+	TriggerType SyncTriggerType `json:"-"`
 }
 
 type SetSyncStatusOption struct {
@@ -135,7 +139,8 @@ type SyncModuleTask struct {
 	SetTopoPath []TopoInstanceNodeSimplify `json:"set_topo_path"`
 	ModuleDiff  SetModuleDiff              `json:"module_diff"`
 
-	SetTemplateVersion int64 `json:"set_template_version"`
+	SetTemplateVersion int64           `json:"set_template_version"`
+	TriggerType        SyncTriggerType `json:"trigger_type"`
 }
 
 var (
@@ -223,3 +228,110 @@ type SetTemplateUpdateToDateStatus struct {
 type BatchCheckSetInstUpdateToDateStatusOption struct {
 	SetTemplateIDs []int64 `field:"set_template_ids" json:"set_template_ids" bson:"set_template_ids" mapstructure:"set_template_ids"`
 }
+
+const (
+	// SetTemplateInstantiateIndexPlaceholder is replaced by the index of the set in the name pattern
+	SetTemplateInstantiateIndexPlaceholder = "{index}"
+	// SetTemplateInstantiateMaxCount the max count of sets created by one instantiation
+	SetTemplateInstantiateMaxCount = 200
+)
+
+// InstantiateSetTemplateOption create sets from the set template, the set names are generated by the name pattern
+// and the index range, such as pattern game-zone-{index} with range 1-50 and width 3 generates game-zone-001 to
+// game-zone-050.
+type InstantiateSetTemplateOption struct {
+	// ParentID is the parent instance of the sets, the business is used if not set
+	ParentID    int64  `json:"bk_parent_id"`
+	NamePattern string `json:"name_pattern"`
+	// Start and End are the index range of the sets, both are included
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	// Width is the min width of the index, the index is padded with zeros
+	Width int `json:"width"`
+	// Attributes are the attributes shared by all the sets
+	Attributes map[string]interface{} `json:"attributes"`
+	// Overrides are the attributes of a set which override the shared attributes, keyed by the set name
+	Overrides map[string]map[string]interface{} `json:"overrides"`
+}
+
+// instantiateReservedFields are the set fields decided by the instantiation, they can not be set in the attributes
+var instantiateReservedFields = []string{
+	common.BKAppIDField,
+	common.BKSetIDField,
+	common.BKSetNameField,
+	common.BKParentIDField,
+	common.BKSetTemplateIDField,
+	common.BKSetTemplateVersionField,
+	common.BKDefaultField,
+}
+
+// Validate validates the option, returns the invalid field if failed.
+func (option InstantiateSetTemplateOption) Validate() (string, error) {
+	if !strings.Contains(option.NamePattern, SetTemplateInstantiateIndexPlaceholder) {
+		return "name_pattern", fmt.Errorf("name pattern must contain %s", SetTemplateInstantiateIndexPlaceholder)
+	}
+	if option.Start < 0 || option.End < option.Start {
+		return "end", errors.New("index range is invalid")
+	}
+	if option.End-option.Start+1 > SetTemplateInstantiateMaxCount {
+		return "end", fmt.Errorf("at most %d sets can be created at once", SetTemplateInstantiateMaxCount)
+	}
+	if option.Width < 0 || option.Width > 10 {
+		return "width", errors.New("width must be between 0 and 10")
+	}
+
+	for _, field := range instantiateReservedFields {
+		if _, exists := option.Attributes[field]; exists {
+			return field, fmt.Errorf("field %s can not be set", field)
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, name := range option.SetNames() {
+		names[name] = true
+	}
+	for name, attributes := range option.Overrides {
+		if !names[name] {
+			return "overrides", fmt.Errorf("set %s is not in the index range", name)
+		}
+		for _, field := range instantiateReservedFields {
+			if _, exists := attributes[field]; exists {
+				return field, fmt.Errorf("field %s can not be set", field)
+			}
+		}
+	}
+	return "", nil
+}
+
+// SetNames returns the names of the sets in the index order
+func (option InstantiateSetTemplateOption) SetNames() []string {
+	names := make([]string, 0)
+	for index := option.Start; index <= option.End; index++ {
+		name := strings.Replace(option.NamePattern, SetTemplateInstantiateIndexPlaceholder,
+			fmt.Sprintf("%0*d", option.Width, index), -1)
+		names = append(names, name)
+	}
+	return names
+}
+
+// SetData returns the attributes of the set with the name, the overrides take precedence over the shared attributes
+func (option InstantiateSetTemplateOption) SetData(name string) map[string]interface{} {
+	data := make(map[string]interface{})
+	for key, value := range option.Attributes {
+		data[key] = value
+	}
+	for key, value := range option.Overrides[name] {
+		data[key] = value
+	}
+	data[common.BKSetNameField] = name
+	return data
+}
+
+// InstantiateSetTask the sub task of the set template instantiation, each task creates one set and its modules
+type InstantiateSetTask struct {
+	Header        http.Header            `json:"header"`
+	BizID         int64                  `json:"bk_biz_id"`
+	SetTemplateID int64                  `json:"set_template_id"`
+	ParentID      int64                  `json:"bk_parent_id"`
+	Set           map[string]interface{} `json:"set"`
+}
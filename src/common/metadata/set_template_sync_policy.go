@@ -0,0 +1,175 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+// SetTemplateSyncPolicyEventType is the event object type pushed when a set template's sync policy is paused
+// because of a failed synchronization.
+const SetTemplateSyncPolicyEventType = "set_template_sync_policy"
+
+const (
+	// SetTemplateSyncDefaultConcurrency is the default max number of sets synchronized at the same time
+	SetTemplateSyncDefaultConcurrency = 10
+	// SetTemplateSyncMaxConcurrency is the upper limit of the max number of sets synchronized at the same time
+	SetTemplateSyncMaxConcurrency = 100
+)
+
+// SyncPolicyType is the way how sets are synchronized with their set template
+type SyncPolicyType string
+
+const (
+	// SyncPolicyManual sets are only synchronized by users
+	SyncPolicyManual SyncPolicyType = "manual"
+	// SyncPolicyAuto sets are synchronized automatically once the set template changed
+	SyncPolicyAuto SyncPolicyType = "auto"
+	// SyncPolicyCron sets are synchronized periodically by a cron expression
+	SyncPolicyCron SyncPolicyType = "cron"
+)
+
+// SyncTriggerType is what triggered a set synchronization
+type SyncTriggerType string
+
+const (
+	SyncTriggerManual SyncTriggerType = "manual"
+	SyncTriggerAuto   SyncTriggerType = "auto"
+	SyncTriggerCron   SyncTriggerType = "cron"
+)
+
+// MaintenanceWindow is a daily time range in "HH:MM" format in which automatic synchronization is allowed,
+// a window whose end is before its start spans midnight.
+type MaintenanceWindow struct {
+	Start string `field:"start" json:"start" bson:"start" mapstructure:"start"`
+	End   string `field:"end" json:"end" bson:"end" mapstructure:"end"`
+}
+
+func (w MaintenanceWindow) Validate() (key string, err error) {
+	if _, err := parseClock(w.Start); err != nil {
+		return "maintenance_window.start", err
+	}
+	if _, err := parseClock(w.End); err != nil {
+		return "maintenance_window.end", err
+	}
+	if w.Start == w.End {
+		return "maintenance_window.end", fmt.Errorf("maintenance window start and end can not be the same")
+	}
+	return "", nil
+}
+
+// Contains check whether t is in the maintenance window
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return false
+	}
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %s, should be in HH:MM format", clock)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// SetTemplateSyncPolicy defines how the sets of a set template are synchronized with it
+type SetTemplateSyncPolicy struct {
+	Type              SyncPolicyType     `field:"type" json:"type" bson:"type" mapstructure:"type"`
+	Cron              string             `field:"cron" json:"cron" bson:"cron" mapstructure:"cron"`
+	MaintenanceWindow *MaintenanceWindow `field:"maintenance_window" json:"maintenance_window" bson:"maintenance_window" mapstructure:"maintenance_window"`
+	MaxConcurrency    int64              `field:"max_concurrency" json:"max_concurrency" bson:"max_concurrency" mapstructure:"max_concurrency"`
+
+	// a failed automatic synchronization pauses the policy until users resume it
+	Paused      bool   `field:"paused" json:"paused" bson:"paused" mapstructure:"paused"`
+	PauseReason string `field:"pause_reason" json:"pause_reason" bson:"pause_reason" mapstructure:"pause_reason"`
+
+	// Pending means a synchronization has been triggered but not all the sets are synchronized yet,
+	// the remaining sets are synchronized in batches of MaxConcurrency in the maintenance window.
+	Pending         bool            `field:"pending" json:"pending" bson:"pending" mapstructure:"pending"`
+	TriggerType     SyncTriggerType `field:"trigger_type" json:"trigger_type" bson:"trigger_type" mapstructure:"trigger_type"`
+	LastTriggerTime *time.Time      `field:"last_trigger_time" json:"last_trigger_time" bson:"last_trigger_time" mapstructure:"last_trigger_time"`
+}
+
+func (p SetTemplateSyncPolicy) Validate() (key string, err error) {
+	switch p.Type {
+	case SyncPolicyManual, SyncPolicyAuto:
+	case SyncPolicyCron:
+		if _, err := cron.ParseStandard(p.Cron); err != nil {
+			return "cron", fmt.Errorf("invalid cron expression %s, err: %v", p.Cron, err)
+		}
+	default:
+		return "type", fmt.Errorf("invalid sync policy type %s", p.Type)
+	}
+
+	if p.MaintenanceWindow != nil {
+		if key, err := p.MaintenanceWindow.Validate(); err != nil {
+			return key, err
+		}
+	}
+
+	if p.MaxConcurrency < 0 || p.MaxConcurrency > SetTemplateSyncMaxConcurrency {
+		return "max_concurrency", fmt.Errorf("max concurrency should be in range [0, %d]",
+			SetTemplateSyncMaxConcurrency)
+	}
+	return "", nil
+}
+
+// GetMaxConcurrency returns the max number of sets synchronized at the same time
+func (p SetTemplateSyncPolicy) GetMaxConcurrency() int64 {
+	if p.MaxConcurrency <= 0 {
+		return SetTemplateSyncDefaultConcurrency
+	}
+	return p.MaxConcurrency
+}
+
+// InMaintenanceWindow check whether automatic synchronization is allowed at t
+func (p SetTemplateSyncPolicy) InMaintenanceWindow(t time.Time) bool {
+	if p.MaintenanceWindow == nil {
+		return true
+	}
+	return p.MaintenanceWindow.Contains(t)
+}
+
+// UpdateSetTemplateSyncPolicyOption is the sync policy settings users can change, resuming a paused policy
+// is done by setting paused to false.
+type UpdateSetTemplateSyncPolicyOption struct {
+	Type              SyncPolicyType     `json:"type"`
+	Cron              string             `json:"cron"`
+	MaintenanceWindow *MaintenanceWindow `json:"maintenance_window"`
+	MaxConcurrency    int64              `json:"max_concurrency"`
+	Paused            bool               `json:"paused"`
+}
+
+// ListSetTemplateSyncPolicyOption list set templates with the sync policy types among all the businesses
+type ListSetTemplateSyncPolicyOption struct {
+	Types []SyncPolicyType `json:"types"`
+}
+
+type ListSetTemplateSyncPolicyResult struct {
+	BaseResp
+	Data []SetTemplate `json:"data"`
+}
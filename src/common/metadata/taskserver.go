@@ -27,8 +27,23 @@ type CreateTaskRequest struct {
 	Flag string `json:"flag"`
 
 	Data []interface{} `json:"data"`
+
+	// 任务优先级, 同一任务队列中优先级高的任务先执行
+	Priority int64 `json:"priority"`
+
+	// 依赖的任务ID, 依赖的任务全部执行成功后才执行本任务
+	DependsOn []string `json:"depends_on"`
 }
 
+const (
+	// TaskPriorityLow low priority, such as tasks triggered by background schedulers
+	TaskPriorityLow int64 = -10
+	// TaskPriorityNormal default priority
+	TaskPriorityNormal int64 = 0
+	// TaskPriorityHigh high priority, such as tasks triggered by users
+	TaskPriorityHigh int64 = 10
+)
+
 // APITaskDetail task info detaill
 type APITaskDetail struct {
 	// task id
@@ -45,6 +60,12 @@ type APITaskDetail struct {
 	Status APITaskStatus `json:"status" bson:"status"`
 	// sub task detail
 	Detail []APISubTaskDetail `json:"detail" bson:"detail"`
+	// task priority, higher priority task is executed first
+	Priority int64 `json:"priority" bson:"priority"`
+	// the tasks must be executed successfully before this task
+	DependsOn []string `json:"depends_on" bson:"depends_on"`
+	// percentage of the finished sub tasks, updated after every sub task is executed
+	Progress int64 `json:"progress" bson:"progress"`
 
 	CreateTime time.Time `json:"create_time" bson:"create_time"`
 	LastTime   time.Time `json:"last_time" bson:"last_time"`
@@ -56,13 +77,15 @@ type APISubTaskDetail struct {
 	Data      interface{}   `json:"data" bson:"data"`
 	Status    APITaskStatus `json:"status" bson:"status"`
 	Response  *Response     `json:"response" bson:"response"`
+	// times the sub task is retried
+	RetryCount int64 `json:"retry_count" bson:"retry_count"`
 }
 
 // APITaskStatus task status type
 type APITaskStatus int64
 
 func (s APITaskStatus) IsFinished() bool {
-	if s == 200 || s == 500 {
+	if s == 200 || s == 500 || s == 300 {
 		return true
 	}
 	return false
@@ -81,11 +104,20 @@ func (s APITaskStatus) IsFailure() bool {
 	return false
 }
 
+func (s APITaskStatus) IsCanceled() bool {
+	if s == 300 {
+		return true
+	}
+	return false
+}
+
 const (
 	// APITaskStatusNew new task ,waiting execute
 	APITaskStatusNew APITaskStatus = 0
 	// APITaskStatusWaitExecute 正在执行的任务中断了。 补偿后。确定需要重新执行
 	APITaskStatusWaitExecute APITaskStatus = 1
+	// APITaskStatusWaitDependency 等待依赖的任务执行成功
+	APITaskStatusWaitDependency APITaskStatus = 2
 
 	// APITaskStatuExecute task executing
 	APITaskStatuExecute APITaskStatus = 100
@@ -93,6 +125,9 @@ const (
 	// APITaskStatusSuccess task execute success
 	APITaskStatusSuccess APITaskStatus = 200
 
+	// APITaskStatusCancel task canceled, the sub tasks not executed yet are canceled too
+	APITaskStatusCancel APITaskStatus = 300
+
 	// APITAskStatusFail task execute failure
 	APITAskStatusFail APITaskStatus = 500
 )
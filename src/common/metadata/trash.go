@@ -0,0 +1,84 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"errors"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+)
+
+// TrashItem is a soft deleted instance, it can be restored until it's purged after the retention period.
+type TrashItem struct {
+	ID       int64  `json:"id" bson:"id" mapstructure:"id"`
+	ObjectID string `json:"bk_obj_id" bson:"bk_obj_id" mapstructure:"bk_obj_id"`
+	InstID   int64  `json:"bk_inst_id" bson:"bk_inst_id" mapstructure:"bk_inst_id"`
+	InstName string `json:"bk_inst_name" bson:"bk_inst_name" mapstructure:"bk_inst_name"`
+	// Detail is the instance's data when it's deleted.
+	Detail mapstr.MapStr `json:"detail" bson:"detail" mapstructure:"detail"`
+	// Associations is the instance's associations which are deleted together with it.
+	Associations    []InstAsst `json:"associations" bson:"associations" mapstructure:"associations"`
+	Operator        string     `json:"operator" bson:"operator" mapstructure:"operator"`
+	DeleteTime      time.Time  `json:"delete_time" bson:"delete_time" mapstructure:"delete_time"`
+	SupplierAccount string     `json:"bk_supplier_account" bson:"bk_supplier_account" mapstructure:"bk_supplier_account"`
+}
+
+type ListTrashOption struct {
+	IDs     []int64  `json:"ids" mapstructure:"ids"`
+	InstIDs []int64  `json:"bk_inst_ids" mapstructure:"bk_inst_ids"`
+	Page    BasePage `json:"page" mapstructure:"page"`
+}
+
+type MultipleTrashItem struct {
+	Count int64       `json:"count" mapstructure:"count"`
+	Info  []TrashItem `json:"info" mapstructure:"info"`
+}
+
+// RestoreTrashOption restore or purge the trash items with the ids.
+type RestoreTrashOption struct {
+	IDs []int64 `json:"ids" mapstructure:"ids"`
+}
+
+func (o *RestoreTrashOption) Validate() (string, error) {
+	if len(o.IDs) == 0 {
+		return "ids", errors.New("ids should not be empty")
+	}
+	if len(o.IDs) > common.BKMaxPageSize {
+		return "ids", errors.New("ids exceed the max page size")
+	}
+	return "", nil
+}
+
+// RestoredTrashItem is the restored instance, the associations whose association model or the
+// other end instance no longer exist are skipped.
+type RestoredTrashItem struct {
+	ID                  int64      `json:"id" mapstructure:"id"`
+	InstID              int64      `json:"bk_inst_id" mapstructure:"bk_inst_id"`
+	SkippedAssociations []InstAsst `json:"skipped_associations" mapstructure:"skipped_associations"`
+}
+
+type RestoreTrashResult struct {
+	Restored []RestoredTrashItem `json:"restored" mapstructure:"restored"`
+}
+
+type MultipleTrashItemResult struct {
+	BaseResp `json:",inline"`
+	Data     MultipleTrashItem `json:"data"`
+}
+
+type RestoreTrashResponse struct {
+	BaseResp `json:",inline"`
+	Data     RestoreTrashResult `json:"data"`
+}
@@ -42,6 +42,9 @@ type LoginUserInfo struct {
 	AvatarUrl     string                      `json:"avatar_url"`
 	SupplierID    int64                       `json:"supplier_id"`
 	MultiSupplier bool                        `json:"multi_supplier"`
+	// ExpireTime the unix time the login session expires and LoginUser is called again to refresh it,
+	// 0 means the login session never expires
+	ExpireTime int64 `json:"-"`
 }
 
 type LoginPluginInfo struct {
@@ -63,6 +66,20 @@ type LoginUserPluginInerface interface {
 	GetUserList(c *gin.Context, config map[string]string) ([]*LoginSystemUserInfo, *errors.RawErrorInfo)
 }
 
+// LoginUserHandlerPluginInterface is implemented by the login plugins handling the login requests sent to web
+// server themselves, such as the login form post or the callback of an identity provider.
+type LoginUserHandlerPluginInterface interface {
+	// HandleLogin authenticates the user of the login request and saves the login session which LoginUser
+	// reads afterwards, returns the url to redirect to after login.
+	HandleLogin(c *gin.Context, config map[string]string) (redirectURL string, err *errors.RawErrorInfo)
+}
+
+// LoginUserLogoutPluginInterface is implemented by the login plugins which also end the login session in the
+// login system, the login session of web server is cleared after Logout.
+type LoginUserLogoutPluginInterface interface {
+	Logout(c *gin.Context, config map[string]string, input *LogoutRequestParams) (logoutURL string)
+}
+
 type LoginSystemUserInfo struct {
 	CnName string `json:"chinese_name"`
 	EnName string `json:"english_name"`
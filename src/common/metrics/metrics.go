@@ -92,6 +92,8 @@ const (
 	LabelAppCode     = "app_code"
 	LabelUser        = "user"
 	LabelRealIP      = "real_ip"
+	// LabelSupplierAccount is the tenant of the request
+	LabelSupplierAccount = "supplier_account"
 )
 
 // labels
@@ -117,56 +117,71 @@ func ParseHostParams(input []metadata.ConditionItem, output map[string]interface
 const exactIPRegexp = `(^IP_PLACEHOLDER$)|(^IP_PLACEHOLDER[,]{1})|([,]{1}IP_PLACEHOLDER[,]{1})|([,]{1}IP_PLACEHOLDER$)`
 
 func ParseHostIPParams(ipCond metadata.IPInfo, output map[string]interface{}) error {
-	ipArr := ipCond.Data
-	exact := ipCond.Exact
-	flag := ipCond.Flag
-	if 0 == len(ipArr) {
+	if !ipCond.HasCondition() {
 		return nil
 	}
-	if 1 == exact {
-		// exact search
-		exactOr := make([]map[string]interface{}, 0)
-		for _, ip := range ipArr {
-			exactIP := make(map[string]interface{})
-			exactIP[common.BKDBLIKE] = strings.Replace(exactIPRegexp, "IP_PLACEHOLDER", SpecialCharChange(ip), -1)
-			if INNERONLY == flag {
-				exactOr = append(exactOr, mapstr.MapStr{common.BKHostInnerIPField: exactIP})
-			} else if OUTERONLY == flag {
-				exactOr = append(exactOr, mapstr.MapStr{common.BKHostOuterIPField: exactIP})
-			} else if IOBOTH == flag {
-				exactOr = append(exactOr, mapstr.MapStr{common.BKHostInnerIPField: exactIP})
-				exactOr = append(exactOr, mapstr.MapStr{common.BKHostOuterIPField: exactIP})
-			} else {
-				return fmt.Errorf("unsupported ip.flag %s", flag)
-			}
+
+	var fields []string
+	switch ipCond.Flag {
+	case INNERONLY:
+		fields = []string{common.BKHostInnerIPField}
+	case OUTERONLY:
+		fields = []string{common.BKHostOuterIPField}
+	case IOBOTH:
+		fields = []string{common.BKHostInnerIPField, common.BKHostOuterIPField}
+	default:
+		return fmt.Errorf("unsupported ip.flag %s", ipCond.Flag)
+	}
+
+	orCond := make([]map[string]interface{}, 0)
+	for _, ip := range ipCond.Data {
+		for _, field := range fields {
+			orCond = append(orCond, ipDataCond(field, ip, ipCond.Exact == 1))
 		}
-		output[common.BKDBOR] = exactOr
-	} else {
-		// not exact search
-		orCond := make([]map[string]map[string]interface{}, 0)
-		for _, ip := range ipArr {
-			c := make(map[string]interface{})
-			c[common.BKDBLIKE] = SpecialCharChange(ip)
-			if INNERONLY == flag {
-				ipCon := make(map[string]map[string]interface{})
-				ipCon[common.BKHostInnerIPField] = c
-				orCond = append(orCond, ipCon)
-			} else if OUTERONLY == flag {
-				ipCon := make(map[string]map[string]interface{})
-				ipCon[common.BKHostOuterIPField] = c
-				orCond = append(orCond, ipCon)
-			} else if IOBOTH == flag {
-				ipiCon := make(map[string]map[string]interface{})
-				ipoCon := make(map[string]map[string]interface{})
-				ipoCon[common.BKHostOuterIPField] = c
-				ipiCon[common.BKHostInnerIPField] = c
-				orCond = append(orCond, ipoCon)
-				orCond = append(orCond, ipiCon)
-			} else {
-				return fmt.Errorf("unsupported ip.flag %s", flag)
-			}
+	}
+
+	// the ip ranges and cidr blocks are matched with the normalized ip fields.
+	for _, cidr := range ipCond.CIDR {
+		from, to, err := util.NormalizedCIDRRange(cidr)
+		if err != nil {
+			return err
+		}
+		for _, field := range fields {
+			orCond = append(orCond, ipRangeCond(field, from, to))
 		}
-		output[common.BKDBOR] = orCond
 	}
+	for _, ipRange := range ipCond.Range {
+		from, to, err := util.NormalizedIPRange(ipRange.Start, ipRange.End)
+		if err != nil {
+			return err
+		}
+		for _, field := range fields {
+			orCond = append(orCond, ipRangeCond(field, from, to))
+		}
+	}
+
+	output[common.BKDBOR] = orCond
 	return nil
 }
+
+// ipDataCond returns the condition to search hosts with the ip of the field.
+func ipDataCond(field, ip string, exact bool) map[string]interface{} {
+	if !exact {
+		return mapstr.MapStr{field: mapstr.MapStr{common.BKDBLIKE: SpecialCharChange(ip)}}
+	}
+
+	// a valid ip is matched with the normalized ip, so that the different texts of the same ipv6 address can be matched.
+	if normalized, err := util.NormalizeIP(ip); err == nil {
+		return mapstr.MapStr{common.HostIPNormalizedFields[field]: normalized}
+	}
+	return mapstr.MapStr{field: mapstr.MapStr{
+		common.BKDBLIKE: strings.Replace(exactIPRegexp, "IP_PLACEHOLDER", SpecialCharChange(ip), -1),
+	}}
+}
+
+// ipRangeCond returns the condition to search hosts with at least one ip of the field in the normalized ip range.
+func ipRangeCond(field, from, to string) map[string]interface{} {
+	return mapstr.MapStr{common.HostIPNormalizedFields[field]: mapstr.MapStr{
+		common.BKDBElemMatch: mapstr.MapStr{common.BKDBGTE: from, common.BKDBLTE: to},
+	}}
+}
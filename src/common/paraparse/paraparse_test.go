@@ -3,6 +3,9 @@ package params
 import (
 	"testing"
 
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -99,3 +102,25 @@ func TestSpecialCharChange(t *testing.T) {
 	}
 
 }
+
+func TestParseHostIPParams(t *testing.T) {
+	output := make(map[string]interface{})
+	err := ParseHostIPParams(metadata.IPInfo{
+		Data:  []string{"FE80::01"},
+		Exact: 1,
+		Flag:  INNERONLY,
+		CIDR:  []string{"10.12.0.0/16"},
+		Range: []metadata.IPRange{{Start: "10.0.0.20", End: "10.0.0.80"}},
+	}, output)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]interface{}{
+		mapstr.MapStr{"bk_host_innerip_normalized": "fe800000000000000000000000000001"},
+		mapstr.MapStr{"bk_host_innerip_normalized": mapstr.MapStr{"$elemMatch": mapstr.MapStr{
+			"$gte": "00000000000000000000ffff0a0c0000", "$lte": "00000000000000000000ffff0a0cffff"}}},
+		mapstr.MapStr{"bk_host_innerip_normalized": mapstr.MapStr{"$elemMatch": mapstr.MapStr{
+			"$gte": "00000000000000000000ffff0a000014", "$lte": "00000000000000000000ffff0a000050"}}},
+	}, output["$or"])
+
+	err = ParseHostIPParams(metadata.IPInfo{CIDR: []string{"10.12.0.0"}, Flag: IOBOTH}, make(map[string]interface{}))
+	require.Error(t, err)
+}
@@ -87,6 +87,24 @@ func (qf *QueryFilter) Validate() (string, error) {
 	return qf.Rule.Validate()
 }
 
+// Fields returns the fields of all the atom rules in the query filter
+func (qf *QueryFilter) Fields() []string {
+	return ruleFields(qf.Rule)
+}
+
+func ruleFields(rule Rule) []string {
+	fields := make([]string, 0)
+	switch r := rule.(type) {
+	case AtomRule:
+		fields = append(fields, r.Field)
+	case CombinedRule:
+		for _, subRule := range r.Rules {
+			fields = append(fields, ruleFields(subRule)...)
+		}
+	}
+	return fields
+}
+
 func (qf *QueryFilter) MarshalJSON() ([]byte, error) {
 	if qf.Rule != nil {
 		return json.Marshal(qf.Rule)
@@ -85,3 +85,12 @@ func TestAsStructField(t *testing.T) {
 	assert.Nil(t, err)
 	t.Logf("output: %s", output)
 }
+
+func TestQueryFilterFields(t *testing.T) {
+	filter := querybuilder.QueryFilter{}
+	input := `{"condition": "AND", "rules": [{"operator": "equal", "value": "1", "field": "bk_os_type"},
+		{"condition": "OR", "rules": [{"operator": "exist", "field": "bk_cpu"}, {"operator": "equal", "value": 1, "field": "bk_mem"}]}]}`
+	err := json.Unmarshal([]byte(input), &filter)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bk_os_type", "bk_cpu", "bk_mem"}, filter.Fields())
+}
@@ -14,10 +14,12 @@ package querybuilder
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"time"
 
 	"configcenter/src/common"
+	"configcenter/src/common/util"
 )
 
 type Rule interface {
@@ -95,6 +97,10 @@ var (
 	// exist check
 	OperatorExist    = Operator("exist")
 	OperatorNotExist = Operator("not_exist")
+
+	// ip check, only for the host ip fields
+	OperatorIPInCIDR  = Operator("ip_in_cidr")
+	OperatorIPInRange = Operator("ip_in_range")
 )
 
 var SupportOperators = map[Operator]bool{
@@ -129,6 +135,9 @@ var SupportOperators = map[Operator]bool{
 
 	OperatorExist:    true,
 	OperatorNotExist: false,
+
+	OperatorIPInCIDR:  true,
+	OperatorIPInRange: true,
 }
 
 func (op Operator) Validate() error {
@@ -177,6 +186,11 @@ func (r AtomRule) validateField() error {
 	if !ValidFieldPattern.MatchString(r.Field) {
 		return fmt.Errorf("invalid field: %s", r.Field)
 	}
+	if r.Operator == OperatorIPInCIDR || r.Operator == OperatorIPInRange {
+		if _, exists := common.HostIPNormalizedFields[r.Field]; !exists {
+			return fmt.Errorf("operator %s is not supported by field: %s", r.Operator, r.Field)
+		}
+	}
 	return nil
 }
 
@@ -198,6 +212,10 @@ func (r AtomRule) validateValue() error {
 		return nil
 	case OperatorExist, OperatorNotExist:
 		return nil
+	case OperatorIPInCIDR:
+		return validateCIDRType(r.Value)
+	case OperatorIPInRange:
+		return validateIPRangeType(r.Value)
 	default:
 		return fmt.Errorf("unsupported operator: %s", r.Operator)
 	}
@@ -325,12 +343,35 @@ func (r AtomRule) ToMgo() (mgoFiler map[string]interface{}, key string, err erro
 		filter[r.Field] = map[string]interface{}{
 			common.BKDBExists: false,
 		}
+	case OperatorIPInCIDR:
+		from, to, err := util.NormalizedCIDRRange(r.Value.(string))
+		if err != nil {
+			return nil, "value", err
+		}
+		filter[common.HostIPNormalizedFields[r.Field]] = ipRangeFilter(from, to)
+	case OperatorIPInRange:
+		ips := reflect.ValueOf(r.Value)
+		from, to, err := util.NormalizedIPRange(ips.Index(0).Interface().(string), ips.Index(1).Interface().(string))
+		if err != nil {
+			return nil, "value", err
+		}
+		filter[common.HostIPNormalizedFields[r.Field]] = ipRangeFilter(from, to)
 	default:
 		return nil, "operator", fmt.Errorf("unsupported operator: %s", r.Operator)
 	}
 	return filter, "", nil
 }
 
+// ipRangeFilter matches the normalized ip array field which has at least one ip in the range.
+func ipRangeFilter(from, to string) map[string]interface{} {
+	return map[string]interface{}{
+		common.BKDBElemMatch: map[string]interface{}{
+			common.BKDBGTE: from,
+			common.BKDBLTE: to,
+		},
+	}
+}
+
 // *************** define query ************************
 type CombinedRule struct {
 	Condition Condition `json:"condition"`
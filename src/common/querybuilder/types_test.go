@@ -419,3 +419,49 @@ func TestExceedMaxDeep(t *testing.T) {
 		assert.NotNil(t, err)
 	}
 }
+
+func TestIPAtomRule(t *testing.T) {
+	rule := querybuilder.AtomRule{
+		Operator: querybuilder.OperatorIPInCIDR,
+		Field:    "bk_host_innerip",
+		Value:    "10.12.0.0/16",
+	}
+	filter, key, err := rule.ToMgo()
+	assert.Nil(t, err, key)
+	assert.Equal(t, map[string]interface{}{
+		"bk_host_innerip_normalized": map[string]interface{}{
+			"$elemMatch": map[string]interface{}{
+				"$gte": "00000000000000000000ffff0a0c0000",
+				"$lte": "00000000000000000000ffff0a0cffff",
+			},
+		},
+	}, filter)
+
+	rule = querybuilder.AtomRule{
+		Operator: querybuilder.OperatorIPInRange,
+		Field:    "bk_host_outerip",
+		Value:    []interface{}{"fe80::20", "fe80::80"},
+	}
+	filter, key, err = rule.ToMgo()
+	assert.Nil(t, err, key)
+	assert.Equal(t, map[string]interface{}{
+		"bk_host_outerip_normalized": map[string]interface{}{
+			"$elemMatch": map[string]interface{}{
+				"$gte": "fe800000000000000000000000000020",
+				"$lte": "fe800000000000000000000000000080",
+			},
+		},
+	}, filter)
+
+	invalidRules := []querybuilder.AtomRule{
+		{Operator: querybuilder.OperatorIPInCIDR, Field: "field", Value: "10.12.0.0/16"},
+		{Operator: querybuilder.OperatorIPInCIDR, Field: "bk_host_innerip", Value: "10.12.0.0"},
+		{Operator: querybuilder.OperatorIPInRange, Field: "bk_host_innerip", Value: []string{"10.0.0.1"}},
+		{Operator: querybuilder.OperatorIPInRange, Field: "bk_host_innerip", Value: []string{"10.0.0.2", "10.0.0.1"}},
+		{Operator: querybuilder.OperatorIPInRange, Field: "bk_host_innerip", Value: []string{"10.0.0.1", "fe80::1"}},
+	}
+	for _, rule := range invalidRules {
+		_, err := rule.Validate()
+		assert.NotNil(t, err, "rule: %+v", rule)
+	}
+}
@@ -114,3 +114,36 @@ func validateSliceOfBasicType(value interface{}, requireSameType bool) error {
 	}
 	return nil
 }
+
+func validateCIDRType(value interface{}) error {
+	if err := validateNotEmptyStringType(value); err != nil {
+		return err
+	}
+	if _, _, err := util.NormalizedCIDRRange(value.(string)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateIPRangeType validates the ip range value, which is an array of the start and end ip.
+func validateIPRangeType(value interface{}) error {
+	if err := validateSliceOfBasicType(value, true); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(value)
+	if v.Len() != 2 {
+		return fmt.Errorf("ip range should be an array of start and end ip, value: %+v", value)
+	}
+	start, ok := v.Index(0).Interface().(string)
+	if !ok {
+		return fmt.Errorf("ip range should be an array of start and end ip, value: %+v", value)
+	}
+	end, ok := v.Index(1).Interface().(string)
+	if !ok {
+		return fmt.Errorf("ip range should be an array of start and end ip, value: %+v", value)
+	}
+	if _, _, err := util.NormalizedIPRange(start, end); err != nil {
+		return err
+	}
+	return nil
+}
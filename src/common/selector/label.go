@@ -14,8 +14,14 @@ package selector
 import (
 	"fmt"
 	"regexp"
+	"sort"
+
+	"configcenter/src/common/util"
 )
 
+// LabelsField is the field that labels are stored in the instance
+const LabelsField = "labels"
+
 type Labels map[string]string
 
 var (
@@ -59,6 +65,22 @@ func (lng Labels) RemoveLabel(keys []string) {
 	}
 }
 
+// Aggregate add the labels to the aggregation
+func (la LabelAggregation) Aggregate(labels Labels) {
+	for key, value := range labels {
+		if !util.InStrArr(la[key], value) {
+			la[key] = append(la[key], value)
+		}
+	}
+}
+
+// Sort sort the values of each key
+func (la LabelAggregation) Sort() {
+	for key := range la {
+		sort.Strings(la[key])
+	}
+}
+
 type LabelInstance struct {
 	Labels Labels `bson:"labels" json:"labels"`
 }
@@ -39,6 +39,15 @@ type LabelRemoveRequest struct {
 	TableName string            `json:"table_name"`
 }
 
+// LabelAggregationRequest aggregate the labels of the instances matching the condition in the table
+type LabelAggregationRequest struct {
+	Condition map[string]interface{} `json:"condition"`
+	TableName string                 `json:"table_name"`
+}
+
+// LabelAggregation is the label keys and all their values, values are unique and sorted
+type LabelAggregation map[string][]string
+
 type Operator string
 
 const (
@@ -90,7 +99,7 @@ func (s *Selector) Validate() (string, error) {
 
 func (s *Selector) ToMgoFilter() (map[string]interface{}, error) {
 	filter := make(map[string]interface{})
-	field := "labels." + s.Key
+	field := LabelsField + "." + s.Key
 	switch s.Operator {
 	case In:
 		filter = map[string]interface{}{
@@ -132,6 +141,26 @@ func (s *Selector) ToMgoFilter() (map[string]interface{}, error) {
 	return filter, nil
 }
 
+// Match check if the labels match the selector, it's consistent with the mongodb filter returned by ToMgoFilter
+func (s *Selector) Match(labels Labels) bool {
+	value, exist := labels[s.Key]
+	switch s.Operator {
+	case Equals:
+		return exist && len(s.Values) > 0 && value == s.Values[0]
+	case NotEquals:
+		return !exist || len(s.Values) == 0 || value != s.Values[0]
+	case In:
+		return exist && util.InStrArr(s.Values, value)
+	case NotIn:
+		return !exist || !util.InStrArr(s.Values, value)
+	case Exists:
+		return exist
+	case DoesNotExist:
+		return !exist
+	}
+	return false
+}
+
 type Selectors []Selector
 
 func (ss Selectors) Validate() (string, error) {
@@ -143,6 +172,16 @@ func (ss Selectors) Validate() (string, error) {
 	return "", nil
 }
 
+// Match check if the labels match all the selectors
+func (ss Selectors) Match(labels Labels) bool {
+	for _, selector := range ss {
+		if !selector.Match(labels) {
+			return false
+		}
+	}
+	return true
+}
+
 func (ss Selectors) ToMgoFilter() (map[string]interface{}, error) {
 	filters := make([]map[string]interface{}, 0)
 	for _, selector := range ss {
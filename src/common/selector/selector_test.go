@@ -177,3 +177,42 @@ func TestSelector(t *testing.T) {
 	assert.Empty(t, filter)
 	assert.NotNil(t, err)
 }
+
+func TestSelectorMatch(t *testing.T) {
+	labels := selector.Labels{"env": "prod", "region": "sh"}
+
+	cases := []struct {
+		selector selector.Selector
+		match    bool
+	}{
+		{selector.Selector{Key: "env", Operator: selector.Equals, Values: []string{"prod"}}, true},
+		{selector.Selector{Key: "env", Operator: selector.Equals, Values: []string{"test"}}, false},
+		{selector.Selector{Key: "env", Operator: selector.NotEquals, Values: []string{"test"}}, true},
+		{selector.Selector{Key: "zone", Operator: selector.NotEquals, Values: []string{"a"}}, true},
+		{selector.Selector{Key: "region", Operator: selector.In, Values: []string{"sh", "bj"}}, true},
+		{selector.Selector{Key: "zone", Operator: selector.In, Values: []string{"a"}}, false},
+		{selector.Selector{Key: "region", Operator: selector.NotIn, Values: []string{"sh"}}, false},
+		{selector.Selector{Key: "zone", Operator: selector.NotIn, Values: []string{"a"}}, true},
+		{selector.Selector{Key: "env", Operator: selector.Exists}, true},
+		{selector.Selector{Key: "zone", Operator: selector.Exists}, false},
+		{selector.Selector{Key: "zone", Operator: selector.DoesNotExist}, true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.match, c.selector.Match(labels), fmt.Sprintf("selector: %+v", c.selector))
+	}
+
+	ss := selector.Selectors{cases[0].selector, cases[4].selector}
+	assert.True(t, ss.Match(labels))
+	ss = append(ss, cases[9].selector)
+	assert.False(t, ss.Match(labels))
+	assert.True(t, selector.Selectors{}.Match(nil))
+}
+
+func TestLabelAggregation(t *testing.T) {
+	aggregation := selector.LabelAggregation{}
+	aggregation.Aggregate(selector.Labels{"env": "prod", "region": "sh"})
+	aggregation.Aggregate(selector.Labels{"env": "test"})
+	aggregation.Aggregate(selector.Labels{"env": "prod"})
+	aggregation.Sort()
+	assert.Equal(t, selector.LabelAggregation{"env": {"prod", "test"}, "region": {"sh"}}, aggregation)
+}
@@ -101,6 +101,31 @@ const (
 
 	// rule for host property auto apply
 	BKTableNameHostApplyRule = "cc_HostApplyRule"
+
+	// roles which are granted attributes' read/write permission
+	BKTableNamePermissionRole = "cc_PermissionRole"
+
+	// sensitive operations which are waiting for approval
+	BKTableNameChangeRequest = "cc_ChangeRequest"
+
+	// snapshots of the archived businesses' topology, used to restore the businesses
+	BKTableNameBizArchive = "cc_BizArchive"
+
+	// hosts' attributes which drift from the values governed by host apply rules
+	BKTableNameHostApplyDrift = "cc_HostApplyDrift"
+
+	// mappings from the host snapshot's json path to the host's attribute
+	BKTableNameHostSnapFieldMapping = "cc_HostSnapFieldMapping"
+
+	// service accounts used by automation, they are authenticated by api tokens
+	BKTableNameServiceAccount = "cc_ServiceAccount"
+	BKTableNameAPIToken       = "cc_APIToken"
+
+	// soft deleted instances which can be restored until they are purged after the retention period
+	BKTableNameTrashBin = "cc_TrashBin"
+
+	// resource quotas of the tenants and businesses
+	BKTableNameQuota = "cc_Quota"
 )
 
 // AllTables alltables
@@ -158,6 +183,15 @@ var AllTables = []string{
 	BKTableNameAPITask,
 	BKTableNameSetTemplateSyncStatus,
 	BKTableNameSetTemplateSyncHistory,
+	BKTableNamePermissionRole,
+	BKTableNameChangeRequest,
+	BKTableNameBizArchive,
+	BKTableNameHostApplyDrift,
+	BKTableNameHostSnapFieldMapping,
+	BKTableNameServiceAccount,
+	BKTableNameAPIToken,
+	BKTableNameTrashBin,
+	BKTableNameQuota,
 }
 
 // GetInstTableName returns inst data table name
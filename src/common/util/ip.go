@@ -0,0 +1,112 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"configcenter/src/common"
+)
+
+// NormalizeIP converts the ipv4 or ipv6 address to the 32 hex characters of its 16 bytes form,
+// ipv4 address is converted to the ipv4-mapped ipv6 address, such as 10.0.0.1 is converted to
+// 00000000000000000000ffff0a000001. so that the normalized ips can be compared and sorted as
+// strings, which makes the ip range and cidr search possible.
+func NormalizeIP(ip string) (string, error) {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return "", fmt.Errorf("invalid ip address %s", ip)
+	}
+	return hex.EncodeToString(parsed.To16()), nil
+}
+
+// NormalizeIPs normalizes the ips separated by comma, the invalid ips are ignored.
+func NormalizeIPs(ips string) []string {
+	normalized := make([]string, 0)
+	for _, ip := range strings.Split(ips, ",") {
+		if strings.TrimSpace(ip) == "" {
+			continue
+		}
+		n, err := NormalizeIP(ip)
+		if err != nil {
+			continue
+		}
+		normalized = append(normalized, n)
+	}
+	return normalized
+}
+
+// CanonicalIP returns the canonical text form of the ip, such as FE80:0::01 is converted to fe80::1,
+// so that the same ip always has the same text. the ip is returned as it is if it's invalid.
+func CanonicalIP(ip string) string {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return ip
+	}
+	return parsed.String()
+}
+
+// NormalizedIPRange returns the normalized start and end ip of the ip range, both are included.
+func NormalizedIPRange(start, end string) (string, string, error) {
+	startIP := net.ParseIP(strings.TrimSpace(start))
+	if startIP == nil {
+		return "", "", fmt.Errorf("invalid start ip address %s", start)
+	}
+	endIP := net.ParseIP(strings.TrimSpace(end))
+	if endIP == nil {
+		return "", "", fmt.Errorf("invalid end ip address %s", end)
+	}
+	if (startIP.To4() == nil) != (endIP.To4() == nil) {
+		return "", "", fmt.Errorf("ip %s and %s are not of the same ip family", start, end)
+	}
+
+	from := hex.EncodeToString(startIP.To16())
+	to := hex.EncodeToString(endIP.To16())
+	if from > to {
+		return "", "", fmt.Errorf("start ip %s is greater than end ip %s", start, end)
+	}
+	return from, to, nil
+}
+
+// NormalizedCIDRRange returns the normalized first and last ip of the cidr block, such as 10.12.0.0/16.
+func NormalizedCIDRRange(cidr string) (string, string, error) {
+	_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid cidr %s", cidr)
+	}
+
+	first := ipNet.IP
+	last := make(net.IP, len(first))
+	for idx := range first {
+		last[idx] = first[idx] | ^ipNet.Mask[idx]
+	}
+	return hex.EncodeToString(first.To16()), hex.EncodeToString(last.To16()), nil
+}
+
+// SetHostIPNormalizedFields sets the normalized ip fields of the host data according to its ip fields,
+// it must be called before the host data is written to db to keep the normalized fields up to date.
+// the normalized fields can not be set directly, they are removed if the ip fields are not set.
+func SetHostIPNormalizedFields(data map[string]interface{}) {
+	for field, normalizedField := range common.HostIPNormalizedFields {
+		value, exists := data[field]
+		if !exists {
+			delete(data, normalizedField)
+			continue
+		}
+		ips, _ := value.(string)
+		data[normalizedField] = NormalizeIPs(ips)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeIP(t *testing.T) {
+	cases := map[string]string{
+		"10.0.0.1":        "00000000000000000000ffff0a000001",
+		" 10.0.0.1 ":      "00000000000000000000ffff0a000001",
+		"::ffff:10.0.0.1": "00000000000000000000ffff0a000001",
+		"FE80:0::01":      "fe800000000000000000000000000001",
+	}
+	for ip, expected := range cases {
+		normalized, err := NormalizeIP(ip)
+		if err != nil || normalized != expected {
+			t.Errorf("normalize ip %s, expected %s, got %s, err: %v", ip, expected, normalized, err)
+		}
+	}
+
+	if _, err := NormalizeIP("10.0.0"); err == nil {
+		t.Errorf("normalize invalid ip should fail")
+	}
+
+	normalized := NormalizeIPs("10.0.0.2,invalid,,fe80::1")
+	expected := []string{"00000000000000000000ffff0a000002", "fe800000000000000000000000000001"}
+	if !reflect.DeepEqual(normalized, expected) {
+		t.Errorf("normalize ips expected %v, got %v", expected, normalized)
+	}
+}
+
+func TestNormalizedRange(t *testing.T) {
+	from, to, err := NormalizedCIDRRange("10.12.3.4/16")
+	if err != nil || from != "00000000000000000000ffff0a0c0000" || to != "00000000000000000000ffff0a0cffff" {
+		t.Errorf("normalized cidr range got %s-%s, err: %v", from, to, err)
+	}
+
+	from, to, err = NormalizedCIDRRange("fe80::/64")
+	if err != nil || from != "fe800000000000000000000000000000" || to != "fe80000000000000ffffffffffffffff" {
+		t.Errorf("normalized ipv6 cidr range got %s-%s, err: %v", from, to, err)
+	}
+
+	if _, _, err := NormalizedIPRange("10.0.0.80", "10.0.0.20"); err == nil {
+		t.Errorf("ip range with start greater than end should fail")
+	}
+	if _, _, err := NormalizedIPRange("10.0.0.1", "fe80::1"); err == nil {
+		t.Errorf("ip range of different ip families should fail")
+	}
+
+	// the normalized ips in range are between the normalized start and end ip
+	from, to, err = NormalizedIPRange("10.0.0.20", "10.0.0.180")
+	if err != nil {
+		t.Fatalf("normalized ip range failed, err: %v", err)
+	}
+	ip, _ := NormalizeIP("10.0.0.100")
+	if ip < from || ip > to {
+		t.Errorf("ip %s should be in range %s-%s", ip, from, to)
+	}
+}
+
+func TestSetHostIPNormalizedFields(t *testing.T) {
+	data := map[string]interface{}{
+		"bk_host_innerip":            "10.0.0.1,10.0.0.2",
+		"bk_host_outerip_normalized": []string{"dirty"},
+	}
+	SetHostIPNormalizedFields(data)
+
+	expected := []string{"00000000000000000000ffff0a000001", "00000000000000000000ffff0a000002"}
+	if !reflect.DeepEqual(data["bk_host_innerip_normalized"], expected) {
+		t.Errorf("expected innerip normalized %v, got %v", expected, data["bk_host_innerip_normalized"])
+	}
+	if _, exists := data["bk_host_outerip_normalized"]; exists {
+		t.Errorf("outerip normalized field should be removed when outerip is not set")
+	}
+}
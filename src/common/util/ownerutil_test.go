@@ -68,7 +68,7 @@ func TestSetQueryOwner(t *testing.T) {
 
 func TestSetModOwner(t *testing.T) {
 	type args struct {
-		condition interface{}
+		condition map[string]interface{}
 		ownerID   string
 	}
 	tests := []struct {
@@ -86,14 +86,14 @@ func TestSetModOwner(t *testing.T) {
 		},
 		{
 			"",
-			args{struct{ Name string }{Name: "haha"}, common.BKSuperOwnerID},
+			args{map[string]interface{}{"name": "haha"}, common.BKSuperOwnerID},
 			map[string]interface{}{
 				"name": "haha",
 			},
 		},
 		{
 			"",
-			args{struct{ Name string }{Name: "haha"}, "ownerid"},
+			args{map[string]interface{}{"name": "haha"}, "ownerid"},
 			map[string]interface{}{
 				"name":                "haha",
 				common.BKOwnerIDField: "ownerid",
@@ -14,11 +14,13 @@ package util
 
 import (
 	"encoding/json"
+	"strings"
 	"unicode/utf8"
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/errors"
+	"configcenter/src/common/expression"
 )
 
 // ValidPropertyOption valid property field option
@@ -30,6 +32,52 @@ func ValidPropertyOption(propertyType string, option interface{}, errProxy error
 		return ValidFieldTypeIntOption(option, errProxy)
 	case common.FieldTypeList:
 		return ValidFieldTypeListOption(option, errProxy)
+	case common.FieldTypeExpression:
+		return ValidFieldTypeExpressionOption(option, errProxy)
+	}
+	return nil
+}
+
+// ValidFieldTypeExpressionOption the option of expression field is the expression which computes the value.
+func ValidFieldTypeExpressionOption(option interface{}, errProxy errors.DefaultCCErrorIf) error {
+	if nil == option {
+		return errProxy.Errorf(common.CCErrCommParamsLostField, "option")
+	}
+
+	expr, ok := option.(string)
+	if !ok {
+		blog.Errorf(" option %v not expression option", option)
+		return errProxy.Errorf(common.CCErrCommParamsIsInvalid, "option")
+	}
+	if _, err := expression.Parse(expr); err != nil {
+		blog.Errorf(" option %s is not a valid expression, err: %v", expr, err)
+		return errProxy.Errorf(common.CCErrCommParamsIsInvalid, "option")
+	}
+	return nil
+}
+
+// ValidExpressionObjectReferences check the expression of the object's attribute only references the fields the
+// object's instances can get. a host can be in several modules of several sets, it has no mainline parent, so it's
+// expressions can not reference the fields of the module, set or business.
+func ValidExpressionObjectReferences(objID string, option interface{}, errProxy errors.DefaultCCErrorIf) error {
+	if objID != common.BKInnerObjIDHost {
+		return nil
+	}
+
+	exprStr, ok := option.(string)
+	if !ok {
+		return errProxy.Errorf(common.CCErrCommParamsIsInvalid, "option")
+	}
+	expr, err := expression.Parse(exprStr)
+	if err != nil {
+		blog.Errorf(" option %s is not a valid expression, err: %v", exprStr, err)
+		return errProxy.Errorf(common.CCErrCommParamsIsInvalid, "option")
+	}
+	for _, field := range expr.Fields() {
+		if strings.Contains(field, ".") {
+			blog.Errorf(" host expression %s can not reference the parent field %s", exprStr, field)
+			return errProxy.Errorf(common.CCErrCommParamsIsInvalid, "option")
+		}
 	}
 	return nil
 }
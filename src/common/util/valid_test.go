@@ -13,6 +13,7 @@
 package util
 
 import (
+	"fmt"
 	"testing"
 
 	"configcenter/src/common"
@@ -30,6 +31,14 @@ func (ei errif) Errorf(errCode int, args ...interface{}) error {
 	return nil
 }
 
+func (ei errif) CCError(errCode int) errors.CCErrorCoder {
+	return nil
+}
+
+func (ei errif) CCErrorf(errCode int, args ...interface{}) errors.CCErrorCoder {
+	return nil
+}
+
 func (ei errif) New(errCode int, msg string) error {
 	return nil
 }
@@ -103,3 +112,36 @@ func TestIsInnerObject(t *testing.T) {
 		})
 	}
 }
+
+// codeErrif returns the errors with the error codes.
+type codeErrif struct {
+	errif
+}
+
+func (ei codeErrif) Errorf(errCode int, args ...interface{}) error {
+	return errors.NewCCError(errCode, fmt.Sprint(args...))
+}
+
+func TestValidExpressionObjectReferences(t *testing.T) {
+	tests := []struct {
+		objID   string
+		option  interface{}
+		wantErr bool
+	}{
+		{objID: common.BKInnerObjIDHost, option: "bk_cpu * 2", wantErr: false},
+		{objID: common.BKInnerObjIDHost, option: `concat(bk_host_name, "-", bk_host_innerip)`, wantErr: false},
+		{objID: common.BKInnerObjIDHost, option: "module.bk_module_name", wantErr: true},
+		{objID: common.BKInnerObjIDHost, option: `concat(bk_host_name, set.bk_set_name)`, wantErr: true},
+		{objID: common.BKInnerObjIDHost, option: "biz.bk_biz_name == null", wantErr: true},
+		{objID: common.BKInnerObjIDHost, option: 1, wantErr: true},
+		{objID: common.BKInnerObjIDModule, option: "set.bk_set_name", wantErr: false},
+		{objID: common.BKInnerObjIDSet, option: "biz.bk_biz_name", wantErr: false},
+	}
+
+	for _, test := range tests {
+		err := ValidExpressionObjectReferences(test.objID, test.option, codeErrif{})
+		if (err != nil) != test.wantErr {
+			t.Errorf("object %s expression %v error = %v, wantErr %v", test.objID, test.option, err, test.wantErr)
+		}
+	}
+}
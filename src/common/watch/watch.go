@@ -15,6 +15,8 @@ package watch
 import (
 	"errors"
 	"fmt"
+
+	"configcenter/src/common/selector"
 )
 
 type WatchEventOptions struct {
@@ -29,6 +31,8 @@ type WatchEventOptions struct {
 	Cursor string `json:"bk_cursor"`
 	// the resource kind you want to watch
 	Resource CursorType `json:"bk_resource"`
+	// the label selectors the resource's labels should match, only events of the matched resources are returned.
+	Selectors selector.Selectors `json:"bk_selectors"`
 }
 
 func (w *WatchEventOptions) Validate() error {
@@ -48,6 +52,17 @@ func (w *WatchEventOptions) Validate() error {
 		}
 	}
 
+	if len(w.Selectors) != 0 {
+		switch w.Resource {
+		case Host, Biz, Set, Module:
+		default:
+			return fmt.Errorf("%s event does not support bk_selectors", w.Resource)
+		}
+		if field, err := w.Selectors.Validate(); err != nil {
+			return fmt.Errorf("bk_selectors.%s invalid, err: %v", field, err)
+		}
+	}
+
 	// use either StartFrom or Cursor.
 	if w.StartFrom != 0 && len(w.Cursor) != 0 {
 		return errors.New("bk_start_from and bk_cursor can not use at the same time")
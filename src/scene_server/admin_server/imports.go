@@ -88,4 +88,16 @@ import (
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202004241035"
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202004291536"
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202005201015"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006021015"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006041530"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006081030"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006101530"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006121600"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006151000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006201000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006221000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006241000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006251000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006261000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.8.202006291000"
 )
@@ -0,0 +1,80 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package y3_8_202006101530
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+)
+
+// upgradeHostApplyRule make host apply rules able to be attached to set and set template,
+// and allow several conditional rules on the same attribute of a module.
+func upgradeHostApplyRule(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	tableName := common.BKTableNameHostApplyRule
+	for _, field := range []string{common.BKSetIDField, common.BKSetTemplateIDField, common.BKPriorityField} {
+		filter := map[string]interface{}{
+			field: map[string]interface{}{
+				common.BKDBExists: false,
+			},
+		}
+		doc := map[string]interface{}{
+			field: 0,
+		}
+		if err := db.Table(tableName).Update(ctx, filter, doc); err != nil {
+			return fmt.Errorf("init field failed, tableName: %s, field: %s, err: %+v", tableName, field, err)
+		}
+	}
+
+	// the rules on the same attribute of a module are no longer unique as they may have different conditions
+	if err := db.Table(tableName).DropIndex(ctx, "host_property_under_module"); err != nil &&
+		!strings.Contains(err.Error(), "not found") {
+		return fmt.Errorf("drop index failed, tableName: %s, err: %+v", tableName, err)
+	}
+
+	indices := []types.Index{
+		{
+			Name: "idx_moduleID_attributeID",
+			Keys: map[string]int32{
+				common.BKModuleIDField:    1,
+				common.BKAttributeIDField: 1,
+			},
+			Background: true,
+		},
+		{Name: "idx_setID", Keys: map[string]int32{common.BKSetIDField: 1}, Background: true},
+		{Name: "idx_setTemplateID", Keys: map[string]int32{common.BKSetTemplateIDField: 1}, Background: true},
+	}
+
+	existIndices, err := db.Table(tableName).Indexes(ctx)
+	if err != nil {
+		return fmt.Errorf("get table indexes failed, tableName: %s, err: %+v", tableName, err)
+	}
+	existIdxMap := make(map[string]bool)
+	for _, idx := range existIndices {
+		existIdxMap[idx.Name] = true
+	}
+	for _, index := range indices {
+		if existIdxMap[index.Name] {
+			continue
+		}
+		if err = db.Table(tableName).CreateIndex(ctx, index); err != nil && !db.IsDuplicatedError(err) {
+			return fmt.Errorf("CreateIndex failed, tableName: %s, index: %s, err: %+v", tableName, index.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package y3_8_202006121600
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+)
+
+// addHostApplyDriftAction add the module attribute which decides how to deal with the drifted host attributes
+func addHostApplyDriftAction(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	attributeFilter := map[string]interface{}{
+		common.BKObjIDField:      common.BKInnerObjIDModule,
+		common.BKPropertyIDField: common.HostApplyDriftActionField,
+	}
+	count, err := db.Table(common.BKTableNameObjAttDes).Find(attributeFilter).Count(ctx)
+	if err != nil {
+		return fmt.Errorf("count module attribute failed, filter: %+v, err: %+v", attributeFilter, err)
+	}
+
+	if count == 0 {
+		id, err := db.NextSequence(ctx, common.BKTableNameObjAttDes)
+		if err != nil {
+			return fmt.Errorf("NextSequence failed, err: %+v", err)
+		}
+
+		now := time.Now()
+		attribute := map[string]interface{}{
+			"id":                  id,
+			"bk_obj_id":           common.BKInnerObjIDModule,
+			"editable":            true,
+			"bk_supplier_account": conf.OwnerID,
+			"ispre":               true,
+			"isreadonly":          false,
+			"bk_issystem":         false,
+			"bk_property_index":   0,
+			"unit":                "",
+			"isrequired":          false,
+			"bk_property_type":    common.FieldTypeEnum,
+			"option": []metadata.EnumVal{
+				{ID: metadata.HostApplyDriftActionRecord, Name: "记录漂移", Type: "text", IsDefault: true},
+				{ID: metadata.HostApplyDriftActionAutoCorrect, Name: "自动修正", Type: "text"},
+			},
+			"bk_property_id":    common.HostApplyDriftActionField,
+			"bk_property_name":  "主机属性漂移处理方式",
+			"bk_property_group": "default",
+			"placeholder":       "主机属性被修改后与自动应用规则不一致时，记录漂移或自动修正",
+			"bk_isapi":          true,
+			"creator":           common.CCSystemOperatorUserName,
+			"create_time":       now,
+			"last_time":         now,
+		}
+		if err := db.Table(common.BKTableNameObjAttDes).Insert(ctx, attribute); err != nil {
+			return fmt.Errorf("insert module attribute failed, err: %+v", err)
+		}
+	}
+
+	filter := map[string]interface{}{
+		common.HostApplyDriftActionField: map[string]interface{}{
+			common.BKDBExists: false,
+		},
+	}
+	doc := map[string]interface{}{
+		common.HostApplyDriftActionField: metadata.HostApplyDriftActionRecord,
+	}
+	if err := db.Table(common.BKTableNameBaseModule).Update(ctx, filter, doc); err != nil {
+		return fmt.Errorf("init module drift action failed, err: %+v", err)
+	}
+	return nil
+}
+
+func createHostApplyDriftTable(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	tableName := common.BKTableNameHostApplyDrift
+	exists, err := db.HasTable(ctx, tableName)
+	if err != nil {
+		return fmt.Errorf("check HasTable failed, tableName: %s, err: %+v", tableName, err)
+	}
+	if !exists {
+		if err = db.CreateTable(ctx, tableName); err != nil && !db.IsDuplicatedError(err) {
+			return fmt.Errorf("CreateTable failed, tableName: %s, err: %+v", tableName, err)
+		}
+	}
+
+	indices := []types.Index{
+		{Name: "idx_id", Keys: map[string]int32{common.BKFieldID: 1}, Unique: true, Background: true},
+		{
+			Name: "idx_bizID_hostID_attributeID",
+			Keys: map[string]int32{
+				common.BKAppIDField:       1,
+				common.BKHostIDField:      1,
+				common.BKAttributeIDField: 1,
+			},
+			Background: true,
+		},
+		{Name: "idx_hostID", Keys: map[string]int32{common.BKHostIDField: 1}, Background: true},
+		{Name: "idx_moduleIDs", Keys: map[string]int32{common.BKModuleIDsField: 1}, Background: true},
+	}
+
+	existIndices, err := db.Table(tableName).Indexes(ctx)
+	if err != nil {
+		return fmt.Errorf("get table indexes failed, tableName: %s, err: %+v", tableName, err)
+	}
+	existIdxMap := make(map[string]bool)
+	for _, idx := range existIndices {
+		existIdxMap[idx.Name] = true
+	}
+	for _, index := range indices {
+		if existIdxMap[index.Name] {
+			continue
+		}
+		if err = db.Table(tableName).CreateIndex(ctx, index); err != nil && !db.IsDuplicatedError(err) {
+			return fmt.Errorf("CreateIndex failed, tableName: %s, index: %s, err: %+v", tableName, index.Name, err)
+		}
+	}
+	return nil
+}
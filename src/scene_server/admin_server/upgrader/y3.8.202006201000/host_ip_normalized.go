@@ -0,0 +1,103 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package y3_8_202006201000
+
+import (
+	"context"
+	"fmt"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+)
+
+func addHostIPNormalizedIndex(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	indices := []types.Index{
+		{
+			Name: "idx_innerIPNormalized_cloudID",
+			Keys: map[string]int32{
+				common.BKHostInnerIPNormalizedField: 1,
+				common.BKCloudIDField:               1,
+			},
+			Background: true,
+		},
+		{
+			Name:       "idx_outerIPNormalized",
+			Keys:       map[string]int32{common.BKHostOuterIPNormalizedField: 1},
+			Background: true,
+		},
+	}
+
+	existIndices, err := db.Table(common.BKTableNameBaseHost).Indexes(ctx)
+	if err != nil {
+		return fmt.Errorf("get table indexes failed, tableName: %s, err: %+v", common.BKTableNameBaseHost, err)
+	}
+	existIdxMap := make(map[string]bool)
+	for _, idx := range existIndices {
+		existIdxMap[idx.Name] = true
+	}
+	for _, index := range indices {
+		if existIdxMap[index.Name] {
+			continue
+		}
+		if err = db.Table(common.BKTableNameBaseHost).CreateIndex(ctx, index); err != nil && !db.IsDuplicatedError(err) {
+			return fmt.Errorf("CreateIndex failed, tableName: %s, index: %s, err: %+v", common.BKTableNameBaseHost, index.Name, err)
+		}
+	}
+	return nil
+}
+
+// setHostIPNormalizedFields sets the normalized ip fields of all the existing hosts page by page.
+func setHostIPNormalizedFields(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	const pageSize = 500
+	fields := []string{common.BKHostIDField, common.BKHostInnerIPField, common.BKHostOuterIPField}
+
+	var lastHostID int64
+	for {
+		filter := mapstr.MapStr{common.BKHostIDField: mapstr.MapStr{common.BKDBGT: lastHostID}}
+		hosts := make([]mapstr.MapStr, 0)
+		err := db.Table(common.BKTableNameBaseHost).Find(filter).Fields(fields...).Sort(common.BKHostIDField).
+			Limit(pageSize).All(ctx, &hosts)
+		if err != nil {
+			return fmt.Errorf("get hosts failed, last host id: %d, err: %+v", lastHostID, err)
+		}
+
+		for _, host := range hosts {
+			hostID, err := util.GetInt64ByInterface(host[common.BKHostIDField])
+			if err != nil {
+				return fmt.Errorf("get host id failed, host: %+v, err: %+v", host, err)
+			}
+			lastHostID = hostID
+
+			doc := mapstr.MapStr{
+				common.BKHostInnerIPField: host[common.BKHostInnerIPField],
+				common.BKHostOuterIPField: host[common.BKHostOuterIPField],
+			}
+			util.SetHostIPNormalizedFields(doc)
+			delete(doc, common.BKHostInnerIPField)
+			delete(doc, common.BKHostOuterIPField)
+
+			hostFilter := mapstr.MapStr{common.BKHostIDField: hostID}
+			if err := db.Table(common.BKTableNameBaseHost).Update(ctx, hostFilter, doc); err != nil {
+				return fmt.Errorf("set host %d normalized ip fields failed, err: %+v", hostID, err)
+			}
+		}
+
+		if len(hosts) < pageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package y3_8_202006221000
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	mCommon "configcenter/src/scene_server/admin_server/common"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+)
+
+// addPlatAttr add the metadata attributes of the cloud area, such as the vendor, region, vpc, agent proxies,
+// creator and host limit, the attributes already exist are skipped.
+func addPlatAttr(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	objID := common.BKInnerObjIDPlat
+	vendorEnum := []metadata.EnumVal{
+		{ID: "1", Name: "AWS", Type: "text"},
+		{ID: "2", Name: "腾讯云", Type: "text"},
+		{ID: "3", Name: "阿里云", Type: "text"},
+		{ID: "4", Name: "华为云", Type: "text"},
+		{ID: "5", Name: "Azure", Type: "text"},
+		{ID: "6", Name: "Google Cloud", Type: "text"},
+		{ID: "7", Name: "IDC", Type: "text"},
+	}
+	dataRows := []*Attribute{
+		{ObjectID: objID, PropertyID: common.BKCloudVendorField, PropertyName: "云厂商", IsEditable: true, PropertyType: common.FieldTypeEnum, Option: vendorEnum},
+		{ObjectID: objID, PropertyID: common.BKCloudRegionField, PropertyName: "地域", IsEditable: true, PropertyType: common.FieldTypeSingleChar, Option: ""},
+		{ObjectID: objID, PropertyID: common.BKCloudVpcIDField, PropertyName: "VPC ID", IsEditable: true, PropertyType: common.FieldTypeSingleChar, Option: ""},
+		{ObjectID: objID, PropertyID: common.BKCloudProxyField, PropertyName: "Proxy主机IP", IsEditable: true, PropertyType: common.FieldTypeSingleChar, Option: common.PatternMultipleIP, Placeholder: "云区域中agent proxy主机的IP，多个IP以逗号分隔"},
+		{ObjectID: objID, PropertyID: common.BKCloudHostLimitField, PropertyName: "主机数量上限", IsEditable: true, PropertyType: common.FieldTypeInt, Option: metadata.IntOption{Min: "0", Max: "1000000000"}, Placeholder: "云区域中最多可以容纳的主机数量，为空或0表示不限制"},
+		{ObjectID: objID, PropertyID: common.CreatorField, PropertyName: "创建者", IsEditable: false, PropertyType: common.FieldTypeUser, Option: ""},
+	}
+
+	now := time.Now()
+	for index, r := range dataRows {
+		filter := map[string]interface{}{
+			common.BKObjIDField:      objID,
+			common.BKPropertyIDField: r.PropertyID,
+		}
+		count, err := db.Table(common.BKTableNameObjAttDes).Find(filter).Count(ctx)
+		if err != nil {
+			return fmt.Errorf("count plat attribute failed, filter: %+v, err: %+v", filter, err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		r.OwnerID = conf.OwnerID
+		r.PropertyGroup = mCommon.BaseInfo
+		r.PropertyIndex = int64(index + 2)
+		r.IsPre = true
+		r.CreateTime = &now
+		r.LastTime = &now
+		r.Creator = common.CCSystemOperatorUserName
+		r.LastEditor = common.CCSystemOperatorUserName
+
+		id, err := db.NextSequence(ctx, common.BKTableNameObjAttDes)
+		if err != nil {
+			return fmt.Errorf("NextSequence failed, plat attribute: %s, err: %+v", r.PropertyID, err)
+		}
+		r.ID = int64(id)
+
+		if err := db.Table(common.BKTableNameObjAttDes).Insert(ctx, r); err != nil {
+			return fmt.Errorf("insert plat attribute %s failed, err: %+v", r.PropertyID, err)
+		}
+	}
+
+	return nil
+}
+
+type Attribute struct {
+	ID                int64       `field:"id" json:"id" bson:"id"`
+	OwnerID           string      `field:"bk_supplier_account" json:"bk_supplier_account" bson:"bk_supplier_account"`
+	ObjectID          string      `field:"bk_obj_id" json:"bk_obj_id" bson:"bk_obj_id"`
+	PropertyID        string      `field:"bk_property_id" json:"bk_property_id" bson:"bk_property_id"`
+	PropertyName      string      `field:"bk_property_name" json:"bk_property_name" bson:"bk_property_name"`
+	PropertyGroup     string      `field:"bk_property_group" json:"bk_property_group" bson:"bk_property_group"`
+	PropertyGroupName string      `field:"bk_property_group_name,ignoretomap" json:"bk_property_group_name" bson:"-"`
+	PropertyIndex     int64       `field:"bk_property_index" json:"bk_property_index" bson:"bk_property_index"`
+	Unit              string      `field:"unit" json:"unit" bson:"unit"`
+	Placeholder       string      `field:"placeholder" json:"placeholder" bson:"placeholder"`
+	IsEditable        bool        `field:"editable" json:"editable" bson:"editable"`
+	IsPre             bool        `field:"ispre" json:"ispre" bson:"ispre"`
+	IsRequired        bool        `field:"isrequired" json:"isrequired" bson:"isrequired"`
+	IsReadOnly        bool        `field:"isreadonly" json:"isreadonly" bson:"isreadonly"`
+	IsOnly            bool        `field:"isonly" json:"isonly" bson:"isonly"`
+	IsSystem          bool        `field:"bk_issystem" json:"bk_issystem" bson:"bk_issystem"`
+	IsAPI             bool        `field:"bk_isapi" json:"bk_isapi" bson:"bk_isapi"`
+	PropertyType      string      `field:"bk_property_type" json:"bk_property_type" bson:"bk_property_type"`
+	Option            interface{} `field:"option" json:"option" bson:"option"`
+	Description       string      `field:"description" json:"description" bson:"description"`
+	Creator           string      `field:"creator" json:"creator" bson:"creator"`
+	CreateTime        *time.Time  `json:"create_time" bson:"create_time"`
+	LastEditor        string      `json:"bk_last_editor" bson:"bk_last_editor"`
+	LastTime          *time.Time  `json:"last_time" bson:"last_time"`
+}
@@ -0,0 +1,82 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package y3_8_202006241000
+
+import (
+	"context"
+	"fmt"
+
+	"configcenter/src/common"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+)
+
+func createServiceAccountTables(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	tables := map[string][]types.Index{
+		common.BKTableNameServiceAccount: {
+			{Name: "idx_id", Keys: map[string]int32{common.BKFieldID: 1}, Unique: true, Background: true},
+			{
+				Name: "idx_supplierAccount_name",
+				Keys: map[string]int32{
+					common.BkSupplierAccount: 1,
+					common.BKFieldName:       1,
+				},
+				Unique:     true,
+				Background: true,
+			},
+		},
+		common.BKTableNameAPIToken: {
+			{Name: "idx_id", Keys: map[string]int32{common.BKFieldID: 1}, Unique: true, Background: true},
+			{Name: "idx_tokenHash", Keys: map[string]int32{common.BKTokenHashField: 1}, Unique: true, Background: true},
+			{
+				Name: "idx_supplierAccount_serviceAccountID",
+				Keys: map[string]int32{
+					common.BkSupplierAccount:       1,
+					common.BKServiceAccountIDField: 1,
+				},
+				Background: true,
+			},
+		},
+	}
+
+	for tableName, indices := range tables {
+		exists, err := db.HasTable(ctx, tableName)
+		if err != nil {
+			return fmt.Errorf("check HasTable failed, tableName: %s, err: %+v", tableName, err)
+		}
+		if !exists {
+			if err = db.CreateTable(ctx, tableName); err != nil && !db.IsDuplicatedError(err) {
+				return fmt.Errorf("CreateTable failed, tableName: %s, err: %+v", tableName, err)
+			}
+		}
+
+		existIndices, err := db.Table(tableName).Indexes(ctx)
+		if err != nil {
+			return fmt.Errorf("get table indexes failed, tableName: %s, err: %+v", tableName, err)
+		}
+		existIdxMap := make(map[string]bool)
+		for _, idx := range existIndices {
+			existIdxMap[idx.Name] = true
+		}
+		for _, index := range indices {
+			if existIdxMap[index.Name] {
+				continue
+			}
+			if err = db.Table(tableName).CreateIndex(ctx, index); err != nil && !db.IsDuplicatedError(err) {
+				return fmt.Errorf("CreateIndex failed, tableName: %s, index: %s, err: %+v", tableName, index.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package y3_8_202006251000
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	mCommon "configcenter/src/scene_server/admin_server/common"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+)
+
+// addBizPortConflictPolicyAttr add the business attribute which decides whether the process port conflicts on
+// the same host are rejected or only warned.
+func addBizPortConflictPolicyAttr(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	objID := common.BKInnerObjIDApp
+	filter := map[string]interface{}{
+		common.BKObjIDField:      objID,
+		common.BKPropertyIDField: common.BKPortConflictPolicyField,
+	}
+	count, err := db.Table(common.BKTableNameObjAttDes).Find(filter).Count(ctx)
+	if err != nil {
+		return fmt.Errorf("count business attribute failed, filter: %+v, err: %+v", filter, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	attrCount, err := db.Table(common.BKTableNameObjAttDes).Find(map[string]interface{}{common.BKObjIDField: objID}).Count(ctx)
+	if err != nil {
+		return fmt.Errorf("count business attributes failed, err: %+v", err)
+	}
+
+	now := time.Now()
+	attr := &Attribute{
+		OwnerID:       conf.OwnerID,
+		ObjectID:      objID,
+		PropertyID:    common.BKPortConflictPolicyField,
+		PropertyName:  "进程端口冲突策略",
+		PropertyGroup: mCommon.BaseInfo,
+		PropertyIndex: int64(attrCount + 1),
+		Placeholder:   "同一主机上的进程端口冲突时拒绝修改或仅告警",
+		IsEditable:    true,
+		IsPre:         true,
+		PropertyType:  common.FieldTypeEnum,
+		Option: []metadata.EnumVal{
+			{ID: string(metadata.PortConflictPolicyWarn), Name: "告警", Type: "text", IsDefault: true},
+			{ID: string(metadata.PortConflictPolicyReject), Name: "拒绝", Type: "text"},
+		},
+		Creator:    common.CCSystemOperatorUserName,
+		CreateTime: &now,
+		LastEditor: common.CCSystemOperatorUserName,
+		LastTime:   &now,
+	}
+
+	id, err := db.NextSequence(ctx, common.BKTableNameObjAttDes)
+	if err != nil {
+		return fmt.Errorf("NextSequence failed, business attribute: %s, err: %+v", attr.PropertyID, err)
+	}
+	attr.ID = int64(id)
+
+	if err := db.Table(common.BKTableNameObjAttDes).Insert(ctx, attr); err != nil {
+		return fmt.Errorf("insert business attribute %s failed, err: %+v", attr.PropertyID, err)
+	}
+	return nil
+}
+
+type Attribute struct {
+	ID                int64       `field:"id" json:"id" bson:"id"`
+	OwnerID           string      `field:"bk_supplier_account" json:"bk_supplier_account" bson:"bk_supplier_account"`
+	ObjectID          string      `field:"bk_obj_id" json:"bk_obj_id" bson:"bk_obj_id"`
+	PropertyID        string      `field:"bk_property_id" json:"bk_property_id" bson:"bk_property_id"`
+	PropertyName      string      `field:"bk_property_name" json:"bk_property_name" bson:"bk_property_name"`
+	PropertyGroup     string      `field:"bk_property_group" json:"bk_property_group" bson:"bk_property_group"`
+	PropertyGroupName string      `field:"bk_property_group_name,ignoretomap" json:"bk_property_group_name" bson:"-"`
+	PropertyIndex     int64       `field:"bk_property_index" json:"bk_property_index" bson:"bk_property_index"`
+	Unit              string      `field:"unit" json:"unit" bson:"unit"`
+	Placeholder       string      `field:"placeholder" json:"placeholder" bson:"placeholder"`
+	IsEditable        bool        `field:"editable" json:"editable" bson:"editable"`
+	IsPre             bool        `field:"ispre" json:"ispre" bson:"ispre"`
+	IsRequired        bool        `field:"isrequired" json:"isrequired" bson:"isrequired"`
+	IsReadOnly        bool        `field:"isreadonly" json:"isreadonly" bson:"isreadonly"`
+	IsOnly            bool        `field:"isonly" json:"isonly" bson:"isonly"`
+	IsSystem          bool        `field:"bk_issystem" json:"bk_issystem" bson:"bk_issystem"`
+	IsAPI             bool        `field:"bk_isapi" json:"bk_isapi" bson:"bk_isapi"`
+	PropertyType      string      `field:"bk_property_type" json:"bk_property_type" bson:"bk_property_type"`
+	Option            interface{} `field:"option" json:"option" bson:"option"`
+	Description       string      `field:"description" json:"description" bson:"description"`
+	Creator           string      `field:"creator" json:"creator" bson:"creator"`
+	CreateTime        *time.Time  `json:"create_time" bson:"create_time"`
+	LastEditor        string      `json:"bk_last_editor" bson:"bk_last_editor"`
+	LastTime          *time.Time  `json:"last_time" bson:"last_time"`
+}
@@ -42,9 +42,10 @@ type HostSnap struct {
 	authManager *extensions.AuthManager
 	*backbone.Engine
 
-	filter *filter
-	ctx    context.Context
-	db     dal.RDB
+	filter   *filter
+	ctx      context.Context
+	db       dal.RDB
+	mappings *mappingCache
 }
 
 func NewHostSnap(ctx context.Context, redisCli *redis.Client, db dal.RDB, engine *backbone.Engine, authManager *extensions.AuthManager) *HostSnap {
@@ -55,6 +56,7 @@ func NewHostSnap(ctx context.Context, redisCli *redis.Client, db dal.RDB, engine
 		authManager: authManager,
 		Engine:      engine,
 		filter:      newFilter(),
+		mappings:    newMappingCache(db),
 	}
 	return h
 }
@@ -86,10 +88,7 @@ func (h *HostSnap) Mock() string {
 }
 
 func (h *HostSnap) Analyze(mesg string) error {
-	var data = mesg
-	if !gjson.Get(mesg, "cloudid").Exists() {
-		data = gjson.Get(mesg, "data").String()
-	}
+	data := UnwrapMessage(mesg)
 
 	header, rid := newHeaderWithRid()
 	blog.V(5).Infof("analyze snapshot %s, rid: %s", data, rid)
@@ -129,14 +128,33 @@ func (h *HostSnap) Analyze(mesg string) error {
 		blog.Errorf("save snapshot key: %s to redis failed: %v, rid: %s", key, err, rid)
 	}
 
-	setter, raw := parseSetter(&val, innerIP, outerIP)
+	// record the last report time of the cloud area, it's shown in the status summary of the cloud area
+	cloudKey := common.RedisCloudSnapReportKeyPrefix + strconv.FormatInt(cloudID, 10)
+	if err := h.redisCli.Set(cloudKey, time.Now().Unix(), 0).Err(); err != nil {
+		blog.Errorf("save cloud area report time key: %s to redis failed: %v, rid: %s", cloudKey, err, rid)
+	}
+
+	setter := parseSetter(&val, innerIP, outerIP)
+	// the custom field mappings of the host's supplier account
+	ownerID := gjson.Get(host, common.BKOwnerIDField).String()
+	if len(ownerID) == 0 {
+		ownerID = common.BKDefaultOwnerID
+	}
+	// the host is found from the cache among all the supplier accounts, it's updated in it's own supplier account
+	header.Set(common.BKHTTPOwnerID, ownerID)
+	skipped := setMappedFields(setter, &val, h.mappings.get(h.ctx, ownerID))
+	if len(skipped) > 0 {
+		blog.V(4).Infof("snapshot of host %d skipped mapped fields: %v, rid: %s", hostID, skipped, rid)
+	}
+
 	// no need to update
-	if !needToUpdate(raw, host) {
+	changed := ChangedFields(setter, host)
+	if len(changed) == 0 {
 		return nil
 	}
 
-	blog.V(5).Infof("snapshot for host changed, need update, host id: %d, ip: %s, cloud id: %d, from %s to %s, rid: %s",
-		hostID, innerIP, cloudID, host, raw, rid)
+	blog.V(5).Infof("snapshot for host changed, need update, host id: %d, ip: %s, cloud id: %d, from %s, changed: %v, rid: %s",
+		hostID, innerIP, cloudID, host, changed, rid)
 
 	// add auditLog
 	preData, err := h.CoreAPI.CoreService().Host().GetHostByID(h.ctx, header, hostID)
@@ -240,26 +258,7 @@ func (h *HostSnap) Analyze(mesg string) error {
 	return nil
 }
 
-func needToUpdate(src, toCompare string) bool {
-	srcElements := gjson.GetMany(src, compareFields...)
-	compareElements := gjson.GetMany(toCompare, compareFields...)
-	for idx := range compareFields {
-		// compare these value with string directly to avoid empty value or null value.
-		if srcElements[idx].String() != compareElements[idx].String() {
-			// tolerate bk_cpu_mhz changes less than 100
-			if compareFields[idx] == "bk_cpu_mhz" {
-				diff := srcElements[idx].Int() - compareElements[idx].Int()
-				if -100 < diff && diff < 100 {
-					continue
-				}
-			}
-			return true
-		}
-	}
-	return false
-}
-
-func parseSetter(val *gjson.Result, innerIP, outerIP string) (map[string]interface{}, string) {
+func parseSetter(val *gjson.Result, innerIP, outerIP string) map[string]interface{} {
 	var cpumodule = val.Get("data.cpu.cpuinfo.0.modelName").String()
 	var cpunum int64
 	for _, core := range val.Get("data.cpu.cpuinfo.#.cores").Array() {
@@ -329,51 +328,6 @@ func parseSetter(val *gjson.Result, innerIP, outerIP string) (map[string]interfa
 		common.HostFieldDockerServerVersion: dockerServerVersion,
 	}
 
-	raw := strings.Builder{}
-	raw.WriteByte('{')
-	raw.WriteString("\"bk_cpu\":")
-	raw.WriteString(strconv.FormatInt(cpunum, 10))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_cpu_module\":")
-	raw.Write([]byte("\"" + cpumodule + "\""))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_cpu_mhz\":")
-	raw.WriteString(strconv.FormatInt(CPUMhz, 10))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_disk\":")
-	raw.WriteString(strconv.FormatInt(disk, 10))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_mem\":")
-	raw.WriteString(strconv.FormatInt(mem, 10))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_os_type\":")
-	raw.Write([]byte("\"" + ostype + "\""))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_os_name\":")
-	raw.Write([]byte("\"" + osname + "\""))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_os_version\":")
-	raw.Write([]byte("\"" + version + "\""))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_host_name\":")
-	raw.Write([]byte("\"" + hostname + "\""))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_outer_mac\":")
-	raw.Write([]byte("\"" + OuterMAC + "\""))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_mac\":")
-	raw.Write([]byte("\"" + InnerMAC + "\""))
-	raw.WriteString(",")
-	raw.WriteString("\"bk_os_bit\":")
-	raw.Write([]byte("\"" + osbit + "\""))
-	raw.WriteString(",")
-	raw.WriteString("\"docker_client_version\":")
-	raw.Write([]byte("\"" + dockerClientVersion + "\""))
-	raw.WriteString(",")
-	raw.WriteString("\"docker_server_version\":")
-	raw.Write([]byte("\"" + dockerServerVersion + "\""))
-	raw.WriteByte('}')
-
 	if cpunum <= 0 {
 		blog.V(4).Infof("bk_cpu not found in message for %s", innerIP)
 	}
@@ -408,10 +362,10 @@ func parseSetter(val *gjson.Result, innerIP, outerIP string) (map[string]interfa
 		blog.V(4).Infof("bk_mac not found in message for %s", innerIP)
 	}
 
-	return setter, raw.String()
+	return setter
 }
 
-var reqireFields = append(compareFields, "bk_host_id", "bk_host_innerip", "bk_host_outerip")
+var reqireFields = append(compareFields, "bk_host_id", "bk_host_innerip", "bk_host_outerip", common.BKOwnerIDField)
 
 func (h *HostSnap) getHostByVal(header http.Header, cloudID int64, ips []string, val *gjson.Result) (string, error) {
 	rid := util.GetHTTPCCRequestID(header)
@@ -430,7 +384,7 @@ func (h *HostSnap) getHostByVal(header http.Header, cloudID int64, ips []string,
 		opt := &metadata.SearchHostWithInnerIPOption{
 			InnerIP: ip,
 			CloudID: cloudID,
-			Fields:  reqireFields,
+			Fields:  h.mappings.requiredFields(h.ctx),
 		}
 
 		host, err := h.Engine.CoreAPI.CoreService().Cache().SearchHostWithInnerIP(context.Background(), header, opt)
@@ -0,0 +1,381 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostsnap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal"
+
+	"github.com/tidwall/gjson"
+)
+
+// mappingRefreshInterval is how long the field mappings loaded from db are used before reloaded.
+const mappingRefreshInterval = time.Minute
+
+type transformFunc func(value interface{}) (interface{}, error)
+
+// fieldMapping is the compiled form of metadata.HostSnapFieldMapping
+type fieldMapping struct {
+	propertyID string
+	path       string
+	transforms []transformFunc
+}
+
+func compileFieldMapping(mapping metadata.HostSnapFieldMapping) (*fieldMapping, error) {
+	if _, err := mapping.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := &fieldMapping{
+		propertyID: mapping.PropertyID,
+		path:       mapping.Path,
+		transforms: make([]transformFunc, 0),
+	}
+	for _, transform := range mapping.Transforms {
+		switch transform.Type {
+		case metadata.HostSnapTransformFirst:
+			m.transforms = append(m.transforms, transformFirst)
+		case metadata.HostSnapTransformSum:
+			m.transforms = append(m.transforms, transformSum)
+		case metadata.HostSnapTransformRegex:
+			regex, err := regexp.Compile(transform.Regex)
+			if err != nil {
+				return nil, err
+			}
+			m.transforms = append(m.transforms, perElement(regexTransform(regex)))
+		case metadata.HostSnapTransformUnit:
+			from := metadata.HostSnapUnits[strings.ToUpper(transform.FromUnit)]
+			to := metadata.HostSnapUnits[strings.ToUpper(transform.ToUnit)]
+			m.transforms = append(m.transforms, perElement(unitTransform(from, to)))
+		}
+	}
+	return m, nil
+}
+
+// value returns the value of the mapped property computed from the snapshot.
+func (m *fieldMapping) value(val *gjson.Result) (interface{}, error) {
+	result := val.Get(m.path)
+	if !result.Exists() {
+		return nil, fmt.Errorf("path %s not exist", m.path)
+	}
+
+	value := gjsonValue(result)
+	var err error
+	for _, transform := range m.transforms {
+		value, err = transform(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return normalizeValue(value), nil
+}
+
+// gjsonValue converts the gjson result to go value, arrays are converted to []interface{}
+// so that the transforms can handle the elements one by one.
+func gjsonValue(result gjson.Result) interface{} {
+	if result.IsArray() {
+		elements := result.Array()
+		values := make([]interface{}, len(elements))
+		for idx, element := range elements {
+			values[idx] = gjsonValue(element)
+		}
+		return values
+	}
+	if result.IsObject() {
+		return result.Raw
+	}
+	return result.Value()
+}
+
+// normalizeValue converts the value to the type which can be saved as host attribute,
+// whole floats are converted to integer, and arrays are joined with comma.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt64 {
+			return int64(v)
+		}
+		return v
+	case []interface{}:
+		elements := make([]string, len(v))
+		for idx, element := range v {
+			elements[idx] = valueString(normalizeValue(element))
+		}
+		return strings.Join(elements, ",")
+	default:
+		return v
+	}
+}
+
+func transformFirst(value interface{}) (interface{}, error) {
+	values, ok := value.([]interface{})
+	if !ok {
+		return value, nil
+	}
+	if len(values) == 0 {
+		return nil, errors.New("can not get first element of empty array")
+	}
+	return values[0], nil
+}
+
+func transformSum(value interface{}) (interface{}, error) {
+	values, ok := value.([]interface{})
+	if !ok {
+		return toFloat(value)
+	}
+	var sum float64
+	for _, element := range values {
+		f, err := toFloat(element)
+		if err != nil {
+			return nil, err
+		}
+		sum += f
+	}
+	return sum, nil
+}
+
+func regexTransform(regex *regexp.Regexp) transformFunc {
+	return func(value interface{}) (interface{}, error) {
+		matches := regex.FindStringSubmatch(valueString(value))
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("value %v does not match regex %s", value, regex.String())
+		}
+		if len(matches) > 1 {
+			return matches[1], nil
+		}
+		return matches[0], nil
+	}
+}
+
+func unitTransform(from, to uint) transformFunc {
+	return func(value interface{}) (interface{}, error) {
+		f, err := toFloat(value)
+		if err != nil {
+			return nil, err
+		}
+		// keep consistent with the builtin fields, the converted value is floored.
+		return math.Floor(f * math.Pow(2, float64(from)-float64(to))), nil
+	}
+}
+
+// perElement applies the transform to each element if the value is an array.
+func perElement(transform transformFunc) transformFunc {
+	return func(value interface{}) (interface{}, error) {
+		values, ok := value.([]interface{})
+		if !ok {
+			return transform(value)
+		}
+		results := make([]interface{}, 0, len(values))
+		for _, element := range values {
+			result, err := transform(element)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		return results, nil
+	}
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("value %s is not a number", v)
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("value %v is not a number", value)
+	}
+}
+
+// valueString returns the same string with gjson.Result.String() for the value,
+// so that it can be compared with the host's value got from cache.
+func valueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// setMappedFields sets the mapped fields to the setter, and returns the reason of the skipped fields.
+func setMappedFields(setter map[string]interface{}, val *gjson.Result, mappings []*fieldMapping) map[string]string {
+	skipped := make(map[string]string)
+	for _, mapping := range mappings {
+		value, err := mapping.value(val)
+		if err != nil {
+			skipped[mapping.propertyID] = err.Error()
+			continue
+		}
+		setter[mapping.propertyID] = value
+	}
+	return skipped
+}
+
+// ComputeSetter computes the host fields from the snapshot with the builtin fields and the mappings,
+// the mappings take precedence over the builtin fields.
+func ComputeSetter(val *gjson.Result, innerIP, outerIP string, mappings []metadata.HostSnapFieldMapping) (
+	map[string]interface{}, map[string]string, error) {
+
+	compiled := make([]*fieldMapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		m, err := compileFieldMapping(mapping)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid mapping of %s, err: %v", mapping.PropertyID, err)
+		}
+		compiled = append(compiled, m)
+	}
+
+	setter := parseSetter(val, innerIP, outerIP)
+	skipped := setMappedFields(setter, val, compiled)
+	return setter, skipped, nil
+}
+
+// ChangedFields returns the fields of the setter which differ from the host.
+func ChangedFields(setter map[string]interface{}, host string) map[string]interface{} {
+	changed := make(map[string]interface{})
+	for field, value := range setter {
+		current := gjson.Get(host, field)
+		// compare these value with string directly to avoid empty value or null value.
+		if valueString(value) == current.String() {
+			continue
+		}
+		// tolerate bk_cpu_mhz changes less than 100
+		if field == "bk_cpu_mhz" {
+			if f, err := toFloat(value); err == nil {
+				diff := int64(f) - current.Int()
+				if -100 < diff && diff < 100 {
+					continue
+				}
+			}
+		}
+		changed[field] = value
+	}
+	return changed
+}
+
+// UnwrapMessage returns the snapshot data of the message reported by the agent.
+func UnwrapMessage(msg string) string {
+	if !gjson.Get(msg, "cloudid").Exists() {
+		return gjson.Get(msg, "data").String()
+	}
+	return msg
+}
+
+// GetIPs returns the ips of the snapshot which can be used to find the host.
+func GetIPs(val *gjson.Result) []string {
+	return getIPS(val)
+}
+
+// RequiredHostFields returns the host fields needed to compare with the snapshot.
+func RequiredHostFields(mappings []metadata.HostSnapFieldMapping) []string {
+	fields := append([]string{}, reqireFields...)
+	for _, mapping := range mappings {
+		fields = append(fields, mapping.PropertyID)
+	}
+	return fields
+}
+
+// mappingCache caches the compiled field mappings of all the supplier accounts,
+// it's reloaded from db periodically so that the changes of the mappings take effect.
+type mappingCache struct {
+	db       dal.RDB
+	lock     sync.RWMutex
+	expireAt time.Time
+	mappings map[string][]*fieldMapping
+	fields   []string
+}
+
+func newMappingCache(db dal.RDB) *mappingCache {
+	return &mappingCache{
+		db:       db,
+		mappings: make(map[string][]*fieldMapping),
+		fields:   reqireFields,
+	}
+}
+
+// get returns the mappings of the supplier account.
+func (c *mappingCache) get(ctx context.Context, ownerID string) []*fieldMapping {
+	c.refresh(ctx)
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.mappings[ownerID]
+}
+
+// requiredFields returns the host fields needed to compare with the snapshot for all the supplier accounts.
+func (c *mappingCache) requiredFields(ctx context.Context) []string {
+	c.refresh(ctx)
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.fields
+}
+
+func (c *mappingCache) refresh(ctx context.Context) {
+	c.lock.RLock()
+	expired := time.Now().After(c.expireAt)
+	c.lock.RUnlock()
+	if !expired || c.db == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if time.Now().Before(c.expireAt) {
+		return
+	}
+	// do not reload too frequently even if it fails, the stale mappings are used in this case.
+	c.expireAt = time.Now().Add(mappingRefreshInterval)
+
+	all := make([]metadata.HostSnapFieldMapping, 0)
+	if err := c.db.Table(common.BKTableNameHostSnapFieldMapping).Find(nil).All(ctx, &all); err != nil {
+		blog.Errorf("load host snapshot field mappings failed, err: %v", err)
+		return
+	}
+
+	mappings := make(map[string][]*fieldMapping)
+	for _, mapping := range all {
+		m, err := compileFieldMapping(mapping)
+		if err != nil {
+			blog.Errorf("skip invalid host snapshot field mapping %d, err: %v", mapping.ID, err)
+			continue
+		}
+		mappings[mapping.SupplierAccount] = append(mappings[mapping.SupplierAccount], m)
+	}
+	c.mappings = mappings
+	c.fields = RequiredHostFields(all)
+}
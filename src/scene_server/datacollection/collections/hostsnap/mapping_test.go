@@ -0,0 +1,113 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostsnap
+
+import (
+	"testing"
+
+	"configcenter/src/common/metadata"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestComputeSetter(t *testing.T) {
+	val := gjson.Parse(UnwrapMessage(MockMessage))
+	mappings := []metadata.HostSnapFieldMapping{
+		{
+			PropertyID: "kernel_version",
+			Path:       "data.system.info.kernelVersion",
+			Transforms: []metadata.HostSnapTransform{{Type: metadata.HostSnapTransformRegex, Regex: `^([\d.]+-\d+)`}},
+		},
+		{
+			PropertyID: "disk_total",
+			Path:       "data.disk.usage.#.total",
+			Transforms: []metadata.HostSnapTransform{
+				{Type: metadata.HostSnapTransformSum},
+				{Type: metadata.HostSnapTransformUnit, FromUnit: "B", ToUnit: "GB"},
+			},
+		},
+		{
+			PropertyID: "cpu_model",
+			Path:       "data.cpu.cpuinfo.#.modelName",
+			Transforms: []metadata.HostSnapTransform{{Type: metadata.HostSnapTransformFirst}},
+		},
+		{
+			PropertyID: "nic_names",
+			Path:       "data.net.interface.#.name",
+		},
+		{
+			PropertyID: "bk_os_name",
+			Path:       "data.system.info.platformFamily",
+		},
+		{
+			PropertyID: "gpu_model",
+			Path:       "data.gpu.0.model",
+		},
+	}
+
+	setter, skipped, err := ComputeSetter(&val, "127.0.0.1", "", mappings)
+	if err != nil {
+		t.Fatalf("compute setter failed, err: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"kernel_version": "2.6.32-504",
+		"disk_total":     int64(49),
+		"cpu_model":      "Intel(R) Xeon(R) CPU E5-26xx v3",
+		"nic_names":      "lo,eth0",
+		"bk_os_name":     "rhel",
+		"bk_cpu":         int64(1),
+	}
+	for field, value := range expected {
+		if setter[field] != value {
+			t.Errorf("field %s expected %v(%T), got %v(%T)", field, value, value, setter[field], setter[field])
+		}
+	}
+
+	if _, exists := setter["gpu_model"]; exists {
+		t.Errorf("field gpu_model should not be set")
+	}
+	if _, exists := skipped["gpu_model"]; !exists {
+		t.Errorf("field gpu_model should be skipped, skipped: %v", skipped)
+	}
+}
+
+func TestComputeSetterWithInvalidMapping(t *testing.T) {
+	val := gjson.Parse(UnwrapMessage(MockMessage))
+	mappings := []metadata.HostSnapFieldMapping{
+		{
+			PropertyID: "bk_mem",
+			Path:       "data.mem.meminfo.total",
+			Transforms: []metadata.HostSnapTransform{{Type: metadata.HostSnapTransformUnit, FromUnit: "B", ToUnit: "PB"}},
+		},
+	}
+	if _, _, err := ComputeSetter(&val, "127.0.0.1", "", mappings); err == nil {
+		t.Errorf("compute setter with invalid unit should fail")
+	}
+}
+
+func TestChangedFields(t *testing.T) {
+	setter := map[string]interface{}{
+		"bk_cpu":         int64(8),
+		"bk_cpu_mhz":     int64(2294),
+		"bk_os_name":     "linux centos",
+		"kernel_version": "3.10.0",
+		"disk_total":     49.5,
+	}
+	host := `{"bk_cpu":8,"bk_cpu_mhz":2300,"bk_os_name":"linux centos","kernel_version":"2.6.32","disk_total":49.5}`
+
+	changed := ChangedFields(setter, host)
+	if len(changed) != 1 || changed["kernel_version"] != "3.10.0" {
+		t.Errorf("expected only kernel_version changed, got %v", changed)
+	}
+}
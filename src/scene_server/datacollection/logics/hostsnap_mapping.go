@@ -0,0 +1,283 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/datacollection/collections/hostsnap"
+
+	"github.com/tidwall/gjson"
+)
+
+// CreateHostSnapFieldMapping create a mapping from the host snapshot to the host attribute
+func (lgc *Logics) CreateHostSnapFieldMapping(pHeader http.Header, option meta.CreateHostSnapFieldMappingOption) (
+	*meta.HostSnapFieldMapping, error) {
+
+	defErr := lgc.Engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+	rid := util.GetHTTPCCRequestID(pHeader)
+	ownerID := util.GetOwnerID(pHeader)
+	user := util.GetUser(pHeader)
+
+	now := time.Now()
+	mapping := meta.HostSnapFieldMapping{
+		PropertyID:      option.PropertyID,
+		Path:            option.Path,
+		Transforms:      option.Transforms,
+		Description:     option.Description,
+		Creator:         user,
+		Modifier:        user,
+		CreateTime:      now,
+		LastTime:        now,
+		SupplierAccount: ownerID,
+	}
+	if mapping.Transforms == nil {
+		mapping.Transforms = make([]meta.HostSnapTransform, 0)
+	}
+	if key, err := mapping.Validate(); err != nil {
+		blog.Errorf("[HostSnapMapping] create mapping failed, invalid option: %#v, err: %v, rid: %s", option, err, rid)
+		return nil, defErr.Errorf(common.CCErrCommParamsInvalid, key)
+	}
+	if err := lgc.checkHostSnapMappingProperty(pHeader, mapping.PropertyID); err != nil {
+		return nil, err
+	}
+
+	cond := map[string]interface{}{
+		common.BKPropertyIDField: mapping.PropertyID,
+		common.BKOwnerIDField:    ownerID,
+	}
+	count, err := lgc.db.Table(common.BKTableNameHostSnapFieldMapping).Find(cond).Count(lgc.ctx)
+	if err != nil {
+		blog.Errorf("[HostSnapMapping] count mapping failed, cond: %#v, err: %v, rid: %s", cond, err, rid)
+		return nil, defErr.Error(common.CCErrCollectHostSnapMappingCreateFail)
+	}
+	if count > 0 {
+		return nil, defErr.Errorf(common.CCErrCollectHostSnapMappingDuplicated, mapping.PropertyID)
+	}
+
+	id, err := lgc.db.NextSequence(lgc.ctx, common.BKTableNameHostSnapFieldMapping)
+	if err != nil {
+		blog.Errorf("[HostSnapMapping] generate mapping id failed, err: %v, rid: %s", err, rid)
+		return nil, defErr.Error(common.CCErrCollectHostSnapMappingCreateFail)
+	}
+	mapping.ID = int64(id)
+
+	if err := lgc.db.Table(common.BKTableNameHostSnapFieldMapping).Insert(lgc.ctx, mapping); err != nil {
+		blog.Errorf("[HostSnapMapping] insert mapping %#v failed, err: %v, rid: %s", mapping, err, rid)
+		return nil, defErr.Error(common.CCErrCollectHostSnapMappingCreateFail)
+	}
+
+	return &mapping, nil
+}
+
+// UpdateHostSnapFieldMapping update the path and transforms of the mapping
+func (lgc *Logics) UpdateHostSnapFieldMapping(pHeader http.Header, id int64, option meta.UpdateHostSnapFieldMappingOption) (
+	*meta.HostSnapFieldMapping, error) {
+
+	defErr := lgc.Engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+	rid := util.GetHTTPCCRequestID(pHeader)
+
+	cond := map[string]interface{}{
+		common.BKFieldID:      id,
+		common.BKOwnerIDField: util.GetOwnerID(pHeader),
+	}
+	mapping := meta.HostSnapFieldMapping{}
+	if err := lgc.db.Table(common.BKTableNameHostSnapFieldMapping).Find(cond).One(lgc.ctx, &mapping); err != nil {
+		if lgc.db.IsNotFoundError(err) {
+			return nil, defErr.Error(common.CCErrCommNotFound)
+		}
+		blog.Errorf("[HostSnapMapping] get mapping failed, cond: %#v, err: %v, rid: %s", cond, err, rid)
+		return nil, defErr.Error(common.CCErrCollectHostSnapMappingUpdateFail)
+	}
+
+	mapping.Path = option.Path
+	mapping.Transforms = option.Transforms
+	if mapping.Transforms == nil {
+		mapping.Transforms = make([]meta.HostSnapTransform, 0)
+	}
+	mapping.Description = option.Description
+	mapping.Modifier = util.GetUser(pHeader)
+	mapping.LastTime = time.Now()
+	if key, err := mapping.Validate(); err != nil {
+		blog.Errorf("[HostSnapMapping] update mapping failed, invalid option: %#v, err: %v, rid: %s", option, err, rid)
+		return nil, defErr.Errorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	if err := lgc.db.Table(common.BKTableNameHostSnapFieldMapping).Update(lgc.ctx, cond, mapping); err != nil {
+		blog.Errorf("[HostSnapMapping] update mapping %d failed, err: %v, rid: %s", id, err, rid)
+		return nil, defErr.Error(common.CCErrCollectHostSnapMappingUpdateFail)
+	}
+
+	return &mapping, nil
+}
+
+// DeleteHostSnapFieldMapping delete the mappings
+func (lgc *Logics) DeleteHostSnapFieldMapping(pHeader http.Header, ids []int64) error {
+	defErr := lgc.Engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+	rid := util.GetHTTPCCRequestID(pHeader)
+
+	cond := map[string]interface{}{
+		common.BKFieldID:      map[string]interface{}{common.BKDBIN: ids},
+		common.BKOwnerIDField: util.GetOwnerID(pHeader),
+	}
+	if err := lgc.db.Table(common.BKTableNameHostSnapFieldMapping).Delete(lgc.ctx, cond); err != nil {
+		blog.Errorf("[HostSnapMapping] delete mappings failed, cond: %#v, err: %v, rid: %s", cond, err, rid)
+		return defErr.Error(common.CCErrCollectHostSnapMappingDeleteFail)
+	}
+
+	return nil
+}
+
+// ListHostSnapFieldMapping list the mappings of the supplier account
+func (lgc *Logics) ListHostSnapFieldMapping(pHeader http.Header, option meta.ListHostSnapFieldMappingOption) (
+	*meta.MultipleHostSnapFieldMapping, error) {
+
+	defErr := lgc.Engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+	rid := util.GetHTTPCCRequestID(pHeader)
+
+	cond := map[string]interface{}{
+		common.BKOwnerIDField: util.GetOwnerID(pHeader),
+	}
+	if len(option.PropertyIDs) > 0 {
+		cond[common.BKPropertyIDField] = map[string]interface{}{common.BKDBIN: option.PropertyIDs}
+	}
+
+	count, err := lgc.db.Table(common.BKTableNameHostSnapFieldMapping).Find(cond).Count(lgc.ctx)
+	if err != nil {
+		blog.Errorf("[HostSnapMapping] count mappings failed, cond: %#v, err: %v, rid: %s", cond, err, rid)
+		return nil, defErr.Error(common.CCErrCollectHostSnapMappingSearchFail)
+	}
+
+	sort := option.Page.Sort
+	if len(sort) == 0 {
+		sort = common.BKFieldID
+	}
+	query := lgc.db.Table(common.BKTableNameHostSnapFieldMapping).Find(cond).Sort(sort).Start(uint64(option.Page.Start))
+	if option.Page.Limit > 0 {
+		query = query.Limit(uint64(option.Page.Limit))
+	}
+	mappings := make([]meta.HostSnapFieldMapping, 0)
+	if err := query.All(lgc.ctx, &mappings); err != nil {
+		blog.Errorf("[HostSnapMapping] list mappings failed, cond: %#v, err: %v, rid: %s", cond, err, rid)
+		return nil, defErr.Error(common.CCErrCollectHostSnapMappingSearchFail)
+	}
+
+	return &meta.MultipleHostSnapFieldMapping{Count: int64(count), Info: mappings}, nil
+}
+
+// DryRunHostSnapFieldMapping computes the host update of the sample snapshot message without saving it.
+func (lgc *Logics) DryRunHostSnapFieldMapping(pHeader http.Header, option meta.HostSnapMappingDryRunOption) (
+	*meta.HostSnapMappingDryRunResult, error) {
+
+	defErr := lgc.Engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+	rid := util.GetHTTPCCRequestID(pHeader)
+
+	// the message may be the json string reported by the agent
+	msg := string(option.Message)
+	if gjson.Parse(msg).Type == gjson.String {
+		if err := json.Unmarshal(option.Message, &msg); err != nil {
+			return nil, defErr.Errorf(common.CCErrCommParamsInvalid, "message")
+		}
+	}
+	if !gjson.Valid(msg) {
+		return nil, defErr.Errorf(common.CCErrCommParamsInvalid, "message")
+	}
+
+	mappings := option.Mappings
+	if len(mappings) == 0 {
+		list, err := lgc.ListHostSnapFieldMapping(pHeader, meta.ListHostSnapFieldMappingOption{})
+		if err != nil {
+			return nil, err
+		}
+		mappings = list.Info
+	}
+
+	val := gjson.Parse(hostsnap.UnwrapMessage(msg))
+	ips := hostsnap.GetIPs(&val)
+	if len(ips) == 0 {
+		return nil, defErr.Errorf(common.CCErrCommParamsInvalid, "message")
+	}
+
+	host := lgc.findSnapshotHost(pHeader, val.Get("cloudid").Int(), ips, hostsnap.RequiredHostFields(mappings))
+	innerIP, outerIP := ips[0], ""
+	if len(host) > 0 {
+		innerIP = gjson.Get(host, common.BKHostInnerIPField).String()
+		outerIP = gjson.Get(host, common.BKHostOuterIPField).String()
+	}
+
+	setter, skipped, err := hostsnap.ComputeSetter(&val, innerIP, outerIP, mappings)
+	if err != nil {
+		blog.Errorf("[HostSnapMapping] dry run failed, err: %v, rid: %s", err, rid)
+		return nil, defErr.Errorf(common.CCErrCommParamsInvalid, "mappings")
+	}
+
+	result := &meta.HostSnapMappingDryRunResult{
+		Setter:  setter,
+		Update:  make(map[string]interface{}),
+		Skipped: skipped,
+	}
+	if len(host) > 0 {
+		result.HostID = gjson.Get(host, common.BKHostIDField).Int()
+		result.Update = hostsnap.ChangedFields(setter, host)
+	}
+	return result, nil
+}
+
+// findSnapshotHost find the host of the snapshot with the ips, returns empty string if not found.
+func (lgc *Logics) findSnapshotHost(pHeader http.Header, cloudID int64, ips []string, fields []string) string {
+	rid := util.GetHTTPCCRequestID(pHeader)
+	for _, ip := range ips {
+		opt := &meta.SearchHostWithInnerIPOption{
+			InnerIP: ip,
+			CloudID: cloudID,
+			Fields:  fields,
+		}
+		host, err := lgc.CoreAPI.CoreService().Cache().SearchHostWithInnerIP(lgc.ctx, pHeader, opt)
+		if err != nil {
+			blog.Warnf("[HostSnapMapping] get host with ip: %s, cloud id: %d failed, err: %v, rid: %s", ip, cloudID, err, rid)
+			continue
+		}
+		if len(host) > 0 {
+			return host
+		}
+	}
+	return ""
+}
+
+// checkHostSnapMappingProperty check the mapped property is a host attribute
+func (lgc *Logics) checkHostSnapMappingProperty(pHeader http.Header, propertyID string) error {
+	defErr := lgc.Engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+	rid := util.GetHTTPCCRequestID(pHeader)
+
+	cond := map[string]interface{}{
+		common.BKObjIDField:      common.BKInnerObjIDHost,
+		common.BKPropertyIDField: propertyID,
+	}
+	cond = util.SetQueryOwner(cond, util.GetOwnerID(pHeader))
+	count, err := lgc.db.Table(common.BKTableNameObjAttDes).Find(cond).Count(lgc.ctx)
+	if err != nil {
+		blog.Errorf("[HostSnapMapping] count host attribute failed, cond: %#v, err: %v, rid: %s", cond, err, rid)
+		return defErr.Error(common.CCErrCommDBSelectFailed)
+	}
+	if count == 0 {
+		blog.Errorf("[HostSnapMapping] host attribute %s not exist, rid: %s", propertyID, rid)
+		return defErr.Errorf(common.CCErrCommParamsInvalid, common.BKPropertyIDField)
+	}
+	return nil
+}
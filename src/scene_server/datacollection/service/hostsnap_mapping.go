@@ -0,0 +1,150 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// CreateHostSnapFieldMapping create host snapshot field mapping
+func (s *Service) CreateHostSnapFieldMapping(req *restful.Request, resp *restful.Response) {
+	pHeader := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+
+	option := meta.CreateHostSnapFieldMappingOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); nil != err {
+		blog.Errorf("[HostSnapMapping] create mapping failed with decode body err: %v, rid: %s", err, util.GetHTTPCCRequestID(pHeader))
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	mapping, err := s.logics.CreateHostSnapFieldMapping(pHeader, option)
+	if nil != err {
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: err})
+		return
+	}
+
+	_ = resp.WriteEntity(meta.NewSuccessResp(mapping))
+}
+
+// UpdateHostSnapFieldMapping update host snapshot field mapping
+func (s *Service) UpdateHostSnapFieldMapping(req *restful.Request, resp *restful.Response) {
+	pHeader := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+	rid := util.GetHTTPCCRequestID(pHeader)
+
+	id, err := strconv.ParseInt(req.PathParameter(common.BKFieldID), 10, 64)
+	if nil != err || id <= 0 {
+		blog.Errorf("[HostSnapMapping] update mapping failed, invalid id: %s, rid: %s", req.PathParameter(common.BKFieldID), rid)
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsNeedInt, common.BKFieldID)})
+		return
+	}
+
+	option := meta.UpdateHostSnapFieldMappingOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); nil != err {
+		blog.Errorf("[HostSnapMapping] update mapping failed with decode body err: %v, rid: %s", err, rid)
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	mapping, err := s.logics.UpdateHostSnapFieldMapping(pHeader, id, option)
+	if nil != err {
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: err})
+		return
+	}
+
+	_ = resp.WriteEntity(meta.NewSuccessResp(mapping))
+}
+
+// DeleteHostSnapFieldMapping delete host snapshot field mappings
+func (s *Service) DeleteHostSnapFieldMapping(req *restful.Request, resp *restful.Response) {
+	pHeader := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+
+	option := meta.DeleteHostSnapFieldMappingOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); nil != err {
+		blog.Errorf("[HostSnapMapping] delete mapping failed with decode body err: %v, rid: %s", err, util.GetHTTPCCRequestID(pHeader))
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if len(option.IDs) == 0 {
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsNeedSet, "ids")})
+		return
+	}
+
+	if err := s.logics.DeleteHostSnapFieldMapping(pHeader, option.IDs); nil != err {
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: err})
+		return
+	}
+
+	_ = resp.WriteEntity(meta.NewSuccessResp(nil))
+}
+
+// SearchHostSnapFieldMapping search host snapshot field mappings
+func (s *Service) SearchHostSnapFieldMapping(req *restful.Request, resp *restful.Response) {
+	pHeader := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+
+	option := meta.ListHostSnapFieldMappingOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); nil != err {
+		blog.Errorf("[HostSnapMapping] search mapping failed with decode body err: %v, rid: %s", err, util.GetHTTPCCRequestID(pHeader))
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if key, err := option.Page.Validate(true); err != nil {
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, key)})
+		return
+	}
+
+	mappings, err := s.logics.ListHostSnapFieldMapping(pHeader, option)
+	if nil != err {
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: err})
+		return
+	}
+
+	_ = resp.WriteEntity(meta.NewSuccessResp(mappings))
+}
+
+// DryRunHostSnapFieldMapping shows the host update computed from a sample snapshot message
+func (s *Service) DryRunHostSnapFieldMapping(req *restful.Request, resp *restful.Response) {
+	pHeader := req.Request.Header
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+
+	option := meta.HostSnapMappingDryRunOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); nil != err {
+		blog.Errorf("[HostSnapMapping] dry run failed with decode body err: %v, rid: %s", err, util.GetHTTPCCRequestID(pHeader))
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if len(option.Message) == 0 {
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: defErr.Errorf(common.CCErrCommParamsNeedSet, "message")})
+		return
+	}
+
+	result, err := s.logics.DryRunHostSnapFieldMapping(pHeader, option)
+	if nil != err {
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: err})
+		return
+	}
+
+	_ = resp.WriteEntity(meta.NewSuccessResp(result))
+}
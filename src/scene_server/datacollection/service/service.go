@@ -112,6 +112,12 @@ func (s *Service) WebService() *restful.Container {
 	api.Route(api.POST("/netcollect/collector/action/update").To(s.UpdateCollector))
 	api.Route(api.POST("/netcollect/collector/action/discover").To(s.DiscoverNetDevice))
 
+	api.Route(api.POST("/hostsnap/field_mapping/action/create").To(s.CreateHostSnapFieldMapping))
+	api.Route(api.POST("/hostsnap/field_mapping/{id}/action/update").To(s.UpdateHostSnapFieldMapping))
+	api.Route(api.DELETE("/hostsnap/field_mapping/action/delete").To(s.DeleteHostSnapFieldMapping))
+	api.Route(api.POST("/hostsnap/field_mapping/action/search").To(s.SearchHostSnapFieldMapping))
+	api.Route(api.POST("/hostsnap/field_mapping/action/dry_run").To(s.DryRunHostSnapFieldMapping))
+
 	container.Add(api)
 
 	healthzAPI := new(restful.WebService).Produces(restful.MIME_JSON)
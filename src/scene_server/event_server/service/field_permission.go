@@ -0,0 +1,76 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/fieldpermission"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+	"configcenter/src/common/watch"
+)
+
+// watchResourceObjects the objects of the watched resources whose event details are instances.
+var watchResourceObjects = map[watch.CursorType]string{
+	watch.Host:   common.BKInnerObjIDHost,
+	watch.Biz:    common.BKInnerObjIDApp,
+	watch.Set:    common.BKInnerObjIDSet,
+	watch.Module: common.BKInnerObjIDModule,
+}
+
+// filterEventDetailFields strip or mask the fields in the events' details which can not be read by the user.
+func (s *Service) filterEventDetailFields(header http.Header, rsc watch.CursorType, events []*watch.WatchEventDetail) error {
+	objID, exists := watchResourceObjects[rsc]
+	if !exists || len(events) == 0 {
+		return nil
+	}
+
+	rid := util.GetHTTPCCRequestID(header)
+	permission, err := fieldpermission.NewFieldPermission(s.CoreAPI, header).GetObjectFieldPermission(s.ctx, objID)
+	if err != nil {
+		blog.Errorf("get object %s field permission failed, err: %v, rid: %s", objID, err, rid)
+		return err
+	}
+	if permission.IsEmpty() {
+		return nil
+	}
+
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		detail, ok := event.Detail.(watch.JsonString)
+		if !ok || len(detail) == 0 {
+			continue
+		}
+
+		data := mapstr.New()
+		if err := json.Unmarshal([]byte(detail), &data); err != nil {
+			blog.Errorf("unmarshal event %s detail failed, detail: %s, err: %v, rid: %s", event.Cursor, detail, err, rid)
+			return err
+		}
+		permission.FilterData(data)
+
+		filtered, err := json.Marshal(data)
+		if err != nil {
+			blog.Errorf("marshal event %s detail failed, err: %v, rid: %s", event.Cursor, err, rid)
+			return err
+		}
+		event.Detail = watch.JsonString(filtered)
+	}
+	return nil
+}
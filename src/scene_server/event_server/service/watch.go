@@ -22,10 +22,12 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/json"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/selector"
 	"configcenter/src/common/util"
 	"configcenter/src/common/watch"
 	"configcenter/src/source_controller/coreservice/event"
 	"github.com/emicklei/go-restful"
+	"github.com/tidwall/gjson"
 	"gopkg.in/redis.v5"
 )
 
@@ -66,6 +68,11 @@ func (s *Service) WatchEvent(req *restful.Request, resp *restful.Response) {
 			return
 		}
 
+		if err := s.filterEventDetailFields(header, options.Resource, events); err != nil {
+			resp.WriteError(http.StatusOK, &metadata.RespError{Msg: err})
+			return
+		}
+
 		resp.WriteEntity(s.generateResp(options.Resource, events))
 		return
 	}
@@ -79,6 +86,11 @@ func (s *Service) WatchEvent(req *restful.Request, resp *restful.Response) {
 			return
 		}
 
+		if err := s.filterEventDetailFields(header, options.Resource, events); err != nil {
+			resp.WriteError(http.StatusOK, &metadata.RespError{Msg: err})
+			return
+		}
+
 		resp.WriteEntity(s.generateResp(options.Resource, events))
 		return
 	}
@@ -91,6 +103,11 @@ func (s *Service) WatchEvent(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
+	if err := s.filterEventDetailFields(header, options.Resource, []*watch.WatchEventDetail{events}); err != nil {
+		resp.WriteError(http.StatusOK, &metadata.RespError{Msg: err})
+		return
+	}
+
 	resp.WriteEntity(s.generateResp(options.Resource, []*watch.WatchEventDetail{events}))
 }
 
@@ -212,6 +229,17 @@ func (s *Service) watchWithStartFrom(key event.Key, opts *watch.WatchEventOption
 		lastNode := nodes[len(nodes)-1]
 		if lastNode.NextCursor == key.TailKey() {
 			// has already scan to the end, no need to scan anymore
+			if len(opts.Selectors) != 0 {
+				// the last event is not matched, return it's cursor with nil detail like the events
+				// filtered by the label selectors, so that the unmatched resource is not exposed.
+				return []*watch.WatchEventDetail{{
+					Cursor:    lastNode.Cursor,
+					Resource:  opts.Resource,
+					EventType: lastNode.EventType,
+					Detail:    nil,
+				}}, nil
+			}
+
 			// get event detail.
 			detail, err := s.cache.Get(key.DetailKey(lastNode.Cursor)).Result()
 			if err != nil {
@@ -235,12 +263,14 @@ func (s *Service) watchWithStartFrom(key event.Key, opts *watch.WatchEventOption
 
 func (s *Service) getEventsWithCursorNodes(opts *watch.WatchEventOptions, hitNodes []*watch.ChainNode, key event.Key, rid string) ([]*watch.WatchEventDetail, error) {
 	results := make([]*redis.StringCmd, 0)
+	detailNodes := make([]*watch.ChainNode, 0)
 	pipe := s.cache.Pipeline()
 	for _, node := range hitNodes {
 		if node.Cursor == key.TailKey() {
 			continue
 		}
 		results = append(results, pipe.Get(key.DetailKey(node.Cursor)))
+		detailNodes = append(detailNodes, node)
 	}
 	_, err := pipe.Exec()
 	if err != nil {
@@ -251,17 +281,45 @@ func (s *Service) getEventsWithCursorNodes(opts *watch.WatchEventOptions, hitNod
 	resp := make([]*watch.WatchEventDetail, 0)
 	for idx, result := range results {
 		jsonStr := result.Val()
+		if !matchLabelSelectors(opts.Selectors, jsonStr) {
+			continue
+		}
 		cut := json.CutJsonDataWithFields(&jsonStr, opts.Fields)
 		resp = append(resp, &watch.WatchEventDetail{
-			Cursor:    hitNodes[idx].Cursor,
+			Cursor:    detailNodes[idx].Cursor,
 			Resource:  opts.Resource,
-			EventType: hitNodes[idx].EventType,
+			EventType: detailNodes[idx].EventType,
 			Detail:    watch.JsonString(*cut),
 		})
 	}
+
+	if len(resp) == 0 && len(detailNodes) != 0 {
+		// all the events are filtered by the label selectors, return the last event cursor with nil detail,
+		// so that the user can watch from here in the next round.
+		lastNode := detailNodes[len(detailNodes)-1]
+		resp = append(resp, &watch.WatchEventDetail{
+			Cursor:    lastNode.Cursor,
+			Resource:  opts.Resource,
+			EventType: lastNode.EventType,
+			Detail:    nil,
+		})
+	}
 	return resp, nil
 }
 
+// matchLabelSelectors check if the labels of the resource in the event detail match the label selectors
+func matchLabelSelectors(selectors selector.Selectors, detail string) bool {
+	if len(selectors) == 0 {
+		return true
+	}
+
+	labels := make(selector.Labels)
+	for key, value := range gjson.Get(detail, selector.LabelsField).Map() {
+		labels[key] = value.String()
+	}
+	return selectors.Match(labels)
+}
+
 func (s *Service) watchFromNow(key event.Key, opts *watch.WatchEventOptions, rid string) (*watch.WatchEventDetail, error) {
 	node, tailTarget, err := s.getLatestEventDetail(key)
 	if err != nil {
@@ -270,7 +328,7 @@ func (s *Service) watchFromNow(key event.Key, opts *watch.WatchEventOptions, rid
 	}
 
 	hit := getHitNodeWithEventType([]*watch.ChainNode{node}, opts.EventTypes)
-	if len(hit) == 0 {
+	if len(hit) == 0 || !matchLabelSelectors(opts.Selectors, tailTarget) {
 		// not matched, set to no event cursor with empty detail
 		return &watch.WatchEventDetail{
 			Cursor:    watch.NoEventCursor,
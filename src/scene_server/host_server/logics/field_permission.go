@@ -0,0 +1,133 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"context"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/fieldpermission"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	params "configcenter/src/common/paraparse"
+)
+
+// FilterSearchHostFields strip or mask the host and it's topology fields which can not be read by the user.
+func (lgc *Logics) FilterSearchHostFields(ctx context.Context, hosts *metadata.SearchHost) error {
+	if hosts == nil || len(hosts.Info) == 0 {
+		return nil
+	}
+
+	getter := fieldpermission.NewFieldPermission(lgc.CoreAPI, lgc.header)
+	for _, objID := range []string{common.BKInnerObjIDHost, common.BKInnerObjIDApp, common.BKInnerObjIDSet, common.BKInnerObjIDModule} {
+		permission, err := getter.GetObjectFieldPermission(ctx, objID)
+		if err != nil {
+			blog.Errorf("get object %s field permission failed, err: %v, rid: %s", objID, err, lgc.rid)
+			return err
+		}
+		if permission.IsEmpty() {
+			continue
+		}
+
+		for _, item := range hosts.Info {
+			switch data := item[objID].(type) {
+			case mapstr.MapStr:
+				permission.FilterData(data)
+			case []mapstr.MapStr:
+				for _, inst := range data {
+					permission.FilterData(inst)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// CheckSearchHostConditionReadable check if the user can read all the fields that the host search condition
+// and sort refer to, otherwise the user can find out the hidden values by the hosts found.
+func (lgc *Logics) CheckSearchHostConditionReadable(ctx context.Context, option *metadata.HostCommonSearch) error {
+	conds := make(map[string]map[string]interface{})
+	for _, cond := range option.Condition {
+		if _, ok := conds[cond.ObjectID]; !ok {
+			conds[cond.ObjectID] = make(map[string]interface{})
+		}
+		items := cond.Condition
+		// the invalid selectors are rejected by the search itself.
+		if labelItems, err := cond.LabelConditions(); err == nil {
+			items = append(items, labelItems...)
+		}
+		for _, item := range items {
+			conds[cond.ObjectID][item.Field] = item.Value
+		}
+	}
+
+	if option.Ip.HasCondition() {
+		if _, ok := conds[common.BKInnerObjIDHost]; !ok {
+			conds[common.BKInnerObjIDHost] = make(map[string]interface{})
+		}
+		switch option.Ip.Flag {
+		case params.INNERONLY:
+			conds[common.BKInnerObjIDHost][common.BKHostInnerIPField] = option.Ip.Data
+		case params.OUTERONLY:
+			conds[common.BKInnerObjIDHost][common.BKHostOuterIPField] = option.Ip.Data
+		case params.IOBOTH:
+			conds[common.BKInnerObjIDHost][common.BKHostInnerIPField] = option.Ip.Data
+			conds[common.BKInnerObjIDHost][common.BKHostOuterIPField] = option.Ip.Data
+		}
+	}
+
+	// the hosts are sorted by the host fields.
+	if len(option.Page.Sort) > 0 {
+		if _, ok := conds[common.BKInnerObjIDHost]; !ok {
+			conds[common.BKInnerObjIDHost] = make(map[string]interface{})
+		}
+	}
+
+	getter := fieldpermission.NewFieldPermission(lgc.CoreAPI, lgc.header)
+	for objID, cond := range conds {
+		permission, err := getter.GetObjectFieldPermission(ctx, objID)
+		if err != nil {
+			blog.Errorf("get object %s field permission failed, err: %v, rid: %s", objID, err, lgc.rid)
+			return err
+		}
+
+		sort := ""
+		if objID == common.BKInnerObjIDHost {
+			sort = option.Page.Sort
+		}
+		if unreadable := permission.UnreadableConditionFields(cond, sort); len(unreadable) > 0 {
+			blog.Errorf("user %s has no permission to search %s by fields %v, rid: %s", lgc.user, objID, unreadable, lgc.rid)
+			return lgc.ccErr.CCErrorf(common.CCErrCommFieldReadPermissionDenied, strings.Join(unreadable, ","))
+		}
+	}
+	return nil
+}
+
+// CheckHostFieldsWritable check if the user can edit all the host fields in the datas.
+func (lgc *Logics) CheckHostFieldsWritable(ctx context.Context, datas ...mapstr.MapStr) error {
+	permission, err := fieldpermission.NewFieldPermission(lgc.CoreAPI, lgc.header).GetObjectFieldPermission(ctx, common.BKInnerObjIDHost)
+	if err != nil {
+		blog.Errorf("get host field permission failed, err: %v, rid: %s", err, lgc.rid)
+		return err
+	}
+
+	for _, data := range datas {
+		if fields := permission.UnwritableFields(data); len(fields) > 0 {
+			blog.Errorf("user %s has no permission to edit host fields %v, rid: %s", lgc.user, fields, lgc.rid)
+			return lgc.ccErr.CCErrorf(common.CCErrCommFieldWritePermissionDenied, strings.Join(fields, ","))
+		}
+	}
+	return nil
+}
@@ -30,10 +30,16 @@ import (
 
 func (lgc *Logics) SearchHost(ctx context.Context, data *metadata.HostCommonSearch, isDetail bool) (*metadata.SearchHost, error) {
 	searchHostInst := NewSearchHost(ctx, lgc, data)
-	searchHostInst.ParseCondition()
 	retHostInfo := &metadata.SearchHost{
 		Info: make([]mapstr.MapStr, 0),
 	}
+	if key, err := data.Page.ValidateCursor(); err != nil {
+		blog.Errorf("search host with invalid page, page: %+v, err: %v, rid: %s", data.Page, err, lgc.rid)
+		return retHostInfo, lgc.ccErr.CCErrorf(common.CCErrCommParamsInvalid, "page."+key)
+	}
+	if err := searchHostInst.ParseCondition(); err != nil {
+		return retHostInfo, err
+	}
 	err := searchHostInst.SearchHostByConds()
 	if err != nil {
 		return retHostInfo, err
@@ -46,6 +52,7 @@ func (lgc *Logics) SearchHost(ctx context.Context, data *metadata.HostCommonSear
 	retHostInfo.Count = cnt
 	if cnt > 0 {
 		retHostInfo.Info = hostInfoArr
+		retHostInfo.NextCursor = searchHostInst.NextCursor()
 	}
 	return retHostInfo, nil
 }
@@ -103,6 +110,8 @@ type searchHost struct {
 	hostInfoArr  []hostInfoStruct // int64 is hostID
 	cacheInfoMap searchHostInfoMapCache
 	totalHostCnt int
+	// nextCursor is the cursor of the next page of the hosts
+	nextCursor string
 
 	paged bool
 
@@ -128,9 +137,10 @@ type appLevelInfo struct {
 
 // searchHostInterface Too many methods, hiding private methods
 type searchHostInterface interface {
-	ParseCondition()
+	ParseCondition() errors.CCError
 	SearchHostByConds() errors.CCError
 	FillTopologyData() ([]mapstr.MapStr, int, errors.CCError)
+	NextCursor() string
 }
 
 func NewSearchHost(ctx context.Context, lgc *Logics, hostSearchParam *metadata.HostCommonSearch) searchHostInterface {
@@ -149,9 +159,21 @@ func NewSearchHost(ctx context.Context, lgc *Logics, hostSearchParam *metadata.H
 	return sh
 }
 
-func (sh *searchHost) ParseCondition() {
+// NextCursor returns the cursor of the next page, the hosts paged in program have no cursor
+func (sh *searchHost) NextCursor() string {
+	return sh.nextCursor
+}
+
+func (sh *searchHost) ParseCondition() errors.CCError {
 
 	for _, object := range sh.hostSearchParam.Condition {
+		labelConds, err := object.LabelConditions()
+		if err != nil {
+			blog.Errorf("parse %s label selectors failed, err: %v, rid: %s", object.ObjectID, err, sh.ccRid)
+			return sh.ccErr.CCErrorf(common.CCErrCommParamsInvalid, "selectors")
+		}
+		object.Condition = append(object.Condition, labelConds...)
+
 		if object.ObjectID == common.BKInnerObjIDHost {
 			sh.conds.hostCond = object
 		} else if object.ObjectID == common.BKInnerObjIDSet {
@@ -175,6 +197,7 @@ func (sh *searchHost) ParseCondition() {
 
 	sh.tryParseAppID()
 
+	return nil
 }
 
 func (sh *searchHost) SearchHostByConds() errors.CCError {
@@ -678,6 +701,7 @@ func (sh *searchHost) searchByHostConds() errors.CCError {
 		Start:     sh.hostSearchParam.Page.Start,
 		Limit:     sh.hostSearchParam.Page.Limit,
 		Sort:      sh.hostSearchParam.Page.Sort,
+		Cursor:    sh.hostSearchParam.Page.Cursor,
 		Fields:    strings.Join(sh.conds.hostCond.Fields, ","),
 	}
 	sh.conds.hostCond.Fields = nil
@@ -703,6 +727,7 @@ func (sh *searchHost) searchByHostConds() errors.CCError {
 
 	if !sh.paged {
 		sh.totalHostCnt = gResult.Data.Count
+		sh.nextCursor = gResult.Data.NextCursor
 	}
 
 	if sh.searchedHostIDs == nil {
@@ -779,8 +804,9 @@ func (sh *searchHost) appendHostTopoConds() errors.CCError {
 
 	sh.totalHostCnt = len(respHostIDInfo.Data.IDArr)
 	// 当有根据主机实例内容查询的时候的时候，无法在程序中完成分页
+	// the hosts paged by the cursor are paged in db too, the cursor is generated by the db query
 	hasHostCond := false
-	if len(sh.hostSearchParam.Ip.Data) > 0 || len(sh.conds.hostCond.Condition) > 0 {
+	if sh.hostSearchParam.Ip.HasCondition() || len(sh.conds.hostCond.Condition) > 0 || sh.hostSearchParam.Page.Cursor != "" {
 		hasHostCond = true
 	}
 	if !hasHostCond && sh.hostSearchParam.Page.Limit > 0 {
@@ -14,12 +14,16 @@ package logics
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"time"
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
 )
 
 func (lgc *Logics) IsPlatExist(ctx context.Context, cond mapstr.MapStr) (bool, errors.CCError) {
@@ -46,3 +50,93 @@ func (lgc *Logics) IsPlatExist(ctx context.Context, cond mapstr.MapStr) (bool, e
 
 	return false, nil
 }
+
+// GetCloudAreaHostCount get the count of the hosts in the cloud area
+func (lgc *Logics) GetCloudAreaHostCount(ctx context.Context, cloudID int64) (int64, errors.CCError) {
+	query := &metadata.QueryInput{
+		Condition: map[string]interface{}{common.BKCloudIDField: cloudID},
+		Fields:    common.BKHostIDField,
+		Limit:     1,
+	}
+	result, err := lgc.CoreAPI.CoreService().Host().GetHosts(ctx, lgc.header, query)
+	if err != nil {
+		blog.Errorf("GetCloudAreaHostCount http do error, err:%s, cloudID:%d, rid:%s", err.Error(), cloudID, lgc.rid)
+		return 0, lgc.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !result.Result {
+		blog.Errorf("GetCloudAreaHostCount http response error, err code:%d, err msg:%s, cloudID:%d, rid:%s", result.Code, result.ErrMsg, cloudID, lgc.rid)
+		return 0, lgc.ccErr.New(result.Code, result.ErrMsg)
+	}
+	return int64(result.Data.Count), nil
+}
+
+// GetCloudAreaStatus get the status summary of the cloud areas, the cloud areas not found are ignored
+func (lgc *Logics) GetCloudAreaStatus(ctx context.Context, cloudIDs []int64) ([]metadata.CloudAreaStatus, errors.CCError) {
+	query := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKCloudIDField: mapstr.MapStr{common.BKDBIN: cloudIDs},
+		},
+		Page:   metadata.BasePage{Limit: common.BKNoLimit},
+		Fields: []string{common.BKCloudIDField, common.BKCloudNameField, common.BKCloudHostLimitField, common.BKCloudProxyField},
+	}
+	result, err := lgc.CoreAPI.CoreService().Instance().ReadInstance(ctx, lgc.header, common.BKInnerObjIDPlat, query)
+	if err != nil {
+		blog.Errorf("GetCloudAreaStatus http do error, err:%s, cloudIDs:%v, rid:%s", err.Error(), cloudIDs, lgc.rid)
+		return nil, lgc.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !result.Result {
+		blog.Errorf("GetCloudAreaStatus http response error, err code:%d, err msg:%s, cloudIDs:%v, rid:%s", result.Code, result.ErrMsg, cloudIDs, lgc.rid)
+		return nil, lgc.ccErr.New(result.Code, result.ErrMsg)
+	}
+	if len(result.Data.Info) == 0 {
+		return make([]metadata.CloudAreaStatus, 0), nil
+	}
+
+	statuses := make([]metadata.CloudAreaStatus, 0)
+	reportKeys := make([]string, 0)
+	for _, plat := range result.Data.Info {
+		cloudID, err := plat.Int64(common.BKCloudIDField)
+		if err != nil {
+			blog.Errorf("GetCloudAreaStatus failed, parse cloud id failed, plat: %+v, err: %v, rid: %s", plat, err, lgc.rid)
+			return nil, lgc.ccErr.Errorf(common.CCErrCommInstFieldConvertFail, common.BKInnerObjIDPlat, common.BKCloudIDField, "int", err.Error())
+		}
+		status := metadata.CloudAreaStatus{
+			CloudID: cloudID,
+			Proxies: make([]string, 0),
+		}
+		status.CloudName, _ = plat.String(common.BKCloudNameField)
+		status.HostLimit, _ = util.GetInt64ByInterface(plat[common.BKCloudHostLimitField])
+		proxies, _ := plat.String(common.BKCloudProxyField)
+		for _, proxy := range strings.Split(proxies, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				status.Proxies = append(status.Proxies, proxy)
+			}
+		}
+
+		status.HostCount, err = lgc.GetCloudAreaHostCount(ctx, cloudID)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+		reportKeys = append(reportKeys, common.RedisCloudSnapReportKeyPrefix+strconv.FormatInt(cloudID, 10))
+	}
+
+	reportTimes, err := lgc.cache.MGet(reportKeys...).Result()
+	if err != nil {
+		blog.Errorf("GetCloudAreaStatus failed, get report time from redis failed, keys: %v, err: %v, rid: %s", reportKeys, err, lgc.rid)
+		return nil, lgc.ccErr.Error(common.CCErrCommRedisOPErr)
+	}
+	for idx, reportTime := range reportTimes {
+		if reportTime == nil {
+			continue
+		}
+		timestamp, err := util.GetInt64ByInterface(reportTime)
+		if err != nil {
+			blog.Errorf("GetCloudAreaStatus got invalid report time %v of key %s, rid: %s", reportTime, reportKeys[idx], lgc.rid)
+			continue
+		}
+		lastReportTime := time.Unix(timestamp, 0)
+		statuses[idx].LastReportTime = &lastReportTime
+	}
+	return statuses, nil
+}
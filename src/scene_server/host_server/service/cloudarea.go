@@ -15,6 +15,7 @@ package service
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"configcenter/src/auth/extensions"
 	authmeta "configcenter/src/auth/meta"
@@ -113,6 +114,7 @@ func (s *Service) CreatePlat(req *restful.Request, resp *restful.Response) {
 	}
 	// read supplier account from header
 	input[common.BkSupplierAccount] = util.GetOwnerID(req.Request.Header)
+	input[common.CreatorField] = srvData.user
 
 	// auth: check authorization
 	if err := s.AuthManager.AuthorizeResourceCreate(srvData.ctx, srvData.header, 0, authmeta.Model); err != nil {
@@ -154,6 +156,16 @@ func (s *Service) CreatePlat(req *restful.Request, resp *restful.Response) {
 
 }
 
+// platUpdatableFields the fields of the cloud area which can be updated
+var platUpdatableFields = []string{
+	common.BKCloudNameField,
+	common.BKCloudVendorField,
+	common.BKCloudRegionField,
+	common.BKCloudVpcIDField,
+	common.BKCloudProxyField,
+	common.BKCloudHostLimitField,
+}
+
 func (s *Service) DelPlat(req *restful.Request, resp *restful.Response) {
 	srvData := s.newSrvComm(req.Request.Header)
 
@@ -229,6 +241,12 @@ func (s *Service) DelPlat(req *restful.Request, resp *restful.Response) {
 
 	}
 
+	// the report time of the deleted plat is useless
+	reportKey := common.RedisCloudSnapReportKeyPrefix + strconv.FormatInt(platID, 10)
+	if err := s.CacheDB.Del(reportKey).Err(); err != nil {
+		blog.Errorf("DelPlat success, but delete report time key %s failed, err: %v, rid: %s", reportKey, err, srvData.rid)
+	}
+
 	// deregister plat
 	if err := s.AuthManager.Authorize.DeregisterResource(srvData.ctx, iamResource...); err != nil {
 		blog.Errorf("DelPlat success, but DeregisterResource from iam failed, platID: %d, err: %+v,rid:%s", platID, err, srvData.rid)
@@ -263,9 +281,7 @@ func (s *Service) UpdatePlat(req *restful.Request, resp *restful.Response) {
 	}
 
 	// decode request body
-	input := struct {
-		CloudName string `json:"bk_cloud_name"`
-	}{}
+	input := make(map[string]interface{})
 	if err := json.NewDecoder(req.Request.Body).Decode(&input); err != nil {
 		blog.Errorf("UpdatePlat failed, err:%+v, rid:%s", err, srvData.rid)
 		ccErr := srvData.ccErr.Errorf(common.CCErrCommJSONUnmarshalFailed)
@@ -273,11 +289,46 @@ func (s *Service) UpdatePlat(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
+	// only the metadata fields of the cloud area can be updated, the creator and supplier account can not be changed
+	data := make(map[string]interface{})
+	for _, field := range platUpdatableFields {
+		if value, exists := input[field]; exists {
+			data[field] = value
+		}
+	}
+	if len(data) == 0 {
+		blog.Errorf("UpdatePlat failed, no updatable field, input:%+v, rid:%s", input, srvData.rid)
+		ccErr := srvData.ccErr.Errorf(common.CCErrCommParamsNeedSet, common.BKCloudNameField)
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: ccErr})
+		return
+	}
+
+	// the host limit can not be less than the count of the hosts already in the cloud area
+	if limitValue, exists := data[common.BKCloudHostLimitField]; exists && limitValue != nil {
+		limit, err := util.GetInt64ByInterface(limitValue)
+		if err != nil {
+			ccErr := srvData.ccErr.Errorf(common.CCErrCommParamsNeedInt, common.BKCloudHostLimitField)
+			_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: ccErr})
+			return
+		}
+		if limit > 0 {
+			count, ccErr := srvData.lgc.GetCloudAreaHostCount(srvData.ctx, platID)
+			if ccErr != nil {
+				_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: ccErr})
+				return
+			}
+			if count > limit {
+				blog.Errorf("UpdatePlat failed, host limit %d less than host count %d, platID: %d, rid:%s", limit, count, platID, srvData.rid)
+				ccErr := srvData.ccErr.CCErrorf(common.CCErrTopoCloudAreaHostLimitTooSmall, limit, count)
+				_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: ccErr})
+				return
+			}
+		}
+	}
+
 	// update plat
 	updateOption := &meta.UpdateOption{
-		Data: map[string]interface{}{
-			common.BKCloudNameField: input.CloudName,
-		},
+		Data: data,
 		Condition: map[string]interface{}{
 			common.BKCloudIDField: platID,
 		},
@@ -296,15 +347,17 @@ func (s *Service) UpdatePlat(req *restful.Request, resp *restful.Response) {
 	}
 
 	// auth: sync resource info to iam
-	iamPlat := extensions.PlatSimplify{
-		BKCloudIDField:   platID,
-		BKCloudNameField: input.CloudName,
-	}
-	if err := s.AuthManager.UpdateRegisteredPlat(srvData.ctx, srvData.header, iamPlat); err != nil {
-		blog.Errorf("UpdatePlat success, but UpdateRegisteredPlat failed, plat: %d, err: %v, rid: %s", platID, err, srvData.rid)
-		ccErr := &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommRegistResourceToIAMFailed)}
-		_ = resp.WriteError(http.StatusInternalServerError, ccErr)
-		return
+	if cloudName, exists := data[common.BKCloudNameField]; exists {
+		iamPlat := extensions.PlatSimplify{
+			BKCloudIDField:   platID,
+			BKCloudNameField: util.GetStrByInterface(cloudName),
+		}
+		if err := s.AuthManager.UpdateRegisteredPlat(srvData.ctx, srvData.header, iamPlat); err != nil {
+			blog.Errorf("UpdatePlat success, but UpdateRegisteredPlat failed, plat: %d, err: %v, rid: %s", platID, err, srvData.rid)
+			ccErr := &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommRegistResourceToIAMFailed)}
+			_ = resp.WriteError(http.StatusInternalServerError, ccErr)
+			return
+		}
 	}
 
 	// response success
@@ -345,3 +398,48 @@ func (s *Service) UpdateHostCloudAreaField(req *restful.Request, resp *restful.R
 		Data:     "",
 	})
 }
+
+// FindCloudAreaStatus get the status summary of the cloud areas, such as the host count, the last time that
+// the hosts in the cloud area reported snapshot and the agent proxies.
+func (s *Service) FindCloudAreaStatus(req *restful.Request, resp *restful.Response) {
+	srvData := s.newSrvComm(req.Request.Header)
+	rid := srvData.rid
+
+	input := new(metadata.CloudAreaStatusOption)
+	if err := json.NewDecoder(req.Request.Body).Decode(input); err != nil {
+		blog.Errorf("FindCloudAreaStatus failed, decode body failed, err: %v, rid: %s", err, rid)
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if len(input.CloudIDs) == 0 {
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: srvData.ccErr.Errorf(common.CCErrCommParamsNeedSet, "bk_cloud_ids")})
+		return
+	}
+	if len(input.CloudIDs) > common.BKMaxPageSize {
+		ccErr := srvData.ccErr.CCErrorf(common.CCErrExceedMaxOperationRecordsAtOnce, common.BKMaxPageSize)
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: ccErr})
+		return
+	}
+	input.CloudIDs = util.IntArrayUnique(input.CloudIDs)
+
+	// auth: check authorization
+	if s.AuthManager.Enabled() && !s.AuthManager.SkipReadAuthorization {
+		if err := s.AuthManager.AuthorizeByPlatIDs(srvData.ctx, srvData.header, authmeta.Find, input.CloudIDs...); err != nil {
+			blog.Errorf("check find plat authorization failed, plats: %v, err: %v, rid: %s", input.CloudIDs, err, rid)
+			_ = resp.WriteError(http.StatusForbidden, &metadata.RespError{Msg: srvData.ccErr.Error(common.CCErrCommAuthorizeFailed)})
+			return
+		}
+	}
+
+	statuses, err := srvData.lgc.GetCloudAreaStatus(srvData.ctx, input.CloudIDs)
+	if err != nil {
+		blog.Errorf("FindCloudAreaStatus failed, cloudIDs: %v, err: %v, rid: %s", input.CloudIDs, err, rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: err})
+		return
+	}
+
+	_ = resp.WriteEntity(metadata.Response{
+		BaseResp: metadata.SuccessBaseResp,
+		Data:     statuses,
+	})
+}
@@ -394,6 +394,15 @@ func (s *Service) AddHost(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
+	hostDatas := make([]mapstr.MapStr, 0)
+	for _, host := range hostList.HostInfo {
+		hostDatas = append(hostDatas, host)
+	}
+	if err := srvData.lgc.CheckHostFieldsWritable(srvData.ctx, hostDatas...); err != nil {
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: err})
+		return
+	}
+
 	appID := hostList.ApplicationID
 	if appID == 0 {
 		// get default app id
@@ -526,6 +535,11 @@ func (s *Service) SearchHost(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
+	if err := srvData.lgc.CheckSearchHostConditionReadable(srvData.ctx, body); err != nil {
+		_ = resp.WriteError(http.StatusForbidden, &meta.RespError{Msg: err})
+		return
+	}
+
 	host, err := srvData.lgc.SearchHost(srvData.ctx, body, false)
 	if err != nil {
 		blog.Errorf("search host failed, err: %v,input:%+v,rid:%s", err, body, srvData.rid)
@@ -541,6 +555,12 @@ func (s *Service) SearchHost(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
+	if err := srvData.lgc.FilterSearchHostFields(srvData.ctx, host); err != nil {
+		blog.Errorf("search host failed, filter fields by field permission failed, err: %v, rid: %s", err, srvData.rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: err})
+		return
+	}
+
 	_ = resp.WriteEntity(meta.SearchHostResult{
 		BaseResp: meta.SuccessBaseResp,
 		Data:     *host,
@@ -557,6 +577,11 @@ func (s *Service) SearchHostWithAsstDetail(req *restful.Request, resp *restful.R
 		return
 	}
 
+	if err := srvData.lgc.CheckSearchHostConditionReadable(srvData.ctx, body); err != nil {
+		_ = resp.WriteError(http.StatusForbidden, &meta.RespError{Msg: err})
+		return
+	}
+
 	host, err := srvData.lgc.SearchHost(srvData.ctx, body, true)
 	if err != nil {
 		blog.Errorf("search host failed, err: %v,input:%+v,rid:%s", err, body, srvData.rid)
@@ -572,6 +597,12 @@ func (s *Service) SearchHostWithAsstDetail(req *restful.Request, resp *restful.R
 		return
 	}
 
+	if err := srvData.lgc.FilterSearchHostFields(srvData.ctx, host); err != nil {
+		blog.Errorf("search host failed, filter fields by field permission failed, err: %v, rid: %s", err, srvData.rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: err})
+		return
+	}
+
 	_ = resp.WriteEntity(meta.SearchHostResult{
 		BaseResp: meta.SuccessBaseResp,
 		Data:     *host,
@@ -674,6 +705,11 @@ func (s *Service) UpdateHostBatch(req *restful.Request, resp *restful.Response)
 		return
 	}
 
+	if err := srvData.lgc.CheckHostFieldsWritable(srvData.ctx, data); err != nil {
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: err})
+		return
+	}
+
 	// check authorization
 	hostIDArr := make([]int64, 0)
 	for _, id := range strings.Split(hostIDStr, ",") {
@@ -843,6 +879,21 @@ func (s *Service) UpdateHostPropertyBatch(req *restful.Request, resp *restful.Re
 		return
 	}
 
+	updateDatas := make([]mapstr.MapStr, 0)
+	for _, update := range parameter.Update {
+		data, err := mapstr.NewFromInterface(update.Properties)
+		if err != nil {
+			blog.Errorf("update host property batch, but convert properties[%v] to mapstr failed, err: %v, rid: %s", update.Properties, err, srvData.rid)
+			_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: err})
+			return
+		}
+		updateDatas = append(updateDatas, data)
+	}
+	if err := srvData.lgc.CheckHostFieldsWritable(srvData.ctx, updateDatas...); err != nil {
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: err})
+		return
+	}
+
 	// check authorization
 	hostIDArr := make([]int64, 0)
 	for _, update := range parameter.Update {
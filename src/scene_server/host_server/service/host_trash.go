@@ -0,0 +1,164 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	meta "configcenter/src/common/metadata"
+	hutil "configcenter/src/scene_server/host_server/util"
+
+	"github.com/emicklei/go-restful"
+)
+
+// ListHostTrash returns the hosts deleted from the resource pool which are kept in the trash bin
+func (s *Service) ListHostTrash(req *restful.Request, resp *restful.Response) {
+	srvData := s.newSrvComm(req.Request.Header)
+	option := meta.ListTrashOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("list host trash failed with decode body err: %v, rid: %s", err, srvData.rid)
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	result, err := s.CoreAPI.CoreService().Trash().ListTrash(srvData.ctx, srvData.header, common.BKInnerObjIDHost, option)
+	if err != nil {
+		blog.Errorf("list host trash failed, option: %+v, err: %v, rid: %s", option, err, srvData.rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: err})
+		return
+	}
+
+	_ = resp.WriteEntity(meta.NewSuccessResp(result))
+}
+
+// RestoreHostTrash restore the hosts in the trash bin to the idle module of the resource pool
+func (s *Service) RestoreHostTrash(req *restful.Request, resp *restful.Response) {
+	srvData := s.newSrvComm(req.Request.Header)
+	option := meta.RestoreTrashOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("restore host trash failed with decode body err: %v, rid: %s", err, srvData.rid)
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if field, err := option.Validate(); err != nil {
+		blog.Errorf("restore host trash failed, option invalid, field: %s, err: %v, rid: %s", field, err, srvData.rid)
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: srvData.ccErr.Errorf(common.CCErrCommParamsInvalid, field)})
+		return
+	}
+
+	appID, err := srvData.lgc.GetDefaultAppIDWithSupplier(srvData.ctx)
+	if err != nil {
+		blog.Errorf("restore host trash, but get default app id failed, err: %v, rid: %s", err, srvData.rid)
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: err})
+		return
+	}
+	cond := hutil.NewOperation().WithModuleName(common.DefaultResModuleName).WithAppID(appID).MapStr()
+	cond.Set(common.BKDefaultField, common.DefaultResModuleFlag)
+	moduleID, err := srvData.lgc.GetResourcePoolModuleID(srvData.ctx, cond)
+	if err != nil {
+		blog.Errorf("restore host trash, but get module id failed, err: %v, rid: %s", err, srvData.rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: err})
+		return
+	}
+
+	// the hosts are restored, put into the idle module and registered in one transaction, so that a host
+	// is never left without module relation while it's trash item is deleted.
+	var result meta.RestoreTrashResult
+	hostIDs := make([]int64, 0)
+	txnErr := s.CoreAPI.CoreService().Txn().AutoRunTxn(srvData.ctx, s.EnableTxn, srvData.header, func() error {
+		var ccErr errors.CCErrorCoder
+		result, ccErr = s.CoreAPI.CoreService().Trash().RestoreTrash(srvData.ctx, srvData.header, common.BKInnerObjIDHost, option)
+		if ccErr != nil {
+			blog.Errorf("restore host trash failed, ids: %v, err: %v, rid: %s", option.IDs, ccErr, srvData.rid)
+			return ccErr
+		}
+
+		for _, restored := range result.Restored {
+			hostIDs = append(hostIDs, restored.InstID)
+		}
+
+		// the host module relations are deleted with the hosts, the restored hosts are put into the idle module.
+		transferInput := &meta.TransferHostToInnerModule{ApplicationID: appID, ModuleID: moduleID, HostID: hostIDs}
+		transferResult, err := s.CoreAPI.CoreService().Host().TransferToInnerModule(srvData.ctx, srvData.header, transferInput)
+		if err != nil {
+			blog.Errorf("restore host trash, but transfer hosts to idle module failed, input: %+v, err: %v, rid: %s", transferInput, err, srvData.rid)
+			return srvData.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if !transferResult.Result {
+			blog.Errorf("restore host trash, but transfer hosts to idle module failed, input: %+v, err code: %d, err msg: %s, rid: %s",
+				transferInput, transferResult.Code, transferResult.ErrMsg, srvData.rid)
+			return srvData.ccErr.New(transferResult.Code, transferResult.ErrMsg)
+		}
+
+		// auth: register hosts
+		if err := s.AuthManager.RegisterHostsByID(srvData.ctx, srvData.header, hostIDs...); err != nil {
+			blog.Errorf("register host to iam failed, hosts: %+v, err: %v, rid: %s", hostIDs, err, srvData.rid)
+			return srvData.ccErr.Error(common.CCErrCommRegistResourceToIAMFailed)
+		}
+		return nil
+	})
+	if txnErr != nil {
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: txnErr})
+		return
+	}
+
+	logs := make([]meta.AuditLog, 0)
+	for _, hostID := range hostIDs {
+		audit := srvData.lgc.NewHostLog(srvData.ctx, srvData.ownerID)
+		if err := audit.WithCurrent(srvData.ctx, hostID, nil); err != nil {
+			blog.Errorf("restore host trash, but get host %d detail for audit failed, err: %v, rid: %s", hostID, err, srvData.rid)
+			continue
+		}
+		auditLog, err := audit.AuditLog(srvData.ctx, hostID, appID, meta.AuditCreate)
+		if err != nil {
+			blog.Errorf("restore host trash, but generate host %d audit log failed, err: %v, rid: %s", hostID, err, srvData.rid)
+			continue
+		}
+		logs = append(logs, auditLog)
+	}
+	if len(logs) > 0 {
+		if _, err := s.CoreAPI.CoreService().Audit().SaveAuditLog(srvData.ctx, srvData.header, logs...); err != nil {
+			blog.Errorf("restore host trash, but save audit log failed, err: %v, rid: %s", err, srvData.rid)
+		}
+	}
+
+	_ = resp.WriteEntity(meta.NewSuccessResp(result))
+}
+
+// PurgeHostTrash remove the hosts from the trash bin permanently
+func (s *Service) PurgeHostTrash(req *restful.Request, resp *restful.Response) {
+	srvData := s.newSrvComm(req.Request.Header)
+	option := meta.RestoreTrashOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("purge host trash failed with decode body err: %v, rid: %s", err, srvData.rid)
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if field, err := option.Validate(); err != nil {
+		blog.Errorf("purge host trash failed, option invalid, field: %s, err: %v, rid: %s", field, err, srvData.rid)
+		_ = resp.WriteError(http.StatusBadRequest, &meta.RespError{Msg: srvData.ccErr.Errorf(common.CCErrCommParamsInvalid, field)})
+		return
+	}
+
+	if err := s.CoreAPI.CoreService().Trash().DeleteTrash(srvData.ctx, srvData.header, common.BKInnerObjIDHost, option); err != nil {
+		blog.Errorf("purge host trash failed, ids: %v, err: %v, rid: %s", option.IDs, err, srvData.rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &meta.RespError{Msg: err})
+		return
+	}
+
+	_ = resp.WriteEntity(meta.NewSuccessResp(nil))
+}
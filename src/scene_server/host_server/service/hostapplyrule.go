@@ -193,8 +193,8 @@ func (s *Service) ListHostApplyRule(req *restful.Request, resp *restful.Response
 		return
 	}
 
-	if len(option.ModuleIDs) == 0 {
-		blog.Errorf("ListHostApplyRule failed, parameter bk_module_ids empty, rid:%s", err, rid)
+	if len(option.ModuleIDs) == 0 && len(option.SetIDs) == 0 && len(option.SetTemplateIDs) == 0 {
+		blog.Errorf("ListHostApplyRule failed, parameter bk_module_ids, bk_set_ids and set_template_ids are all empty, rid:%s", rid)
 		result := &metadata.RespError{Msg: srvData.ccErr.Errorf(common.CCErrCommParamsInvalid, "bk_module_ids")}
 		_ = resp.WriteError(http.StatusBadRequest, result)
 		return
@@ -704,3 +704,36 @@ func (s *Service) listHostRelatedApplyRule(srvData *srvComm, bizID int64, option
 	}
 	return result, nil
 }
+
+// ListHostApplyDrift list the hosts' attributes which drift from the host apply rules in the business
+func (s *Service) ListHostApplyDrift(req *restful.Request, resp *restful.Response) {
+	srvData := s.newSrvComm(req.Request.Header)
+	rid := srvData.rid
+
+	bizIDStr := req.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		blog.Errorf("ListHostApplyDrift failed, parse biz id failed, bizIDStr: %s, err: %v,rid:%s", bizIDStr, err, rid)
+		result := &metadata.RespError{Msg: srvData.ccErr.Errorf(common.CCErrCommParamsInvalid, common.BKAppIDField)}
+		_ = resp.WriteError(http.StatusBadRequest, result)
+		return
+	}
+
+	option := metadata.ListHostApplyDriftOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("ListHostApplyDrift failed, decode request body failed, err: %v,rid:%s", err, rid)
+		result := &metadata.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)}
+		_ = resp.WriteError(http.StatusBadRequest, result)
+		return
+	}
+
+	driftResult, err := s.CoreAPI.CoreService().HostApplyRule().ListHostApplyDrift(srvData.ctx, srvData.header, bizID, option)
+	if err != nil {
+		blog.ErrorJSON("ListHostApplyDrift failed, core service ListHostApplyDrift failed, bizID: %s, option: %s, err: %s, rid: %s", bizID, option, err.Error(), rid)
+		result := &metadata.RespError{Msg: err}
+		_ = resp.WriteError(http.StatusBadRequest, result)
+		return
+	}
+
+	_ = resp.WriteEntity(metadata.NewSuccessResp(driftResult))
+}
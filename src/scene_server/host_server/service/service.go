@@ -40,6 +40,7 @@ type Service struct {
 	disc        discovery.DiscoveryInterface
 	CacheDB     *redis.Client
 	AuthManager *extensions.AuthManager
+	EnableTxn   bool
 }
 
 type srvComm struct {
@@ -100,6 +101,11 @@ func (s *Service) WebService() *restful.Container {
 	api.Route(api.DELETE("/hosts/favorites/{id}").To(s.DeleteHostFavouriteByID))
 	api.Route(api.PUT("/hosts/favorites/{id}/incr").To(s.IncrHostFavouritesCount))
 
+	// host trash bin
+	api.Route(api.POST("/hosts/trash/search").To(s.ListHostTrash))
+	api.Route(api.POST("/hosts/trash/restore").To(s.RestoreHostTrash))
+	api.Route(api.DELETE("/hosts/trash").To(s.PurgeHostTrash))
+
 	api.Route(api.POST("/hosts/modules").To(s.TransferHostModule))
 	api.Route(api.POST("/hosts/modules/idle").To(s.MoveHost2IdleModule))
 	api.Route(api.POST("/hosts/modules/fault").To(s.MoveHost2FaultModule))
@@ -150,6 +156,7 @@ func (s *Service) WebService() *restful.Container {
 	api.Route(api.POST("/create/cloudarea").To(s.CreatePlat))
 	api.Route(api.PUT("/update/cloudarea/{bk_cloud_id}").To(s.UpdatePlat))
 	api.Route(api.DELETE("/delete/cloudarea/{bk_cloud_id}").To(s.DelPlat))
+	api.Route(api.POST("/findmany/cloudarea/status").To(s.FindCloudAreaStatus))
 
 	// first install use api
 	api.Route(api.POST("/host/install/bk").To(s.BKSystemInstall))
@@ -166,6 +173,7 @@ func (s *Service) WebService() *restful.Container {
 	api.Route(api.POST("/createmany/host_apply_plan/bk_biz_id/{bk_biz_id}/preview").To(s.GenerateApplyPlan))
 	api.Route(api.POST("/updatemany/host_apply_plan/bk_biz_id/{bk_biz_id}/run").To(s.RunHostApplyRule))
 	api.Route(api.POST("/findmany/host_apply_rule/bk_biz_id/{bk_biz_id}/host_related_rules").To(s.ListHostRelatedApplyRule))
+	api.Route(api.POST("/findmany/host_apply_drift/bk_biz_id/{bk_biz_id}").To(s.ListHostApplyDrift))
 
 	api.Route(api.PUT("/hosts/update").To(s.UpdateImportHosts))
 	container.Add(api)
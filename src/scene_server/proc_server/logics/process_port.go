@@ -0,0 +1,416 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+const (
+	minPort = 1
+	maxPort = 65535
+)
+
+type portRange struct {
+	start int
+	end   int
+}
+
+// parsePorts parse the process's port such as 80,8000-8010 into port ranges.
+func parsePorts(port string) ([]portRange, error) {
+	ranges := make([]portRange, 0)
+	for _, item := range strings.Split(port, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+
+		bounds := strings.SplitN(item, "-", 2)
+		start, err := parsePort(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if len(bounds) == 2 {
+			if end, err = parsePort(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+		if start > end {
+			return nil, fmt.Errorf("port range %s start is greater than end", item)
+		}
+		ranges = append(ranges, portRange{start: start, end: end})
+	}
+	return ranges, nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("port %s is not a number", s)
+	}
+	if port < minPort || port > maxPort {
+		return 0, fmt.Errorf("port %d out of range [%d, %d]", port, minPort, maxPort)
+	}
+	return port, nil
+}
+
+// overlapPorts returns the ports both in a and b, the adjacent and overlapped ranges are merged.
+func overlapPorts(a, b []portRange) []portRange {
+	overlaps := make([]portRange, 0)
+	for _, x := range a {
+		for _, y := range b {
+			start, end := x.start, x.end
+			if y.start > start {
+				start = y.start
+			}
+			if y.end < end {
+				end = y.end
+			}
+			if start <= end {
+				overlaps = append(overlaps, portRange{start: start, end: end})
+			}
+		}
+	}
+	if len(overlaps) == 0 {
+		return overlaps
+	}
+
+	sort.Slice(overlaps, func(i, j int) bool { return overlaps[i].start < overlaps[j].start })
+	merged := []portRange{overlaps[0]}
+	for _, r := range overlaps[1:] {
+		last := &merged[len(merged)-1]
+		if r.start <= last.end+1 {
+			if r.end > last.end {
+				last.end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func formatPorts(ranges []portRange) string {
+	items := make([]string, 0)
+	for _, r := range ranges {
+		if r.start == r.end {
+			items = append(items, strconv.Itoa(r.start))
+		} else {
+			items = append(items, fmt.Sprintf("%d-%d", r.start, r.end))
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// isAnyIP check if the bind ip listens on all the addresses, empty bind ip is regarded as so, because
+// which address the process listens on is unknown.
+func isAnyIP(ip string) bool {
+	return ip == "" || ip == "0.0.0.0" || ip == "::"
+}
+
+func bindIPOverlap(a, b string) bool {
+	return a == b || isAnyIP(a) || isAnyIP(b)
+}
+
+// protocolOverlap check if the protocols may conflict, empty protocol may be any of them.
+func protocolOverlap(a, b metadata.ProtocolType) bool {
+	return a == b || len(a) == 0 || len(b) == 0
+}
+
+type hostProcess struct {
+	metadata.HostProcessInstance
+	name  string
+	ports []portRange
+}
+
+// findPortConflicts find the processes on the same host listening on overlapped ports with the same protocol and
+// bind ip, only the conflicts involving the changed processes are returned, all the conflicts are returned if
+// changed is nil.
+func findPortConflicts(processes []hostProcess, changed map[int64]bool) []metadata.ProcessPortConflict {
+	hostProcesses := make(map[int64][]hostProcess)
+	hostIDs := make([]int64, 0)
+	for _, process := range processes {
+		if _, exist := hostProcesses[process.HostID]; !exist {
+			hostIDs = append(hostIDs, process.HostID)
+		}
+		hostProcesses[process.HostID] = append(hostProcesses[process.HostID], process)
+	}
+
+	conflicts := make([]metadata.ProcessPortConflict, 0)
+	for _, hostID := range hostIDs {
+		procs := hostProcesses[hostID]
+		for i := 0; i < len(procs); i++ {
+			for j := i + 1; j < len(procs); j++ {
+				p, q := procs[i], procs[j]
+				if changed != nil && !changed[p.ProcessID] && !changed[q.ProcessID] {
+					continue
+				}
+				if !protocolOverlap(p.Protocol, q.Protocol) || !bindIPOverlap(p.BindIP, q.BindIP) {
+					continue
+				}
+				overlaps := overlapPorts(p.ports, q.ports)
+				if len(overlaps) == 0 {
+					continue
+				}
+				// report the changed process as the conflicted one
+				if changed != nil && !changed[p.ProcessID] {
+					p, q = q, p
+				}
+				conflicts = append(conflicts, metadata.ProcessPortConflict{
+					HostID:              hostID,
+					ProcessID:           p.ProcessID,
+					ProcessName:         p.name,
+					ConflictProcessID:   q.ProcessID,
+					ConflictProcessName: q.name,
+					BindIP:              p.BindIP,
+					Protocol:            p.Protocol,
+					Ports:               formatPorts(overlaps),
+				})
+			}
+		}
+	}
+	return conflicts
+}
+
+func inPortRanges(port int, ranges []portRange) bool {
+	for _, r := range ranges {
+		if port >= r.start && port <= r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// findFreePorts returns at most count ports in the candidate ranges which are not used by the processes
+// listening on the bind ip and protocol, the ports are returned in ascending order.
+func findFreePorts(processes []hostProcess, bindIP string, protocol metadata.ProtocolType, candidates []portRange,
+	count int) []int {
+
+	used := make([]portRange, 0)
+	for _, process := range processes {
+		if protocolOverlap(process.Protocol, protocol) && bindIPOverlap(process.BindIP, bindIP) {
+			used = append(used, process.ports...)
+		}
+	}
+	// merge the candidate ranges so that the ports are returned in order and only once
+	candidates = overlapPorts(candidates, []portRange{{start: minPort, end: maxPort}})
+
+	ports := make([]int, 0)
+	for _, candidate := range candidates {
+		for port := candidate.start; port <= candidate.end && len(ports) < count; port++ {
+			if !inPortRanges(port, used) {
+				ports = append(ports, port)
+			}
+		}
+	}
+	return ports
+}
+
+// listHostProcesses list the processes with ports on the hosts, the processes whose port is invalid are skipped.
+func (lgc *Logic) listHostProcesses(kit *rest.Kit, bizID int64, hostIDs []int64) ([]hostProcess, errors.CCErrorCoder) {
+	option := &metadata.ListProcessInstancesWithHostOption{
+		BizID:   bizID,
+		HostIDs: hostIDs,
+		Page:    metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	relations, err := lgc.CoreAPI.CoreService().Process().ListHostProcessRelation(kit.Ctx, kit.Header, option)
+	if err != nil {
+		blog.ErrorJSON("list host process relation failed, option: %s, err: %s, rid: %s", option, err, kit.Rid)
+		return nil, err
+	}
+	if len(relations.Info) == 0 {
+		return make([]hostProcess, 0), nil
+	}
+
+	processIDs := make([]int64, 0)
+	for _, relation := range relations.Info {
+		processIDs = append(processIDs, relation.ProcessID)
+	}
+	reqParam := &metadata.QueryCondition{
+		Fields: []string{common.BKProcessIDField, common.BKProcessNameField, common.BKPort, common.BKBindIP, common.BKProtocol},
+		Condition: map[string]interface{}{
+			common.BKProcessIDField: map[string]interface{}{
+				common.BKDBIN: processIDs,
+			},
+		},
+	}
+	ret, e := lgc.CoreAPI.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, common.BKInnerObjIDProc, reqParam)
+	if e != nil {
+		blog.Errorf("list process instance failed, processIDs: %v, err: %v, rid: %s", processIDs, e, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !ret.Result {
+		blog.Errorf("list process instance failed, processIDs: %v, err: %s, rid: %s", processIDs, ret.ErrMsg, kit.Rid)
+		return nil, errors.New(ret.Code, ret.ErrMsg)
+	}
+
+	processMap := make(map[int64]hostProcess)
+	for _, process := range ret.Data.Info {
+		processID, err := util.GetInt64ByInterface(process[common.BKProcessIDField])
+		if err != nil {
+			blog.ErrorJSON("parse process id failed, process: %s, err: %s, rid: %s", process, err, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKProcessIDField)
+		}
+		port := util.GetStrByInterface(process[common.BKPort])
+		if len(port) == 0 {
+			continue
+		}
+		ports, err := parsePorts(port)
+		if err != nil {
+			blog.Warnf("skip process %d with invalid port %s, err: %v, rid: %s", processID, port, err, kit.Rid)
+			continue
+		}
+		processMap[processID] = hostProcess{
+			HostProcessInstance: metadata.HostProcessInstance{
+				ProcessID: processID,
+				BindIP:    util.GetStrByInterface(process[common.BKBindIP]),
+				Port:      port,
+				Protocol:  metadata.ProtocolType(util.GetStrByInterface(process[common.BKProtocol])),
+			},
+			name:  util.GetStrByInterface(process[common.BKProcessNameField]),
+			ports: ports,
+		}
+	}
+
+	processes := make([]hostProcess, 0)
+	for _, relation := range relations.Info {
+		process, exist := processMap[relation.ProcessID]
+		if !exist {
+			continue
+		}
+		process.HostID = relation.HostID
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// getPortConflictPolicy get the business's port conflict policy, the default policy is warn.
+func (lgc *Logic) getPortConflictPolicy(kit *rest.Kit, bizID int64) (metadata.PortConflictPolicy, errors.CCErrorCoder) {
+	reqParam := &metadata.QueryCondition{
+		Fields:    []string{common.BKAppIDField, common.BKPortConflictPolicyField},
+		Condition: map[string]interface{}{common.BKAppIDField: bizID},
+	}
+	ret, err := lgc.CoreAPI.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, common.BKInnerObjIDApp, reqParam)
+	if err != nil {
+		blog.Errorf("get business %d failed, err: %v, rid: %s", bizID, err, kit.Rid)
+		return "", kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !ret.Result {
+		blog.Errorf("get business %d failed, err: %s, rid: %s", bizID, ret.ErrMsg, kit.Rid)
+		return "", errors.New(ret.Code, ret.ErrMsg)
+	}
+	if len(ret.Data.Info) == 0 {
+		blog.Errorf("business %d not found, rid: %s", bizID, kit.Rid)
+		return "", kit.CCError.CCErrorf(common.CCErrCommNotFound)
+	}
+
+	policy := metadata.PortConflictPolicy(util.GetStrByInterface(ret.Data.Info[0][common.BKPortConflictPolicyField]))
+	if policy != metadata.PortConflictPolicyReject {
+		policy = metadata.PortConflictPolicyWarn
+	}
+	return policy, nil
+}
+
+// FindProcessPortConflicts find the port conflicts of the processes on the hosts, only the conflicts involving
+// the processes are returned, or all the conflicts on the hosts if processIDs is nil.
+func (lgc *Logic) FindProcessPortConflicts(kit *rest.Kit, bizID int64, hostIDs []int64, processIDs []int64) (
+	[]metadata.ProcessPortConflict, errors.CCErrorCoder) {
+
+	processes, err := lgc.listHostProcesses(kit, bizID, hostIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed map[int64]bool
+	if processIDs != nil {
+		changed = make(map[int64]bool)
+		for _, processID := range processIDs {
+			changed[processID] = true
+		}
+	}
+	return findPortConflicts(processes, changed), nil
+}
+
+// ValidateProcessPortConflict check if the changed processes' ports conflict with other processes on the same
+// host after they are saved, the change is rejected or the conflicts are returned as warnings according to the
+// business's port conflict policy. It should be called in the transaction which saves the processes.
+func (lgc *Logic) ValidateProcessPortConflict(kit *rest.Kit, bizID int64, hostIDs []int64, processIDs []int64) (
+	[]metadata.ProcessPortConflict, errors.CCErrorCoder) {
+
+	if len(hostIDs) == 0 || len(processIDs) == 0 {
+		return make([]metadata.ProcessPortConflict, 0), nil
+	}
+
+	conflicts, err := lgc.FindProcessPortConflicts(kit, bizID, util.IntArrayUnique(hostIDs), processIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) == 0 {
+		return conflicts, nil
+	}
+
+	policy, err := lgc.getPortConflictPolicy(kit, bizID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]string, 0)
+	for _, c := range conflicts {
+		details = append(details, fmt.Sprintf("%s(%d) and %s(%d) on host %d port %s", c.ProcessName, c.ProcessID,
+			c.ConflictProcessName, c.ConflictProcessID, c.HostID, c.Ports))
+	}
+	if policy == metadata.PortConflictPolicyReject {
+		blog.Errorf("process port conflicts, bizID: %d, conflicts: %v, rid: %s", bizID, details, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrProcPortConflict, strings.Join(details, "; "))
+	}
+	blog.Warnf("process port conflicts, bizID: %d, conflicts: %v, rid: %s", bizID, details, kit.Rid)
+	return conflicts, nil
+}
+
+// FindFreePorts find the ports on the host in the range which are not used by the processes with the bind ip
+// and protocol.
+func (lgc *Logic) FindFreePorts(kit *rest.Kit, option *metadata.FindFreePortOption) ([]int, errors.CCErrorCoder) {
+	candidates, e := parsePorts(option.PortRange)
+	if e != nil {
+		blog.Errorf("parse port range %s failed, err: %v, rid: %s", option.PortRange, e, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrProcPortFormatInvalid, option.PortRange)
+	}
+
+	processes, err := lgc.listHostProcesses(kit, option.BizID, []int64{option.HostID})
+	if err != nil {
+		return nil, err
+	}
+
+	count := option.Count
+	if count == 0 {
+		count = 1
+	}
+	ports := findFreePorts(processes, option.BindIP, option.Protocol, candidates, count)
+	if len(ports) < count {
+		blog.Errorf("host %d has only %d free ports in range %s, need %d, rid: %s", option.HostID, len(ports),
+			option.PortRange, count, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrProcNoFreePort, option.HostID, option.PortRange)
+	}
+	return ports, nil
+}
@@ -0,0 +1,109 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"reflect"
+	"testing"
+
+	"configcenter/src/common/metadata"
+)
+
+func TestParsePorts(t *testing.T) {
+	ranges, err := parsePorts("80, 8000-8010,443")
+	if err != nil {
+		t.Fatalf("parse ports failed, err: %v", err)
+	}
+	expected := []portRange{{80, 80}, {8000, 8010}, {443, 443}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Fatalf("expect %v, got %v", expected, ranges)
+	}
+
+	for _, port := range []string{"0", "65536", "abc", "9000-8000", "80-"} {
+		if _, err := parsePorts(port); err == nil {
+			t.Errorf("port %s should be invalid", port)
+		}
+	}
+}
+
+func TestOverlapPorts(t *testing.T) {
+	a := []portRange{{8000, 8010}, {9000, 9000}}
+	b := []portRange{{8005, 8020}, {8000, 8001}, {9001, 9002}}
+	overlaps := overlapPorts(a, b)
+	if formatPorts(overlaps) != "8000-8001,8005-8010" {
+		t.Fatalf("unexpected overlaps %s", formatPorts(overlaps))
+	}
+	if len(overlapPorts(a, []portRange{{1, 79}})) != 0 {
+		t.Fatalf("ranges should not overlap")
+	}
+}
+
+func newHostProcess(t *testing.T, hostID, processID int64, bindIP, port string, protocol metadata.ProtocolType) hostProcess {
+	ports, err := parsePorts(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hostProcess{
+		HostProcessInstance: metadata.HostProcessInstance{
+			HostID: hostID, ProcessID: processID, BindIP: bindIP, Port: port, Protocol: protocol,
+		},
+		ports: ports,
+	}
+}
+
+func TestFindPortConflicts(t *testing.T) {
+	processes := []hostProcess{
+		newHostProcess(t, 1, 1, "127.0.0.1", "8000-8010", metadata.ProtocolTypeTCP),
+		// same port with different protocol
+		newHostProcess(t, 1, 2, "127.0.0.1", "8000", metadata.ProtocolTypeUDP),
+		// same port with different bind ip
+		newHostProcess(t, 1, 3, "10.0.0.1", "8005", metadata.ProtocolTypeTCP),
+		// listen on all addresses
+		newHostProcess(t, 1, 4, "0.0.0.0", "8010,9000", metadata.ProtocolTypeTCP),
+		// same port on another host
+		newHostProcess(t, 2, 5, "127.0.0.1", "8000", metadata.ProtocolTypeTCP),
+	}
+
+	conflicts := findPortConflicts(processes, nil)
+	if len(conflicts) != 1 {
+		t.Fatalf("expect 1 conflict, got %+v", conflicts)
+	}
+	if conflicts[0].ProcessID != 1 || conflicts[0].ConflictProcessID != 4 || conflicts[0].Ports != "8010" {
+		t.Errorf("unexpected conflict %+v", conflicts[0])
+	}
+
+	conflicts = findPortConflicts(processes, map[int64]bool{4: true})
+	if len(conflicts) != 1 || conflicts[0].ProcessID != 4 || conflicts[0].ConflictProcessID != 1 {
+		t.Errorf("changed process should be reported as the conflicted one, got %+v", conflicts)
+	}
+	if len(findPortConflicts(processes, map[int64]bool{2: true, 5: true})) != 0 {
+		t.Errorf("process 2 and 5 should have no conflicts")
+	}
+}
+
+func TestFindFreePorts(t *testing.T) {
+	processes := []hostProcess{
+		newHostProcess(t, 1, 1, "127.0.0.1", "8000-8002", metadata.ProtocolTypeTCP),
+		newHostProcess(t, 1, 2, "0.0.0.0", "8004", ""),
+		newHostProcess(t, 1, 3, "10.0.0.1", "8003", metadata.ProtocolTypeTCP),
+	}
+
+	ports := findFreePorts(processes, "127.0.0.1", metadata.ProtocolTypeTCP, []portRange{{8000, 8010}}, 3)
+	if !reflect.DeepEqual(ports, []int{8003, 8005, 8006}) {
+		t.Fatalf("unexpected free ports %v", ports)
+	}
+	ports = findFreePorts(processes, "", metadata.ProtocolTypeUDP, []portRange{{8003, 8005}}, 5)
+	if !reflect.DeepEqual(ports, []int{8003, 8005}) {
+		t.Fatalf("unexpected free ports %v", ports)
+	}
+}
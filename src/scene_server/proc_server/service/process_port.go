@@ -0,0 +1,72 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// CheckProcessPortConflict returns all the port conflicts between the processes on the hosts.
+func (ps *ProcServer) CheckProcessPortConflict(ctx *rest.Contexts) {
+	input := new(metadata.CheckProcessPortConflictOption)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if field, err := input.Validate(); err != nil {
+		blog.Errorf("check process port conflict failed, option invalid, field: %s, err: %v, rid: %s", field, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field))
+		return
+	}
+
+	if err := ps.CheckHostInBusiness(ctx, input.BizID, input.HostIDs); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	conflicts, err := ps.Logic.FindProcessPortConflicts(ctx.Kit, input.BizID, input.HostIDs, nil)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntityWithCount(int64(len(conflicts)), conflicts)
+}
+
+// FindFreePort find the ports in the range which are not used by the processes on the host.
+func (ps *ProcServer) FindFreePort(ctx *rest.Contexts) {
+	input := new(metadata.FindFreePortOption)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if field, err := input.Validate(); err != nil {
+		blog.Errorf("find free port failed, option invalid, field: %s, err: %v, rid: %s", field, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field))
+		return
+	}
+
+	if err := ps.CheckHostInBusiness(ctx, input.BizID, []int64{input.HostID}); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ports, err := ps.Logic.FindFreePorts(ctx.Kit, input)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(metadata.FindFreePortResult{Ports: ports})
+}
@@ -36,11 +36,15 @@ func (ps *ProcServer) CreateProcessInstances(ctx *rest.Contexts) {
 	}
 
 	var processIDs []int64
+	var conflicts []metadata.ProcessPortConflict
 	txnErr := ps.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ps.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
-		processIDs, err = ps.createProcessInstances(ctx, input)
+		processIDs, conflicts, err = ps.createProcessInstances(ctx, input)
 		if err != nil {
 			blog.Errorf("create process instance failed, serviceInstanceID: %d, input: %+v, err: %+v", input.ServiceInstanceID, input, err)
+			if ccErr, ok := err.(errors.CCErrorCoder); ok && ccErr.GetCode() == common.CCErrProcPortConflict {
+				return err
+			}
 			return ctx.Kit.CCError.CCError(common.CCErrProcCreateProcessFailed)
 		}
 
@@ -55,32 +59,35 @@ func (ps *ProcServer) CreateProcessInstances(ctx *rest.Contexts) {
 		ctx.RespAutoError(txnErr)
 		return
 	}
-	ctx.RespEntity(processIDs)
+	ctx.RespBody(metadata.ProcessPortConflictResponse{Response: *metadata.NewSuccessResp(processIDs),
+		PortConflicts: conflicts})
 }
 
-func (ps *ProcServer) createProcessInstances(ctx *rest.Contexts, input *metadata.CreateRawProcessInstanceInput) ([]int64, errors.CCErrorCoder) {
+func (ps *ProcServer) createProcessInstances(ctx *rest.Contexts, input *metadata.CreateRawProcessInstanceInput) (
+	[]int64, []metadata.ProcessPortConflict, errors.CCErrorCoder) {
+
 	bizID := input.BizID
 	if bizID == 0 && input.Metadata != nil {
 		var e error
 		bizID, e = metadata.BizIDFromMetadata(*input.Metadata)
 		if e != nil {
 			blog.Errorf("create process instance with raw, parse biz id from metadata failed, err: %+v, rid: %s", e, ctx.Kit.Rid)
-			return nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommHTTPInputInvalid, common.MetadataField)
+			return nil, nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommHTTPInputInvalid, common.MetadataField)
 		}
 	}
 
 	serviceInstance, err := ps.CoreAPI.CoreService().Process().GetServiceInstance(ctx.Kit.Ctx, ctx.Kit.Header, input.ServiceInstanceID)
 	if err != nil {
 		blog.Errorf("create process instance failed, get service instance by id failed, serviceInstanceID: %d, err: %v, rid: %s", input.ServiceInstanceID, err, ctx.Kit.Rid)
-		return nil, err
+		return nil, nil, err
 	}
 	if serviceInstance.BizID != bizID {
 		blog.Errorf("create process instance with raw, biz id from input not equal with service instance, rid: %s", ctx.Kit.Rid)
-		return nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.MetadataField)
+		return nil, nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.MetadataField)
 	}
 	if serviceInstance.ServiceTemplateID != common.ServiceTemplateIDNotSet {
 		blog.Errorf("create process instance failed, create process instance on service instance initialized by template forbidden, serviceInstanceID: %d, err: %v, rid: %s", input.ServiceInstanceID, err, ctx.Kit.Rid)
-		return nil, ctx.Kit.CCError.CCError(common.CCErrProcEditProcessInstanceCreateByTemplateForbidden)
+		return nil, nil, ctx.Kit.CCError.CCError(common.CCErrProcEditProcessInstanceCreateByTemplateForbidden)
 	}
 
 	processIDs := make([]int64, 0)
@@ -93,13 +100,13 @@ func (ps *ProcServer) createProcessInstances(ctx *rest.Contexts, input *metadata
 		item.ProcessData[common.LastTimeField] = now
 
 		if err := ps.validateRawInstanceUnique(ctx, serviceInstance.ID, item.ProcessData); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		processID, err := ps.Logic.CreateProcessInstance(ctx.Kit, item.ProcessData)
 		if err != nil {
 			blog.Errorf("create process instance failed, create process failed, serviceInstanceID: %d, process: %+v, err: %v, rid: %s", input.ServiceInstanceID, item, err, ctx.Kit.Rid)
-			return nil, err
+			return nil, nil, err
 		}
 
 		relation := &metadata.ProcessInstanceRelation{
@@ -113,12 +120,18 @@ func (ps *ProcServer) createProcessInstances(ctx *rest.Contexts, input *metadata
 		_, err = ps.CoreAPI.CoreService().Process().CreateProcessInstanceRelation(ctx.Kit.Ctx, ctx.Kit.Header, relation)
 		if err != nil {
 			blog.Errorf("create service instance relations, create process instance relation failed, serviceInstanceID: %d, relation: %+v, err: %v, rid: %s", input.ServiceInstanceID, relation, err, ctx.Kit.Rid)
-			return nil, err
+			return nil, nil, err
 		}
 		processIDs = append(processIDs, processID)
 	}
 
-	return processIDs, nil
+	conflicts, err := ps.Logic.ValidateProcessPortConflict(ctx.Kit, bizID, []int64{serviceInstance.HostID}, processIDs)
+	if err != nil {
+		blog.Errorf("create process instance failed, validate port conflict failed, serviceInstanceID: %d, err: %v, rid: %s", input.ServiceInstanceID, err, ctx.Kit.Rid)
+		return nil, nil, err
+	}
+
+	return processIDs, conflicts, nil
 }
 
 func (ps *ProcServer) UpdateProcessInstances(ctx *rest.Contexts) {
@@ -129,9 +142,10 @@ func (ps *ProcServer) UpdateProcessInstances(ctx *rest.Contexts) {
 	}
 
 	var result []int64
+	var conflicts []metadata.ProcessPortConflict
 	txnErr := ps.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ps.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
-		result, err = ps.updateProcessInstances(ctx, input)
+		result, conflicts, err = ps.updateProcessInstances(ctx, input)
 		if err != nil {
 			return err
 		}
@@ -142,10 +156,13 @@ func (ps *ProcServer) UpdateProcessInstances(ctx *rest.Contexts) {
 		ctx.RespAutoError(txnErr)
 		return
 	}
-	ctx.RespEntity(result)
+	ctx.RespBody(metadata.ProcessPortConflictResponse{Response: *metadata.NewSuccessResp(result),
+		PortConflicts: conflicts})
 }
 
-func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.UpdateRawProcessInstanceInput) ([]int64, errors.CCErrorCoder) {
+func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.UpdateRawProcessInstanceInput) (
+	[]int64, []metadata.ProcessPortConflict, errors.CCErrorCoder) {
+
 	rid := ctx.Kit.Rid
 	bizID := input.BizID
 	if bizID == 0 && input.Metadata != nil {
@@ -153,7 +170,7 @@ func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.
 		bizID, err = metadata.BizIDFromMetadata(*input.Metadata)
 		if err != nil {
 			blog.Errorf("update process instance failed, parse business id failed, err: %+v, rid: %s", err, rid)
-			return nil, ctx.Kit.CCError.CCError(common.CCErrCommHTTPInputInvalid)
+			return nil, nil, ctx.Kit.CCError.CCError(common.CCErrCommHTTPInputInvalid)
 		}
 	}
 	processIDs := make([]int64, 0)
@@ -162,13 +179,13 @@ func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.
 		process := metadata.Process{}
 		if err := mapstr.DecodeFromMapStr(&process, pData); err != nil {
 			blog.ErrorJSON("update process instance failed, unmarshal request body failed, data: %s, err: %s, rid: %s", pData, err.Error(), rid)
-			return nil, ctx.Kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+			return nil, nil, ctx.Kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
 		}
 		input.Processes = append(input.Processes, process)
 
 		if process.ProcessID == 0 {
 			blog.Errorf("update process instance failed, process_id invalid, rid: %s", rid)
-			return nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKProcessIDField)
+			return nil, nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKProcessIDField)
 		}
 		processIDs = append(processIDs, process.ProcessID)
 	}
@@ -181,15 +198,17 @@ func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.
 	relations, err := ps.CoreAPI.CoreService().Process().ListProcessInstanceRelation(ctx.Kit.Ctx, ctx.Kit.Header, option)
 	if err != nil {
 		blog.ErrorJSON("update process instance failed, search process instance relation failed, option: %s, err: %+v, rid: %s", option, err, rid)
-		return nil, err
+		return nil, nil, err
 	}
 
 	// make sure all process valid
 	foundProcessIDs := make([]int64, 0)
 	serviceInstanceIDs := make([]int64, 0)
+	hostIDs := make([]int64, 0)
 	for _, relation := range relations.Info {
 		foundProcessIDs = append(foundProcessIDs, relation.ProcessID)
 		serviceInstanceIDs = append(serviceInstanceIDs, relation.ServiceInstanceID)
+		hostIDs = append(hostIDs, relation.HostID)
 	}
 	invalidProcessIDs := make([]string, 0)
 	for _, processID := range processIDs {
@@ -201,7 +220,7 @@ func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.
 		blog.Errorf("update process instance failed, process %+v not found", invalidProcessIDs)
 		msg := fmt.Sprintf("[%s: %s]", common.BKProcessIDField, strings.Join(invalidProcessIDs, ","))
 		err := ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, msg)
-		return nil, err
+		return nil, nil, err
 	}
 
 	processTemplateMap := make(map[int64]*metadata.ProcessTemplate)
@@ -215,7 +234,7 @@ func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.
 		processTemplate, err := ps.CoreAPI.CoreService().Process().GetProcessTemplate(ctx.Kit.Ctx, ctx.Kit.Header, relation.ProcessTemplateID)
 		if err != nil {
 			blog.ErrorJSON("update process instance failed, get process template failed, processTemplateID: %d, err: %s, rid: %s", relation.ProcessTemplateID, err, rid)
-			return nil, err
+			return nil, nil, err
 		}
 		processTemplateMap[relation.ProcessTemplateID] = processTemplate
 	}
@@ -241,7 +260,7 @@ func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.
 		if !exist {
 			err := ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKProcessIDField)
 			blog.ErrorJSON("update process instance failed, process related service instance not found, process: %s, err: %s, rid: %s", process, err, rid)
-			return nil, err
+			return nil, nil, err
 		}
 
 		var processData map[string]interface{}
@@ -252,11 +271,11 @@ func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.
 			processData, err = mapstruct.Struct2Map(process)
 			if nil != err {
 				blog.Errorf("UpdateProcessInstances failed, json Unmarshal process failed, processData: %s, err: %+v, rid: %s", processData, err, ctx.Kit.Rid)
-				return nil, ctx.Kit.CCError.CCError(common.CCErrCommJsonDecode)
+				return nil, nil, ctx.Kit.CCError.CCError(common.CCErrCommJsonDecode)
 			}
 			if err := ps.validateRawInstanceUnique(ctx, serviceInstanceID, processData); err != nil {
 				blog.Errorf("update process instance failed, serviceInstanceID: %d, process: %+v, err: %v, rid: %s", serviceInstanceID, process, err, rid)
-				return nil, err
+				return nil, nil, err
 			}
 			delete(processData, common.BKProcessIDField)
 			delete(processData, common.MetadataField)
@@ -267,7 +286,7 @@ func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.
 			if !exist {
 				err := ctx.Kit.CCError.CCError(common.CCErrCommNotFound)
 				blog.Errorf("update process instance failed, process related template not found, relation: %+v, err: %v, rid: %s", relation, err, rid)
-				return nil, err
+				return nil, nil, err
 			}
 			processData = processTemplate.ExtractInstanceUpdateData(&process)
 			clearFields = processTemplate.GetEditableFields(clearFields)
@@ -279,19 +298,25 @@ func (ps *ProcServer) updateProcessInstances(ctx *rest.Contexts, input metadata.
 
 		if err := ps.Logic.UpdateProcessInstance(ctx.Kit, process.ProcessID, processData); err != nil {
 			blog.Errorf("update process failed, processID: %d, process: %+v, err: %v, rid: %s", process.ProcessID, process, err, rid)
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
+	conflicts, err := ps.Logic.ValidateProcessPortConflict(ctx.Kit, bizID, hostIDs, processIDs)
+	if err != nil {
+		blog.Errorf("update process instance failed, validate port conflict failed, processIDs: %v, err: %v, rid: %s", processIDs, err, rid)
+		return nil, nil, err
+	}
+
 	serviceInstanceIDs = util.IntArrayUnique(serviceInstanceIDs)
 	for _, svcInstanceID := range serviceInstanceIDs {
 		if err := ps.CoreAPI.CoreService().Process().ReconstructServiceInstanceName(ctx.Kit.Ctx, ctx.Kit.Header, svcInstanceID); err != nil {
 			blog.Errorf("update process instance failed, reconstruct service instance name failed, instanceID: %d, err: %s, rid: %s", svcInstanceID, err.Error(), rid)
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	return processIDs, nil
+	return processIDs, conflicts, nil
 }
 
 func (ps *ProcServer) CheckHostInBusiness(ctx *rest.Contexts, bizID int64, hostIDs []int64) errors.CCErrorCoder {
@@ -145,6 +145,9 @@ func (ps *ProcServer) newProcessService(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/deletemany/proc/service_instance/preview", Handler: ps.DeleteServiceInstancePreview})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/proc/service_instance/difference", Handler: ps.DiffServiceInstanceWithTemplate})
 	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/proc/service_instance/sync", Handler: ps.SyncServiceInstanceByTemplate})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/proc/service_instance/sync_task", Handler: ps.CreateSyncServiceInstanceTask})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/proc/service_instance/sync_task/{task_id}/bk_biz_id/{bk_biz_id}", Handler: ps.GetSyncServiceInstanceTask})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/proc/service_instance/sync_task/{task_id}/bk_biz_id/{bk_biz_id}/cancel", Handler: ps.CancelSyncServiceInstanceTask})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/proc/service_instance/labels", Handler: ps.ServiceInstanceAddLabels})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/proc/service_instance/labels", Handler: ps.ServiceInstanceRemoveLabels})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/proc/service_instance/labels/aggregation", Handler: ps.ServiceInstanceLabelsAggregation})
@@ -155,10 +158,15 @@ func (ps *ProcServer) newProcessService(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/proc/process_instance", Handler: ps.DeleteProcessInstance})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/proc/process_instance", Handler: ps.ListProcessInstances})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/proc/process_instance/with_host", Handler: ps.ListProcessInstancesWithHost})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/proc/process_instance/port_conflict", Handler: ps.CheckProcessPortConflict})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/proc/process_instance/free_port", Handler: ps.FindFreePort})
 
 	// module
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/proc/template_binding_on_module", Handler: ps.RemoveTemplateBindingOnModule})
 
+	// internal task, be careful: path has internal prefix shouldn't be route by api-server
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/internal/task/sync_service_instance", Handler: ps.SyncServiceInstanceTaskHandler})
+
 	utility.AddToRestfulWebService(web)
 }
 
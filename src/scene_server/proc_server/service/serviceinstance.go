@@ -117,7 +117,7 @@ func (ps *ProcServer) createServiceInstances(ctx *rest.Contexts, input metadata.
 					ServiceInstanceID: serviceInstance.ID,
 					Processes:         inst.Processes,
 				}
-				if _, err = ps.createProcessInstances(ctx, createProcessInput); err != nil {
+				if _, _, err = ps.createProcessInstances(ctx, createProcessInput); err != nil {
 					blog.ErrorJSON("createServiceInstances failed, createProcessInstances failed, input: %s, err: %s, rid: %s", createProcessInput, err.Error(), rid)
 					return nil, err
 				}
@@ -155,7 +155,7 @@ func (ps *ProcServer) createServiceInstances(ctx *rest.Contexts, input metadata.
 					BizID: bizID,
 					Raw:   processes,
 				}
-				_, err = ps.updateProcessInstances(ctx, input)
+				_, _, err = ps.updateProcessInstances(ctx, input)
 				if err != nil {
 					blog.ErrorJSON("CreateServiceInstances failed, updateProcessInstances failed, input: %s, err: %s, rid: %s", input, err.Error(), rid)
 					return nil, err
@@ -1070,8 +1070,10 @@ func (ps *ProcServer) SyncServiceInstanceByTemplate(ctx *rest.Contexts) {
 		return
 	}
 
+	var conflicts []metadata.ProcessPortConflict
 	txnErr := ps.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ps.EnableTxn, ctx.Kit.Header, func() error {
-		err := ps.syncServiceInstanceByTemplate(ctx, syncOption)
+		var err errors.CCErrorCoder
+		conflicts, err = ps.syncServiceInstanceByTemplate(ctx, syncOption)
 		if err != nil {
 			return err
 		}
@@ -1082,10 +1084,15 @@ func (ps *ProcServer) SyncServiceInstanceByTemplate(ctx *rest.Contexts) {
 		ctx.RespAutoError(txnErr)
 		return
 	}
-	ctx.RespEntity(make(map[string]interface{}))
+	ctx.RespBody(metadata.ProcessPortConflictResponse{Response: *metadata.NewSuccessResp(make(map[string]interface{})),
+		PortConflicts: conflicts})
 }
 
-func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOption metadata.SyncServiceInstanceByTemplateOption) errors.CCErrorCoder {
+// syncServiceInstanceByTemplate synchronize the service instances with the service template, returns the port
+// conflicts of the changed processes as warnings.
+func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOption metadata.SyncServiceInstanceByTemplateOption) (
+	[]metadata.ProcessPortConflict, errors.CCErrorCoder) {
+
 	rid := ctx.Kit.Rid
 
 	bizID := syncOption.BizID
@@ -1094,14 +1101,14 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 		bizID, err = metadata.BizIDFromMetadata(*syncOption.Metadata)
 		if err != nil {
 			blog.ErrorJSON("syncServiceInstanceByTemplate failed, parse bizID from metadata failed, metadata: %s, err: %s, rid: %s", syncOption.Metadata, err.Error(), rid)
-			return ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.MetadataField)
+			return nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.MetadataField)
 		}
 	}
 
 	modules, err := ps.getModules(ctx, syncOption.ModuleIDs)
 	if err != nil {
 		blog.Errorf("syncServiceInstanceByTemplate failed, getModule failed, moduleIDs: %+v, err: %s, rid: %s", syncOption.ModuleIDs, err.Error(), rid)
-		return err
+		return nil, err
 	}
 
 	// step 0:
@@ -1116,11 +1123,11 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 	serviceInstanceResult, err := ps.CoreAPI.CoreService().Process().ListServiceInstance(ctx.Kit.Ctx, ctx.Kit.Header, serviceInstanceOption)
 	if err != nil {
 		blog.ErrorJSON("syncServiceInstanceByTemplate failed, ListServiceInstance failed, option: %s, err: %s, rid: %s", serviceInstanceOption, err.Error(), rid)
-		return err
+		return nil, err
 	}
 	if serviceInstanceResult.Count == 0 {
 		blog.V(3).Infof("syncServiceInstanceByTemplate success, no service instance found, option: %+v, rid: %s", serviceInstanceOption, rid)
-		return nil
+		return make([]metadata.ProcessPortConflict, 0), nil
 	}
 	serviceInstanceIDs := make([]int64, 0)
 	for _, serviceInstance := range serviceInstanceResult.Info {
@@ -1142,7 +1149,7 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 	processTemplate, err := ps.CoreAPI.CoreService().Process().ListProcessTemplates(ctx.Kit.Ctx, ctx.Kit.Header, processTemplateFilter)
 	if err != nil {
 		blog.ErrorJSON("syncServiceInstanceByTemplate failed, ListProcessTemplates failed, option: %s, err: %s, rid: %s", processTemplateFilter, err.Error(), rid)
-		return err
+		return nil, err
 	}
 	processTemplateMap := make(map[int64]*metadata.ProcessTemplate)
 	for idx, t := range processTemplate.Info {
@@ -1158,7 +1165,7 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 	relations, err := ps.CoreAPI.CoreService().Process().ListProcessInstanceRelation(ctx.Kit.Ctx, ctx.Kit.Header, relationOption)
 	if err != nil {
 		blog.ErrorJSON("syncServiceInstanceByTemplate failed, ListProcessInstanceRelation failed, option: %s, err: %s, rid: %s", relationOption, err.Error(), rid)
-		return err
+		return nil, err
 	}
 	procIDs := make([]int64, 0)
 	for _, r := range relations.Info {
@@ -1170,7 +1177,7 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 	processInstances, err := ps.Logic.ListProcessInstanceWithIDs(ctx.Kit, procIDs)
 	if err != nil {
 		blog.ErrorJSON("syncServiceInstanceByTemplate failed, ListProcessInstanceWithIDs failed, procIDs: %s, err: %s, rid: %s", procIDs, err.Error(), rid)
-		return err
+		return nil, err
 	}
 	processInstanceMap := make(map[int64]*metadata.Process)
 	for idx, p := range processInstances {
@@ -1212,6 +1219,8 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 	// step 5:
 	// compare the difference between process instance and process template from one service instance to another.
 	removedProcessIDs := make([]int64, 0)
+	// the updated and created process, their ports are checked for conflicts at last
+	changedProcessIDs := make([]int64, 0)
 	for serviceInstanceID, processes := range serviceInstance2ProcessMap {
 		for _, process := range processes {
 			processTemplateID := processInstanceWithTemplateMap[process.ProcessID]
@@ -1235,22 +1244,23 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 			}
 			if err := ps.Logic.UpdateProcessInstance(ctx.Kit, process.ProcessID, proc); err != nil {
 				blog.Errorf("syncServiceInstanceByTemplate failed, UpdateProcessInstance failed, processID:%d, err: %s, rid:%s", process.ProcessID, err.Error(), rid)
-				return err
+				return nil, err
 			}
+			changedProcessIDs = append(changedProcessIDs, process.ProcessID)
 		}
 	}
 	// remove processes whose template has been removed
 	if len(removedProcessIDs) != 0 {
 		if err := ps.Logic.DeleteProcessInstanceBatch(ctx.Kit, removedProcessIDs); err != nil {
 			blog.Errorf("syncServiceInstanceByTemplate failed, DeleteProcessInstance failed, processID: %d, err: %s, rid: %s", removedProcessIDs, err.Error(), rid)
-			return err
+			return nil, err
 		}
 		// remove process instance relation now.
 		deleteOption := metadata.DeleteProcessInstanceRelationOption{}
 		deleteOption.ProcessIDs = removedProcessIDs
 		if err := ps.CoreAPI.CoreService().Process().DeleteProcessInstanceRelation(ctx.Kit.Ctx, ctx.Kit.Header, deleteOption); err != nil {
 			blog.ErrorJSON("syncServiceInstanceByTemplate failed, DeleteProcessInstanceRelation failed, option: %s, err: %s, rid: %s", deleteOption, err.Error(), rid)
-			return err
+			return nil, err
 		}
 	}
 
@@ -1272,12 +1282,12 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 			processData, e := mapstruct.Struct2Map(newProcess)
 			if e != nil {
 				blog.ErrorJSON("SyncServiceInstanceByTemplate failed, Struct2Map failed, process: %s, err: %s, rid: %s", newProcess, e.Error(), rid)
-				return ctx.Kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+				return nil, ctx.Kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
 			}
 			newProcessID, err := ps.Logic.CreateProcessInstance(ctx.Kit, processData)
 			if err != nil {
 				blog.ErrorJSON("syncServiceInstanceByTemplate failed, CreateProcessInstance failed, option: %s, err: %s, rid: %s", processData, err.Error(), rid)
-				return err
+				return nil, err
 			}
 
 			relation := &metadata.ProcessInstanceRelation{
@@ -1292,11 +1302,22 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 			_, err = ps.CoreAPI.CoreService().Process().CreateProcessInstanceRelation(ctx.Kit.Ctx, ctx.Kit.Header, relation)
 			if err != nil {
 				blog.ErrorJSON("syncServiceInstanceByTemplate failed, CreateProcessInstanceRelation failed, relation: %s, err: %s, rid: %s", relation, err.Error(), rid)
-				return err
+				return nil, err
 			}
+			changedProcessIDs = append(changedProcessIDs, newProcessID)
 		}
 	}
 
+	hostIDs := make([]int64, 0)
+	for _, hostID := range serviceInstance2HostMap {
+		hostIDs = append(hostIDs, hostID)
+	}
+	conflicts, err := ps.Logic.ValidateProcessPortConflict(ctx.Kit, bizID, hostIDs, changedProcessIDs)
+	if err != nil {
+		blog.Errorf("syncServiceInstanceByTemplate failed, validate port conflict failed, processIDs: %v, err: %v, rid: %s", changedProcessIDs, err, rid)
+		return nil, err
+	}
+
 	// reconstruct service instance's name as it's dependence(first process's name + first process's port) changed
 	for _, svcInstanceID := range serviceInstanceIDs {
 		if !serviceInstanceNameChangedMap[svcInstanceID] {
@@ -1304,7 +1325,7 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 		}
 		if err := ps.CoreAPI.CoreService().Process().ReconstructServiceInstanceName(ctx.Kit.Ctx, ctx.Kit.Header, svcInstanceID); err != nil {
 			blog.ErrorJSON("syncServiceInstanceByTemplate failed, ReconstructServiceInstanceName failed, instanceID:%d, err:%s, rid:%s", svcInstanceID, err.Error(), rid)
-			return err
+			return nil, err
 		}
 	}
 
@@ -1318,7 +1339,7 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 	})
 	if err != nil {
 		blog.Errorf("syncServiceInstanceByTemplate failed, ListServiceTemplates failed, serviceTemplateIDs: %+v, err: %s, rid: %s", serviceTemplateIDs, err.Error(), rid)
-		return err
+		return nil, err
 	}
 
 	// step 7:
@@ -1350,14 +1371,14 @@ func (ps *ProcServer) syncServiceInstanceByTemplate(ctx *rest.Contexts, syncOpti
 		resp, e := ps.CoreAPI.CoreService().Instance().UpdateInstance(ctx.Kit.Ctx, ctx.Kit.Header, common.BKInnerObjIDModule, moduleUpdateOption)
 		if e != nil {
 			blog.ErrorJSON("syncServiceInstanceByTemplate failed, UpdateInstance failed, option: %s, err: %s, rid:%s", moduleUpdateOption, e.Error(), rid)
-			return ctx.Kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+			return nil, ctx.Kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
 		}
 		if ccErr := resp.CCError(); ccErr != nil {
 			blog.ErrorJSON("syncServiceInstanceByTemplate failed, UpdateInstance failed, option: %s, result: %s, rid: %s", moduleUpdateOption, resp, rid)
-			return ccErr
+			return nil, ccErr
 		}
 	}
-	return nil
+	return conflicts, nil
 }
 
 func (ps *ProcServer) ListServiceInstancesWithHost(ctx *rest.Contexts) {
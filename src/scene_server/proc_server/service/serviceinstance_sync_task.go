@@ -0,0 +1,210 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// CreateSyncServiceInstanceTask synchronize the service instances of the modules with their service templates
+// asynchronously by task server, each module is synchronized in a sub task. The task waits for the unfinished
+// sync tasks of the same business to be executed first.
+func (ps *ProcServer) CreateSyncServiceInstanceTask(ctx *rest.Contexts) {
+	option := metadata.SyncServiceInstanceTaskOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if option.BizID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+	if len(option.ModuleIDs) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "bk_module_ids"))
+		return
+	}
+
+	modules, err := ps.getModules(ctx, option.ModuleIDs)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if len(modules) != len(option.ModuleIDs) {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "bk_module_ids"))
+		return
+	}
+	for _, module := range modules {
+		if module.BizID != option.BizID {
+			blog.Errorf("CreateSyncServiceInstanceTask failed, module %d not belongs to biz %d, rid: %s", module.ModuleID, option.BizID, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "bk_module_ids"))
+			return
+		}
+	}
+
+	indexKey := metadata.GetServiceInstanceSyncIndex(option.BizID)
+	unfinishedTaskIDs, err := ps.listUnfinishedSyncServiceInstanceTaskIDs(ctx, indexKey)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	tasksData := make([]interface{}, 0)
+	for _, moduleID := range option.ModuleIDs {
+		tasksData = append(tasksData, metadata.SyncModuleServiceInstanceByTemplateOption{
+			BizID:    option.BizID,
+			ModuleID: moduleID,
+		})
+	}
+	createOption := &metadata.CreateTaskRequest{
+		Name:      common.SyncServiceInstanceTaskName,
+		Flag:      indexKey,
+		Data:      tasksData,
+		Priority:  option.Priority,
+		DependsOn: unfinishedTaskIDs,
+	}
+	result, rawErr := ps.CoreAPI.TaskServer().Task().CreateTask(ctx.Kit.Ctx, ctx.Kit.Header, createOption)
+	if rawErr != nil {
+		blog.Errorf("CreateSyncServiceInstanceTask failed, create task failed, option: %+v, err: %v, rid: %s", option, rawErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed))
+		return
+	}
+	if ccErr := result.CCError(); ccErr != nil {
+		blog.Errorf("CreateSyncServiceInstanceTask failed, create task failed, option: %+v, result: %+v, rid: %s", option, result, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result.Data)
+}
+
+// GetSyncServiceInstanceTask get the service instance sync task of the business, the progress of the task
+// is updated once a module is synchronized.
+func (ps *ProcServer) GetSyncServiceInstanceTask(ctx *rest.Contexts) {
+	task, err := ps.getSyncServiceInstanceTask(ctx)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(task)
+}
+
+// CancelSyncServiceInstanceTask cancel the service instance sync task of the business, the modules
+// already synchronized are kept.
+func (ps *ProcServer) CancelSyncServiceInstanceTask(ctx *rest.Contexts) {
+	task, err := ps.getSyncServiceInstanceTask(ctx)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, rawErr := ps.CoreAPI.TaskServer().Task().Cancel(ctx.Kit.Ctx, ctx.Kit.Header, task.TaskID)
+	if rawErr != nil {
+		blog.Errorf("CancelSyncServiceInstanceTask failed, cancel task %s failed, err: %v, rid: %s", task.TaskID, rawErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed))
+		return
+	}
+	if ccErr := result.CCError(); ccErr != nil {
+		blog.Errorf("CancelSyncServiceInstanceTask failed, cancel task %s failed, result: %+v, rid: %s", task.TaskID, result, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// SyncServiceInstanceTaskHandler is called by task server to synchronize the service instances of one module
+func (ps *ProcServer) SyncServiceInstanceTaskHandler(ctx *rest.Contexts) {
+	task := metadata.SyncModuleServiceInstanceByTemplateOption{}
+	if err := ctx.DecodeInto(&task); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	syncOption := metadata.SyncServiceInstanceByTemplateOption{
+		BizID:     task.BizID,
+		ModuleIDs: []int64{task.ModuleID},
+	}
+	txnErr := ps.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ps.EnableTxn, ctx.Kit.Header, func() error {
+		if _, err := ps.syncServiceInstanceByTemplate(ctx, syncOption); err != nil {
+			return err
+		}
+		return nil
+	})
+	if txnErr != nil {
+		blog.Errorf("SyncServiceInstanceTaskHandler failed, task: %+v, err: %v, rid: %s", task, txnErr, ctx.Kit.Rid)
+		ctx.RespAutoError(txnErr)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+func (ps *ProcServer) getSyncServiceInstanceTask(ctx *rest.Contexts) (*metadata.APITaskDetail, errors.CCErrorCoder) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		return nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField)
+	}
+	taskID := ctx.Request.PathParameter("task_id")
+
+	result, err := ps.CoreAPI.TaskServer().Task().TaskDetail(ctx.Kit.Ctx, ctx.Kit.Header, taskID)
+	if err != nil {
+		blog.Errorf("getSyncServiceInstanceTask failed, get task %s failed, err: %v, rid: %s", taskID, err, ctx.Kit.Rid)
+		return nil, ctx.Kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if ccErr := result.CCError(); ccErr != nil {
+		blog.Errorf("getSyncServiceInstanceTask failed, get task %s failed, result: %+v, rid: %s", taskID, result, ctx.Kit.Rid)
+		return nil, ccErr
+	}
+
+	// the task must be a service instance sync task of the business
+	task := &result.Data.Info
+	if task.Name != common.SyncServiceInstanceTaskName || task.Flag != metadata.GetServiceInstanceSyncIndex(bizID) {
+		return nil, ctx.Kit.CCError.CCError(common.CCErrTaskNotFound)
+	}
+	return task, nil
+}
+
+func (ps *ProcServer) listUnfinishedSyncServiceInstanceTaskIDs(ctx *rest.Contexts, indexKey string) ([]string, errors.CCErrorCoder) {
+	listTaskOption := &metadata.ListAPITaskRequest{
+		Condition: mapstr.MapStr{
+			"flag": indexKey,
+			common.BKStatusField: map[string]interface{}{
+				common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusNew, metadata.APITaskStatusWaitExecute,
+					metadata.APITaskStatusWaitDependency, metadata.APITaskStatuExecute},
+			},
+		},
+		Page: metadata.BasePage{
+			Limit: common.BKMaxPageSize,
+		},
+	}
+	result, err := ps.CoreAPI.TaskServer().Task().ListTask(ctx.Kit.Ctx, ctx.Kit.Header, common.SyncServiceInstanceTaskName, listTaskOption)
+	if err != nil {
+		blog.Errorf("list unfinished service instance sync tasks failed, flag: %s, err: %v, rid: %s", indexKey, err, ctx.Kit.Rid)
+		return nil, ctx.Kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if ccErr := result.CCError(); ccErr != nil {
+		blog.Errorf("list unfinished service instance sync tasks failed, flag: %s, result: %+v, rid: %s", indexKey, result, ctx.Kit.Rid)
+		return nil, ccErr
+	}
+
+	taskIDs := make([]string, 0)
+	for _, task := range result.Data.Info {
+		taskIDs = append(taskIDs, task.TaskID)
+	}
+	return taskIDs, nil
+}
@@ -27,6 +27,7 @@ import (
 	"configcenter/src/common/types"
 	"configcenter/src/scene_server/task_server/app/options"
 	tasksvc "configcenter/src/scene_server/task_server/service"
+	"configcenter/src/scene_server/task_server/taskconfig"
 	"configcenter/src/storage/dal/redis"
 
 	"github.com/emicklei/go-restful"
@@ -140,15 +141,44 @@ func (h *TaskServer) onHostConfigUpdate(previous, current cc.ProcessConfig) {
 			}
 		}
 
+		// backoff and max_backoff are durations like "1s", retry_on_failure is a bool
+		retryPolicy := taskconfig.DefaultRetryPolicy
+		if strBackoff := current.ConfigMap[prefix+".backoff"]; strBackoff != "" {
+			backoff, err := time.ParseDuration(strBackoff)
+			if err != nil {
+				blog.Errorf(" parse task name %s backoff %s error. err:%s", name, strBackoff, err.Error())
+			} else {
+				retryPolicy.Backoff = backoff
+				retryPolicy.MaxBackoff = backoff
+			}
+		}
+		if strMaxBackoff := current.ConfigMap[prefix+".max_backoff"]; strMaxBackoff != "" {
+			maxBackoff, err := time.ParseDuration(strMaxBackoff)
+			if err != nil {
+				blog.Errorf(" parse task name %s max backoff %s error. err:%s", name, strMaxBackoff, err.Error())
+			} else {
+				retryPolicy.MaxBackoff = maxBackoff
+			}
+		}
+		if strRetryOnFailure := current.ConfigMap[prefix+".retry_on_failure"]; strRetryOnFailure != "" {
+			retryOnFailure, err := strconv.ParseBool(strRetryOnFailure)
+			if err != nil {
+				blog.Errorf(" parse task name %s retry on failure %s error. err:%s", name, strRetryOnFailure, err.Error())
+			} else {
+				retryPolicy.RetryOnFailure = retryOnFailure
+			}
+		}
+
 		f := func() ([]string, error) {
 			addrs := strings.Split(current.ConfigMap[prefix+".addrs"], ",")
 			return addrs, nil
 		}
 		task := tasksvc.TaskInfo{
-			Name:  name,
-			Addr:  f,
-			Path:  current.ConfigMap[prefix+".path"],
-			Retry: retry,
+			Name:        name,
+			Addr:        f,
+			Path:        current.ConfigMap[prefix+".path"],
+			Retry:       retry,
+			RetryPolicy: retryPolicy,
 		}
 		if h.taskQueue == nil {
 			h.taskQueue = make(map[string]tasksvc.TaskInfo, 0)
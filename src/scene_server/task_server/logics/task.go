@@ -24,6 +24,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
 )
 
 // Create add task
@@ -40,12 +41,31 @@ func (lgc *Logics) Create(ctx context.Context, input *metadata.CreateTaskRequest
 		return dbTask, lgc.ccErr.Errorf(common.CCErrCommParamsNeedString, "data")
 	}
 
+	dependsOn := util.StrArrayUnique(input.DependsOn)
+	if len(dependsOn) > 0 {
+		cond := mapstr.MapStr{"task_id": mapstr.MapStr{common.BKDBIN: dependsOn}}
+		cnt, err := lgc.db.Table(common.BKTableNameAPITask).Find(cond).Count(ctx)
+		if err != nil {
+			blog.ErrorJSON("count dependent tasks failed, cond:%s, err:%s, rid:%s", cond, err.Error(), lgc.rid)
+			return dbTask, lgc.ccErr.Error(common.CCErrCommDBSelectFailed)
+		}
+		if int(cnt) != len(dependsOn) {
+			blog.ErrorJSON("some of the dependent tasks %s not found, rid:%s", dependsOn, lgc.rid)
+			return dbTask, lgc.ccErr.CCError(common.CCErrTaskNotFound)
+		}
+	}
+
 	dbTask.TaskID = getStrTaskID("id")
 	dbTask.Name = input.Name
 	dbTask.User = lgc.user
 	dbTask.Flag = input.Flag
 	dbTask.Header = getDBHTTPHeader(lgc.header)
 	dbTask.Status = metadata.APITaskStatusNew
+	dbTask.Priority = input.Priority
+	dbTask.DependsOn = dependsOn
+	if len(dependsOn) > 0 {
+		dbTask.Status = metadata.APITaskStatusWaitDependency
+	}
 	dbTask.CreateTime = time.Now()
 	dbTask.LastTime = time.Now()
 	for _, taskItem := range input.Data {
@@ -107,6 +127,65 @@ func (lgc *Logics) Detail(ctx context.Context, taskID string) (*metadata.APITask
 	return &rows[0], nil
 }
 
+// Cancel cancel the task which is not finished, the sub tasks not executed yet are canceled,
+// the executing sub task is not interrupted.
+func (lgc *Logics) Cancel(ctx context.Context, taskID string) error {
+	task, err := lgc.Detail(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return lgc.ccErr.CCError(common.CCErrTaskNotFound)
+	}
+	if task.Status.IsFinished() {
+		blog.Errorf("cancel task %s failed, task is finished, status: %d, rid: %s", taskID, task.Status, lgc.rid)
+		return lgc.ccErr.CCErrorf(common.CCErrTaskStatusNotAllowChangeTo, metadata.APITaskStatusCancel)
+	}
+
+	unfinished := []metadata.APITaskStatus{metadata.APITaskStatusNew, metadata.APITaskStatusWaitExecute,
+		metadata.APITaskStatusWaitDependency, metadata.APITaskStatuExecute}
+	cond := mapstr.MapStr{
+		"task_id": taskID,
+		"status":  mapstr.MapStr{common.BKDBIN: unfinished},
+	}
+	data := mapstr.MapStr{
+		"status":             metadata.APITaskStatusCancel,
+		common.LastTimeField: time.Now(),
+	}
+	if err := lgc.db.Table(common.BKTableNameAPITask).Update(ctx, cond, data); err != nil {
+		blog.ErrorJSON("cancel task failed, cond:%s, err:%s, rid:%s", cond, err.Error(), lgc.rid)
+		return lgc.ccErr.Error(common.CCErrCommDBUpdateFailed)
+	}
+
+	ccErr := lgc.ccErr.CCError(common.CCErrTaskCanceled)
+	cancelResponse := &metadata.Response{
+		BaseResp: metadata.BaseResp{Result: false, Code: ccErr.GetCode(), ErrMsg: ccErr.Error()},
+	}
+	for _, subTask := range task.Detail {
+		if subTask.Status != metadata.APITaskStatusNew && subTask.Status != metadata.APITaskStatusWaitExecute {
+			continue
+		}
+		// only cancel the sub task which is not executed yet, in case it's executed during canceling
+		subCond := mapstr.MapStr{
+			"task_id": taskID,
+			"detail": mapstr.MapStr{common.BKDBElemMatch: mapstr.MapStr{
+				"sub_task_id": subTask.SubTaskID,
+				"status": mapstr.MapStr{common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusNew,
+					metadata.APITaskStatusWaitExecute}},
+			}},
+		}
+		subData := mapstr.MapStr{
+			"detail.$.status":   metadata.APITaskStatusCancel,
+			"detail.$.response": cancelResponse,
+		}
+		if err := lgc.db.Table(common.BKTableNameAPITask).Update(ctx, subCond, subData); err != nil {
+			blog.ErrorJSON("cancel sub task failed, cond:%s, err:%s, rid:%s", subCond, err.Error(), lgc.rid)
+			return lgc.ccErr.Error(common.CCErrCommDBUpdateFailed)
+		}
+	}
+	return nil
+}
+
 // ChangeStatusToSuccess task status change to success
 func (lgc *Logics) ChangeStatusToSuccess(ctx context.Context, taskID, subTaskID string) error {
 
@@ -34,11 +34,15 @@ var (
 	dbMaxRetry = 20
 )
 
+// waitDependencyPageSize is the page size of the tasks waiting for dependencies which are resolved at a time
+const waitDependencyPageSize = 100
+
 type TaskInfo struct {
-	Name  string
-	Addr  func() ([]string, error)
-	Path  string
-	Retry int64
+	Name        string
+	Addr        func() ([]string, error)
+	Path        string
+	Retry       int64
+	RetryPolicy taskconfig.RetryPolicy
 }
 
 type TaskQueue struct {
@@ -78,6 +82,7 @@ func (tq *TaskQueue) Stop() {
 func (tq *TaskQueue) Start() {
 
 	go tq.compensate(context.Background())
+	go tq.resolveDependencies(context.Background())
 	for _, taskInfo := range tq.task {
 
 		go func(taskInfo TaskInfo) {
@@ -107,6 +112,9 @@ func (tq *TaskQueue) execute(ctx context.Context, task TaskInfo) {
 	if task.Retry < 1 {
 		task.Retry = 1
 	}
+	if task.RetryPolicy.Backoff <= 0 {
+		task.RetryPolicy = taskconfig.DefaultRetryPolicy
+	}
 
 	for {
 		if tq.close {
@@ -178,6 +186,12 @@ func (tq *TaskQueue) executePush(ctx context.Context, taskInfo TaskInfo, taskQue
 	blog.InfoJSON("task execute task id:%s", taskQueue.TaskID)
 
 	allSucc := true
+	finished := 0
+	for _, subTask := range taskQueue.Detail {
+		if subTask.Status == metadata.APITaskStatusSuccess {
+			finished++
+		}
+	}
 
 	for _, subTask := range taskQueue.Detail {
 
@@ -185,20 +199,35 @@ func (tq *TaskQueue) executePush(ctx context.Context, taskInfo TaskInfo, taskQue
 			continue
 		}
 
+		// the sub tasks not executed yet are canceled by the cancel api, stop executing them
+		if tq.isTaskCanceled(ctx, taskQueue.TaskID) {
+			blog.Infof("task %s is canceled, stop executing the remaining sub tasks", taskQueue.TaskID)
+			return
+		}
+
 		if subTask.Status != metadata.APITaskStatusNew && subTask.Status != metadata.APITaskStatusWaitExecute {
 			blog.ErrorJSON("task execute http do error. taskID:%s, taskqueue:%s, queue info: status not wait execute ", taskQueue.TaskID, taskQueue)
 			allSucc = false
 			break
 		}
+		retryCount := int64(0)
 		for retry := int64(0); retry < taskInfo.Retry; retry++ {
+			if retry > 0 {
+				retryCount = retry
+				time.Sleep(taskInfo.RetryPolicy.Wait(retry))
+			}
 			resp, err = tq.service.CoreAPI.TaskServer().Queue(taskInfo.Name).Post(ctx, taskQueue.Header, taskInfo.Path, subTask.Data)
 			if err != nil {
-				time.Sleep(time.Millisecond * 100)
 				blog.ErrorJSON("task execute http do error. taskID:%s, path:%s, taskName:%s, err:%s", taskQueue.TaskID, taskInfo.Path, taskInfo.Name, err.Error())
 				continue
 			}
+			if !resp.Result && taskInfo.RetryPolicy.RetryOnFailure {
+				blog.ErrorJSON("task execute failed. taskID:%s, subTaskID:%s, taskName:%s, resp:%s", taskQueue.TaskID, subTask.SubTaskID, taskInfo.Name, resp)
+				continue
+			}
 			break
 		}
+		finished++
 
 		updateConditon := mapstr.New()
 		updateConditon.Set("task_id", taskQueue.TaskID)
@@ -223,6 +252,8 @@ func (tq *TaskQueue) executePush(ctx context.Context, taskInfo TaskInfo, taskQue
 			updateData.Set("detail.$.status", metadata.APITaskStatusSuccess)
 		}
 		updateData.Set("detail.$.response", errResponse)
+		updateData.Set("detail.$.retry_count", retryCount)
+		updateData.Set("progress", int64(finished*100/len(taskQueue.Detail)))
 		updateData.Set(common.LastTimeField, time.Now())
 
 		for dbRetry := 0; dbRetry < dbMaxRetry; dbRetry++ {
@@ -237,12 +268,14 @@ func (tq *TaskQueue) executePush(ctx context.Context, taskInfo TaskInfo, taskQue
 
 	}
 
-	// 所有任务执行完成，修改整个任务状态
+	// 所有任务执行完成，修改整个任务状态, 执行过程中被取消的任务保持取消状态
 	updateConditon := mapstr.New()
 	updateConditon.Set("task_id", taskQueue.TaskID)
+	updateConditon.Set("status", mapstr.MapStr{common.BKDBNE: metadata.APITaskStatusCancel})
 	updateData := mapstr.New()
 	if allSucc {
 		updateData.Set("status", metadata.APITaskStatusSuccess)
+		updateData.Set("progress", 100)
 	} else {
 		updateData.Set("status", metadata.APITAskStatusFail)
 	}
@@ -290,7 +323,7 @@ func (tq *TaskQueue) getWaitExectue(ctx context.Context, name string) ([]metadat
 	cond.Field("status").In([]metadata.APITaskStatus{metadata.APITaskStatusNew, metadata.APITaskStatusWaitExecute})
 
 	rows := make([]metadata.APITaskDetail, 0)
-	err := tq.service.DB.Table(common.BKTableNameAPITask).Find(cond.ToMapStr()).Sort("create_time").Limit(20).All(ctx, &rows)
+	err := tq.service.DB.Table(common.BKTableNameAPITask).Find(cond.ToMapStr()).Sort("-priority,create_time").Limit(20).All(ctx, &rows)
 	if err != nil {
 		blog.ErrorJSON("query wait execute error:%s, task queue task:%s, cond:%s", err.Error(), name, cond.ToMapStr())
 		return nil, tq.service.CCErr.Error("zh-cn", common.CCErrCommDBSelectFailed)
@@ -353,15 +386,129 @@ func (tq *TaskQueue) compensateDBExecute(ctx context.Context) {
 	}
 }
 
+// isTaskCanceled check whether the task is canceled during executing, db error is taken as not canceled
+func (tq *TaskQueue) isTaskCanceled(ctx context.Context, taskID string) bool {
+	cond := mapstr.MapStr{
+		"task_id": taskID,
+		"status":  metadata.APITaskStatusCancel,
+	}
+	cnt, err := tq.service.DB.Table(common.BKTableNameAPITask).Find(cond).Count(ctx)
+	if err != nil {
+		blog.ErrorJSON("check task canceled error, taskID:%s, err:%s", taskID, err.Error())
+		return false
+	}
+	return cnt > 0
+}
+
+// resolveDependencies start the tasks waiting for dependencies once all the dependent tasks are executed successfully,
+// the task fails if one of the dependent tasks fails or is canceled.
+func (tq *TaskQueue) resolveDependencies(ctx context.Context) {
+	ticker := time.NewTicker(time.Second * 5)
+	defer ticker.Stop()
+	for range ticker.C {
+		if tq.close {
+			return
+		}
+		tq.resolveDependenciesOnce(ctx)
+	}
+}
+
+// resolveDependenciesOnce check all the tasks waiting for dependencies, they are paged by task id so that
+// the tasks whose status are changed in this round do not make the others skipped.
+func (tq *TaskQueue) resolveDependenciesOnce(ctx context.Context) {
+	lastTaskID := ""
+	for {
+		cond := mapstr.MapStr{"status": metadata.APITaskStatusWaitDependency}
+		if len(lastTaskID) > 0 {
+			cond["task_id"] = mapstr.MapStr{common.BKDBGT: lastTaskID}
+		}
+		tasks := make([]metadata.APITaskDetail, 0)
+		err := tq.service.DB.Table(common.BKTableNameAPITask).Find(cond).Sort("task_id").
+			Limit(waitDependencyPageSize).All(ctx, &tasks)
+		if err != nil {
+			blog.ErrorJSON("query wait dependency task error:%s, cond:%s", err.Error(), cond)
+			return
+		}
+		if len(tasks) == 0 {
+			return
+		}
+
+		tq.resolvePageDependencies(ctx, tasks)
+		if len(tasks) < waitDependencyPageSize {
+			return
+		}
+		lastTaskID = tasks[len(tasks)-1].TaskID
+	}
+}
+
+// resolvePageDependencies start the tasks whose dependencies are all successful,
+// and fail the tasks whose dependencies are failed, canceled or not exist.
+func (tq *TaskQueue) resolvePageDependencies(ctx context.Context, tasks []metadata.APITaskDetail) {
+	dependIDs := make([]string, 0)
+	for _, task := range tasks {
+		dependIDs = append(dependIDs, task.DependsOn...)
+	}
+	dependCond := mapstr.MapStr{"task_id": mapstr.MapStr{common.BKDBIN: util.StrArrayUnique(dependIDs)}}
+	dependTasks := make([]metadata.APITaskDetail, 0)
+	err := tq.service.DB.Table(common.BKTableNameAPITask).Find(dependCond).Fields("task_id", "status").All(ctx, &dependTasks)
+	if err != nil {
+		blog.ErrorJSON("query dependent task error:%s, cond:%s", err.Error(), dependCond)
+		return
+	}
+	statusMap := make(map[string]metadata.APITaskStatus)
+	for _, task := range dependTasks {
+		statusMap[task.TaskID] = task.Status
+	}
+
+	for _, task := range tasks {
+		ready := true
+		failedDependID := ""
+		for _, dependID := range task.DependsOn {
+			status, exist := statusMap[dependID]
+			if !exist || status.IsFailure() || status.IsCanceled() {
+				failedDependID = dependID
+				break
+			}
+			if !status.IsSuccessful() {
+				ready = false
+			}
+		}
+
+		updateCond := mapstr.MapStr{"task_id": task.TaskID, "status": metadata.APITaskStatusWaitDependency}
+		updateData := mapstr.MapStr{common.LastTimeField: time.Now()}
+		switch {
+		case len(failedDependID) > 0:
+			ccErr := tq.service.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(task.Header)).CCErrorf(common.CCErrTaskDependencyFailed, failedDependID)
+			for idx := range task.Detail {
+				task.Detail[idx].Status = metadata.APITaskStatusCancel
+				task.Detail[idx].Response = &metadata.Response{
+					BaseResp: metadata.BaseResp{Result: false, Code: ccErr.GetCode(), ErrMsg: ccErr.Error()},
+				}
+			}
+			updateData["status"] = metadata.APITAskStatusFail
+			updateData["detail"] = task.Detail
+		case ready:
+			updateData["status"] = metadata.APITaskStatusNew
+		default:
+			continue
+		}
+
+		if err := tq.service.DB.Table(common.BKTableNameAPITask).Update(ctx, updateCond, updateData); err != nil {
+			blog.ErrorJSON("update wait dependency task error:%s, taskID:%s, data:%s", err.Error(), task.TaskID, updateData)
+		}
+	}
+}
+
 func (s *Service) initCodeTaskConfig() map[string]TaskInfo {
 	taskInfoMap := make(map[string]TaskInfo, 0)
 	codeTaskConfigArr := taskconfig.GetCodeTaskConfig()
 
 	for _, codeTaskConfig := range codeTaskConfigArr {
 		ti := TaskInfo{
-			Name:  codeTaskConfig.Name,
-			Retry: codeTaskConfig.Retry,
-			Path:  codeTaskConfig.Path,
+			Name:        codeTaskConfig.Name,
+			Retry:       codeTaskConfig.Retry,
+			Path:        codeTaskConfig.Path,
+			RetryPolicy: codeTaskConfig.RetryPolicy,
 		}
 		switch codeTaskConfig.SvrType {
 		case types.CC_MODULE_APISERVER:
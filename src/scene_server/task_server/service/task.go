@@ -65,6 +65,16 @@ func (s *Service) DetailTask(ctx *rest.Contexts) {
 	ctx.RespEntity(map[string]interface{}{"info": taskInfo})
 }
 
+// CancelTask cancel the task, the sub tasks not executed yet won't be executed
+func (s *Service) CancelTask(ctx *rest.Contexts) {
+	srvData := s.newSrvComm(ctx.Request.Request.Header)
+	if err := srvData.lgc.Cancel(srvData.ctx, ctx.Request.PathParameter("task_id")); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
 func (s *Service) StatusToSuccess(ctx *rest.Contexts) {
 	taskID := ctx.Request.PathParameter("task_id")
 	subTaskID := ctx.Request.PathParameter("sub_task_id")
@@ -13,6 +13,9 @@
 package taskconfig
 
 import (
+	"time"
+
+	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/types"
 )
@@ -26,6 +29,36 @@ type CodeTaskConfig struct {
 	Path string
 	// http request error. max retry
 	Retry int64
+	// retry backoff of the sub task requests
+	RetryPolicy RetryPolicy
+}
+
+// RetryPolicy how the sub task request is retried
+type RetryPolicy struct {
+	// wait time before the first retry, it's doubled for each next retry
+	Backoff time.Duration
+	// upper limit of the wait time between two retries
+	MaxBackoff time.Duration
+	// retry the sub task when it returns a failed response too, only use it when the sub task is idempotent
+	RetryOnFailure bool
+}
+
+// DefaultRetryPolicy retry failed requests almost immediately, which is the behavior before retry policy is supported
+var DefaultRetryPolicy = RetryPolicy{
+	Backoff:    100 * time.Millisecond,
+	MaxBackoff: 100 * time.Millisecond,
+}
+
+// Wait returns the wait time before the retry times retry, retry starts from 1
+func (r RetryPolicy) Wait(retry int64) time.Duration {
+	wait := r.Backoff
+	for i := int64(1); i < retry && wait < r.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if r.MaxBackoff > 0 && wait > r.MaxBackoff {
+		wait = r.MaxBackoff
+	}
+	return wait
 }
 
 var (
@@ -35,17 +68,27 @@ var (
 
 // init for auto task
 func init() {
-	AddCodeTaskConfig("sync-settemplate2set", types.CC_MODULE_TOPO, "/topo/v3/internal/task", 1)
+	AddCodeTaskConfigWithRetryPolicy(common.SyncSetTaskName, types.CC_MODULE_TOPO, "/topo/v3/internal/task", 3,
+		RetryPolicy{Backoff: time.Second, MaxBackoff: 10 * time.Second})
+	AddCodeTaskConfig(common.InstantiateSetTaskName, types.CC_MODULE_TOPO, "/topo/v3/internal/task/instantiate_set", 1)
+	AddCodeTaskConfigWithRetryPolicy(common.SyncServiceInstanceTaskName, types.CC_MODULE_PROC,
+		"/process/v3/internal/task/sync_service_instance", 3, RetryPolicy{Backoff: time.Second, MaxBackoff: 10 * time.Second})
 }
 
 // AddCodeTaskConfig add task
 func AddCodeTaskConfig(name, srvType, path string, retry int64) {
-	blog.Infof("add task. name:%s, service type:%s, path:%s", name, srvType, path)
+	AddCodeTaskConfigWithRetryPolicy(name, srvType, path, retry, DefaultRetryPolicy)
+}
+
+// AddCodeTaskConfigWithRetryPolicy add task whose sub task requests are retried with the retry policy
+func AddCodeTaskConfigWithRetryPolicy(name, srvType, path string, retry int64, policy RetryPolicy) {
+	blog.Infof("add task. name:%s, service type:%s, path:%s, retry: %d, retry policy: %+v", name, srvType, path, retry, policy)
 	codeTaskConfigArr = append(codeTaskConfigArr, CodeTaskConfig{
-		Name:    name,
-		SvrType: srvType,
-		Path:    path,
-		Retry:   retry,
+		Name:        name,
+		SvrType:     srvType,
+		Path:        path,
+		Retry:       retry,
+		RetryPolicy: policy,
 	})
 }
 
@@ -0,0 +1,32 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package taskconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyWait(t *testing.T) {
+	policy := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	expects := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for idx, expect := range expects {
+		if wait := policy.Wait(int64(idx + 1)); wait != expect {
+			t.Errorf("retry %d should wait %s, but got %s", idx+1, expect, wait)
+		}
+	}
+
+	if wait := DefaultRetryPolicy.Wait(3); wait != 100*time.Millisecond {
+		t.Errorf("default retry policy should wait 100ms, but got %s", wait)
+	}
+}
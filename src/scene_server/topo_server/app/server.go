@@ -28,6 +28,7 @@ import (
 	"configcenter/src/common/types"
 	"configcenter/src/scene_server/topo_server/app/options"
 	"configcenter/src/scene_server/topo_server/core"
+	"configcenter/src/scene_server/topo_server/core/settemplate"
 	"configcenter/src/scene_server/topo_server/service"
 	"configcenter/src/thirdpartyclient/elasticsearch"
 )
@@ -130,6 +131,10 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		Config:      server.Config,
 	}
 
+	scheduler := settemplate.NewSyncScheduler(engine.CoreAPI, server.Service.Core.SetTemplateOperation(),
+		engine.ServiceManageInterface)
+	go scheduler.Run(ctx)
+
 	err = backbone.StartServer(ctx, cancel, engine, server.Service.WebService(), true)
 	if err != nil {
 		return err
@@ -53,6 +53,7 @@ type AssociationOperationInterface interface {
 	CreateCommonInstAssociation(kit *rest.Kit, data *metadata.InstAsst) error
 	DeleteInstAssociation(kit *rest.Kit, cond condition.Condition) error
 	CheckAssociation(kit *rest.Kit, obj model.Object, objectID string, instID int64) error
+	TraverseInstAssociation(kit *rest.Kit, request *metadata.InstAssociationTraversalRequest) (*metadata.InstAssociationTraversalResult, error)
 
 	// 关联关系改造后的接口
 	SearchObjectAssocWithAssocKindList(kit *rest.Kit, asstKindIDs []string) (resp *metadata.AssociationList, err error)
@@ -0,0 +1,418 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"sort"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/condition"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// traversalVisit records how an instance is reached during the traversal.
+type traversalVisit struct {
+	depth int
+	// parent is the instance this instance is reached from, it's nil for the start instances.
+	parent *metadata.TraversalInstNode
+	// edge is the association this instance is reached through.
+	edge *metadata.TraversalEdge
+}
+
+// TraverseInstAssociation traverse the instance association graph breadth first from the start instances.
+// every instance is visited at most once, so the association cycles are cut off, and the depth of an
+// instance is always it's shortest hops to the start instances.
+func (assoc *association) TraverseInstAssociation(kit *rest.Kit, request *metadata.InstAssociationTraversalRequest) (
+	*metadata.InstAssociationTraversalResult, error) {
+
+	visited := make(map[metadata.TraversalInstNode]*traversalVisit)
+	frontier := make([]metadata.TraversalInstNode, 0)
+	for _, node := range request.Start {
+		if _, exist := visited[node]; exist {
+			continue
+		}
+		visited[node] = &traversalVisit{depth: 0}
+		frontier = append(frontier, node)
+	}
+
+	// the start instances must be exist.
+	startNames, err := assoc.getTraversalInstNames(kit, frontier)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range frontier {
+		if _, exist := startNames[node]; !exist {
+			blog.Errorf("traverse inst association, but start instance %s not exist, rid: %s", node, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "start")
+		}
+	}
+
+	edges := make(map[int64]metadata.TraversalEdge)
+	truncated := false
+	reachEnd := request.End != nil && visited[*request.End] != nil
+
+	for depth := 1; depth <= request.MaxDepth && len(frontier) > 0 && !reachEnd; depth++ {
+		assts, err := assoc.findTraversalInstAssociations(kit, frontier, request)
+		if err != nil {
+			return nil, err
+		}
+
+		frontierSet := make(map[metadata.TraversalInstNode]bool)
+		for _, node := range frontier {
+			frontierSet[node] = true
+		}
+
+		// collect the neighbors of the frontier instances, the first association which reaches a
+		// neighbor is used as the neighbor's parent edge.
+		candidates := make([]metadata.TraversalInstNode, 0)
+		candidateEdges := make(map[metadata.TraversalInstNode][]metadata.TraversalEdge)
+		for _, asst := range assts {
+			edge := metadata.TraversalEdge{
+				ID:                asst.ID,
+				ObjectAsstID:      asst.ObjectAsstID,
+				AssociationKindID: asst.AssociationKindID,
+				Src:               metadata.TraversalInstNode{ObjectID: asst.ObjectID, InstID: asst.InstID},
+				Dest:              metadata.TraversalInstNode{ObjectID: asst.AsstObjectID, InstID: asst.AsstInstID},
+			}
+
+			neighbors := make([]metadata.TraversalInstNode, 0)
+			if request.Direction != metadata.TraversalDirectionIn && frontierSet[edge.Src] {
+				neighbors = append(neighbors, edge.Dest)
+			}
+			if request.Direction != metadata.TraversalDirectionOut && frontierSet[edge.Dest] {
+				neighbors = append(neighbors, edge.Src)
+			}
+
+			for _, neighbor := range neighbors {
+				if _, exist := visited[neighbor]; exist {
+					// association to a visited instance closes a cycle, keep the edge but do not visit it again.
+					edges[edge.ID] = edge
+					continue
+				}
+				if _, exist := candidateEdges[neighbor]; !exist {
+					candidates = append(candidates, neighbor)
+				}
+				candidateEdges[neighbor] = append(candidateEdges[neighbor], edge)
+			}
+		}
+
+		matched, err := assoc.filterTraversalHop(kit, candidates, request.HopFilters(depth))
+		if err != nil {
+			return nil, err
+		}
+
+		frontier = make([]metadata.TraversalInstNode, 0)
+		for _, node := range candidates {
+			if !matched[node] {
+				continue
+			}
+
+			if len(visited) >= metadata.TraversalMaxNodes {
+				truncated = true
+				break
+			}
+
+			nodeEdges := candidateEdges[node]
+			parentEdge := nodeEdges[0]
+			parent := parentEdge.Src
+			if parent == node {
+				parent = parentEdge.Dest
+			}
+			visited[node] = &traversalVisit{depth: depth, parent: &parent, edge: &parentEdge}
+			for _, edge := range nodeEdges {
+				edges[edge.ID] = edge
+			}
+			frontier = append(frontier, node)
+
+			if request.End != nil && node == *request.End {
+				reachEnd = true
+			}
+		}
+
+		if truncated {
+			blog.Warnf("traverse inst association stopped at depth %d, visited instances exceed %d, rid: %s",
+				depth, metadata.TraversalMaxNodes, kit.Rid)
+			break
+		}
+	}
+
+	if request.End != nil {
+		return assoc.buildTraversalPath(kit, visited, *request.End, truncated)
+	}
+
+	return assoc.buildTraversalResult(kit, request.Page, visited, edges, truncated)
+}
+
+// findTraversalInstAssociations find the instance associations of the frontier instances on the traversal direction.
+func (assoc *association) findTraversalInstAssociations(kit *rest.Kit, frontier []metadata.TraversalInstNode,
+	request *metadata.InstAssociationTraversalRequest) ([]metadata.InstAsst, error) {
+
+	objInstIDs := make(map[string][]int64)
+	for _, node := range frontier {
+		objInstIDs[node.ObjectID] = append(objInstIDs[node.ObjectID], node.InstID)
+	}
+
+	orCond := make([]mapstr.MapStr, 0)
+	for objID, instIDs := range objInstIDs {
+		if request.Direction != metadata.TraversalDirectionIn {
+			orCond = append(orCond, mapstr.MapStr{
+				common.BKObjIDField:  objID,
+				common.BKInstIDField: mapstr.MapStr{common.BKDBIN: instIDs},
+			})
+		}
+		if request.Direction != metadata.TraversalDirectionOut {
+			orCond = append(orCond, mapstr.MapStr{
+				common.BKAsstObjIDField:  objID,
+				common.BKAsstInstIDField: mapstr.MapStr{common.BKDBIN: instIDs},
+			})
+		}
+	}
+
+	cond := condition.CreateCondition()
+	cond.NewOR().MapStrArr(orCond)
+	if len(request.AssociationKinds) > 0 {
+		cond.Field(common.AssociationKindIDField).In(request.AssociationKinds)
+	}
+
+	query := &metadata.QueryCondition{
+		Condition: cond.ToMapStr(),
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	rsp, err := assoc.clientSet.CoreService().Association().ReadInstAssociation(kit.Ctx, kit.Header, query)
+	if nil != err {
+		blog.Errorf("traverse inst association, but read inst association failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !rsp.Result {
+		blog.ErrorJSON("traverse inst association, but read inst association failed, query: %s, response: %s, rid: %s",
+			query, rsp, kit.Rid)
+		return nil, kit.CCError.New(rsp.Code, rsp.ErrMsg)
+	}
+
+	// sort the associations so that the parent edge of an instance is stable.
+	assts := rsp.Data.Info
+	sort.Slice(assts, func(i, j int) bool { return assts[i].ID < assts[j].ID })
+	return assts, nil
+}
+
+// filterTraversalHop returns the candidates which match all the hop filters.
+func (assoc *association) filterTraversalHop(kit *rest.Kit, candidates []metadata.TraversalInstNode,
+	filters []metadata.TraversalHopFilter) (map[metadata.TraversalInstNode]bool, error) {
+
+	matched := make(map[metadata.TraversalInstNode]bool)
+	for _, node := range candidates {
+		matched[node] = true
+	}
+
+	for _, filter := range filters {
+		objInstIDs := make(map[string][]int64)
+		for node := range matched {
+			if len(filter.Objects) > 0 && !util.InStrArr(filter.Objects, node.ObjectID) {
+				delete(matched, node)
+				continue
+			}
+			if _, exist := filter.Condition[node.ObjectID]; exist {
+				objInstIDs[node.ObjectID] = append(objInstIDs[node.ObjectID], node.InstID)
+			}
+		}
+
+		for objID, instIDs := range objInstIDs {
+			idField := metadata.GetInstIDFieldByObjID(objID)
+			query := &metadata.QueryCondition{
+				Condition: mapstr.MapStr{
+					common.BKDBAND: []mapstr.MapStr{
+						filter.Condition[objID],
+						{idField: mapstr.MapStr{common.BKDBIN: instIDs}},
+					},
+				},
+				Fields: []string{idField},
+				Page:   metadata.BasePage{Limit: common.BKNoLimit},
+			}
+			rsp, err := assoc.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, objID, query)
+			if err != nil {
+				blog.Errorf("traverse inst association, but read instance failed, err: %v, rid: %s", err, kit.Rid)
+				return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+			}
+			if !rsp.Result {
+				blog.ErrorJSON("traverse inst association, but read instance failed, query: %s, response: %s, rid: %s",
+					query, rsp, kit.Rid)
+				return nil, kit.CCError.New(rsp.Code, rsp.ErrMsg)
+			}
+
+			hit := make(map[int64]bool)
+			for _, inst := range rsp.Data.Info {
+				id, err := inst.Int64(idField)
+				if err != nil {
+					blog.ErrorJSON("traverse inst association, but parse instance id failed, inst: %s, rid: %s", inst, kit.Rid)
+					return nil, kit.CCError.CCErrorf(common.CCErrCommInstFieldConvertFail, objID, idField, "int", err.Error())
+				}
+				hit[id] = true
+			}
+			for _, instID := range instIDs {
+				if !hit[instID] {
+					delete(matched, metadata.TraversalInstNode{ObjectID: objID, InstID: instID})
+				}
+			}
+		}
+	}
+
+	return matched, nil
+}
+
+// getTraversalInstNames returns the name of the instances, instance which does not exist is not in the result.
+func (assoc *association) getTraversalInstNames(kit *rest.Kit, nodes []metadata.TraversalInstNode) (
+	map[metadata.TraversalInstNode]string, error) {
+
+	objInstIDs := make(map[string][]int64)
+	for _, node := range nodes {
+		objInstIDs[node.ObjectID] = append(objInstIDs[node.ObjectID], node.InstID)
+	}
+
+	names := make(map[metadata.TraversalInstNode]string)
+	for objID, instIDs := range objInstIDs {
+		idField := metadata.GetInstIDFieldByObjID(objID)
+		nameField := metadata.GetInstNameFieldName(objID)
+		query := &metadata.QueryCondition{
+			Condition: mapstr.MapStr{idField: mapstr.MapStr{common.BKDBIN: instIDs}},
+			Fields:    []string{idField, nameField},
+			Page:      metadata.BasePage{Limit: common.BKNoLimit},
+		}
+		rsp, err := assoc.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, objID, query)
+		if err != nil {
+			blog.Errorf("traverse inst association, but read instance failed, err: %v, rid: %s", err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if !rsp.Result {
+			blog.ErrorJSON("traverse inst association, but read instance failed, query: %s, response: %s, rid: %s",
+				query, rsp, kit.Rid)
+			return nil, kit.CCError.New(rsp.Code, rsp.ErrMsg)
+		}
+
+		for _, inst := range rsp.Data.Info {
+			id, err := inst.Int64(idField)
+			if err != nil {
+				blog.ErrorJSON("traverse inst association, but parse instance id failed, inst: %s, rid: %s", inst, kit.Rid)
+				return nil, kit.CCError.CCErrorf(common.CCErrCommInstFieldConvertFail, objID, idField, "int", err.Error())
+			}
+			name, _ := inst.String(nameField)
+			names[metadata.TraversalInstNode{ObjectID: objID, InstID: id}] = name
+		}
+	}
+
+	return names, nil
+}
+
+// buildTraversalResult page the visited instances ordered by depth, and returns the edges related to them.
+func (assoc *association) buildTraversalResult(kit *rest.Kit, page metadata.BasePage,
+	visited map[metadata.TraversalInstNode]*traversalVisit, edges map[int64]metadata.TraversalEdge, truncated bool) (
+	*metadata.InstAssociationTraversalResult, error) {
+
+	all := make([]metadata.TraversalInstNode, 0)
+	for node := range visited {
+		all = append(all, node)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if visited[all[i]].depth != visited[all[j]].depth {
+			return visited[all[i]].depth < visited[all[j]].depth
+		}
+		if all[i].ObjectID != all[j].ObjectID {
+			return all[i].ObjectID < all[j].ObjectID
+		}
+		return all[i].InstID < all[j].InstID
+	})
+
+	result := &metadata.InstAssociationTraversalResult{
+		Count:     len(all),
+		Nodes:     make([]metadata.TraversalNode, 0),
+		Edges:     make([]metadata.TraversalEdge, 0),
+		Truncated: truncated,
+	}
+
+	if page.Start >= len(all) {
+		return result, nil
+	}
+	end := page.Start + page.Limit
+	if end > len(all) {
+		end = len(all)
+	}
+	paged := all[page.Start:end]
+
+	names, err := assoc.getTraversalInstNames(kit, paged)
+	if err != nil {
+		return nil, err
+	}
+
+	pagedSet := make(map[metadata.TraversalInstNode]bool)
+	for _, node := range paged {
+		pagedSet[node] = true
+		result.Nodes = append(result.Nodes, metadata.TraversalNode{
+			TraversalInstNode: node,
+			InstName:          names[node],
+			Depth:             visited[node].depth,
+		})
+	}
+
+	for _, edge := range edges {
+		if pagedSet[edge.Src] || pagedSet[edge.Dest] {
+			result.Edges = append(result.Edges, edge)
+		}
+	}
+	sort.Slice(result.Edges, func(i, j int) bool { return result.Edges[i].ID < result.Edges[j].ID })
+
+	return result, nil
+}
+
+// buildTraversalPath returns the path from the start instance to the end instance,
+// the result is empty if the end instance is not reached.
+func (assoc *association) buildTraversalPath(kit *rest.Kit, visited map[metadata.TraversalInstNode]*traversalVisit,
+	end metadata.TraversalInstNode, truncated bool) (*metadata.InstAssociationTraversalResult, error) {
+
+	result := &metadata.InstAssociationTraversalResult{
+		Nodes:     make([]metadata.TraversalNode, 0),
+		Edges:     make([]metadata.TraversalEdge, 0),
+		Truncated: truncated,
+	}
+
+	if _, exist := visited[end]; !exist {
+		return result, nil
+	}
+
+	path := make([]metadata.TraversalInstNode, 0)
+	edges := make([]metadata.TraversalEdge, 0)
+	for node := &end; node != nil; node = visited[*node].parent {
+		path = append([]metadata.TraversalInstNode{*node}, path...)
+		if visited[*node].edge != nil {
+			edges = append([]metadata.TraversalEdge{*visited[*node].edge}, edges...)
+		}
+	}
+
+	names, err := assoc.getTraversalInstNames(kit, path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range path {
+		result.Nodes = append(result.Nodes, metadata.TraversalNode{
+			TraversalInstNode: node,
+			InstName:          names[node],
+			Depth:             visited[node].depth,
+		})
+	}
+	result.Edges = edges
+	result.Count = len(result.Nodes)
+	return result, nil
+}
@@ -0,0 +1,437 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/apimachinery/coreservice"
+	asstclient "configcenter/src/apimachinery/coreservice/association"
+	instclient "configcenter/src/apimachinery/coreservice/instance"
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+)
+
+// traversalGraph is the instances and the instance associations read by the traversal.
+type traversalGraph struct {
+	insts map[string][]mapstr.MapStr
+	assts []metadata.InstAsst
+}
+
+func (g *traversalGraph) addInst(objID string, instID int64) {
+	if g.insts == nil {
+		g.insts = make(map[string][]mapstr.MapStr)
+	}
+	g.insts[objID] = append(g.insts[objID], mapstr.MapStr{
+		common.BKInstIDField:   instID,
+		common.BKInstNameField: fmt.Sprintf("%s%d", objID, instID),
+	})
+}
+
+func (g *traversalGraph) addAsst(id int64, kind string, src, dest metadata.TraversalInstNode) {
+	g.assts = append(g.assts, metadata.InstAsst{
+		ID:                id,
+		ObjectID:          src.ObjectID,
+		InstID:            src.InstID,
+		AsstObjectID:      dest.ObjectID,
+		AsstInstID:        dest.InstID,
+		ObjectAsstID:      fmt.Sprintf("%s_%s_%s", src.ObjectID, kind, dest.ObjectID),
+		AssociationKindID: kind,
+	})
+}
+
+// toGeneric converts the value to the json generic value, so that the conditions and the documents can be compared.
+func toGeneric(t require.TestingT, value interface{}) interface{} {
+	js, err := json.Marshal(value)
+	require.NoError(t, err)
+	var generic interface{}
+	require.NoError(t, json.Unmarshal(js, &generic))
+	return generic
+}
+
+// matchCondition supports the mongo operators used by the traversal and the tests.
+func matchCondition(doc map[string]interface{}, cond map[string]interface{}) bool {
+	for key, value := range cond {
+		switch key {
+		case common.BKDBAND, common.BKDBOR:
+			subConds := value.([]interface{})
+			matched := key == common.BKDBAND
+			for _, subCond := range subConds {
+				if matchCondition(doc, subCond.(map[string]interface{})) != matched {
+					matched = !matched
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+			continue
+		}
+
+		ops, isOp := value.(map[string]interface{})
+		if !isOp {
+			if doc[key] != value {
+				return false
+			}
+			continue
+		}
+		for op, opValue := range ops {
+			switch op {
+			case common.BKDBIN:
+				in := false
+				for _, item := range opValue.([]interface{}) {
+					in = in || doc[key] == item
+				}
+				if !in {
+					return false
+				}
+			case common.BKDBNE:
+				if doc[key] == opValue {
+					return false
+				}
+			default:
+				panic(fmt.Sprintf("unsupported operator %s", op))
+			}
+		}
+	}
+	return true
+}
+
+type mockTraversalClientSet struct {
+	apimachinery.ClientSetInterface
+	graph *traversalGraph
+	t     *testing.T
+}
+
+func (c *mockTraversalClientSet) CoreService() coreservice.CoreServiceClientInterface {
+	return &mockTraversalCoreService{graph: c.graph, t: c.t}
+}
+
+type mockTraversalCoreService struct {
+	coreservice.CoreServiceClientInterface
+	graph *traversalGraph
+	t     *testing.T
+}
+
+func (c *mockTraversalCoreService) Association() asstclient.AssociationClientInterface {
+	return &mockTraversalAssociation{graph: c.graph, t: c.t}
+}
+
+func (c *mockTraversalCoreService) Instance() instclient.InstanceClientInterface {
+	return &mockTraversalInstance{graph: c.graph, t: c.t}
+}
+
+type mockTraversalAssociation struct {
+	asstclient.AssociationClientInterface
+	graph *traversalGraph
+	t     *testing.T
+}
+
+func (a *mockTraversalAssociation) ReadInstAssociation(ctx context.Context, h http.Header,
+	input *metadata.QueryCondition) (*metadata.ReadInstAssociationResult, error) {
+
+	cond := toGeneric(a.t, input.Condition).(map[string]interface{})
+	result := &metadata.ReadInstAssociationResult{BaseResp: metadata.SuccessBaseResp}
+	for _, asst := range a.graph.assts {
+		if matchCondition(toGeneric(a.t, asst).(map[string]interface{}), cond) {
+			result.Data.Info = append(result.Data.Info, asst)
+		}
+	}
+	result.Data.Count = uint64(len(result.Data.Info))
+	return result, nil
+}
+
+type mockTraversalInstance struct {
+	instclient.InstanceClientInterface
+	graph *traversalGraph
+	t     *testing.T
+}
+
+func (i *mockTraversalInstance) ReadInstance(ctx context.Context, h http.Header, objID string,
+	input *metadata.QueryCondition) (*metadata.QueryConditionResult, error) {
+
+	cond := toGeneric(i.t, input.Condition).(map[string]interface{})
+	result := &metadata.QueryConditionResult{BaseResp: metadata.SuccessBaseResp}
+	for _, inst := range i.graph.insts[objID] {
+		if matchCondition(toGeneric(i.t, inst).(map[string]interface{}), cond) {
+			result.Data.Info = append(result.Data.Info, inst)
+		}
+	}
+	result.Data.Count = len(result.Data.Info)
+	return result, nil
+}
+
+var (
+	sw1 = metadata.TraversalInstNode{ObjectID: "sw", InstID: 1}
+	sw2 = metadata.TraversalInstNode{ObjectID: "sw", InstID: 2}
+	rt1 = metadata.TraversalInstNode{ObjectID: "rt", InstID: 1}
+	sv1 = metadata.TraversalInstNode{ObjectID: "sv", InstID: 1}
+	sv2 = metadata.TraversalInstNode{ObjectID: "sv", InstID: 2}
+	sv3 = metadata.TraversalInstNode{ObjectID: "sv", InstID: 3}
+)
+
+// newTraversalTestAssociation returns the association operation on the graph:
+//
+//	sv1 --belong--> sw1 --connect--> rt1 --connect--> sw2 --run--> sv2
+//	                 ^                                 |
+//	                 +-------------connect-------------+
+//
+// and the isolated instance sv3.
+func newTraversalTestAssociation(t *testing.T) *association {
+	graph := &traversalGraph{}
+	for _, node := range []metadata.TraversalInstNode{sw1, sw2, rt1, sv1, sv2, sv3} {
+		graph.addInst(node.ObjectID, node.InstID)
+	}
+	graph.addAsst(1, "connect", sw1, rt1)
+	graph.addAsst(2, "connect", rt1, sw2)
+	graph.addAsst(3, "connect", sw2, sw1)
+	graph.addAsst(4, "belong", sv1, sw1)
+	graph.addAsst(5, "run", sw2, sv2)
+	return &association{clientSet: &mockTraversalClientSet{graph: graph, t: t}}
+}
+
+var traversalTestKit = func() *rest.Kit {
+	errIf, _ := errors.NewFactory("../../../../../resources/errors/")
+	return &rest.Kit{
+		Rid:             "test_req_id",
+		Header:          http.Header{},
+		Ctx:             context.Background(),
+		CCError:         errIf.CreateDefaultCCErrorIf("en"),
+		User:            "test_user",
+		SupplierAccount: "0",
+	}
+}()
+
+func traversalNode(node metadata.TraversalInstNode, depth int) metadata.TraversalNode {
+	return metadata.TraversalNode{
+		TraversalInstNode: node,
+		InstName:          fmt.Sprintf("%s%d", node.ObjectID, node.InstID),
+		Depth:             depth,
+	}
+}
+
+func edgeIDs(edges []metadata.TraversalEdge) []int64 {
+	ids := make([]int64, 0)
+	for _, edge := range edges {
+		ids = append(ids, edge.ID)
+	}
+	return ids
+}
+
+func TestTraverseInstAssociation(t *testing.T) {
+	assoc := newTraversalTestAssociation(t)
+	page := metadata.BasePage{Limit: common.BKDefaultLimit}
+
+	testCases := []struct {
+		name    string
+		request metadata.InstAssociationTraversalRequest
+		nodes   []metadata.TraversalNode
+		edges   []int64
+	}{
+		{
+			name: "follow the out associations, the cycle is cut off",
+			request: metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sw1},
+				Direction: metadata.TraversalDirectionOut, MaxDepth: metadata.TraversalMaxDepth, Page: page},
+			nodes: []metadata.TraversalNode{traversalNode(sw1, 0), traversalNode(rt1, 1), traversalNode(sw2, 2),
+				traversalNode(sv2, 3)},
+			edges: []int64{1, 2, 3, 5},
+		},
+		{
+			name: "follow the in associations",
+			request: metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sw1},
+				Direction: metadata.TraversalDirectionIn, MaxDepth: metadata.TraversalMaxDepth, Page: page},
+			nodes: []metadata.TraversalNode{traversalNode(sw1, 0), traversalNode(sv1, 1), traversalNode(sw2, 1),
+				traversalNode(rt1, 2)},
+			edges: []int64{1, 2, 3, 4},
+		},
+		{
+			name: "follow both sides in the max depth",
+			request: metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sw1},
+				Direction: metadata.TraversalDirectionBoth, MaxDepth: 1, Page: page},
+			nodes: []metadata.TraversalNode{traversalNode(sw1, 0), traversalNode(rt1, 1), traversalNode(sv1, 1),
+				traversalNode(sw2, 1)},
+			edges: []int64{1, 3, 4},
+		},
+		{
+			name: "follow the association kinds",
+			request: metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sw1},
+				Direction: metadata.TraversalDirectionBoth, MaxDepth: metadata.TraversalMaxDepth, Page: page,
+				AssociationKinds: []string{"belong"}},
+			nodes: []metadata.TraversalNode{traversalNode(sw1, 0), traversalNode(sv1, 1)},
+			edges: []int64{4},
+		},
+		{
+			name: "filter the objects of a hop",
+			request: metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sw1},
+				Direction: metadata.TraversalDirectionBoth, MaxDepth: 2, Page: page,
+				Filters: []metadata.TraversalHopFilter{{Depth: 1, Objects: []string{"rt"}}}},
+			nodes: []metadata.TraversalNode{traversalNode(sw1, 0), traversalNode(rt1, 1), traversalNode(sw2, 2)},
+			edges: []int64{1, 2},
+		},
+		{
+			name: "filter the attributes of a hop",
+			request: metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sw1},
+				Direction: metadata.TraversalDirectionBoth, MaxDepth: 2, Page: page,
+				Filters: []metadata.TraversalHopFilter{
+					{Depth: 1, Objects: []string{"rt"}},
+					{Depth: 2, Condition: map[string]mapstr.MapStr{"sw": {common.BKInstNameField: "sw3"}}},
+				}},
+			nodes: []metadata.TraversalNode{traversalNode(sw1, 0), traversalNode(rt1, 1)},
+			edges: []int64{1},
+		},
+		{
+			name: "filter the attributes of all the hops",
+			request: metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sw1},
+				Direction: metadata.TraversalDirectionBoth, MaxDepth: metadata.TraversalMaxDepth, Page: page,
+				Filters: []metadata.TraversalHopFilter{{Condition: map[string]mapstr.MapStr{
+					"sv": {common.BKInstNameField: mapstr.MapStr{common.BKDBNE: "sv1"}},
+				}}}},
+			nodes: []metadata.TraversalNode{traversalNode(sw1, 0), traversalNode(rt1, 1), traversalNode(sw2, 1),
+				traversalNode(sv2, 2)},
+			edges: []int64{1, 2, 3, 5},
+		},
+		{
+			name: "traverse from several start instances",
+			request: metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sv1, sv2, sv1},
+				Direction: metadata.TraversalDirectionBoth, MaxDepth: 1, Page: page},
+			nodes: []metadata.TraversalNode{traversalNode(sv1, 0), traversalNode(sv2, 0), traversalNode(sw1, 1),
+				traversalNode(sw2, 1)},
+			edges: []int64{4, 5},
+		},
+	}
+
+	for _, testCase := range testCases {
+		result, err := assoc.TraverseInstAssociation(traversalTestKit, &testCase.request)
+		require.NoError(t, err, testCase.name)
+		require.Equal(t, len(testCase.nodes), result.Count, testCase.name)
+		require.Equal(t, testCase.nodes, result.Nodes, testCase.name)
+		require.Equal(t, testCase.edges, edgeIDs(result.Edges), testCase.name)
+		require.False(t, result.Truncated, testCase.name)
+	}
+
+	// the start instance must exist
+	request := metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{{ObjectID: "sw", InstID: 9}},
+		Direction: metadata.TraversalDirectionBoth, MaxDepth: 1, Page: page}
+	_, err := assoc.TraverseInstAssociation(traversalTestKit, &request)
+	require.Error(t, err)
+	require.Equal(t, common.CCErrCommParamsIsInvalid, err.(errors.CCErrorCoder).GetCode())
+}
+
+func TestTraverseInstAssociationPage(t *testing.T) {
+	assoc := newTraversalTestAssociation(t)
+	request := metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sw1},
+		Direction: metadata.TraversalDirectionBoth, MaxDepth: metadata.TraversalMaxDepth,
+		Page: metadata.BasePage{Start: 1, Limit: 2}}
+
+	// the nodes are ordered by depth, the edges related to the paged nodes are returned.
+	result, err := assoc.TraverseInstAssociation(traversalTestKit, &request)
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Count)
+	require.Equal(t, []metadata.TraversalNode{traversalNode(rt1, 1), traversalNode(sv1, 1)}, result.Nodes)
+	require.Equal(t, []int64{1, 2, 4}, edgeIDs(result.Edges))
+
+	request.Page = metadata.BasePage{Start: 4, Limit: 2}
+	result, err = assoc.TraverseInstAssociation(traversalTestKit, &request)
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Count)
+	require.Equal(t, []metadata.TraversalNode{traversalNode(sv2, 2)}, result.Nodes)
+	require.Equal(t, []int64{5}, edgeIDs(result.Edges))
+
+	request.Page = metadata.BasePage{Start: 5, Limit: 2}
+	result, err = assoc.TraverseInstAssociation(traversalTestKit, &request)
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Count)
+	require.Empty(t, result.Nodes)
+	require.Empty(t, result.Edges)
+}
+
+func TestTraverseInstAssociationPath(t *testing.T) {
+	assoc := newTraversalTestAssociation(t)
+	page := metadata.BasePage{Limit: common.BKDefaultLimit}
+
+	// the shortest path goes through the cycle association sw2 -> sw1 instead of the router
+	request := metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sv1}, End: &sv2,
+		Direction: metadata.TraversalDirectionBoth, MaxDepth: metadata.TraversalMaxDepth, Page: page}
+	result, err := assoc.TraverseInstAssociation(traversalTestKit, &request)
+	require.NoError(t, err)
+	require.Equal(t, 4, result.Count)
+	require.Equal(t, []metadata.TraversalNode{traversalNode(sv1, 0), traversalNode(sw1, 1), traversalNode(sw2, 2),
+		traversalNode(sv2, 3)}, result.Nodes)
+	require.Equal(t, []int64{4, 3, 5}, edgeIDs(result.Edges))
+
+	// the end instance is the start instance
+	request.End = &sv1
+	result, err = assoc.TraverseInstAssociation(traversalTestKit, &request)
+	require.NoError(t, err)
+	require.Equal(t, []metadata.TraversalNode{traversalNode(sv1, 0)}, result.Nodes)
+	require.Empty(t, result.Edges)
+
+	unreachable := []struct {
+		name      string
+		end       metadata.TraversalInstNode
+		direction metadata.TraversalDirection
+		maxDepth  int
+	}{
+		{name: "the end instance has no association", end: sv3, direction: metadata.TraversalDirectionBoth,
+			maxDepth: metadata.TraversalMaxDepth},
+		{name: "the end instance exceeds the max depth", end: sv2, direction: metadata.TraversalDirectionBoth,
+			maxDepth: 2},
+		{name: "the end instance is on the other direction", end: sv2, direction: metadata.TraversalDirectionIn,
+			maxDepth: metadata.TraversalMaxDepth},
+	}
+	for _, testCase := range unreachable {
+		request := metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{sv1},
+			End: &testCase.end, Direction: testCase.direction, MaxDepth: testCase.maxDepth, Page: page}
+		result, err := assoc.TraverseInstAssociation(traversalTestKit, &request)
+		require.NoError(t, err, testCase.name)
+		require.Equal(t, 0, result.Count, testCase.name)
+		require.Empty(t, result.Nodes, testCase.name)
+		require.Empty(t, result.Edges, testCase.name)
+		require.False(t, result.Truncated, testCase.name)
+	}
+}
+
+func TestTraverseInstAssociationTruncated(t *testing.T) {
+	// the hub instance is associated with more instances than the traversal can visit
+	hub := metadata.TraversalInstNode{ObjectID: "hub", InstID: 1}
+	graph := &traversalGraph{}
+	graph.addInst(hub.ObjectID, hub.InstID)
+	for id := int64(1); id <= metadata.TraversalMaxNodes+5; id++ {
+		leaf := metadata.TraversalInstNode{ObjectID: "leaf", InstID: id}
+		graph.addInst(leaf.ObjectID, leaf.InstID)
+		graph.addAsst(id, "connect", hub, leaf)
+	}
+	assoc := &association{clientSet: &mockTraversalClientSet{graph: graph, t: t}}
+
+	request := metadata.InstAssociationTraversalRequest{Start: []metadata.TraversalInstNode{hub},
+		Direction: metadata.TraversalDirectionBoth, MaxDepth: metadata.TraversalMaxDepth,
+		Page: metadata.BasePage{Start: metadata.TraversalMaxNodes - 2, Limit: 10}}
+	result, err := assoc.TraverseInstAssociation(traversalTestKit, &request)
+	require.NoError(t, err)
+	require.True(t, result.Truncated)
+	require.Equal(t, metadata.TraversalMaxNodes, result.Count)
+	leaf := metadata.TraversalInstNode{ObjectID: "leaf", InstID: metadata.TraversalMaxNodes - 2}
+	lastLeaf := metadata.TraversalInstNode{ObjectID: "leaf", InstID: metadata.TraversalMaxNodes - 1}
+	require.Equal(t, []metadata.TraversalNode{traversalNode(leaf, 1), traversalNode(lastLeaf, 1)}, result.Nodes)
+	require.Equal(t, []int64{metadata.TraversalMaxNodes - 2, metadata.TraversalMaxNodes - 1}, edgeIDs(result.Edges))
+}
@@ -997,7 +997,8 @@ func (c *commonInst) FindOriginInst(kit *rest.Kit, objID string, cond *metadata.
 			return nil, kit.CCError.New(rsp.Code, rsp.ErrMsg)
 		}
 
-		return &metadata.InstResult{Count: rsp.Data.Count, Info: mapstr.NewArrayFromMapStr(rsp.Data.Info)}, nil
+		return &metadata.InstResult{Count: rsp.Data.Count, Info: mapstr.NewArrayFromMapStr(rsp.Data.Info),
+			NextCursor: rsp.Data.NextCursor}, nil
 
 	default:
 		queryCond, err := mapstr.NewFromInterface(cond.Condition)
@@ -1005,6 +1006,7 @@ func (c *commonInst) FindOriginInst(kit *rest.Kit, objID string, cond *metadata.
 		input.Page.Start = cond.Start
 		input.Page.Limit = cond.Limit
 		input.Page.Sort = cond.Sort
+		input.Page.Cursor = cond.Cursor
 		input.Fields = strings.Split(cond.Fields, ",")
 		rsp, err := c.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, objID, input)
 		if nil != err {
@@ -1016,7 +1018,7 @@ func (c *commonInst) FindOriginInst(kit *rest.Kit, objID string, cond *metadata.
 			blog.Errorf("[operation-inst] failed to delete the object(%s) inst by the condition(%#v), err: %s, rid: %s", objID, cond, rsp.ErrMsg, kit.Rid)
 			return nil, kit.CCError.New(rsp.Code, rsp.ErrMsg)
 		}
-		return &metadata.InstResult{Info: rsp.Data.Info, Count: rsp.Data.Count}, nil
+		return &metadata.InstResult{Info: rsp.Data.Info, Count: rsp.Data.Count, NextCursor: rsp.Data.NextCursor}, nil
 	}
 }
 
@@ -42,6 +42,8 @@ type BusinessOperationInterface interface {
 	HasHosts(kit *rest.Kit, bizID int64) (bool, error)
 	SetProxy(set SetOperationInterface, module ModuleOperationInterface, inst InstOperationInterface, obj ObjectOperationInterface)
 	GenerateAchieveBusinessName(kit *rest.Kit, bizName string) (achieveName string, err error)
+	ArchiveBusiness(kit *rest.Kit, obj model.Object, bizID int64, metaData *metadata.Metadata) (*metadata.BusinessArchive, error)
+	RestoreBusiness(kit *rest.Kit, obj model.Object, bizID int64, option metadata.RestoreBusinessOption, metaData *metadata.Metadata) (*metadata.BusinessRestoreReport, error)
 }
 
 // NewBusinessOperation create a business instance
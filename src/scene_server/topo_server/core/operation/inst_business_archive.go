@@ -0,0 +1,975 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/topo_server/core/model"
+)
+
+// archiveSystemFields are the fields of the archived instances which are generated by the system,
+// they are removed before the instances are recreated.
+var archiveSystemFields = []string{
+	"_id",
+	common.BKAppIDField,
+	common.BKParentIDField,
+	common.BkSupplierAccount,
+	common.CreateTimeField,
+	common.LastTimeField,
+	common.MetadataField,
+}
+
+/*
+ArchiveBusiness 归档业务
+	- 将业务的拓扑、服务实例、进程、主机属性自动应用规则以及主机与模块的关系保存为归档快照
+	- 将业务下的主机转移到资源池的空闲机模块
+	- 删除业务下除内置集群、模块以外的拓扑实例，并将业务重命名为"foo-archived"，释放原业务名
+*/
+func (b *business) ArchiveBusiness(kit *rest.Kit, obj model.Object, bizID int64, metaData *metadata.Metadata) (*metadata.BusinessArchive, error) {
+	biz, err := b.getBusinessByID(kit, bizID)
+	if err != nil {
+		return nil, err
+	}
+
+	bizName, _ := biz.String(common.BKAppNameField)
+	defaultFlag, _ := util.GetInt64ByInterface(biz[common.BKDefaultField])
+	if defaultFlag == int64(common.DefaultAppFlag) {
+		return nil, kit.CCError.CCError(common.CCErrTopoResourcePoolBusinessCanNotArchive)
+	}
+	if status, _ := biz.String(common.BKDataStatusField); status == string(common.DataStatusDisabled) {
+		return nil, kit.CCError.CCErrorf(common.CCErrTopoBusinessAlreadyArchived, bizID)
+	}
+
+	archive := metadata.BusinessArchive{
+		BizID:   bizID,
+		BizName: bizName,
+	}
+	if archive.Topology, err = b.archiveTopology(kit, bizID); err != nil {
+		return nil, err
+	}
+	if archive.ServiceInstances, err = b.archiveServiceInstances(kit, bizID); err != nil {
+		return nil, err
+	}
+	if archive.HostRelations, err = b.archiveHostRelations(kit, bizID); err != nil {
+		return nil, err
+	}
+	rules, ccErr := b.clientSet.CoreService().HostApplyRule().ListHostApplyRule(kit.Ctx, kit.Header, bizID,
+		metadata.ListHostApplyRuleOption{Page: metadata.BasePage{Limit: common.BKNoLimit}})
+	if ccErr != nil {
+		blog.Errorf("[operation-biz] archive business %d failed, list host apply rules failed, err: %s, rid: %s", bizID, ccErr.Error(), kit.Rid)
+		return nil, ccErr
+	}
+	archive.HostApplyRules = rules.Info
+
+	archive.ArchivedName, err = b.GenerateAchieveBusinessName(kit, bizName)
+	if err != nil {
+		return nil, err
+	}
+	archive, ccErr = b.clientSet.CoreService().BusinessArchive().CreateBusinessArchive(kit.Ctx, kit.Header, archive)
+	if ccErr != nil {
+		blog.Errorf("[operation-biz] archive business %d failed, save archive failed, err: %s, rid: %s", bizID, ccErr.Error(), kit.Rid)
+		return nil, ccErr
+	}
+
+	// move the hosts to the resource pool, so that the topology can be deleted.
+	hostIDs := make([]int64, 0)
+	for _, relation := range archive.HostRelations {
+		hostIDs = append(hostIDs, relation.HostID)
+	}
+	if len(hostIDs) > 0 {
+		poolBizID, idleModuleID, err := b.getResourcePoolIdleModule(kit)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.transferArchivedHosts(kit, bizID, poolBizID, hostIDs, []int64{idleModuleID}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(archive.HostApplyRules) > 0 {
+		ruleIDs := make([]int64, 0)
+		for _, rule := range archive.HostApplyRules {
+			ruleIDs = append(ruleIDs, rule.ID)
+		}
+		option := metadata.DeleteHostApplyRuleOption{RuleIDs: ruleIDs}
+		if ccErr := b.clientSet.CoreService().HostApplyRule().DeleteHostApplyRule(kit.Ctx, kit.Header, bizID, option); ccErr != nil {
+			blog.Errorf("[operation-biz] archive business %d failed, delete host apply rules failed, err: %s, rid: %s", bizID, ccErr.Error(), kit.Rid)
+			return nil, ccErr
+		}
+	}
+
+	if err := b.deleteArchivedTopology(kit, bizID, archive.Topology, metaData); err != nil {
+		return nil, err
+	}
+
+	updateData := mapstr.MapStr{
+		common.BKAppNameField:    archive.ArchivedName,
+		common.BKDataStatusField: string(common.DataStatusDisabled),
+	}
+	if err := b.UpdateBusiness(kit, updateData, obj, bizID, metaData); err != nil {
+		blog.Errorf("[operation-biz] archive business %d failed, update business failed, err: %s, rid: %s", bizID, err.Error(), kit.Rid)
+		return nil, err
+	}
+	return &archive, nil
+}
+
+/*
+RestoreBusiness 使用归档快照恢复业务
+	- 按快照重建拓扑实例，新实例的ID与原ID的对应关系记录在恢复报告中
+	- 仍在资源池中的主机被转移回原模块，服务实例、进程和主机属性自动应用规则随之恢复
+	- 无法按原样恢复的资源不会导致恢复失败，而是作为冲突记录在恢复报告中
+*/
+func (b *business) RestoreBusiness(kit *rest.Kit, obj model.Object, bizID int64, option metadata.RestoreBusinessOption,
+	metaData *metadata.Metadata) (*metadata.BusinessRestoreReport, error) {
+
+	biz, err := b.getBusinessByID(kit, bizID)
+	if err != nil {
+		return nil, err
+	}
+	if status, _ := biz.String(common.BKDataStatusField); status != string(common.DataStatusDisabled) {
+		return nil, kit.CCError.CCErrorf(common.CCErrTopoBusinessNotArchived, bizID)
+	}
+
+	listOption := metadata.ListBusinessArchiveOption{
+		BizIDs: []int64{bizID},
+		Status: []metadata.BusinessArchiveStatus{metadata.BusinessArchived},
+		Page:   metadata.BasePage{Limit: 1},
+	}
+	if option.ArchiveID > 0 {
+		listOption.IDs = []int64{option.ArchiveID}
+	}
+	archives, ccErr := b.clientSet.CoreService().BusinessArchive().ListBusinessArchive(kit.Ctx, kit.Header, listOption)
+	if ccErr != nil {
+		blog.Errorf("[operation-biz] restore business %d failed, find archive failed, err: %s, rid: %s", bizID, ccErr.Error(), kit.Rid)
+		return nil, ccErr
+	}
+	if len(archives.Info) == 0 {
+		blog.Errorf("[operation-biz] restore business %d failed, no archive can be restored, option: %+v, rid: %s", bizID, option, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+	archive := archives.Info[0]
+
+	bizName := option.BizName
+	if len(bizName) == 0 {
+		bizName = archive.BizName
+	}
+	sameNameBizs, err := b.readBusiness(kit, mapstr.MapStr{
+		common.BKAppNameField: bizName,
+		common.BKAppIDField:   mapstr.MapStr{common.BKDBNE: bizID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(sameNameBizs) > 0 {
+		return nil, kit.CCError.CCErrorf(common.CCErrTopoRestoreBusinessNameDuplicated, bizName)
+	}
+
+	updateData := mapstr.MapStr{
+		common.BKAppNameField:    bizName,
+		common.BKDataStatusField: string(common.DataStatusEnable),
+	}
+	if err := b.UpdateBusiness(kit, updateData, obj, bizID, metaData); err != nil {
+		blog.Errorf("[operation-biz] restore business %d failed, update business failed, err: %s, rid: %s", bizID, err.Error(), kit.Rid)
+		return nil, err
+	}
+
+	report := &metadata.BusinessRestoreReport{
+		ArchiveID:  archive.ID,
+		BizID:      bizID,
+		BizName:    bizName,
+		IDMappings: make([]metadata.RestoredIDMapping, 0),
+		Conflicts:  make([]metadata.RestoreConflict, 0),
+	}
+	restorer := &businessRestorer{
+		business: b,
+		kit:      kit,
+		bizID:    bizID,
+		metaData: metaData,
+		report:   report,
+		idMap:    make(map[string]map[int64]int64),
+	}
+	if err := restorer.restoreTopology(archive.Topology); err != nil {
+		return nil, err
+	}
+	restoredHosts, err := restorer.restoreHostRelations(archive.HostRelations)
+	if err != nil {
+		return nil, err
+	}
+	if err := restorer.restoreServiceInstances(archive.ServiceInstances, restoredHosts); err != nil {
+		return nil, err
+	}
+	restorer.restoreHostApplyRules(archive.HostApplyRules)
+
+	updateOption := metadata.UpdateBusinessArchiveOption{
+		Status:        metadata.BusinessRestored,
+		RestoreReport: report,
+	}
+	if _, ccErr := b.clientSet.CoreService().BusinessArchive().UpdateBusinessArchive(kit.Ctx, kit.Header, archive.ID, updateOption); ccErr != nil {
+		blog.Errorf("[operation-biz] restore business %d failed, update archive %d failed, err: %s, rid: %s", bizID, archive.ID, ccErr.Error(), kit.Rid)
+		return nil, ccErr
+	}
+	return report, nil
+}
+
+// readBusiness read the businesses directly, as FindBiz does not find the resource pool business.
+func (b *business) readBusiness(kit *rest.Kit, cond mapstr.MapStr) ([]mapstr.MapStr, error) {
+	query := &metadata.QueryCondition{
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+		Condition: cond,
+	}
+	rsp, err := b.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, common.BKInnerObjIDApp, query)
+	if err != nil {
+		blog.Errorf("[operation-biz] read business failed, cond: %+v, err: %s, rid: %s", cond, err.Error(), kit.Rid)
+		return nil, kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !rsp.Result {
+		return nil, kit.CCError.New(rsp.Code, rsp.ErrMsg)
+	}
+	return rsp.Data.Info, nil
+}
+
+func (b *business) getBusinessByID(kit *rest.Kit, bizID int64) (mapstr.MapStr, error) {
+	bizs, err := b.readBusiness(kit, mapstr.MapStr{common.BKAppIDField: bizID})
+	if err != nil {
+		return nil, err
+	}
+	if len(bizs) == 0 {
+		blog.Errorf("[operation-biz] business %d not found, rid: %s", bizID, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+	return bizs[0], nil
+}
+
+// getResourcePoolIdleModule get the resource pool business and it's idle module.
+func (b *business) getResourcePoolIdleModule(kit *rest.Kit) (int64, int64, error) {
+	bizs, err := b.readBusiness(kit, mapstr.MapStr{common.BKDefaultField: common.DefaultAppFlag})
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(bizs) == 0 {
+		blog.Errorf("[operation-biz] resource pool business not found, rid: %s", kit.Rid)
+		return 0, 0, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+	poolBizID, err := bizs[0].Int64(common.BKAppIDField)
+	if err != nil {
+		return 0, 0, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField)
+	}
+
+	cond := &metadata.QueryCondition{
+		Fields: []string{common.BKModuleIDField},
+		Condition: mapstr.MapStr{
+			common.BKAppIDField:   poolBizID,
+			common.BKDefaultField: common.DefaultResModuleFlag,
+		},
+	}
+	rsp, err := b.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, common.BKInnerObjIDModule, cond)
+	if err != nil {
+		blog.Errorf("[operation-biz] find resource pool idle module failed, err: %s, rid: %s", err.Error(), kit.Rid)
+		return 0, 0, kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !rsp.Result {
+		return 0, 0, kit.CCError.New(rsp.Code, rsp.ErrMsg)
+	}
+	if len(rsp.Data.Info) == 0 {
+		blog.Errorf("[operation-biz] resource pool idle module not found, bizID: %d, rid: %s", poolBizID, kit.Rid)
+		return 0, 0, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+	moduleID, err := rsp.Data.Info[0].Int64(common.BKModuleIDField)
+	if err != nil {
+		return 0, 0, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKModuleIDField)
+	}
+	return poolBizID, moduleID, nil
+}
+
+// transferArchivedHosts transfer the hosts between the archived business and the resource pool.
+func (b *business) transferArchivedHosts(kit *rest.Kit, srcBizID, dstBizID int64, hostIDs, moduleIDs []int64) error {
+	if err := b.authManager.DeregisterHostsByID(kit.Ctx, kit.Header, hostIDs...); err != nil {
+		blog.Errorf("[operation-biz] transfer hosts %v failed, deregister hosts from iam failed, err: %s, rid: %s", hostIDs, err.Error(), kit.Rid)
+		return kit.CCError.Error(common.CCErrCommUnRegistResourceToIAMFailed)
+	}
+
+	option := &metadata.TransferHostsCrossBusinessRequest{
+		SrcApplicationID: srcBizID,
+		DstApplicationID: dstBizID,
+		HostIDArr:        hostIDs,
+		DstModuleIDArr:   moduleIDs,
+	}
+	result, err := b.clientSet.CoreService().Host().TransferToAnotherBusiness(kit.Ctx, kit.Header, option)
+	if err != nil {
+		blog.Errorf("[operation-biz] transfer hosts failed, http do error, option: %+v, err: %s, rid: %s", option, err.Error(), kit.Rid)
+		return kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !result.Result {
+		blog.Errorf("[operation-biz] transfer hosts failed, option: %+v, code: %d, err: %s, rid: %s", option, result.Code, result.ErrMsg, kit.Rid)
+		return kit.CCError.New(result.Code, result.ErrMsg)
+	}
+
+	if err := b.authManager.RegisterHostsByID(kit.Ctx, kit.Header, hostIDs...); err != nil {
+		blog.Errorf("[operation-biz] transfer hosts %v failed, register hosts to iam failed, err: %s, rid: %s", hostIDs, err.Error(), kit.Rid)
+		return kit.CCError.Error(common.CCErrCommRegistResourceToIAMFailed)
+	}
+	return nil
+}
+
+// archiveTopology snapshot the mainline instances of the business, parents are in front of their children.
+func (b *business) archiveTopology(kit *rest.Kit, bizID int64) ([]metadata.ArchivedTopoInstance, error) {
+	root, ccErr := b.clientSet.CoreService().Mainline().SearchMainlineInstanceTopo(kit.Ctx, kit.Header, bizID, true)
+	if ccErr != nil {
+		blog.Errorf("[operation-biz] archive business %d failed, search topology failed, err: %s, rid: %s", bizID, ccErr.Error(), kit.Rid)
+		return nil, ccErr
+	}
+
+	topology := make([]metadata.ArchivedTopoInstance, 0)
+	var walk func(node *metadata.TopoInstanceNode)
+	walk = func(node *metadata.TopoInstanceNode) {
+		for _, child := range node.Children {
+			defaultFlag, _ := util.GetInt64ByInterface(child.Detail[common.BKDefaultField])
+			topology = append(topology, metadata.ArchivedTopoInstance{
+				ObjectID:       child.ObjectID,
+				InstID:         child.InstanceID,
+				ParentObjectID: node.ObjectID,
+				ParentID:       node.InstanceID,
+				Default:        defaultFlag,
+				Data:           child.Detail,
+			})
+			walk(child)
+		}
+	}
+	walk(root)
+	return topology, nil
+}
+
+// archiveServiceInstances snapshot the service instances of the business with their processes.
+func (b *business) archiveServiceInstances(kit *rest.Kit, bizID int64) ([]metadata.ArchivedServiceInstance, error) {
+	listOption := &metadata.ListServiceInstanceOption{
+		BusinessID: bizID,
+		Page:       metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	instances, ccErr := b.clientSet.CoreService().Process().ListServiceInstance(kit.Ctx, kit.Header, listOption)
+	if ccErr != nil {
+		blog.Errorf("[operation-biz] archive business %d failed, list service instances failed, err: %s, rid: %s", bizID, ccErr.Error(), kit.Rid)
+		return nil, ccErr
+	}
+	archived := make([]metadata.ArchivedServiceInstance, 0)
+	if len(instances.Info) == 0 {
+		return archived, nil
+	}
+
+	relationOption := &metadata.ListProcessInstanceRelationOption{
+		BusinessID: bizID,
+		Page:       metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	relations, ccErr := b.clientSet.CoreService().Process().ListProcessInstanceRelation(kit.Ctx, kit.Header, relationOption)
+	if ccErr != nil {
+		blog.Errorf("[operation-biz] archive business %d failed, list process relations failed, err: %s, rid: %s", bizID, ccErr.Error(), kit.Rid)
+		return nil, ccErr
+	}
+
+	processes := make(map[int64]mapstr.MapStr)
+	if len(relations.Info) > 0 {
+		processIDs := make([]int64, 0)
+		for _, relation := range relations.Info {
+			processIDs = append(processIDs, relation.ProcessID)
+		}
+		cond := &metadata.QueryCondition{
+			Page:      metadata.BasePage{Limit: common.BKNoLimit},
+			Condition: mapstr.MapStr{common.BKProcessIDField: mapstr.MapStr{common.BKDBIN: processIDs}},
+		}
+		rsp, err := b.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, common.BKInnerObjIDProc, cond)
+		if err != nil {
+			blog.Errorf("[operation-biz] archive business %d failed, read processes failed, err: %s, rid: %s", bizID, err.Error(), kit.Rid)
+			return nil, kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if !rsp.Result {
+			return nil, kit.CCError.New(rsp.Code, rsp.ErrMsg)
+		}
+		for _, process := range rsp.Data.Info {
+			processID, err := process.Int64(common.BKProcessIDField)
+			if err != nil {
+				blog.Errorf("[operation-biz] archive business %d failed, parse process id failed, process: %+v, rid: %s", bizID, process, kit.Rid)
+				return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKProcessIDField)
+			}
+			processes[processID] = process
+		}
+	}
+
+	instanceProcesses := make(map[int64][]metadata.ArchivedProcess)
+	for _, relation := range relations.Info {
+		instanceProcesses[relation.ServiceInstanceID] = append(instanceProcesses[relation.ServiceInstanceID], metadata.ArchivedProcess{
+			ProcessTemplateID: relation.ProcessTemplateID,
+			Data:              processes[relation.ProcessID],
+		})
+	}
+	for _, instance := range instances.Info {
+		archived = append(archived, metadata.ArchivedServiceInstance{
+			ServiceInstance: instance,
+			Processes:       instanceProcesses[instance.ID],
+		})
+	}
+	return archived, nil
+}
+
+// archiveHostRelations snapshot the modules of each host in the business.
+func (b *business) archiveHostRelations(kit *rest.Kit, bizID int64) ([]metadata.ArchivedHostRelation, error) {
+	option := &metadata.HostModuleRelationRequest{
+		ApplicationID: bizID,
+		Fields:        []string{common.BKHostIDField, common.BKModuleIDField},
+		Page:          metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	rsp, err := b.clientSet.CoreService().Host().GetHostModuleRelation(kit.Ctx, kit.Header, option)
+	if err != nil {
+		blog.Errorf("[operation-biz] archive business %d failed, get host relations failed, err: %s, rid: %s", bizID, err.Error(), kit.Rid)
+		return nil, kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !rsp.Result {
+		return nil, kit.CCError.New(rsp.Code, rsp.ErrMsg)
+	}
+
+	relations := make([]metadata.ArchivedHostRelation, 0)
+	hostIndex := make(map[int64]int)
+	for _, relation := range rsp.Data.Info {
+		index, exist := hostIndex[relation.HostID]
+		if !exist {
+			index = len(relations)
+			hostIndex[relation.HostID] = index
+			relations = append(relations, metadata.ArchivedHostRelation{HostID: relation.HostID})
+		}
+		relations[index].ModuleIDs = append(relations[index].ModuleIDs, relation.ModuleID)
+	}
+	return relations, nil
+}
+
+// deleteArchivedTopology delete the archived topology except the built in set and modules.
+func (b *business) deleteArchivedTopology(kit *rest.Kit, bizID int64, topology []metadata.ArchivedTopoInstance,
+	metaData *metadata.Metadata) error {
+
+	setIDs := make([]int64, 0)
+	for _, instance := range topology {
+		if instance.ObjectID == common.BKInnerObjIDSet && instance.Default == int64(common.DefaultFlagDefaultValue) {
+			setIDs = append(setIDs, instance.InstID)
+		}
+	}
+	if len(setIDs) > 0 {
+		setObj, err := b.obj.FindSingleObject(kit, common.BKInnerObjIDSet, metaData)
+		if err != nil {
+			blog.Errorf("[operation-biz] failed to find the set object, err: %s, rid: %s", err.Error(), kit.Rid)
+			return err
+		}
+		if err := b.set.DeleteSet(kit, setObj, bizID, setIDs, metaData); err != nil {
+			blog.Errorf("[operation-biz] archive business %d failed, delete sets failed, err: %s, rid: %s", bizID, err.Error(), kit.Rid)
+			return err
+		}
+	}
+
+	// delete the custom level instances from the bottom up.
+	objects := make(map[string]model.Object)
+	for index := len(topology) - 1; index >= 0; index-- {
+		instance := topology[index]
+		if instance.ObjectID == common.BKInnerObjIDSet || instance.ObjectID == common.BKInnerObjIDModule {
+			continue
+		}
+		obj, exist := objects[instance.ObjectID]
+		if !exist {
+			var err error
+			obj, err = b.obj.FindSingleObject(kit, instance.ObjectID, metaData)
+			if err != nil {
+				blog.Errorf("[operation-biz] failed to find the object %s, err: %s, rid: %s", instance.ObjectID, err.Error(), kit.Rid)
+				return err
+			}
+			objects[instance.ObjectID] = obj
+		}
+		if err := b.inst.DeleteMainlineInstWithID(kit, obj, instance.InstID); err != nil {
+			blog.Errorf("[operation-biz] archive business %d failed, delete %s instance %d failed, err: %s, rid: %s",
+				bizID, instance.ObjectID, instance.InstID, err.Error(), kit.Rid)
+			return err
+		}
+	}
+	return nil
+}
+
+// businessRestorer restore a business with it's archive, it keeps the mapping between the archived ids and
+// the recreated ids, and records the resources which can not be restored into the report.
+type businessRestorer struct {
+	*business
+	kit      *rest.Kit
+	bizID    int64
+	metaData *metadata.Metadata
+	report   *metadata.BusinessRestoreReport
+	// idMap is object id => archived instance id => current instance id
+	idMap map[string]map[int64]int64
+	// setTemplates is the set template id of the restored sets
+	setTemplates map[int64]int64
+	// templateModules is set id => service template id => module id of the modules created with the set template
+	templateModules map[int64]map[int64]int64
+}
+
+func (r *businessRestorer) addMapping(objID string, originID, currentID int64) {
+	if _, exist := r.idMap[objID]; !exist {
+		r.idMap[objID] = make(map[int64]int64)
+	}
+	r.idMap[objID][originID] = currentID
+	r.report.IDMappings = append(r.report.IDMappings, metadata.RestoredIDMapping{
+		ObjectID:  objID,
+		OriginID:  originID,
+		CurrentID: currentID,
+	})
+}
+
+func (r *businessRestorer) mappedID(objID string, originID int64) (int64, bool) {
+	if objID == common.BKInnerObjIDApp {
+		return r.bizID, true
+	}
+	currentID, exist := r.idMap[objID][originID]
+	return currentID, exist
+}
+
+func (r *businessRestorer) readInstances(objID string, cond mapstr.MapStr) ([]mapstr.MapStr, error) {
+	query := &metadata.QueryCondition{
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+		Condition: cond,
+	}
+	rsp, err := r.clientSet.CoreService().Instance().ReadInstance(r.kit.Ctx, r.kit.Header, objID, query)
+	if err != nil {
+		blog.Errorf("[operation-biz] read %s instances failed, cond: %+v, err: %s, rid: %s", objID, cond, err.Error(), r.kit.Rid)
+		return nil, r.kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !rsp.Result {
+		return nil, r.kit.CCError.New(rsp.Code, rsp.ErrMsg)
+	}
+	return rsp.Data.Info, nil
+}
+
+// restoreTopology recreate the archived mainline instances, the built in set and modules are mapped to
+// the existing ones by their default flag.
+func (r *businessRestorer) restoreTopology(topology []metadata.ArchivedTopoInstance) error {
+	r.setTemplates = make(map[int64]int64)
+	r.templateModules = make(map[int64]map[int64]int64)
+
+	builtIn := make(map[string]map[int64]int64)
+	for _, objID := range []string{common.BKInnerObjIDSet, common.BKInnerObjIDModule} {
+		cond := mapstr.MapStr{
+			common.BKAppIDField:   r.bizID,
+			common.BKDefaultField: mapstr.MapStr{common.BKDBNE: common.DefaultFlagDefaultValue},
+		}
+		instances, err := r.readInstances(objID, cond)
+		if err != nil {
+			return err
+		}
+		builtIn[objID] = make(map[int64]int64)
+		for _, instance := range instances {
+			defaultFlag, _ := util.GetInt64ByInterface(instance[common.BKDefaultField])
+			instID, _ := util.GetInt64ByInterface(instance[common.GetInstIDField(objID)])
+			builtIn[objID][defaultFlag] = instID
+		}
+	}
+
+	objects := make(map[string]model.Object)
+	for _, instance := range topology {
+		name := util.GetStrByInterface(instance.Data[common.GetInstNameField(instance.ObjectID)])
+		parentID, exist := r.mappedID(instance.ParentObjectID, instance.ParentID)
+		if !exist {
+			r.report.AddConflict(instance.ObjectID, instance.InstID, name, "the parent instance is not restored")
+			continue
+		}
+
+		if instance.Default != int64(common.DefaultFlagDefaultValue) {
+			instID, exist := builtIn[instance.ObjectID][instance.Default]
+			if !exist {
+				r.report.AddConflict(instance.ObjectID, instance.InstID, name, "the built in instance does not exist")
+				continue
+			}
+			r.addMapping(instance.ObjectID, instance.InstID, instID)
+			continue
+		}
+
+		obj, exist := objects[instance.ObjectID]
+		if !exist {
+			var err error
+			obj, err = r.obj.FindSingleObject(r.kit, instance.ObjectID, r.metaData)
+			if err != nil {
+				blog.Errorf("[operation-biz] failed to find the object %s, err: %s, rid: %s", instance.ObjectID, err.Error(), r.kit.Rid)
+				return err
+			}
+			objects[instance.ObjectID] = obj
+		}
+
+		instID, reason := r.restoreTopoInstance(obj, instance, parentID)
+		if len(reason) > 0 {
+			r.report.AddConflict(instance.ObjectID, instance.InstID, name, reason)
+			continue
+		}
+		r.addMapping(instance.ObjectID, instance.InstID, instID)
+	}
+	return nil
+}
+
+// restoreTopoInstance recreate an archived instance, returns the reason if it can not be restored.
+func (r *businessRestorer) restoreTopoInstance(obj model.Object, instance metadata.ArchivedTopoInstance, parentID int64) (int64, string) {
+	data := instance.Data.Clone()
+	for _, field := range archiveSystemFields {
+		data.Remove(field)
+	}
+	data.Remove(common.GetInstIDField(instance.ObjectID))
+	data.Set(common.BKParentIDField, parentID)
+
+	switch instance.ObjectID {
+	case common.BKInnerObjIDSet:
+		data.Remove(common.BKSetTemplateVersionField)
+		setTemplateID, _ := util.GetInt64ByInterface(data[common.BKSetTemplateIDField])
+		if setTemplateID != common.SetTemplateIDNotSet {
+			if _, ccErr := r.clientSet.CoreService().SetTemplate().GetSetTemplate(r.kit.Ctx, r.kit.Header, r.bizID, setTemplateID); ccErr != nil {
+				r.report.AddConflict(common.BKInnerObjIDSet, instance.InstID, util.GetStrByInterface(data[common.BKSetNameField]),
+					fmt.Sprintf("set template %d is not found, the set is restored without template", setTemplateID))
+				setTemplateID = common.SetTemplateIDNotSet
+			}
+		}
+		data.Set(common.BKSetTemplateIDField, setTemplateID)
+
+		setInst, err := r.set.CreateSet(r.kit, obj, r.bizID, data, r.metaData)
+		if err != nil {
+			return 0, err.Error()
+		}
+		setID, err := setInst.GetInstID()
+		if err != nil {
+			return 0, err.Error()
+		}
+		r.setTemplates[setID] = setTemplateID
+		if setTemplateID == common.SetTemplateIDNotSet {
+			return setID, ""
+		}
+
+		// the modules of the set template are created with the set, the archived ones are mapped to them.
+		modules, err := r.readInstances(common.BKInnerObjIDModule, mapstr.MapStr{common.BKSetIDField: setID})
+		if err != nil {
+			return 0, err.Error()
+		}
+		r.templateModules[setID] = make(map[int64]int64)
+		for _, module := range modules {
+			serviceTemplateID, _ := util.GetInt64ByInterface(module[common.BKServiceTemplateIDField])
+			moduleID, _ := util.GetInt64ByInterface(module[common.BKModuleIDField])
+			r.templateModules[setID][serviceTemplateID] = moduleID
+		}
+		return setID, ""
+
+	case common.BKInnerObjIDModule:
+		data.Remove(common.BKSetIDField)
+		serviceTemplateID, _ := util.GetInt64ByInterface(data[common.BKServiceTemplateIDField])
+		if moduleID, exist := r.templateModules[parentID][serviceTemplateID]; exist && serviceTemplateID != common.ServiceTemplateIDNotSet {
+			return moduleID, ""
+		}
+		if serviceTemplateID != common.ServiceTemplateIDNotSet {
+			if _, ccErr := r.clientSet.CoreService().Process().GetServiceTemplate(r.kit.Ctx, r.kit.Header, serviceTemplateID); ccErr != nil {
+				r.report.AddConflict(common.BKInnerObjIDModule, instance.InstID, util.GetStrByInterface(data[common.BKModuleNameField]),
+					fmt.Sprintf("service template %d is not found, the module is restored without template", serviceTemplateID))
+				data.Set(common.BKServiceTemplateIDField, common.ServiceTemplateIDNotSet)
+			}
+		}
+		data.Set(common.BKSetTemplateIDField, r.setTemplates[parentID])
+		hostApplyEnabled := data[common.HostApplyEnabledField]
+
+		moduleInst, err := r.module.CreateModule(r.kit, obj, r.bizID, parentID, data)
+		if err != nil {
+			return 0, err.Error()
+		}
+		moduleID, err := moduleInst.GetInstID()
+		if err != nil {
+			return 0, err.Error()
+		}
+		if enabled, ok := hostApplyEnabled.(bool); ok && enabled {
+			option := &metadata.UpdateOption{
+				Data:      mapstr.MapStr{common.HostApplyEnabledField: true},
+				Condition: mapstr.MapStr{common.BKModuleIDField: moduleID},
+			}
+			rsp, err := r.clientSet.CoreService().Instance().UpdateInstance(r.kit.Ctx, r.kit.Header, common.BKInnerObjIDModule, option)
+			if err != nil {
+				return 0, err.Error()
+			}
+			if !rsp.Result {
+				return 0, rsp.ErrMsg
+			}
+		}
+		return moduleID, ""
+
+	default:
+		data.Set(common.BKAppIDField, r.bizID)
+		customInst, err := r.inst.CreateInst(r.kit, obj, data)
+		if err != nil {
+			return 0, err.Error()
+		}
+		instID, err := customInst.GetInstID()
+		if err != nil {
+			return 0, err.Error()
+		}
+		return instID, ""
+	}
+}
+
+// restoreHostRelations transfer the hosts which are still in the resource pool back to their restored modules,
+// returns the restored hosts.
+func (r *businessRestorer) restoreHostRelations(relations []metadata.ArchivedHostRelation) (map[int64]bool, error) {
+	restored := make(map[int64]bool)
+	if len(relations) == 0 {
+		return restored, nil
+	}
+
+	poolBizID, _, err := r.getResourcePoolIdleModule(r.kit)
+	if err != nil {
+		return nil, err
+	}
+	hostIDs := make([]int64, 0)
+	for _, relation := range relations {
+		hostIDs = append(hostIDs, relation.HostID)
+	}
+	option := &metadata.HostModuleRelationRequest{
+		HostIDArr: hostIDs,
+		Fields:    []string{common.BKAppIDField, common.BKHostIDField},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	rsp, err := r.clientSet.CoreService().Host().GetHostModuleRelation(r.kit.Ctx, r.kit.Header, option)
+	if err != nil {
+		blog.Errorf("[operation-biz] restore business %d failed, get host relations failed, err: %s, rid: %s", r.bizID, err.Error(), r.kit.Rid)
+		return nil, r.kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !rsp.Result {
+		return nil, r.kit.CCError.New(rsp.Code, rsp.ErrMsg)
+	}
+	hostBiz := make(map[int64]int64)
+	for _, relation := range rsp.Data.Info {
+		hostBiz[relation.HostID] = relation.AppID
+	}
+
+	// hosts with the same modules are transferred together.
+	groups := make(map[string][]int64)
+	groupModules := make(map[string][]int64)
+	groupKeys := make([]string, 0)
+	for _, relation := range relations {
+		if bizID, exist := hostBiz[relation.HostID]; !exist || bizID != poolBizID {
+			r.report.AddConflict(common.BKInnerObjIDHost, relation.HostID, "", "the host is not in the resource pool")
+			continue
+		}
+		moduleIDs := make([]int64, 0)
+		for _, originID := range relation.ModuleIDs {
+			if moduleID, exist := r.mappedID(common.BKInnerObjIDModule, originID); exist {
+				moduleIDs = append(moduleIDs, moduleID)
+			}
+		}
+		if len(moduleIDs) == 0 {
+			r.report.AddConflict(common.BKInnerObjIDHost, relation.HostID, "", "none of the host's modules is restored")
+			continue
+		}
+		if len(moduleIDs) != len(relation.ModuleIDs) {
+			r.report.AddConflict(common.BKInnerObjIDHost, relation.HostID, "", "some of the host's modules are not restored")
+		}
+
+		sort.Slice(moduleIDs, func(i, j int) bool { return moduleIDs[i] < moduleIDs[j] })
+		key := strings.Trim(fmt.Sprint(moduleIDs), "[]")
+		if _, exist := groups[key]; !exist {
+			groupKeys = append(groupKeys, key)
+			groupModules[key] = moduleIDs
+		}
+		groups[key] = append(groups[key], relation.HostID)
+	}
+
+	for _, key := range groupKeys {
+		if err := r.transferArchivedHosts(r.kit, poolBizID, r.bizID, groups[key], groupModules[key]); err != nil {
+			return nil, err
+		}
+		for _, hostID := range groups[key] {
+			restored[hostID] = true
+		}
+	}
+	return restored, nil
+}
+
+// restoreServiceInstances restore the service instances of the restored hosts, the instances of the template
+// modules are created when the hosts are transferred, so only their processes are restored.
+func (r *businessRestorer) restoreServiceInstances(instances []metadata.ArchivedServiceInstance, restoredHosts map[int64]bool) error {
+	if len(instances) == 0 {
+		return nil
+	}
+
+	listOption := &metadata.ListServiceInstanceOption{
+		BusinessID: r.bizID,
+		Page:       metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	current, ccErr := r.clientSet.CoreService().Process().ListServiceInstance(r.kit.Ctx, r.kit.Header, listOption)
+	if ccErr != nil {
+		blog.Errorf("[operation-biz] restore business %d failed, list service instances failed, err: %s, rid: %s", r.bizID, ccErr.Error(), r.kit.Rid)
+		return ccErr
+	}
+	templateInstances := make(map[string]metadata.ServiceInstance)
+	for _, instance := range current.Info {
+		templateInstances[fmt.Sprintf("%d:%d:%d", instance.HostID, instance.ModuleID, instance.ServiceTemplateID)] = instance
+	}
+
+	for _, archived := range instances {
+		if !restoredHosts[archived.HostID] {
+			r.report.AddConflict(metadata.RestoreResourceServiceInstance, archived.ID, archived.Name, "the host is not restored")
+			continue
+		}
+		moduleID, exist := r.mappedID(common.BKInnerObjIDModule, archived.ModuleID)
+		if !exist {
+			r.report.AddConflict(metadata.RestoreResourceServiceInstance, archived.ID, archived.Name, "the module is not restored")
+			continue
+		}
+
+		if archived.ServiceTemplateID != common.ServiceTemplateIDNotSet {
+			instance, exist := templateInstances[fmt.Sprintf("%d:%d:%d", archived.HostID, moduleID, archived.ServiceTemplateID)]
+			if !exist {
+				r.report.AddConflict(metadata.RestoreResourceServiceInstance, archived.ID, archived.Name,
+					"the service instance is not created by the service template")
+				continue
+			}
+			if err := r.restoreTemplateProcesses(archived, instance); err != nil {
+				return err
+			}
+			continue
+		}
+
+		instance := &metadata.ServiceInstance{
+			BizID:    r.bizID,
+			Name:     archived.Name,
+			Labels:   archived.Labels,
+			HostID:   archived.HostID,
+			ModuleID: moduleID,
+		}
+		instance, ccErr := r.clientSet.CoreService().Process().CreateServiceInstance(r.kit.Ctx, r.kit.Header, instance)
+		if ccErr != nil {
+			r.report.AddConflict(metadata.RestoreResourceServiceInstance, archived.ID, archived.Name, ccErr.Error())
+			continue
+		}
+		r.addMapping(metadata.RestoreResourceServiceInstance, archived.ID, instance.ID)
+		for _, process := range archived.Processes {
+			if err := r.restoreProcess(instance, process); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restoreTemplateProcesses update the processes created by template with the archived process data.
+func (r *businessRestorer) restoreTemplateProcesses(archived metadata.ArchivedServiceInstance, instance metadata.ServiceInstance) error {
+	r.addMapping(metadata.RestoreResourceServiceInstance, archived.ID, instance.ID)
+
+	option := &metadata.ListProcessInstanceRelationOption{
+		BusinessID:         r.bizID,
+		ServiceInstanceIDs: []int64{instance.ID},
+		Page:               metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	relations, ccErr := r.clientSet.CoreService().Process().ListProcessInstanceRelation(r.kit.Ctx, r.kit.Header, option)
+	if ccErr != nil {
+		blog.Errorf("[operation-biz] restore business %d failed, list process relations failed, err: %s, rid: %s", r.bizID, ccErr.Error(), r.kit.Rid)
+		return ccErr
+	}
+	templateProcesses := make(map[int64]int64)
+	for _, relation := range relations.Info {
+		templateProcesses[relation.ProcessTemplateID] = relation.ProcessID
+	}
+
+	for _, process := range archived.Processes {
+		processID, exist := templateProcesses[process.ProcessTemplateID]
+		if !exist {
+			continue
+		}
+		data := process.Data.Clone()
+		for _, field := range archiveSystemFields {
+			data.Remove(field)
+		}
+		data.Remove(common.BKProcessIDField)
+		updateOption := &metadata.UpdateOption{
+			Data:      data,
+			Condition: mapstr.MapStr{common.BKProcessIDField: processID},
+		}
+		rsp, err := r.clientSet.CoreService().Instance().UpdateInstance(r.kit.Ctx, r.kit.Header, common.BKInnerObjIDProc, updateOption)
+		if err != nil {
+			blog.Errorf("[operation-biz] restore process %d failed, err: %s, rid: %s", processID, err.Error(), r.kit.Rid)
+			return r.kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if !rsp.Result {
+			originID, _ := util.GetInt64ByInterface(process.Data[common.BKProcessIDField])
+			r.report.AddConflict(common.BKInnerObjIDProc, originID, util.GetStrByInterface(process.Data[common.BKProcessNameField]), rsp.ErrMsg)
+		}
+	}
+	return nil
+}
+
+// restoreProcess recreate an archived process which is not created by template.
+func (r *businessRestorer) restoreProcess(instance *metadata.ServiceInstance, process metadata.ArchivedProcess) error {
+	originID, _ := util.GetInt64ByInterface(process.Data[common.BKProcessIDField])
+	name := util.GetStrByInterface(process.Data[common.BKProcessNameField])
+
+	data := process.Data.Clone()
+	for _, field := range archiveSystemFields {
+		data.Remove(field)
+	}
+	data.Remove(common.BKProcessIDField)
+	data.Set(common.BKAppIDField, r.bizID)
+	data.Set(common.BkSupplierAccount, r.kit.SupplierAccount)
+	rsp, err := r.clientSet.CoreService().Instance().CreateInstance(r.kit.Ctx, r.kit.Header, common.BKInnerObjIDProc,
+		&metadata.CreateModelInstance{Data: data})
+	if err != nil {
+		blog.Errorf("[operation-biz] restore process %d failed, err: %s, rid: %s", originID, err.Error(), r.kit.Rid)
+		return r.kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !rsp.Result {
+		r.report.AddConflict(common.BKInnerObjIDProc, originID, name, rsp.ErrMsg)
+		return nil
+	}
+
+	relation := &metadata.ProcessInstanceRelation{
+		BizID:             r.bizID,
+		ProcessID:         int64(rsp.Data.Created.ID),
+		ServiceInstanceID: instance.ID,
+		ProcessTemplateID: common.ServiceTemplateIDNotSet,
+		HostID:            instance.HostID,
+		SupplierAccount:   r.kit.SupplierAccount,
+	}
+	if _, ccErr := r.clientSet.CoreService().Process().CreateProcessInstanceRelation(r.kit.Ctx, r.kit.Header, relation); ccErr != nil {
+		blog.Errorf("[operation-biz] restore process %d failed, create relation failed, err: %s, rid: %s", originID, ccErr.Error(), r.kit.Rid)
+		return ccErr
+	}
+	r.addMapping(common.BKInnerObjIDProc, originID, relation.ProcessID)
+	return nil
+}
+
+// restoreHostApplyRules recreate the host apply rules on the restored modules.
+func (r *businessRestorer) restoreHostApplyRules(rules []metadata.HostApplyRule) {
+	for _, rule := range rules {
+		moduleID, exist := r.mappedID(common.BKInnerObjIDModule, rule.ModuleID)
+		if !exist {
+			r.report.AddConflict(metadata.RestoreResourceHostApplyRule, rule.ID, "", "the module is not restored")
+			continue
+		}
+		option := metadata.CreateHostApplyRuleOption{
+			AttributeID:   rule.AttributeID,
+			ModuleID:      moduleID,
+			PropertyValue: rule.PropertyValue,
+		}
+		created, ccErr := r.clientSet.CoreService().HostApplyRule().CreateHostApplyRule(r.kit.Ctx, r.kit.Header, r.bizID, option)
+		if ccErr != nil {
+			r.report.AddConflict(metadata.RestoreResourceHostApplyRule, rule.ID, "", ccErr.Error())
+			continue
+		}
+		r.addMapping(metadata.RestoreResourceHostApplyRule, rule.ID, created.ID)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package settemplate
+
+import (
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// InstantiateSetTemplate dispatches a task which creates the sets of the option from the set template, each set
+// is created with its modules in a sub task, so the result of each set can be found in the task detail.
+func (st *setTemplate) InstantiateSetTemplate(kit *rest.Kit, bizID int64, setTemplateID int64,
+	option metadata.InstantiateSetTemplateOption) (metadata.APITaskDetail, errors.CCErrorCoder) {
+
+	taskDetail := metadata.APITaskDetail{}
+	if key, err := option.Validate(); err != nil {
+		blog.Errorf("InstantiateSetTemplate failed, option invalid, option: %+v, err: %v, rid: %s", option, err, kit.Rid)
+		return taskDetail, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	if _, err := st.client.CoreService().SetTemplate().GetSetTemplate(kit.Ctx, kit.Header, bizID, setTemplateID); err != nil {
+		blog.Errorf("InstantiateSetTemplate failed, GetSetTemplate failed, bizID: %d, setTemplateID: %d, err: %v, rid: %s",
+			bizID, setTemplateID, err, kit.Rid)
+		return taskDetail, err
+	}
+
+	parentID := option.ParentID
+	if parentID == 0 {
+		parentID = bizID
+	}
+
+	// the set name is unique under the same parent, check it before dispatching the task so that the user
+	// gets the error at once rather than find all the sub tasks failed.
+	names := option.SetNames()
+	filter := &metadata.QueryCondition{
+		Fields: []string{common.BKSetNameField},
+		Page:   metadata.BasePage{Limit: common.BKNoLimit},
+		Condition: mapstr.MapStr{
+			common.BKAppIDField:    bizID,
+			common.BKParentIDField: parentID,
+			common.BKSetNameField:  mapstr.MapStr{common.BKDBIN: names},
+		},
+	}
+	setResult, err := st.client.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, common.BKInnerObjIDSet, filter)
+	if err != nil {
+		blog.Errorf("InstantiateSetTemplate failed, ReadInstance of set failed, option: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return taskDetail, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if ccErr := setResult.CCError(); ccErr != nil {
+		blog.Errorf("InstantiateSetTemplate failed, ReadInstance of set failed, option: %+v, err: %v, rid: %s", filter, ccErr, kit.Rid)
+		return taskDetail, ccErr
+	}
+	if len(setResult.Data.Info) > 0 {
+		existNames := make([]string, 0)
+		for _, set := range setResult.Data.Info {
+			existNames = append(existNames, util.GetStrByInterface(set[common.BKSetNameField]))
+		}
+		blog.Errorf("InstantiateSetTemplate failed, sets %v already exist, rid: %s", existNames, kit.Rid)
+		return taskDetail, kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, strings.Join(existNames, ","))
+	}
+
+	tasks := make([]interface{}, 0)
+	for _, name := range names {
+		tasks = append(tasks, metadata.InstantiateSetTask{
+			Header:        kit.Header,
+			BizID:         bizID,
+			SetTemplateID: setTemplateID,
+			ParentID:      parentID,
+			Set:           option.SetData(name),
+		})
+	}
+
+	indexKey := metadata.GetSetTemplateInstantiateIndex(bizID, setTemplateID)
+	createTaskResult, e := st.client.TaskServer().Task().Create(kit.Ctx, kit.Header, common.InstantiateSetTaskName, indexKey, tasks)
+	if e != nil {
+		blog.Errorf("dispatch set template instantiate task failed, option: %+v, err: %v, rid: %s", option, e, kit.Rid)
+		return taskDetail, errors.CCHttpError
+	}
+	if ccErr := createTaskResult.CCError(); ccErr != nil {
+		blog.ErrorJSON("dispatch set template instantiate task failed, option: %s, result: %s, rid: %s", option, createTaskResult, kit.Rid)
+		return taskDetail, ccErr
+	}
+	blog.Infof("dispatch set template instantiate task success, bizID: %d, setTemplateID: %d, task: %s, rid: %s",
+		bizID, setTemplateID, createTaskResult.Data.TaskID, kit.Rid)
+	return createTaskResult.Data, nil
+}
+
+// GetInstantiateTaskDetail get the detail of the set template instantiation task, the response of each sub task
+// is the result of the set.
+func (st *setTemplate) GetInstantiateTaskDetail(kit *rest.Kit, bizID int64, setTemplateID int64, taskID string) (
+	*metadata.APITaskDetail, errors.CCErrorCoder) {
+
+	result, err := st.client.TaskServer().Task().TaskDetail(kit.Ctx, kit.Header, taskID)
+	if err != nil {
+		blog.Errorf("GetInstantiateTaskDetail failed, get task %s failed, err: %v, rid: %s", taskID, err, kit.Rid)
+		return nil, errors.CCHttpError
+	}
+	if ccErr := result.CCError(); ccErr != nil {
+		blog.Errorf("GetInstantiateTaskDetail failed, get task %s failed, err: %v, rid: %s", taskID, ccErr, kit.Rid)
+		return nil, ccErr
+	}
+
+	// the task must belong to the set template of the business
+	taskDetail := &result.Data.Info
+	if taskDetail.Name != common.InstantiateSetTaskName ||
+		taskDetail.Flag != metadata.GetSetTemplateInstantiateIndex(bizID, setTemplateID) {
+		return nil, kit.CCError.CCError(common.CCErrTaskNotFound)
+	}
+	clearSetSyncTaskDetail(taskDetail)
+	return taskDetail, nil
+}
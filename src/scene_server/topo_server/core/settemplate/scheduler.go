@@ -0,0 +1,130 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package settemplate
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/apimachinery/discovery"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/robfig/cron"
+)
+
+const syncSchedulerInterval = time.Minute
+
+// SyncScheduler drives the automatic and cron sync policies of set templates, it only works on the master
+// topo server. Every round it triggers the cron policies whose schedule is reached, then synchronize the
+// pending sync runs in batches.
+type SyncScheduler struct {
+	client      apimachinery.ClientSetInterface
+	setTemplate SetTemplate
+	isMaster    discovery.ServiceManageInterface
+}
+
+func NewSyncScheduler(client apimachinery.ClientSetInterface, setTemplate SetTemplate,
+	isMaster discovery.ServiceManageInterface) *SyncScheduler {
+
+	return &SyncScheduler{
+		client:      client,
+		setTemplate: setTemplate,
+		isMaster:    isMaster,
+	}
+}
+
+// Run the scheduler until the context is done
+func (s *SyncScheduler) Run(ctx context.Context) {
+	blog.Info("start set template sync policy scheduler")
+	ticker := time.NewTicker(syncSchedulerInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			blog.Info("set template sync policy scheduler stopped")
+			return
+		case now := <-ticker.C:
+			if !s.isMaster.IsMaster() {
+				last = now
+				continue
+			}
+			s.schedule(last, now)
+			last = now
+		}
+	}
+}
+
+func (s *SyncScheduler) schedule(last, now time.Time) {
+	// list the sync policies of all the supplier accounts, the super owner is not scoped to a tenant.
+	kit := newSchedulerKit(common.BKSuperOwnerID)
+	option := metadata.ListSetTemplateSyncPolicyOption{
+		Types: []metadata.SyncPolicyType{metadata.SyncPolicyAuto, metadata.SyncPolicyCron},
+	}
+	setTemplates, err := s.client.CoreService().SetTemplate().ListSetTemplateSyncPolicy(kit.Ctx, kit.Header, option)
+	if err != nil {
+		blog.Errorf("list set template sync policies failed, err: %v, rid: %s", err, kit.Rid)
+		return
+	}
+
+	for _, setTemplate := range setTemplates {
+		policy := setTemplate.SyncPolicy
+		if policy == nil || policy.Paused {
+			continue
+		}
+
+		kit := newSchedulerKit(setTemplate.SupplierAccount)
+		if policy.Type == metadata.SyncPolicyCron && !policy.Pending {
+			schedule, err := cron.ParseStandard(policy.Cron)
+			if err != nil {
+				blog.Errorf("parse set template %d sync cron %s failed, err: %v, rid: %s", setTemplate.ID,
+					policy.Cron, err, kit.Rid)
+				continue
+			}
+			if schedule.Next(last).After(now) {
+				continue
+			}
+			setTemplate, err = s.setTemplate.TriggerSync(kit, setTemplate, metadata.SyncTriggerCron)
+			if err != nil {
+				continue
+			}
+		}
+
+		if err := s.setTemplate.RunSyncPolicy(kit, setTemplate); err != nil {
+			blog.Errorf("run set template %d sync policy failed, err: %v, rid: %s", setTemplate.ID, err, kit.Rid)
+		}
+	}
+}
+
+func newSchedulerKit(supplierAccount string) *rest.Kit {
+	header := make(http.Header)
+	header.Set(common.BKHTTPHeaderUser, common.CCSystemOperatorUserName)
+	header.Set(common.BKHTTPOwnerID, supplierAccount)
+	header.Set(common.BKHTTPCCRequestID, util.GenerateRID())
+
+	return &rest.Kit{
+		Rid:             util.GetHTTPCCRequestID(header),
+		Header:          header,
+		Ctx:             util.NewContextFromHTTPHeader(header),
+		CCError:         util.GetDefaultCCError(header),
+		User:            common.CCSystemOperatorUserName,
+		SupplierAccount: supplierAccount,
+	}
+}
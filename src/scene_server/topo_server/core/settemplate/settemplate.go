@@ -34,6 +34,12 @@ type SetTemplate interface {
 	UpdateSetSyncStatus(kit *rest.Kit, setID int64) (metadata.SetTemplateSyncStatus, errors.CCErrorCoder)
 	GetLatestSyncTaskDetail(kit *rest.Kit, setID int64) (*metadata.APITaskDetail, errors.CCErrorCoder)
 	CheckSetInstUpdateToDateStatus(kit *rest.Kit, bizID int64, setTemplateID int64) (metadata.SetTemplateUpdateToDateStatus, errors.CCErrorCoder)
+	InstantiateSetTemplate(kit *rest.Kit, bizID int64, setTemplateID int64, option metadata.InstantiateSetTemplateOption) (metadata.APITaskDetail, errors.CCErrorCoder)
+	GetInstantiateTaskDetail(kit *rest.Kit, bizID int64, setTemplateID int64, taskID string) (*metadata.APITaskDetail, errors.CCErrorCoder)
+	UpdateSyncPolicy(kit *rest.Kit, bizID int64, setTemplateID int64, option metadata.UpdateSetTemplateSyncPolicyOption) (metadata.SetTemplate, errors.CCErrorCoder)
+	TriggerSync(kit *rest.Kit, setTemplate metadata.SetTemplate, triggerType metadata.SyncTriggerType) (metadata.SetTemplate, errors.CCErrorCoder)
+	RunSyncPolicy(kit *rest.Kit, setTemplate metadata.SetTemplate) errors.CCErrorCoder
+	CancelSyncSetTplToInst(kit *rest.Kit, bizID int64, setTemplateID int64, setIDs []int64) errors.CCErrorCoder
 }
 
 func NewSetTemplate(client apimachinery.ClientSetInterface) SetTemplate {
@@ -186,6 +192,11 @@ func (st *setTemplate) SyncSetTplToInst(kit *rest.Kit, bizID int64, setTemplateI
 		return err
 	}
 
+	triggerType := option.TriggerType
+	if len(triggerType) == 0 {
+		triggerType = metadata.SyncTriggerManual
+	}
+
 	for _, setDiff := range setDiffs {
 		indexKey := metadata.GetSetTemplateSyncIndex(setDiff.SetID)
 		blog.V(3).Infof("dispatch synchronize task on set [%s](%d), rid: %s", setDiff.SetDetail.SetName, setDiff.SetID, rid)
@@ -197,6 +208,7 @@ func (st *setTemplate) SyncSetTplToInst(kit *rest.Kit, bizID int64, setTemplateI
 				ModuleDiff:         moduleDiff,
 				SetTopoPath:        setDiff.TopoPath,
 				SetTemplateVersion: setDiff.SetTemplateVersion,
+				TriggerType:        triggerType,
 			}
 			tasks = append(tasks, task)
 		}
@@ -257,7 +269,27 @@ func (st *setTemplate) DispatchTask4ModuleSync(ctx context.Context, header http.
 	for _, task := range tasks {
 		tasksData = append(tasksData, task)
 	}
-	createTaskResult, err := st.client.TaskServer().Task().Create(ctx, header, common.SyncSetTaskName, indexKey, tasksData)
+
+	// sync requested by users goes before the ones triggered by the sync policy scheduler
+	priority := metadata.TaskPriorityHigh
+	if len(tasks) > 0 && tasks[0].TriggerType != metadata.SyncTriggerManual {
+		priority = metadata.TaskPriorityLow
+	}
+
+	// the unfinished sync tasks of the same set must be executed before this one
+	unfinishedTaskIDs, ccErr := st.listUnfinishedSyncTaskIDs(ctx, header, indexKey)
+	if ccErr != nil {
+		return taskDetail, ccErr
+	}
+
+	createOption := &metadata.CreateTaskRequest{
+		Name:      common.SyncSetTaskName,
+		Flag:      indexKey,
+		Data:      tasksData,
+		Priority:  priority,
+		DependsOn: unfinishedTaskIDs,
+	}
+	createTaskResult, err := st.client.TaskServer().Task().CreateTask(ctx, header, createOption)
 	if err != nil {
 		blog.ErrorJSON("dispatch synchronize task failed, task: %s, err: %s, rid: %s", tasks, err.Error(), rid)
 		return taskDetail, errors.CCHttpError
@@ -271,6 +303,73 @@ func (st *setTemplate) DispatchTask4ModuleSync(ctx context.Context, header http.
 	return taskDetail, nil
 }
 
+// listUnfinishedSyncTaskIDs list the unfinished sync tasks with the index key
+func (st *setTemplate) listUnfinishedSyncTaskIDs(ctx context.Context, header http.Header, indexKey string) ([]string, errors.CCErrorCoder) {
+	rid := util.GetHTTPCCRequestID(header)
+	listTaskOption := &metadata.ListAPITaskRequest{
+		Condition: mapstr.MapStr{
+			"flag": indexKey,
+			common.BKStatusField: map[string]interface{}{
+				common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusNew, metadata.APITaskStatusWaitExecute,
+					metadata.APITaskStatusWaitDependency, metadata.APITaskStatuExecute},
+			},
+		},
+		Page: metadata.BasePage{
+			Limit: common.BKMaxPageSize,
+		},
+	}
+	listResult, err := st.client.TaskServer().Task().ListTask(ctx, header, common.SyncSetTaskName, listTaskOption)
+	if err != nil {
+		blog.ErrorJSON("list unfinished set sync tasks failed, option: %s, err: %s, rid: %s", listTaskOption, err.Error(), rid)
+		return nil, errors.CCHttpError
+	}
+	if ccErr := listResult.CCError(); ccErr != nil {
+		blog.ErrorJSON("list unfinished set sync tasks failed, option: %s, result: %s, rid: %s", listTaskOption, listResult, rid)
+		return nil, ccErr
+	}
+
+	taskIDs := make([]string, 0)
+	for _, task := range listResult.Data.Info {
+		taskIDs = append(taskIDs, task.TaskID)
+	}
+	return taskIDs, nil
+}
+
+// CancelSyncSetTplToInst cancel the unfinished sync tasks of the sets, the modules already synchronized are kept
+func (st *setTemplate) CancelSyncSetTplToInst(kit *rest.Kit, bizID int64, setTemplateID int64, setIDs []int64) errors.CCErrorCoder {
+	for _, setID := range setIDs {
+		set, ccErr := st.GetOneSet(kit, setID)
+		if ccErr != nil {
+			return ccErr
+		}
+		if set.BizID != bizID || set.SetTemplateID != setTemplateID {
+			blog.Errorf("CancelSyncSetTplToInst failed, set %d not belongs to set template %d of biz %d, rid: %s", setID, setTemplateID, bizID, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "bk_set_ids")
+		}
+
+		taskIDs, ccErr := st.listUnfinishedSyncTaskIDs(kit.Ctx, kit.Header, metadata.GetSetTemplateSyncIndex(setID))
+		if ccErr != nil {
+			return ccErr
+		}
+		for _, taskID := range taskIDs {
+			result, err := st.client.TaskServer().Task().Cancel(kit.Ctx, kit.Header, taskID)
+			if err != nil {
+				blog.Errorf("CancelSyncSetTplToInst failed, cancel task %s failed, err: %v, rid: %s", taskID, err, kit.Rid)
+				return kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+			}
+			if ccErr := result.CCError(); ccErr != nil {
+				blog.Errorf("CancelSyncSetTplToInst failed, cancel task %s failed, result: %+v, rid: %s", taskID, result, kit.Rid)
+				return ccErr
+			}
+		}
+
+		if _, ccErr := st.UpdateSetSyncStatus(kit, setID); ccErr != nil {
+			return ccErr
+		}
+	}
+	return nil
+}
+
 // DiffServiceTemplateWithModules diff modules with template in one set
 func DiffServiceTemplateWithModules(serviceTemplates []metadata.ServiceTemplate, modules []metadata.ModuleInst) []metadata.SetModuleDiff {
 	svcTplMap := make(map[int64]metadata.ServiceTemplate)
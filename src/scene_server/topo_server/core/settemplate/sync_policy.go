@@ -0,0 +1,190 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package settemplate
+
+import (
+	"fmt"
+	"time"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// UpdateSyncPolicy change the sync policy settings of the set template, the state of a pending sync run is kept
+// unless the policy is changed to manual, resuming a paused policy clears the pause reason.
+func (st *setTemplate) UpdateSyncPolicy(kit *rest.Kit, bizID int64, setTemplateID int64,
+	option metadata.UpdateSetTemplateSyncPolicyOption) (metadata.SetTemplate, errors.CCErrorCoder) {
+
+	setTemplate, ccErr := st.client.CoreService().SetTemplate().GetSetTemplate(kit.Ctx, kit.Header, bizID, setTemplateID)
+	if ccErr != nil {
+		blog.Errorf("UpdateSyncPolicy failed, GetSetTemplate failed, bizID: %d, setTemplateID: %d, err: %v, rid: %s",
+			bizID, setTemplateID, ccErr, kit.Rid)
+		return setTemplate, ccErr
+	}
+
+	policy := metadata.SetTemplateSyncPolicy{}
+	if setTemplate.SyncPolicy != nil {
+		policy = *setTemplate.SyncPolicy
+	}
+	policy.Type = option.Type
+	policy.Cron = option.Cron
+	policy.MaintenanceWindow = option.MaintenanceWindow
+	policy.MaxConcurrency = option.MaxConcurrency
+	if policy.Paused && !option.Paused {
+		policy.PauseReason = ""
+	}
+	policy.Paused = option.Paused
+	if policy.Type == metadata.SyncPolicyManual {
+		policy.Pending = false
+	}
+
+	return st.client.CoreService().SetTemplate().UpdateSetTemplateSyncPolicy(kit.Ctx, kit.Header, bizID,
+		setTemplateID, policy)
+}
+
+// TriggerSync start a sync run of the set template's sync policy, the out of date sets are synchronized
+// by RunSyncPolicy in batches.
+func (st *setTemplate) TriggerSync(kit *rest.Kit, setTemplate metadata.SetTemplate,
+	triggerType metadata.SyncTriggerType) (metadata.SetTemplate, errors.CCErrorCoder) {
+
+	if setTemplate.SyncPolicy == nil || setTemplate.SyncPolicy.Paused {
+		return setTemplate, nil
+	}
+
+	now := time.Now()
+	policy := *setTemplate.SyncPolicy
+	policy.Pending = true
+	policy.TriggerType = triggerType
+	policy.LastTriggerTime = &now
+
+	updated, ccErr := st.client.CoreService().SetTemplate().UpdateSetTemplateSyncPolicy(kit.Ctx, kit.Header,
+		setTemplate.BizID, setTemplate.ID, policy)
+	if ccErr != nil {
+		blog.Errorf("trigger set template %d sync failed, err: %v, rid: %s", setTemplate.ID, ccErr, kit.Rid)
+		return setTemplate, ccErr
+	}
+	blog.Infof("trigger set template %d sync by %s, rid: %s", setTemplate.ID, triggerType, kit.Rid)
+	return updated, nil
+}
+
+// RunSyncPolicy synchronize the out of date sets of the set template with a pending sync run, at most max concurrency
+// sets are synchronized at the same time and only in the maintenance window. A failed synchronization of the run
+// pauses the policy, the run finishes once all the sets are up to date.
+func (st *setTemplate) RunSyncPolicy(kit *rest.Kit, setTemplate metadata.SetTemplate) errors.CCErrorCoder {
+	policy := setTemplate.SyncPolicy
+	if policy == nil || !policy.Pending || policy.Paused {
+		return nil
+	}
+	if !policy.InMaintenanceWindow(time.Now()) {
+		blog.V(4).Infof("set template %d is not in maintenance window, skip sync, rid: %s", setTemplate.ID, kit.Rid)
+		return nil
+	}
+
+	statusOption := metadata.ListSetTemplateSyncStatusOption{
+		BizID:         setTemplate.BizID,
+		SetTemplateID: setTemplate.ID,
+	}
+	statuses, ccErr := st.client.CoreService().SetTemplate().ListSetTemplateSyncStatus(kit.Ctx, kit.Header,
+		setTemplate.BizID, statusOption)
+	if ccErr != nil {
+		blog.Errorf("RunSyncPolicy failed, ListSetTemplateSyncStatus failed, option: %+v, err: %v, rid: %s",
+			statusOption, ccErr, kit.Rid)
+		return ccErr
+	}
+
+	syncing := make(map[int64]bool)
+	for _, status := range statuses.Info {
+		if status.Status == metadata.SyncStatusSyncing {
+			// refresh the status in case the polling of the dispatched task is lost
+			latest, ccErr := st.UpdateSetSyncStatus(kit, status.SetID)
+			if ccErr != nil {
+				return ccErr
+			}
+			status = latest
+		}
+
+		switch status.Status {
+		case metadata.SyncStatusSyncing:
+			syncing[status.SetID] = true
+		case metadata.SyncStatusFailure:
+			if status.TriggerType == metadata.SyncTriggerManual || policy.LastTriggerTime == nil ||
+				status.LastTime.Before(*policy.LastTriggerTime) {
+				continue
+			}
+			reason := fmt.Sprintf("synchronize set %s(%d) failed, task id: %s", status.Name, status.SetID, status.TaskID)
+			return st.pauseSyncPolicy(kit, setTemplate, reason)
+		}
+	}
+
+	upToDateStatus, ccErr := st.CheckSetInstUpdateToDateStatus(kit, setTemplate.BizID, setTemplate.ID)
+	if ccErr != nil {
+		return ccErr
+	}
+	setIDs := make([]int64, 0)
+	for _, set := range upToDateStatus.Sets {
+		if set.NeedSync && !syncing[set.SetID] {
+			setIDs = append(setIDs, set.SetID)
+		}
+	}
+
+	if len(setIDs) == 0 && len(syncing) == 0 {
+		finished := *policy
+		finished.Pending = false
+		if _, ccErr := st.client.CoreService().SetTemplate().UpdateSetTemplateSyncPolicy(kit.Ctx, kit.Header,
+			setTemplate.BizID, setTemplate.ID, finished); ccErr != nil {
+			blog.Errorf("finish set template %d sync run failed, err: %v, rid: %s", setTemplate.ID, ccErr, kit.Rid)
+			return ccErr
+		}
+		blog.Infof("set template %d sync run triggered by %s finished, rid: %s", setTemplate.ID, policy.TriggerType,
+			kit.Rid)
+		return nil
+	}
+
+	available := int(policy.GetMaxConcurrency()) - len(syncing)
+	if available <= 0 || len(setIDs) == 0 {
+		return nil
+	}
+	if len(setIDs) > available {
+		setIDs = setIDs[:available]
+	}
+
+	syncOption := metadata.SyncSetTplToInstOption{
+		SetIDs:      setIDs,
+		TriggerType: policy.TriggerType,
+	}
+	if ccErr := st.SyncSetTplToInst(kit, setTemplate.BizID, setTemplate.ID, syncOption); ccErr != nil {
+		blog.Errorf("RunSyncPolicy failed, sync set template %d to sets %v failed, err: %v, rid: %s", setTemplate.ID,
+			setIDs, ccErr, kit.Rid)
+		return st.pauseSyncPolicy(kit, setTemplate, ccErr.Error())
+	}
+	return nil
+}
+
+// pauseSyncPolicy stop the sync run and pause the sync policy, core service pushes an event of the paused policy
+func (st *setTemplate) pauseSyncPolicy(kit *rest.Kit, setTemplate metadata.SetTemplate, reason string) errors.CCErrorCoder {
+	policy := *setTemplate.SyncPolicy
+	policy.Paused = true
+	policy.PauseReason = reason
+	policy.Pending = false
+
+	if _, ccErr := st.client.CoreService().SetTemplate().UpdateSetTemplateSyncPolicy(kit.Ctx, kit.Header,
+		setTemplate.BizID, setTemplate.ID, policy); ccErr != nil {
+		blog.Errorf("pause set template %d sync policy failed, reason: %s, err: %v, rid: %s", setTemplate.ID, reason,
+			ccErr, kit.Rid)
+		return ccErr
+	}
+	blog.Warnf("set template %d sync policy is paused, reason: %s, rid: %s", setTemplate.ID, reason, kit.Rid)
+	return nil
+}
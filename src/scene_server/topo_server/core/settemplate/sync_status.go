@@ -82,6 +82,22 @@ func extractSetTemplateVersionFromTaskData(detail *metadata.APITaskDetail) (int6
 	return versionInt, nil
 }
 
+func extractSyncTriggerTypeFromTaskData(detail *metadata.APITaskDetail) metadata.SyncTriggerType {
+	if detail == nil || len(detail.Detail) == 0 {
+		return metadata.SyncTriggerManual
+	}
+	detailData, ok := detail.Detail[0].Data.(map[string]interface{})
+	if !ok {
+		return metadata.SyncTriggerManual
+	}
+	triggerType, ok := detailData["trigger_type"].(string)
+	if !ok || len(triggerType) == 0 {
+		// tasks created before sync policy is supported are all triggered by users
+		return metadata.SyncTriggerManual
+	}
+	return metadata.SyncTriggerType(triggerType)
+}
+
 func (st *setTemplate) UpdateSetSyncStatus(kit *rest.Kit, setID int64) (metadata.SetTemplateSyncStatus, errors.CCErrorCoder) {
 	setSyncStatus := metadata.SetTemplateSyncStatus{}
 	set, err := st.GetOneSet(kit, setID)
@@ -126,7 +142,7 @@ func (st *setTemplate) UpdateSetSyncStatus(kit *rest.Kit, setID int64) (metadata
 		} else {
 			syncStatus = metadata.SyncStatusFinished
 		}
-	} else if detail.Status.IsFailure() {
+	} else if detail.Status.IsFailure() || detail.Status.IsCanceled() {
 		syncStatus = metadata.SyncStatusFailure
 	} else {
 		blog.ErrorJSON("unexpected task status: %s, rid: %s", detail, kit.Rid)
@@ -160,6 +176,7 @@ func (st *setTemplate) UpdateSetSyncStatus(kit *rest.Kit, setID int64) (metadata
 		setSyncStatus.CreateTime = metadata.Time{Time: detail.CreateTime}
 		setSyncStatus.LastTime = metadata.Time{Time: detail.LastTime}
 		setSyncStatus.TaskID = detail.TaskID
+		setSyncStatus.TriggerType = extractSyncTriggerTypeFromTaskData(detail)
 	}
 	if setSyncStatus.Status == metadata.SyncStatusWaiting {
 		setSyncStatus.TaskID = ""
@@ -507,3 +507,26 @@ func (s *Service) SearchTopoPath(ctx *rest.Contexts) {
 
 	ctx.RespEntity(result)
 }
+
+// TraverseInstAssociation traverse the instance association graph from the start instances,
+// or search the shortest association path between the start instances and the end instance.
+func (s *Service) TraverseInstAssociation(ctx *rest.Contexts) {
+	request := new(metadata.InstAssociationTraversalRequest)
+	if err := ctx.DecodeInto(request); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if field, err := request.Validate(); err != nil {
+		blog.Errorf("traverse inst association, but request is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, field))
+		return
+	}
+
+	result, err := s.Core.AssociationOperation().TraverseInstAssociation(ctx.Kit, request)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
@@ -0,0 +1,110 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/fieldpermission"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/scene_server/topo_server/core/inst"
+)
+
+// newFieldPermission new the field permission getter of the request user, it's replaced in the tests.
+var newFieldPermission = fieldpermission.NewFieldPermission
+
+// filterInstFields strip or mask the instances' fields which can not be read by the request user.
+func (s *Service) filterInstFields(kit *rest.Kit, objID string, insts ...mapstr.MapStr) error {
+	if len(insts) == 0 {
+		return nil
+	}
+
+	permission, err := newFieldPermission(s.Engine.CoreAPI, kit.Header).GetObjectFieldPermission(kit.Ctx, objID)
+	if err != nil {
+		blog.Errorf("get object %s field permission failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return err
+	}
+
+	for _, item := range insts {
+		permission.FilterData(item)
+	}
+	return nil
+}
+
+// filterInstItemsFields strip or mask the instances' fields which can not be read by the request user.
+func (s *Service) filterInstItemsFields(kit *rest.Kit, objID string, items []inst.Inst) error {
+	insts := make([]mapstr.MapStr, 0, len(items))
+	for _, item := range items {
+		insts = append(insts, item.ToMapStr())
+	}
+	return s.filterInstFields(kit, objID, insts...)
+}
+
+// checkInstFieldsReadable check if the request user can read all the fields.
+func (s *Service) checkInstFieldsReadable(kit *rest.Kit, objID string, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	permission, err := newFieldPermission(s.Engine.CoreAPI, kit.Header).GetObjectFieldPermission(kit.Ctx, objID)
+	if err != nil {
+		blog.Errorf("get object %s field permission failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return err
+	}
+
+	if unreadable := permission.UnreadableFields(fields); len(unreadable) > 0 {
+		blog.Errorf("user %s has no permission to read object %s fields %v, rid: %s", kit.User, objID, unreadable, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommFieldReadPermissionDenied, strings.Join(unreadable, ","))
+	}
+	return nil
+}
+
+// checkInstConditionReadable check if the request user can read all the fields the search condition and the
+// sort refer to, otherwise the user can find out the hidden values by the instances found.
+func (s *Service) checkInstConditionReadable(kit *rest.Kit, objID string, cond interface{}, sort string) error {
+	permission, err := newFieldPermission(s.Engine.CoreAPI, kit.Header).GetObjectFieldPermission(kit.Ctx, objID)
+	if err != nil {
+		blog.Errorf("get object %s field permission failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return err
+	}
+
+	if unreadable := permission.UnreadableConditionFields(cond, sort); len(unreadable) > 0 {
+		blog.Errorf("user %s has no permission to search object %s by fields %v, rid: %s", kit.User, objID, unreadable, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommFieldReadPermissionDenied, strings.Join(unreadable, ","))
+	}
+	return nil
+}
+
+// checkInstFieldsWritable check if the request user can edit all the fields in the instances' data.
+func (s *Service) checkInstFieldsWritable(kit *rest.Kit, objID string, datas ...mapstr.MapStr) error {
+	if len(datas) == 0 {
+		return nil
+	}
+
+	permission, err := newFieldPermission(s.Engine.CoreAPI, kit.Header).GetObjectFieldPermission(kit.Ctx, objID)
+	if err != nil {
+		blog.Errorf("get object %s field permission failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return err
+	}
+
+	for _, data := range datas {
+		if fields := permission.UnwritableFields(data); len(fields) > 0 {
+			blog.Errorf("user %s has no permission to edit object %s fields %v, rid: %s", kit.User, objID, fields, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommFieldWritePermissionDenied, strings.Join(fields, ","))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/fieldpermission"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// mockFieldPermission returns the field permissions of the operator role, the host password can only be read
+// by the admin, and the host asset id can only be edited by the admin.
+type mockFieldPermission struct{}
+
+func (m *mockFieldPermission) GetObjectFieldPermission(ctx context.Context, objID string) (*metadata.ObjectFieldPermission, error) {
+	attrs := make([]metadata.Attribute, 0)
+	if objID == common.BKInnerObjIDHost {
+		attrs = []metadata.Attribute{
+			{ObjectID: objID, PropertyID: "password", Permission: &metadata.AttributePermission{
+				ReadRoles: []string{"admin"}, HiddenMode: metadata.FieldHiddenModeMask}},
+			{ObjectID: objID, PropertyID: "bk_asset_id", Permission: &metadata.AttributePermission{
+				WriteRoles: []string{"admin"}}},
+		}
+	}
+	roles, _ := m.GetUserRoles(ctx)
+	return metadata.NewObjectFieldPermission(objID, attrs, roles), nil
+}
+
+func (m *mockFieldPermission) GetUserRoles(ctx context.Context) ([]string, error) {
+	return []string{"operator"}, nil
+}
+
+func newFieldPermissionService(t *testing.T) (*Service, *rest.Kit) {
+	newFieldPermission = func(apimachinery.ClientSetInterface, http.Header) fieldpermission.FieldPermissionInterface {
+		return &mockFieldPermission{}
+	}
+	t.Cleanup(func() { newFieldPermission = fieldpermission.NewFieldPermission })
+
+	errIf, err := errors.NewFactory("../../../../resources/errors/")
+	if err != nil {
+		t.Fatalf("new error factory failed, err: %v", err)
+	}
+	kit := &rest.Kit{
+		Rid:             "test_req_id",
+		Header:          http.Header{},
+		Ctx:             context.Background(),
+		CCError:         errIf.CreateDefaultCCErrorIf("en"),
+		User:            "test_user",
+		SupplierAccount: common.BKDefaultOwnerID,
+	}
+	return &Service{Engine: &backbone.Engine{}}, kit
+}
+
+func TestCheckInstFieldsWritable(t *testing.T) {
+	s, kit := newFieldPermissionService(t)
+
+	tests := []struct {
+		objID  string
+		datas  []mapstr.MapStr
+		denied bool
+	}{
+		{objID: "host", datas: nil, denied: false},
+		{objID: "host", datas: []mapstr.MapStr{{"bk_host_name": "h1"}}, denied: false},
+		{objID: "host", datas: []mapstr.MapStr{{"bk_host_name": "h1"}, {"bk_asset_id": "a1"}}, denied: true},
+		{objID: "host", datas: []mapstr.MapStr{{"password": "p1"}}, denied: true},
+		{objID: "set", datas: []mapstr.MapStr{{"password": "p1"}}, denied: false},
+	}
+
+	for _, test := range tests {
+		err := s.checkInstFieldsWritable(kit, test.objID, test.datas...)
+		if !test.denied {
+			if err != nil {
+				t.Errorf("object %s datas %v should be writable, err: %v", test.objID, test.datas, err)
+			}
+			continue
+		}
+
+		ccErr, ok := err.(errors.CCErrorCoder)
+		if !ok || ccErr.GetCode() != common.CCErrCommFieldWritePermissionDenied {
+			t.Errorf("object %s datas %v should be denied with write permission error, got %v", test.objID,
+				test.datas, err)
+		}
+	}
+}
+
+func TestCheckInstConditionReadable(t *testing.T) {
+	s, kit := newFieldPermissionService(t)
+
+	tests := []struct {
+		cond   interface{}
+		sort   string
+		denied bool
+	}{
+		{cond: mapstr.MapStr{"bk_host_name": "h1"}, sort: "bk_host_id", denied: false},
+		{cond: mapstr.MapStr{"bk_asset_id": "a1"}, denied: false},
+		{cond: mapstr.MapStr{"password": mapstr.MapStr{"$regex": "^a"}}, denied: true},
+		{cond: mapstr.MapStr{"$or": []mapstr.MapStr{{"bk_host_name": "h1"}, {"password": "p1"}}}, denied: true},
+		{cond: nil, sort: "-password", denied: true},
+	}
+
+	for _, test := range tests {
+		err := s.checkInstConditionReadable(kit, "host", test.cond, test.sort)
+		if !test.denied {
+			if err != nil {
+				t.Errorf("condition %v sort %s should be allowed, err: %v", test.cond, test.sort, err)
+			}
+			continue
+		}
+
+		ccErr, ok := err.(errors.CCErrorCoder)
+		if !ok || ccErr.GetCode() != common.CCErrCommFieldReadPermissionDenied {
+			t.Errorf("condition %v sort %s should be denied with read permission error, got %v", test.cond,
+				test.sort, err)
+		}
+	}
+}
+
+func TestFilterInstFields(t *testing.T) {
+	s, kit := newFieldPermissionService(t)
+
+	insts := []mapstr.MapStr{{"bk_host_name": "h1", "password": "p1", "bk_asset_id": "a1"}, {"bk_host_name": "h2"}}
+	if err := s.filterInstFields(kit, common.BKInnerObjIDHost, insts...); err != nil {
+		t.Fatalf("filter instance fields failed, err: %v", err)
+	}
+	if insts[0]["password"] != metadata.FieldMaskValue || insts[0]["bk_asset_id"] != "a1" {
+		t.Errorf("the password should be masked and the asset id should be kept, got %v", insts[0])
+	}
+	if _, exists := insts[1]["password"]; exists {
+		t.Errorf("the absent password should not be added, got %v", insts[1])
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	paraparse "configcenter/src/common/paraparse"
+	"configcenter/src/common/selector"
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/topo_server/core/inst"
 	"configcenter/src/scene_server/topo_server/core/operation"
@@ -92,6 +93,15 @@ func (s *Service) CreateInst(ctx *rest.Contexts) {
 			return
 		}
 
+		batchDatas := make([]mapstr.MapStr, 0)
+		for _, item := range batchInfo.BatchInfo {
+			batchDatas = append(batchDatas, item)
+		}
+		if err := s.checkInstFieldsWritable(ctx.Kit, objID, batchDatas...); err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+
 		var setInst *operation.BatchResult
 		txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 			var err error
@@ -127,6 +137,11 @@ func (s *Service) CreateInst(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkInstFieldsWritable(ctx.Kit, objID, data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	var setInst inst.Inst
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
@@ -154,6 +169,11 @@ func (s *Service) CreateInst(ctx *rest.Contexts) {
 		ctx.RespAutoError(txnErr)
 		return
 	}
+
+	if err := s.filterInstFields(ctx.Kit, objID, setInst.ToMapStr()); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	ctx.RespEntity(setInst.ToMapStr())
 }
 
@@ -380,6 +400,15 @@ func (s *Service) UpdateInsts(ctx *rest.Contexts) {
 		// TODO add custom mainline instance param validation
 	}
 
+	updateDatas := make([]mapstr.MapStr, 0)
+	for _, item := range updateCondition.Update {
+		updateDatas = append(updateDatas, item.InstInfo)
+	}
+	if err := s.checkInstFieldsWritable(ctx.Kit, objID, updateDatas...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		instanceIDs := make([]int64, 0)
 		for _, item := range updateCondition.Update {
@@ -468,6 +497,11 @@ func (s *Service) UpdateInst(ctx *rest.Contexts) {
 		data.Remove("metadata")
 	}
 
+	if err := s.checkInstFieldsWritable(ctx.Kit, objID, data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	cond := condition.CreateCondition()
 	cond.Field(obj.GetInstIDFieldName()).Eq(instID)
 
@@ -499,6 +533,7 @@ func (s *Service) SearchInsts(ctx *rest.Contexts) {
 	data := struct {
 		paraparse.SearchParams `json:",inline"`
 		Metadata               *metadata.Metadata `json:"metadata"`
+		Selectors              selector.Selectors `json:"selectors"`
 	}{}
 	if err := ctx.DecodeInto(&data); nil != err {
 		ctx.RespAutoError(err)
@@ -520,23 +555,48 @@ func (s *Service) SearchInsts(ctx *rest.Contexts) {
 		queryCond.Condition = mapstr.New()
 	}
 	page := metadata.ParsePage(queryCond.Page)
+	if key, err := page.ValidateCursor(); err != nil {
+		blog.Errorf("[api-inst] search the objects(%s) with invalid page, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page."+key))
+		return
+	}
+	cond, err := mergeLabelSelectors(ctx.Kit, queryCond.Condition, data.Selectors)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if err := s.checkInstConditionReadable(ctx.Kit, objID, cond, page.Sort); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	query := &metadata.QueryInput{}
-	query.Condition = queryCond.Condition
+	query.Condition = cond
 	query.Fields = strings.Join(queryCond.Fields, ",")
 	query.Limit = page.Limit
 	query.Sort = page.Sort
 	query.Start = page.Start
+	query.Cursor = page.Cursor
 
-	cnt, instItems, err := s.Core.InstOperation().FindInst(ctx.Kit, obj, query, false)
+	// find the origin instances rather than FindInst, so that the cursor of the next page is returned
+	rsp, err := s.Core.InstOperation().FindOriginInst(ctx.Kit, objID, query)
 	if nil != err {
 		blog.Errorf("[api-inst] failed to find the objects(%s), error info is %s, rid: %s", ctx.Request.PathParameter("obj_id"), err.Error(), ctx.Kit.Rid)
 		ctx.RespAutoError(err)
 		return
 	}
+	instItems := inst.CreateInst(ctx.Kit, s.Engine.CoreAPI, obj, rsp.Info)
+
+	if err := s.filterInstItemsFields(ctx.Kit, objID, instItems); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	result := mapstr.MapStr{}
-	result.Set("count", cnt)
+	result.Set("count", rsp.Count)
 	result.Set("info", instItems)
+	if len(rsp.NextCursor) > 0 {
+		result.Set("next_cursor", rsp.NextCursor)
+	}
 	ctx.RespEntity(result)
 }
 
@@ -546,6 +606,7 @@ func (s *Service) SearchInstAndAssociationDetail(ctx *rest.Contexts) {
 	data := struct {
 		paraparse.SearchParams `json:",inline"`
 		Metadata               *metadata.Metadata `json:"metadata"`
+		Selectors              selector.Selectors `json:"selectors"`
 	}{}
 	if err := ctx.DecodeInto(&data); nil != err {
 		ctx.RespAutoError(err)
@@ -564,23 +625,47 @@ func (s *Service) SearchInstAndAssociationDetail(ctx *rest.Contexts) {
 		queryCond.Condition = mapstr.New()
 	}
 	page := metadata.ParsePage(queryCond.Page)
+	if key, err := page.ValidateCursor(); err != nil {
+		blog.Errorf("[api-inst] search the objects(%s) with invalid page, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page."+key))
+		return
+	}
+	cond, err := mergeLabelSelectors(ctx.Kit, queryCond.Condition, data.Selectors)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if err := s.checkInstConditionReadable(ctx.Kit, objID, cond, page.Sort); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	query := &metadata.QueryInput{}
-	query.Condition = queryCond.Condition
+	query.Condition = cond
 	query.Fields = strings.Join(queryCond.Fields, ",")
 	query.Limit = page.Limit
 	query.Sort = page.Sort
 	query.Start = page.Start
+	query.Cursor = page.Cursor
 
-	cnt, instItems, err := s.Core.InstOperation().FindInst(ctx.Kit, obj, query, true)
+	rsp, err := s.Core.InstOperation().FindOriginInst(ctx.Kit, objID, query)
 	if nil != err {
 		blog.Errorf("[api-inst] failed to find the objects(%s), error info is %s, rid: %s", ctx.Request.PathParameter("bk_obj_id"), err.Error(), ctx.Kit.Rid)
 		ctx.RespAutoError(err)
 		return
 	}
+	instItems := inst.CreateInst(ctx.Kit, s.Engine.CoreAPI, obj, rsp.Info)
+
+	if err := s.filterInstItemsFields(ctx.Kit, objID, instItems); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	result := mapstr.MapStr{}
-	result.Set("count", cnt)
+	result.Set("count", rsp.Count)
 	result.Set("info", instItems)
+	if len(rsp.NextCursor) > 0 {
+		result.Set("next_cursor", rsp.NextCursor)
+	}
 	ctx.RespEntity(result)
 }
 
@@ -591,6 +676,7 @@ func (s *Service) SearchInstByObject(ctx *rest.Contexts) {
 	data := struct {
 		paraparse.SearchParams `json:",inline"`
 		Metadata               *metadata.Metadata `json:"metadata"`
+		Selectors              selector.Selectors `json:"selectors"`
 	}{}
 	if err := ctx.DecodeInto(&data); nil != err {
 		ctx.RespAutoError(err)
@@ -608,22 +694,46 @@ func (s *Service) SearchInstByObject(ctx *rest.Contexts) {
 		queryCond.Condition = mapstr.New()
 	}
 	page := metadata.ParsePage(queryCond.Page)
+	if key, err := page.ValidateCursor(); err != nil {
+		blog.Errorf("[api-inst] search the objects(%s) with invalid page, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page."+key))
+		return
+	}
+	cond, err := mergeLabelSelectors(ctx.Kit, queryCond.Condition, data.Selectors)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if err := s.checkInstConditionReadable(ctx.Kit, objID, cond, page.Sort); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	query := &metadata.QueryInput{}
-	query.Condition = queryCond.Condition
+	query.Condition = cond
 	query.Fields = strings.Join(queryCond.Fields, ",")
 	query.Limit = page.Limit
 	query.Sort = page.Sort
 	query.Start = page.Start
-	cnt, instItems, err := s.Core.InstOperation().FindInst(ctx.Kit, obj, query, false)
+	query.Cursor = page.Cursor
+	rsp, err := s.Core.InstOperation().FindOriginInst(ctx.Kit, objID, query)
 	if nil != err {
 		blog.Errorf("[api-inst] failed to find the objects(%s), error info is %s, rid: %s", ctx.Request.PathParameter("bk_obj_id"), err.Error(), ctx.Kit.Rid)
 		ctx.RespAutoError(err)
 		return
 	}
+	instItems := inst.CreateInst(ctx.Kit, s.Engine.CoreAPI, obj, rsp.Info)
+
+	if err := s.filterInstItemsFields(ctx.Kit, objID, instItems); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	result := mapstr.MapStr{}
-	result.Set("count", cnt)
+	result.Set("count", rsp.Count)
 	result.Set("info", instItems)
+	if len(rsp.NextCursor) > 0 {
+		result.Set("next_cursor", rsp.NextCursor)
+	}
 	ctx.RespEntity(result)
 }
 
@@ -646,6 +756,21 @@ func (s *Service) SearchInstByAssociation(ctx *rest.Contexts) {
 		return
 	}
 
+	for condObjID, items := range data.Condition {
+		fields := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			fields = append(fields, map[string]interface{}{item.Field: item.Value})
+		}
+		if err := s.checkInstConditionReadable(ctx.Kit, condObjID, fields, ""); err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+	}
+	if err := s.checkInstConditionReadable(ctx.Kit, objID, nil, data.Page.Sort); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	cnt, instItems, err := s.Core.InstOperation().FindInstByAssociationInst(ctx.Kit, obj, &data.AssociationParams)
 	if nil != err {
 		blog.Errorf("[api-inst] failed to find the objects(%s), error info is %s, rid: %s", ctx.Request.PathParameter("bk_obj_id"), err.Error(), ctx.Kit.Rid)
@@ -653,6 +778,11 @@ func (s *Service) SearchInstByAssociation(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.filterInstItemsFields(ctx.Kit, objID, instItems); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	result := mapstr.MapStr{}
 	result.Set("count", cnt)
 	result.Set("info", instItems)
@@ -693,6 +823,11 @@ func (s *Service) SearchInstByInstID(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.filterInstItemsFields(ctx.Kit, objID, instItems); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	result := mapstr.MapStr{}
 	result.Set("count", cnt)
 	result.Set("info", instItems)
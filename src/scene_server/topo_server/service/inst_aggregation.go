@@ -0,0 +1,59 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// AggregateInsts group the object's instances matched by the filter and calculate the metrics of each group,
+// the hosts can also be grouped by the topology fields bk_biz_id, bk_set_id and bk_module_id.
+func (s *Service) AggregateInsts(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	option := metadata.AggregateOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	// the instances can not be grouped, calculated or filtered by the fields the user can not read
+	if err := s.checkInstFieldsReadable(ctx.Kit, objID, aggregateFields(option)); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().Instance().AggregateInstance(ctx.Kit.Ctx, ctx.Kit.Header, objID, option)
+	if err != nil {
+		blog.Errorf("AggregateInsts failed, object: %s, option: %+v, err: %v, rid: %s", objID, option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// aggregateFields returns the fields used by the group by, the metrics and the filter of the aggregation
+func aggregateFields(option metadata.AggregateOption) []string {
+	fields := append([]string{}, option.GroupBy...)
+	for _, metric := range option.Metrics {
+		if len(metric.Field) != 0 {
+			fields = append(fields, metric.Field)
+		}
+	}
+	if option.Filter != nil {
+		fields = append(fields, option.Filter.Fields()...)
+	}
+	return fields
+}
@@ -49,6 +49,11 @@ func (s *Service) CreateBusiness(ctx *rest.Contexts) {
 	}
 
 	data.Set(common.BKDefaultField, common.DefaultFlagDefaultValue)
+	if err := s.checkInstFieldsWritable(ctx.Kit, common.BKInnerObjIDApp, data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	// do with transaction
 	var business inst.Inst
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
@@ -142,6 +147,11 @@ func (s *Service) UpdateBusiness(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkInstFieldsWritable(ctx.Kit, common.BKInnerObjIDApp, dataWithMetadata.Data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		err = s.Core.BusinessOperation().UpdateBusiness(ctx.Kit, dataWithMetadata.Data, obj, bizID, dataWithMetadata.Metadata)
 		if err != nil {
@@ -408,6 +418,10 @@ func (s *Service) SearchBusiness(ctx *rest.Contexts) {
 		ctx.RespErrorCodeOnly(common.CCErrCommJSONUnmarshalFailed, "")
 		return
 	}
+	if err := s.checkInstConditionReadable(ctx.Kit, common.BKInnerObjIDApp, searchCond.Condition, searchCond.Page.Sort); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	attrCond := condition.CreateCondition()
 	attrCond.Field(metadata.AttributeFieldObjectID).Eq(common.BKInnerObjIDApp)
@@ -509,6 +523,11 @@ func (s *Service) SearchBusiness(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.filterInstFields(ctx.Kit, common.BKInnerObjIDApp, instItems...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	result := mapstr.MapStr{}
 	result.Set("count", cnt)
 	result.Set("info", instItems)
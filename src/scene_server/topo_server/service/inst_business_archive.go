@@ -0,0 +1,134 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// ArchiveBusiness archive the business with a snapshot of it's topology, the hosts are moved to the resource pool.
+func (s *Service) ArchiveBusiness(ctx *rest.Contexts) {
+	data := struct {
+		Metadata *metadata.Metadata `json:"metadata"`
+	}{}
+	if err := ctx.DecodeInto(&data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter("app_id"), 10, 64)
+	if nil != err {
+		blog.Errorf("[api-business]failed to parse the biz id, error info is %s, rid: %s", err.Error(), ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, "business id"))
+		return
+	}
+
+	obj, err := s.Core.ObjectOperation().FindSingleObject(ctx.Kit, common.BKInnerObjIDApp, data.Metadata)
+	if nil != err {
+		blog.Errorf("failed to search the business, %s, rid: %s", err.Error(), ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	if err := s.Core.AssociationOperation().CheckAssociation(ctx.Kit, obj, obj.Object().ObjectID, bizID); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	var archive *metadata.BusinessArchive
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
+		archive, err = s.Core.BusinessOperation().ArchiveBusiness(ctx.Kit, obj, bizID, data.Metadata)
+		if err != nil {
+			blog.Errorf("ArchiveBusiness failed, bizID: %d, err: %+v, rid: %s", bizID, err, ctx.Kit.Rid)
+			return err
+		}
+		if err := s.AuthManager.UpdateRegisteredBusinessByID(ctx.Kit.Ctx, ctx.Kit.Header, bizID); err != nil {
+			blog.Errorf("ArchiveBusiness failed, update register business info failed, err: %+v, rid: %s", err, ctx.Kit.Rid)
+			return ctx.Kit.CCError.Error(common.CCErrCommRegistResourceToIAMFailed)
+		}
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+	ctx.RespEntity(archive)
+}
+
+// RestoreBusiness restore an archived business with it's archive, returns the restore report.
+func (s *Service) RestoreBusiness(ctx *rest.Contexts) {
+	data := struct {
+		metadata.RestoreBusinessOption `json:",inline"`
+		Metadata                       *metadata.Metadata `json:"metadata"`
+	}{}
+	if err := ctx.DecodeInto(&data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter("app_id"), 10, 64)
+	if nil != err {
+		blog.Errorf("[api-business]failed to parse the biz id, error info is %s, rid: %s", err.Error(), ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, "business id"))
+		return
+	}
+
+	obj, err := s.Core.ObjectOperation().FindSingleObject(ctx.Kit, common.BKInnerObjIDApp, data.Metadata)
+	if nil != err {
+		blog.Errorf("failed to search the business, %s, rid: %s", err.Error(), ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	var report *metadata.BusinessRestoreReport
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
+		report, err = s.Core.BusinessOperation().RestoreBusiness(ctx.Kit, obj, bizID, data.RestoreBusinessOption, data.Metadata)
+		if err != nil {
+			blog.Errorf("RestoreBusiness failed, bizID: %d, option: %+v, err: %+v, rid: %s", bizID, data.RestoreBusinessOption, err, ctx.Kit.Rid)
+			return err
+		}
+		if err := s.AuthManager.UpdateRegisteredBusinessByID(ctx.Kit.Ctx, ctx.Kit.Header, bizID); err != nil {
+			blog.Errorf("RestoreBusiness failed, update register business info failed, err: %+v, rid: %s", err, ctx.Kit.Rid)
+			return ctx.Kit.CCError.Error(common.CCErrCommRegistResourceToIAMFailed)
+		}
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+	ctx.RespEntity(report)
+}
+
+// ListBusinessArchive list the business archives, the latest archives are in the front by default.
+func (s *Service) ListBusinessArchive(ctx *rest.Contexts) {
+	option := metadata.ListBusinessArchiveOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().BusinessArchive().ListBusinessArchive(ctx.Kit.Ctx, ctx.Kit.Header, option)
+	if err != nil {
+		blog.Errorf("ListBusinessArchive failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
@@ -0,0 +1,196 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/selector"
+	"configcenter/src/common/util"
+)
+
+// AggregateInstLabelsOption aggregate the labels of the object's instances matching the condition
+type AggregateInstLabelsOption struct {
+	Condition mapstr.MapStr      `json:"condition"`
+	Selectors selector.Selectors `json:"selectors"`
+}
+
+// AddInstLabels add labels to the instances of the object
+func (s *Service) AddInstLabels(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	option := selector.LabelAddOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if field, err := option.Labels.Validate(); err != nil {
+		blog.Errorf("AddInstLabels failed, labels invalid, field: %s, err: %v, rid: %s", field, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "labels."+field))
+		return
+	}
+
+	if err := s.checkLabelInstances(ctx.Kit, objID, option.InstanceIDs); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
+		tableName := common.GetInstTableName(objID)
+		if err := s.Engine.CoreAPI.CoreService().Label().AddLabel(ctx.Kit.Ctx, ctx.Kit.Header, tableName, option); err != nil {
+			blog.Errorf("AddInstLabels failed, object: %s, option: %+v, err: %v, rid: %s", objID, option, err, ctx.Kit.Rid)
+			return err
+		}
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// RemoveInstLabels remove the labels with the keys from the instances of the object
+func (s *Service) RemoveInstLabels(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	option := selector.LabelRemoveOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if len(option.Keys) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "keys"))
+		return
+	}
+
+	if err := s.checkLabelInstances(ctx.Kit, objID, option.InstanceIDs); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
+		tableName := common.GetInstTableName(objID)
+		if err := s.Engine.CoreAPI.CoreService().Label().RemoveLabel(ctx.Kit.Ctx, ctx.Kit.Header, tableName, option); err != nil {
+			blog.Errorf("RemoveInstLabels failed, object: %s, option: %+v, err: %v, rid: %s", objID, option, err, ctx.Kit.Rid)
+			return err
+		}
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// AggregateInstLabels returns all the label keys and their values of the object's instances
+func (s *Service) AggregateInstLabels(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	option := AggregateInstLabelsOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if _, err := s.Core.ObjectOperation().FindSingleObject(ctx.Kit, objID, nil); err != nil {
+		blog.Errorf("AggregateInstLabels failed, find object %s failed, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond, err := mergeLabelSelectors(ctx.Kit, option.Condition, option.Selectors)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	tableName := common.GetInstTableName(objID)
+	if tableName == common.BKTableNameBaseInst {
+		cond[common.BKObjIDField] = objID
+	}
+
+	aggregation, err := s.Engine.CoreAPI.CoreService().Label().AggregateLabels(ctx.Kit.Ctx, ctx.Kit.Header, tableName, cond)
+	if err != nil {
+		blog.Errorf("AggregateInstLabels failed, object: %s, condition: %+v, err: %v, rid: %s", objID, cond, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(aggregation)
+}
+
+// checkLabelInstances check that all the instances exist and belong to the object
+func (s *Service) checkLabelInstances(kit *rest.Kit, objID string, instanceIDs []int64) errors.CCErrorCoder {
+	if len(instanceIDs) == 0 {
+		return kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "instance_ids")
+	}
+	if len(instanceIDs) > common.BKMaxPageSize {
+		return kit.CCError.CCErrorf(common.CCErrCommXXExceedLimit, "instance_ids", common.BKMaxPageSize)
+	}
+
+	obj, err := s.Core.ObjectOperation().FindSingleObject(kit, objID, nil)
+	if err != nil {
+		blog.Errorf("find object %s failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKObjIDField)
+	}
+
+	instanceIDs = util.IntArrayUnique(instanceIDs)
+	idField := obj.GetInstIDFieldName()
+	query := &metadata.QueryInput{
+		Condition: mapstr.MapStr{
+			idField: mapstr.MapStr{common.BKDBIN: instanceIDs},
+		},
+		Fields: idField,
+		Limit:  common.BKNoLimit,
+	}
+	result, err := s.Core.InstOperation().FindOriginInst(kit, obj.GetObjectID(), query)
+	if err != nil {
+		blog.Errorf("find %s instances %v failed, err: %v, rid: %s", objID, instanceIDs, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if result.Count != len(instanceIDs) {
+		blog.Errorf("some instances %v are not %s instances, found %d, rid: %s", instanceIDs, objID, result.Count, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "instance_ids")
+	}
+	return nil
+}
+
+// mergeLabelSelectors add the label selectors to the instance query condition
+func mergeLabelSelectors(kit *rest.Kit, cond mapstr.MapStr, selectors selector.Selectors) (mapstr.MapStr, errors.CCErrorCoder) {
+	if cond == nil {
+		cond = mapstr.New()
+	}
+	if len(selectors) == 0 {
+		return cond, nil
+	}
+
+	if field, err := selectors.Validate(); err != nil {
+		blog.Errorf("label selectors invalid, field: %s, err: %v, rid: %s", field, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "selectors."+field)
+	}
+	labelFilter, err := selectors.ToMgoFilter()
+	if err != nil {
+		blog.Errorf("parse label selectors %+v failed, err: %v, rid: %s", selectors, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "selectors")
+	}
+
+	if len(cond) == 0 {
+		return labelFilter, nil
+	}
+	return mapstr.MapStr{
+		common.BKDBAND: []interface{}{cond, labelFilter},
+	}, nil
+}
@@ -103,6 +103,11 @@ func (s *Service) CreateModule(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkInstFieldsWritable(ctx.Kit, common.BKInnerObjIDModule, dataWithMetadata.Data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	var module inst.Inst
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
@@ -280,6 +285,11 @@ func (s *Service) UpdateModule(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkInstFieldsWritable(ctx.Kit, common.BKInnerObjIDModule, dataWithMetadata.Data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		err = s.Core.ModuleOperation().UpdateModule(ctx.Kit, dataWithMetadata.Data, obj, bizID, setID, moduleID)
 		if err != nil {
@@ -405,13 +415,17 @@ func (s *Service) SearchModule(ctx *rest.Contexts) {
 		return
 	}
 
+	page := metadata.ParsePage(paramsCond.Page)
+	if err := s.checkInstConditionReadable(ctx.Kit, common.BKInnerObjIDModule, paramsCond.Condition, page.Sort); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	paramsCond.Condition[common.BKAppIDField] = bizID
 	paramsCond.Condition[common.BKSetIDField] = setID
 
 	queryCond := &metadata.QueryInput{}
 	queryCond.Condition = paramsCond.Condition
 	queryCond.Fields = strings.Join(paramsCond.Fields, ",")
-	page := metadata.ParsePage(paramsCond.Page)
 	queryCond.Limit = page.Limit
 	queryCond.Sort = page.Sort
 	queryCond.Start = page.Start
@@ -423,6 +437,11 @@ func (s *Service) SearchModule(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.filterInstFields(ctx.Kit, common.BKInnerObjIDModule, instItems...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	result := mapstr.MapStr{}
 	result.Set("count", cnt)
 	result.Set("info", instItems)
@@ -599,6 +618,13 @@ func (s *Service) UpdateModuleHostApplyEnableStatus(ctx *rest.Contexts) {
 			common.HostApplyEnabledField: requestBody.Enable,
 		},
 	}
+	if len(requestBody.DriftAction) != 0 {
+		if !metadata.ValidateHostApplyDriftAction(requestBody.DriftAction) {
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.HostApplyDriftActionField))
+			return
+		}
+		updateOption.Data[common.HostApplyDriftActionField] = requestBody.DriftAction
+	}
 
 	var result *metadata.UpdatedOptionResult
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
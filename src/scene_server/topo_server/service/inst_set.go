@@ -119,6 +119,11 @@ func (s *Service) CreateSet(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkInstFieldsWritable(ctx.Kit, common.BKInnerObjIDSet, dataWithMetadata.Data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	var resp interface{}
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
@@ -337,6 +342,11 @@ func (s *Service) UpdateSet(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkInstFieldsWritable(ctx.Kit, common.BKInnerObjIDSet, dataWithMetadata.Data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		err = s.Core.SetOperation().UpdateSet(ctx.Kit, dataWithMetadata.Data, obj, bizID, setID, dataWithMetadata.Metadata)
 		if err != nil {
@@ -389,12 +399,16 @@ func (s *Service) SearchSet(ctx *rest.Contexts) {
 		return
 	}
 
+	page := metadata.ParsePage(paramsCond.Page)
+	if err := s.checkInstConditionReadable(ctx.Kit, common.BKInnerObjIDSet, paramsCond.Condition, page.Sort); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	paramsCond.Condition[common.BKAppIDField] = bizID
 
 	queryCond := &metadata.QueryInput{}
 	queryCond.Condition = paramsCond.Condition
 	queryCond.Fields = strings.Join(paramsCond.Fields, ",")
-	page := metadata.ParsePage(paramsCond.Page)
 	queryCond.Start = page.Start
 	queryCond.Sort = page.Sort
 	queryCond.Limit = page.Limit
@@ -406,6 +420,11 @@ func (s *Service) SearchSet(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.filterInstItemsFields(ctx.Kit, common.BKInnerObjIDSet, instItems); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	result := mapstr.MapStr{}
 	result.Set("count", cnt)
 	result.Set("info", instItems)
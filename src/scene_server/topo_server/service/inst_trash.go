@@ -0,0 +1,142 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/topo_server/core/model"
+	"configcenter/src/scene_server/topo_server/core/operation"
+)
+
+// ListInstTrash returns the soft deleted instances of the object in the trash bin
+func (s *Service) ListInstTrash(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	if _, err := s.findTrashObject(ctx.Kit, objID); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	option := metadata.ListTrashOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().Trash().ListTrash(ctx.Kit.Ctx, ctx.Kit.Header, objID, option)
+	if err != nil {
+		blog.Errorf("ListInstTrash failed, object: %s, option: %+v, err: %v, rid: %s", objID, option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// RestoreInstTrash restore the soft deleted instances of the object, the instances are registered to iam again
+func (s *Service) RestoreInstTrash(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	obj, err := s.findTrashObject(ctx.Kit, objID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	option := metadata.RestoreTrashOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if field, err := option.Validate(); err != nil {
+		blog.Errorf("RestoreInstTrash failed, option invalid, field: %s, err: %v, rid: %s", field, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field))
+		return
+	}
+
+	var result metadata.RestoreTrashResult
+	instIDs := make([]int64, 0)
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
+		var err errors.CCErrorCoder
+		result, err = s.Engine.CoreAPI.CoreService().Trash().RestoreTrash(ctx.Kit.Ctx, ctx.Kit.Header, objID, option)
+		if err != nil {
+			blog.Errorf("RestoreInstTrash failed, object: %s, ids: %v, err: %v, rid: %s", objID, option.IDs, err, ctx.Kit.Rid)
+			return err
+		}
+
+		for _, restored := range result.Restored {
+			instIDs = append(instIDs, restored.InstID)
+		}
+		// auth: register instances to iam
+		if err := s.AuthManager.RegisterInstancesByID(ctx.Kit.Ctx, ctx.Kit.Header, objID, instIDs...); err != nil {
+			blog.Errorf("RestoreInstTrash success, but register instances %v to iam failed, err: %v, rid: %s", instIDs, err, ctx.Kit.Rid)
+			return ctx.Kit.CCError.CCError(common.CCErrCommRegistResourceToIAMFailed)
+		}
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	audit := operation.NewSupplementary().Audit(ctx.Kit, s.Engine.CoreAPI, obj, s.Core.InstOperation())
+	for _, instID := range instIDs {
+		audit.CommitCreateLog(nil, audit.CreateSnapshot(instID, mapstr.New()), nil, nil)
+	}
+	ctx.RespEntity(result)
+}
+
+// PurgeInstTrash remove the soft deleted instances of the object from the trash bin permanently
+func (s *Service) PurgeInstTrash(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	if _, err := s.findTrashObject(ctx.Kit, objID); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	option := metadata.RestoreTrashOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if field, err := option.Validate(); err != nil {
+		blog.Errorf("PurgeInstTrash failed, option invalid, field: %s, err: %v, rid: %s", field, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field))
+		return
+	}
+
+	if err := s.Engine.CoreAPI.CoreService().Trash().DeleteTrash(ctx.Kit.Ctx, ctx.Kit.Header, objID, option); err != nil {
+		blog.Errorf("PurgeInstTrash failed, object: %s, ids: %v, err: %v, rid: %s", objID, option.IDs, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// findTrashObject find the custom object, the trash bin of the inner objects are not managed by topo server
+func (s *Service) findTrashObject(kit *rest.Kit, objID string) (model.Object, error) {
+	if common.IsInnerModel(objID) {
+		blog.Errorf("the trash bin of inner object %s can not be operated with common api, rid: %s", objID, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommForbiddenOperateInnerModelInstanceWithCommonAPI)
+	}
+
+	obj, err := s.Core.ObjectOperation().FindSingleObject(kit, objID, nil)
+	if err != nil {
+		blog.Errorf("find object %s failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, err
+	}
+	return obj, nil
+}
@@ -13,8 +13,10 @@
 package service
 
 import (
+	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	"configcenter/src/scene_server/topo_server/core/settemplate"
 )
@@ -40,3 +42,40 @@ func (s *Service) SyncModuleTaskHandler(ctx *rest.Contexts) {
 	}
 	ctx.RespEntity(nil)
 }
+
+// InstantiateSetTaskHandler creates one set and its modules from the set template, it's the sub task of the set
+// template instantiation. the set is created in a transaction, so no partial set is left if the creation failed.
+func (s *Service) InstantiateSetTaskHandler(ctx *rest.Contexts) {
+	task := &metadata.InstantiateSetTask{}
+	if err := ctx.DecodeInto(task); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	obj, err := s.Core.ObjectOperation().FindSingleObject(ctx.Kit, common.BKInnerObjIDSet, nil)
+	if err != nil {
+		blog.Errorf("InstantiateSetTaskHandler failed, get set model failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	data := mapstr.New()
+	for key, value := range task.Set {
+		data[key] = value
+	}
+	data[common.BKParentIDField] = task.ParentID
+	data[common.BKSetTemplateIDField] = task.SetTemplateID
+
+	var set interface{}
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
+		var err error
+		set, err = s.createSet(ctx.Kit, task.BizID, obj, data, nil)
+		return err
+	})
+	if txnErr != nil {
+		blog.ErrorJSON("InstantiateSetTaskHandler failed, create set failed, task: %s, err: %s, rid: %s", task.Set, txnErr, ctx.Kit.Rid)
+		ctx.RespAutoError(txnErr)
+		return
+	}
+	ctx.RespEntity(set)
+}
@@ -48,6 +48,11 @@ func (s *Service) CreateObjectBatch(ctx *rest.Contexts) {
 		ctx.RespAutoError(txnErr)
 		return
 	}
+
+	// the imported attributes may be expression attributes, backfill them after they are committed
+	for objID := range dataWithMetadata.Data {
+		s.refreshExpressionFields(ctx.Kit, objID)
+	}
 	ctx.RespEntity(ret)
 }
 
@@ -13,6 +13,7 @@
 package service
 
 import (
+	"net/http"
 	"strconv"
 
 	"configcenter/src/common"
@@ -21,6 +22,7 @@ import (
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
 )
 
 // CreateObjectAttribute create a new object attribute
@@ -95,6 +97,11 @@ func (s *Service) CreateObjectAttribute(ctx *rest.Contexts) {
 		return
 	}
 
+	// backfill the expression field of the existing instances after the attribute is committed
+	if attrInfo[0].PropertyType == common.FieldTypeExpression {
+		s.refreshExpressionFields(ctx.Kit, attrInfo[0].ObjectID)
+	}
+
 	ctx.RespEntity(attrInfo[0])
 }
 
@@ -216,9 +223,53 @@ func (s *Service) UpdateObjectAttribute(ctx *rest.Contexts) {
 		ctx.RespAutoError(txnErr)
 		return
 	}
+
+	// backfill the expression fields of the existing instances after the changed expression is committed
+	if data.Exists(metadata.AttributeFieldOption) {
+		s.refreshExpressionFieldsByAttrID(ctx.Kit, id)
+	}
 	ctx.RespEntity(nil)
 }
 
+// refreshExpressionFieldsByAttrID refresh the expression fields of the attribute's model if it's an expression attribute
+func (s *Service) refreshExpressionFieldsByAttrID(kit *rest.Kit, id int64) {
+	header := newNoTxnHeader(kit.Header)
+	opt := &metadata.QueryCondition{Condition: mapstr.MapStr{common.BKFieldID: id}}
+	rsp, err := s.Engine.CoreAPI.CoreService().Model().ReadModelAttrByCondition(kit.Ctx, header, opt)
+	if err != nil {
+		blog.Errorf("get attribute %d to refresh expression fields failed, err: %v, rid: %s", id, err, kit.Rid)
+		return
+	}
+	if !rsp.Result {
+		blog.Errorf("get attribute %d to refresh expression fields failed, err: %s, rid: %s", id, rsp.ErrMsg, kit.Rid)
+		return
+	}
+
+	for _, attr := range rsp.Data.Info {
+		if attr.PropertyType == common.FieldTypeExpression {
+			s.refreshExpressionFields(kit, attr.ObjectID)
+		}
+	}
+}
+
+// refreshExpressionFields asks core service to recompute the expression fields of the model's instances in
+// background. it must be called after the transaction is committed, otherwise the refresh can not read the
+// new expression, and the failure is only logged because the attribute is already saved.
+func (s *Service) refreshExpressionFields(kit *rest.Kit, objID string) {
+	err := s.Engine.CoreAPI.CoreService().Instance().RefreshExpressionFields(kit.Ctx, newNoTxnHeader(kit.Header), objID)
+	if err != nil {
+		blog.Errorf("refresh the expression fields of model %s failed, err: %v, rid: %s", objID, err, kit.Rid)
+	}
+}
+
+// newNoTxnHeader copies the header without the committed transaction, so that the request is not run in it
+func newNoTxnHeader(h http.Header) http.Header {
+	header := util.CloneHeader(h)
+	header.Del(common.TransactionIdHeader)
+	header.Del(common.TransactionTimeoutHeader)
+	return header
+}
+
 // DeleteObjectAttribute delete the object attribute
 func (s *Service) DeleteObjectAttribute(ctx *rest.Contexts) {
 
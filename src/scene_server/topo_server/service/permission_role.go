@@ -0,0 +1,108 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// CreatePermissionRole create a role which can be granted attributes' read/write permission
+func (s *Service) CreatePermissionRole(ctx *rest.Contexts) {
+	option := metadata.CreatePermissionRoleOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	role, err := s.Engine.CoreAPI.CoreService().PermissionRole().CreatePermissionRole(ctx.Kit.Ctx, ctx.Kit.Header, option)
+	if err != nil {
+		blog.Errorf("CreatePermissionRole failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(role)
+}
+
+// UpdatePermissionRole update the permission role's members and description
+func (s *Service) UpdatePermissionRole(ctx *rest.Contexts) {
+	roleID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKFieldID))
+		return
+	}
+
+	option := metadata.UpdatePermissionRoleOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	role, ccErr := s.Engine.CoreAPI.CoreService().PermissionRole().UpdatePermissionRole(ctx.Kit.Ctx, ctx.Kit.Header, roleID, option)
+	if ccErr != nil {
+		blog.Errorf("UpdatePermissionRole failed, roleID: %d, option: %+v, err: %+v, rid: %s", roleID, option, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(role)
+}
+
+// DeletePermissionRole delete permission roles
+func (s *Service) DeletePermissionRole(ctx *rest.Contexts) {
+	option := metadata.DeletePermissionRoleOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(option.RoleIDs) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "ids"))
+		return
+	}
+
+	if err := s.Engine.CoreAPI.CoreService().PermissionRole().DeletePermissionRole(ctx.Kit.Ctx, ctx.Kit.Header, option); err != nil {
+		blog.Errorf("DeletePermissionRole failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// ListPermissionRole list permission roles by names or member
+func (s *Service) ListPermissionRole(ctx *rest.Contexts) {
+	option := metadata.ListPermissionRoleOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if option.Page.Limit == 0 {
+		option.Page.Limit = common.BKDefaultLimit
+	}
+	if option.Page.IsIllegal() {
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommPageLimitIsExceeded))
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().PermissionRole().ListPermissionRole(ctx.Kit.Ctx, ctx.Kit.Header, option)
+	if err != nil {
+		blog.Errorf("ListPermissionRole failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
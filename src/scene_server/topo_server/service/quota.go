@@ -0,0 +1,70 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// SetQuota set the quota of the tenant or a business, the quota of a business overrides the default business
+// quota whose business id is 0, and the limit -1 makes the business unlimited.
+func (s *Service) SetQuota(ctx *rest.Contexts) {
+	option := metadata.SetQuotaOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	quota, err := s.Engine.CoreAPI.CoreService().Quota().SetQuota(ctx.Kit.Ctx, ctx.Kit.Header, option)
+	if err != nil {
+		blog.Errorf("SetQuota failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(quota)
+}
+
+// DeleteQuota delete the quota, the resource is not limited or limited by the default business quota then
+func (s *Service) DeleteQuota(ctx *rest.Contexts) {
+	key := metadata.QuotaKey{}
+	if err := ctx.DecodeInto(&key); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := s.Engine.CoreAPI.CoreService().Quota().DeleteQuota(ctx.Kit.Ctx, ctx.Kit.Header, key); err != nil {
+		blog.Errorf("DeleteQuota failed, key: %+v, err: %+v, rid: %s", key, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// ListQuotaUsage list the limits and usages of the tenant, or the business if the business id is set
+func (s *Service) ListQuotaUsage(ctx *rest.Contexts) {
+	option := metadata.ListQuotaUsageOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	usages, err := s.Engine.CoreAPI.CoreService().Quota().ListQuotaUsage(ctx.Kit.Ctx, ctx.Kit.Header, option)
+	if err != nil {
+		blog.Errorf("ListQuotaUsage failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(usages)
+}
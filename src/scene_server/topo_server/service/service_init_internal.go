@@ -31,6 +31,7 @@ func (s *Service) initInternalTask(web *restful.WebService) {
 	})
 
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/internal/task", Handler: s.SyncModuleTaskHandler})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/internal/task/instantiate_set", Handler: s.InstantiateSetTaskHandler})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -57,6 +57,7 @@ func (s *Service) initAssociation(web *restful.WebService) {
 	// topo search methods
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/search/owner/{owner_id}/object/{bk_obj_id}", Handler: s.SearchInstByAssociation})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/topo/search/owner/{owner_id}/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstTopo})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/inst/association/traversal", Handler: s.TraverseInstAssociation})
 
 	// ATTENTION: the following methods is not recommended
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/topo/owner/{owner_id}/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstChildTopo})
@@ -88,6 +89,9 @@ func (s *Service) initBusiness(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/app/{owner_id}/{app_id}", Handler: s.DeleteBusiness})
 	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/app/{owner_id}/{app_id}", Handler: s.UpdateBusiness})
 	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/app/status/{flag}/{owner_id}/{app_id}", Handler: s.UpdateBusinessStatus})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/archive/{app_id}", Handler: s.ArchiveBusiness})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/restore/{app_id}", Handler: s.RestoreBusiness})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/archives/search", Handler: s.ListBusinessArchive})
 	// utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/search/{owner_id}", Handler: s.SearchBusiness})
 	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/app/{app_id}/basic_info", Handler: s.GetBusinessBasicInfo})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/default/{owner_id}/search", Handler: s.SearchOwnerResourcePoolBusiness})
@@ -153,6 +157,13 @@ func (s *Service) initInst(web *restful.WebService) {
 	// utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/inst/association/object/{bk_obj_id}/inst_id/{id}/offset/{start}/limit/{limit}", Handler: s.SearchInstAssociation})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/inst/association/object/{bk_obj_id}/inst_id/{id}/offset/{start}/limit/{limit}/web", Handler: s.SearchInstAssociationUI})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/inst/association/association_object/inst_base_info", Handler: s.SearchInstAssociationWithOtherObject})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/instance/object/{bk_obj_id}/labels", Handler: s.AddInstLabels})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/instance/object/{bk_obj_id}/labels", Handler: s.RemoveInstLabels})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/instance/object/{bk_obj_id}/labels/aggregation", Handler: s.AggregateInstLabels})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/instance/object/{bk_obj_id}/aggregation", Handler: s.AggregateInsts})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/trash/object/{bk_obj_id}", Handler: s.ListInstTrash})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/updatemany/trash/object/{bk_obj_id}/restore", Handler: s.RestoreInstTrash})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/trash/object/{bk_obj_id}", Handler: s.PurgeInstTrash})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -172,6 +183,33 @@ func (s *Service) initObjectAttribute(web *restful.WebService) {
 	utility.AddToRestfulWebService(web)
 }
 
+func (s *Service) initPermissionRole(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.Engine.CCErr,
+		Language: s.Engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/topo/permission_role", Handler: s.CreatePermissionRole})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/topo/permission_role/{id}", Handler: s.UpdatePermissionRole})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/topo/permission_role", Handler: s.DeletePermissionRole})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/permission_role", Handler: s.ListPermissionRole})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *Service) initQuota(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.Engine.CCErr,
+		Language: s.Engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/topo/quota", Handler: s.SetQuota})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/topo/quota", Handler: s.DeleteQuota})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/quota/usage", Handler: s.ListQuotaUsage})
+
+	utility.AddToRestfulWebService(web)
+}
+
 func (s *Service) initObjectClassification(web *restful.WebService) {
 	utility := rest.NewRestUtility(rest.Config{
 		ErrorIf:  s.Engine.CCErr,
@@ -278,6 +316,8 @@ func (s *Service) initService(web *restful.WebService) {
 	s.initSet(web)
 	s.initObject(web)
 	s.initObjectAttribute(web)
+	s.initPermissionRole(web)
+	s.initQuota(web)
 	s.initObjectClassification(web)
 	s.initObjectGroup(web)
 	s.initGraphics(web)
@@ -37,11 +37,16 @@ func (s *Service) initSetTemplate(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/sets/web", Handler: s.ListSetTplRelatedSetsWeb})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/diff_with_instances", Handler: s.DiffSetTplWithInst})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/updatemany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/sync_to_instances", Handler: s.SyncSetTplToInst})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/updatemany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/cancel_sync", Handler: s.CancelSyncSetTplToInst})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/instances_sync_status", Handler: s.GetSetSyncDetails})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template_sync_status/bk_biz_id/{bk_biz_id}", Handler: s.ListSetTemplateSyncStatus})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template_sync_history/bk_biz_id/{bk_biz_id}", Handler: s.ListSetTemplateSyncHistory})
 	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/set_template_status", Handler: s.CheckSetInstUpdateToDateStatus})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/bk_biz_id/{bk_biz_id}/set_template_status", Handler: s.BatchCheckSetInstUpdateToDateStatus})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/instantiate", Handler: s.InstantiateSetTemplate})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/instantiate_task/{task_id}", Handler: s.GetSetTemplateInstantiateTask})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/sync_policy", Handler: s.GetSetTemplateSyncPolicy})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/sync_policy", Handler: s.UpdateSetTemplateSyncPolicy})
 
 	utility.AddToRestfulWebService(web)
 }
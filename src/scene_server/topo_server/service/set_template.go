@@ -98,6 +98,15 @@ func (s *Service) UpdateSetTemplate(ctx *rest.Contexts) {
 			return err
 		}
 
+		// the sets are synchronized by the sync policy scheduler in the maintenance window
+		if option.ServiceTemplateIDs != nil && setTemplate.SyncPolicy != nil && setTemplate.SyncPolicy.Type == metadata.SyncPolicyAuto {
+			setTemplate, err = s.Core.SetTemplateOperation().TriggerSync(ctx.Kit, setTemplate, metadata.SyncTriggerAuto)
+			if err != nil {
+				blog.Errorf("UpdateSetTemplate failed, TriggerSync failed, setTemplateID: %d, err: %v, rid: %s", setTemplateID, err, ctx.Kit.Rid)
+				return err
+			}
+		}
+
 		filter := &metadata.QueryCondition{
 			Page: metadata.BasePage{
 				Limit: common.BKNoLimit,
@@ -735,6 +744,40 @@ func (s *Service) SyncSetTplToInst(ctx *rest.Contexts) {
 	ctx.RespEntity(nil)
 }
 
+// CancelSyncSetTplToInst cancel the unfinished sync tasks of the sets
+func (s *Service) CancelSyncSetTplToInst(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setTemplateIDStr := ctx.Request.PathParameter(common.BKSetTemplateIDField)
+	setTemplateID, err := strconv.ParseInt(setTemplateIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKSetTemplateIDField))
+		return
+	}
+
+	option := metadata.SyncSetTplToInstOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if len(option.SetIDs) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "bk_set_ids"))
+		return
+	}
+
+	if err := s.Core.SetTemplateOperation().CancelSyncSetTplToInst(ctx.Kit, bizID, setTemplateID, option.SetIDs); err != nil {
+		blog.Errorf("CancelSyncSetTplToInst failed, bizID: %d, setTemplateID: %d, option: %+v, err: %s, rid: %s", bizID, setTemplateID, option, err.Error(), ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
 func (s *Service) GetSetSyncDetails(ctx *rest.Contexts) {
 	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
 	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
@@ -912,3 +955,132 @@ func (s *Service) BatchCheckSetInstUpdateToDateStatus(ctx *rest.Contexts) {
 	}
 	ctx.RespEntity(batchResult)
 }
+
+// InstantiateSetTemplate creates sets from the set template with the name pattern and index range, the sets are
+// created asynchronously by a task, the result of each set can be got by GetSetTemplateInstantiateTask.
+func (s *Service) InstantiateSetTemplate(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setTemplateIDStr := ctx.Request.PathParameter(common.BKSetTemplateIDField)
+	setTemplateID, err := strconv.ParseInt(setTemplateIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKSetTemplateIDField))
+		return
+	}
+
+	option := metadata.InstantiateSetTemplateOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	datas := []mapstr.MapStr{option.Attributes}
+	for _, attributes := range option.Overrides {
+		datas = append(datas, attributes)
+	}
+	if err := s.checkInstFieldsWritable(ctx.Kit, common.BKInnerObjIDSet, datas...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	taskDetail, ccErr := s.Core.SetTemplateOperation().InstantiateSetTemplate(ctx.Kit, bizID, setTemplateID, option)
+	if ccErr != nil {
+		blog.Errorf("InstantiateSetTemplate failed, bizID: %d, setTemplateID: %d, option: %+v, err: %v, rid: %s",
+			bizID, setTemplateID, option, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(taskDetail)
+}
+
+// GetSetTemplateInstantiateTask get the set template instantiation task, the status and response of each sub task
+// is the creation result of a set.
+func (s *Service) GetSetTemplateInstantiateTask(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setTemplateIDStr := ctx.Request.PathParameter(common.BKSetTemplateIDField)
+	setTemplateID, err := strconv.ParseInt(setTemplateIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKSetTemplateIDField))
+		return
+	}
+
+	taskID := ctx.Request.PathParameter("task_id")
+	taskDetail, ccErr := s.Core.SetTemplateOperation().GetInstantiateTaskDetail(ctx.Kit, bizID, setTemplateID, taskID)
+	if ccErr != nil {
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(taskDetail)
+}
+
+// GetSetTemplateSyncPolicy get the sync policy of the set template, set templates without a policy are synchronized manually
+func (s *Service) GetSetTemplateSyncPolicy(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setTemplateIDStr := ctx.Request.PathParameter(common.BKSetTemplateIDField)
+	setTemplateID, err := strconv.ParseInt(setTemplateIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKSetTemplateIDField))
+		return
+	}
+
+	setTemplate, ccErr := s.Engine.CoreAPI.CoreService().SetTemplate().GetSetTemplate(ctx.Kit.Ctx, ctx.Kit.Header, bizID, setTemplateID)
+	if ccErr != nil {
+		blog.Errorf("GetSetTemplateSyncPolicy failed, bizID: %d, setTemplateID: %d, err: %v, rid: %s", bizID, setTemplateID, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+
+	if setTemplate.SyncPolicy == nil {
+		ctx.RespEntity(metadata.SetTemplateSyncPolicy{Type: metadata.SyncPolicyManual})
+		return
+	}
+	ctx.RespEntity(setTemplate.SyncPolicy)
+}
+
+// UpdateSetTemplateSyncPolicy change the sync policy of the set template, a paused policy is resumed by setting paused to false
+func (s *Service) UpdateSetTemplateSyncPolicy(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setTemplateIDStr := ctx.Request.PathParameter(common.BKSetTemplateIDField)
+	setTemplateID, err := strconv.ParseInt(setTemplateIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKSetTemplateIDField))
+		return
+	}
+
+	option := metadata.UpdateSetTemplateSyncPolicyOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	setTemplate, ccErr := s.Core.SetTemplateOperation().UpdateSyncPolicy(ctx.Kit, bizID, setTemplateID, option)
+	if ccErr != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, bizID: %d, setTemplateID: %d, option: %+v, err: %v, rid: %s", bizID, setTemplateID, option, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(setTemplate.SyncPolicy)
+}
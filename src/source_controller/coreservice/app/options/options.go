@@ -29,6 +29,12 @@ type ServerOption struct {
 type Config struct {
 	Mongo mongo.Config
 	Redis redis.Config
+	// InstanceCacheObjects is the objects whose instances are opted into the redis cache
+	InstanceCacheObjects []string
+	// TrashObjects is the objects whose instances are soft deleted into the trash bin
+	TrashObjects []string
+	// TrashRetentionDays is the days the soft deleted instances are kept before they are purged
+	TrashRetentionDays int
 }
 
 //NewServerOption create a ServerOption object
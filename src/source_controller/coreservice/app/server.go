@@ -15,6 +15,8 @@ package app
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"configcenter/src/common"
@@ -26,6 +28,9 @@ import (
 	coresvr "configcenter/src/source_controller/coreservice/service"
 )
 
+// defaultTrashRetentionDays is the days the soft deleted instances are kept if it's not configured
+const defaultTrashRetentionDays = 30
+
 // CoreServer the core server
 type CoreServer struct {
 	Core    *backbone.Engine
@@ -38,6 +43,42 @@ func (t *CoreServer) onCoreServiceConfigUpdate(previous, current cc.ProcessConfi
 		t.Config = new(options.Config)
 	}
 
+	t.Config.InstanceCacheObjects = make([]string, 0)
+	for _, objID := range strings.Split(current.ConfigMap["cache.instanceObjects"], ",") {
+		objID = strings.TrimSpace(objID)
+		if len(objID) == 0 {
+			continue
+		}
+		if common.GetInstTableName(objID) != common.BKTableNameBaseInst {
+			blog.Warnf("object %s is not a custom object, can not be opted into the instance cache, skip", objID)
+			continue
+		}
+		t.Config.InstanceCacheObjects = append(t.Config.InstanceCacheObjects, objID)
+	}
+
+	t.Config.TrashObjects = make([]string, 0)
+	for _, objID := range strings.Split(current.ConfigMap["trash.objects"], ",") {
+		objID = strings.TrimSpace(objID)
+		if len(objID) == 0 {
+			continue
+		}
+		if objID != common.BKInnerObjIDHost && common.GetInstTableName(objID) != common.BKTableNameBaseInst {
+			blog.Warnf("object %s is neither a custom object nor host, can not be opted into the trash bin, skip", objID)
+			continue
+		}
+		t.Config.TrashObjects = append(t.Config.TrashObjects, objID)
+	}
+
+	t.Config.TrashRetentionDays = defaultTrashRetentionDays
+	if days, ok := current.ConfigMap["trash.retentionDays"]; ok && len(days) > 0 {
+		retentionDays, err := strconv.Atoi(days)
+		if err != nil || retentionDays <= 0 {
+			blog.Warnf("trash.retentionDays %s is invalid, use the default %d days", days, defaultTrashRetentionDays)
+		} else {
+			t.Config.TrashRetentionDays = retentionDays
+		}
+	}
+
 	blog.V(3).Infof("the new cfg:%#v the origin cfg:%#v", t.Config, current.ConfigMap)
 
 }
@@ -0,0 +1,260 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package business
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/coreservice/cache/tools"
+	"configcenter/src/storage/dal"
+	"github.com/tidwall/gjson"
+	"gopkg.in/redis.v5"
+)
+
+// NewCheckers returns the checkers which compare the business, set, module and topology cache with the db.
+func NewCheckers(rds *redis.Client, db dal.DB) []tools.KeyChecker {
+	return []tools.KeyChecker{
+		&detailChecker{
+			rds:       rds,
+			db:        db,
+			key:       bizKey,
+			resource:  metadata.CacheResourceBiz,
+			table:     common.BKTableNameBaseApp,
+			idField:   common.BKAppIDField,
+			nameField: common.BKAppNameField,
+		},
+		&detailChecker{
+			rds:         rds,
+			db:          db,
+			key:         setKey,
+			resource:    metadata.CacheResourceSet,
+			table:       common.BKTableNameBaseSet,
+			idField:     common.BKSetIDField,
+			nameField:   common.BKSetNameField,
+			parentField: common.BKParentIDField,
+		},
+		&detailChecker{
+			rds:         rds,
+			db:          db,
+			key:         moduleKey,
+			resource:    metadata.CacheResourceModule,
+			table:       common.BKTableNameBaseModule,
+			idField:     common.BKModuleIDField,
+			nameField:   common.BKModuleNameField,
+			parentField: common.BKSetIDField,
+		},
+		&topologyChecker{
+			rds:   rds,
+			level: &customLevel{key: customKey, rds: rds, db: db},
+		},
+	}
+}
+
+// detailChecker checks the detail keys of business, set or module.
+type detailChecker struct {
+	rds      *redis.Client
+	db       dal.DB
+	key      keyGenerator
+	resource string
+	table    string
+	idField  string
+	// the field of the instance name and the parent instance id, they are used to generate the list key value.
+	nameField   string
+	parentField string
+}
+
+func (c *detailChecker) Resource() string {
+	return c.resource
+}
+
+func (c *detailChecker) Pattern() string {
+	return fmt.Sprintf("%s:%s_detail:*", c.key.namespace, c.key.name)
+}
+
+// Match skips the lock and expire keys which have the same prefix with the detail keys.
+func (c *detailChecker) Match(key string) bool {
+	_, err := c.parseKey(key)
+	return err == nil
+}
+
+func (c *detailChecker) parseKey(key string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(key, fmt.Sprintf("%s:%s_detail:", c.key.namespace, c.key.name)), 10, 64)
+}
+
+func (c *detailChecker) getDetailFromMongo(instID int64) (string, error) {
+	detail := make(map[string]interface{})
+	filter := mapstr.MapStr{
+		c.idField: instID,
+	}
+	if err := c.db.Table(c.table).Find(filter).One(context.Background(), &detail); err != nil {
+		return "", err
+	}
+	js, _ := json.Marshal(detail)
+	return string(js), nil
+}
+
+func (c *detailChecker) Check(ctx context.Context, key string) (string, error) {
+	instID, _ := c.parseKey(key)
+	cached, err := c.rds.Get(key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+		return "", err
+	}
+
+	detail, err := c.getDetailFromMongo(instID)
+	if err != nil {
+		if c.db.IsNotFoundError(err) {
+			return metadata.CacheMismatchNotInDB, nil
+		}
+		return "", err
+	}
+
+	if !tools.JSONEqual(cached, detail) {
+		return metadata.CacheMismatchDiffer, nil
+	}
+	return "", nil
+}
+
+func (c *detailChecker) Repair(ctx context.Context, key string, reason string) error {
+	instID, _ := c.parseKey(key)
+	if reason == metadata.CacheMismatchNotInDB {
+		return c.deleteInstance(key, instID)
+	}
+
+	detail, err := c.getDetailFromMongo(instID)
+	if err != nil {
+		return err
+	}
+
+	info := gjson.GetMany(detail, c.nameField, common.BKAppIDField)
+	var parentID int64
+	if c.parentField != "" {
+		parentID = gjson.Get(detail, c.parentField).Int()
+	}
+
+	upsertListCache(&forUpsertCache{
+		instID:            instID,
+		parentID:          parentID,
+		name:              info[0].String(),
+		doc:               []byte(detail),
+		rds:               c.rds,
+		listKey:           c.key.listKeyWithBiz(info[1].Int()),
+		listExpireKey:     c.key.listExpireKeyWithBiz(info[1].Int()),
+		detailKey:         key,
+		detailExpireKey:   c.key.detailExpireKey(instID),
+		parseListKeyValue: c.key.parseListKeyValue,
+		genListKeyValue:   c.key.genListKeyValue,
+		getInstName:       c.getInstName,
+	})
+	return nil
+}
+
+func (c *detailChecker) getInstName(instID int64) (string, error) {
+	detail, err := c.getDetailFromMongo(instID)
+	if err != nil {
+		return "", err
+	}
+	return gjson.Get(detail, c.nameField).String(), nil
+}
+
+// deleteInstance deletes the instance's detail key and removes it from the list key of its business.
+func (c *detailChecker) deleteInstance(key string, instID int64) error {
+	cached, err := c.rds.Get(key).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipeline := c.rds.Pipeline()
+	defer pipeline.Close()
+	pipeline.Del(key, c.key.detailExpireKey(instID))
+
+	if cached != "" {
+		listKey := c.key.listKeyWithBiz(gjson.Get(cached, common.BKAppIDField).Int())
+		values, err := c.rds.SMembers(listKey).Result()
+		if err != nil {
+			return err
+		}
+		for _, value := range values {
+			id, _, _, err := c.key.parseListKeyValue(value)
+			if err == nil && id == instID {
+				pipeline.SRem(listKey, value)
+			}
+		}
+	}
+
+	_, err = pipeline.Exec()
+	return err
+}
+
+// topologyChecker checks the mainline topology key.
+type topologyChecker struct {
+	rds   *redis.Client
+	level *customLevel
+}
+
+func (c *topologyChecker) Resource() string {
+	return metadata.CacheResourceTopology
+}
+
+func (c *topologyChecker) Pattern() string {
+	return customKey.topologyKey()
+}
+
+func (c *topologyChecker) Match(key string) bool {
+	return key == customKey.topologyKey()
+}
+
+func (c *topologyChecker) rank() (string, error) {
+	relations, err := c.level.getMainlineTopology()
+	if err != nil {
+		return "", err
+	}
+	return customKey.topologyValue(c.level.rankMainlineTopology(relations)), nil
+}
+
+func (c *topologyChecker) Check(ctx context.Context, key string) (string, error) {
+	cached, err := c.rds.Get(key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+		return "", err
+	}
+
+	rank, err := c.rank()
+	if err != nil {
+		return "", err
+	}
+
+	if cached != rank {
+		return metadata.CacheMismatchDiffer, nil
+	}
+	return "", nil
+}
+
+func (c *topologyChecker) Repair(ctx context.Context, key string, reason string) error {
+	rank, err := c.rank()
+	if err != nil {
+		return err
+	}
+	return c.rds.Set(key, rank, 0).Err()
+}
@@ -17,13 +17,14 @@ import (
 
 	"configcenter/src/source_controller/coreservice/cache/business"
 	"configcenter/src/source_controller/coreservice/cache/host"
+	"configcenter/src/source_controller/coreservice/cache/instance"
 	"configcenter/src/source_controller/coreservice/cache/topo_tree"
 	"configcenter/src/storage/dal"
 	"configcenter/src/storage/reflector"
 	"gopkg.in/redis.v5"
 )
 
-func NewCache(rds *redis.Client, db dal.DB, event reflector.Interface) (*ClientSet, error) {
+func NewCache(rds *redis.Client, db dal.DB, event reflector.Interface, instObjects []string) (*ClientSet, error) {
 	if err := business.NewCache(event, rds, db); err != nil {
 		return nil, fmt.Errorf("new business cache failed, err: %v", err)
 	}
@@ -32,6 +33,10 @@ func NewCache(rds *redis.Client, db dal.DB, event reflector.Interface) (*ClientS
 		return nil, fmt.Errorf("new host cache failed, err: %v", err)
 	}
 
+	if err := instance.NewCache(event, rds, db, instObjects); err != nil {
+		return nil, fmt.Errorf("new instance cache failed, err: %v", err)
+	}
+
 	bizClient := business.NewClient(rds, db)
 	hostClient := host.NewClient(rds, db)
 
@@ -39,6 +44,7 @@ func NewCache(rds *redis.Client, db dal.DB, event reflector.Interface) (*ClientS
 		Topology: topo_tree.NewTopologyTree(bizClient),
 		Host:     hostClient,
 		Business: bizClient,
+		Instance: instance.NewClient(rds, db, instObjects),
 	}
 	return cache, nil
 }
@@ -47,4 +53,5 @@ type ClientSet struct {
 	Topology *topo_tree.TopologyTree
 	Host     *host.Client
 	Business *business.Client
+	Instance *instance.Client
 }
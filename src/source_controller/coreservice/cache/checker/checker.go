@@ -0,0 +1,138 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package checker checks whether the keys in the cache are consistent with the data in mongodb,
+// it's used to find out the stale cache caused by the missed change events or redis restarts,
+// and repair them if needed.
+package checker
+
+import (
+	"context"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/coreservice/cache/business"
+	"configcenter/src/source_controller/coreservice/cache/host"
+	"configcenter/src/source_controller/coreservice/cache/tools"
+	"configcenter/src/storage/dal"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/redis.v5"
+)
+
+const metricsNamespacePrefix = "cmdb_cache_check"
+
+type Checker struct {
+	rds      *redis.Client
+	checkers map[string]tools.KeyChecker
+
+	checkedTotal    *prometheus.CounterVec
+	mismatchedTotal *prometheus.CounterVec
+	repairedTotal   *prometheus.CounterVec
+	lastMismatched  *prometheus.GaugeVec
+}
+
+// NewChecker creates a cache checker, the check results are recorded as metrics if registry is not nil.
+func NewChecker(rds *redis.Client, db dal.DB, registry prometheus.Registerer) *Checker {
+	c := &Checker{
+		rds:      rds,
+		checkers: make(map[string]tools.KeyChecker),
+	}
+
+	for _, checker := range append(host.NewCheckers(rds, db), business.NewCheckers(rds, db)...) {
+		c.checkers[checker.Resource()] = checker
+	}
+
+	if registry != nil {
+		c.registerMetrics(registry)
+	}
+	return c
+}
+
+func (c *Checker) registerMetrics(registry prometheus.Registerer) {
+	c.checkedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: metricsNamespacePrefix + "_checked_total",
+			Help: "total number of the checked cache keys.",
+		},
+		[]string{"resource"},
+	)
+	registry.MustRegister(c.checkedTotal)
+
+	c.mismatchedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: metricsNamespacePrefix + "_mismatched_total",
+			Help: "total number of the cache keys which are not consistent with db.",
+		},
+		[]string{"resource", "reason"},
+	)
+	registry.MustRegister(c.mismatchedTotal)
+
+	c.repairedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: metricsNamespacePrefix + "_repaired_total",
+			Help: "total number of the repaired cache keys.",
+		},
+		[]string{"resource"},
+	)
+	registry.MustRegister(c.repairedTotal)
+
+	c.lastMismatched = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: metricsNamespacePrefix + "_last_mismatched",
+			Help: "number of the mismatched cache keys found by the last check.",
+		},
+		[]string{"resource"},
+	)
+	registry.MustRegister(c.lastMismatched)
+}
+
+// Check checks the cache resources of the option one by one, the results of the checked resources
+// are returned even if the check is broken by an error.
+func (c *Checker) Check(ctx context.Context, opt *metadata.CheckCacheOption) ([]metadata.CheckCacheResult, error) {
+	resources := opt.Resources
+	if len(resources) == 0 {
+		resources = metadata.CacheResources
+	}
+
+	results := make([]metadata.CheckCacheResult, 0)
+	for _, resource := range resources {
+		checker, exists := c.checkers[resource]
+		if !exists {
+			continue
+		}
+
+		result, err := tools.CheckKeys(ctx, c.rds, checker, opt.SampleSize, opt.Repair)
+		c.record(result)
+		if err != nil {
+			blog.Errorf("check %s cache failed, err: %v", resource, err)
+			return results, err
+		}
+		blog.Infof("check %s cache done, checked: %d, mismatched: %d, repaired: %d", resource, result.Checked,
+			result.Mismatched, result.Repaired)
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+func (c *Checker) record(result *metadata.CheckCacheResult) {
+	if c.checkedTotal == nil || result == nil {
+		return
+	}
+
+	c.checkedTotal.WithLabelValues(result.Resource).Add(float64(result.Checked))
+	c.repairedTotal.WithLabelValues(result.Resource).Add(float64(result.Repaired))
+	c.lastMismatched.WithLabelValues(result.Resource).Set(float64(result.Mismatched))
+	for reason, count := range result.Reasons {
+		c.mismatchedTotal.WithLabelValues(result.Resource, reason).Add(float64(count))
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package host
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/coreservice/cache/tools"
+	"configcenter/src/storage/dal"
+	"gopkg.in/redis.v5"
+)
+
+// NewCheckers returns the checkers which compare the host cache with the db.
+func NewCheckers(rds *redis.Client, db dal.DB) []tools.KeyChecker {
+	return []tools.KeyChecker{
+		&detailChecker{rds: rds, db: db},
+		&ipCloudChecker{rds: rds, db: db},
+	}
+}
+
+// detailChecker checks the host detail keys.
+type detailChecker struct {
+	rds *redis.Client
+	db  dal.DB
+}
+
+func (c *detailChecker) Resource() string {
+	return metadata.CacheResourceHost
+}
+
+func (c *detailChecker) Pattern() string {
+	return hostKey.namespace + ":detail:*"
+}
+
+func (c *detailChecker) Match(key string) bool {
+	_, err := c.parseKey(key)
+	return err == nil
+}
+
+func (c *detailChecker) parseKey(key string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(key, hostKey.namespace+":detail:"), 10, 64)
+}
+
+func (c *detailChecker) Check(ctx context.Context, key string) (string, error) {
+	hostID, _ := c.parseKey(key)
+	cached, err := c.rds.Get(key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			// the key has been expired after scanned.
+			return "", nil
+		}
+		return "", err
+	}
+
+	_, _, detail, err := getHostDetailsFromMongoWithHostID(c.db, hostID)
+	if err != nil {
+		if c.db.IsNotFoundError(err) {
+			return metadata.CacheMismatchNotInDB, nil
+		}
+		return "", err
+	}
+
+	if !tools.JSONEqual(cached, string(detail)) {
+		return metadata.CacheMismatchDiffer, nil
+	}
+	return "", nil
+}
+
+func (c *detailChecker) Repair(ctx context.Context, key string, reason string) error {
+	if reason == metadata.CacheMismatchNotInDB {
+		return c.rds.Del(key).Err()
+	}
+
+	hostID, _ := c.parseKey(key)
+	ips, cloudID, detail, err := getHostDetailsFromMongoWithHostID(c.db, hostID)
+	if err != nil {
+		return err
+	}
+
+	pipeline := c.rds.Pipeline()
+	defer pipeline.Close()
+	ttl := hostKey.WithRandomExpireSeconds()
+	for _, ip := range strings.Split(ips, ",") {
+		pipeline.Set(hostKey.IPCloudIDKey(ip, cloudID), hostID, ttl)
+	}
+	pipeline.Set(key, detail, ttl)
+	_, err = pipeline.Exec()
+	return err
+}
+
+// ipCloudChecker checks the keys of the relation between the host's ip, cloud id and host id.
+type ipCloudChecker struct {
+	rds *redis.Client
+	db  dal.DB
+}
+
+func (c *ipCloudChecker) Resource() string {
+	return metadata.CacheResourceHostIP
+}
+
+func (c *ipCloudChecker) Pattern() string {
+	return hostKey.namespace + ":ip_cloud_id:*"
+}
+
+func (c *ipCloudChecker) Match(key string) bool {
+	return true
+}
+
+// parseKey parses the ip and cloud id from the key, the cloud id is after the last colon,
+// because the ip may be an ipv6 address which contains colons.
+func (c *ipCloudChecker) parseKey(key string) (string, int64, error) {
+	value := strings.TrimPrefix(key, hostKey.namespace+":ip_cloud_id:")
+	idx := strings.LastIndex(value, ":")
+	if idx <= 0 {
+		return "", 0, errors.New("invalid ip cloud id key")
+	}
+	cloudID, err := strconv.ParseInt(value[idx+1:], 10, 64)
+	if err != nil {
+		return "", 0, err
+	}
+	return value[:idx], cloudID, nil
+}
+
+func (c *ipCloudChecker) Check(ctx context.Context, key string) (string, error) {
+	ip, cloudID, err := c.parseKey(key)
+	if err != nil {
+		return metadata.CacheMismatchInvalid, nil
+	}
+
+	cached, err := c.rds.Get(key).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+		return metadata.CacheMismatchInvalid, nil
+	}
+
+	hostID, _, err := getHostDetailsFromMongoWithIP(c.db, ip, cloudID)
+	if err != nil {
+		if c.db.IsNotFoundError(err) {
+			return metadata.CacheMismatchNotInDB, nil
+		}
+		return "", err
+	}
+
+	if hostID != cached {
+		return metadata.CacheMismatchDiffer, nil
+	}
+	return "", nil
+}
+
+func (c *ipCloudChecker) Repair(ctx context.Context, key string, reason string) error {
+	if reason != metadata.CacheMismatchDiffer {
+		return c.rds.Del(key).Err()
+	}
+
+	ip, cloudID, err := c.parseKey(key)
+	if err != nil {
+		return err
+	}
+	hostID, _, err := getHostDetailsFromMongoWithIP(c.db, ip, cloudID)
+	if err != nil {
+		return err
+	}
+	return c.rds.Set(key, hostID, hostKey.WithRandomExpireSeconds()).Err()
+}
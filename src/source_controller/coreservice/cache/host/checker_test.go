@@ -0,0 +1,42 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package host
+
+import "testing"
+
+func TestIPCloudCheckerParseKey(t *testing.T) {
+	c := new(ipCloudChecker)
+	cases := []struct {
+		key     string
+		ip      string
+		cloudID int64
+		valid   bool
+	}{
+		{key: hostKey.IPCloudIDKey("127.0.0.1", 0), ip: "127.0.0.1", cloudID: 0, valid: true},
+		{key: hostKey.IPCloudIDKey("fe80::1", 12), ip: "fe80::1", cloudID: 12, valid: true},
+		{key: hostKey.namespace + ":ip_cloud_id:127.0.0.1", valid: false},
+		{key: hostKey.namespace + ":ip_cloud_id:127.0.0.1:abc", valid: false},
+	}
+
+	for _, ca := range cases {
+		ip, cloudID, err := c.parseKey(ca.key)
+		if (err == nil) != ca.valid {
+			t.Errorf("parse key %s, expected valid: %v, got err: %v", ca.key, ca.valid, err)
+			continue
+		}
+		if ca.valid && (ip != ca.ip || cloudID != ca.cloudID) {
+			t.Errorf("parse key %s, expected %s:%d, got %s:%d", ca.key, ca.ip, ca.cloudID, ip, cloudID)
+		}
+	}
+}
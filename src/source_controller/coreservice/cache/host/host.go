@@ -279,11 +279,16 @@ const exactIPRegexp = `(^IP_PLACEHOLDER$)|(^IP_PLACEHOLDER[,]{1})|([,]{1}IP_PLAC
 
 func getHostDetailsFromMongoWithIP(db dal.DB, innerIP string, cloudID int64) (hostID int64, detail []byte, err error) {
 	filter := mapstr.MapStr{
-		common.BKHostInnerIPField: mapstr.MapStr{
-			common.BKDBLIKE: strings.Replace(exactIPRegexp, "IP_PLACEHOLDER", params.SpecialCharChange(innerIP), -1),
-		},
 		common.BKCloudIDField: cloudID,
 	}
+	// match with the normalized ip if possible, so that an ipv6 address written in different ways can be matched.
+	if normalized, err := util.NormalizeIP(innerIP); err == nil {
+		filter[common.BKHostInnerIPNormalizedField] = normalized
+	} else {
+		filter[common.BKHostInnerIPField] = mapstr.MapStr{
+			common.BKDBLIKE: strings.Replace(exactIPRegexp, "IP_PLACEHOLDER", params.SpecialCharChange(innerIP), -1),
+		}
+	}
 	host := make(map[string]interface{})
 	err = db.Table(common.BKTableNameBaseHost).Find(filter).One(context.Background(), &host)
 	if err != nil {
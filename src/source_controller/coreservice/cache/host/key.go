@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"configcenter/src/common"
+	"configcenter/src/common/util"
 )
 
 const hostKeyNamespace = common.BKCacheKeyV3Prefix + "host"
@@ -53,8 +54,9 @@ func (h hostKeyGenerator) HostDetailLockKey(hostID int64) string {
 // key: bk_host_innerip:bk_cloud_id
 // value: bk_host_id
 // this key has a ttl, which is h.expireSeconds
+// the ip is converted to its canonical form, so that an ipv6 address written in different ways has the same key.
 func (h hostKeyGenerator) IPCloudIDKey(ip string, cloudID int64) string {
-	return h.namespace + ":ip_cloud_id:" + ip + ":" + strconv.FormatInt(cloudID, 10)
+	return h.namespace + ":ip_cloud_id:" + util.CanonicalIP(ip) + ":" + strconv.FormatInt(cloudID, 10)
 }
 
 func (h hostKeyGenerator) ListDoneKey() string {
@@ -0,0 +1,77 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"fmt"
+	"sync"
+
+	"configcenter/src/source_controller/coreservice/cache/tools"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/reflector"
+	"gopkg.in/redis.v5"
+)
+
+var client *Client
+var clientOnce sync.Once
+var cache *instCache
+
+// NewClient create the instance cache client, only the instances of the objects
+// which are opted into the cache can be searched with it.
+func NewClient(rds *redis.Client, db dal.DB, objIDs []string) *Client {
+
+	if client != nil {
+		return client
+	}
+
+	clientOnce.Do(func() {
+		objects := make(map[string]bool)
+		for _, objID := range objIDs {
+			objects[objID] = true
+		}
+		client = &Client{
+			rds:     rds,
+			db:      db,
+			lock:    tools.NewRefreshingLock(),
+			objects: objects,
+			uniques: newUniqueCache(db),
+		}
+	})
+
+	return client
+}
+
+// Attention, it can only be called for once.
+func NewCache(event reflector.Interface, rds *redis.Client, db dal.DB, objIDs []string) error {
+
+	if cache != nil {
+		return nil
+	}
+
+	// cache has not been initialized.
+	cache = &instCache{
+		key:     instKey,
+		rds:     rds,
+		event:   event,
+		db:      db,
+		uniques: newUniqueCache(db),
+	}
+
+	for _, objID := range objIDs {
+		if err := cache.Run(objID); err != nil {
+			return fmt.Errorf("run object %s instance cache failed, err: %v", objID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/json"
+	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/coreservice/cache/tools"
+	"configcenter/src/storage/dal"
+	"github.com/tidwall/gjson"
+	"gopkg.in/redis.v5"
+)
+
+// ErrObjectNotCached means the object's instances are not opted into the cache.
+var ErrObjectNotCached = errors.New("object instance is not cached")
+
+// ErrNotUniqueKey means the properties of the keys are not one of the object's unique rules.
+var ErrNotUniqueKey = errors.New("keys are not the object's unique key")
+
+// ErrInstNotFound means the instance does not exist or belongs to another supplier account.
+var ErrInstNotFound = errors.New("instance is not found")
+
+type Client struct {
+	rds     *redis.Client
+	db      dal.DB
+	lock    tools.RefreshingLock
+	objects map[string]bool
+	uniques *uniqueCache
+}
+
+// IsCached returns whether the object's instances are cached.
+func (c *Client) IsCached(objID string) bool {
+	return c.objects[objID]
+}
+
+// GetInstWithID get instance of the owner with object id and instance id.
+// fields allows you can specify which fields you need only.
+func (c *Client) GetInstWithID(ctx context.Context, ownerID string, opt *metadata.SearchInstWithIDOption) (string, error) {
+	rid := ctx.Value(common.ContextRequestIDField)
+	if !c.IsCached(opt.ObjID) {
+		return "", ErrObjectNotCached
+	}
+
+	data, err := c.rds.Get(instKey.DetailKey(opt.ObjID, opt.InstID)).Result()
+	if err == nil {
+		// already get the data, use cache
+		if !isOwnedBy(data, ownerID) {
+			return "", ErrInstNotFound
+		}
+		return cutFields(data, opt.Fields), nil
+	}
+	if err != redis.Nil {
+		// return directly to avoid cache penetration
+		blog.Errorf("get object %s instance %d from redis failed, err: %v, rid: %s", opt.ObjID, opt.InstID, err, rid)
+		return "", err
+	}
+
+	// data has already expired, need to refresh from db
+	detail, err := getInstDetailFromMongo(c.db, opt.ObjID, opt.InstID)
+	if err != nil {
+		if c.db.IsNotFoundError(err) {
+			return "", ErrInstNotFound
+		}
+		blog.Errorf("get object %s instance %d, and cache expired, but get from mongo failed, err: %v, rid: %s",
+			opt.ObjID, opt.InstID, err, rid)
+		return "", err
+	}
+
+	// try refresh cache
+	c.tryRefreshInstDetail(opt.ObjID, opt.InstID, detail)
+
+	if !isOwnedBy(string(detail), ownerID) {
+		return "", ErrInstNotFound
+	}
+	return cutFields(string(detail), opt.Fields), nil
+}
+
+// isOwnedBy returns whether the instance detail belongs to the owner, the super owner owns all the instances.
+func isOwnedBy(detail string, ownerID string) bool {
+	if ownerID == common.BKSuperOwnerID {
+		return true
+	}
+	return gjson.Get(detail, common.BKOwnerIDField).String() == ownerID
+}
+
+// GetInstWithUniqueKey get instance with the values of one of the object's unique rules.
+func (c *Client) GetInstWithUniqueKey(ctx context.Context, ownerID string, opt *metadata.SearchInstWithUniqueKeyOption) (
+	string, error) {
+
+	rid := ctx.Value(common.ContextRequestIDField)
+	if !c.IsCached(opt.ObjID) {
+		return "", ErrObjectNotCached
+	}
+
+	properties := make([]string, 0, len(opt.Keys))
+	for property := range opt.Keys {
+		properties = append(properties, property)
+	}
+	matched, err := c.uniques.match(opt.ObjID, properties)
+	if err != nil {
+		blog.Errorf("get object %s unique failed, err: %v, rid: %s", opt.ObjID, err, rid)
+		return "", err
+	}
+	if !matched {
+		return "", ErrNotUniqueKey
+	}
+
+	values, err := keysToValues(opt.Keys)
+	if err != nil {
+		return "", fmt.Errorf("invalid unique key values, err: %v", err)
+	}
+
+	detail, err := c.getInstDetailWithUniqueKey(opt.ObjID, ownerID, values)
+	if err != nil {
+		blog.Errorf("get object %s instance with unique key from redis failed, err: %v, rid: %s", opt.ObjID, err, rid)
+		return "", err
+	}
+	if len(detail) != 0 {
+		return cutFields(detail, opt.Fields), nil
+	}
+
+	// not in cache, or the cache is out of date, need to refresh from db
+	instID, js, err := getInstDetailFromMongoWithUniqueKey(c.db, opt.ObjID, ownerID, opt.Keys)
+	if err != nil {
+		blog.Errorf("get object %s instance with unique key %v from mongo failed, err: %v, rid: %s", opt.ObjID, opt.Keys, err, rid)
+		return "", err
+	}
+
+	c.tryRefreshInstDetail(opt.ObjID, instID, js)
+
+	return cutFields(string(js), opt.Fields), nil
+}
+
+// getInstDetailWithUniqueKey returns empty string if the instance is not in the cache.
+func (c *Client) getInstDetailWithUniqueKey(objID, ownerID string, values map[string]string) (string, error) {
+	instID, err := c.rds.Get(instKey.UniqueKey(objID, ownerID, values)).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+		return "", err
+	}
+
+	detail, err := c.rds.Get(instKey.DetailKey(objID, instID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+		return "", err
+	}
+
+	// the unique key may be out of date when the instance's unique values changed,
+	// so we need to check the detail's values again.
+	for property, value := range values {
+		if gjson.Get(detail, property).String() != value {
+			return "", nil
+		}
+	}
+	return detail, nil
+}
+
+func (c *Client) tryRefreshInstDetail(objID string, instID int64, detail []byte) {
+	key := instKey.DetailKey(objID, instID)
+	if !c.lock.CanRefresh(key) {
+		return
+	}
+	// set refreshing status
+	c.lock.SetRefreshing(key)
+
+	go func() {
+		defer func() {
+			c.lock.SetUnRefreshing(key)
+		}()
+
+		refreshInstDetailCache(c.rds, c.uniques, objID, instID, detail)
+	}()
+}
+
+func cutFields(detail string, fields []string) string {
+	if len(fields) == 0 {
+		return detail
+	}
+	return *json.CutJsonDataWithFields(&detail, fields)
+}
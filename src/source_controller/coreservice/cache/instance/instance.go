@@ -0,0 +1,245 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/reflector"
+	"configcenter/src/storage/stream/types"
+	"github.com/tidwall/gjson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+	"gopkg.in/redis.v5"
+)
+
+type instCache struct {
+	key     instKeyGenerator
+	rds     *redis.Client
+	event   reflector.Interface
+	db      dal.DB
+	uniques *uniqueCache
+}
+
+// Run list and watch the instances of the object to keep the cache up to date.
+func (i *instCache) Run(objID string) error {
+
+	opts := types.Options{
+		EventStruct: new(map[string]interface{}),
+		Collection:  common.BKTableNameBaseInst,
+		Filter: mapstr.MapStr{
+			common.BKObjIDField: objID,
+		},
+	}
+
+	_, err := i.rds.Get(i.key.ListDoneKey(objID)).Result()
+	if err != nil {
+		if err != redis.Nil {
+			blog.Errorf("get object %s instance list done redis key failed, err: %v", objID, err)
+			return fmt.Errorf("get object %s instance list done redis key failed, err: %v", objID, err)
+		}
+		listCap := &reflector.Capable{
+			OnChange: reflector.OnChangeEvent{
+				OnLister: i.onUpsert,
+				OnAdd:    i.onUpsert,
+				OnUpdate: i.onUpsert,
+				OnListerDone: func() {
+					i.onListDone(objID)
+				},
+				OnDelete: i.onDelete,
+			},
+		}
+		// do with list watcher.
+		page := 500
+		listOpts := &types.ListWatchOptions{
+			Options:  opts,
+			PageSize: &page,
+		}
+		blog.Infof("do object %s instance cache with list watcher.", objID)
+		return i.event.ListWatcher(context.Background(), listOpts, listCap)
+	}
+
+	watchCap := &reflector.Capable{
+		OnChange: reflector.OnChangeEvent{
+			OnAdd:    i.onUpsert,
+			OnUpdate: i.onUpsert,
+			OnDelete: i.onDelete,
+		},
+	}
+	// do with watcher only.
+	watchOpts := &types.WatchOptions{
+		Options: opts,
+	}
+	blog.Infof("do object %s instance cache with only watcher", objID)
+	return i.event.Watcher(context.Background(), watchOpts, watchCap)
+}
+
+func (i *instCache) onUpsert(e *types.Event) {
+	blog.V(4).Infof("received instance upsert event, oid: %s, doc: %s", e.Oid, e.DocBytes)
+
+	elements := gjson.GetManyBytes(e.DocBytes, common.BKObjIDField, common.BKInstIDField)
+	objID := elements[0].String()
+	instID := elements[1].Int()
+	if len(objID) == 0 || instID <= 0 {
+		blog.Errorf("received instance upsert event, but got invalid object id or instance id, doc: %s", e.DocBytes)
+		return
+	}
+
+	// get instance details from db again to avoid dirty data.
+	detail, err := getInstDetailFromMongo(i.db, objID, instID)
+	if err != nil {
+		blog.Errorf("received object %s instance %d upsert event, but get detail from mongodb failed, err: %v",
+			objID, instID, err)
+		return
+	}
+	refreshInstDetailCache(i.rds, i.uniques, objID, instID, detail)
+}
+
+func (i *instCache) onDelete(e *types.Event) {
+	blog.Infof("received instance delete event, oid: %s", e.Oid)
+
+	filter := mapstr.MapStr{
+		"oid": e.Oid,
+	}
+	doc := bsonx.Doc{}
+	err := i.db.Table(common.BKTableNameDelArchive).Find(filter).One(context.Background(), &doc)
+	if err != nil {
+		blog.Errorf("received delete instance event, but get archive deleted doc from mongodb failed, oid: %s, err: %v", e.Oid, err)
+		return
+	}
+
+	byt, err := bson.MarshalExtJSON(doc.Lookup("detail"), false, false)
+	if err != nil {
+		blog.Errorf("received delete instance event, but marshal doc to bytes failed, oid: %s, err: %v", e.Oid, err)
+		return
+	}
+
+	elements := gjson.GetManyBytes(byt, common.BKObjIDField, common.BKInstIDField, common.BKOwnerIDField)
+	objID := elements[0].String()
+	instID := elements[1].Int()
+	ownerID := elements[2].String()
+
+	pipe := i.rds.Pipeline()
+	// delete the unique keys which point to this instance
+	properties, err := i.uniques.get(objID)
+	if err != nil {
+		blog.Errorf("received instance delete event, oid: %s, but get object %s unique failed, err: %v", e.Oid, objID, err)
+	}
+	for _, values := range uniqueValues(byt, properties) {
+		pipe.Del(i.key.UniqueKey(objID, ownerID, values))
+	}
+
+	// delete instance details
+	pipe.Del(i.key.DetailKey(objID, instID))
+	_, err = pipe.Exec()
+	if err != nil {
+		blog.Errorf("received instance delete event, oid: %s, but delete unique key and detail failed, err: %v", e.Oid, err)
+		return
+	}
+	blog.Infof("received instance delete event, oid: %s, delete object %s instance %d detail success", e.Oid, objID, instID)
+}
+
+func (i *instCache) onListDone(objID string) {
+	if err := i.rds.Set(i.key.ListDoneKey(objID), "done", 0).Err(); err != nil {
+		blog.Errorf("list object %s instance data to cache and list done, but set list done key failed, err: %v", objID, err)
+		return
+	}
+	blog.Infof("list object %s instance data to cache and list done", objID)
+}
+
+// refreshInstDetailCache refresh the instance's detail cache and the unique keys of it.
+func refreshInstDetailCache(rds *redis.Client, uniques *uniqueCache, objID string, instID int64, detail []byte) {
+	// get refresh lock to avoid concurrent
+	success, err := rds.SetNX(instKey.DetailLockKey(objID, instID), 1, 10*time.Second).Result()
+	if err != nil {
+		blog.Errorf("upsert object %s instance %d detail cache, but got redis lock failed, err: %v", objID, instID, err)
+		return
+	}
+
+	if !success {
+		blog.V(4).Infof("upsert object %s instance %d detail cache, but do not get redis lock. skip", objID, instID)
+		return
+	}
+
+	defer func() {
+		if err := rds.Del(instKey.DetailLockKey(objID, instID)).Err(); err != nil {
+			blog.Errorf("upsert object %s instance %d detail cache, but delete redis lock failed, err: %v", objID, instID, err)
+		}
+	}()
+
+	properties, err := uniques.get(objID)
+	if err != nil {
+		// the detail can still be cached, the unique key lookup falls back to mongodb.
+		blog.Errorf("upsert object %s instance %d detail cache, but get object unique failed, err: %v", objID, instID, err)
+	}
+
+	// we have get the lock, and now we can refresh the cache.
+	pipeline := rds.Pipeline()
+	ttl := instKey.WithRandomExpireSeconds()
+	ownerID := gjson.GetBytes(detail, common.BKOwnerIDField).String()
+	for _, values := range uniqueValues(detail, properties) {
+		pipeline.Set(instKey.UniqueKey(objID, ownerID, values), instID, ttl)
+	}
+
+	pipeline.Set(instKey.DetailKey(objID, instID), detail, ttl)
+	_, err = pipeline.Exec()
+	if err != nil {
+		blog.Errorf("upsert object %s instance %d cache, but upsert to redis failed, err: %v", objID, instID, err)
+		return
+	}
+	blog.V(4).Infof("refresh object %s instance %d cache success, ttl: %ds", objID, instID, ttl/time.Second)
+}
+
+func getInstDetailFromMongo(db dal.DB, objID string, instID int64) ([]byte, error) {
+	filter := mapstr.MapStr{
+		common.BKObjIDField:  objID,
+		common.BKInstIDField: instID,
+	}
+	inst := make(map[string]interface{})
+	err := db.Table(common.BKTableNameBaseInst).Find(filter).One(context.Background(), &inst)
+	if err != nil {
+		blog.Errorf("get object %s instance %d from mongodb for cache failed, err: %v", objID, instID, err)
+		return nil, err
+	}
+
+	return json.Marshal(inst)
+}
+
+func getInstDetailFromMongoWithUniqueKey(db dal.DB, objID, ownerID string, keys map[string]interface{}) (int64, []byte, error) {
+	filter := mapstr.MapStr{
+		common.BKObjIDField:   objID,
+		common.BKOwnerIDField: ownerID,
+	}
+	for property, value := range keys {
+		filter[property] = value
+	}
+	inst := make(map[string]interface{})
+	err := db.Table(common.BKTableNameBaseInst).Find(filter).One(context.Background(), &inst)
+	if err != nil {
+		blog.Errorf("get object %s instance from mongodb with unique key %v for cache failed, err: %v", objID, keys, err)
+		return 0, nil, err
+	}
+
+	detail, err := json.Marshal(inst)
+	if err != nil {
+		return 0, nil, err
+	}
+	return gjson.GetBytes(detail, common.BKInstIDField).Int(), detail, nil
+}
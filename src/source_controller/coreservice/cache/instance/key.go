@@ -0,0 +1,79 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+)
+
+const instKeyNamespace = common.BKCacheKeyV3Prefix + "inst"
+
+var instKey = instKeyGenerator{
+	namespace: instKeyNamespace,
+	// 30 minutes
+	expireSeconds:      30 * 60 * time.Second,
+	expireRangeSeconds: [2]int{-600, 600},
+}
+
+// instKeyGenerator is the same with the host's key generator, the keys have a
+// random ttl to avoid the keys expire at the same time.
+type instKeyGenerator struct {
+	namespace string
+	// expireSeconds is defined how long is the ttl for the key.
+	// it's always used with the expireRangeSeconds to avoid the keys is expired at same time.
+	expireSeconds time.Duration
+	// min:[0], max:[1]
+	expireRangeSeconds [2]int
+}
+
+func (k instKeyGenerator) DetailKey(objID string, instID int64) string {
+	return k.namespace + ":" + objID + ":detail:" + strconv.FormatInt(instID, 10)
+}
+
+func (k instKeyGenerator) DetailLockKey(objID string, instID int64) string {
+	return k.namespace + ":" + objID + ":detail:lock:" + strconv.FormatInt(instID, 10)
+}
+
+// key to store the relation with the unique key's value and instance id:
+// key: supplier account:property1=value1&property2=value2, properties are sorted.
+// value: bk_inst_id
+// this key has a ttl, which is k.expireSeconds
+func (k instKeyGenerator) UniqueKey(objID, ownerID string, values map[string]string) string {
+	properties := make([]string, 0, len(values))
+	for property := range values {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+
+	pairs := make([]string, len(properties))
+	for idx, property := range properties {
+		pairs[idx] = property + "=" + values[property]
+	}
+	return k.namespace + ":" + objID + ":unique:" + ownerID + ":" + strings.Join(pairs, "&")
+}
+
+func (k instKeyGenerator) ListDoneKey(objID string) string {
+	return k.namespace + ":" + objID + ":listdone"
+}
+
+func (k instKeyGenerator) WithRandomExpireSeconds() time.Duration {
+	rand.Seed(time.Now().UnixNano())
+	seconds := rand.Intn(k.expireRangeSeconds[1]-k.expireRangeSeconds[0]) + k.expireRangeSeconds[0]
+	return k.expireSeconds + time.Duration(seconds)*time.Second
+}
@@ -0,0 +1,178 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal"
+	"github.com/tidwall/gjson"
+)
+
+// uniqueExpireDuration is how long the object's unique rules are cached in memory.
+const uniqueExpireDuration = time.Minute
+
+type objectUnique struct {
+	expireAt time.Time
+	// the sorted property ids of each unique rule of the object
+	properties [][]string
+}
+
+// uniqueCache caches the properties of the objects' unique rules, which are used to
+// generate the unique keys of the instances.
+type uniqueCache struct {
+	db      dal.DB
+	lock    sync.RWMutex
+	uniques map[string]objectUnique
+}
+
+func newUniqueCache(db dal.DB) *uniqueCache {
+	return &uniqueCache{
+		db:      db,
+		uniques: make(map[string]objectUnique),
+	}
+}
+
+func (u *uniqueCache) get(objID string) ([][]string, error) {
+	u.lock.RLock()
+	unique, exist := u.uniques[objID]
+	u.lock.RUnlock()
+	if exist && time.Now().Before(unique.expireAt) {
+		return unique.properties, nil
+	}
+
+	properties, err := getObjectUniqueProperties(u.db, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.lock.Lock()
+	u.uniques[objID] = objectUnique{
+		expireAt:   time.Now().Add(uniqueExpireDuration),
+		properties: properties,
+	}
+	u.lock.Unlock()
+	return properties, nil
+}
+
+// match returns whether the properties are the same with one of the object's unique rules.
+func (u *uniqueCache) match(objID string, properties []string) (bool, error) {
+	uniques, err := u.get(objID)
+	if err != nil {
+		return false, err
+	}
+
+	sorted := append([]string{}, properties...)
+	sort.Strings(sorted)
+	key := strings.Join(sorted, ",")
+	for _, unique := range uniques {
+		if strings.Join(unique, ",") == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func getObjectUniqueProperties(db dal.DB, objID string) ([][]string, error) {
+	uniques := make([]metadata.ObjectUnique, 0)
+	filter := mapstr.MapStr{common.BKObjIDField: objID}
+	if err := db.Table(common.BKTableNameObjUnique).Find(filter).All(context.Background(), &uniques); err != nil {
+		return nil, err
+	}
+
+	attrIDs := make([]uint64, 0)
+	for _, unique := range uniques {
+		for _, key := range unique.Keys {
+			if key.Kind == metadata.UniqueKeyKindProperty {
+				attrIDs = append(attrIDs, key.ID)
+			}
+		}
+	}
+	if len(attrIDs) == 0 {
+		return make([][]string, 0), nil
+	}
+
+	attrs := make([]metadata.Attribute, 0)
+	filter = mapstr.MapStr{
+		common.BKObjIDField: objID,
+		common.BKFieldID:    mapstr.MapStr{common.BKDBIN: attrIDs},
+	}
+	err := db.Table(common.BKTableNameObjAttDes).Find(filter).Fields(common.BKFieldID, common.BKPropertyIDField).
+		All(context.Background(), &attrs)
+	if err != nil {
+		return nil, err
+	}
+	propertyIDs := make(map[uint64]string)
+	for _, attr := range attrs {
+		propertyIDs[uint64(attr.ID)] = attr.PropertyID
+	}
+
+	properties := make([][]string, 0, len(uniques))
+	for _, unique := range uniques {
+		keys := make([]string, 0, len(unique.Keys))
+		for _, key := range unique.Keys {
+			if propertyID, exist := propertyIDs[key.ID]; exist && key.Kind == metadata.UniqueKeyKindProperty {
+				keys = append(keys, propertyID)
+			}
+		}
+		if len(keys) == 0 || len(keys) != len(unique.Keys) {
+			continue
+		}
+		sort.Strings(keys)
+		properties = append(properties, keys)
+	}
+	return properties, nil
+}
+
+// uniqueValues returns the instance's values of each unique rule, the rule is skipped
+// if the instance doesn't have value of any of the rule's properties.
+func uniqueValues(detail []byte, properties [][]string) []map[string]string {
+	all := make([]map[string]string, 0)
+	for _, unique := range properties {
+		elements := gjson.GetManyBytes(detail, unique...)
+		values := make(map[string]string)
+		for idx, element := range elements {
+			if !element.Exists() || element.Type == gjson.Null || len(element.String()) == 0 {
+				break
+			}
+			values[unique[idx]] = element.String()
+		}
+		if len(values) != len(unique) {
+			continue
+		}
+		all = append(all, values)
+	}
+	return all
+}
+
+// keysToValues converts the unique key's values requested by user to the same format with
+// the values got from the instance's detail.
+func keysToValues(keys map[string]interface{}) (map[string]string, error) {
+	values := make(map[string]string)
+	for property, value := range keys {
+		js, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		values[property] = gjson.ParseBytes(js).String()
+	}
+	return values, nil
+}
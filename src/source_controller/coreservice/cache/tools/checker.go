@@ -0,0 +1,118 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/json"
+	"configcenter/src/common/metadata"
+	"gopkg.in/redis.v5"
+)
+
+// KeyChecker compares a kind of cached keys with the data in db.
+type KeyChecker interface {
+	// Resource returns the resource name of the cached keys.
+	Resource() string
+	// Pattern is used to scan the cached keys.
+	Pattern() string
+	// Match tells whether the scanned key should be checked, such as lock keys are not checked.
+	Match(key string) bool
+	// Check compares the key with the db, returns the mismatch reason, empty reason means the key is consistent.
+	Check(ctx context.Context, key string) (string, error)
+	// Repair rewrites the key with the data in db, or deletes the key if the data not exist in db.
+	Repair(ctx context.Context, key string, reason string) error
+}
+
+// scanCount is the count hint of each redis scan command.
+const scanCount = 500
+
+// CheckKeys scans the keys of the checker, and checks at most sampleSize keys, all the keys are
+// checked if sampleSize is 0. the mismatched keys are repaired if repair is true.
+func CheckKeys(ctx context.Context, rds *redis.Client, checker KeyChecker, sampleSize int64, repair bool) (
+	*metadata.CheckCacheResult, error) {
+
+	result := &metadata.CheckCacheResult{
+		Resource: checker.Resource(),
+		Reasons:  make(map[string]int64),
+		Details:  make([]metadata.CacheMismatch, 0),
+	}
+
+	var cursor uint64
+	for {
+		keys, next, err := rds.Scan(cursor, checker.Pattern(), scanCount).Result()
+		if err != nil {
+			return result, fmt.Errorf("scan %s keys failed, err: %v", checker.Pattern(), err)
+		}
+
+		for _, key := range keys {
+			if !checker.Match(key) {
+				continue
+			}
+
+			if sampleSize > 0 && result.Checked >= sampleSize {
+				return result, nil
+			}
+
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+
+			reason, err := checker.Check(ctx, key)
+			if err != nil {
+				return result, fmt.Errorf("check cache key %s failed, err: %v", key, err)
+			}
+			result.Checked++
+
+			if reason == "" {
+				continue
+			}
+			result.Mismatched++
+			result.Reasons[reason]++
+
+			mismatch := metadata.CacheMismatch{Key: key, Reason: reason}
+			if repair {
+				if err := checker.Repair(ctx, key, reason); err != nil {
+					blog.Errorf("repair cache key %s with reason %s failed, err: %v", key, reason, err)
+				} else {
+					mismatch.Repaired = true
+					result.Repaired++
+				}
+			}
+
+			if len(result.Details) < metadata.CheckCacheMaxDetails {
+				result.Details = append(result.Details, mismatch)
+			}
+		}
+
+		if next == 0 {
+			return result, nil
+		}
+		cursor = next
+	}
+}
+
+// JSONEqual tells whether the two json documents have the same content, the order of the fields is ignored.
+func JSONEqual(a, b string) bool {
+	var av, bv interface{}
+	if err := json.Unmarshal([]byte(a), &av); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(b), &bv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
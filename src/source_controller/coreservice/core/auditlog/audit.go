@@ -42,6 +42,9 @@ func New(dbProxy dal.RDB) core.AuditOperation {
 }
 
 func (m *auditManager) CreateAuditLog(kit *rest.Kit, logs ...metadata.AuditLog) error {
+	// the operation is executed for an approved change request, label the logs with the change request's id
+	changeRequestID := kit.Header.Get(common.BKHTTPChangeRequestID)
+
 	var logRows []interface{}
 	for _, log := range logs {
 		if log.OperationDetail == nil || instNotChange(kit.Ctx, log.OperationDetail) {
@@ -50,6 +53,12 @@ func (m *auditManager) CreateAuditLog(kit *rest.Kit, logs ...metadata.AuditLog)
 		if log.OperateFrom == "" {
 			log.OperateFrom = metadata.FromUser
 		}
+		if len(changeRequestID) != 0 {
+			if log.Label == nil {
+				log.Label = make(map[string]string)
+			}
+			log.Label[common.BKChangeRequestIDField] = changeRequestID
+		}
 		log.SupplierAccount = kit.SupplierAccount
 		log.User = kit.User
 		log.OperationTime = metadata.Now()
@@ -0,0 +1,164 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package businessarchive
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+)
+
+var _ core.BusinessArchiveOperation = (*businessArchive)(nil)
+
+type businessArchive struct {
+	dbProxy dal.RDB
+}
+
+// New create a new business archive manager instance
+func New(dbProxy dal.RDB) core.BusinessArchiveOperation {
+	return &businessArchive{
+		dbProxy: dbProxy,
+	}
+}
+
+func (b *businessArchive) CreateBusinessArchive(kit *rest.Kit, archive metadata.BusinessArchive) (metadata.BusinessArchive, errors.CCErrorCoder) {
+	if archive.BizID <= 0 {
+		return archive, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField)
+	}
+
+	id, err := b.dbProxy.NextSequence(kit.Ctx, common.BKTableNameBizArchive)
+	if err != nil {
+		blog.Errorf("CreateBusinessArchive failed, generate id failed, err: %+v, rid: %s", err, kit.Rid)
+		return archive, kit.CCError.CCErrorf(common.CCErrCommGenerateRecordIDFailed)
+	}
+
+	now := time.Now()
+	archive.ID = int64(id)
+	archive.Status = metadata.BusinessArchived
+	archive.RestoreReport = nil
+	archive.Operator = kit.User
+	archive.CreateTime = now
+	archive.LastTime = now
+	archive.SupplierAccount = kit.SupplierAccount
+
+	if err := b.dbProxy.Table(common.BKTableNameBizArchive).Insert(kit.Ctx, archive); err != nil {
+		blog.Errorf("CreateBusinessArchive failed, db insert failed, bizID: %d, err: %+v, rid: %s", archive.BizID, err, kit.Rid)
+		return archive, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+	return archive, nil
+}
+
+// UpdateBusinessArchive mark the archive as restored, an archive can only be restored once.
+func (b *businessArchive) UpdateBusinessArchive(kit *rest.Kit, archiveID int64, option metadata.UpdateBusinessArchiveOption) (
+	metadata.BusinessArchive, errors.CCErrorCoder) {
+
+	archive := metadata.BusinessArchive{}
+	if option.Status != metadata.BusinessRestored {
+		return archive, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKStatusField)
+	}
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID:         archiveID,
+		common.BKStatusField:     metadata.BusinessArchived,
+	}
+	count, err := b.dbProxy.Table(common.BKTableNameBizArchive).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("UpdateBusinessArchive failed, db count failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return archive, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count == 0 {
+		blog.Errorf("UpdateBusinessArchive failed, archive %d not found or already restored, rid: %s", archiveID, kit.Rid)
+		return archive, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+
+	doc := map[string]interface{}{
+		common.BKStatusField: option.Status,
+		"restore_report":     option.RestoreReport,
+		common.LastTimeField: time.Now(),
+	}
+	if err := b.dbProxy.Table(common.BKTableNameBizArchive).Update(kit.Ctx, filter, doc); err != nil {
+		blog.Errorf("UpdateBusinessArchive failed, db update failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return archive, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	delete(filter, common.BKStatusField)
+	if err := b.dbProxy.Table(common.BKTableNameBizArchive).Find(filter).One(kit.Ctx, &archive); err != nil {
+		blog.Errorf("UpdateBusinessArchive failed, db select failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return archive, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return archive, nil
+}
+
+func (b *businessArchive) ListBusinessArchive(kit *rest.Kit, option metadata.ListBusinessArchiveOption) (
+	metadata.MultipleBusinessArchive, errors.CCErrorCoder) {
+
+	result := metadata.MultipleBusinessArchive{}
+	if option.Page.Limit > common.BKMaxPageSize && option.Page.Limit != common.BKNoLimit {
+		return result, kit.CCError.CCError(common.CCErrCommPageLimitIsExceeded)
+	}
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+	if len(option.IDs) != 0 {
+		filter[common.BKFieldID] = map[string]interface{}{
+			common.BKDBIN: option.IDs,
+		}
+	}
+	if len(option.BizIDs) != 0 {
+		filter[common.BKAppIDField] = map[string]interface{}{
+			common.BKDBIN: option.BizIDs,
+		}
+	}
+	if len(option.Status) != 0 {
+		filter[common.BKStatusField] = map[string]interface{}{
+			common.BKDBIN: option.Status,
+		}
+	}
+
+	query := b.dbProxy.Table(common.BKTableNameBizArchive).Find(filter)
+	total, err := query.Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("ListBusinessArchive failed, db count failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	result.Count = int64(total)
+
+	if len(option.Page.Sort) > 0 {
+		query = query.Sort(option.Page.Sort)
+	} else {
+		query = query.Sort("-" + common.BKFieldID)
+	}
+	if option.Page.Limit > 0 {
+		query = query.Limit(uint64(option.Page.Limit))
+	}
+	if option.Page.Start > 0 {
+		query = query.Start(uint64(option.Page.Start))
+	}
+
+	archives := make([]metadata.BusinessArchive, 0)
+	if err := query.All(kit.Ctx, &archives); err != nil {
+		blog.ErrorJSON("ListBusinessArchive failed, db select failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	result.Info = archives
+	return result, nil
+}
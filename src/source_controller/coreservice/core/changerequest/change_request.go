@@ -0,0 +1,289 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package changerequest
+
+import (
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/eventclient"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+
+	"gopkg.in/redis.v5"
+)
+
+var _ core.ChangeRequestOperation = (*changeRequest)(nil)
+
+type changeRequest struct {
+	dbProxy  dal.RDB
+	eventCli eventclient.Client
+}
+
+// New create a new change request manager instance
+func New(dbProxy dal.RDB, cache *redis.Client) core.ChangeRequestOperation {
+	return &changeRequest{
+		dbProxy:  dbProxy,
+		eventCli: eventclient.NewClientViaRedis(cache, dbProxy),
+	}
+}
+
+func (c *changeRequest) CreateChangeRequest(kit *rest.Kit, option metadata.CreateChangeRequestOption) (metadata.ChangeRequest, errors.CCErrorCoder) {
+	now := time.Now()
+	request := metadata.ChangeRequest{
+		ResourceType:    option.ResourceType,
+		Action:          option.Action,
+		BizID:           option.BizID,
+		Method:          strings.ToUpper(option.Method),
+		URI:             option.URI,
+		Header:          option.Header,
+		Body:            option.Body,
+		Status:          metadata.ChangeRequestPending,
+		Applicant:       kit.User,
+		ExpireTime:      option.ExpireTime,
+		CreateTime:      now,
+		LastTime:        now,
+		SupplierAccount: kit.SupplierAccount,
+	}
+	if key, err := option.Validate(); err != nil {
+		blog.Errorf("CreateChangeRequest failed, parameter invalid, key: %s, err: %+v, rid: %s", key, err, kit.Rid)
+		return request, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	id, err := c.dbProxy.NextSequence(kit.Ctx, common.BKTableNameChangeRequest)
+	if err != nil {
+		blog.Errorf("CreateChangeRequest failed, generate id failed, err: %+v, rid: %s", err, kit.Rid)
+		return request, kit.CCError.CCErrorf(common.CCErrCommGenerateRecordIDFailed)
+	}
+	request.ID = int64(id)
+
+	if err := c.dbProxy.Table(common.BKTableNameChangeRequest).Insert(kit.Ctx, request); err != nil {
+		blog.Errorf("CreateChangeRequest failed, db insert failed, doc: %+v, err: %+v, rid: %s", request, err, kit.Rid)
+		return request, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+
+	c.pushEvent(kit, metadata.EventActionCreate, nil, request)
+	return request, nil
+}
+
+func (c *changeRequest) GetChangeRequest(kit *rest.Kit, requestID int64) (metadata.ChangeRequest, errors.CCErrorCoder) {
+	request := metadata.ChangeRequest{}
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID:         requestID,
+	}
+	if err := c.dbProxy.Table(common.BKTableNameChangeRequest).Find(filter).One(kit.Ctx, &request); err != nil {
+		if c.dbProxy.IsNotFoundError(err) {
+			blog.Errorf("GetChangeRequest failed, not found, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+			return request, kit.CCError.CCError(common.CCErrCommNotFound)
+		}
+		blog.Errorf("GetChangeRequest failed, db select failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return request, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if request.IsExpired(time.Now()) {
+		return c.updateStatus(kit, request, metadata.UpdateChangeRequestStatusOption{Status: metadata.ChangeRequestExpired})
+	}
+	return request, nil
+}
+
+// UpdateChangeRequestStatus change the status of the change request, the user who approves or rejects
+// the pending change request is recorded as the approver.
+func (c *changeRequest) UpdateChangeRequestStatus(kit *rest.Kit, requestID int64, option metadata.UpdateChangeRequestStatusOption) (
+	metadata.ChangeRequest, errors.CCErrorCoder) {
+
+	request, ccErr := c.GetChangeRequest(kit, requestID)
+	if ccErr != nil {
+		return request, ccErr
+	}
+
+	if !request.Status.CanTransitTo(option.Status) {
+		blog.Errorf("UpdateChangeRequestStatus failed, can not change status from %s to %s, id: %d, rid: %s", request.Status, option.Status, requestID, kit.Rid)
+		return request, kit.CCError.CCErrorf(common.CCErrCommChangeRequestStatusInvalid, requestID, request.Status)
+	}
+
+	if request.Status == metadata.ChangeRequestPending && request.Applicant == kit.User {
+		blog.Errorf("UpdateChangeRequestStatus failed, applicant %s can not approve or reject it, id: %d, rid: %s", kit.User, requestID, kit.Rid)
+		return request, kit.CCError.CCError(common.CCErrCommChangeRequestSelfApprove)
+	}
+
+	// the executing request id is used to make sure the request is executed only once.
+	if option.Status == metadata.ChangeRequestExecuting && (option.Rid == nil || len(*option.Rid) == 0) {
+		return request, kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "rid")
+	}
+
+	return c.updateStatus(kit, request, option)
+}
+
+func (c *changeRequest) updateStatus(kit *rest.Kit, request metadata.ChangeRequest, option metadata.UpdateChangeRequestStatusOption) (
+	metadata.ChangeRequest, errors.CCErrorCoder) {
+
+	origin := request
+	if request.Status == metadata.ChangeRequestPending && option.Status != metadata.ChangeRequestExpired {
+		request.Approver = kit.User
+	}
+	request.Status = option.Status
+	if option.Comment != nil {
+		request.Comment = *option.Comment
+	}
+	if option.Response != nil {
+		request.Response = *option.Response
+	}
+	if option.Rid != nil {
+		request.Rid = *option.Rid
+	}
+	request.LastTime = time.Now()
+
+	// only update the change request when it's status is not changed by others
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID:         request.ID,
+		common.BKStatusField:     origin.Status,
+	}
+	if err := c.dbProxy.Table(common.BKTableNameChangeRequest).Update(kit.Ctx, filter, request); err != nil {
+		blog.ErrorJSON("update change request status failed, db update failed, filter: %s, doc: %s, err: %s, rid: %s", filter, request, err, kit.Rid)
+		return origin, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	current := metadata.ChangeRequest{}
+	filter = map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID:         request.ID,
+	}
+	if err := c.dbProxy.Table(common.BKTableNameChangeRequest).Find(filter).One(kit.Ctx, &current); err != nil {
+		blog.Errorf("update change request status, but get change request failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return origin, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if current.Status != request.Status || current.Rid != request.Rid {
+		blog.Errorf("update change request %d status to %s failed, it's changed to %s by others, rid: %s", request.ID, request.Status, current.Status, kit.Rid)
+		return current, kit.CCError.CCErrorf(common.CCErrCommChangeRequestStatusInvalid, request.ID, current.Status)
+	}
+
+	c.pushEvent(kit, metadata.EventActionUpdate, origin, current)
+	return current, nil
+}
+
+// expireChangeRequests change the status of the pending change requests whose expire time is passed to expired.
+func (c *changeRequest) expireChangeRequests(kit *rest.Kit) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKStatusField:     metadata.ChangeRequestPending,
+		common.BKExpireTimeField: map[string]interface{}{common.BKDBLT: time.Now()},
+	}
+	requests := make([]metadata.ChangeRequest, 0)
+	if err := c.dbProxy.Table(common.BKTableNameChangeRequest).Find(filter).All(kit.Ctx, &requests); err != nil {
+		blog.Errorf("expire change requests failed, db select failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	for _, request := range requests {
+		if _, err := c.updateStatus(kit, request, metadata.UpdateChangeRequestStatusOption{Status: metadata.ChangeRequestExpired}); err != nil {
+			// the change request may be approved by others at the same time, just skip it.
+			blog.Warnf("expire change request %d failed, err: %v, rid: %s", request.ID, err, kit.Rid)
+		}
+	}
+	return nil
+}
+
+func (c *changeRequest) ListChangeRequest(kit *rest.Kit, option metadata.ListChangeRequestOption) (metadata.MultipleChangeRequest, errors.CCErrorCoder) {
+	result := metadata.MultipleChangeRequest{}
+	if option.Page.Limit > common.BKMaxPageSize && option.Page.Limit != common.BKNoLimit {
+		return result, kit.CCError.CCError(common.CCErrCommPageLimitIsExceeded)
+	}
+
+	if err := c.expireChangeRequests(kit); err != nil {
+		return result, err
+	}
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+	if len(option.IDs) != 0 {
+		filter[common.BKFieldID] = map[string]interface{}{
+			common.BKDBIN: option.IDs,
+		}
+	}
+	if len(option.Status) != 0 {
+		filter[common.BKStatusField] = map[string]interface{}{
+			common.BKDBIN: option.Status,
+		}
+	}
+	if len(option.ResourceType) != 0 {
+		filter["resource_type"] = option.ResourceType
+	}
+	if len(option.Action) != 0 {
+		filter["action"] = option.Action
+	}
+	if option.BizID != 0 {
+		filter[common.BKAppIDField] = option.BizID
+	}
+	if len(option.Applicant) != 0 {
+		filter["applicant"] = option.Applicant
+	}
+	if len(option.Approver) != 0 {
+		filter["approver"] = option.Approver
+	}
+	if len(option.Participant) != 0 {
+		filter[common.BKDBOR] = []map[string]interface{}{
+			{"applicant": option.Participant},
+			{"approver": option.Participant},
+		}
+	}
+
+	query := c.dbProxy.Table(common.BKTableNameChangeRequest).Find(filter)
+	total, err := query.Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("ListChangeRequest failed, db count failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	result.Count = int64(total)
+
+	if len(option.Page.Sort) > 0 {
+		query = query.Sort(option.Page.Sort)
+	} else {
+		query = query.Sort("-" + common.BKFieldID)
+	}
+	if option.Page.Limit > 0 {
+		query = query.Limit(uint64(option.Page.Limit))
+	}
+	if option.Page.Start > 0 {
+		query = query.Start(uint64(option.Page.Start))
+	}
+
+	requests := make([]metadata.ChangeRequest, 0)
+	if err := query.All(kit.Ctx, &requests); err != nil {
+		blog.ErrorJSON("ListChangeRequest failed, db select failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	result.Info = requests
+	return result, nil
+}
+
+// pushEvent notify the change of the change request through event subscription, failure is only logged
+// because the change request is already saved.
+func (c *changeRequest) pushEvent(kit *rest.Kit, action string, preData interface{}, curData metadata.ChangeRequest) {
+	event := eventclient.NewEventWithHeader(kit.Header)
+	event.EventType = metadata.EventTypeInstData
+	event.ObjType = metadata.ChangeRequestEventType
+	event.Action = action
+	event.Data = []metadata.EventData{{PreData: preData, CurData: curData}}
+	if err := c.eventCli.Push(kit.Ctx, event); err != nil {
+		blog.Errorf("push change request %d %s event failed, err: %v, rid: %s", curData.ID, action, err, kit.Rid)
+	}
+}
@@ -90,6 +90,11 @@ type InstanceOperation interface {
 	SearchModelInstance(kit *rest.Kit, objID string, inputParam metadata.QueryCondition) (*metadata.QueryResult, error)
 	DeleteModelInstance(kit *rest.Kit, objID string, inputParam metadata.DeleteOption) (*metadata.DeletedCount, error)
 	CascadeDeleteModelInstance(kit *rest.Kit, objID string, inputParam metadata.DeleteOption) (*metadata.DeletedCount, error)
+	RefreshExpressionFields(kit *rest.Kit, objID string, cond mapstr.MapStr) error
+	// RestoreModelInstance restore the soft deleted instance in the trash bin with it's original id
+	RestoreModelInstance(kit *rest.Kit, objID string, item metadata.TrashItem) error
+	// AggregateModelInstances group the instances and calculate the metrics of each group
+	AggregateModelInstances(kit *rest.Kit, objID string, option metadata.AggregateOption) (*metadata.AggregateResult, errors.CCErrorCoder)
 }
 
 // AssociationKind association kind methods
@@ -200,6 +205,12 @@ type Core interface {
 	SetTemplateOperation() SetTemplateOperation
 	HostApplyRuleOperation() HostApplyRuleOperation
 	SystemOperation() SystemOperation
+	PermissionRoleOperation() PermissionRoleOperation
+	ChangeRequestOperation() ChangeRequestOperation
+	BusinessArchiveOperation() BusinessArchiveOperation
+	ServiceAccountOperation() ServiceAccountOperation
+	TrashOperation() TrashOperation
+	QuotaOperation() QuotaOperation
 }
 
 // ProcessOperation methods
@@ -253,6 +264,7 @@ type ProcessOperation interface {
 type LabelOperation interface {
 	AddLabel(kit *rest.Kit, tableName string, option selector.LabelAddOption) errors.CCErrorCoder
 	RemoveLabel(kit *rest.Kit, tableName string, option selector.LabelRemoveOption) errors.CCErrorCoder
+	AggregateLabels(kit *rest.Kit, tableName string, condition map[string]interface{}) (selector.LabelAggregation, errors.CCErrorCoder)
 }
 
 type SetTemplateOperation interface {
@@ -267,6 +279,8 @@ type SetTemplateOperation interface {
 	ListSetTemplateSyncStatus(kit *rest.Kit, option metadata.ListSetTemplateSyncStatusOption) (metadata.MultipleSetTemplateSyncStatus, errors.CCErrorCoder)
 	ListSetTemplateSyncHistory(kit *rest.Kit, option metadata.ListSetTemplateSyncStatusOption) (metadata.MultipleSetTemplateSyncStatus, errors.CCErrorCoder)
 	DeleteSetTemplateSyncStatus(kit *rest.Kit, option metadata.DeleteSetTemplateSyncStatusOption) errors.CCErrorCoder
+	UpdateSetTemplateSyncPolicy(kit *rest.Kit, bizID int64, setTemplateID int64, policy metadata.SetTemplateSyncPolicy) (metadata.SetTemplate, errors.CCErrorCoder)
+	ListSetTemplateSyncPolicy(kit *rest.Kit, option metadata.ListSetTemplateSyncPolicyOption) ([]metadata.SetTemplate, errors.CCErrorCoder)
 }
 
 type HostApplyRuleOperation interface {
@@ -279,12 +293,68 @@ type HostApplyRuleOperation interface {
 	SearchRuleRelatedModules(kit *rest.Kit, bizID int64, option metadata.SearchRuleRelatedModulesOption) ([]metadata.Module, errors.CCErrorCoder)
 	BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, option metadata.BatchCreateOrUpdateApplyRuleOption) (metadata.BatchCreateOrUpdateHostApplyRuleResult, errors.CCErrorCoder)
 	RunHostApplyOnHosts(kit *rest.Kit, bizID int64, option metadata.UpdateHostByHostApplyRuleOption) (metadata.MultipleHostApplyResult, errors.CCErrorCoder)
+	ReconcileHostApply(kit *rest.Kit, hostID int64) errors.CCErrorCoder
+	RemoveHostApplyDrift(kit *rest.Kit, hostIDs ...int64) errors.CCErrorCoder
+	ListHostApplyDrift(kit *rest.Kit, bizID int64, option metadata.ListHostApplyDriftOption) (metadata.MultipleHostApplyDrift, errors.CCErrorCoder)
+}
+
+type PermissionRoleOperation interface {
+	CreatePermissionRole(kit *rest.Kit, option metadata.CreatePermissionRoleOption) (metadata.PermissionRole, errors.CCErrorCoder)
+	UpdatePermissionRole(kit *rest.Kit, roleID int64, option metadata.UpdatePermissionRoleOption) (metadata.PermissionRole, errors.CCErrorCoder)
+	DeletePermissionRole(kit *rest.Kit, roleIDs []int64) errors.CCErrorCoder
+	ListPermissionRole(kit *rest.Kit, option metadata.ListPermissionRoleOption) (metadata.MultiplePermissionRole, errors.CCErrorCoder)
+}
+
+type ChangeRequestOperation interface {
+	CreateChangeRequest(kit *rest.Kit, option metadata.CreateChangeRequestOption) (metadata.ChangeRequest, errors.CCErrorCoder)
+	GetChangeRequest(kit *rest.Kit, requestID int64) (metadata.ChangeRequest, errors.CCErrorCoder)
+	UpdateChangeRequestStatus(kit *rest.Kit, requestID int64, option metadata.UpdateChangeRequestStatusOption) (metadata.ChangeRequest, errors.CCErrorCoder)
+	ListChangeRequest(kit *rest.Kit, option metadata.ListChangeRequestOption) (metadata.MultipleChangeRequest, errors.CCErrorCoder)
+}
+
+type BusinessArchiveOperation interface {
+	CreateBusinessArchive(kit *rest.Kit, archive metadata.BusinessArchive) (metadata.BusinessArchive, errors.CCErrorCoder)
+	UpdateBusinessArchive(kit *rest.Kit, archiveID int64, option metadata.UpdateBusinessArchiveOption) (metadata.BusinessArchive, errors.CCErrorCoder)
+	ListBusinessArchive(kit *rest.Kit, option metadata.ListBusinessArchiveOption) (metadata.MultipleBusinessArchive, errors.CCErrorCoder)
+}
+
+type ServiceAccountOperation interface {
+	CreateServiceAccount(kit *rest.Kit, option metadata.CreateServiceAccountOption) (metadata.ServiceAccount, errors.CCErrorCoder)
+	UpdateServiceAccount(kit *rest.Kit, accountID int64, option metadata.UpdateServiceAccountOption) (metadata.ServiceAccount, errors.CCErrorCoder)
+	DeleteServiceAccount(kit *rest.Kit, accountID int64) errors.CCErrorCoder
+	ListServiceAccount(kit *rest.Kit, option metadata.ListServiceAccountOption) (metadata.MultipleServiceAccount, errors.CCErrorCoder)
+	CreateAPIToken(kit *rest.Kit, accountID int64, option metadata.CreateAPITokenOption) (metadata.CreateAPITokenResult, errors.CCErrorCoder)
+	RevokeAPIToken(kit *rest.Kit, accountID int64, tokenID int64) errors.CCErrorCoder
+	ListAPIToken(kit *rest.Kit, option metadata.ListAPITokenOption) (metadata.MultipleAPIToken, errors.CCErrorCoder)
+	ValidateAPIToken(kit *rest.Kit, option metadata.ValidateAPITokenOption) (metadata.ValidAPIToken, errors.CCErrorCoder)
 }
 
 type SystemOperation interface {
 	GetSystemUserConfig(kit *rest.Kit) (map[string]interface{}, errors.CCErrorCoder)
 }
 
+// TrashOperation manages the soft deleted instances of the objects opted into the trash bin
+type TrashOperation interface {
+	IsEnabled(objID string) bool
+	// AddTrash save the instances and their associations to the trash bin before they are deleted,
+	// it does nothing if the object is not opted into the trash bin.
+	AddTrash(kit *rest.Kit, objID string, instances []mapstr.MapStr) errors.CCErrorCoder
+	ListTrash(kit *rest.Kit, objID string, option metadata.ListTrashOption) (metadata.MultipleTrashItem, errors.CCErrorCoder)
+	DeleteTrash(kit *rest.Kit, objID string, ids []int64) errors.CCErrorCoder
+}
+
+// QuotaOperation manages the resource quotas of the tenants and businesses
+type QuotaOperation interface {
+	SetQuota(kit *rest.Kit, option metadata.SetQuotaOption) (*metadata.Quota, errors.CCErrorCoder)
+	DeleteQuota(kit *rest.Kit, key metadata.QuotaKey) errors.CCErrorCoder
+	ListQuotaUsage(kit *rest.Kit, option metadata.ListQuotaUsageOption) ([]metadata.QuotaUsage, errors.CCErrorCoder)
+	// CheckQuota returns a quota exceeded error if the increment number of the resource can not be created
+	CheckQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64, increment int64) errors.CCErrorCoder
+	// CheckBusinessQuota returns a quota exceeded error if the increment number of the resource can not be moved
+	// into the business, the tenant quota is not checked.
+	CheckBusinessQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64, increment int64) errors.CCErrorCoder
+}
+
 type core struct {
 	model           ModelOperation
 	instance        InstanceOperation
@@ -299,6 +369,12 @@ type core struct {
 	sys             SystemOperation
 	setTemplate     SetTemplateOperation
 	hostApplyRule   HostApplyRuleOperation
+	permissionRole  PermissionRoleOperation
+	changeRequest   ChangeRequestOperation
+	businessArchive BusinessArchiveOperation
+	serviceAccount  ServiceAccountOperation
+	trash           TrashOperation
+	quota           QuotaOperation
 }
 
 // New create core
@@ -315,6 +391,12 @@ func New(
 	operation StatisticOperation,
 	hostApplyRule HostApplyRuleOperation,
 	sys SystemOperation,
+	permissionRole PermissionRoleOperation,
+	changeRequest ChangeRequestOperation,
+	businessArchive BusinessArchiveOperation,
+	serviceAccount ServiceAccountOperation,
+	trash TrashOperation,
+	quota QuotaOperation,
 ) Core {
 	return &core{
 		model:           model,
@@ -330,6 +412,12 @@ func New(
 		sys:             sys,
 		setTemplate:     setTemplate,
 		hostApplyRule:   hostApplyRule,
+		permissionRole:  permissionRole,
+		changeRequest:   changeRequest,
+		businessArchive: businessArchive,
+		serviceAccount:  serviceAccount,
+		trash:           trash,
+		quota:           quota,
 	}
 }
 
@@ -384,3 +472,27 @@ func (m *core) SystemOperation() SystemOperation {
 func (m *core) HostApplyRuleOperation() HostApplyRuleOperation {
 	return m.hostApplyRule
 }
+
+func (m *core) PermissionRoleOperation() PermissionRoleOperation {
+	return m.permissionRole
+}
+
+func (m *core) ChangeRequestOperation() ChangeRequestOperation {
+	return m.changeRequest
+}
+
+func (m *core) BusinessArchiveOperation() BusinessArchiveOperation {
+	return m.businessArchive
+}
+
+func (m *core) ServiceAccountOperation() ServiceAccountOperation {
+	return m.serviceAccount
+}
+
+func (m *core) TrashOperation() TrashOperation {
+	return m.trash
+}
+
+func (m *core) QuotaOperation() QuotaOperation {
+	return m.quota
+}
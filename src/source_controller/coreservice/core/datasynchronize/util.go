@@ -165,6 +165,9 @@ func (s *synchronizeAdapter) replaceSynchronize(kit *rest.Kit, dbParam synchroni
 		}
 
 		blog.V(6).Infof("replaceSynchronize DataClassify:%s, info:%#v, table:%s, version:%v, exist:%v, rid:%s", s.syncData.DataClassify, item, dbParam.tableName, s.syncData.Version, exist, kit.Rid)
+		if dbParam.tableName == common.BKTableNameBaseHost {
+			util.SetHostIPNormalizedFields(item.Info)
+		}
 		if exist {
 			// Existing data, does not update the ID field
 			delete(item.Info, dbParam.InstIDField)
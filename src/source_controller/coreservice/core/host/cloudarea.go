@@ -13,12 +13,15 @@
 package host
 
 import (
+	"strings"
+
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
+	hostutil "configcenter/src/source_controller/coreservice/core/host/util"
 )
 
 func (hm *hostManager) UpdateHostCloudAreaField(kit *rest.Kit, input metadata.UpdateHostCloudAreaFieldOption) errors.CCErrorCoder {
@@ -72,24 +75,40 @@ func (hm *hostManager) UpdateHostCloudAreaField(kit *rest.Kit, input metadata.Up
 	}
 
 	// step3. validate unique of bk_cloud_id + bk_host_innerip in input parameters
-	hostIDs := make([]int64, 0)
+	hostIPs := make(map[string]string)
 	innerIPs := make([]string, 0)
+	addCount := int64(0)
 	for _, item := range hostSimplify {
-		hostIDs = append(hostIDs, item.HostID)
 		innerIPs = append(innerIPs, item.InnerIP)
-	}
-	if len(hostIDs) != len(innerIPs) {
-		return kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, common.BKHostInnerIPField)
+		if item.CloudID != input.CloudID {
+			addCount++
+		}
+		for _, ip := range strings.Split(item.InnerIP, ",") {
+			normalized, err := util.NormalizeIP(ip)
+			if err != nil {
+				continue
+			}
+			if _, exists := hostIPs[normalized]; exists {
+				blog.Errorf("UpdateHostCloudAreaField failed, ip %s of the hosts duplicated, rid: %s", ip, rid)
+				return kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, ip)
+			}
+			hostIPs[normalized] = util.CanonicalIP(ip)
+		}
 	}
 
 	// step4. validate unique of bk_cloud_id + bk_inner_ip in database
+	normalizedIPs := make([]string, 0)
+	for normalized := range hostIPs {
+		normalizedIPs = append(normalizedIPs, normalized)
+	}
 	dbHostFilter := map[string]interface{}{
 		common.BKHostIDField: map[string]interface{}{
 			common.BKDBNIN: input.HostIDs,
 		},
 		common.BKCloudIDField: input.CloudID,
-		common.BKHostInnerIPField: map[string]interface{}{
-			common.BKDBIN: innerIPs,
+		common.BKDBOR: []map[string]interface{}{
+			{common.BKHostInnerIPNormalizedField: map[string]interface{}{common.BKDBIN: normalizedIPs}},
+			{common.BKHostInnerIPField: map[string]interface{}{common.BKDBIN: innerIPs}},
 		},
 	}
 	duplicatedHosts := make([]HostSimplify, 0)
@@ -99,10 +118,31 @@ func (hm *hostManager) UpdateHostCloudAreaField(kit *rest.Kit, input metadata.Up
 	}
 	if len(duplicatedHosts) > 0 {
 		blog.ErrorJSON("UpdateHostCloudAreaField failed, bk_cloud_id + bk_host_innerip duplicated, input: %s, duplicated hosts: %s, rid: %s", input, duplicatedHosts, rid)
-		return kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, common.BKHostInnerIPField)
+		conflictIPs := make([]string, 0)
+		for _, host := range duplicatedHosts {
+			for _, ip := range strings.Split(host.InnerIP, ",") {
+				normalized, err := util.NormalizeIP(ip)
+				if err != nil {
+					continue
+				}
+				if canonical, exists := hostIPs[normalized]; exists {
+					conflictIPs = append(conflictIPs, canonical)
+				}
+			}
+		}
+		if len(conflictIPs) == 0 {
+			conflictIPs = append(conflictIPs, duplicatedHosts[0].InnerIP)
+		}
+		return kit.CCError.CCErrorf(common.CCErrTopoCloudAreaHostIPConflict, strings.Join(util.StrArrayUnique(conflictIPs), ","),
+			input.CloudID)
+	}
+
+	// step5. validate the host limit of the target cloud area
+	if err := hostutil.ValidateCloudAreaHostLimit(kit, hm.DbProxy, input.CloudID, addCount); err != nil {
+		return err
 	}
 
-	// step5. update hosts bk_cloud_id field
+	// step6. update hosts bk_cloud_id field
 	updateFilter := map[string]interface{}{
 		common.BKHostIDField: map[string]interface{}{
 			common.BKDBIN: input.HostIDs,
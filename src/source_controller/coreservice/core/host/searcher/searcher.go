@@ -98,6 +98,13 @@ func (s *Searcher) ListHosts(ctx context.Context, option metadata.ListHosts) (se
 		finalFilter[common.BKDBAND] = filters
 	}
 
+	pageFilter, err := option.Page.CursorCondition(finalFilter, common.BKHostIDField)
+	if err != nil {
+		blog.Errorf("ListHosts failed, invalid page cursor, page: %+v, err: %+v, rid: %s", option.Page, err, rid)
+		return nil, err
+	}
+
+	// the count is the total of the hosts matching the filter, not only the ones after the cursor
 	total, err := s.DbProxy.Table(common.BKTableNameBaseHost).Find(finalFilter).Count(ctx)
 	if err != nil {
 		blog.Errorf("ListHosts failed, db select failed, filter: %+v, err: %+v, rid: %s", finalFilter, err, rid)
@@ -108,18 +115,22 @@ func (s *Searcher) ListHosts(ctx context.Context, option metadata.ListHosts) (se
 
 	limit := uint64(option.Page.Limit)
 	start := uint64(option.Page.Start)
-	query := s.DbProxy.Table(common.BKTableNameBaseHost).Find(finalFilter).Limit(limit).Start(start).Fields(option.Fields...)
-	if len(option.Page.Sort) > 0 {
-		query = query.Sort(option.Page.Sort)
-	} else {
-		query = query.Sort(common.BKHostIDField)
-	}
+	fields := option.Page.CursorFields(option.Fields, common.BKHostIDField)
+	query := s.DbProxy.Table(common.BKTableNameBaseHost).Find(pageFilter).Limit(limit).Start(start).Fields(fields...)
+	query = query.Sort(option.Page.CursorSort(common.BKHostIDField))
 
 	hosts := make([]map[string]interface{}, 0)
 	if err := query.All(ctx, &hosts); err != nil {
-		blog.Errorf("ListHosts failed, db select hosts failed, filter: %+v, err: %+v, rid: %s", finalFilter, err, rid)
+		blog.Errorf("ListHosts failed, db select hosts failed, filter: %+v, err: %+v, rid: %s", pageFilter, err, rid)
 		return nil, err
 	}
 	searchResult.Info = hosts
+	if len(hosts) > 0 {
+		searchResult.NextCursor, err = option.Page.NextCursor(common.BKHostIDField, len(hosts), hosts[len(hosts)-1])
+		if err != nil {
+			blog.Errorf("ListHosts failed, generate next cursor failed, page: %+v, err: %+v, rid: %s", option.Page, err, rid)
+			return nil, err
+		}
+	}
 	return searchResult, nil
 }
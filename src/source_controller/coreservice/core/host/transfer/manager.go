@@ -19,6 +19,7 @@ import (
 	"configcenter/src/common/errors"
 	"configcenter/src/common/eventclient"
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	"configcenter/src/storage/dal"
@@ -39,6 +40,8 @@ type OperationDependence interface {
 	AutoCreateServiceInstanceModuleHost(kit *rest.Kit, hostID int64, moduleID int64) (*metadata.ServiceInstance, errors.CCErrorCoder)
 	SelectObjectAttWithParams(kit *rest.Kit, objID string, bizID int64) (attribute []metadata.Attribute, err error)
 	UpdateModelInstance(kit *rest.Kit, objID string, param metadata.UpdateOption) (*metadata.UpdatedCount, error)
+	AddTrash(kit *rest.Kit, objID string, instances []mapstr.MapStr) errors.CCErrorCoder
+	CheckBusinessQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64, increment int64) errors.CCErrorCoder
 }
 
 type HostApplyRuleDependence interface {
@@ -90,6 +93,10 @@ func (manager *TransferManager) TransferToInnerModule(kit *rest.Kit, input *meta
 		blog.ErrorJSON("TransferHostToInnerModule failed, ValidParameter failed, input:%s, err:%s, rid:%s", input, err.Error(), kit.Rid)
 		return nil, err
 	}
+	if err := transfer.CheckHostQuota(kit, input.HostID); err != nil {
+		blog.ErrorJSON("TransferHostToInnerModule failed, CheckHostQuota failed, input:%s, err:%s, rid:%s", input, err.Error(), kit.Rid)
+		return nil, err
+	}
 
 	var exceptionArr []metadata.ExceptionResult
 	for _, hostID := range input.HostID {
@@ -202,6 +209,10 @@ func (manager *TransferManager) TransferToNormalModule(kit *rest.Kit, input *met
 		blog.ErrorJSON("TransferToNormalModule failed, ValidParameter failed, input:%s, err:%s, rid:%s", input, err, kit.Rid)
 		return nil, err
 	}
+	if err := transfer.CheckHostQuota(kit, input.HostID); err != nil {
+		blog.ErrorJSON("TransferToNormalModule failed, CheckHostQuota failed, input:%s, err:%s, rid:%s", input, err, kit.Rid)
+		return nil, err
+	}
 	for _, hostID := range input.HostID {
 		err := transfer.Transfer(kit, hostID)
 		if err != nil {
@@ -343,6 +354,10 @@ func (manager *TransferManager) TransferToAnotherBusiness(kit *rest.Kit, input *
 		blog.ErrorJSON("TransferToAnotherBusiness failed, ValidParameter failed, err:%s, input:%s, rid:%s", err.Error(), input, kit.Rid)
 		return nil, err
 	}
+	if err := transfer.CheckHostQuota(kit, input.HostIDArr); err != nil {
+		blog.ErrorJSON("TransferToAnotherBusiness failed, CheckHostQuota failed, err:%s, input:%s, rid:%s", err.Error(), input, kit.Rid)
+		return nil, err
+	}
 
 	// attributes in legacy business
 	legacyAttributes, err := transfer.dependent.SelectObjectAttWithParams(kit, common.BKInnerObjIDHost, input.SrcApplicationID)
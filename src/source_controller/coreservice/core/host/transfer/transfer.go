@@ -158,6 +158,11 @@ func (t *genericTransfer) deleteHost(kit *rest.Kit, hostID int64) (mapstr.MapStr
 		return nil, kit.CCError.CCErrorf(common.CCErrCommDBDeleteFailed)
 	}
 
+	if err := t.dependent.AddTrash(kit, common.BKInnerObjIDHost, hostInfoArr); err != nil {
+		blog.Errorf("deleteHost add host %d to trash failed, err: %v, rid: %s", hostID, err, kit.Rid)
+		return nil, err
+	}
+
 	err = t.dbProxy.Table(common.BKTableNameBaseHost).Delete(kit.Ctx, hostCondMap)
 	if err != nil {
 		blog.ErrorJSON("deleteHost delete host error. err:%s, cond:%s, rid:%s", err.Error(), hostCondMap, kit.Rid)
@@ -346,6 +351,35 @@ func (t *genericTransfer) validHost(kit *rest.Kit, hostID int64) errors.CCErrorC
 	return nil
 }
 
+// CheckHostQuota check the host quota of the business once for the hosts to be transferred, the increment is
+// the number of the hosts which are new to the business. the tenant quota is not checked, because the hosts
+// are already counted when they are created, and the transfer does not add hosts to the tenant.
+func (t *genericTransfer) CheckHostQuota(kit *rest.Kit, hostIDs []int64) errors.CCErrorCoder {
+	hostIDs = util.IntArrayUnique(hostIDs)
+	if len(hostIDs) == 0 {
+		return nil
+	}
+
+	cond := map[string]interface{}{
+		common.BKAppIDField: t.bizID,
+		common.BKHostIDField: map[string]interface{}{
+			common.BKDBIN: hostIDs,
+		},
+	}
+	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
+	existIDs := make([]int64, 0)
+	if err := t.dbProxy.Table(common.BKTableNameModuleHostConfig).Distinct(kit.Ctx, common.BKHostIDField, cond, &existIDs); err != nil {
+		blog.ErrorJSON("CheckHostQuota find the hosts in business failed, err: %s, cond: %s, rid: %s", err.Error(), cond, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	increment := int64(len(hostIDs) - len(existIDs))
+	if increment <= 0 {
+		return nil
+	}
+	return t.dependent.CheckBusinessQuota(kit, metadata.QuotaResourceHost, t.bizID, increment)
+}
+
 // delHostModuleRelation delete single host module relation
 func (t *genericTransfer) delHostModuleRelation(kit *rest.Kit, hostID int64) ([]mapstr.MapStr, errors.CCErrorCoder) {
 	bizID := t.bizID
@@ -0,0 +1,102 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal/mongo/local"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/require"
+)
+
+type quotaCheck struct {
+	resource  metadata.QuotaResource
+	bizID     int64
+	increment int64
+}
+
+// mockQuotaDependence records the business quota checks, the other dependences are not implemented.
+type mockQuotaDependence struct {
+	OperationDependence
+	checks []quotaCheck
+	err    errors.CCErrorCoder
+}
+
+func (m *mockQuotaDependence) CheckBusinessQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64,
+	increment int64) errors.CCErrorCoder {
+
+	m.checks = append(m.checks, quotaCheck{resource: resource, bizID: bizID, increment: increment})
+	return m.err
+}
+
+func TestCheckHostQuota(t *testing.T) {
+	uri := os.Getenv("MONGOURI")
+	if uri == "" {
+		t.Skip("MONGOURI is not set, skip the test which needs mongodb")
+	}
+	db, err := local.NewMgo(local.MongoConf{
+		MaxOpenConns: 100,
+		MaxIdleConns: 10,
+		URI:          uri,
+		RsName:       os.Getenv("MONGORS"),
+	}, time.Minute)
+	require.NoError(t, err)
+
+	errIf, _ := errors.NewFactory("../../../../../../resources/errors/")
+	kit := &rest.Kit{
+		Rid:             "test_req_id",
+		Header:          http.Header{},
+		Ctx:             context.Background(),
+		CCError:         errIf.CreateDefaultCCErrorIf("en"),
+		User:            "test_user",
+		SupplierAccount: xid.New().String(),
+	}
+	defer db.Table(common.BKTableNameModuleHostConfig).Delete(kit.Ctx, map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount})
+
+	bizID, otherBizID := int64(1), int64(2)
+	relations := []metadata.ModuleHost{
+		{AppID: bizID, SetID: 1, ModuleID: 1, HostID: 1, OwnerID: kit.SupplierAccount},
+		{AppID: bizID, SetID: 1, ModuleID: 2, HostID: 1, OwnerID: kit.SupplierAccount},
+		{AppID: otherBizID, SetID: 2, ModuleID: 3, HostID: 2, OwnerID: kit.SupplierAccount},
+	}
+	require.NoError(t, db.Table(common.BKTableNameModuleHostConfig).Insert(kit.Ctx, relations))
+
+	dependence := &mockQuotaDependence{}
+	manager := New(db, nil, nil, dependence, nil)
+	transfer := manager.NewHostModuleTransfer(kit, bizID, []int64{1}, false)
+
+	// the hosts which are already in the business are not counted
+	require.NoError(t, transfer.CheckHostQuota(kit, []int64{1}))
+	require.Empty(t, dependence.checks)
+
+	// the hosts are checked once for the batch, the duplicate hosts are counted once
+	require.NoError(t, transfer.CheckHostQuota(kit, []int64{1, 2, 3, 3}))
+	require.Equal(t, []quotaCheck{{resource: metadata.QuotaResourceHost, bizID: bizID, increment: 2}},
+		dependence.checks)
+
+	dependence.err = kit.CCError.CCErrorf(common.CCErrCommQuotaExceeded, metadata.QuotaResourceHost, "business 1", 2, 1)
+	quotaErr := transfer.CheckHostQuota(kit, []int64{2, 3})
+	require.Error(t, quotaErr)
+	require.Equal(t, common.CCErrCommQuotaExceeded, quotaErr.GetCode())
+}
@@ -0,0 +1,58 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+)
+
+// ValidateCloudAreaHostLimit checks whether the cloud area can hold the hosts to be added into it,
+// the cloud area without host limit can hold any number of hosts.
+func ValidateCloudAreaHostLimit(kit *rest.Kit, db dal.RDB, cloudID int64, addCount int64) errors.CCErrorCoder {
+	cloudFilter := map[string]interface{}{
+		common.BKCloudIDField: cloudID,
+	}
+	plat := make(map[string]interface{})
+	err := db.Table(common.BKTableNameBasePlat).Find(cloudFilter).Fields(common.BKCloudHostLimitField).One(kit.Ctx, &plat)
+	if err != nil {
+		if db.IsNotFoundError(err) {
+			// the existence of the cloud area is validated by the callers
+			return nil
+		}
+		blog.Errorf("get cloud area %d failed, err: %v, rid: %s", cloudID, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	limit, err := util.GetInt64ByInterface(plat[common.BKCloudHostLimitField])
+	if err != nil || limit <= 0 {
+		return nil
+	}
+
+	count, err := db.Table(common.BKTableNameBaseHost).Find(cloudFilter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("count hosts in cloud area %d failed, err: %v, rid: %s", cloudID, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if int64(count)+addCount > limit {
+		blog.Errorf("cloud area %d host limit %d exceeded, has %d hosts, add %d hosts, rid: %s", cloudID, limit, count,
+			addCount, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrTopoCloudAreaHostLimitExceeded, cloudID, limit)
+	}
+	return nil
+}
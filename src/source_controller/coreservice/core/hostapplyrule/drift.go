@@ -0,0 +1,363 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostapplyrule
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// ReconcileHostApply check the host's attributes governed by the enabled rules of its modules, the drifted attributes
+// are corrected if all of the host's modules are set to auto correct, otherwise they are recorded as drifts.
+func (p *hostApplyRule) ReconcileHostApply(kit *rest.Kit, hostID int64) errors.CCErrorCoder {
+	relationFilter := map[string]interface{}{
+		common.BKHostIDField: hostID,
+	}
+	relations := make([]metadata.ModuleHost, 0)
+	if err := p.dbProxy.Table(common.BKTableNameModuleHostConfig).Find(relationFilter).All(kit.Ctx, &relations); err != nil {
+		blog.ErrorJSON("ReconcileHostApply failed, find %s failed, filter: %s, err: %s, rid: %s", common.BKTableNameModuleHostConfig, relationFilter, err.Error(), kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if len(relations) == 0 {
+		return p.RemoveHostApplyDrift(kit, hostID)
+	}
+	bizID := relations[0].AppID
+
+	moduleIDs := make([]int64, 0)
+	for _, item := range relations {
+		moduleIDs = append(moduleIDs, item.ModuleID)
+	}
+	moduleFilter := map[string]interface{}{
+		common.BKModuleIDField: map[string]interface{}{
+			common.BKDBIN: moduleIDs,
+		},
+		common.HostApplyEnabledField: true,
+	}
+	modules := make([]metadata.ModuleInst, 0)
+	if err := p.dbProxy.Table(common.BKTableNameBaseModule).Find(moduleFilter).All(kit.Ctx, &modules); err != nil {
+		blog.ErrorJSON("ReconcileHostApply failed, find %s failed, filter: %s, err: %s, rid: %s", common.BKTableNameBaseModule, moduleFilter, err.Error(), kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if len(modules) == 0 {
+		return p.RemoveHostApplyDrift(kit, hostID)
+	}
+	enabledModuleIDs := make([]int64, 0)
+	autoCorrect := true
+	for _, module := range modules {
+		enabledModuleIDs = append(enabledModuleIDs, module.ModuleID)
+		if module.HostApplyDriftAction != metadata.HostApplyDriftActionAutoCorrect {
+			autoCorrect = false
+		}
+	}
+
+	host := make(map[string]interface{})
+	hostFilter := map[string]interface{}{
+		common.BKHostIDField: hostID,
+	}
+	if err := p.dbProxy.Table(common.BKTableNameBaseHost).Find(hostFilter).One(kit.Ctx, &host); err != nil {
+		if p.dbProxy.IsNotFoundError(err) {
+			return p.RemoveHostApplyDrift(kit, hostID)
+		}
+		blog.ErrorJSON("ReconcileHostApply failed, find host failed, filter: %s, err: %s, rid: %s", hostFilter, err.Error(), kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	listRuleOption := metadata.ListHostApplyRuleOption{
+		ModuleIDs: enabledModuleIDs,
+		Page: metadata.BasePage{
+			Limit: common.BKNoLimit,
+		},
+	}
+	rules, ccErr := p.ListHostApplyRule(kit, bizID, listRuleOption)
+	if ccErr != nil {
+		blog.ErrorJSON("ReconcileHostApply failed, ListHostApplyRule failed, option: %s, err: %s, rid: %s", listRuleOption, ccErr.Error(), kit.Rid)
+		return ccErr
+	}
+	planOption := metadata.HostApplyPlanOption{
+		Rules: rules.Info,
+		HostModules: []metadata.Host2Modules{
+			{HostID: hostID, ModuleIDs: enabledModuleIDs},
+		},
+	}
+	planResult, ccErr := p.GenerateApplyPlan(kit, bizID, planOption)
+	if ccErr != nil {
+		blog.ErrorJSON("ReconcileHostApply failed, GenerateApplyPlan failed, option: %s, err: %s, rid: %s", planOption, ccErr.Error(), kit.Rid)
+		return ccErr
+	}
+	if len(planResult.Plans) == 0 {
+		return nil
+	}
+	plan := planResult.Plans[0]
+	if err := plan.GetError(); err != nil {
+		blog.ErrorJSON("ReconcileHostApply failed, generate host apply plan failed, plan: %s, rid: %s", plan, kit.Rid)
+		return err
+	}
+
+	// the conflicted attributes are left to the users, only the attributes with a winner rule are checked
+	driftFields := make([]metadata.HostApplyUpdateField, 0)
+	for _, field := range plan.UpdateFields {
+		if field.WinnerRule == nil || hostApplyValueEqual(host[field.PropertyID], field.PropertyValue) {
+			continue
+		}
+		driftFields = append(driftFields, field)
+	}
+
+	if autoCorrect && len(driftFields) > 0 {
+		if ccErr := p.correctHostApplyDrift(kit, bizID, host, driftFields); ccErr != nil {
+			return ccErr
+		}
+		driftFields = make([]metadata.HostApplyUpdateField, 0)
+	}
+	return p.saveHostApplyDrift(kit, bizID, enabledModuleIDs, host, driftFields)
+}
+
+// hostApplyValueEqual compare the host's value with the rule's value, they may be decoded as different types
+func hostApplyValueEqual(hostValue, ruleValue interface{}) bool {
+	if cmp.Equal(hostValue, ruleValue) {
+		return true
+	}
+	if hostValue == nil || ruleValue == nil {
+		return false
+	}
+	return fmt.Sprint(hostValue) == fmt.Sprint(ruleValue)
+}
+
+// correctHostApplyDrift apply the winner rules' values on the host, and save an audit log for each of the rules
+func (p *hostApplyRule) correctHostApplyDrift(kit *rest.Kit, bizID int64, host map[string]interface{},
+	driftFields []metadata.HostApplyUpdateField) errors.CCErrorCoder {
+
+	hostID, err := util.GetInt64ByInterface(host[common.BKHostIDField])
+	if err != nil {
+		blog.ErrorJSON("correctHostApplyDrift failed, parse host id failed, host: %s, err: %s, rid: %s", host, err.Error(), kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommParseDBFailed)
+	}
+
+	updateData := make(map[string]interface{})
+	ruleFields := make(map[int64]map[string]interface{})
+	for _, field := range driftFields {
+		updateData[field.PropertyID] = field.PropertyValue
+		if _, exist := ruleFields[field.WinnerRule.ID]; !exist {
+			ruleFields[field.WinnerRule.ID] = make(map[string]interface{})
+		}
+		ruleFields[field.WinnerRule.ID][field.PropertyID] = field.PropertyValue
+	}
+	updateOption := metadata.UpdateOption{
+		Condition: map[string]interface{}{
+			common.BKHostIDField: hostID,
+		},
+		Data: updateData,
+	}
+	if _, err := p.dependence.UpdateModelInstance(kit, common.BKInnerObjIDHost, updateOption); err != nil {
+		blog.ErrorJSON("correctHostApplyDrift failed, UpdateModelInstance failed, option: %s, err: %s, rid: %s", updateOption, err.Error(), kit.Rid)
+		if ccErr, ok := err.(errors.CCErrorCoder); ok {
+			return ccErr
+		}
+		return kit.CCError.CCError(common.CCErrHostUpdateFail)
+	}
+
+	innerIP := util.GetStrByInterface(host[common.BKHostInnerIPField])
+	logs := make([]metadata.AuditLog, 0)
+	for ruleID, fields := range ruleFields {
+		curData := make(map[string]interface{})
+		for key, value := range host {
+			curData[key] = value
+		}
+		for key, value := range fields {
+			curData[key] = value
+		}
+		logs = append(logs, metadata.AuditLog{
+			AuditType:    metadata.HostType,
+			ResourceType: metadata.HostRes,
+			Action:       metadata.AuditUpdate,
+			OperateFrom:  metadata.FromHostApply,
+			OperationDetail: &metadata.InstanceOpDetail{
+				BasicOpDetail: metadata.BasicOpDetail{
+					BusinessID:   bizID,
+					ResourceID:   hostID,
+					ResourceName: innerIP,
+					Details: &metadata.BasicContent{
+						PreData: host,
+						CurData: curData,
+					},
+				},
+				ModelID: common.BKInnerObjIDHost,
+			},
+			Label: map[string]string{
+				common.HostApplyRuleIDField: strconv.FormatInt(ruleID, 10),
+			},
+		})
+	}
+	if err := p.audit.CreateAuditLog(kit, logs...); err != nil {
+		blog.ErrorJSON("correctHostApplyDrift failed, save audit logs failed, logs: %s, err: %s, rid: %s", logs, err.Error(), kit.Rid)
+		return kit.CCError.CCError(common.CCErrAuditSaveLogFailed)
+	}
+	return nil
+}
+
+// saveHostApplyDrift save the host's drifted attributes, and remove the drifts which are no longer exist
+func (p *hostApplyRule) saveHostApplyDrift(kit *rest.Kit, bizID int64, moduleIDs []int64, host map[string]interface{},
+	driftFields []metadata.HostApplyUpdateField) errors.CCErrorCoder {
+
+	hostID, err := util.GetInt64ByInterface(host[common.BKHostIDField])
+	if err != nil {
+		blog.ErrorJSON("saveHostApplyDrift failed, parse host id failed, host: %s, err: %s, rid: %s", host, err.Error(), kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommParseDBFailed)
+	}
+
+	now := time.Now()
+	attributeIDs := make([]int64, 0)
+	for _, field := range driftFields {
+		attributeIDs = append(attributeIDs, field.AttributeID)
+		filter := map[string]interface{}{
+			common.BKAppIDField:       bizID,
+			common.BKHostIDField:      hostID,
+			common.BKAttributeIDField: field.AttributeID,
+		}
+		count, err := p.dbProxy.Table(common.BKTableNameHostApplyDrift).Find(filter).Count(kit.Ctx)
+		if err != nil {
+			blog.ErrorJSON("saveHostApplyDrift failed, count drift failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+			return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		}
+		if count > 0 {
+			doc := map[string]interface{}{
+				common.BKModuleIDsField:     moduleIDs,
+				common.HostApplyRuleIDField: field.WinnerRule.ID,
+				"expect_value":              field.PropertyValue,
+				"actual_value":              host[field.PropertyID],
+				common.LastTimeField:        now,
+			}
+			if err := p.dbProxy.Table(common.BKTableNameHostApplyDrift).Update(kit.Ctx, filter, doc); err != nil {
+				blog.ErrorJSON("saveHostApplyDrift failed, update drift failed, filter: %s, doc: %s, err: %s, rid: %s", filter, doc, err.Error(), kit.Rid)
+				return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+			}
+			continue
+		}
+
+		id, err := p.dbProxy.NextSequence(kit.Ctx, common.BKTableNameHostApplyDrift)
+		if err != nil {
+			blog.Errorf("saveHostApplyDrift failed, generate id failed, err: %+v, rid: %s", err, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommGenerateRecordIDFailed)
+		}
+		drift := metadata.HostApplyDrift{
+			ID:              int64(id),
+			BizID:           bizID,
+			HostID:          hostID,
+			ModuleIDs:       moduleIDs,
+			AttributeID:     field.AttributeID,
+			PropertyID:      field.PropertyID,
+			RuleID:          field.WinnerRule.ID,
+			ExpectValue:     field.PropertyValue,
+			ActualValue:     host[field.PropertyID],
+			SupplierAccount: kit.SupplierAccount,
+			CreateTime:      now,
+			LastTime:        now,
+		}
+		if err := p.dbProxy.Table(common.BKTableNameHostApplyDrift).Insert(kit.Ctx, drift); err != nil {
+			blog.ErrorJSON("saveHostApplyDrift failed, insert drift failed, drift: %s, err: %s, rid: %s", drift, err.Error(), kit.Rid)
+			return kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+		}
+	}
+
+	staleFilter := map[string]interface{}{
+		common.BKHostIDField: hostID,
+		common.BKDBOR: []map[string]interface{}{
+			{
+				common.BKAppIDField: map[string]interface{}{
+					common.BKDBNE: bizID,
+				},
+			},
+			{
+				common.BKAttributeIDField: map[string]interface{}{
+					common.BKDBNIN: attributeIDs,
+				},
+			},
+		},
+	}
+	if err := p.dbProxy.Table(common.BKTableNameHostApplyDrift).Delete(kit.Ctx, staleFilter); err != nil {
+		blog.ErrorJSON("saveHostApplyDrift failed, delete stale drifts failed, filter: %s, err: %s, rid: %s", staleFilter, err.Error(), kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
+
+// RemoveHostApplyDrift remove all the drifts of the hosts
+func (p *hostApplyRule) RemoveHostApplyDrift(kit *rest.Kit, hostIDs ...int64) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BKHostIDField: map[string]interface{}{
+			common.BKDBIN: hostIDs,
+		},
+	}
+	if err := p.dbProxy.Table(common.BKTableNameHostApplyDrift).Delete(kit.Ctx, filter); err != nil {
+		blog.ErrorJSON("RemoveHostApplyDrift failed, db delete failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
+
+// ListHostApplyDrift list the drifts of the hosts in the business
+func (p *hostApplyRule) ListHostApplyDrift(kit *rest.Kit, bizID int64, option metadata.ListHostApplyDriftOption) (metadata.MultipleHostApplyDrift, errors.CCErrorCoder) {
+	result := metadata.MultipleHostApplyDrift{
+		Info: make([]metadata.HostApplyDrift, 0),
+	}
+	filter := map[string]interface{}{
+		common.BKAppIDField: bizID,
+	}
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+	if option.HostIDs != nil {
+		filter[common.BKHostIDField] = map[string]interface{}{
+			common.BKDBIN: option.HostIDs,
+		}
+	}
+	if option.ModuleIDs != nil {
+		filter[common.BKModuleIDsField] = map[string]interface{}{
+			common.BKDBIN: option.ModuleIDs,
+		}
+	}
+	if option.AttributeIDs != nil {
+		filter[common.BKAttributeIDField] = map[string]interface{}{
+			common.BKDBIN: option.AttributeIDs,
+		}
+	}
+	query := p.dbProxy.Table(common.BKTableNameHostApplyDrift).Find(filter)
+	total, err := query.Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("ListHostApplyDrift failed, db count failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	result.Count = int64(total)
+
+	if len(option.Page.Sort) > 0 {
+		query = query.Sort(option.Page.Sort)
+	}
+	if option.Page.Limit > 0 {
+		query = query.Limit(uint64(option.Page.Limit))
+	}
+	if option.Page.Start > 0 {
+		query = query.Start(uint64(option.Page.Start))
+	}
+	if err := query.All(kit.Ctx, &result.Info); err != nil {
+		blog.ErrorJSON("ListHostApplyDrift failed, db select failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return result, nil
+}
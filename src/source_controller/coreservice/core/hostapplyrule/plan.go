@@ -88,9 +88,19 @@ func (p *hostApplyRule) GenerateApplyPlan(kit *rest.Kit, bizID int64, option met
 		cloudMap[item.CloudID] = item
 	}
 
+	// get modules and the rules inherited from their sets and set templates
+	moduleMap, ccErr := p.getHostApplyModules(kit, option.HostModules)
+	if ccErr != nil {
+		return result, ccErr
+	}
+	rules, ccErr := p.mergeInheritedRules(kit, bizID, moduleMap, option.Rules)
+	if ccErr != nil {
+		return result, ccErr
+	}
+
 	// get attributes
 	attributeIDs := make([]int64, 0)
-	for _, item := range option.Rules {
+	for _, item := range rules {
 		attributeIDs = append(attributeIDs, item.AttributeID)
 	}
 	attributes, err := p.listHostAttributes(kit, bizID, attributeIDs...)
@@ -117,9 +127,9 @@ func (p *hostApplyRule) GenerateApplyPlan(kit *rest.Kit, bizID int64, option met
 			hostApplyPlans = append(hostApplyPlans, hostApplyPlan)
 			continue
 		}
-		hostApplyPlan, err = p.generateOneHostApplyPlan(kit, hostModule.HostID, host, hostModule.ModuleIDs, option.Rules, attributes, option.ConflictResolvers)
+		hostApplyPlan, err = p.generateOneHostApplyPlan(kit, hostModule.HostID, host, hostModule.ModuleIDs, moduleMap, rules, attributes, option.ConflictResolvers)
 		if err != nil {
-			blog.ErrorJSON("generateOneHostApplyPlan failed, host: %s, moduleIDs: %s, rules: %s, err: %s, rid: %s", host, hostModule.ModuleIDs, option.Rules, err.Error(), rid)
+			blog.ErrorJSON("generateOneHostApplyPlan failed, host: %s, moduleIDs: %s, rules: %s, err: %s, rid: %s", host, hostModule.ModuleIDs, rules, err.Error(), rid)
 			return result, err
 		}
 		if hostApplyPlan.UnresolvedConflictCount > 0 {
@@ -154,11 +164,110 @@ func (p *hostApplyRule) GenerateApplyPlan(kit *rest.Kit, bizID int64, option met
 	return result, nil
 }
 
+// getHostApplyModules get the modules of the hosts, their set and set template are used to match the inherited rules
+func (p *hostApplyRule) getHostApplyModules(kit *rest.Kit, hostModules []metadata.Host2Modules) (map[int64]metadata.ModuleInst, errors.CCErrorCoder) {
+	moduleIDs := make([]int64, 0)
+	for _, item := range hostModules {
+		moduleIDs = append(moduleIDs, item.ModuleIDs...)
+	}
+	moduleFilter := map[string]interface{}{
+		common.BKModuleIDField: map[string]interface{}{
+			common.BKDBIN: util.IntArrayUnique(moduleIDs),
+		},
+	}
+	modules := make([]metadata.ModuleInst, 0)
+	if err := p.dbProxy.Table(common.BKTableNameBaseModule).Find(moduleFilter).All(kit.Ctx, &modules); err != nil {
+		blog.ErrorJSON("getHostApplyModules failed, find %s failed, filter: %s, err: %s, rid: %s", common.BKTableNameBaseModule, moduleFilter, err.Error(), kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	moduleMap := make(map[int64]metadata.ModuleInst)
+	for _, module := range modules {
+		moduleMap[module.ModuleID] = module
+	}
+	return moduleMap, nil
+}
+
+// mergeInheritedRules add the rules attached to the modules' sets and set templates to the given rules,
+// the given rules take precedence as they may be the modified rules to preview.
+func (p *hostApplyRule) mergeInheritedRules(kit *rest.Kit, bizID int64, moduleMap map[int64]metadata.ModuleInst, rules []metadata.HostApplyRule) ([]metadata.HostApplyRule, errors.CCErrorCoder) {
+	setIDs := make([]int64, 0)
+	setTemplateIDs := make([]int64, 0)
+	for _, module := range moduleMap {
+		setIDs = append(setIDs, module.ParentID)
+		if module.SetTemplateID > 0 {
+			setTemplateIDs = append(setTemplateIDs, module.SetTemplateID)
+		}
+	}
+	if len(setIDs) == 0 {
+		return rules, nil
+	}
+
+	ruleFilter := map[string]interface{}{
+		common.BKAppIDField: bizID,
+		common.BKDBOR: []map[string]interface{}{
+			{
+				common.BKSetIDField: map[string]interface{}{
+					common.BKDBIN: util.IntArrayUnique(setIDs),
+				},
+			},
+			{
+				common.BKSetTemplateIDField: map[string]interface{}{
+					common.BKDBIN: util.IntArrayUnique(setTemplateIDs),
+				},
+			},
+		},
+	}
+	inheritedRules := make([]metadata.HostApplyRule, 0)
+	if err := p.dbProxy.Table(common.BKTableNameHostApplyRule).Find(ruleFilter).All(kit.Ctx, &inheritedRules); err != nil {
+		blog.ErrorJSON("mergeInheritedRules failed, find %s failed, filter: %s, err: %s, rid: %s", common.BKTableNameHostApplyRule, ruleFilter, err.Error(), kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	existRuleIDs := make(map[int64]bool)
+	for _, rule := range rules {
+		if rule.ID > 0 {
+			existRuleIDs[rule.ID] = true
+		}
+	}
+	result := append(make([]metadata.HostApplyRule, 0, len(rules)+len(inheritedRules)), rules...)
+	for _, rule := range inheritedRules {
+		if _, exist := existRuleIDs[rule.ID]; exist {
+			continue
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+// hostApplyRuleLess sort the candidate rules of a host's attribute, the first one has the highest precedence:
+// the higher priority, the more specific scope, and the rules with conditions first.
+func hostApplyRuleLess(a, b metadata.HostApplyRule) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if a.ScopeRank() != b.ScopeRank() {
+		return a.ScopeRank() > b.ScopeRank()
+	}
+	return len(a.Conditions) > 0 && len(b.Conditions) == 0
+}
+
+// winReason explain why the winner rule takes precedence over the first rule of the lower tier
+func winReason(winner, loser metadata.HostApplyRule) string {
+	if winner.Priority != loser.Priority {
+		return metadata.HostApplyWinByPriority
+	}
+	if winner.ScopeRank() != loser.ScopeRank() {
+		return metadata.HostApplyWinByScope
+	}
+	return metadata.HostApplyWinByCondition
+}
+
 func (p *hostApplyRule) generateOneHostApplyPlan(
 	kit *rest.Kit,
 	hostID int64,
 	host map[string]interface{},
 	moduleIDs []int64,
+	moduleMap map[int64]metadata.ModuleInst,
 	rules []metadata.HostApplyRule,
 	attributes []metadata.Attribute,
 	resolvers []metadata.HostApplyConflictResolver,
@@ -182,13 +291,24 @@ func (p *hostApplyRule) generateOneHostApplyPlan(
 		UnresolvedConflictCount: 0,
 	}
 
-	moduleIDSet := make(map[int64]bool)
+	modules := make([]metadata.ModuleInst, 0)
 	for _, moduleID := range moduleIDs {
-		moduleIDSet[moduleID] = true
+		module, exist := moduleMap[moduleID]
+		if !exist {
+			module = metadata.ModuleInst{ModuleID: moduleID}
+		}
+		modules = append(modules, module)
 	}
 	attributeRules := make(map[int64][]metadata.HostApplyRule)
 	for _, rule := range rules {
-		if _, exist := moduleIDSet[rule.ModuleID]; !exist {
+		applied := false
+		for _, module := range modules {
+			if rule.AppliesTo(module, host) {
+				applied = true
+				break
+			}
+		}
+		if !applied {
 			continue
 		}
 		if _, exist := attributeRules[rule.AttributeID]; !exist {
@@ -221,10 +341,31 @@ func (p *hostApplyRule) generateOneHostApplyPlan(
 			originalValue = nil
 		}
 
+		// only the rules with the highest precedence take part in the conflict check
+		sort.SliceStable(targetRules, func(i, j int) bool {
+			return hostApplyRuleLess(targetRules[i], targetRules[j])
+		})
+		topRules := targetRules[:1]
+		for _, rule := range targetRules[1:] {
+			if hostApplyRuleLess(topRules[0], rule) {
+				break
+			}
+			topRules = append(topRules, rule)
+		}
+
 		// check conflicts
-		firstValue := targetRules[0].PropertyValue
+		firstValue := topRules[0].PropertyValue
 		conflictedStillExist := false
-		for _, rule := range targetRules {
+		winnerRule := &targetRules[0]
+		reason := metadata.HostApplyWinByOnlyRule
+		if len(targetRules) > 1 {
+			if len(topRules) == len(targetRules) {
+				reason = metadata.HostApplyWinBySameValue
+			} else {
+				reason = winReason(topRules[0], targetRules[len(topRules)])
+			}
+		}
+		for _, rule := range topRules {
 			if cmp.Equal(firstValue, rule.PropertyValue) {
 				continue
 			}
@@ -233,13 +374,15 @@ func (p *hostApplyRule) generateOneHostApplyPlan(
 			if propertyValue, exist := resolverMap[attribute.ID]; exist {
 				conflictedStillExist = false
 				firstValue = propertyValue
+				winnerRule = nil
+				reason = metadata.HostApplyWinByResolver
 			}
 
 			plan.ConflictFields = append(plan.ConflictFields, metadata.HostApplyConflictField{
 				AttributeID:             attributeID,
 				PropertyID:              propertyIDField,
 				PropertyValue:           originalValue,
-				Rules:                   targetRules,
+				Rules:                   topRules,
 				UnresolvedConflictExist: conflictedStillExist,
 			})
 			break
@@ -253,7 +396,9 @@ func (p *hostApplyRule) generateOneHostApplyPlan(
 		// validate property value before update to host
 		if value, ok := firstValue.(string); ok {
 			firstValue = strings.TrimSpace(value)
-			targetRules[0].PropertyValue = firstValue
+			if winnerRule != nil {
+				winnerRule.PropertyValue = firstValue
+			}
 		}
 		rawErr := attribute.Validate(kit.Ctx, firstValue, propertyIDField)
 		if rawErr.ErrCode != 0 {
@@ -271,6 +416,8 @@ func (p *hostApplyRule) generateOneHostApplyPlan(
 			AttributeID:   attributeID,
 			PropertyID:    propertyIDField,
 			PropertyValue: firstValue,
+			WinnerRule:    winnerRule,
+			WinReason:     reason,
 		})
 	}
 
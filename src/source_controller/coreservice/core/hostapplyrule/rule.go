@@ -26,12 +26,14 @@ import (
 	"configcenter/src/common/querybuilder"
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/source_controller/coreservice/core/auditlog"
 	"configcenter/src/storage/dal"
 )
 
 type hostApplyRule struct {
 	dbProxy    dal.RDB
 	dependence HostApplyDependence
+	audit      core.AuditOperation
 }
 
 type HostApplyDependence interface {
@@ -42,6 +44,7 @@ func New(dbProxy dal.RDB, dependence HostApplyDependence) core.HostApplyRuleOper
 	rule := &hostApplyRule{
 		dbProxy:    dbProxy,
 		dependence: dependence,
+		audit:      auditlog.New(dbProxy),
 	}
 	return rule
 }
@@ -62,6 +65,63 @@ func (p *hostApplyRule) validateModuleID(kit *rest.Kit, bizID int64, moduleID in
 	return nil
 }
 
+// validateRuleScope validate the module, set or set template the rule is attached to belongs to the business
+func (p *hostApplyRule) validateRuleScope(kit *rest.Kit, bizID int64, rule metadata.HostApplyRule) errors.CCErrorCoder {
+	switch rule.GetScope() {
+	case metadata.HostApplyRuleScopeSet:
+		return p.validateScopeID(kit, common.BKTableNameBaseSet, common.BKSetIDField, bizID, rule.SetID)
+	case metadata.HostApplyRuleScopeSetTemplate:
+		return p.validateScopeID(kit, common.BKTableNameSetTemplate, common.BKFieldID, bizID, rule.SetTemplateID)
+	default:
+		return p.validateModuleID(kit, bizID, rule.ModuleID)
+	}
+}
+
+func (p *hostApplyRule) validateScopeID(kit *rest.Kit, table, idField string, bizID int64, id int64) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BKAppIDField: bizID,
+		idField:             id,
+	}
+	count, err := p.dbProxy.Table(table).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("validateScopeID failed, db select failed, table: %s, filter: %+v, err: %+v, rid: %s", table, filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count == 0 {
+		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, idField)
+	}
+	return nil
+}
+
+// scopeFilter generate the filter of the rules attached to the same module, set or set template with the rule
+func scopeFilter(kit *rest.Kit, bizID int64, rule metadata.HostApplyRule) map[string]interface{} {
+	return map[string]interface{}{
+		common.BKAppIDField:         bizID,
+		common.BkSupplierAccount:    kit.SupplierAccount,
+		common.BKAttributeIDField:   rule.AttributeID,
+		common.BKModuleIDField:      rule.ModuleID,
+		common.BKSetIDField:         rule.SetID,
+		common.BKSetTemplateIDField: rule.SetTemplateID,
+	}
+}
+
+// checkRuleDuplicated check whether there is another rule with the same scope, attribute and conditions
+func (p *hostApplyRule) checkRuleDuplicated(kit *rest.Kit, bizID int64, rule metadata.HostApplyRule) errors.CCErrorCoder {
+	filter := scopeFilter(kit, bizID, rule)
+	rules := make([]metadata.HostApplyRule, 0)
+	if err := p.dbProxy.Table(common.BKTableNameHostApplyRule).Find(filter).All(kit.Ctx, &rules); err != nil {
+		blog.Errorf("checkRuleDuplicated failed, db select failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	conditionKey := rule.ConditionKey()
+	for _, item := range rules {
+		if item.ID != rule.ID && item.ConditionKey() == conditionKey {
+			return kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, common.BKAttributeIDField)
+		}
+	}
+	return nil
+}
+
 func (p *hostApplyRule) listHostAttributes(kit *rest.Kit, bizID int64, hostAttributeIDs ...int64) ([]metadata.Attribute, errors.CCErrorCoder) {
 	filter := map[string]interface{}{
 		common.BKDBOR: []map[string]interface{}{
@@ -122,7 +182,11 @@ func (p *hostApplyRule) CreateHostApplyRule(kit *rest.Kit, bizID int64, option m
 		BizID:           bizID,
 		AttributeID:     option.AttributeID,
 		ModuleID:        option.ModuleID,
+		SetID:           option.SetID,
+		SetTemplateID:   option.SetTemplateID,
 		PropertyValue:   option.PropertyValue,
+		Conditions:      option.Conditions,
+		Priority:        option.Priority,
 		Creator:         kit.User,
 		Modifier:        kit.User,
 		CreateTime:      now,
@@ -134,9 +198,13 @@ func (p *hostApplyRule) CreateHostApplyRule(kit *rest.Kit, bizID int64, option m
 		return rule, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
 	}
 
-	// validate bk_module_id
-	if err := p.validateModuleID(kit, bizID, rule.ModuleID); err != nil {
-		blog.Errorf("CreateHostApplyRule failed, validate bk_module_id failed, bizID: %d, moduleID: %d, err: %s, rid: %s", bizID, err.Error(), kit.Rid)
+	// validate bk_module_id, bk_set_id or set_template_id
+	if err := p.validateRuleScope(kit, bizID, rule); err != nil {
+		blog.Errorf("CreateHostApplyRule failed, validate rule scope failed, bizID: %d, rule: %+v, err: %s, rid: %s", bizID, rule, err.Error(), kit.Rid)
+		return rule, err
+	}
+	if err := p.checkRuleDuplicated(kit, bizID, rule); err != nil {
+		blog.Errorf("CreateHostApplyRule failed, rule duplicated, bizID: %d, rule: %+v, rid: %s", bizID, rule, kit.Rid)
 		return rule, err
 	}
 
@@ -201,6 +269,9 @@ func (p *hostApplyRule) UpdateHostApplyRule(kit *rest.Kit, bizID int64, ruleID i
 	rule.LastTime = time.Now()
 	rule.Modifier = kit.User
 	rule.PropertyValue = option.PropertyValue
+	if option.Priority != nil {
+		rule.Priority = *option.Priority
+	}
 
 	filter := map[string]interface{}{
 		common.BKFieldID: ruleID,
@@ -253,20 +324,29 @@ func (p *hostApplyRule) GetHostApplyRule(kit *rest.Kit, bizID int64, ruleID int6
 	return rule, nil
 }
 
-func (p *hostApplyRule) GetHostApplyRuleByAttributeID(kit *rest.Kit, bizID, moduleID, attributeID int64) (metadata.HostApplyRule, errors.CCErrorCoder) {
-	rule := metadata.HostApplyRule{}
-	filter := map[string]interface{}{
-		common.BkSupplierAccount:  kit.SupplierAccount,
-		common.BKAppIDField:       bizID,
-		common.BKModuleIDField:    moduleID,
-		common.BKAttributeIDField: attributeID,
+// unconditionalRuleFilter generate the filter of the unconditional rule which is created or updated in batch
+func unconditionalRuleFilter(kit *rest.Kit, bizID int64, option metadata.CreateOrUpdateApplyRuleOption) map[string]interface{} {
+	filter := scopeFilter(kit, bizID, metadata.HostApplyRule{
+		ModuleID:      option.ModuleID,
+		SetID:         option.SetID,
+		SetTemplateID: option.SetTemplateID,
+		AttributeID:   option.AttributeID,
+	})
+	filter[common.BKConditionsField] = map[string]interface{}{
+		common.BKDBExists: false,
 	}
+	return filter
+}
+
+func (p *hostApplyRule) GetUnconditionalHostApplyRule(kit *rest.Kit, bizID int64, option metadata.CreateOrUpdateApplyRuleOption) (metadata.HostApplyRule, errors.CCErrorCoder) {
+	rule := metadata.HostApplyRule{}
+	filter := unconditionalRuleFilter(kit, bizID, option)
 	if err := p.dbProxy.Table(common.BKTableNameHostApplyRule).Find(filter).One(kit.Ctx, &rule); err != nil {
 		if p.dbProxy.IsNotFoundError(err) {
-			blog.Errorf("GetHostApplyRuleByAttributeID failed, db select failed, not found, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+			blog.Errorf("GetUnconditionalHostApplyRule failed, db select failed, not found, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
 			return rule, kit.CCError.CCError(common.CCErrCommNotFound)
 		}
-		blog.Errorf("GetHostApplyRuleByAttributeID failed, db select failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		blog.Errorf("GetUnconditionalHostApplyRule failed, db select failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
 		return rule, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
 	}
 	return rule, nil
@@ -290,6 +370,16 @@ func (p *hostApplyRule) ListHostApplyRule(kit *rest.Kit, bizID int64, option met
 			common.BKDBIN: option.ModuleIDs,
 		}
 	}
+	if option.SetIDs != nil {
+		filter[common.BKSetIDField] = map[string]interface{}{
+			common.BKDBIN: option.SetIDs,
+		}
+	}
+	if option.SetTemplateIDs != nil {
+		filter[common.BKSetTemplateIDField] = map[string]interface{}{
+			common.BKDBIN: option.SetTemplateIDs,
+		}
+	}
 	if len(option.AttributeIDs) != 0 {
 		filter[common.BKAttributeIDField] = map[string]interface{}{
 			common.BKDBIN: option.AttributeIDs,
@@ -471,11 +561,18 @@ func (p *hostApplyRule) BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, opt
 		itemResult := metadata.CreateOrUpdateHostApplyRuleResult{
 			Index: index,
 		}
-		ruleFilter := map[string]interface{}{
-			common.BKAppIDField:       bizID,
-			common.BkSupplierAccount:  kit.SupplierAccount,
-			common.BKAttributeIDField: item.AttributeID,
-			common.BKModuleIDField:    item.ModuleID,
+		ruleFilter := unconditionalRuleFilter(kit, bizID, item)
+		scopeRule := metadata.HostApplyRule{ModuleID: item.ModuleID, SetID: item.SetID, SetTemplateID: item.SetTemplateID}
+		if key, err := scopeRule.Validate(); err != nil {
+			blog.Errorf("BatchUpdateHostApplyRule failed, invalid rule scope, key: %s, err: %+v, rid: %s", key, err, rid)
+			itemResult.SetError(kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key))
+			batchResult.Items = append(batchResult.Items, itemResult)
+			continue
+		}
+		if ccErr := p.validateRuleScope(kit, bizID, scopeRule); ccErr != nil {
+			itemResult.SetError(ccErr)
+			batchResult.Items = append(batchResult.Items, itemResult)
+			continue
 		}
 		count, err := p.dbProxy.Table(common.BKTableNameHostApplyRule).Find(ruleFilter).Count(kit.Ctx)
 		if err != nil {
@@ -510,6 +607,7 @@ func (p *hostApplyRule) BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, opt
 		if count > 0 {
 			updateData := map[string]interface{}{
 				common.BKPropertyValueField: item.PropertyValue,
+				common.BKPriorityField:      item.Priority,
 				common.LastTimeField:        now,
 				common.ModifierField:        kit.User,
 			}
@@ -535,8 +633,11 @@ func (p *hostApplyRule) BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, opt
 			ID:              int64(newRuleID),
 			BizID:           bizID,
 			ModuleID:        item.ModuleID,
+			SetID:           item.SetID,
+			SetTemplateID:   item.SetTemplateID,
 			AttributeID:     item.AttributeID,
 			PropertyValue:   item.PropertyValue,
+			Priority:        item.Priority,
 			Creator:         kit.User,
 			Modifier:        kit.User,
 			CreateTime:      now,
@@ -554,9 +655,9 @@ func (p *hostApplyRule) BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, opt
 	}
 
 	for index, item := range option.Rules {
-		rule, ccErr := p.GetHostApplyRuleByAttributeID(kit, bizID, item.ModuleID, item.AttributeID)
+		rule, ccErr := p.GetUnconditionalHostApplyRule(kit, bizID, item)
 		if ccErr != nil {
-			blog.Errorf("GetHostApplyRuleByAttributeID failed, bizID: %d, moduleID: %d, attribute: %d, err: %s, rid: %s", bizID, item.ModuleID, item.AttributeID, ccErr.Error(), rid)
+			blog.Errorf("GetUnconditionalHostApplyRule failed, bizID: %d, option: %+v, err: %s, rid: %s", bizID, item, ccErr.Error(), rid)
 			if err := batchResult.Items[index].GetError(); err == nil {
 				batchResult.Items[index].SetError(ccErr)
 			}
@@ -0,0 +1,251 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instances
+
+import (
+	"strconv"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// relationField is the temporary field which holds the host module relation in the host aggregate pipeline
+const relationField = "__relation"
+
+// AggregateModelInstances groups the object's instances and calculates the metrics of the groups, the
+// pipeline is generated from the validated option so that the callers can not run arbitrary pipelines.
+func (m *instanceManager) AggregateModelInstances(kit *rest.Kit, objID string, option metadata.AggregateOption) (*metadata.AggregateResult, errors.CCErrorCoder) {
+	if field, err := option.Validate(); err != nil {
+		blog.Errorf("AggregateModelInstances failed, option invalid, field: %s, err: %v, rid: %s", field, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field)
+	}
+	if err := m.validAggregateFields(kit, objID, option); err != nil {
+		return nil, err
+	}
+
+	pipeline, err := m.aggregatePipeline(kit, objID, option)
+	if err != nil {
+		return nil, err
+	}
+
+	tableName := common.GetInstTableName(objID)
+	countPipeline := append(pipeline[:len(pipeline):len(pipeline)], map[string]interface{}{common.BKDBCount: "count"})
+	counts := make([]struct {
+		Count int64 `bson:"count"`
+	}, 0)
+	if err := m.dbProxy.Table(tableName).AggregateAll(kit.Ctx, countPipeline, &counts); err != nil {
+		blog.ErrorJSON("AggregateModelInstances failed, count the groups failed, pipeline: %s, err: %s, rid: %s", countPipeline, err.Error(), kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommDBSelectFailed)
+	}
+
+	result := &metadata.AggregateResult{Info: make([]metadata.AggregateGroup, 0)}
+	if len(counts) == 0 || counts[0].Count == 0 {
+		return result, nil
+	}
+	result.Count = counts[0].Count
+
+	pipeline = append(pipeline, aggregatePageStages(option)...)
+	if err := m.dbProxy.Table(tableName).AggregateAll(kit.Ctx, pipeline, &result.Info); err != nil {
+		blog.ErrorJSON("AggregateModelInstances failed, aggregate the groups failed, pipeline: %s, err: %s, rid: %s", pipeline, err.Error(), kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommDBSelectFailed)
+	}
+	return result, nil
+}
+
+// validAggregateFields checks the group by and metric fields are the object's attributes, the topology fields
+// can only be used to group the instances, and only the numeric fields can be summed up or compared.
+func (m *instanceManager) validAggregateFields(kit *rest.Kit, objID string, option metadata.AggregateOption) errors.CCErrorCoder {
+	switch objID {
+	case common.BKInnerObjIDHost, common.BKInnerObjIDSet, common.BKInnerObjIDModule:
+	default:
+		if option.BizID != 0 {
+			blog.Errorf("AggregateModelInstances failed, object %s can not be aggregated in business, rid: %s", objID, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField)
+		}
+	}
+
+	attributes, err := m.dependent.SelectObjectAttWithParams(kit, objID, option.BizID)
+	if err != nil {
+		blog.Errorf("AggregateModelInstances failed, get the attributes of object %s failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrTopoObjectAttributeSelectFailed)
+	}
+	propertyTypes := make(map[string]string)
+	for _, attribute := range attributes {
+		propertyTypes[attribute.PropertyID] = attribute.PropertyType
+	}
+
+	for _, field := range option.GroupBy {
+		if _, exists := propertyTypes[field]; !exists && !metadata.IsTopoField(objID, field) {
+			blog.Errorf("AggregateModelInstances failed, group by field %s is not an attribute of %s, rid: %s", field, objID, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "group_by")
+		}
+	}
+
+	for idx, metric := range option.Metrics {
+		if metric.Type == metadata.AggregateCount {
+			continue
+		}
+		propertyType, exists := propertyTypes[metric.Field]
+		if !exists || metric.IsNumeric() && propertyType != common.FieldTypeInt && propertyType != common.FieldTypeFloat {
+			blog.Errorf("AggregateModelInstances failed, metric %s is invalid for %s, rid: %s", metric.Name(), objID, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "metrics["+strconv.Itoa(idx)+"].field")
+		}
+	}
+	return nil
+}
+
+func (m *instanceManager) aggregatePipeline(kit *rest.Kit, objID string, option metadata.AggregateOption) ([]interface{}, errors.CCErrorCoder) {
+	filter := make(map[string]interface{})
+	if option.Filter != nil && option.Filter.Rule != nil {
+		mgoFilter, key, err := option.Filter.ToMgo()
+		if err != nil {
+			blog.Errorf("AggregateModelInstances failed, invalid filter, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "filter."+key)
+		}
+		filter = mgoFilter
+	}
+	if common.GetInstTableName(objID) == common.BKTableNameBaseInst {
+		filter[common.BKObjIDField] = objID
+	}
+	if option.BizID != 0 && objID != common.BKInnerObjIDHost {
+		filter[common.BKAppIDField] = option.BizID
+	}
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+	pipeline := []interface{}{map[string]interface{}{common.BKDBMatch: filter}}
+
+	// fieldRef returns the reference of the field in the group stage
+	fieldRef := func(field string) string {
+		return "$" + field
+	}
+
+	needRelation := option.BizID != 0
+	for _, field := range option.GroupBy {
+		needRelation = needRelation || metadata.IsTopoField(objID, field)
+	}
+	if objID == common.BKInnerObjIDHost && needRelation {
+		pipeline = append(pipeline, hostRelationStages(option)...)
+		fieldRef = func(field string) string {
+			if metadata.IsTopoField(objID, field) {
+				return "$_id." + field
+			}
+			return "$" + field
+		}
+	}
+
+	groupID := make(map[string]interface{})
+	for _, field := range option.GroupBy {
+		groupID[field] = fieldRef(field)
+	}
+	group := map[string]interface{}{"_id": groupID}
+	for _, metric := range option.Metrics {
+		switch metric.Type {
+		case metadata.AggregateCount:
+			group[metric.Name()] = map[string]interface{}{common.BKDBSum: 1}
+		case metadata.AggregateSum:
+			group[metric.Name()] = map[string]interface{}{common.BKDBSum: fieldRef(metric.Field)}
+		case metadata.AggregateMin:
+			group[metric.Name()] = map[string]interface{}{"$min": fieldRef(metric.Field)}
+		case metadata.AggregateMax:
+			group[metric.Name()] = map[string]interface{}{"$max": fieldRef(metric.Field)}
+		case metadata.AggregateDistinctCount:
+			group[metric.Name()] = map[string]interface{}{common.BKDBAddToSet: fieldRef(metric.Field)}
+		}
+	}
+	return append(pipeline, map[string]interface{}{common.BKDBGroup: group}), nil
+}
+
+// hostRelationStages joins the hosts with their module relations, then keeps one document for each host in
+// each topology group, so that a host in several modules of a set is counted only once in the set.
+func hostRelationStages(option metadata.AggregateOption) []interface{} {
+	stages := []interface{}{
+		map[string]interface{}{"$lookup": map[string]interface{}{
+			"from":         common.BKTableNameModuleHostConfig,
+			"localField":   common.BKHostIDField,
+			"foreignField": common.BKHostIDField,
+			"as":           relationField,
+		}},
+		map[string]interface{}{"$unwind": "$" + relationField},
+	}
+	if option.BizID != 0 {
+		stages = append(stages, map[string]interface{}{
+			common.BKDBMatch: map[string]interface{}{relationField + "." + common.BKAppIDField: option.BizID},
+		})
+	}
+
+	groupID := map[string]interface{}{common.BKHostIDField: "$" + common.BKHostIDField}
+	group := map[string]interface{}{"_id": groupID}
+	for _, field := range option.GroupBy {
+		if metadata.IsTopoField(common.BKInnerObjIDHost, field) {
+			groupID[field] = "$" + relationField + "." + field
+		} else {
+			group[field] = map[string]interface{}{"$first": "$" + field}
+		}
+	}
+	for _, metric := range option.Metrics {
+		if metric.Type != metadata.AggregateCount {
+			group[metric.Field] = map[string]interface{}{"$first": "$" + metric.Field}
+		}
+	}
+	return append(stages, map[string]interface{}{common.BKDBGroup: group})
+}
+
+// aggregatePageStages sorts the groups by the sort field and the group by fields, then returns the page. the
+// distinct values are replaced by their count before they are sorted, otherwise the arrays are sorted by their
+// min or max elements.
+func aggregatePageStages(option metadata.AggregateOption) []interface{} {
+	sort := bson.D{}
+	if len(option.Page.Sort) > 0 {
+		field := strings.TrimPrefix(option.Page.Sort, "-")
+		order := 1
+		if strings.HasPrefix(option.Page.Sort, "-") {
+			order = -1
+		}
+		for _, groupBy := range option.GroupBy {
+			if groupBy == field {
+				field = "_id." + field
+				break
+			}
+		}
+		sort = append(sort, bson.E{Key: field, Value: order})
+	}
+	for _, field := range option.GroupBy {
+		sort = append(sort, bson.E{Key: "_id." + field, Value: 1})
+	}
+
+	stages := make([]interface{}, 0)
+	distinctCounts := make(map[string]interface{})
+	metrics := make(map[string]interface{})
+	for _, metric := range option.Metrics {
+		if metric.Type == metadata.AggregateDistinctCount {
+			distinctCounts[metric.Name()] = map[string]interface{}{"$size": "$" + metric.Name()}
+		}
+		metrics[metric.Name()] = "$" + metric.Name()
+	}
+	if len(distinctCounts) > 0 {
+		stages = append(stages, map[string]interface{}{"$addFields": distinctCounts})
+	}
+
+	return append(stages,
+		map[string]interface{}{"$sort": sort},
+		map[string]interface{}{"$skip": option.Page.Start},
+		map[string]interface{}{"$limit": option.Page.Limit},
+		map[string]interface{}{"$project": map[string]interface{}{"_id": 0, "group": "$_id", "metrics": metrics}},
+	)
+}
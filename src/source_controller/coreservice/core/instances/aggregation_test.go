@@ -0,0 +1,130 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instances
+
+import (
+	"reflect"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestHostRelationStages(t *testing.T) {
+	lookup := map[string]interface{}{"$lookup": map[string]interface{}{
+		"from":         common.BKTableNameModuleHostConfig,
+		"localField":   common.BKHostIDField,
+		"foreignField": common.BKHostIDField,
+		"as":           relationField,
+	}}
+	unwind := map[string]interface{}{"$unwind": "$" + relationField}
+
+	tests := []struct {
+		name   string
+		option metadata.AggregateOption
+		want   []interface{}
+	}{
+		{
+			name: "group by topology and attribute fields",
+			option: metadata.AggregateOption{
+				GroupBy: []string{common.BKSetIDField, "bk_os_type"},
+				Metrics: []metadata.AggregateMetric{{Type: metadata.AggregateCount}, {Type: metadata.AggregateSum, Field: "bk_cpu"}},
+			},
+			want: []interface{}{lookup, unwind, map[string]interface{}{common.BKDBGroup: map[string]interface{}{
+				"_id": map[string]interface{}{
+					common.BKHostIDField: "$" + common.BKHostIDField,
+					common.BKSetIDField:  "$" + relationField + "." + common.BKSetIDField,
+				},
+				"bk_os_type": map[string]interface{}{"$first": "$bk_os_type"},
+				"bk_cpu":     map[string]interface{}{"$first": "$bk_cpu"},
+			}}},
+		},
+		{
+			name: "in business",
+			option: metadata.AggregateOption{
+				BizID:   2,
+				GroupBy: []string{common.BKModuleIDField},
+				Metrics: []metadata.AggregateMetric{{Type: metadata.AggregateCount}},
+			},
+			want: []interface{}{lookup, unwind,
+				map[string]interface{}{common.BKDBMatch: map[string]interface{}{relationField + "." + common.BKAppIDField: int64(2)}},
+				map[string]interface{}{common.BKDBGroup: map[string]interface{}{
+					"_id": map[string]interface{}{
+						common.BKHostIDField:   "$" + common.BKHostIDField,
+						common.BKModuleIDField: "$" + relationField + "." + common.BKModuleIDField,
+					},
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hostRelationStages(tt.option); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hostRelationStages() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregatePageStages(t *testing.T) {
+	tests := []struct {
+		name   string
+		option metadata.AggregateOption
+		want   []interface{}
+	}{
+		{
+			name: "sort by group by field",
+			option: metadata.AggregateOption{
+				GroupBy: []string{"bk_os_type", "bk_cloud_id"},
+				Metrics: []metadata.AggregateMetric{{Type: metadata.AggregateCount}},
+				Page:    metadata.BasePage{Start: 10, Limit: 5, Sort: "-bk_cloud_id"},
+			},
+			want: []interface{}{
+				map[string]interface{}{"$sort": bson.D{{Key: "_id.bk_cloud_id", Value: -1},
+					{Key: "_id.bk_os_type", Value: 1}, {Key: "_id.bk_cloud_id", Value: 1}}},
+				map[string]interface{}{"$skip": 10},
+				map[string]interface{}{"$limit": 5},
+				map[string]interface{}{"$project": map[string]interface{}{"_id": 0, "group": "$_id",
+					"metrics": map[string]interface{}{"count": "$count"}}},
+			},
+		},
+		{
+			name: "sort by distinct count",
+			option: metadata.AggregateOption{
+				GroupBy: []string{"bk_os_type"},
+				Metrics: []metadata.AggregateMetric{{Type: metadata.AggregateDistinctCount, Field: "bk_cloud_id"}},
+				Page:    metadata.BasePage{Limit: 5, Sort: "-distinct_count_bk_cloud_id"},
+			},
+			want: []interface{}{
+				map[string]interface{}{"$addFields": map[string]interface{}{
+					"distinct_count_bk_cloud_id": map[string]interface{}{"$size": "$distinct_count_bk_cloud_id"},
+				}},
+				map[string]interface{}{"$sort": bson.D{{Key: "distinct_count_bk_cloud_id", Value: -1},
+					{Key: "_id.bk_os_type", Value: 1}}},
+				map[string]interface{}{"$skip": 0},
+				map[string]interface{}{"$limit": 5},
+				map[string]interface{}{"$project": map[string]interface{}{"_id": 0, "group": "$_id",
+					"metrics": map[string]interface{}{"distinct_count_bk_cloud_id": "$distinct_count_bk_cloud_id"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aggregatePageStages(tt.option); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("aggregatePageStages() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
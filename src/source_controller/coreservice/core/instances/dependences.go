@@ -13,7 +13,9 @@
 package instances
 
 import (
+	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 )
 
@@ -32,4 +34,10 @@ type OperationDependences interface {
 
 	// SearchUnique search unique attribute
 	SearchUnique(kit *rest.Kit, objID string) (uniqueAttr []metadata.ObjectUnique, err error)
+
+	// AddTrash save the instances to the trash bin before they are deleted if the object is opted into it
+	AddTrash(kit *rest.Kit, objID string, instances []mapstr.MapStr) errors.CCErrorCoder
+
+	// CheckQuota check if the quota of the resource is enough for the instances to be created
+	CheckQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64, increment int64) errors.CCErrorCoder
 }
@@ -0,0 +1,345 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instances
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/expression"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+const (
+	// expressionRefreshPageSize the instances count refreshed in one batch.
+	expressionRefreshPageSize = 500
+	// maxMainlineDepth is used to avoid endless loop when walking through the mainline topology.
+	maxMainlineDepth = 16
+)
+
+// expressionAttribute is an expression attribute with the parsed expression.
+type expressionAttribute struct {
+	propertyID string
+	// bizID is the business of a business private attribute, 0 means it's a public attribute.
+	bizID int64
+	expr  *expression.Expression
+	// parents is the mainline parent objects referenced by the expression.
+	parents []string
+}
+
+func parseExpressionAttributes(kit *rest.Kit, attrs []metadata.Attribute) []expressionAttribute {
+	exprAttrs := make([]expressionAttribute, 0)
+	for _, attr := range attrs {
+		if attr.PropertyType != common.FieldTypeExpression {
+			continue
+		}
+
+		option, _ := attr.Option.(string)
+		expr, err := expression.Parse(option)
+		if err != nil {
+			// option is validated when the attribute is saved, this should not happen.
+			blog.Errorf("parse expression of attribute %s.%s failed, option: %v, err: %v, rid: %s", attr.ObjectID, attr.PropertyID, attr.Option, err, kit.Rid)
+			continue
+		}
+
+		bizID, _ := attr.Metadata.ParseBizID()
+		exprAttr := expressionAttribute{propertyID: attr.PropertyID, bizID: bizID, expr: expr, parents: make([]string, 0)}
+		for _, field := range expr.Fields() {
+			if parts := strings.Split(field, "."); len(parts) > 1 {
+				exprAttr.parents = append(exprAttr.parents, parts[0])
+			}
+		}
+		exprAttr.parents = util.StrArrayUnique(exprAttr.parents)
+		exprAttrs = append(exprAttrs, exprAttr)
+	}
+	return exprAttrs
+}
+
+// getExpressionAttributes get all the expression attributes keyed by object id.
+func (m *instanceManager) getExpressionAttributes(kit *rest.Kit) (map[string][]expressionAttribute, error) {
+	cond := util.SetQueryOwner(map[string]interface{}{common.BKPropertyTypeField: common.FieldTypeExpression}, kit.SupplierAccount)
+	attrs := make([]metadata.Attribute, 0)
+	if err := m.dbProxy.Table(common.BKTableNameObjAttDes).Find(cond).All(kit.Ctx, &attrs); err != nil {
+		blog.Errorf("get expression attributes failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	objAttrs := make(map[string][]metadata.Attribute)
+	for _, attr := range attrs {
+		objAttrs[attr.ObjectID] = append(objAttrs[attr.ObjectID], attr)
+	}
+
+	exprAttrs := make(map[string][]expressionAttribute)
+	for objID, attrs := range objAttrs {
+		exprAttrs[objID] = parseExpressionAttributes(kit, attrs)
+	}
+	return exprAttrs, nil
+}
+
+// expressionContext caches the data used when computing the expression fields in one request.
+type expressionContext struct {
+	// mainlineParent is the parent object of the mainline objects, keyed by child object id.
+	mainlineParent map[string]string
+	// mainlineChild is the child object of the mainline objects, keyed by parent object id.
+	mainlineChild map[string]string
+	// instances is the loaded parent instances, keyed by objID:instID.
+	instances map[string]mapstr.MapStr
+}
+
+func (m *instanceManager) newExpressionContext(kit *rest.Kit) (*expressionContext, error) {
+	cond := map[string]interface{}{common.AssociationKindIDField: common.AssociationKindMainline}
+	assts := make([]metadata.Association, 0)
+	if err := m.dbProxy.Table(common.BKTableNameObjAsst).Find(cond).All(kit.Ctx, &assts); err != nil {
+		blog.Errorf("get mainline associations failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	ctx := &expressionContext{
+		mainlineParent: make(map[string]string),
+		mainlineChild:  make(map[string]string),
+		instances:      make(map[string]mapstr.MapStr),
+	}
+	for _, asst := range assts {
+		ctx.mainlineParent[asst.ObjectID] = asst.AsstObjID
+		ctx.mainlineChild[asst.AsstObjID] = asst.ObjectID
+	}
+	return ctx, nil
+}
+
+// getMainlineParents get the instance's mainline parents which are referenced by the expressions, keyed by object id.
+func (m *instanceManager) getMainlineParents(kit *rest.Kit, ctx *expressionContext, objID string, inst mapstr.MapStr,
+	referenced map[string]bool) (map[string]interface{}, error) {
+
+	parents := make(map[string]interface{})
+	for depth := 0; depth < maxMainlineDepth && len(parents) < len(referenced); depth++ {
+		parentObjID, exists := ctx.mainlineParent[objID]
+		if !exists {
+			break
+		}
+		// host has no bk_parent_id, it can be in multiple modules, so it's expressions are not allowed to
+		// reference the parents when the attributes are saved.
+		parentID, err := util.GetInt64ByInterface(inst[common.BKInstParentStr])
+		if err != nil || parentID == 0 {
+			break
+		}
+
+		key := fmt.Sprintf("%s:%d", parentObjID, parentID)
+		parent, exists := ctx.instances[key]
+		if !exists {
+			parent, err = m.getInstDataByID(kit, parentObjID, uint64(parentID), m)
+			if err != nil {
+				blog.Errorf("get mainline parent %s failed, err: %v, rid: %s", key, err, kit.Rid)
+				return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+			}
+			ctx.instances[key] = parent
+		}
+
+		if referenced[parentObjID] {
+			parents[parentObjID] = parent
+		}
+		objID, inst = parentObjID, parent
+	}
+	return parents, nil
+}
+
+// computeExpressionFields compute the values of the expression fields of the instance.
+func (m *instanceManager) computeExpressionFields(kit *rest.Kit, ctx *expressionContext, objID string, attrs []expressionAttribute,
+	inst mapstr.MapStr) (mapstr.MapStr, error) {
+
+	bizID, err := FetchBizIDFromInstance(objID, inst)
+	if err != nil {
+		blog.Errorf("compute expression fields, but get instance biz id failed, inst: %+v, err: %v, rid: %s", inst, err, kit.Rid)
+		return nil, kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField)
+	}
+
+	// expressions can not reference the expression fields, their values are always null.
+	env := make(map[string]interface{}, len(inst))
+	for key, value := range inst {
+		env[key] = value
+	}
+	referenced := make(map[string]bool)
+	for _, attr := range attrs {
+		delete(env, attr.propertyID)
+		for _, parent := range attr.parents {
+			referenced[parent] = true
+		}
+	}
+
+	if len(referenced) > 0 {
+		parents, err := m.getMainlineParents(kit, ctx, objID, inst, referenced)
+		if err != nil {
+			return nil, err
+		}
+		for parentObjID, parent := range parents {
+			env[parentObjID] = parent
+		}
+	}
+
+	values := mapstr.New()
+	for _, attr := range attrs {
+		if attr.bizID != 0 && attr.bizID != bizID {
+			continue
+		}
+		value, err := attr.expr.Eval(env)
+		if err != nil {
+			blog.Errorf("evaluate expression of %s.%s failed, expression: %s, err: %v, rid: %s", objID, attr.propertyID, attr.expr, err, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommExpressionEvalFailed, attr.propertyID, err.Error())
+		}
+		values[attr.propertyID] = value
+	}
+	return values, nil
+}
+
+// fillExpressionFields compute the expression fields of the instance to be created and set them to the instance.
+func (m *instanceManager) fillExpressionFields(kit *rest.Kit, objID string, properties []metadata.Attribute, inst mapstr.MapStr) error {
+	attrs := parseExpressionAttributes(kit, properties)
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	ctx, err := m.newExpressionContext(kit)
+	if err != nil {
+		return err
+	}
+	values, err := m.computeExpressionFields(kit, ctx, objID, attrs, inst)
+	if err != nil {
+		return err
+	}
+	inst.Merge(values)
+	return nil
+}
+
+// RefreshExpressionFields recompute the expression fields of the instances matching the condition,
+// and the expression fields of their mainline descendants which reference the parents.
+func (m *instanceManager) RefreshExpressionFields(kit *rest.Kit, objID string, cond mapstr.MapStr) error {
+	return m.refreshExpressionFields(kit, objID, cond, true)
+}
+
+func (m *instanceManager) refreshExpressionFields(kit *rest.Kit, objID string, cond mapstr.MapStr, withEvent bool) error {
+	allAttrs, err := m.getExpressionAttributes(kit)
+	if err != nil {
+		return err
+	}
+	if len(allAttrs) == 0 {
+		return nil
+	}
+
+	ctx, err := m.newExpressionContext(kit)
+	if err != nil {
+		return err
+	}
+	return m.refreshObjectExpressionFields(kit, ctx, allAttrs, objID, cond, withEvent, 0)
+}
+
+func (m *instanceManager) refreshObjectExpressionFields(kit *rest.Kit, ctx *expressionContext, allAttrs map[string][]expressionAttribute,
+	objID string, cond mapstr.MapStr, withEvent bool, depth int) error {
+
+	if depth >= maxMainlineDepth {
+		return nil
+	}
+
+	attrs := allAttrs[objID]
+	// the descendants' expression fields need to be refreshed too if they reference the parents.
+	childObjID, cascade := ctx.mainlineChild[objID], false
+	for descendant, i := childObjID, 0; descendant != "" && i < maxMainlineDepth; descendant, i = ctx.mainlineChild[descendant], i+1 {
+		for _, attr := range allAttrs[descendant] {
+			if len(attr.parents) > 0 {
+				cascade = true
+			}
+		}
+	}
+	if len(attrs) == 0 && !cascade {
+		return nil
+	}
+
+	tableName := common.GetInstTableName(objID)
+	instIDField := common.GetInstIDField(objID)
+	filter := mapstr.New()
+	filter.Merge(cond)
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+	if tableName == common.BKTableNameBaseInst {
+		filter[common.BKObjIDField] = objID
+	}
+
+	eh := m.NewEventClient(objID)
+	changedIDs := make([]int64, 0)
+	for start := uint64(0); ; start += expressionRefreshPageSize {
+		insts := make([]mapstr.MapStr, 0)
+		err := m.dbProxy.Table(tableName).Find(filter).Sort(instIDField).Start(start).Limit(expressionRefreshPageSize).All(kit.Ctx, &insts)
+		if err != nil {
+			blog.Errorf("refresh expression fields, but get %s instances failed, cond: %+v, err: %v, rid: %s", objID, filter, err, kit.Rid)
+			return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		}
+
+		instIDs := make([]int64, 0)
+		for _, inst := range insts {
+			instID, err := util.GetInt64ByInterface(inst[instIDField])
+			if err != nil {
+				blog.Errorf("refresh expression fields, but get %s instance id failed, inst: %+v, err: %v, rid: %s", objID, inst, err, kit.Rid)
+				return kit.CCError.CCErrorf(common.CCErrCommInstFieldConvertFail, objID, instIDField, "int", err.Error())
+			}
+			instIDs = append(instIDs, instID)
+
+			if len(attrs) == 0 {
+				continue
+			}
+			values, err := m.computeExpressionFields(kit, ctx, objID, attrs, inst)
+			if err != nil {
+				return err
+			}
+			changed := mapstr.New()
+			for field, value := range values {
+				if old, exists := inst[field]; !exists || !reflect.DeepEqual(old, value) {
+					changed[field] = value
+				}
+			}
+			if len(changed) == 0 {
+				continue
+			}
+
+			if err := m.update(kit, objID, changed, mapstr.MapStr{instIDField: instID}); err != nil {
+				blog.Errorf("refresh expression fields, but update %s instance %d failed, data: %+v, err: %v, rid: %s", objID, instID, changed, err, kit.Rid)
+				return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+			}
+			eh.SetPreData(instID, inst)
+			changedIDs = append(changedIDs, instID)
+		}
+
+		if cascade && len(instIDs) > 0 {
+			childCond := mapstr.MapStr{common.BKInstParentStr: mapstr.MapStr{common.BKDBIN: instIDs}}
+			if err := m.refreshObjectExpressionFields(kit, ctx, allAttrs, childObjID, childCond, true, depth+1); err != nil {
+				return err
+			}
+		}
+
+		if len(insts) < expressionRefreshPageSize {
+			break
+		}
+	}
+
+	if !withEvent || len(changedIDs) == 0 {
+		return nil
+	}
+	eventCond := mapstr.MapStr{instIDField: mapstr.MapStr{common.BKDBIN: changedIDs}}
+	if err := eh.SetCurDataAndPush(kit, objID, metadata.EventActionUpdate, eventCond); err != nil {
+		blog.Errorf("refresh expression fields, but push %s instances event failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return err
+	}
+	return nil
+}
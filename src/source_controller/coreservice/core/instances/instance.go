@@ -64,6 +64,10 @@ func (m *instanceManager) CreateModelInstance(kit *rest.Kit, objID string, input
 		blog.Errorf("CreateModelInstance failed, valid error: %+v, rid: %s", err, rid)
 		return nil, err
 	}
+	if err := m.checkQuota(kit, objID, inputParam.Data); err != nil {
+		blog.Errorf("CreateModelInstance failed, check quota error: %+v, rid: %s", err, rid)
+		return nil, err
+	}
 	id, err := m.save(kit, objID, inputParam.Data)
 	if err != nil {
 		blog.ErrorJSON("CreateModelInstance create objID(%s) instance error. err:%s, data:%s, rid:%s", objID, err.Error(), inputParam.Data, kit.Rid)
@@ -96,6 +100,15 @@ func (m *instanceManager) CreateManyModelInstance(kit *rest.Kit, objID string, i
 			})
 			continue
 		}
+		if err := m.checkQuota(kit, objID, item); err != nil {
+			dataResult.Exceptions = append(dataResult.Exceptions, metadata.ExceptionResult{
+				Message:     err.Error(),
+				Code:        int64(err.GetCode()),
+				Data:        item,
+				OriginIndex: int64(itemIdx),
+			})
+			continue
+		}
 		item.Set(common.BKOwnerIDField, kit.SupplierAccount)
 		id, err := m.save(kit, objID, item)
 		if nil != err {
@@ -157,9 +170,11 @@ func (m *instanceManager) UpdateModelInstance(kit *rest.Kit, objID string, input
 		inputParam.Condition.Set(metadata.BKMetadata, instMedataData)
 	}
 
+	instIDs := make([]int64, 0, len(origins))
 	for _, origin := range origins {
 		instIDI := origin[instIDFieldName]
 		instID, _ := util.GetInt64ByInterface(instIDI)
+		instIDs = append(instIDs, instID)
 		err := m.validUpdateInstanceData(kit, objID, inputParam.Data, instMedataData, uint64(instID))
 		if nil != err {
 			blog.Errorf("update model instance validate error :%v ,rid:%s", err, kit.Rid)
@@ -174,6 +189,12 @@ func (m *instanceManager) UpdateModelInstance(kit *rest.Kit, objID string, input
 		blog.ErrorJSON("UpdateModelInstance update objID(%s) inst error. err:%s, condition:%s, rid:%s", objID, inputParam.Condition, kit.Rid)
 		return nil, err
 	}
+	// recompute the expression fields of the updated instances and their mainline descendants
+	err = m.refreshExpressionFields(kit, objID, mapstr.MapStr{instIDFieldName: mapstr.MapStr{common.BKDBIN: instIDs}}, false)
+	if err != nil {
+		blog.Errorf("UpdateModelInstance refresh objID(%s) expression fields failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, err
+	}
 	err = eh.SetCurDataAndPush(kit, objID, metadata.EventActionUpdate, inputParam.Condition)
 	if err != nil {
 		blog.ErrorJSON("UpdateModelInstance  event push instance current data error. err:%s, condition:%s, rid:%s", err, inputParam.Condition, kit.Rid)
@@ -200,17 +221,26 @@ func (m *instanceManager) SearchModelInstance(kit *rest.Kit, objID string, input
 	}
 	inputParam.Condition = util.SetQueryOwner(inputParam.Condition, kit.SupplierAccount)
 
+	// the instances are paged by the sort field and then the instance id, since the _id is not returned
+	instIDField := common.GetInstIDField(objID)
+	cond, err := inputParam.Page.CursorCondition(inputParam.Condition, instIDField)
+	if err != nil {
+		blog.Errorf("search instance with invalid page cursor, page: %+v, err: %v, rid: %s", inputParam.Page, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page.cursor")
+	}
+
 	instItems := make([]mapstr.MapStr, 0)
-	instErr := m.dbProxy.Table(tableName).Find(inputParam.Condition).Start(uint64(inputParam.Page.Start)).
+	instErr := m.dbProxy.Table(tableName).Find(cond).Start(uint64(inputParam.Page.Start)).
 		Limit(uint64(inputParam.Page.Limit)).
-		Sort(inputParam.Page.Sort).
-		Fields(inputParam.Fields...).
+		Sort(inputParam.Page.CursorSort(instIDField)).
+		Fields(inputParam.Page.CursorFields(inputParam.Fields, instIDField)...).
 		All(kit.Ctx, &instItems)
 	if instErr != nil {
 		blog.Errorf("search instance error [%v], rid: %s", instErr, kit.Rid)
 		return nil, instErr
 	}
 
+	// the count is the total of the instances matching the condition, not only the ones after the cursor
 	count, countErr := m.dbProxy.Table(tableName).Find(inputParam.Condition).Count(kit.Ctx)
 	if countErr != nil {
 		blog.Errorf("count instance error [%v], rid: %s", countErr, kit.Rid)
@@ -221,6 +251,14 @@ func (m *instanceManager) SearchModelInstance(kit *rest.Kit, objID string, input
 		Count: count,
 		Info:  instItems,
 	}
+	if len(instItems) > 0 {
+		dataResult.NextCursor, err = inputParam.Page.NextCursor(instIDField, len(instItems), instItems[len(instItems)-1])
+		if err != nil {
+			blog.Errorf("search instance, but generate next cursor failed, page: %+v, err: %v, rid: %s", inputParam.Page,
+				err, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page.sort")
+		}
+	}
 
 	return dataResult, nil
 }
@@ -253,6 +291,11 @@ func (m *instanceManager) DeleteModelInstance(kit *rest.Kit, objID string, input
 		}
 		eh.SetPreData(instID, origin)
 	}
+
+	if err := m.dependent.AddTrash(kit, objID, origins); err != nil {
+		blog.Errorf("DeleteModelInstance add objID(%s) instances to trash failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return &metadata.DeletedCount{}, err
+	}
 	err = m.dbProxy.Table(tableName).Delete(kit.Ctx, inputParam.Condition)
 	if nil != err {
 		blog.ErrorJSON("DeleteModelInstance delete objID(%s) instance error. err:%s, coniditon:%s, rid:%s", objID, err.Error(), inputParam.Condition, kit.Rid)
@@ -277,6 +320,12 @@ func (m *instanceManager) CascadeDeleteModelInstance(kit *rest.Kit, objID string
 		return &metadata.DeletedCount{}, err
 	}
 
+	// the associations are kept in the trash bin with the instances, so it must be done before they are deleted.
+	if err := m.dependent.AddTrash(kit, objID, origins); err != nil {
+		blog.Errorf("cascade delete model instance add objID(%s) instances to trash failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return &metadata.DeletedCount{}, err
+	}
+
 	for _, origin := range origins {
 		instID, err := util.GetInt64ByInterface(origin[instIDFieldName])
 		if nil != err {
@@ -38,6 +38,9 @@ func (m *instanceManager) save(kit *rest.Kit, objID string, inputParam mapstr.Ma
 	inputParam.Set(common.BKOwnerIDField, kit.SupplierAccount)
 	inputParam.Set(common.CreateTimeField, ts)
 	inputParam.Set(common.LastTimeField, ts)
+	if objID == common.BKInnerObjIDHost {
+		util.SetHostIPNormalizedFields(inputParam)
+	}
 	err = m.dbProxy.Table(tableName).Insert(kit.Ctx, inputParam)
 	return id, err
 }
@@ -50,6 +53,9 @@ func (m *instanceManager) update(kit *rest.Kit, objID string, data mapstr.MapStr
 	ts := time.Now()
 	data.Set(common.LastTimeField, ts)
 	data.Remove(common.BKObjIDField)
+	if objID == common.BKInnerObjIDHost {
+		util.SetHostIPNormalizedFields(data)
+	}
 	return m.dbProxy.Table(tableName).Update(kit.Ctx, cond, data)
 }
 
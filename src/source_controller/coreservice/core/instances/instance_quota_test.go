@@ -0,0 +1,78 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instances_test
+
+import (
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateManyInstanceQuota(t *testing.T) {
+	dependent := newSwitchDependences()
+	dependent.quotas = map[metadata.QuotaResource]int64{metadata.QuotaResourceInstance: 2}
+	instMgr := newInstancesWithDependences(t, dependent)
+	kit := *defaultKit
+	kit.SupplierAccount = xid.New().String()
+
+	inputParams := metadata.CreateManyModelInstance{}
+	for i := 0; i < 3; i++ {
+		inputParams.Datas = append(inputParams.Datas, mapstr.MapStr{
+			common.BKInstNameField: xid.New().String(),
+			common.BKAssetIDField:  xid.New().String(),
+		})
+	}
+
+	// each instance of the batch is counted in the quota
+	dataResult, err := instMgr.CreateManyModelInstance(&kit, "bk_switch", inputParams)
+	require.NoError(t, err)
+	require.Len(t, dataResult.Created, 2)
+	require.Len(t, dataResult.Exceptions, 1)
+	require.Equal(t, int64(common.CCErrCommQuotaExceeded), dataResult.Exceptions[0].Code)
+	require.Equal(t, int64(2), dataResult.Exceptions[0].OriginIndex)
+	require.Equal(t, int64(0), dependent.quotas[metadata.QuotaResourceInstance])
+
+	_, err = instMgr.CreateModelInstance(&kit, "bk_switch", metadata.CreateModelInstance{Data: inputParams.Datas[2]})
+	require.Error(t, err)
+	require.Equal(t, common.CCErrCommQuotaExceeded, err.(errors.CCErrorCoder).GetCode())
+}
+
+func TestRestoreInstanceQuota(t *testing.T) {
+	dependent := newSwitchDependences()
+	dependent.quotas = map[metadata.QuotaResource]int64{metadata.QuotaResourceInstance: 0}
+	instMgr := newInstancesWithDependences(t, dependent)
+	kit := *defaultKit
+	kit.SupplierAccount = xid.New().String()
+
+	item := metadata.TrashItem{ID: 1, InstID: time.Now().UnixNano(), Detail: mapstr.MapStr{
+		common.BKObjIDField:    "bk_switch",
+		common.BKInstNameField: xid.New().String(),
+		common.BKAssetIDField:  xid.New().String(),
+	}}
+
+	// the restored instance is counted in the quota like a created one
+	err := instMgr.RestoreModelInstance(&kit, "bk_switch", item)
+	require.Error(t, err)
+	require.Equal(t, common.CCErrCommQuotaExceeded, err.(errors.CCErrorCoder).GetCode())
+
+	dependent.quotas[metadata.QuotaResourceInstance] = 1
+	require.NoError(t, instMgr.RestoreModelInstance(&kit, "bk_switch", item))
+	require.Equal(t, int64(0), dependent.quotas[metadata.QuotaResourceInstance])
+}
@@ -32,7 +32,7 @@ func TestCreateOneInstance(t *testing.T) {
 	inputParams.Data.Set(common.BKInstNameField, xid.New().String())
 
 	// create a new bk_switch instance without bk_asset_id
-	dataResult, err := instMgr.CreateModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err := instMgr.CreateModelInstance(defaultKit, objID, inputParams)
 	require.NotNil(t, err)
 	require.NotNil(t, dataResult)
 	require.Equal(t, uint64(0), dataResult.Created.ID)
@@ -43,7 +43,7 @@ func TestCreateOneInstance(t *testing.T) {
 	// create a valid model  instance with valid params
 	inputParams.Data.Set(common.BKAssetIDField, xid.New().String())
 	inputParams.Data.Set("bk_sn", "cmdb_sn")
-	dataResult, err = instMgr.CreateModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err = instMgr.CreateModelInstance(defaultKit, objID, inputParams)
 	require.Nil(t, err)
 	require.NotEqual(t, uint64(0), dataResult.Created.ID)
 
@@ -75,7 +75,7 @@ func TestCreateManyInstance(t *testing.T) {
 	})
 
 	// create two new bk_switch instance without bk_asset_id
-	dataResult, err := instMgr.CreateManyModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err := instMgr.CreateManyModelInstance(defaultKit, objID, inputParams)
 
 	require.Nil(t, err)
 	require.NotNil(t, dataResult)
@@ -93,7 +93,7 @@ func TestUpdateOneInstance(t *testing.T) {
 	inputParams.Data.Set(common.BKInstNameField, xid.New().String())
 	inputParams.Data.Set(common.BKAssetIDField, xid.New().String())
 	inputParams.Data.Set("bk_sn", "cmdb_sn")
-	dataResult, err := instMgr.CreateModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err := instMgr.CreateModelInstance(defaultKit, objID, inputParams)
 	require.Nil(t, err)
 	require.NotNil(t, dataResult)
 	require.Equal(t, uint64(0), dataResult.Created.ID)
@@ -102,7 +102,7 @@ func TestUpdateOneInstance(t *testing.T) {
 	updateParams := metadata.UpdateOption{}
 	updateParams.Condition = mapstr.MapStr{"bk_sn": "cmdb_sn"}
 	updateParams.Data = mapstr.MapStr{"bk_operator": "test"}
-	updateResult, err := instMgr.UpdateModelInstance(defaultCtx, objID, updateParams)
+	updateResult, err := instMgr.UpdateModelInstance(defaultKit, objID, updateParams)
 
 	require.Nil(t, err)
 	require.NotNil(t, updateResult)
@@ -119,7 +119,7 @@ func TestSearchAndDeleteInstance(t *testing.T) {
 	inputParams.Data.Set(common.BKInstNameField, "test_sw1")
 	inputParams.Data.Set(common.BKAssetIDField, "test_sw_001")
 	inputParams.Data.Set("bk_sn", "cmdb_sn")
-	dataResult, err := instMgr.CreateModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err := instMgr.CreateModelInstance(defaultKit, objID, inputParams)
 	require.Nil(t, err)
 	require.NotNil(t, dataResult)
 	require.Equal(t, uint64(0), dataResult.Created.ID)
@@ -127,7 +127,7 @@ func TestSearchAndDeleteInstance(t *testing.T) {
 	//search  this instance
 	searchCond := metadata.QueryCondition{}
 	searchCond.Condition.Set("bk_sn", "cmdb_sn")
-	searchResult, err := instMgr.SearchModelInstance(defaultCtx, objID, searchCond)
+	searchResult, err := instMgr.SearchModelInstance(defaultKit, objID, searchCond)
 	require.Nil(t, err)
 	require.NotNil(t, searchResult)
 	require.NotEqual(t, uint64(0), searchResult.Count)
@@ -136,7 +136,7 @@ func TestSearchAndDeleteInstance(t *testing.T) {
 	//delete   this instance
 	deleteCond := metadata.DeleteOption{}
 	deleteCond.Condition.Set("bk_sn", "cmdb_sn")
-	deleteResult, err := instMgr.DeleteModelInstance(defaultCtx, objID, deleteCond)
+	deleteResult, err := instMgr.DeleteModelInstance(defaultKit, objID, deleteCond)
 	require.Nil(t, err)
 	require.NotNil(t, deleteResult)
 	require.NotEqual(t, uint64(0), deleteResult.Count)
@@ -151,7 +151,7 @@ func TestCascadeDeleteInstance(t *testing.T) {
 	inputParams.Data.Set(common.BKInstNameField, xid.New().String())
 	inputParams.Data.Set(common.BKAssetIDField, xid.New().String())
 	inputParams.Data.Set("bk_sn", "cmdb_sn")
-	dataResult, err := instMgr.CreateModelInstance(defaultCtx, objID, inputParams)
+	dataResult, err := instMgr.CreateModelInstance(defaultKit, objID, inputParams)
 	require.Nil(t, err)
 	require.NotNil(t, dataResult)
 	require.Equal(t, uint64(0), dataResult.Created.ID)
@@ -159,7 +159,7 @@ func TestCascadeDeleteInstance(t *testing.T) {
 	//delete   this instance
 	deleteCond := metadata.DeleteOption{}
 	deleteCond.Condition.Set("bk_sn", "cmdb_sn")
-	deleteResult, err := instMgr.CascadeDeleteModelInstance(defaultCtx, objID, deleteCond)
+	deleteResult, err := instMgr.CascadeDeleteModelInstance(defaultKit, objID, deleteCond)
 	require.Nil(t, err)
 	require.NotNil(t, deleteResult)
 	require.NotEqual(t, uint64(0), deleteResult.Count)
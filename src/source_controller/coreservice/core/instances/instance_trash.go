@@ -0,0 +1,91 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instances
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// RestoreModelInstance restore the soft deleted instance with it's original id, the unique rules are validated
+// again since other instances may have taken the unique values after it's deleted.
+func (m *instanceManager) RestoreModelInstance(kit *rest.Kit, objID string, item metadata.TrashItem) error {
+	if len(item.Detail) == 0 {
+		blog.Errorf("RestoreModelInstance failed, trash item %d has no instance detail, rid: %s", item.ID, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "detail")
+	}
+
+	instIDField := common.GetInstIDField(objID)
+	_, exists, err := m.instCnt(kit, objID, mapstr.MapStr{instIDField: item.InstID})
+	if err != nil {
+		blog.Errorf("RestoreModelInstance failed, count instance %d failed, err: %v, rid: %s", item.InstID, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if exists {
+		blog.Errorf("RestoreModelInstance failed, %s instance %d already exists, rid: %s", objID, item.InstID, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, instIDField)
+	}
+
+	instance := item.Detail
+	instance.Remove("_id")
+	bizID, err := FetchBizIDFromInstance(objID, instance)
+	if err != nil {
+		blog.Errorf("RestoreModelInstance failed, FetchBizIDFromInstance failed, err: %+v, rid: %s", err, kit.Rid)
+		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField)
+	}
+	if err := m.validBizID(kit, bizID); err != nil {
+		blog.Errorf("RestoreModelInstance failed, the business %d of the instance is invalid, err: %v, rid: %s", bizID, err, kit.Rid)
+		return err
+	}
+
+	valid, err := NewValidator(kit, m.dependent, objID, bizID, m.language)
+	if err != nil {
+		blog.Errorf("init validator failed %s, rid: %s", err.Error(), kit.Rid)
+		return err
+	}
+	instMetadata := metadata.Metadata{Label: make(metadata.Label)}
+	if bizID := metadata.GetBusinessIDFromMeta(instance[metadata.BKMetadata]); bizID != "" {
+		instMetadata.Label.Set(metadata.LabelBusinessID, bizID)
+	}
+	if err := valid.validCreateUnique(kit, instance, instMetadata, m); err != nil {
+		blog.Errorf("RestoreModelInstance failed, %s instance %d is not unique, err: %v, rid: %s", objID, item.InstID, err, kit.Rid)
+		return err
+	}
+
+	// the restored instance is counted in the quota like a created one
+	if err := m.checkQuota(kit, objID, instance); err != nil {
+		blog.Errorf("RestoreModelInstance failed, check %s quota failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return err
+	}
+
+	instance[instIDField] = item.InstID
+	instance.Set(common.BKOwnerIDField, kit.SupplierAccount)
+	instance.Set(common.LastTimeField, time.Now())
+	if err := m.dbProxy.Table(common.GetInstTableName(objID)).Insert(kit.Ctx, instance); err != nil {
+		blog.ErrorJSON("RestoreModelInstance insert objID(%s) instance error. err:%s, data:%s, rid:%s", objID, err, instance, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+
+	eh := m.NewEventClient(objID)
+	err = eh.SetCurDataAndPush(kit, objID, metadata.EventActionCreate, mapstr.MapStr{instIDField: item.InstID})
+	if err != nil {
+		blog.ErrorJSON("RestoreModelInstance event push instance current data error. err:%s, objID:%s inst id:%s, rid:%s", err, objID, item.InstID, kit.Rid)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instances_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/require"
+)
+
+// newSwitchDependences returns the dependences of the bk_switch whose bk_asset_id is unique.
+func newSwitchDependences() *mockDependences {
+	return &mockDependences{
+		attributes: []metadata.Attribute{
+			{ID: 1, ObjectID: "bk_switch", PropertyID: common.BKInstNameField, PropertyType: common.FieldTypeSingleChar},
+			{ID: 2, ObjectID: "bk_switch", PropertyID: common.BKAssetIDField, PropertyType: common.FieldTypeSingleChar},
+		},
+		uniques: []metadata.ObjectUnique{
+			{ID: 1, ObjID: "bk_switch", MustCheck: true, Keys: []metadata.UniqueKey{
+				{Kind: metadata.UniqueKeyKindProperty, ID: 2},
+			}},
+		},
+	}
+}
+
+func TestRestoreModelInstance(t *testing.T) {
+	instMgr := newInstancesWithDependences(t, newSwitchDependences())
+	objID := "bk_switch"
+	kit := *defaultKit
+	kit.SupplierAccount = xid.New().String()
+	kit.Header = http.Header{}
+	kit.Header.Set(common.BKHTTPLanguage, "en")
+
+	inputParams := metadata.CreateModelInstance{Data: mapstr.MapStr{
+		common.BKInstNameField: xid.New().String(),
+		common.BKAssetIDField:  xid.New().String(),
+	}}
+	dataResult, err := instMgr.CreateModelInstance(&kit, objID, inputParams)
+	require.NoError(t, err)
+	existID := int64(dataResult.Created.ID)
+
+	restoredID := time.Now().UnixNano()
+	testCases := []struct {
+		name   string
+		item   metadata.TrashItem
+		errArg string
+	}{
+		{
+			name:   "detail is empty",
+			item:   metadata.TrashItem{ID: 1, InstID: restoredID},
+			errArg: "",
+		},
+		{
+			name: "instance id already exists",
+			item: metadata.TrashItem{ID: 2, InstID: existID, Detail: mapstr.MapStr{
+				common.BKObjIDField:    objID,
+				common.BKInstNameField: xid.New().String(),
+				common.BKAssetIDField:  xid.New().String(),
+			}},
+			errArg: common.BKInstIDField,
+		},
+		{
+			name: "unique value is taken by the other instance",
+			item: metadata.TrashItem{ID: 3, InstID: restoredID, Detail: mapstr.MapStr{
+				common.BKObjIDField:    objID,
+				common.BKInstNameField: xid.New().String(),
+				common.BKAssetIDField:  inputParams.Data[common.BKAssetIDField],
+			}},
+			errArg: "asset id",
+		},
+	}
+	for _, testCase := range testCases {
+		err := instMgr.RestoreModelInstance(&kit, objID, testCase.item)
+		require.Error(t, err, testCase.name)
+		ccErr, ok := err.(errors.CCErrorCoder)
+		require.True(t, ok, testCase.name)
+		if testCase.errArg == "" {
+			require.Equal(t, common.CCErrCommParamsInvalid, ccErr.GetCode(), testCase.name)
+			continue
+		}
+		require.Equal(t, common.CCErrCommDuplicateItem, ccErr.GetCode(), testCase.name)
+		require.Contains(t, ccErr.Error(), testCase.errArg, testCase.name)
+	}
+
+	// the instance is restored with its original id
+	assetID := xid.New().String()
+	item := metadata.TrashItem{ID: 4, InstID: restoredID, Detail: mapstr.MapStr{
+		"_id":                  "5e8c0a6b2a6a4a0001000000",
+		common.BKObjIDField:    objID,
+		common.BKInstIDField:   restoredID,
+		common.BKInstNameField: xid.New().String(),
+		common.BKAssetIDField:  assetID,
+	}}
+	require.NoError(t, instMgr.RestoreModelInstance(&kit, objID, item))
+
+	searchCond := metadata.QueryCondition{Condition: mapstr.MapStr{common.BKAssetIDField: assetID}}
+	searchResult, err := instMgr.SearchModelInstance(&kit, objID, searchCond)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), searchResult.Count)
+	instID, err := searchResult.Info[0].Int64(common.BKInstIDField)
+	require.NoError(t, err)
+	require.Equal(t, restoredID, instID)
+
+	// the restored instance can't be restored again
+	err = instMgr.RestoreModelInstance(&kit, objID, item)
+	require.Error(t, err)
+	require.Equal(t, common.CCErrCommDuplicateItem, err.(errors.CCErrorCoder).GetCode())
+}
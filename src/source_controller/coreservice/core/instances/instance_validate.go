@@ -24,6 +24,7 @@ import (
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
+	hostutil "configcenter/src/source_controller/coreservice/core/host/util"
 	"configcenter/src/storage/dal"
 )
 
@@ -91,6 +92,20 @@ func (m *instanceManager) validBizID(kit *rest.Kit, bizID int64) error {
 	return nil
 }
 
+// checkQuota check if the quota of the object's instances is enough for the instance to be created
+func (m *instanceManager) checkQuota(kit *rest.Kit, objID string, instanceData mapstr.MapStr) errors.CCErrorCoder {
+	resource, limited := metadata.GetQuotaResource(objID)
+	if !limited {
+		return nil
+	}
+	bizID, err := FetchBizIDFromInstance(objID, instanceData)
+	if err != nil {
+		blog.Errorf("checkQuota failed, FetchBizIDFromInstance failed, err: %+v, rid: %s", err, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField)
+	}
+	return m.dependent.CheckQuota(kit, resource, bizID, 1)
+}
+
 func (m *instanceManager) validCreateInstanceData(kit *rest.Kit, objID string, instanceData mapstr.MapStr) error {
 	bizID, err := FetchBizIDFromInstance(objID, instanceData)
 	if err != nil {
@@ -144,6 +159,11 @@ func (m *instanceManager) validCreateInstanceData(kit *rest.Kit, objID string, i
 			// blog.Errorf("field [%s] is not a valid property for model [%s], rid: %s", key, objID, kit.Rid)
 			// return valid.errif.CCErrorf(common.CCErrCommParamsIsInvalid, key)
 		}
+		if property.PropertyType == common.FieldTypeExpression {
+			// expression field is computed by the server, it's value can not be set by the clients
+			delete(instanceData, key)
+			continue
+		}
 		if value, ok := val.(string); ok {
 			val = strings.TrimSpace(value)
 			instanceData[key] = val
@@ -159,6 +179,11 @@ func (m *instanceManager) validCreateInstanceData(kit *rest.Kit, objID string, i
 		instanceData.Set(metadata.BKMetadata, instMedataData)
 	}
 
+	if err := m.fillExpressionFields(kit, objID, valid.propertySlice, instanceData); err != nil {
+		blog.Errorf("fill expression fields failed, objID: %s, err: %v, rid: %s", objID, err, kit.Rid)
+		return err
+	}
+
 	// module instance's name must coincide with template
 	if objID == common.BKInnerObjIDModule {
 		if err := m.validateModuleCreate(kit, instanceData, valid); err != nil {
@@ -168,6 +193,16 @@ func (m *instanceManager) validCreateInstanceData(kit *rest.Kit, objID string, i
 			return err
 		}
 	}
+
+	if objID == common.BKInnerObjIDHost {
+		cloudID := int64(common.BKDefaultDirSubArea)
+		if instanceData.Exists(common.BKCloudIDField) {
+			cloudID, _ = util.GetInt64ByInterface(instanceData[common.BKCloudIDField])
+		}
+		if err := hostutil.ValidateCloudAreaHostLimit(kit, m.dbProxy, cloudID, 1); err != nil {
+			return err
+		}
+	}
 	return valid.validCreateUnique(kit, instanceData, instMedataData, m)
 }
 
@@ -276,7 +311,7 @@ func (m *instanceManager) validUpdateInstanceData(kit *rest.Kit, objID string, i
 		}
 
 		property, ok := valid.properties[key]
-		if !ok {
+		if !ok || property.PropertyType == common.FieldTypeExpression {
 			delete(instanceData, key)
 			continue
 		}
@@ -290,6 +325,17 @@ func (m *instanceManager) validUpdateInstanceData(kit *rest.Kit, objID string, i
 		}
 	}
 
+	// the host moved to another cloud area must not exceed the host limit of that cloud area
+	if objID == common.BKInnerObjIDHost && instanceData.Exists(common.BKCloudIDField) {
+		newCloudID, _ := util.GetInt64ByInterface(instanceData[common.BKCloudIDField])
+		oldCloudID, _ := util.GetInt64ByInterface(updateData[common.BKCloudIDField])
+		if newCloudID != oldCloudID {
+			if err := hostutil.ValidateCloudAreaHostLimit(kit, m.dbProxy, newCloudID, 1); err != nil {
+				return err
+			}
+		}
+	}
+
 	for key, val := range instanceData {
 		updateData[key] = val
 	}
@@ -14,59 +14,104 @@ package instances_test
 
 import (
 	"context"
+	"net/http"
+	"os"
 	"testing"
 	"time"
 
+	"configcenter/src/common"
 	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
 	"configcenter/src/common/language"
+	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/source_controller/coreservice/core/instances"
-	"configcenter/src/storage/dal/mongo"
 	"configcenter/src/storage/dal/mongo/local"
 
 	"github.com/stretchr/testify/require"
 )
 
 type mockDependences struct {
+	attributes []metadata.Attribute
+	uniques    []metadata.ObjectUnique
+	// quotas is the remaining quota of the resources, the resources not in it are not limited
+	quotas map[metadata.QuotaResource]int64
 }
 
 // IsInstanceExist used to check if the  instances  asst exist
-func (s *mockDependences) IsInstAsstExist(ctx core.ContextParams, objID string, instID uint64) (exists bool, err error) {
+func (s *mockDependences) IsInstAsstExist(kit *rest.Kit, objID string, instID uint64) (exists bool, err error) {
 	return false, nil
 }
 
 // DeleteInstAsst used to delete inst asst
-func (s *mockDependences) DeleteInstAsst(ctx core.ContextParams, objID string, instID uint64) error {
+func (s *mockDependences) DeleteInstAsst(kit *rest.Kit, objID string, instID uint64) error {
 	return nil
 }
 
 // SelectObjectAttWithParams select object att with params
-func (s *mockDependences) SelectObjectAttWithParams(ctx core.ContextParams, objID string) (attribute []metadata.Attribute, err error) {
-	return nil, nil
+func (s *mockDependences) SelectObjectAttWithParams(kit *rest.Kit, objID string, bizID int64) (attribute []metadata.Attribute, err error) {
+	return s.attributes, nil
 }
 
 // SearchUnique search unique attribute
-func (s *mockDependences) SearchUnique(ctx core.ContextParams, objID string) (uniqueAttr []metadata.ObjectUnique, err error) {
-	return nil, nil
+func (s *mockDependences) SearchUnique(kit *rest.Kit, objID string) (uniqueAttr []metadata.ObjectUnique, err error) {
+	return s.uniques, nil
 }
 
+// AddTrash save the instances to the trash bin before they are deleted
+func (s *mockDependences) AddTrash(kit *rest.Kit, objID string, instances []mapstr.MapStr) errors.CCErrorCoder {
+	return nil
+}
+
+// CheckQuota check if the quota of the resource is enough
+func (s *mockDependences) CheckQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64, increment int64) errors.CCErrorCoder {
+	remaining, limited := s.quotas[resource]
+	if !limited {
+		return nil
+	}
+	if remaining < increment {
+		return kit.CCError.CCErrorf(common.CCErrCommQuotaExceeded, resource, kit.SupplierAccount, remaining, 0)
+	}
+	s.quotas[resource] = remaining - increment
+	return nil
+}
+
+// newInstances returns the instance operation with the mongodb of MONGOURI and MONGORS, the test is skipped
+// if the mongodb is not set.
 func newInstances(t *testing.T) core.InstanceOperation {
+	return newInstancesWithDependences(t, &mockDependences{})
+}
 
-	db, err := local.NewMgo("mongodb://cc:cc@localhost:27010,localhost:27011,localhost:27012,localhost:27013/cmdb", time.Minute)
+// newInstancesWithDependences returns the instance operation which depends on the given dependences.
+func newInstancesWithDependences(t *testing.T, dependent instances.OperationDependences) core.InstanceOperation {
+	uri := os.Getenv("MONGOURI")
+	if uri == "" {
+		t.Skip("MONGOURI is not set, skip the test which needs mongodb")
+	}
+	db, err := local.NewMgo(local.MongoConf{
+		MaxOpenConns: 100,
+		MaxIdleConns: 10,
+		URI:          uri,
+		RsName:       os.Getenv("MONGORS"),
+	}, time.Minute)
 	require.NoError(t, err)
-	return instances.New(db, &mockDependences{})
+	return instances.New(db, dependent, nil, defaultLanguage)
 }
 
-var defaultCtx = func() core.ContextParams {
-	err, _ := errors.New("../../../../../resources/errors/")
+var defaultLanguage = func() language.CCLanguageIf {
 	lan, _ := language.New("../../../../../resources/language/")
-	return core.ContextParams{
-		Context:         context.Background(),
-		ReqID:           "test_req_id",
-		SupplierAccount: "test_owner",
+	return lan
+}()
+
+var defaultKit = func() *rest.Kit {
+	errIf, _ := errors.NewFactory("../../../../../resources/errors/")
+	return &rest.Kit{
+		Rid:             "test_req_id",
+		Header:          http.Header{},
+		Ctx:             context.Background(),
+		CCError:         errIf.CreateDefaultCCErrorIf("en"),
 		User:            "test_user",
-		Error:           err.CreateDefaultCCErrorIf("en"),
-		Lang:            lan.CreateDefaultCCLanguageIf("en"),
+		SupplierAccount: "test_owner",
 	}
 }()
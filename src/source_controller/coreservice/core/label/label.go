@@ -35,13 +35,30 @@ func New(dbProxy dal.RDB) core.LabelOperation {
 	return labelOps
 }
 
+// instIDField get the id field of the table's instances, tables of the inner objects are mapped to the object first
+func instIDField(tableName string) string {
+	switch tableName {
+	case common.BKTableNameBaseApp:
+		return common.BKAppIDField
+	case common.BKTableNameBaseSet:
+		return common.BKSetIDField
+	case common.BKTableNameBaseModule:
+		return common.BKModuleIDField
+	case common.BKTableNameBaseHost:
+		return common.BKHostIDField
+	case common.BKTableNameBaseInst:
+		return common.BKInstIDField
+	}
+	return common.GetInstIDField(tableName)
+}
+
 func (p *labelOperation) AddLabel(kit *rest.Kit, tableName string, option selector.LabelAddOption) errors.CCErrorCoder {
 	if field, err := option.Labels.Validate(); err != nil {
 		blog.Infof("addLabel failed, validate failed, field:%s, err: %+v, rid: %s", field, err, kit.Rid)
 		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "label."+field)
 	}
 
-	idField := common.GetInstIDField(tableName)
+	idField := instIDField(tableName)
 
 	// check all instance validate
 	option.InstanceIDs = util.IntArrayUnique(option.InstanceIDs)
@@ -81,7 +98,7 @@ func (p *labelOperation) AddLabel(kit *rest.Kit, tableName string, option select
 }
 
 func (p *labelOperation) RemoveLabel(kit *rest.Kit, tableName string, option selector.LabelRemoveOption) errors.CCErrorCoder {
-	idField := common.GetInstIDField(tableName)
+	idField := instIDField(tableName)
 
 	// check all instance validate
 	option.InstanceIDs = util.IntArrayUnique(option.InstanceIDs)
@@ -119,3 +136,26 @@ func (p *labelOperation) RemoveLabel(kit *rest.Kit, tableName string, option sel
 	}
 	return nil
 }
+
+func (p *labelOperation) AggregateLabels(kit *rest.Kit, tableName string, condition map[string]interface{}) (selector.LabelAggregation, errors.CCErrorCoder) {
+	if condition == nil {
+		condition = make(map[string]interface{})
+	}
+	condition = util.SetQueryOwner(condition, kit.SupplierAccount)
+	condition[selector.LabelsField] = map[string]interface{}{
+		common.BKDBExists: true,
+	}
+
+	instances := make([]selector.LabelInstance, 0)
+	if err := p.dbProxy.Table(tableName).Find(condition).Fields(selector.LabelsField).All(kit.Ctx, &instances); err != nil {
+		blog.ErrorJSON("AggregateLabels failed, db find instances failed, table: %s, filter: %s, err: %s, rid: %s", tableName, condition, err.Error(), kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommDBSelectFailed)
+	}
+
+	aggregation := make(selector.LabelAggregation)
+	for _, instance := range instances {
+		aggregation.Aggregate(instance.Labels)
+	}
+	aggregation.Sort()
+	return aggregation, nil
+}
@@ -103,6 +103,15 @@ func (m *modelAttribute) CreateModelAttributes(kit *rest.Kit, objID string, inpu
 			})
 			continue
 		}
+
+		if !attr.IsPre {
+			bizID, _ := metadata.BizIDFromMetadata(attr.Metadata)
+			if err := m.model.dependent.CheckQuota(kit, metadata.QuotaResourceAttribute, bizID, 1); err != nil {
+				addExceptionFunc(int64(attrIdx), err, &attr)
+				continue
+			}
+		}
+
 		id, err := m.save(kit, attr)
 		if nil != err {
 			blog.Errorf("CreateModelAttributes failed, failed to save the attribute(%#v), err: %s, rid: %s", attr, err.Error(), kit.Rid)
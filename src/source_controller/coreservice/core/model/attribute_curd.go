@@ -86,6 +86,12 @@ func (m *modelAttribute) save(kit *rest.Kit, attribute metadata.Attribute) (id u
 		attribute.LastTime.Time = time.Now()
 	}
 
+	// expression attribute's value is computed by the server, it can not be edited or required
+	if attribute.PropertyType == common.FieldTypeExpression {
+		attribute.IsEditable = false
+		attribute.IsRequired = false
+	}
+
 	if err = m.saveCheck(kit, attribute); err != nil {
 		return 0, err
 	}
@@ -204,7 +210,8 @@ func (m *modelAttribute) checkAttributeValidity(kit *rest.Kit, attribute metadat
 	if attribute.PropertyType != "" {
 		switch attribute.PropertyType {
 		case common.FieldTypeSingleChar, common.FieldTypeLongChar, common.FieldTypeInt, common.FieldTypeFloat, common.FieldTypeEnum,
-			common.FieldTypeDate, common.FieldTypeTime, common.FieldTypeUser, common.FieldTypeOrganization, common.FieldTypeTimeZone, common.FieldTypeBool, common.FieldTypeList:
+			common.FieldTypeDate, common.FieldTypeTime, common.FieldTypeUser, common.FieldTypeOrganization, common.FieldTypeTimeZone, common.FieldTypeBool, common.FieldTypeList,
+			common.FieldTypeExpression:
 		default:
 			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, metadata.AttributeFieldPropertyType)
 		}
@@ -572,6 +579,24 @@ func (m *modelAttribute) saveCheck(kit *rest.Kit, attribute metadata.Attribute)
 		return err
 	}
 
+	if attribute.PropertyType == common.FieldTypeExpression {
+		if err := util.ValidPropertyOption(attribute.PropertyType, attribute.Option, kit.CCError); err != nil {
+			blog.ErrorJSON("save attribute, but expression is invalid, err: %s, input: %s, rid: %s", err, attribute, kit.Rid)
+			return err
+		}
+		if err := util.ValidExpressionObjectReferences(attribute.ObjectID, attribute.Option, kit.CCError); err != nil {
+			blog.ErrorJSON("save attribute, but expression references unreachable fields, err: %s, input: %s, rid: %s", err, attribute, kit.Rid)
+			return err
+		}
+	}
+
+	if attribute.Permission != nil {
+		if err := attribute.Permission.Validate(); err != nil {
+			blog.ErrorJSON("save attribute, but permission is invalid, err: %s, input: %s, rid: %s", err.Error(), attribute, kit.Rid)
+			return kit.CCError.Errorf(common.CCErrCommParamsInvalid, metadata.AttributeFieldPermission)
+		}
+	}
+
 	// check name duplicate
 	if err := m.checkUnique(kit, true, attribute.ObjectID, attribute.PropertyID, attribute.PropertyName, attribute.Metadata); err != nil {
 		blog.ErrorJSON("save attribute check unique err:%s, input:%s, rid:%s", err.Error(), attribute, kit.Rid)
@@ -603,7 +628,7 @@ func (m *modelAttribute) checkUpdate(kit *rest.Kit, data mapstr.MapStr, cond uni
 		}
 	}
 
-	// 预定义字段，只能更新分组、分组内排序、名称、单位、提示语和option
+	// 预定义字段，只能更新分组、分组内排序、名称、单位、提示语、option和字段权限
 	if hasIsPreProperty {
 		_ = data.ForEach(func(key string, val interface{}) error {
 			if key != metadata.AttributeFieldPropertyGroup &&
@@ -611,7 +636,8 @@ func (m *modelAttribute) checkUpdate(kit *rest.Kit, data mapstr.MapStr, cond uni
 				key != metadata.AttributeFieldPropertyName &&
 				key != metadata.AttributeFieldUnit &&
 				key != metadata.AttributeFieldPlaceHolder &&
-				key != metadata.AttributeFieldOption {
+				key != metadata.AttributeFieldOption &&
+				key != metadata.AttributeFieldPermission {
 				data.Remove(key)
 			}
 			return nil
@@ -630,6 +656,37 @@ func (m *modelAttribute) checkUpdate(kit *rest.Kit, data mapstr.MapStr, cond uni
 			blog.ErrorJSON("valid property option failed, err: %s, data: %s, rid:%s", err, data, kit.Ctx)
 			return changeRow, err
 		}
+		if propertyType == common.FieldTypeExpression {
+			for _, dbAttribute := range dbAttributeArr {
+				if err := util.ValidExpressionObjectReferences(dbAttribute.ObjectID, option, kit.CCError); err != nil {
+					blog.ErrorJSON("update option, but expression references unreachable fields, err: %s, data: %s, rid: %s", err, data, kit.Rid)
+					return changeRow, err
+				}
+			}
+		}
+	}
+
+	// expression attribute's value is computed by the server, it can not be edited or required
+	if dbAttributeArr[0].PropertyType == common.FieldTypeExpression {
+		if data.Exists(metadata.AttributeFieldIsEditable) {
+			data.Set(metadata.AttributeFieldIsEditable, false)
+		}
+		if data.Exists(metadata.AttributeFieldIsRequired) {
+			data.Set(metadata.AttributeFieldIsRequired, false)
+		}
+	}
+
+	if permission, exists := data.Get(metadata.AttributeFieldPermission); exists && permission != nil {
+		attrPermission, err := metadata.ParseAttributePermission(permission)
+		if err != nil {
+			blog.ErrorJSON("parse attribute permission failed, err: %s, data: %s, rid: %s", err, data, kit.Rid)
+			return changeRow, kit.CCError.Errorf(common.CCErrCommParamsInvalid, metadata.AttributeFieldPermission)
+		}
+		if err := attrPermission.Validate(); err != nil {
+			blog.ErrorJSON("attribute permission is invalid, err: %s, data: %s, rid: %s", err, data, kit.Rid)
+			return changeRow, kit.CCError.Errorf(common.CCErrCommParamsInvalid, metadata.AttributeFieldPermission)
+		}
+		data.Set(metadata.AttributeFieldPermission, attrPermission)
 	}
 
 	// 删除不可更新字段， 避免由于传入数据，修改字段
@@ -13,7 +13,9 @@
 package model
 
 import (
+	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
 )
 
 // ATTENTIONS: the dependent methods of the other module
@@ -32,4 +34,7 @@ type OperationDependences interface {
 
 	// CascadeDeleteInstances cascade delete all instances(included instances, instance association) associated with modelObjID
 	CascadeDeleteInstances(kit *rest.Kit, objIDS []string) error
+
+	// CheckQuota checks the quota of the resource is not exceeded after the resources are created
+	CheckQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64, increment int64) errors.CCErrorCoder
 }
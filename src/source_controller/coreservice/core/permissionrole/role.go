@@ -0,0 +1,204 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package permissionrole
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+)
+
+var _ core.PermissionRoleOperation = (*permissionRole)(nil)
+
+type permissionRole struct {
+	dbProxy dal.RDB
+}
+
+// New create a new permission role manager instance
+func New(dbProxy dal.RDB) core.PermissionRoleOperation {
+	return &permissionRole{
+		dbProxy: dbProxy,
+	}
+}
+
+func (p *permissionRole) CreatePermissionRole(kit *rest.Kit, option metadata.CreatePermissionRoleOption) (metadata.PermissionRole, errors.CCErrorCoder) {
+	now := time.Now()
+	role := metadata.PermissionRole{
+		Name:            option.Name,
+		Members:         util.StrArrayUnique(option.Members),
+		Description:     option.Description,
+		Creator:         kit.User,
+		Modifier:        kit.User,
+		CreateTime:      now,
+		LastTime:        now,
+		SupplierAccount: kit.SupplierAccount,
+	}
+	if key, err := role.Validate(); err != nil {
+		blog.Errorf("CreatePermissionRole failed, parameter invalid, key: %s, err: %+v, rid: %s", key, err, kit.Rid)
+		return role, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	// role name is referenced by attributes' permission, it must be unique.
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKRoleNameField:   role.Name,
+	}
+	count, err := p.dbProxy.Table(common.BKTableNamePermissionRole).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("CreatePermissionRole failed, db count failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return role, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count > 0 {
+		return role, kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, common.BKRoleNameField)
+	}
+
+	id, err := p.dbProxy.NextSequence(kit.Ctx, common.BKTableNamePermissionRole)
+	if err != nil {
+		blog.Errorf("CreatePermissionRole failed, generate id failed, err: %+v, rid: %s", err, kit.Rid)
+		return role, kit.CCError.CCErrorf(common.CCErrCommGenerateRecordIDFailed)
+	}
+	role.ID = int64(id)
+
+	if err := p.dbProxy.Table(common.BKTableNamePermissionRole).Insert(kit.Ctx, role); err != nil {
+		if p.dbProxy.IsDuplicatedError(err) {
+			blog.Errorf("CreatePermissionRole failed, duplicated error, doc: %+v, err: %+v, rid: %s", role, err, kit.Rid)
+			return role, kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, common.BKRoleNameField)
+		}
+		blog.Errorf("CreatePermissionRole failed, db insert failed, doc: %+v, err: %+v, rid: %s", role, err, kit.Rid)
+		return role, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+
+	return role, nil
+}
+
+func (p *permissionRole) getPermissionRole(kit *rest.Kit, roleID int64) (metadata.PermissionRole, errors.CCErrorCoder) {
+	role := metadata.PermissionRole{}
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID:         roleID,
+	}
+	if err := p.dbProxy.Table(common.BKTableNamePermissionRole).Find(filter).One(kit.Ctx, &role); err != nil {
+		if p.dbProxy.IsNotFoundError(err) {
+			blog.Errorf("getPermissionRole failed, not found, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+			return role, kit.CCError.CCError(common.CCErrCommNotFound)
+		}
+		blog.Errorf("getPermissionRole failed, db select failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return role, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return role, nil
+}
+
+func (p *permissionRole) UpdatePermissionRole(kit *rest.Kit, roleID int64, option metadata.UpdatePermissionRoleOption) (metadata.PermissionRole, errors.CCErrorCoder) {
+	role, ccErr := p.getPermissionRole(kit, roleID)
+	if ccErr != nil {
+		return role, ccErr
+	}
+
+	if option.Members != nil {
+		role.Members = util.StrArrayUnique(option.Members)
+	}
+	if option.Description != nil {
+		role.Description = *option.Description
+	}
+	if key, err := role.Validate(); err != nil {
+		blog.Errorf("UpdatePermissionRole failed, parameter invalid, key: %s, err: %+v, rid: %s", key, err, kit.Rid)
+		return role, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+	role.Modifier = kit.User
+	role.LastTime = time.Now()
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID:         roleID,
+	}
+	if err := p.dbProxy.Table(common.BKTableNamePermissionRole).Update(kit.Ctx, filter, role); err != nil {
+		blog.ErrorJSON("UpdatePermissionRole failed, db update failed, filter: %s, doc: %s, err: %s, rid: %s", filter, role, err, kit.Rid)
+		return role, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	return role, nil
+}
+
+// DeletePermissionRole delete the permission roles, attributes' permission granted to these roles becomes
+// ineffective, which means only the users of the other granted roles have the permission.
+func (p *permissionRole) DeletePermissionRole(kit *rest.Kit, roleIDs []int64) errors.CCErrorCoder {
+	if len(roleIDs) == 0 {
+		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "ids")
+	}
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID: map[string]interface{}{
+			common.BKDBIN: roleIDs,
+		},
+	}
+	if err := p.dbProxy.Table(common.BKTableNamePermissionRole).Delete(kit.Ctx, filter); err != nil {
+		blog.Errorf("DeletePermissionRole failed, db remove failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+
+	return nil
+}
+
+func (p *permissionRole) ListPermissionRole(kit *rest.Kit, option metadata.ListPermissionRoleOption) (metadata.MultiplePermissionRole, errors.CCErrorCoder) {
+	result := metadata.MultiplePermissionRole{}
+	if option.Page.Limit > common.BKMaxPageSize && option.Page.Limit != common.BKNoLimit {
+		return result, kit.CCError.CCError(common.CCErrCommPageLimitIsExceeded)
+	}
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+	if len(option.Names) != 0 {
+		filter[common.BKRoleNameField] = map[string]interface{}{
+			common.BKDBIN: option.Names,
+		}
+	}
+	if len(option.Member) != 0 {
+		filter[common.BKMembersField] = option.Member
+	}
+
+	query := p.dbProxy.Table(common.BKTableNamePermissionRole).Find(filter)
+	total, err := query.Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("ListPermissionRole failed, db count failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	result.Count = int64(total)
+
+	if len(option.Page.Sort) > 0 {
+		query = query.Sort(option.Page.Sort)
+	}
+	if option.Page.Limit > 0 {
+		query = query.Limit(uint64(option.Page.Limit))
+	}
+	if option.Page.Start > 0 {
+		query = query.Start(uint64(option.Page.Start))
+	}
+
+	roles := make([]metadata.PermissionRole, 0)
+	if err := query.All(kit.Ctx, &roles); err != nil {
+		blog.ErrorJSON("ListPermissionRole failed, db select failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	result.Info = roles
+	return result, nil
+}
@@ -0,0 +1,427 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package quota
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+)
+
+var _ core.QuotaOperation = (*quotaManager)(nil)
+
+type quotaManager struct {
+	dbProxy dal.RDB
+	audit   core.AuditOperation
+}
+
+// New create a new quota manager instance, the quota changes are saved to the audit log.
+func New(dbProxy dal.RDB, audit core.AuditOperation) core.QuotaOperation {
+	return &quotaManager{
+		dbProxy: dbProxy,
+		audit:   audit,
+	}
+}
+
+func (q *quotaManager) SetQuota(kit *rest.Kit, option metadata.SetQuotaOption) (*metadata.Quota, errors.CCErrorCoder) {
+	if field, err := option.Validate(); err != nil {
+		blog.Errorf("SetQuota failed, option invalid, field: %s, err: %v, rid: %s", field, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field)
+	}
+
+	if option.Scope == metadata.QuotaScopeBusiness && option.BizID != 0 {
+		filter := map[string]interface{}{common.BKAppIDField: option.BizID}
+		count, err := q.dbProxy.Table(common.BKTableNameBaseApp).Find(filter).Count(kit.Ctx)
+		if err != nil {
+			blog.Errorf("SetQuota failed, count business %d failed, err: %v, rid: %s", option.BizID, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		}
+		if count == 0 {
+			blog.Errorf("SetQuota failed, business %d not exists, rid: %s", option.BizID, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField)
+		}
+	}
+
+	previous, err := q.getQuota(kit, option.QuotaKey)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	action := metadata.AuditCreate
+	current := metadata.Quota{
+		Scope:           option.Scope,
+		BizID:           option.BizID,
+		Resource:        option.Resource,
+		Limit:           option.Limit,
+		Creator:         kit.User,
+		Modifier:        kit.User,
+		CreateTime:      now,
+		LastTime:        now,
+		SupplierAccount: kit.SupplierAccount,
+	}
+	if previous != nil {
+		action = metadata.AuditUpdate
+		current.ID = previous.ID
+		current.Creator = previous.Creator
+		current.CreateTime = previous.CreateTime
+		doc := map[string]interface{}{
+			"limit":              option.Limit,
+			common.ModifierField: kit.User,
+			common.LastTimeField: now,
+		}
+		filter := map[string]interface{}{
+			common.BkSupplierAccount: kit.SupplierAccount,
+			common.BKFieldID:         previous.ID,
+		}
+		if err := q.dbProxy.Table(common.BKTableNameQuota).Update(kit.Ctx, filter, doc); err != nil {
+			blog.Errorf("SetQuota failed, db update failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+		}
+	} else {
+		id, err := q.dbProxy.NextSequence(kit.Ctx, common.BKTableNameQuota)
+		if err != nil {
+			blog.Errorf("SetQuota failed, generate id failed, err: %v, rid: %s", err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommGenerateRecordIDFailed)
+		}
+		current.ID = int64(id)
+		if err := q.dbProxy.Table(common.BKTableNameQuota).Insert(kit.Ctx, current); err != nil {
+			blog.Errorf("SetQuota failed, db insert failed, quota: %+v, err: %v, rid: %s", current, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+		}
+	}
+
+	if err := q.saveAuditLog(kit, action, previous, &current); err != nil {
+		return nil, err
+	}
+	return &current, nil
+}
+
+func (q *quotaManager) DeleteQuota(kit *rest.Kit, key metadata.QuotaKey) errors.CCErrorCoder {
+	if field, err := key.Validate(); err != nil {
+		blog.Errorf("DeleteQuota failed, option invalid, field: %s, err: %v, rid: %s", field, err, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field)
+	}
+
+	previous, err := q.getQuota(kit, key)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		blog.Errorf("DeleteQuota failed, quota %+v not found, rid: %s", key, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID:         previous.ID,
+	}
+	if err := q.dbProxy.Table(common.BKTableNameQuota).Delete(kit.Ctx, filter); err != nil {
+		blog.Errorf("DeleteQuota failed, db delete failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+
+	return q.saveAuditLog(kit, metadata.AuditDelete, previous, nil)
+}
+
+func (q *quotaManager) ListQuotaUsage(kit *rest.Kit, option metadata.ListQuotaUsageOption) ([]metadata.QuotaUsage, errors.CCErrorCoder) {
+	usages := make([]metadata.QuotaUsage, 0)
+	for _, resource := range metadata.QuotaResources {
+		usage := metadata.QuotaUsage{
+			QuotaKey: metadata.QuotaKey{
+				Scope:    metadata.QuotaScopeTenant,
+				BizID:    option.BizID,
+				Resource: resource,
+			},
+		}
+
+		quotas, err := q.findQuotas(kit, resource, option.BizID)
+		if err != nil {
+			return nil, err
+		}
+		if option.BizID == 0 {
+			if quota, exists := quotas[metadata.QuotaScopeTenant]; exists {
+				usage.Limit = &quota.Limit
+			}
+		} else {
+			usage.Scope = metadata.QuotaScopeBusiness
+			quota, overridden, err := q.businessQuota(kit, quotas, option.BizID)
+			if err != nil {
+				return nil, err
+			}
+			if quota != nil && quota.Limit != metadata.QuotaUnlimited {
+				usage.Limit = &quota.Limit
+			}
+			usage.Overridden = overridden
+		}
+
+		if usage.Used, err = q.countUsage(kit, resource, option.BizID); err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
+
+// CheckQuota checks the tenant quota and the business quota of the resource are not exceeded after the
+// increment number of resources are created, the business quota is not checked if the business id is 0.
+func (q *quotaManager) CheckQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64, increment int64) errors.CCErrorCoder {
+	quotas, err := q.findQuotas(kit, resource, bizID)
+	if err != nil {
+		return err
+	}
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	if quota, exists := quotas[metadata.QuotaScopeTenant]; exists {
+		if err := q.checkLimit(kit, quota, 0, increment, fmt.Sprintf("supplier account %s", kit.SupplierAccount)); err != nil {
+			return err
+		}
+	}
+	return q.checkBusinessLimit(kit, quotas, bizID, increment)
+}
+
+// CheckBusinessQuota checks only the business quota of the resource is not exceeded after the increment number
+// of resources are moved into the business, it's used when the resources are moved between businesses, which
+// does not add resources to the tenant.
+func (q *quotaManager) CheckBusinessQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64, increment int64) errors.CCErrorCoder {
+	if bizID == 0 {
+		return nil
+	}
+	quotas, err := q.findQuotas(kit, resource, bizID)
+	if err != nil {
+		return err
+	}
+	return q.checkBusinessLimit(kit, quotas, bizID, increment)
+}
+
+func (q *quotaManager) checkBusinessLimit(kit *rest.Kit, quotas map[metadata.QuotaScope]*metadata.Quota, bizID int64,
+	increment int64) errors.CCErrorCoder {
+
+	if bizID == 0 || len(quotas) == 0 {
+		return nil
+	}
+	quota, _, err := q.businessQuota(kit, quotas, bizID)
+	if err != nil {
+		return err
+	}
+	if quota == nil || quota.Limit == metadata.QuotaUnlimited {
+		return nil
+	}
+	return q.checkLimit(kit, quota, bizID, increment, fmt.Sprintf("business %d", bizID))
+}
+
+func (q *quotaManager) checkLimit(kit *rest.Kit, quota *metadata.Quota, bizID, increment int64, scope string) errors.CCErrorCoder {
+	used, err := q.countUsage(kit, quota.Resource, bizID)
+	if err != nil {
+		return err
+	}
+	if used+increment > quota.Limit {
+		blog.Errorf("the %s quota of %s is exceeded, limit: %d, used: %d, increment: %d, rid: %s", quota.Resource,
+			scope, quota.Limit, used, increment, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommQuotaExceeded, quota.Resource, scope, quota.Limit, used)
+	}
+	return nil
+}
+
+// findQuotas returns the tenant quota and the business quotas of the resource, the quota of the specified
+// business is keyed by QuotaScopeBusiness, and the default business quota is keyed by defaultBizQuota.
+func (q *quotaManager) findQuotas(kit *rest.Kit, resource metadata.QuotaResource, bizID int64) (
+	map[metadata.QuotaScope]*metadata.Quota, errors.CCErrorCoder) {
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		"resource":               resource,
+	}
+	quotas := make([]metadata.Quota, 0)
+	if err := q.dbProxy.Table(common.BKTableNameQuota).Find(filter).All(kit.Ctx, &quotas); err != nil {
+		blog.Errorf("find %s quotas failed, filter: %+v, err: %v, rid: %s", resource, filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	result := make(map[metadata.QuotaScope]*metadata.Quota)
+	for idx := range quotas {
+		quota := &quotas[idx]
+		switch {
+		case quota.Scope == metadata.QuotaScopeTenant:
+			result[metadata.QuotaScopeTenant] = quota
+		case quota.Scope == metadata.QuotaScopeBusiness && quota.BizID == 0:
+			result[defaultBizQuota] = quota
+		case quota.Scope == metadata.QuotaScopeBusiness && quota.BizID == bizID:
+			result[metadata.QuotaScopeBusiness] = quota
+		}
+	}
+	return result, nil
+}
+
+// defaultBizQuota is the key of the default business quota in the quotas found by findQuotas
+const defaultBizQuota metadata.QuotaScope = "default_business"
+
+// businessQuota returns the effective quota of the business and whether it's overridden, the default
+// business quota is not applied to the resource pool.
+func (q *quotaManager) businessQuota(kit *rest.Kit, quotas map[metadata.QuotaScope]*metadata.Quota, bizID int64) (
+	*metadata.Quota, bool, errors.CCErrorCoder) {
+
+	if quota, exists := quotas[metadata.QuotaScopeBusiness]; exists {
+		return quota, true, nil
+	}
+
+	quota, exists := quotas[defaultBizQuota]
+	if !exists {
+		return nil, false, nil
+	}
+	filter := map[string]interface{}{
+		common.BKAppIDField:   bizID,
+		common.BKDefaultField: common.DefaultAppFlag,
+	}
+	count, err := q.dbProxy.Table(common.BKTableNameBaseApp).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("check whether business %d is resource pool failed, err: %v, rid: %s", bizID, err, kit.Rid)
+		return nil, false, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count > 0 {
+		return nil, false, nil
+	}
+	return quota, false, nil
+}
+
+// countUsage counts the resources of the tenant, or the business if the business id is not 0
+func (q *quotaManager) countUsage(kit *rest.Kit, resource metadata.QuotaResource, bizID int64) (int64, errors.CCErrorCoder) {
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+
+	var table string
+	switch resource {
+	case metadata.QuotaResourceHost:
+		if bizID != 0 {
+			filter[common.BKAppIDField] = bizID
+			return q.countBizHosts(kit, filter, bizID)
+		}
+		table = common.BKTableNameBaseHost
+	case metadata.QuotaResourceSet, metadata.QuotaResourceModule:
+		table = common.BKTableNameBaseSet
+		if resource == metadata.QuotaResourceModule {
+			table = common.BKTableNameBaseModule
+		}
+		if bizID != 0 {
+			filter[common.BKAppIDField] = bizID
+		}
+	case metadata.QuotaResourceInstance, metadata.QuotaResourceAttribute:
+		table = common.BKTableNameBaseInst
+		if resource == metadata.QuotaResourceAttribute {
+			table = common.BKTableNameObjAttDes
+			filter[common.BKIsPre] = false
+		}
+		if bizID != 0 {
+			filter[metadata.MetadataBizField] = strconv.FormatInt(bizID, 10)
+		}
+	default:
+		return 0, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "resource")
+	}
+
+	count, err := q.dbProxy.Table(table).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("count %s usage failed, filter: %+v, err: %v, rid: %s", resource, filter, err, kit.Rid)
+		return 0, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return int64(count), nil
+}
+
+// countBizHosts counts the distinct hosts of the business in the db, a host may be in several modules of the business
+func (q *quotaManager) countBizHosts(kit *rest.Kit, filter map[string]interface{}, bizID int64) (int64, errors.CCErrorCoder) {
+	pipeline := []map[string]interface{}{
+		{common.BKDBMatch: filter},
+		{common.BKDBGroup: map[string]interface{}{"_id": "$" + common.BKHostIDField}},
+		{common.BKDBCount: "count"},
+	}
+	result := struct {
+		Count int64 `bson:"count"`
+	}{}
+	if err := q.dbProxy.Table(common.BKTableNameModuleHostConfig).AggregateOne(kit.Ctx, pipeline, &result); err != nil {
+		// no document is returned if the business has no host
+		if q.dbProxy.IsNotFoundError(err) {
+			return 0, nil
+		}
+		blog.Errorf("count the hosts of business %d failed, err: %v, rid: %s", bizID, err, kit.Rid)
+		return 0, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return result.Count, nil
+}
+
+func (q *quotaManager) getQuota(kit *rest.Kit, key metadata.QuotaKey) (*metadata.Quota, errors.CCErrorCoder) {
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		"scope":                  key.Scope,
+		common.BKAppIDField:      key.BizID,
+		"resource":               key.Resource,
+	}
+	quotas := make([]metadata.Quota, 0)
+	if err := q.dbProxy.Table(common.BKTableNameQuota).Find(filter).All(kit.Ctx, &quotas); err != nil {
+		blog.Errorf("get quota failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if len(quotas) == 0 {
+		return nil, nil
+	}
+	return &quotas[0], nil
+}
+
+func (q *quotaManager) saveAuditLog(kit *rest.Kit, action metadata.ActionType, previous, current *metadata.Quota) errors.CCErrorCoder {
+	quota := current
+	if quota == nil {
+		quota = previous
+	}
+
+	details := &metadata.BasicContent{}
+	if previous != nil {
+		details.PreData = quotaAuditData(previous)
+	}
+	if current != nil {
+		details.CurData = quotaAuditData(current)
+	}
+	auditLog := metadata.AuditLog{
+		AuditType:    metadata.QuotaType,
+		ResourceType: metadata.QuotaRes,
+		Action:       action,
+		OperationDetail: &metadata.BasicOpDetail{
+			BusinessID:   quota.BizID,
+			ResourceID:   quota.ID,
+			ResourceName: fmt.Sprintf("%s/%s", quota.Scope, quota.Resource),
+			Details:      details,
+		},
+	}
+	if err := q.audit.CreateAuditLog(kit, auditLog); err != nil {
+		blog.Errorf("save quota audit log failed, quota: %+v, err: %v, rid: %s", quota, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrAuditSaveLogFailed)
+	}
+	return nil
+}
+
+func quotaAuditData(quota *metadata.Quota) map[string]interface{} {
+	return map[string]interface{}{
+		"scope":             quota.Scope,
+		common.BKAppIDField: quota.BizID,
+		"resource":          quota.Resource,
+		"limit":             quota.Limit,
+	}
+}
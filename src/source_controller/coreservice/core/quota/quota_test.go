@@ -0,0 +1,406 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package quota_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/source_controller/coreservice/core/quota"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/mongo/local"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAudit struct {
+	logs []metadata.AuditLog
+}
+
+func (a *mockAudit) CreateAuditLog(kit *rest.Kit, logs ...metadata.AuditLog) error {
+	a.logs = append(a.logs, logs...)
+	return nil
+}
+
+func (a *mockAudit) SearchAuditLog(kit *rest.Kit, param metadata.QueryInput) ([]metadata.AuditLog, uint64, error) {
+	return a.logs, uint64(len(a.logs)), nil
+}
+
+// testSuite is the quota manager of a new supplier account, so that the quotas and resources of the tests
+// don't affect each other.
+type testSuite struct {
+	t     *testing.T
+	db    dal.RDB
+	kit   *rest.Kit
+	audit *mockAudit
+	quota core.QuotaOperation
+}
+
+// newTestSuite returns the test suite with the mongodb of MONGOURI and MONGORS, the test is skipped if the
+// mongodb is not set.
+func newTestSuite(t *testing.T) *testSuite {
+	uri := os.Getenv("MONGOURI")
+	if uri == "" {
+		t.Skip("MONGOURI is not set, skip the test which needs mongodb")
+	}
+	db, err := local.NewMgo(local.MongoConf{
+		MaxOpenConns: 100,
+		MaxIdleConns: 10,
+		URI:          uri,
+		RsName:       os.Getenv("MONGORS"),
+	}, time.Minute)
+	require.NoError(t, err)
+
+	errIf, _ := errors.NewFactory("../../../../../resources/errors/")
+	kit := &rest.Kit{
+		Rid:             "test_req_id",
+		Header:          http.Header{},
+		Ctx:             context.Background(),
+		CCError:         errIf.CreateDefaultCCErrorIf("en"),
+		User:            "test_user",
+		SupplierAccount: xid.New().String(),
+	}
+	audit := &mockAudit{}
+	s := &testSuite{t: t, db: db, kit: kit, audit: audit, quota: quota.New(db, audit)}
+	t.Cleanup(s.cleanup)
+	return s
+}
+
+var testTables = []string{
+	common.BKTableNameQuota,
+	common.BKTableNameBaseApp,
+	common.BKTableNameBaseHost,
+	common.BKTableNameModuleHostConfig,
+	common.BKTableNameBaseSet,
+	common.BKTableNameBaseModule,
+	common.BKTableNameBaseInst,
+	common.BKTableNameObjAttDes,
+}
+
+func (s *testSuite) cleanup() {
+	for _, table := range testTables {
+		filter := map[string]interface{}{common.BkSupplierAccount: s.kit.SupplierAccount}
+		if err := s.db.Table(table).Delete(s.kit.Ctx, filter); err != nil {
+			s.t.Logf("clean up the table %s failed, err: %v", table, err)
+		}
+	}
+}
+
+// newBizID returns a business id which is not used by the other tests.
+var newBizID = func() func() int64 {
+	bizID := time.Now().UnixNano() / 1000
+	return func() int64 {
+		bizID++
+		return bizID
+	}
+}()
+
+// createBiz creates a business, it's the resource pool if isDefault is true.
+func (s *testSuite) createBiz(isDefault bool) int64 {
+	bizID := newBizID()
+	biz := map[string]interface{}{
+		common.BKAppIDField:      bizID,
+		common.BKAppNameField:    strconv.FormatInt(bizID, 10),
+		common.BKDefaultField:    0,
+		common.BkSupplierAccount: s.kit.SupplierAccount,
+	}
+	if isDefault {
+		biz[common.BKDefaultField] = common.DefaultAppFlag
+	}
+	require.NoError(s.t, s.db.Table(common.BKTableNameBaseApp).Insert(s.kit.Ctx, biz))
+	return bizID
+}
+
+func (s *testSuite) insert(table string, docs ...map[string]interface{}) {
+	for _, doc := range docs {
+		doc[common.BkSupplierAccount] = s.kit.SupplierAccount
+		require.NoError(s.t, s.db.Table(table).Insert(s.kit.Ctx, doc))
+	}
+}
+
+func (s *testSuite) setQuota(scope metadata.QuotaScope, bizID int64, resource metadata.QuotaResource, limit int64) {
+	option := metadata.SetQuotaOption{
+		QuotaKey: metadata.QuotaKey{Scope: scope, BizID: bizID, Resource: resource},
+		Limit:    limit,
+	}
+	_, err := s.quota.SetQuota(s.kit, option)
+	require.NoError(s.t, err)
+}
+
+func (s *testSuite) requireExceeded(err errors.CCErrorCoder) {
+	require.Error(s.t, err)
+	require.Equal(s.t, common.CCErrCommQuotaExceeded, err.GetCode())
+}
+
+func (s *testSuite) usage(bizID int64, resource metadata.QuotaResource) metadata.QuotaUsage {
+	usages, err := s.quota.ListQuotaUsage(s.kit, metadata.ListQuotaUsageOption{BizID: bizID})
+	require.NoError(s.t, err)
+	for _, usage := range usages {
+		if usage.Resource == resource {
+			return usage
+		}
+	}
+	s.t.Fatalf("the usage of %s is not found", resource)
+	return metadata.QuotaUsage{}
+}
+
+func TestSetQuota(t *testing.T) {
+	s := newTestSuite(t)
+	bizID := s.createBiz(false)
+
+	testCases := []struct {
+		name   string
+		option metadata.SetQuotaOption
+		field  string
+	}{
+		{
+			name: "invalid scope",
+			option: metadata.SetQuotaOption{QuotaKey: metadata.QuotaKey{Scope: "biz", Resource: metadata.QuotaResourceHost},
+				Limit: 1},
+			field: "scope",
+		},
+		{
+			name: "tenant quota of a business",
+			option: metadata.SetQuotaOption{QuotaKey: metadata.QuotaKey{Scope: metadata.QuotaScopeTenant, BizID: bizID,
+				Resource: metadata.QuotaResourceHost}, Limit: 1},
+			field: common.BKAppIDField,
+		},
+		{
+			name: "invalid resource",
+			option: metadata.SetQuotaOption{QuotaKey: metadata.QuotaKey{Scope: metadata.QuotaScopeTenant,
+				Resource: "process"}, Limit: 1},
+			field: "resource",
+		},
+		{
+			name: "the default business quota can't be unlimited",
+			option: metadata.SetQuotaOption{QuotaKey: metadata.QuotaKey{Scope: metadata.QuotaScopeBusiness,
+				Resource: metadata.QuotaResourceHost}, Limit: metadata.QuotaUnlimited},
+			field: "limit",
+		},
+		{
+			name: "business not exists",
+			option: metadata.SetQuotaOption{QuotaKey: metadata.QuotaKey{Scope: metadata.QuotaScopeBusiness,
+				BizID: newBizID(), Resource: metadata.QuotaResourceHost}, Limit: 1},
+			field: common.BKAppIDField,
+		},
+	}
+	for _, testCase := range testCases {
+		_, err := s.quota.SetQuota(s.kit, testCase.option)
+		require.Error(t, err, testCase.name)
+		require.Equal(t, common.CCErrCommParamsInvalid, err.GetCode(), testCase.name)
+		require.Contains(t, err.Error(), testCase.field, testCase.name)
+	}
+	require.Empty(t, s.audit.logs)
+
+	key := metadata.QuotaKey{Scope: metadata.QuotaScopeBusiness, BizID: bizID, Resource: metadata.QuotaResourceHost}
+	created, err := s.quota.SetQuota(s.kit, metadata.SetQuotaOption{QuotaKey: key, Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), created.Limit)
+	require.Equal(t, "test_user", created.Creator)
+
+	// the quota is updated with the same key
+	s.kit.User = "admin"
+	updated, err := s.quota.SetQuota(s.kit, metadata.SetQuotaOption{QuotaKey: key, Limit: metadata.QuotaUnlimited})
+	require.NoError(t, err)
+	require.Equal(t, created.ID, updated.ID)
+	require.Equal(t, metadata.QuotaUnlimited, updated.Limit)
+	require.Equal(t, "test_user", updated.Creator)
+	require.Equal(t, "admin", updated.Modifier)
+
+	require.NoError(t, s.quota.DeleteQuota(s.kit, key))
+	err = s.quota.DeleteQuota(s.kit, key)
+	require.Error(t, err)
+	require.Equal(t, common.CCErrCommNotFound, err.GetCode())
+
+	// all the changes are saved to the audit log
+	require.Len(t, s.audit.logs, 3)
+	actions := []metadata.ActionType{metadata.AuditCreate, metadata.AuditUpdate, metadata.AuditDelete}
+	for idx, log := range s.audit.logs {
+		require.Equal(t, metadata.QuotaType, log.AuditType)
+		require.Equal(t, actions[idx], log.Action)
+		detail, ok := log.OperationDetail.(*metadata.BasicOpDetail)
+		require.True(t, ok)
+		require.Equal(t, bizID, detail.BusinessID)
+		require.Equal(t, created.ID, detail.ResourceID)
+	}
+	require.Nil(t, s.audit.logs[0].OperationDetail.(*metadata.BasicOpDetail).Details.PreData)
+	require.Equal(t, int64(10), s.audit.logs[1].OperationDetail.(*metadata.BasicOpDetail).Details.PreData["limit"])
+	require.Equal(t, metadata.QuotaUnlimited,
+		s.audit.logs[1].OperationDetail.(*metadata.BasicOpDetail).Details.CurData["limit"])
+	require.Nil(t, s.audit.logs[2].OperationDetail.(*metadata.BasicOpDetail).Details.CurData)
+}
+
+func TestCheckTenantQuota(t *testing.T) {
+	s := newTestSuite(t)
+	bizID := s.createBiz(false)
+
+	// the resources are not limited without the quota
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceInstance, 0, 100))
+
+	s.setQuota(metadata.QuotaScopeTenant, 0, metadata.QuotaResourceInstance, 3)
+	s.insert(common.BKTableNameBaseInst,
+		map[string]interface{}{common.BKInstIDField: 1, common.BKObjIDField: "bk_switch"},
+		map[string]interface{}{common.BKInstIDField: 2, common.BKObjIDField: "bk_switch",
+			metadata.BKMetadata: map[string]interface{}{
+				metadata.BKLabel: map[string]interface{}{common.BKAppIDField: strconv.FormatInt(bizID, 10)},
+			}},
+	)
+
+	// the instances of all the businesses are counted in the tenant quota
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceInstance, 0, 1))
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceInstance, bizID, 1))
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceInstance, 0, 2))
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceInstance, bizID, 2))
+
+	// the quota of the other resources and the other tenants are not affected
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceSet, 0, 100))
+	otherKit := *s.kit
+	otherKit.SupplierAccount = xid.New().String()
+	require.NoError(t, s.quota.CheckQuota(&otherKit, metadata.QuotaResourceInstance, 0, 100))
+
+	usage := s.usage(0, metadata.QuotaResourceInstance)
+	require.Equal(t, metadata.QuotaScopeTenant, usage.Scope)
+	require.Equal(t, int64(3), *usage.Limit)
+	require.Equal(t, int64(2), usage.Used)
+	require.Nil(t, s.usage(0, metadata.QuotaResourceSet).Limit)
+}
+
+func TestCheckBusinessQuota(t *testing.T) {
+	s := newTestSuite(t)
+	bizID := s.createBiz(false)
+	otherBizID := s.createBiz(false)
+	poolBizID := s.createBiz(true)
+
+	// the default business quota
+	s.setQuota(metadata.QuotaScopeBusiness, 0, metadata.QuotaResourceSet, 2)
+	s.insert(common.BKTableNameBaseSet,
+		map[string]interface{}{common.BKSetIDField: 1, common.BKAppIDField: bizID},
+		map[string]interface{}{common.BKSetIDField: 2, common.BKAppIDField: bizID},
+		map[string]interface{}{common.BKSetIDField: 3, common.BKAppIDField: otherBizID},
+		map[string]interface{}{common.BKSetIDField: 4, common.BKAppIDField: poolBizID},
+		map[string]interface{}{common.BKSetIDField: 5, common.BKAppIDField: poolBizID},
+	)
+
+	// each business is counted separately
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceSet, bizID, 1))
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceSet, otherBizID, 1))
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceSet, otherBizID, 2))
+	// the default business quota is not applied to the resource pool and the tenant
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceSet, poolBizID, 10))
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceSet, 0, 10))
+
+	// the business is limited by the tenant quota too
+	s.setQuota(metadata.QuotaScopeTenant, 0, metadata.QuotaResourceSet, 6)
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceSet, otherBizID, 1))
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceSet, poolBizID, 2))
+
+	usage := s.usage(otherBizID, metadata.QuotaResourceSet)
+	require.Equal(t, metadata.QuotaScopeBusiness, usage.Scope)
+	require.Equal(t, int64(2), *usage.Limit)
+	require.Equal(t, int64(1), usage.Used)
+	require.False(t, usage.Overridden)
+	require.Nil(t, s.usage(poolBizID, metadata.QuotaResourceSet).Limit)
+}
+
+func TestOverrideBusinessQuota(t *testing.T) {
+	s := newTestSuite(t)
+	bizID := s.createBiz(false)
+	otherBizID := s.createBiz(false)
+
+	s.setQuota(metadata.QuotaScopeBusiness, 0, metadata.QuotaResourceModule, 1)
+	s.insert(common.BKTableNameBaseModule,
+		map[string]interface{}{common.BKModuleIDField: 1, common.BKAppIDField: bizID},
+		map[string]interface{}{common.BKModuleIDField: 2, common.BKAppIDField: otherBizID},
+	)
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceModule, bizID, 1))
+
+	// the admin raises the quota of the business only
+	s.setQuota(metadata.QuotaScopeBusiness, bizID, metadata.QuotaResourceModule, 3)
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceModule, bizID, 2))
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceModule, bizID, 3))
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceModule, otherBizID, 1))
+
+	usage := s.usage(bizID, metadata.QuotaResourceModule)
+	require.Equal(t, int64(3), *usage.Limit)
+	require.Equal(t, int64(1), usage.Used)
+	require.True(t, usage.Overridden)
+
+	// the business is not limited after it's overridden to be unlimited
+	s.setQuota(metadata.QuotaScopeBusiness, bizID, metadata.QuotaResourceModule, metadata.QuotaUnlimited)
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceModule, bizID, 100))
+	usage = s.usage(bizID, metadata.QuotaResourceModule)
+	require.Nil(t, usage.Limit)
+	require.True(t, usage.Overridden)
+
+	// the default business quota is applied again after the override is deleted
+	key := metadata.QuotaKey{Scope: metadata.QuotaScopeBusiness, BizID: bizID, Resource: metadata.QuotaResourceModule}
+	require.NoError(t, s.quota.DeleteQuota(s.kit, key))
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceModule, bizID, 1))
+}
+
+func TestCheckHostQuota(t *testing.T) {
+	s := newTestSuite(t)
+	bizID := s.createBiz(false)
+	s.insert(common.BKTableNameBaseHost,
+		map[string]interface{}{common.BKHostIDField: 1},
+		map[string]interface{}{common.BKHostIDField: 2},
+		map[string]interface{}{common.BKHostIDField: 3},
+	)
+	// the host 1 is in two modules of the business, it's counted once
+	s.insert(common.BKTableNameModuleHostConfig,
+		map[string]interface{}{common.BKHostIDField: 1, common.BKAppIDField: bizID, common.BKModuleIDField: 1},
+		map[string]interface{}{common.BKHostIDField: 1, common.BKAppIDField: bizID, common.BKModuleIDField: 2},
+		map[string]interface{}{common.BKHostIDField: 2, common.BKAppIDField: bizID, common.BKModuleIDField: 1},
+	)
+	s.setQuota(metadata.QuotaScopeTenant, 0, metadata.QuotaResourceHost, 4)
+	s.setQuota(metadata.QuotaScopeBusiness, bizID, metadata.QuotaResourceHost, 3)
+
+	require.Equal(t, int64(3), s.usage(0, metadata.QuotaResourceHost).Used)
+	require.Equal(t, int64(2), s.usage(bizID, metadata.QuotaResourceHost).Used)
+
+	// the batch of hosts to be created are checked at once
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceHost, 0, 1))
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceHost, 0, 2))
+	require.NoError(t, s.quota.CheckQuota(s.kit, metadata.QuotaResourceHost, bizID, 1))
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceHost, bizID, 2))
+
+	// the transferred hosts are already counted in the tenant quota, only the business quota is checked
+	s.setQuota(metadata.QuotaScopeTenant, 0, metadata.QuotaResourceHost, 3)
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceHost, bizID, 1))
+	require.NoError(t, s.quota.CheckBusinessQuota(s.kit, metadata.QuotaResourceHost, bizID, 1))
+	s.requireExceeded(s.quota.CheckBusinessQuota(s.kit, metadata.QuotaResourceHost, bizID, 2))
+	require.NoError(t, s.quota.CheckBusinessQuota(s.kit, metadata.QuotaResourceHost, 0, 100))
+}
+
+func TestCheckAttributeQuota(t *testing.T) {
+	s := newTestSuite(t)
+	s.setQuota(metadata.QuotaScopeTenant, 0, metadata.QuotaResourceAttribute, 1)
+	s.insert(common.BKTableNameObjAttDes,
+		map[string]interface{}{common.BKPropertyIDField: common.BKInstNameField, common.BKIsPre: true},
+		map[string]interface{}{common.BKPropertyIDField: "switch_port", common.BKIsPre: false},
+	)
+
+	// the preset attributes are not counted
+	require.Equal(t, int64(1), s.usage(0, metadata.QuotaResourceAttribute).Used)
+	s.requireExceeded(s.quota.CheckQuota(s.kit, metadata.QuotaResourceAttribute, 0, 1))
+}
@@ -0,0 +1,401 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serviceaccount
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+)
+
+// lastUsedUpdateInterval is the minimum interval to update the api token's last used time, so that
+// every request authenticated by the token does not write the db.
+const lastUsedUpdateInterval = time.Minute
+
+var _ core.ServiceAccountOperation = (*serviceAccount)(nil)
+
+type serviceAccount struct {
+	dbProxy dal.RDB
+}
+
+// New create a new service account manager instance
+func New(dbProxy dal.RDB) core.ServiceAccountOperation {
+	return &serviceAccount{
+		dbProxy: dbProxy,
+	}
+}
+
+func (s *serviceAccount) CreateServiceAccount(kit *rest.Kit, option metadata.CreateServiceAccountOption) (metadata.ServiceAccount, errors.CCErrorCoder) {
+	now := time.Now()
+	account := metadata.ServiceAccount{
+		Name:            option.Name,
+		Description:     option.Description,
+		Enabled:         true,
+		Creator:         kit.User,
+		CreateTime:      now,
+		LastTime:        now,
+		SupplierAccount: kit.SupplierAccount,
+	}
+	if key, err := option.Validate(); err != nil {
+		blog.Errorf("CreateServiceAccount failed, parameter invalid, key: %s, err: %+v, rid: %s", key, err, kit.Rid)
+		return account, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	// the name is part of the user name the service account's requests are executed as, it must be unique.
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldName:       account.Name,
+	}
+	count, err := s.dbProxy.Table(common.BKTableNameServiceAccount).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("CreateServiceAccount failed, db count failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return account, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count > 0 {
+		return account, kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, common.BKFieldName)
+	}
+
+	id, err := s.dbProxy.NextSequence(kit.Ctx, common.BKTableNameServiceAccount)
+	if err != nil {
+		blog.Errorf("CreateServiceAccount failed, generate id failed, err: %+v, rid: %s", err, kit.Rid)
+		return account, kit.CCError.CCErrorf(common.CCErrCommGenerateRecordIDFailed)
+	}
+	account.ID = int64(id)
+
+	if err := s.dbProxy.Table(common.BKTableNameServiceAccount).Insert(kit.Ctx, account); err != nil {
+		if s.dbProxy.IsDuplicatedError(err) {
+			return account, kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, common.BKFieldName)
+		}
+		blog.Errorf("CreateServiceAccount failed, db insert failed, doc: %+v, err: %+v, rid: %s", account, err, kit.Rid)
+		return account, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+	return account, nil
+}
+
+func (s *serviceAccount) getServiceAccount(kit *rest.Kit, accountID int64) (metadata.ServiceAccount, errors.CCErrorCoder) {
+	account := metadata.ServiceAccount{}
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID:         accountID,
+	}
+	if err := s.dbProxy.Table(common.BKTableNameServiceAccount).Find(filter).One(kit.Ctx, &account); err != nil {
+		if s.dbProxy.IsNotFoundError(err) {
+			blog.Errorf("get service account failed, not found, filter: %+v, rid: %s", filter, kit.Rid)
+			return account, kit.CCError.CCError(common.CCErrCommNotFound)
+		}
+		blog.Errorf("get service account failed, db select failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return account, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return account, nil
+}
+
+// UpdateServiceAccount update the service account's description, or enable/disable it.
+func (s *serviceAccount) UpdateServiceAccount(kit *rest.Kit, accountID int64, option metadata.UpdateServiceAccountOption) (
+	metadata.ServiceAccount, errors.CCErrorCoder) {
+
+	account, ccErr := s.getServiceAccount(kit, accountID)
+	if ccErr != nil {
+		return account, ccErr
+	}
+
+	if option.Description != nil {
+		account.Description = *option.Description
+	}
+	if option.Enabled != nil {
+		account.Enabled = *option.Enabled
+	}
+	account.LastTime = time.Now()
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID:         accountID,
+	}
+	if err := s.dbProxy.Table(common.BKTableNameServiceAccount).Update(kit.Ctx, filter, account); err != nil {
+		blog.ErrorJSON("UpdateServiceAccount failed, db update failed, filter: %s, doc: %s, err: %s, rid: %s", filter, account, err, kit.Rid)
+		return account, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+	return account, nil
+}
+
+// DeleteServiceAccount delete the service account and all of it's api tokens.
+func (s *serviceAccount) DeleteServiceAccount(kit *rest.Kit, accountID int64) errors.CCErrorCoder {
+	if _, ccErr := s.getServiceAccount(kit, accountID); ccErr != nil {
+		return ccErr
+	}
+
+	tokenFilter := map[string]interface{}{
+		common.BkSupplierAccount:       kit.SupplierAccount,
+		common.BKServiceAccountIDField: accountID,
+	}
+	if err := s.dbProxy.Table(common.BKTableNameAPIToken).Delete(kit.Ctx, tokenFilter); err != nil {
+		blog.Errorf("DeleteServiceAccount failed, delete api tokens failed, filter: %+v, err: %+v, rid: %s", tokenFilter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKFieldID:         accountID,
+	}
+	if err := s.dbProxy.Table(common.BKTableNameServiceAccount).Delete(kit.Ctx, filter); err != nil {
+		blog.Errorf("DeleteServiceAccount failed, db delete failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
+
+func (s *serviceAccount) ListServiceAccount(kit *rest.Kit, option metadata.ListServiceAccountOption) (metadata.MultipleServiceAccount, errors.CCErrorCoder) {
+	result := metadata.MultipleServiceAccount{}
+	if option.Page.Limit > common.BKMaxPageSize && option.Page.Limit != common.BKNoLimit {
+		return result, kit.CCError.CCError(common.CCErrCommPageLimitIsExceeded)
+	}
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+	if len(option.IDs) != 0 {
+		filter[common.BKFieldID] = map[string]interface{}{
+			common.BKDBIN: option.IDs,
+		}
+	}
+	if len(option.Name) != 0 {
+		filter[common.BKFieldName] = option.Name
+	}
+	if option.Enabled != nil {
+		filter["enabled"] = *option.Enabled
+	}
+	if len(option.Creator) != 0 {
+		filter[common.CreatorField] = option.Creator
+	}
+
+	query := s.dbProxy.Table(common.BKTableNameServiceAccount).Find(filter)
+	total, err := query.Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("ListServiceAccount failed, db count failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	result.Count = int64(total)
+
+	accounts := make([]metadata.ServiceAccount, 0)
+	if err := pageQuery(query, option.Page).All(kit.Ctx, &accounts); err != nil {
+		blog.ErrorJSON("ListServiceAccount failed, db select failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	result.Info = accounts
+	return result, nil
+}
+
+// CreateAPIToken create an api token for the service account, the token is only returned here and
+// only it's hash is saved.
+func (s *serviceAccount) CreateAPIToken(kit *rest.Kit, accountID int64, option metadata.CreateAPITokenOption) (
+	metadata.CreateAPITokenResult, errors.CCErrorCoder) {
+
+	result := metadata.CreateAPITokenResult{}
+	now := time.Now()
+	if key, err := option.Validate(now); err != nil {
+		blog.Errorf("CreateAPIToken failed, parameter invalid, key: %s, err: %+v, rid: %s", key, err, kit.Rid)
+		return result, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	if _, ccErr := s.getServiceAccount(kit, accountID); ccErr != nil {
+		return result, ccErr
+	}
+
+	token, hash, err := metadata.NewAPIToken()
+	if err != nil {
+		blog.Errorf("CreateAPIToken failed, generate token failed, err: %+v, rid: %s", err, kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommGenerateRecordIDFailed)
+	}
+
+	// the creator can only grant the token to it's own supplier account.
+	for _, supplierAccount := range option.SupplierAccounts {
+		if supplierAccount != kit.SupplierAccount {
+			blog.Errorf("CreateAPIToken failed, supplier account %s is not the creator's %s, rid: %s", supplierAccount,
+				kit.SupplierAccount, kit.Rid)
+			return result, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "bk_supplier_accounts")
+		}
+	}
+	supplierAccounts := []string{kit.SupplierAccount}
+	apiToken := metadata.APIToken{
+		ServiceAccountID: accountID,
+		Description:      option.Description,
+		TokenHash:        hash,
+		TokenPrefix:      metadata.APITokenDisplayPrefix(token),
+		SupplierAccounts: supplierAccounts,
+		BizIDs:           util.IntArrayUnique(option.BizIDs),
+		Scopes:           option.Scopes,
+		ExpireTime:       option.ExpireTime,
+		Creator:          kit.User,
+		CreateTime:       now,
+		LastTime:         now,
+		SupplierAccount:  kit.SupplierAccount,
+	}
+
+	id, err := s.dbProxy.NextSequence(kit.Ctx, common.BKTableNameAPIToken)
+	if err != nil {
+		blog.Errorf("CreateAPIToken failed, generate id failed, err: %+v, rid: %s", err, kit.Rid)
+		return result, kit.CCError.CCErrorf(common.CCErrCommGenerateRecordIDFailed)
+	}
+	apiToken.ID = int64(id)
+
+	if err := s.dbProxy.Table(common.BKTableNameAPIToken).Insert(kit.Ctx, apiToken); err != nil {
+		blog.Errorf("CreateAPIToken failed, db insert failed, service account: %d, err: %+v, rid: %s", accountID, err, kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+
+	result.Token = token
+	result.Info = apiToken
+	return result, nil
+}
+
+// RevokeAPIToken revoke the api token, a revoked token can not be used any more.
+func (s *serviceAccount) RevokeAPIToken(kit *rest.Kit, accountID int64, tokenID int64) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BkSupplierAccount:       kit.SupplierAccount,
+		common.BKServiceAccountIDField: accountID,
+		common.BKFieldID:               tokenID,
+	}
+	count, err := s.dbProxy.Table(common.BKTableNameAPIToken).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("RevokeAPIToken failed, db count failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count == 0 {
+		blog.Errorf("RevokeAPIToken failed, not found, filter: %+v, rid: %s", filter, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+
+	doc := map[string]interface{}{
+		"revoked":            true,
+		common.LastTimeField: time.Now(),
+	}
+	if err := s.dbProxy.Table(common.BKTableNameAPIToken).Update(kit.Ctx, filter, doc); err != nil {
+		blog.Errorf("RevokeAPIToken failed, db update failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+	return nil
+}
+
+func (s *serviceAccount) ListAPIToken(kit *rest.Kit, option metadata.ListAPITokenOption) (metadata.MultipleAPIToken, errors.CCErrorCoder) {
+	result := metadata.MultipleAPIToken{}
+	if option.Page.Limit > common.BKMaxPageSize && option.Page.Limit != common.BKNoLimit {
+		return result, kit.CCError.CCError(common.CCErrCommPageLimitIsExceeded)
+	}
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+	if option.ServiceAccountID != 0 {
+		filter[common.BKServiceAccountIDField] = option.ServiceAccountID
+	}
+	if len(option.IDs) != 0 {
+		filter[common.BKFieldID] = map[string]interface{}{
+			common.BKDBIN: option.IDs,
+		}
+	}
+	if option.Revoked != nil {
+		filter["revoked"] = *option.Revoked
+	}
+
+	query := s.dbProxy.Table(common.BKTableNameAPIToken).Find(filter)
+	total, err := query.Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("ListAPIToken failed, db count failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	result.Count = int64(total)
+
+	tokens := make([]metadata.APIToken, 0)
+	if err := pageQuery(query, option.Page).All(kit.Ctx, &tokens); err != nil {
+		blog.ErrorJSON("ListAPIToken failed, db select failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	result.Info = tokens
+	return result, nil
+}
+
+// ValidateAPIToken find the api token by it's hash, it's valid only when it's not expired or revoked
+// and it's service account is enabled. the token is found in all supplier accounts, the supplier accounts
+// it can be used with are checked by the caller.
+func (s *serviceAccount) ValidateAPIToken(kit *rest.Kit, option metadata.ValidateAPITokenOption) (metadata.ValidAPIToken, errors.CCErrorCoder) {
+	result := metadata.ValidAPIToken{}
+	if len(option.TokenHash) == 0 {
+		return result, kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, common.BKTokenHashField)
+	}
+
+	filter := map[string]interface{}{
+		common.BKTokenHashField: option.TokenHash,
+	}
+	if err := s.dbProxy.Table(common.BKTableNameAPIToken).Find(filter).One(kit.Ctx, &result.Token); err != nil {
+		if s.dbProxy.IsNotFoundError(err) {
+			return result, kit.CCError.CCError(common.CCErrCommAPITokenInvalid)
+		}
+		blog.Errorf("ValidateAPIToken failed, db select api token failed, err: %+v, rid: %s", err, kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	now := time.Now()
+	if !result.Token.IsValid(now) {
+		blog.Warnf("api token %d of service account %d is expired or revoked, rid: %s", result.Token.ID, result.Token.ServiceAccountID, kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommAPITokenInvalid)
+	}
+
+	filter = map[string]interface{}{
+		common.BkSupplierAccount: result.Token.SupplierAccount,
+		common.BKFieldID:         result.Token.ServiceAccountID,
+	}
+	if err := s.dbProxy.Table(common.BKTableNameServiceAccount).Find(filter).One(kit.Ctx, &result.ServiceAccount); err != nil {
+		if s.dbProxy.IsNotFoundError(err) {
+			return result, kit.CCError.CCError(common.CCErrCommAPITokenInvalid)
+		}
+		blog.Errorf("ValidateAPIToken failed, db select service account failed, filter: %+v, err: %+v, rid: %s", filter, err, kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if !result.ServiceAccount.Enabled {
+		blog.Warnf("service account %d of api token %d is disabled, rid: %s", result.ServiceAccount.ID, result.Token.ID, kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommAPITokenInvalid)
+	}
+
+	if now.Sub(result.Token.LastUsedTime) > lastUsedUpdateInterval {
+		tokenFilter := map[string]interface{}{common.BKFieldID: result.Token.ID}
+		doc := map[string]interface{}{"last_used_time": now}
+		if err := s.dbProxy.Table(common.BKTableNameAPIToken).Update(kit.Ctx, tokenFilter, doc); err != nil {
+			// the token is valid anyway, only log the failure.
+			blog.Warnf("update last used time of api token %d failed, err: %v, rid: %s", result.Token.ID, err, kit.Rid)
+		}
+		result.Token.LastUsedTime = now
+	}
+	return result, nil
+}
+
+func pageQuery(query types.Find, page metadata.BasePage) types.Find {
+	if len(page.Sort) > 0 {
+		query = query.Sort(page.Sort)
+	} else {
+		query = query.Sort("-" + common.BKFieldID)
+	}
+	if page.Limit > 0 {
+		query = query.Limit(uint64(page.Limit))
+	}
+	if page.Start > 0 {
+		query = query.Start(uint64(page.Start))
+	}
+	return query
+}
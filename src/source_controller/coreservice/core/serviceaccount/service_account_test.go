@@ -0,0 +1,153 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serviceaccount
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/mongo/local"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServiceAccount returns the service account operation with the mongodb of MONGOURI and MONGORS,
+// the test is skipped if the mongodb is not set.
+func newTestServiceAccount(t *testing.T) (*serviceAccount, dal.RDB) {
+	uri := os.Getenv("MONGOURI")
+	if uri == "" {
+		t.Skip("MONGOURI is not set, skip the test which needs mongodb")
+	}
+	db, err := local.NewMgo(local.MongoConf{
+		MaxOpenConns: 100,
+		MaxIdleConns: 10,
+		URI:          uri,
+		RsName:       os.Getenv("MONGORS"),
+	}, time.Minute)
+	require.NoError(t, err)
+	return &serviceAccount{dbProxy: db}, db
+}
+
+func newTestKit(t *testing.T, user string) *rest.Kit {
+	errIf, err := errors.NewFactory("../../../../../resources/errors/")
+	require.NoError(t, err)
+	return &rest.Kit{
+		Rid:             "test_req_id",
+		Header:          http.Header{},
+		Ctx:             context.Background(),
+		CCError:         errIf.CreateDefaultCCErrorIf("en"),
+		User:            user,
+		SupplierAccount: common.BKDefaultOwnerID,
+	}
+}
+
+func requireCCErrorCode(t *testing.T, err errors.CCErrorCoder, code int) {
+	require.Error(t, err)
+	require.Equal(t, code, err.GetCode(), err.Error())
+}
+
+func TestValidateAPIToken(t *testing.T) {
+	s, db := newTestServiceAccount(t)
+	kit := newTestKit(t, "admin")
+
+	name := fmt.Sprintf("robot_%d", time.Now().UnixNano())
+	account, ccErr := s.CreateServiceAccount(kit, metadata.CreateServiceAccountOption{Name: name})
+	require.NoError(t, ccErr)
+	defer s.DeleteServiceAccount(kit, account.ID)
+
+	newToken := func() metadata.CreateAPITokenResult {
+		option := metadata.CreateAPITokenOption{
+			Scopes:     []metadata.APITokenScope{metadata.APITokenScopeRead},
+			ExpireTime: time.Now().Add(time.Hour),
+		}
+		result, ccErr := s.CreateAPIToken(kit, account.ID, option)
+		require.NoError(t, ccErr)
+		return result
+	}
+	validate := func(token string) (metadata.ValidAPIToken, errors.CCErrorCoder) {
+		return s.ValidateAPIToken(kit, metadata.ValidateAPITokenOption{TokenHash: metadata.HashAPIToken(token)})
+	}
+
+	// a valid token is validated as it's service account.
+	valid := newToken()
+	result, ccErr := validate(valid.Token)
+	require.NoError(t, ccErr)
+	require.Equal(t, valid.Info.ID, result.Token.ID)
+	require.Equal(t, account.ID, result.ServiceAccount.ID)
+	require.Equal(t, []string{common.BKDefaultOwnerID}, result.Token.SupplierAccounts)
+
+	// an unknown token is invalid.
+	_, ccErr = validate(metadata.APITokenPrefix + "unknown")
+	requireCCErrorCode(t, ccErr, common.CCErrCommAPITokenInvalid)
+
+	// a revoked token is invalid.
+	revoked := newToken()
+	require.NoError(t, s.RevokeAPIToken(kit, account.ID, revoked.Info.ID))
+	_, ccErr = validate(revoked.Token)
+	requireCCErrorCode(t, ccErr, common.CCErrCommAPITokenInvalid)
+
+	// an expired token is invalid.
+	expired := newToken()
+	filter := map[string]interface{}{common.BKFieldID: expired.Info.ID}
+	doc := map[string]interface{}{"expire_time": time.Now().Add(-time.Minute)}
+	require.NoError(t, db.Table(common.BKTableNameAPIToken).Update(kit.Ctx, filter, doc))
+	_, ccErr = validate(expired.Token)
+	requireCCErrorCode(t, ccErr, common.CCErrCommAPITokenInvalid)
+
+	// all the tokens are invalid when the service account is disabled.
+	disabled := false
+	_, ccErr = s.UpdateServiceAccount(kit, account.ID, metadata.UpdateServiceAccountOption{Enabled: &disabled})
+	require.NoError(t, ccErr)
+	_, ccErr = validate(valid.Token)
+	requireCCErrorCode(t, ccErr, common.CCErrCommAPITokenInvalid)
+
+	// the token is required.
+	_, ccErr = s.ValidateAPIToken(kit, metadata.ValidateAPITokenOption{})
+	requireCCErrorCode(t, ccErr, common.CCErrCommParamsNeedSet)
+}
+
+func TestListServiceAccountByCreator(t *testing.T) {
+	s, _ := newTestServiceAccount(t)
+	adminKit := newTestKit(t, "admin")
+	otherKit := newTestKit(t, "other")
+
+	suffix := time.Now().UnixNano()
+	own, ccErr := s.CreateServiceAccount(adminKit, metadata.CreateServiceAccountOption{Name: fmt.Sprintf("own_%d", suffix)})
+	require.NoError(t, ccErr)
+	defer s.DeleteServiceAccount(adminKit, own.ID)
+	others, ccErr := s.CreateServiceAccount(otherKit, metadata.CreateServiceAccountOption{Name: fmt.Sprintf("others_%d", suffix)})
+	require.NoError(t, ccErr)
+	defer s.DeleteServiceAccount(otherKit, others.ID)
+
+	option := metadata.ListServiceAccountOption{IDs: []int64{own.ID, others.ID}, Creator: "admin"}
+	result, ccErr := s.ListServiceAccount(adminKit, option)
+	require.NoError(t, ccErr)
+	require.Equal(t, int64(1), result.Count)
+	require.Len(t, result.Info, 1)
+	require.Equal(t, own.ID, result.Info[0].ID)
+
+	// the core service lists all the service accounts without the creator, it's set by the api server.
+	option.Creator = ""
+	result, ccErr = s.ListServiceAccount(adminKit, option)
+	require.NoError(t, ccErr)
+	require.Equal(t, int64(2), result.Count)
+}
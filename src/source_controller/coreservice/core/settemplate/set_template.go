@@ -18,21 +18,26 @@ import (
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/errors"
+	"configcenter/src/common/eventclient"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/storage/dal"
+
+	"gopkg.in/redis.v5"
 )
 
 type setTemplateOperation struct {
-	dbProxy dal.RDB
+	dbProxy  dal.RDB
+	eventCli eventclient.Client
 }
 
 // New create a new model manager instance
-func New(dbProxy dal.RDB) core.SetTemplateOperation {
+func New(dbProxy dal.RDB, cache *redis.Client) core.SetTemplateOperation {
 	setTplOps := &setTemplateOperation{
-		dbProxy: dbProxy,
+		dbProxy:  dbProxy,
+		eventCli: eventclient.NewClientViaRedis(cache, dbProxy),
 	}
 	return setTplOps
 }
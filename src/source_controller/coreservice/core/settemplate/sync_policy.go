@@ -0,0 +1,92 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package settemplate
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/eventclient"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+const setTemplateSyncPolicyField = "sync_policy"
+
+// UpdateSetTemplateSyncPolicy replace the sync policy of the set template, an event is pushed when the policy is paused
+func (p *setTemplateOperation) UpdateSetTemplateSyncPolicy(kit *rest.Kit, bizID int64, setTemplateID int64,
+	policy metadata.SetTemplateSyncPolicy) (metadata.SetTemplate, errors.CCErrorCoder) {
+
+	if key, err := policy.Validate(); err != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, policy: %+v, key: %s, err: %v, rid: %s", policy, key, err, kit.Rid)
+		return metadata.SetTemplate{}, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	origin, ccErr := p.GetSetTemplate(kit, bizID, setTemplateID)
+	if ccErr != nil {
+		return origin, ccErr
+	}
+
+	filter := map[string]interface{}{
+		common.BKFieldID:    setTemplateID,
+		common.BKAppIDField: bizID,
+	}
+	filter = util.SetModOwner(filter, kit.SupplierAccount)
+	doc := map[string]interface{}{
+		setTemplateSyncPolicyField: policy,
+	}
+	if err := p.dbProxy.Table(common.BKTableNameSetTemplate).Update(kit.Ctx, filter, doc); err != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return origin, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	current := origin
+	current.SyncPolicy = &policy
+	if policy.Paused && (origin.SyncPolicy == nil || !origin.SyncPolicy.Paused) {
+		event := eventclient.NewEventWithHeader(kit.Header)
+		event.EventType = metadata.EventTypeInstData
+		event.ObjType = metadata.SetTemplateSyncPolicyEventType
+		event.Action = metadata.EventActionUpdate
+		event.Data = []metadata.EventData{{PreData: origin, CurData: current}}
+		if err := p.eventCli.Push(kit.Ctx, event); err != nil {
+			blog.Errorf("push set template %d sync policy paused event failed, err: %v, rid: %s", setTemplateID, err,
+				kit.Rid)
+		}
+	}
+	return current, nil
+}
+
+// ListSetTemplateSyncPolicy list the set templates with the sync policy types among all the businesses,
+// it's used by the sync policy scheduler.
+func (p *setTemplateOperation) ListSetTemplateSyncPolicy(kit *rest.Kit, option metadata.ListSetTemplateSyncPolicyOption) (
+	[]metadata.SetTemplate, errors.CCErrorCoder) {
+
+	filter := map[string]interface{}{
+		setTemplateSyncPolicyField: map[string]interface{}{
+			common.BKDBExists: true,
+		},
+	}
+	if len(option.Types) > 0 {
+		filter[setTemplateSyncPolicyField+".type"] = map[string]interface{}{
+			common.BKDBIN: option.Types,
+		}
+	}
+
+	setTemplates := make([]metadata.SetTemplate, 0)
+	if err := p.dbProxy.Table(common.BKTableNameSetTemplate).Find(filter).All(kit.Ctx, &setTemplates); err != nil {
+		blog.ErrorJSON("ListSetTemplateSyncPolicy failed, db select failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return setTemplates, nil
+}
@@ -0,0 +1,269 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trash
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/apimachinery/discovery"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+)
+
+// purgeInterval is the interval to check and purge the trash items which exceed the retention period.
+const purgeInterval = time.Hour
+
+var _ core.TrashOperation = (*trashBin)(nil)
+
+type trashBin struct {
+	dbProxy   dal.RDB
+	objects   map[string]bool
+	retention time.Duration
+}
+
+// New create a new trash bin manager instance, the expired trash items are purged by the master.
+func New(dbProxy dal.RDB, objects []string, retentionDays int, isMaster discovery.ServiceManageInterface) core.TrashOperation {
+	t := &trashBin{
+		dbProxy:   dbProxy,
+		objects:   make(map[string]bool),
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+	}
+	for _, objID := range objects {
+		t.objects[objID] = true
+	}
+
+	if len(t.objects) > 0 {
+		blog.Infof("objects %v are opted into the trash bin, retention days: %d", objects, retentionDays)
+	}
+	go t.purgeExpiredTrash(isMaster)
+	return t
+}
+
+func (t *trashBin) IsEnabled(objID string) bool {
+	return t.objects[objID]
+}
+
+func (t *trashBin) AddTrash(kit *rest.Kit, objID string, instances []mapstr.MapStr) errors.CCErrorCoder {
+	if !t.IsEnabled(objID) || len(instances) == 0 {
+		return nil
+	}
+
+	idField := common.GetInstIDField(objID)
+	nameField := common.GetInstNameField(objID)
+	if objID == common.BKInnerObjIDHost {
+		nameField = common.BKHostInnerIPField
+	}
+
+	now := time.Now()
+	items := make([]metadata.TrashItem, 0)
+	instIDs := make([]int64, 0)
+	for _, instance := range instances {
+		// the mainline instances can't be restored without their topology, they are not kept in the trash bin.
+		if _, exists := instance[common.BKParentIDField]; exists {
+			continue
+		}
+
+		instID, err := util.GetInt64ByInterface(instance[idField])
+		if err != nil {
+			blog.ErrorJSON("AddTrash failed, parse instance id failed, instance: %s, err: %s, rid: %s", instance, err, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommInstFieldConvertFail, objID, idField, "integer", err.Error())
+		}
+
+		detail := instance.Clone()
+		detail.Remove("_id")
+		items = append(items, metadata.TrashItem{
+			ObjectID:        objID,
+			InstID:          instID,
+			InstName:        util.GetStrByInterface(instance[nameField]),
+			Detail:          detail,
+			Associations:    make([]metadata.InstAsst, 0),
+			Operator:        kit.User,
+			DeleteTime:      now,
+			SupplierAccount: kit.SupplierAccount,
+		})
+		instIDs = append(instIDs, instID)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	// the associations are deleted with the instances, keep them so that they can be restored too.
+	asstFilter := map[string]interface{}{
+		common.BKDBOR: []map[string]interface{}{
+			{
+				common.BKObjIDField:  objID,
+				common.BKInstIDField: map[string]interface{}{common.BKDBIN: instIDs},
+			},
+			{
+				common.BKAsstObjIDField:  objID,
+				common.BKAsstInstIDField: map[string]interface{}{common.BKDBIN: instIDs},
+			},
+		},
+	}
+	associations := make([]metadata.InstAsst, 0)
+	if err := t.dbProxy.Table(common.BKTableNameInstAsst).Find(asstFilter).All(kit.Ctx, &associations); err != nil {
+		blog.ErrorJSON("AddTrash failed, find associations failed, filter: %s, err: %s, rid: %s", asstFilter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	for idx := range items {
+		for _, asst := range associations {
+			if (asst.ObjectID == objID && asst.InstID == items[idx].InstID) ||
+				(asst.AsstObjectID == objID && asst.AsstInstID == items[idx].InstID) {
+				items[idx].Associations = append(items[idx].Associations, asst)
+			}
+		}
+
+		id, err := t.dbProxy.NextSequence(kit.Ctx, common.BKTableNameTrashBin)
+		if err != nil {
+			blog.Errorf("AddTrash failed, generate id failed, err: %+v, rid: %s", err, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommGenerateRecordIDFailed)
+		}
+		items[idx].ID = int64(id)
+	}
+
+	if err := t.dbProxy.Table(common.BKTableNameTrashBin).Insert(kit.Ctx, items); err != nil {
+		blog.Errorf("AddTrash failed, db insert failed, object: %s, instances: %v, err: %+v, rid: %s", objID, instIDs, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+	return nil
+}
+
+func (t *trashBin) ListTrash(kit *rest.Kit, objID string, option metadata.ListTrashOption) (metadata.MultipleTrashItem, errors.CCErrorCoder) {
+	result := metadata.MultipleTrashItem{Info: make([]metadata.TrashItem, 0)}
+	if option.Page.Limit > common.BKMaxPageSize && option.Page.Limit != common.BKNoLimit {
+		return result, kit.CCError.CCError(common.CCErrCommPageLimitIsExceeded)
+	}
+
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKObjIDField:      objID,
+	}
+	if len(option.IDs) != 0 {
+		filter[common.BKFieldID] = map[string]interface{}{
+			common.BKDBIN: option.IDs,
+		}
+	}
+	if len(option.InstIDs) != 0 {
+		filter[common.BKInstIDField] = map[string]interface{}{
+			common.BKDBIN: option.InstIDs,
+		}
+	}
+
+	query := t.dbProxy.Table(common.BKTableNameTrashBin).Find(filter)
+	total, err := query.Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("ListTrash failed, db count failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	result.Count = int64(total)
+
+	if len(option.Page.Sort) > 0 {
+		query = query.Sort(option.Page.Sort)
+	} else {
+		query = query.Sort("-" + common.BKFieldID)
+	}
+	if option.Page.Limit > 0 {
+		query = query.Limit(uint64(option.Page.Limit))
+	}
+	if option.Page.Start > 0 {
+		query = query.Start(uint64(option.Page.Start))
+	}
+	if err := query.All(kit.Ctx, &result.Info); err != nil {
+		blog.ErrorJSON("ListTrash failed, db select failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return result, nil
+}
+
+func (t *trashBin) DeleteTrash(kit *rest.Kit, objID string, ids []int64) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount,
+		common.BKObjIDField:      objID,
+		common.BKFieldID: map[string]interface{}{
+			common.BKDBIN: ids,
+		},
+	}
+	if err := t.dbProxy.Table(common.BKTableNameTrashBin).Delete(kit.Ctx, filter); err != nil {
+		blog.ErrorJSON("DeleteTrash failed, db delete failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
+
+// purgeExpiredTrash purge the trash items which are deleted before the retention period, the trash items of the
+// objects which are no longer opted into the trash bin are purged as well after the retention period.
+func (t *trashBin) purgeExpiredTrash(isMaster discovery.ServiceManageInterface) {
+	for {
+		time.Sleep(purgeInterval)
+		if !isMaster.IsMaster() {
+			continue
+		}
+
+		rid := util.GenerateRID()
+		ctx := context.WithValue(context.Background(), common.ContextRequestIDField, rid)
+		if err := t.purge(ctx, time.Now()); err != nil {
+			blog.Errorf("purge expired trash failed, err: %v, rid: %s", err, rid)
+			continue
+		}
+		blog.V(4).Infof("purge the trash items deleted before %d days done, rid: %s", int(t.retention.Hours()/24), rid)
+	}
+}
+
+// purge delete the trash items which are deleted before the retention period till now.
+func (t *trashBin) purge(ctx context.Context, now time.Time) error {
+	filter := map[string]interface{}{
+		common.BKDeleteTimeField: map[string]interface{}{
+			common.BKDBLT: now.Add(-t.retention),
+		},
+	}
+	return t.dbProxy.Table(common.BKTableNameTrashBin).Delete(ctx, filter)
+}
+
+// RestoreAssociations restore the associations of the restored trash items, the associations whose association
+// model or the other end instance no longer exists are skipped and returned in the result.
+func RestoreAssociations(kit *rest.Kit, asstOp core.AssociationOperation, objID string,
+	items []metadata.TrashItem) metadata.RestoreTrashResult {
+
+	result := metadata.RestoreTrashResult{Restored: make([]metadata.RestoredTrashItem, 0)}
+	for _, item := range items {
+		restored := metadata.RestoredTrashItem{
+			ID:                  item.ID,
+			InstID:              item.InstID,
+			SkippedAssociations: make([]metadata.InstAsst, 0),
+		}
+		for _, asst := range item.Associations {
+			_, err := asstOp.CreateOneInstanceAssociation(kit, metadata.CreateOneInstanceAssociation{Data: asst})
+			if err == nil {
+				continue
+			}
+			// the association between two restored instances is kept in both of their trash items.
+			if ccErr, ok := err.(errors.CCErrorCoder); ok && ccErr.GetCode() == common.CCErrCommDuplicateItem {
+				continue
+			}
+			blog.Warnf("RestoreAssociations skip association %+v of %s instance %d, err: %v, rid: %s", asst, objID,
+				item.InstID, err, kit.Rid)
+			restored.SkippedAssociations = append(restored.SkippedAssociations, asst)
+		}
+		result.Restored = append(result.Restored, restored)
+	}
+	return result
+}
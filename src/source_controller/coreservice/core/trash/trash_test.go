@@ -0,0 +1,184 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package trash
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/mongo/local"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/require"
+)
+
+var defaultKit = func() *rest.Kit {
+	errIf, _ := errors.NewFactory("../../../../../resources/errors/")
+	return &rest.Kit{
+		Rid:             "test_req_id",
+		Header:          http.Header{},
+		Ctx:             context.Background(),
+		CCError:         errIf.CreateDefaultCCErrorIf("en"),
+		User:            "test_user",
+		SupplierAccount: "test_owner",
+	}
+}()
+
+// newDB returns the mongodb of MONGOURI and MONGORS, the test is skipped if the mongodb is not set.
+func newDB(t *testing.T) dal.RDB {
+	uri := os.Getenv("MONGOURI")
+	if uri == "" {
+		t.Skip("MONGOURI is not set, skip the test which needs mongodb")
+	}
+	db, err := local.NewMgo(local.MongoConf{
+		MaxOpenConns: 100,
+		MaxIdleConns: 10,
+		URI:          uri,
+		RsName:       os.Getenv("MONGORS"),
+	}, time.Minute)
+	require.NoError(t, err)
+	return db
+}
+
+// mockAssociation creates the instance associations except the ones whose association id is in the errors.
+type mockAssociation struct {
+	core.AssociationOperation
+	errors  map[string]error
+	created []metadata.InstAsst
+}
+
+func (m *mockAssociation) CreateOneInstanceAssociation(kit *rest.Kit, inputParam metadata.CreateOneInstanceAssociation) (
+	*metadata.CreateOneDataResult, error) {
+
+	if err, exists := m.errors[inputParam.Data.ObjectAsstID]; exists {
+		return nil, err
+	}
+	m.created = append(m.created, inputParam.Data)
+	return &metadata.CreateOneDataResult{Created: metadata.CreatedDataResult{ID: uint64(len(m.created))}}, nil
+}
+
+func TestRestoreAssociations(t *testing.T) {
+	hostToSwitch := metadata.InstAsst{ObjectAsstID: "bk_host_connect_bk_switch", ObjectID: "bk_host", InstID: 1,
+		AsstObjectID: "bk_switch", AsstInstID: 2}
+	switchToRouter := metadata.InstAsst{ObjectAsstID: "bk_switch_connect_bk_router", ObjectID: "bk_switch", InstID: 2,
+		AsstObjectID: "bk_router", AsstInstID: 3}
+	switchToFirewall := metadata.InstAsst{ObjectAsstID: "bk_switch_connect_bk_firewall", ObjectID: "bk_switch",
+		InstID: 2, AsstObjectID: "bk_firewall", AsstInstID: 4}
+	switchToSwitch := metadata.InstAsst{ObjectAsstID: "bk_switch_connect_bk_switch", ObjectID: "bk_switch", InstID: 2,
+		AsstObjectID: "bk_switch", AsstInstID: 5}
+
+	asstOp := &mockAssociation{
+		errors: map[string]error{
+			// the association model is deleted after the instance is deleted
+			switchToFirewall.ObjectAsstID: errors.NewCCError(common.CCErrorTopoObjectAssociationNotExist, "not exist"),
+			// the association is restored with the other end instance which is restored together
+			switchToSwitch.ObjectAsstID: errors.NewCCError(common.CCErrCommDuplicateItem, "duplicate"),
+		},
+	}
+	items := []metadata.TrashItem{
+		{ID: 10, InstID: 2, Associations: []metadata.InstAsst{hostToSwitch, switchToRouter, switchToFirewall,
+			switchToSwitch}},
+		{ID: 11, InstID: 6, Associations: []metadata.InstAsst{}},
+	}
+
+	result := RestoreAssociations(defaultKit, asstOp, "bk_switch", items)
+	require.Equal(t, []metadata.InstAsst{hostToSwitch, switchToRouter}, asstOp.created)
+	require.Equal(t, []metadata.RestoredTrashItem{
+		{ID: 10, InstID: 2, SkippedAssociations: []metadata.InstAsst{switchToFirewall}},
+		{ID: 11, InstID: 6, SkippedAssociations: []metadata.InstAsst{}},
+	}, result.Restored)
+}
+
+func TestAddTrash(t *testing.T) {
+	db := newDB(t)
+	bin := &trashBin{dbProxy: db, objects: map[string]bool{"bk_switch": true}, retention: 24 * time.Hour}
+	kit := *defaultKit
+	kit.SupplierAccount = xid.New().String()
+
+	asst := metadata.InstAsst{ID: time.Now().UnixNano(), ObjectAsstID: "bk_switch_connect_bk_router",
+		ObjectID: "bk_switch", InstID: 1, AsstObjectID: "bk_router", AsstInstID: 2, OwnerID: kit.SupplierAccount}
+	require.NoError(t, db.Table(common.BKTableNameInstAsst).Insert(kit.Ctx, asst))
+	defer db.Table(common.BKTableNameInstAsst).Delete(kit.Ctx, map[string]interface{}{
+		common.BkSupplierAccount: kit.SupplierAccount})
+
+	// the objects which are not opted into the trash bin are ignored
+	require.NoError(t, bin.AddTrash(&kit, "bk_router", []mapstr.MapStr{{common.BKInstIDField: 2}}))
+	instances := []mapstr.MapStr{
+		{common.BKInstIDField: 1, common.BKInstNameField: "switch1"},
+		// the mainline instances are not kept
+		{common.BKInstIDField: 3, common.BKInstNameField: "switch3", common.BKParentIDField: 1},
+	}
+	require.NoError(t, bin.AddTrash(&kit, "bk_switch", instances))
+
+	result, err := bin.ListTrash(&kit, "bk_switch", metadata.ListTrashOption{Page: metadata.BasePage{Limit: 10}})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Count)
+	require.Equal(t, int64(1), result.Info[0].InstID)
+	require.Equal(t, "switch1", result.Info[0].InstName)
+	require.Len(t, result.Info[0].Associations, 1)
+	require.Equal(t, asst.ObjectAsstID, result.Info[0].Associations[0].ObjectAsstID)
+	defer bin.DeleteTrash(&kit, "bk_switch", []int64{result.Info[0].ID})
+
+	result, err = bin.ListTrash(&kit, "bk_router", metadata.ListTrashOption{Page: metadata.BasePage{Limit: 10}})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), result.Count)
+}
+
+func TestPurgeExpiredTrash(t *testing.T) {
+	db := newDB(t)
+	bin := &trashBin{dbProxy: db, objects: map[string]bool{"bk_switch": true}, retention: 7 * 24 * time.Hour}
+	kit := *defaultKit
+	kit.SupplierAccount = xid.New().String()
+
+	now := time.Now()
+	items := []metadata.TrashItem{
+		{InstID: 1, DeleteTime: now.Add(-8 * 24 * time.Hour)},
+		{InstID: 2, DeleteTime: now.Add(-6 * 24 * time.Hour)},
+		{InstID: 3, DeleteTime: now},
+	}
+	for idx := range items {
+		id, err := db.NextSequence(kit.Ctx, common.BKTableNameTrashBin)
+		require.NoError(t, err)
+		items[idx].ID = int64(id)
+		items[idx].ObjectID = "bk_switch"
+		items[idx].SupplierAccount = kit.SupplierAccount
+	}
+	require.NoError(t, db.Table(common.BKTableNameTrashBin).Insert(kit.Ctx, items))
+	defer bin.DeleteTrash(&kit, "bk_switch", []int64{items[0].ID, items[1].ID, items[2].ID})
+
+	require.NoError(t, bin.purge(kit.Ctx, now))
+	result, err := bin.ListTrash(&kit, "bk_switch", metadata.ListTrashOption{
+		Page: metadata.BasePage{Limit: 10, Sort: common.BKInstIDField},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), result.Count)
+	require.Equal(t, int64(2), result.Info[0].InstID)
+	require.Equal(t, int64(3), result.Info[1].InstID)
+
+	// the items are purged once they exceed the retention period
+	require.NoError(t, bin.purge(kit.Ctx, now.Add(2*24*time.Hour)))
+	result, err = bin.ListTrash(&kit, "bk_switch", metadata.ListTrashOption{Page: metadata.BasePage{Limit: 10}})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Count)
+	require.Equal(t, int64(3), result.Info[0].InstID)
+}
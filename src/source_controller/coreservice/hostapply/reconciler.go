@@ -0,0 +1,133 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostapply
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/discovery"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/reflector"
+	"configcenter/src/storage/stream/types"
+
+	"github.com/tidwall/gjson"
+)
+
+// NewReconciler watch the host changes, and reconcile the changed hosts' attributes with the host apply rules,
+// so that the attributes changed by users or data collection after the rules are applied can be detected.
+func NewReconciler(event reflector.Interface, db dal.DB, hostApply core.HostApplyRuleOperation,
+	isMaster discovery.ServiceManageInterface) error {
+
+	r := &reconciler{
+		event:     event,
+		db:        db,
+		hostApply: hostApply,
+		isMaster:  isMaster,
+	}
+	return r.Run()
+}
+
+type reconciler struct {
+	event     reflector.Interface
+	db        dal.DB
+	hostApply core.HostApplyRuleOperation
+	isMaster  discovery.ServiceManageInterface
+}
+
+func (r *reconciler) Run() error {
+	opts := &types.WatchOptions{
+		Options: types.Options{
+			EventStruct: new(map[string]interface{}),
+			Collection:  common.BKTableNameBaseHost,
+		},
+	}
+	watchCap := &reflector.Capable{
+		OnChange: reflector.OnChangeEvent{
+			OnAdd:    r.onUpsert,
+			OnUpdate: r.onUpsert,
+			OnDelete: r.onDelete,
+		},
+	}
+	blog.Info("start host apply reconciler with watcher")
+	return r.event.Watcher(context.Background(), opts, watchCap)
+}
+
+func (r *reconciler) onUpsert(e *types.Event) {
+	if !r.isMaster.IsMaster() {
+		blog.V(4).Infof("received host upsert event, oid: %s, but not master, skip", e.Oid)
+		return
+	}
+
+	elements := gjson.GetManyBytes(e.DocBytes, common.BKHostIDField, common.BkSupplierAccount)
+	hostID := elements[0].Int()
+	if hostID <= 0 {
+		blog.Errorf("received host upsert event, but got invalid host id, doc: %s", e.DocBytes)
+		return
+	}
+
+	kit := newKit(elements[1].String())
+	if err := r.hostApply.ReconcileHostApply(kit, hostID); err != nil {
+		blog.Errorf("reconcile host %d with host apply rules failed, err: %v, rid: %s", hostID, err, kit.Rid)
+		return
+	}
+	blog.V(4).Infof("reconcile host %d with host apply rules success, rid: %s", hostID, kit.Rid)
+}
+
+func (r *reconciler) onDelete(e *types.Event) {
+	if !r.isMaster.IsMaster() {
+		blog.V(4).Infof("received host delete event, oid: %s, but not master, skip", e.Oid)
+		return
+	}
+
+	filter := mapstr.MapStr{
+		"oid": e.Oid,
+	}
+	doc := struct {
+		Detail struct {
+			HostID          int64  `bson:"bk_host_id"`
+			SupplierAccount string `bson:"bk_supplier_account"`
+		} `bson:"detail"`
+	}{}
+	if err := r.db.Table(common.BKTableNameDelArchive).Find(filter).One(context.Background(), &doc); err != nil {
+		blog.Errorf("received host delete event, but get archive deleted doc failed, oid: %s, err: %v", e.Oid, err)
+		return
+	}
+
+	kit := newKit(doc.Detail.SupplierAccount)
+	if err := r.hostApply.RemoveHostApplyDrift(kit, doc.Detail.HostID); err != nil {
+		blog.Errorf("remove deleted host %d's host apply drifts failed, err: %v, rid: %s", doc.Detail.HostID, err, kit.Rid)
+	}
+}
+
+func newKit(supplierAccount string) *rest.Kit {
+	header := make(http.Header)
+	header.Set(common.BKHTTPHeaderUser, common.CCSystemOperatorUserName)
+	header.Set(common.BKHTTPOwnerID, supplierAccount)
+	header.Set(common.BKHTTPCCRequestID, util.GenerateRID())
+
+	return &rest.Kit{
+		Rid:             util.GetHTTPCCRequestID(header),
+		Header:          header,
+		Ctx:             util.NewContextFromHTTPHeader(header),
+		CCError:         util.GetDefaultCCError(header),
+		User:            common.CCSystemOperatorUserName,
+		SupplierAccount: supplierAccount,
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+func (s *coreService) CreateBusinessArchive(ctx *rest.Contexts) {
+	archive := metadata.BusinessArchive{}
+	if err := ctx.DecodeInto(&archive); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.BusinessArchiveOperation().CreateBusinessArchive(ctx.Kit, archive)
+	if err != nil {
+		blog.Errorf("CreateBusinessArchive failed, bizID: %d, err: %+v, rid: %s", archive.BizID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) UpdateBusinessArchive(ctx *rest.Contexts) {
+	archiveID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKFieldID))
+		return
+	}
+
+	option := metadata.UpdateBusinessArchiveOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.BusinessArchiveOperation().UpdateBusinessArchive(ctx.Kit, archiveID, option)
+	if err != nil {
+		blog.Errorf("UpdateBusinessArchive failed, id: %d, status: %s, err: %+v, rid: %s", archiveID, option.Status, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) ListBusinessArchive(ctx *rest.Contexts) {
+	option := metadata.ListBusinessArchiveOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.BusinessArchiveOperation().ListBusinessArchive(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("ListBusinessArchive failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
@@ -16,8 +16,10 @@ import (
 	"strconv"
 
 	"configcenter/src/common"
+	"configcenter/src/common/blog"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/coreservice/cache/instance"
 	"configcenter/src/source_controller/coreservice/cache/topo_tree"
 )
 
@@ -93,6 +95,67 @@ func (s *coreService) ListHostWithHostIDInCache(ctx *rest.Contexts) {
 	ctx.RespStringArray(host)
 }
 
+// SearchInstWithInstIDInCache get the instance of the object which is opted into the cache with instance id.
+func (s *coreService) SearchInstWithInstIDInCache(ctx *rest.Contexts) {
+	opt := new(metadata.SearchInstWithIDOption)
+	if err := ctx.DecodeInto(&opt); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+	if len(opt.ObjID) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, common.BKObjIDField))
+		return
+	}
+	if !s.cacheSet.Instance.IsCached(opt.ObjID) {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKObjIDField))
+		return
+	}
+
+	inst, err := s.cacheSet.Instance.GetInstWithID(ctx.Kit.Ctx, ctx.Kit.SupplierAccount, opt)
+	if err != nil {
+		if err == instance.ErrInstNotFound {
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+			return
+		}
+		ctx.RespErrorCodeOnly(common.CCErrCommDBSelectFailed, "search instance with id in cache, but get instance failed, err: %v", err)
+		return
+	}
+	ctx.RespString(inst)
+}
+
+// SearchInstWithUniqueKeyInCache get the instance of the object which is opted into the cache with
+// the values of one of the object's unique rules.
+func (s *coreService) SearchInstWithUniqueKeyInCache(ctx *rest.Contexts) {
+	opt := new(metadata.SearchInstWithUniqueKeyOption)
+	if err := ctx.DecodeInto(&opt); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+	if len(opt.ObjID) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, common.BKObjIDField))
+		return
+	}
+	if len(opt.Keys) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "keys"))
+		return
+	}
+	if !s.cacheSet.Instance.IsCached(opt.ObjID) {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKObjIDField))
+		return
+	}
+
+	inst, err := s.cacheSet.Instance.GetInstWithUniqueKey(ctx.Kit.Ctx, ctx.Kit.SupplierAccount, opt)
+	if err != nil {
+		if err == instance.ErrNotUniqueKey {
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "keys"))
+			return
+		}
+		ctx.RespErrorCodeOnly(common.CCErrCommDBSelectFailed, "search instance with unique key in cache, but get instance failed, err: %v", err)
+		return
+	}
+	ctx.RespString(inst)
+}
+
 func (s *coreService) SearchBusinessInCache(ctx *rest.Contexts) {
 	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
 	biz, err := s.cacheSet.Business.GetBusiness(bizID)
@@ -133,3 +196,25 @@ func (s *coreService) SearchCustomLayerInCache(ctx *rest.Contexts) {
 	}
 	ctx.RespString(inst)
 }
+
+// CheckCacheConsistency compares the cached keys with the data in db, and repairs the mismatched keys if required.
+func (s *coreService) CheckCacheConsistency(ctx *rest.Contexts) {
+	opt := new(metadata.CheckCacheOption)
+	if err := ctx.DecodeInto(opt); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if key, err := opt.Validate(); err != nil {
+		blog.Errorf("check cache consistency, but option is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key))
+		return
+	}
+
+	results, err := s.cacheChecker.Check(ctx.Kit.Ctx, opt)
+	if err != nil {
+		ctx.RespErrorCodeOnly(common.CCErrCommDBSelectFailed, "check cache consistency failed, err: %v", err)
+		return
+	}
+	ctx.RespEntity(results)
+}
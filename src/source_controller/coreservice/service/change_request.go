@@ -0,0 +1,92 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+func (s *coreService) CreateChangeRequest(ctx *rest.Contexts) {
+	option := metadata.CreateChangeRequestOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ChangeRequestOperation().CreateChangeRequest(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("CreateChangeRequest failed, resource: %s, action: %s, err: %+v, rid: %s", option.ResourceType, option.Action, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) GetChangeRequest(ctx *rest.Contexts) {
+	requestID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKFieldID))
+		return
+	}
+
+	result, err := s.core.ChangeRequestOperation().GetChangeRequest(ctx.Kit, requestID)
+	if err != nil {
+		blog.Errorf("GetChangeRequest failed, id: %d, err: %+v, rid: %s", requestID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) UpdateChangeRequestStatus(ctx *rest.Contexts) {
+	requestID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKFieldID))
+		return
+	}
+
+	option := metadata.UpdateChangeRequestStatusOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ChangeRequestOperation().UpdateChangeRequestStatus(ctx.Kit, requestID, option)
+	if err != nil {
+		blog.Errorf("UpdateChangeRequestStatus failed, id: %d, status: %s, err: %+v, rid: %s", requestID, option.Status, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) ListChangeRequest(ctx *rest.Contexts) {
+	option := metadata.ListChangeRequestOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ChangeRequestOperation().ListChangeRequest(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("ListChangeRequest failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
@@ -0,0 +1,281 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"io"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/json"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// ExportInstances exports the object's instances as a stream in the order of the instance ids
+func (s *coreService) ExportInstances(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	option := metadata.ExportOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	permission, err := s.exportFieldPermission(ctx.Kit, objID, &option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	instIDField := common.GetInstIDField(objID)
+	cond, err := exportCondition(ctx.Kit, option, instIDField)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if common.GetInstTableName(objID) == common.BKTableNameBaseInst {
+		cond[common.BKObjIDField] = objID
+	}
+
+	find := s.db.Table(common.GetInstTableName(objID)).Find(cond).Sort(instIDField)
+	if len(option.Fields) > 0 {
+		find = find.Fields(append(option.Fields, instIDField)...)
+	}
+	ctx.RespStream(metadata.ExportContentType, func(w io.Writer) error {
+		inst := make(map[string]interface{})
+		return find.Iterate(ctx.Kit.Ctx, &inst, func() error {
+			permission.FilterData(inst)
+			return writeExportLine(w, inst)
+		})
+	})
+}
+
+// ExportHosts exports the hosts with their topology as a stream in the order of the host ids
+func (s *coreService) ExportHosts(ctx *rest.Contexts) {
+	option := metadata.ExportOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	permission, err := s.exportFieldPermission(ctx.Kit, common.BKInnerObjIDHost, &option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	cond, err := exportCondition(ctx.Kit, option, common.BKHostIDField)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	find := s.db.Table(common.BKTableNameBaseHost).Find(cond).Sort(common.BKHostIDField)
+	if len(option.Fields) > 0 {
+		find = find.Fields(append(option.Fields, common.BKHostIDField)...)
+	}
+	ctx.RespStream(metadata.ExportContentType, func(w io.Writer) error {
+		// the topology of the hosts are read in batches, so that the memory is bounded by the batch size
+		hosts := make([]map[string]interface{}, 0, metadata.ExportBatchSize)
+		host := make(map[string]interface{})
+		err := find.Iterate(ctx.Kit.Ctx, &host, func() error {
+			permission.FilterData(host)
+			hosts = append(hosts, host)
+			if len(hosts) < metadata.ExportBatchSize {
+				return nil
+			}
+			err := s.writeExportHosts(ctx.Kit, w, hosts)
+			hosts = hosts[:0]
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return s.writeExportHosts(ctx.Kit, w, hosts)
+	})
+}
+
+func (s *coreService) writeExportHosts(kit *rest.Kit, w io.Writer, hosts []map[string]interface{}) error {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	hostIDs := make([]int64, len(hosts))
+	for idx, host := range hosts {
+		hostID, err := util.GetInt64ByInterface(host[common.BKHostIDField])
+		if err != nil {
+			return err
+		}
+		hostIDs[idx] = hostID
+	}
+
+	relations := make([]metadata.ModuleHost, 0)
+	relationCond := map[string]interface{}{common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs}}
+	relationCond = util.SetQueryOwner(relationCond, kit.SupplierAccount)
+	if err := s.db.Table(common.BKTableNameModuleHostConfig).Find(relationCond).All(kit.Ctx, &relations); err != nil {
+		blog.Errorf("export hosts, but get host relations failed, err: %v, rid: %s", err, kit.Rid)
+		return err
+	}
+	topo := make(map[int64][]metadata.ExportHostTopo)
+	for _, relation := range relations {
+		topo[relation.HostID] = append(topo[relation.HostID], metadata.ExportHostTopo{
+			BizID:    relation.AppID,
+			SetID:    relation.SetID,
+			ModuleID: relation.ModuleID,
+		})
+	}
+
+	for idx, host := range hosts {
+		line := metadata.ExportHost{Host: host, Topo: topo[hostIDs[idx]]}
+		if line.Topo == nil {
+			line.Topo = make([]metadata.ExportHostTopo, 0)
+		}
+		if err := writeExportLine(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportInstanceAssociations exports the instance associations of the object as a stream in the order of
+// the association ids, both the associations from and to the object's instances are exported.
+func (s *coreService) ExportInstanceAssociations(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	option := metadata.ExportOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	objCond := map[string]interface{}{common.BKDBOR: []map[string]interface{}{
+		{common.BKObjIDField: objID},
+		{common.BKAsstObjIDField: objID},
+	}}
+	cond, err := exportCondition(ctx.Kit, option, common.BKFieldID, objCond)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	find := s.db.Table(common.BKTableNameInstAsst).Find(cond).Sort(common.BKFieldID)
+	if len(option.Fields) > 0 {
+		find = find.Fields(append(option.Fields, common.BKFieldID)...)
+	}
+	ctx.RespStream(metadata.ExportContentType, func(w io.Writer) error {
+		association := make(map[string]interface{})
+		return find.Iterate(ctx.Kit.Ctx, &association, func() error {
+			return writeExportLine(w, association)
+		})
+	})
+}
+
+// exportFieldPermission returns the field permissions of the request user on the exported object, the fields
+// which can not be read are removed from the exported fields, and the filter can not use them.
+func (s *coreService) exportFieldPermission(kit *rest.Kit, objID string, option *metadata.ExportOption) (
+	*metadata.ObjectFieldPermission, errors.CCErrorCoder) {
+
+	attrCond := map[string]interface{}{
+		common.BKObjIDField: objID,
+		metadata.AttributeFieldPermission: map[string]interface{}{
+			common.BKDBExists: true,
+			common.BKDBNE:     nil,
+		},
+	}
+	attrCond = util.SetQueryOwner(attrCond, kit.SupplierAccount)
+	attrs := make([]metadata.Attribute, 0)
+	if err := s.db.Table(common.BKTableNameObjAttDes).Find(attrCond).All(kit.Ctx, &attrs); err != nil {
+		blog.Errorf("export %s, but get attributes with permission failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+
+	roleOption := metadata.ListPermissionRoleOption{
+		Member: kit.User,
+		Page:   metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	roles, err := s.core.PermissionRoleOperation().ListPermissionRole(kit, roleOption)
+	if err != nil {
+		blog.Errorf("export %s, but list the permission roles of user %s failed, err: %v, rid: %s", objID, kit.User, err, kit.Rid)
+		return nil, err
+	}
+	roleNames := make([]string, 0, len(roles.Info))
+	for _, role := range roles.Info {
+		roleNames = append(roleNames, role.Name)
+	}
+	permission := metadata.NewObjectFieldPermission(objID, attrs, roleNames)
+
+	if option.Filter != nil && option.Filter.Rule != nil {
+		if fields := permission.UnreadableFields(option.Filter.Fields()); len(fields) > 0 {
+			blog.Errorf("export %s, but user %s can not read filter fields %v, rid: %s", objID, kit.User, fields, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommFieldReadPermissionDenied, strings.Join(fields, ","))
+		}
+	}
+
+	if len(option.Fields) > 0 {
+		fields := make([]string, 0, len(option.Fields))
+		for _, field := range option.Fields {
+			if permission.CanRead(field) {
+				fields = append(fields, field)
+			}
+		}
+		// all the fields are exported if the fields are empty, so it's denied if none of the fields can be read
+		if len(fields) == 0 {
+			blog.Errorf("export %s, but user %s can not read any of the fields %v, rid: %s", objID, kit.User, option.Fields, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommFieldReadPermissionDenied, strings.Join(option.Fields, ","))
+		}
+		option.Fields = fields
+	}
+	return permission, nil
+}
+
+// exportCondition returns the condition of the exported resources, the conditions of the filter, the id and
+// the resource are all in the $and condition.
+func exportCondition(kit *rest.Kit, option metadata.ExportOption, idField string,
+	conds ...map[string]interface{}) (map[string]interface{}, errors.CCErrorCoder) {
+
+	if key, err := option.Validate(); err != nil {
+		blog.Errorf("export failed, option invalid, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	filters := make([]map[string]interface{}, 0)
+	if option.Filter != nil && option.Filter.Rule != nil {
+		mgoFilter, key, err := option.Filter.ToMgo()
+		if err != nil {
+			blog.Errorf("export failed, invalid filter, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "filter."+key)
+		}
+		filters = append(filters, mgoFilter)
+	}
+	filters = append(filters, map[string]interface{}{idField: map[string]interface{}{common.BKDBGT: option.AfterID}})
+	filters = append(filters, conds...)
+
+	cond := map[string]interface{}{common.BKDBAND: filters}
+	return util.SetQueryOwner(cond, kit.SupplierAccount), nil
+}
+
+func writeExportLine(w io.Writer, data interface{}) error {
+	line, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"sync"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+)
+
+// expressionRefresher backfills the expression fields of the model's instances in background, so that the
+// attribute is saved without waiting for all the instances to be computed. the refreshes of a model are run
+// one by one, the refreshes requested while the model is being refreshed are merged into one more refresh.
+type expressionRefresher struct {
+	lock    sync.Mutex
+	running map[string]bool
+	pending map[string]bool
+}
+
+func newExpressionRefresher() *expressionRefresher {
+	return &expressionRefresher{
+		running: make(map[string]bool),
+		pending: make(map[string]bool),
+	}
+}
+
+// RefreshExpressionFields recompute the expression fields of all the instances of the model in background,
+// it's called after the expression attribute's change is committed, so that the refresh reads the new expression.
+func (s *coreService) RefreshExpressionFields(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	s.refreshExpressionFieldsInBackground(ctx.Kit, objID)
+	ctx.RespEntity(nil)
+}
+
+func (s *coreService) refreshExpressionFieldsInBackground(kit *rest.Kit, objID string) {
+	key := kit.SupplierAccount + ":" + objID
+	r := s.expressionRefresher
+
+	r.lock.Lock()
+	if r.running[key] {
+		r.pending[key] = true
+		r.lock.Unlock()
+		return
+	}
+	r.running[key] = true
+	r.lock.Unlock()
+
+	go s.refreshExpressionFields(newBackgroundKit(kit), key, objID)
+}
+
+func (s *coreService) refreshExpressionFields(kit *rest.Kit, key, objID string) {
+	r := s.expressionRefresher
+	for {
+		if err := s.core.InstanceOperation().RefreshExpressionFields(kit, objID, mapstr.New()); err != nil {
+			blog.Errorf("refresh the expression fields of the model objectID(%s) failed, err: %v, rid: %s", objID, err, kit.Rid)
+		}
+
+		r.lock.Lock()
+		if !r.pending[key] {
+			delete(r.running, key)
+			r.lock.Unlock()
+			return
+		}
+		delete(r.pending, key)
+		r.lock.Unlock()
+	}
+}
+
+// newBackgroundKit copies the kit of the request for the background job, it's not canceled with the request
+// and does not join the request's transaction.
+func newBackgroundKit(kit *rest.Kit) *rest.Kit {
+	header := util.CloneHeader(kit.Header)
+	header.Del(common.TransactionIdHeader)
+	header.Del(common.TransactionTimeoutHeader)
+
+	return &rest.Kit{
+		Rid:             kit.Rid,
+		Header:          header,
+		Ctx:             util.NewContextFromHTTPHeader(header),
+		CCError:         kit.CCError,
+		User:            kit.User,
+		SupplierAccount: kit.SupplierAccount,
+	}
+}
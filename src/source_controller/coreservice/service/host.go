@@ -191,11 +191,19 @@ func (s *coreService) GetHosts(ctx *rest.Contexts) {
 			blog.Errorf("SetModOwner failed condition %#v, error %s", condition, err.Error())
 		}
 	}
-	condition = util.SetModOwner(cond, ctx.Kit.SupplierAccount)
-	fieldArr := util.SplitStrField(dat.Fields, ",")
+	page := metadata.BasePage{Start: dat.Start, Limit: dat.Limit, Sort: dat.Sort, Cursor: dat.Cursor}
+	cond = util.SetModOwner(cond, ctx.Kit.SupplierAccount)
+	condition, err := page.CursorCondition(cond, common.BKHostIDField)
+	if err != nil {
+		blog.Errorf("get hosts with invalid page cursor, input: %+v, err: %v, rid: %s", dat, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "cursor"))
+		return
+	}
+	fieldArr := page.CursorFields(util.SplitStrField(dat.Fields, ","), common.BKHostIDField)
 
 	result := make([]mapstr.MapStr, 0)
-	dbInst := s.db.Table(common.BKTableNameBaseHost).Find(condition).Sort(dat.Sort).Start(uint64(dat.Start)).Limit(uint64(dat.Limit))
+	dbInst := s.db.Table(common.BKTableNameBaseHost).Find(condition).Sort(page.CursorSort(common.BKHostIDField)).
+		Start(uint64(dat.Start)).Limit(uint64(dat.Limit))
 	if 0 < len(fieldArr) {
 		dbInst.Fields(fieldArr...)
 	}
@@ -205,17 +213,27 @@ func (s *coreService) GetHosts(ctx *rest.Contexts) {
 		return
 	}
 
-	count, err := s.db.Table(common.BKTableNameBaseHost).Find(condition).Count(ctx.Kit.Ctx)
+	// the count is the total of the hosts matching the condition, not only the ones after the cursor
+	count, err := s.db.Table(common.BKTableNameBaseHost).Find(cond).Count(ctx.Kit.Ctx)
 	if err != nil {
 		blog.Errorf("get object failed type:%s ,input: %v error: %v, rid: %s", common.BKInnerObjIDHost, dat, err, ctx.Kit.Rid)
 		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrHostSelectInst))
 		return
 	}
 
-	ctx.RespEntity(metadata.HostInfo{
+	hostInfo := metadata.HostInfo{
 		Count: int(count),
 		Info:  result,
-	})
+	}
+	if len(result) > 0 {
+		hostInfo.NextCursor, err = page.NextCursor(common.BKHostIDField, len(result), result[len(result)-1])
+		if err != nil {
+			blog.Errorf("get hosts, but generate next cursor failed, input: %+v, err: %v, rid: %s", dat, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "sort"))
+			return
+		}
+	}
+	ctx.RespEntity(hostInfo)
 }
 
 func (s *coreService) GetHostSnap(ctx *rest.Contexts) {
@@ -283,3 +283,26 @@ func (s *coreService) UpdateHostByHostApplyRule(ctx *rest.Contexts) {
 	}
 	ctx.RespEntity(result)
 }
+
+func (s *coreService) ListHostApplyDrift(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	option := metadata.ListHostApplyDriftOption{}
+	if err := ctx.DecodeInto(&option); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, ccErr := s.core.HostApplyRuleOperation().ListHostApplyDrift(ctx.Kit, bizID, option)
+	if ccErr != nil {
+		blog.Errorf("ListHostApplyDrift failed, bizID: %d, option: %+v, err: %+v, rid: %s", bizID, option, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
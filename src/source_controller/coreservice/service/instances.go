@@ -14,6 +14,7 @@ package service
 
 import (
 	"configcenter/src/common"
+	"configcenter/src/common/blog"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/metadata"
 	"configcenter/src/source_controller/coreservice/multilingual"
@@ -95,6 +96,23 @@ func (s *coreService) SearchModelInstances(ctx *rest.Contexts) {
 	ctx.RespEntity(dataResult)
 }
 
+func (s *coreService) AggregateModelInstances(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	option := metadata.AggregateOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.InstanceOperation().AggregateModelInstances(ctx.Kit, objID, option)
+	if err != nil {
+		blog.Errorf("AggregateModelInstances failed, object: %s, option: %+v, err: %+v, rid: %s", objID, option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
 func (s *coreService) DeleteModelInstances(ctx *rest.Contexts) {
 	inputData := metadata.DeleteOption{}
 	if err := ctx.DecodeInto(&inputData); nil != err {
@@ -15,6 +15,7 @@ package service
 import (
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
@@ -101,6 +102,21 @@ func (s *coreService) SearchUnique(kit *rest.Kit, objID string) (uniqueAttr []me
 	return result.Info, err
 }
 
+// AddTrash save the instances to the trash bin before they are deleted if the object is opted into it
+func (s *coreService) AddTrash(kit *rest.Kit, objID string, instances []mapstr.MapStr) errors.CCErrorCoder {
+	return s.core.TrashOperation().AddTrash(kit, objID, instances)
+}
+
+// CheckQuota checks the quota of the resource is not exceeded after the resources are created
+func (s *coreService) CheckQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64, increment int64) errors.CCErrorCoder {
+	return s.core.QuotaOperation().CheckQuota(kit, resource, bizID, increment)
+}
+
+// CheckBusinessQuota checks the business quota of the resource is not exceeded after the resources are moved into the business
+func (s *coreService) CheckBusinessQuota(kit *rest.Kit, resource metadata.QuotaResource, bizID int64, increment int64) errors.CCErrorCoder {
+	return s.core.QuotaOperation().CheckBusinessQuota(kit, resource, bizID, increment)
+}
+
 func (s *coreService) UpdateModelInstance(kit *rest.Kit, objID string, param metadata.UpdateOption) (*metadata.UpdatedCount, error) {
 	return s.core.InstanceOperation().UpdateModelInstance(kit, objID, param)
 }
@@ -0,0 +1,91 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+func (s *coreService) CreatePermissionRole(ctx *rest.Contexts) {
+	option := metadata.CreatePermissionRoleOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.PermissionRoleOperation().CreatePermissionRole(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("CreatePermissionRole failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) UpdatePermissionRole(ctx *rest.Contexts) {
+	roleID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKFieldID))
+		return
+	}
+
+	option := metadata.UpdatePermissionRoleOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.PermissionRoleOperation().UpdatePermissionRole(ctx.Kit, roleID, option)
+	if err != nil {
+		blog.Errorf("UpdatePermissionRole failed, roleID: %d, option: %+v, err: %+v, rid: %s", roleID, option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) DeletePermissionRole(ctx *rest.Contexts) {
+	option := metadata.DeletePermissionRoleOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := s.core.PermissionRoleOperation().DeletePermissionRole(ctx.Kit, option.RoleIDs); err != nil {
+		blog.Errorf("DeletePermissionRole failed, roleIDs: %v, err: %+v, rid: %s", option.RoleIDs, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+func (s *coreService) ListPermissionRole(ctx *rest.Contexts) {
+	option := metadata.ListPermissionRoleOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.PermissionRoleOperation().ListPermissionRole(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("ListPermissionRole failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
@@ -0,0 +1,82 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// SetQuota create or update the quota, the limit -1 overrides the default business quota to be unlimited
+func (s *coreService) SetQuota(ctx *rest.Contexts) {
+	option := metadata.SetQuotaOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if field, err := option.Validate(); err != nil {
+		blog.Errorf("SetQuota failed, option invalid, field: %s, err: %v, rid: %s", field, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field))
+		return
+	}
+
+	quota, err := s.core.QuotaOperation().SetQuota(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("SetQuota failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(quota)
+}
+
+func (s *coreService) DeleteQuota(ctx *rest.Contexts) {
+	key := metadata.QuotaKey{}
+	if err := ctx.DecodeInto(&key); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if field, err := key.Validate(); err != nil {
+		blog.Errorf("DeleteQuota failed, key invalid, field: %s, err: %v, rid: %s", field, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field))
+		return
+	}
+
+	if err := s.core.QuotaOperation().DeleteQuota(ctx.Kit, key); err != nil {
+		blog.Errorf("DeleteQuota failed, key: %+v, err: %+v, rid: %s", key, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+func (s *coreService) ListQuotaUsage(ctx *rest.Contexts) {
+	option := metadata.ListQuotaUsageOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if option.BizID < 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	usages, err := s.core.QuotaOperation().ListQuotaUsage(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("ListQuotaUsage failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(usages)
+}
@@ -25,9 +25,12 @@ import (
 	"configcenter/src/source_controller/coreservice/app/options"
 	"configcenter/src/source_controller/coreservice/cache"
 	cacheop "configcenter/src/source_controller/coreservice/cache"
+	"configcenter/src/source_controller/coreservice/cache/checker"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/source_controller/coreservice/core/association"
 	"configcenter/src/source_controller/coreservice/core/auditlog"
+	"configcenter/src/source_controller/coreservice/core/businessarchive"
+	"configcenter/src/source_controller/coreservice/core/changerequest"
 	"configcenter/src/source_controller/coreservice/core/datasynchronize"
 	"configcenter/src/source_controller/coreservice/core/host"
 	"configcenter/src/source_controller/coreservice/core/hostapplyrule"
@@ -36,13 +39,20 @@ import (
 	"configcenter/src/source_controller/coreservice/core/mainline"
 	"configcenter/src/source_controller/coreservice/core/model"
 	"configcenter/src/source_controller/coreservice/core/operation"
+	"configcenter/src/source_controller/coreservice/core/permissionrole"
 	"configcenter/src/source_controller/coreservice/core/process"
+	"configcenter/src/source_controller/coreservice/core/quota"
+	"configcenter/src/source_controller/coreservice/core/serviceaccount"
 	"configcenter/src/source_controller/coreservice/core/settemplate"
 	dbSystem "configcenter/src/source_controller/coreservice/core/system"
+	"configcenter/src/source_controller/coreservice/core/trash"
 	watchEvent "configcenter/src/source_controller/coreservice/event"
+	"configcenter/src/source_controller/coreservice/hostapply"
+	"configcenter/src/source_controller/coreservice/tenantusage"
 	"configcenter/src/storage/dal"
 	"configcenter/src/storage/dal/mongo/local"
 	dalredis "configcenter/src/storage/dal/redis"
+	"configcenter/src/storage/dal/tenant"
 	"configcenter/src/storage/reflector"
 	"configcenter/src/storage/stream"
 
@@ -58,20 +68,23 @@ type CoreServiceInterface interface {
 
 // New create topo service instance
 func New() CoreServiceInterface {
-	return &coreService{}
+	return &coreService{expressionRefresher: newExpressionRefresher()}
 }
 
 // coreService topo service
 type coreService struct {
-	engine      *backbone.Engine
-	langFactory map[common.LanguageType]language.DefaultCCLanguageIf
-	language    language.CCLanguageIf
-	err         errors.CCErrorIf
-	cfg         options.Config
-	core        core.Core
-	db          dal.RDB
-	rds         *redis.Client
-	cacheSet    *cache.ClientSet
+	engine       *backbone.Engine
+	langFactory  map[common.LanguageType]language.DefaultCCLanguageIf
+	language     language.CCLanguageIf
+	err          errors.CCErrorIf
+	cfg          options.Config
+	core         core.Core
+	db           dal.RDB
+	rds          *redis.Client
+	cacheSet     *cache.ClientSet
+	cacheChecker *checker.Checker
+
+	expressionRefresher *expressionRefresher
 }
 
 func (s *coreService) SetConfig(cfg options.Config, engine *backbone.Engine, err errors.CCErrorIf, lang language.CCLanguageIf) error {
@@ -107,27 +120,38 @@ func (s *coreService) SetConfig(cfg options.Config, engine *backbone.Engine, err
 		return initErr
 	}
 
-	s.db = db
+	// the requests are scoped to their supplier account in the db layer, the cache and event
+	// which work for all the tenants use the db directly.
+	tenantDB := tenant.New(db, tenant.GlobalTables...)
+	s.db = tenantDB
 	s.rds = cache
 
 	// connect the remote mongodb
-	instance := instances.New(db, s, cache, lang)
-	hostApplyRuleCore := hostapplyrule.New(db, instance)
+	instance := instances.New(tenantDB, s, cache, lang)
+	hostApplyRuleCore := hostapplyrule.New(tenantDB, instance)
+	auditCore := auditlog.New(tenantDB)
 	s.core = core.New(
-		model.New(db, s, lang, cache),
+		model.New(tenantDB, s, lang, cache),
 		instance,
-		association.New(db, s),
-		datasynchronize.New(db, s),
-		mainline.New(db, lang),
-		host.New(db, cache, s, hostApplyRuleCore),
-		auditlog.New(db),
-		process.New(db, s, cache),
-		label.New(db),
-		settemplate.New(db),
-		operation.New(db),
+		association.New(tenantDB, s),
+		datasynchronize.New(tenantDB, s),
+		mainline.New(tenantDB, lang),
+		host.New(tenantDB, cache, s, hostApplyRuleCore),
+		auditCore,
+		process.New(tenantDB, s, cache),
+		label.New(tenantDB),
+		settemplate.New(tenantDB, cache),
+		operation.New(tenantDB),
 		hostApplyRuleCore,
-		dbSystem.New(db),
+		dbSystem.New(tenantDB),
+		permissionrole.New(tenantDB),
+		changerequest.New(tenantDB, cache),
+		businessarchive.New(tenantDB),
+		serviceaccount.New(tenantDB),
+		trash.New(tenantDB, cfg.TrashObjects, cfg.TrashRetentionDays, engine.ServiceManageInterface),
+		quota.New(tenantDB, auditCore),
 	)
+	tenantusage.NewCollector(db, engine.Metric().Registry(), engine.ServiceManageInterface)
 
 	event, eventErr := reflector.NewReflector(s.cfg.Mongo.GetMongoConf())
 	if eventErr != nil {
@@ -135,12 +159,18 @@ func (s *coreService) SetConfig(cfg options.Config, engine *backbone.Engine, err
 		return eventErr
 	}
 
-	c, cacheErr := cacheop.NewCache(cache, db, event)
+	c, cacheErr := cacheop.NewCache(cache, db, event, cfg.InstanceCacheObjects)
 	if cacheErr != nil {
 		blog.Errorf("new cache instance failed, err: %v", cacheErr)
 		return cacheErr
 	}
 	s.cacheSet = c
+	s.cacheChecker = checker.NewChecker(cache, db, engine.Metric().Registry())
+
+	if err := hostapply.NewReconciler(event, db, hostApplyRuleCore, engine.ServiceManageInterface); err != nil {
+		blog.Errorf("new host apply reconciler failed, err: %v", err)
+		return err
+	}
 
 	watcher, watchErr := stream.NewStream(s.cfg.Mongo.GetMongoConf())
 	if watchErr != nil {
@@ -148,7 +178,7 @@ func (s *coreService) SetConfig(cfg options.Config, engine *backbone.Engine, err
 		return watchErr
 	}
 
-	if err := watchEvent.NewEvent(s.db, s.rds, watcher, engine.ServiceManageInterface); err != nil {
+	if err := watchEvent.NewEvent(db, s.rds, watcher, engine.ServiceManageInterface); err != nil {
 		blog.Errorf("new watch event failed, err: %v", err)
 		return err
 	}
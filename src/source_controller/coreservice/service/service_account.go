@@ -0,0 +1,164 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+func (s *coreService) CreateServiceAccount(ctx *rest.Contexts) {
+	option := metadata.CreateServiceAccountOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ServiceAccountOperation().CreateServiceAccount(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("CreateServiceAccount failed, name: %s, err: %+v, rid: %s", option.Name, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) UpdateServiceAccount(ctx *rest.Contexts) {
+	accountID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKFieldID))
+		return
+	}
+
+	option := metadata.UpdateServiceAccountOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ServiceAccountOperation().UpdateServiceAccount(ctx.Kit, accountID, option)
+	if err != nil {
+		blog.Errorf("UpdateServiceAccount failed, id: %d, err: %+v, rid: %s", accountID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) DeleteServiceAccount(ctx *rest.Contexts) {
+	accountID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKFieldID))
+		return
+	}
+
+	if err := s.core.ServiceAccountOperation().DeleteServiceAccount(ctx.Kit, accountID); err != nil {
+		blog.Errorf("DeleteServiceAccount failed, id: %d, err: %+v, rid: %s", accountID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+func (s *coreService) ListServiceAccount(ctx *rest.Contexts) {
+	option := metadata.ListServiceAccountOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ServiceAccountOperation().ListServiceAccount(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("ListServiceAccount failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) CreateAPIToken(ctx *rest.Contexts) {
+	accountID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKServiceAccountIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKServiceAccountIDField))
+		return
+	}
+
+	option := metadata.CreateAPITokenOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ServiceAccountOperation().CreateAPIToken(ctx.Kit, accountID, option)
+	if err != nil {
+		blog.Errorf("CreateAPIToken failed, service account: %d, err: %+v, rid: %s", accountID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) RevokeAPIToken(ctx *rest.Contexts) {
+	accountID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKServiceAccountIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKServiceAccountIDField))
+		return
+	}
+	tokenID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKFieldID))
+		return
+	}
+
+	if err := s.core.ServiceAccountOperation().RevokeAPIToken(ctx.Kit, accountID, tokenID); err != nil {
+		blog.Errorf("RevokeAPIToken failed, service account: %d, id: %d, err: %+v, rid: %s", accountID, tokenID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+func (s *coreService) ListAPIToken(ctx *rest.Contexts) {
+	option := metadata.ListAPITokenOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ServiceAccountOperation().ListAPIToken(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("ListAPIToken failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) ValidateAPIToken(ctx *rest.Contexts) {
+	option := metadata.ValidateAPITokenOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ServiceAccountOperation().ValidateAPIToken(ctx.Kit, option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
@@ -98,8 +98,10 @@ func (s *coreService) initModelInstances(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/model/{bk_obj_id}/instance", Handler: s.CreateManyModelInstances})
 	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/model/{bk_obj_id}/instance", Handler: s.UpdateModelInstances})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/read/model/{bk_obj_id}/instances", Handler: s.SearchModelInstances})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/read/model/{bk_obj_id}/instances/aggregation", Handler: s.AggregateModelInstances})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/model/{bk_obj_id}/instance", Handler: s.DeleteModelInstances})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/model/{bk_obj_id}/instance/cascade", Handler: s.CascadeDeleteModelInstances})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/update/model/{bk_obj_id}/instance/expression/refresh", Handler: s.RefreshExpressionFields})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -257,6 +259,7 @@ func (s *coreService) label(web *restful.WebService) {
 
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/labels", Handler: s.AddLabels})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/labels", Handler: s.RemoveLabels})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/labels/aggregation", Handler: s.AggregateLabels})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -273,6 +276,104 @@ func (s *coreService) topographics(web *restful.WebService) {
 	utility.AddToRestfulWebService(web)
 }
 
+func (s *coreService) initPermissionRole(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.engine.CCErr,
+		Language: s.engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/permission_role", Handler: s.CreatePermissionRole})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/permission_role/{id}", Handler: s.UpdatePermissionRole})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/permission_role", Handler: s.DeletePermissionRole})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/permission_role", Handler: s.ListPermissionRole})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *coreService) initChangeRequest(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.engine.CCErr,
+		Language: s.engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/change_request", Handler: s.CreateChangeRequest})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/change_request/{id}", Handler: s.GetChangeRequest})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/change_request/{id}/status", Handler: s.UpdateChangeRequestStatus})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/change_request", Handler: s.ListChangeRequest})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *coreService) initBusinessArchive(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.engine.CCErr,
+		Language: s.engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/business_archive", Handler: s.CreateBusinessArchive})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/business_archive/{id}", Handler: s.UpdateBusinessArchive})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/business_archive", Handler: s.ListBusinessArchive})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *coreService) initServiceAccount(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.engine.CCErr,
+		Language: s.engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/service_account", Handler: s.CreateServiceAccount})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/service_account/{id}", Handler: s.UpdateServiceAccount})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/service_account/{id}", Handler: s.DeleteServiceAccount})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/service_account", Handler: s.ListServiceAccount})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/service_account/{service_account_id}/api_token", Handler: s.CreateAPIToken})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/service_account/{service_account_id}/api_token/{id}/revoke", Handler: s.RevokeAPIToken})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/api_token", Handler: s.ListAPIToken})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/api_token/validate", Handler: s.ValidateAPIToken})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *coreService) initTrash(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.engine.CCErr,
+		Language: s.engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/trash/object/{bk_obj_id}", Handler: s.ListTrash})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/update/trash/object/{bk_obj_id}/restore", Handler: s.RestoreTrash})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/trash/object/{bk_obj_id}", Handler: s.DeleteTrash})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *coreService) initQuota(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.engine.CCErr,
+		Language: s.engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/quota", Handler: s.SetQuota})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/quota", Handler: s.DeleteQuota})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/quota/usage", Handler: s.ListQuotaUsage})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *coreService) initExport(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.engine.CCErr,
+		Language: s.engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/export/model/{bk_obj_id}/instances", Handler: s.ExportInstances})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/export/hosts", Handler: s.ExportHosts})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/export/model/{bk_obj_id}/instance_associations", Handler: s.ExportInstanceAssociations})
+
+	utility.AddToRestfulWebService(web)
+}
+
 func (s *coreService) ccSystem(web *restful.WebService) {
 	utility := rest.NewRestUtility(rest.Config{
 		ErrorIf:  s.engine.CCErr,
@@ -304,10 +405,13 @@ func (s *coreService) initCache(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/cache/host/with_inner_ip", Handler: s.SearchHostWithInnerIPInCache})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/cache/host/with_host_id", Handler: s.SearchHostWithHostIDInCache})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/cache/host/with_host_id", Handler: s.ListHostWithHostIDInCache})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/cache/instance/with_inst_id", Handler: s.SearchInstWithInstIDInCache})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/cache/instance/with_unique_key", Handler: s.SearchInstWithUniqueKeyInCache})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/cache/biz/{bk_biz_id}", Handler: s.SearchBusinessInCache})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/cache/set/{bk_set_id}", Handler: s.SearchSetInCache})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/cache/module/{bk_module_id}", Handler: s.SearchModuleInCache})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/cache/{bk_obj_id}/{bk_inst_id}", Handler: s.SearchCustomLayerInCache})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/check/cache/consistency", Handler: s.CheckCacheConsistency})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -342,6 +446,13 @@ func (s *coreService) initService(web *restful.WebService) {
 	s.ccSystem(web)
 	s.initSetTemplate(web)
 	s.initHostApplyRule(web)
+	s.initPermissionRole(web)
+	s.initChangeRequest(web)
+	s.initBusinessArchive(web)
+	s.initServiceAccount(web)
+	s.initTrash(web)
+	s.initQuota(web)
+	s.initExport(web)
 	s.transaction(web)
 	s.initCount(web)
 	s.initCache(web)
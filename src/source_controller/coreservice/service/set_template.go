@@ -343,3 +343,49 @@ func (s *coreService) DeleteSetTemplateSyncStatus(ctx *rest.Contexts) {
 	}
 	ctx.RespEntity(nil)
 }
+
+func (s *coreService) UpdateSetTemplateSyncPolicy(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setTemplateIDStr := ctx.Request.PathParameter(common.BKSetTemplateIDField)
+	setTemplateID, err := strconv.ParseInt(setTemplateIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKSetTemplateIDField))
+		return
+	}
+
+	policy := metadata.SetTemplateSyncPolicy{}
+	if err := ctx.DecodeInto(&policy); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	setTemplate, err := s.core.SetTemplateOperation().UpdateSetTemplateSyncPolicy(ctx.Kit, bizID, setTemplateID, policy)
+	if err != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, bizID: %d, setTemplateID: %d, policy: %+v, err: %+v, rid: %s", bizID, setTemplateID, policy, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(setTemplate)
+}
+
+func (s *coreService) ListSetTemplateSyncPolicy(ctx *rest.Contexts) {
+	option := metadata.ListSetTemplateSyncPolicyOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	setTemplates, err := s.core.SetTemplateOperation().ListSetTemplateSyncPolicy(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("ListSetTemplateSyncPolicy failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(setTemplates)
+}
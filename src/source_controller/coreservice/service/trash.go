@@ -0,0 +1,112 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/coreservice/core/trash"
+)
+
+func (s *coreService) ListTrash(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	option := metadata.ListTrashOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.TrashOperation().ListTrash(ctx.Kit, objID, option)
+	if err != nil {
+		blog.Errorf("ListTrash failed, object: %s, option: %+v, err: %+v, rid: %s", objID, option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// RestoreTrash restore the instances in the trash bin, then restore their associations whose association model
+// and the other end instance still exist. each trash item is removed as soon as its instance is restored, so that
+// a restore failed midway can be retried with the remaining items.
+func (s *coreService) RestoreTrash(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	option := metadata.RestoreTrashOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if field, err := option.Validate(); err != nil {
+		blog.Errorf("RestoreTrash failed, option invalid, field: %s, err: %v, rid: %s", field, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field))
+		return
+	}
+
+	option.IDs = util.IntArrayUnique(option.IDs)
+	listOption := metadata.ListTrashOption{
+		IDs:  option.IDs,
+		Page: metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	items, err := s.core.TrashOperation().ListTrash(ctx.Kit, objID, listOption)
+	if err != nil {
+		blog.Errorf("RestoreTrash failed, list trash failed, object: %s, ids: %v, err: %+v, rid: %s", objID, option.IDs, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	if len(items.Info) != len(option.IDs) {
+		blog.Errorf("RestoreTrash failed, some of the trash items %v are not found, rid: %s", option.IDs, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "ids"))
+		return
+	}
+
+	for _, item := range items.Info {
+		if err := s.core.InstanceOperation().RestoreModelInstance(ctx.Kit, objID, item); err != nil {
+			blog.Errorf("RestoreTrash failed, restore %s instance %d failed, err: %v, rid: %s", objID, item.InstID, err, ctx.Kit.Rid)
+			ctx.RespAutoError(err)
+			return
+		}
+		if err := s.core.TrashOperation().DeleteTrash(ctx.Kit, objID, []int64{item.ID}); err != nil {
+			blog.Errorf("RestoreTrash failed, delete trash item %d of %s instance %d failed, err: %v, rid: %s", item.ID, objID,
+				item.InstID, err, ctx.Kit.Rid)
+			ctx.RespAutoError(err)
+			return
+		}
+	}
+
+	// the associations are restored after all the instances, so that the associations between them can be restored.
+	result := trash.RestoreAssociations(ctx.Kit, s.core.AssociationOperation(), objID, items.Info)
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) DeleteTrash(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	option := metadata.RestoreTrashOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if field, err := option.Validate(); err != nil {
+		blog.Errorf("DeleteTrash failed, option invalid, field: %s, err: %v, rid: %s", field, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field))
+		return
+	}
+
+	if err := s.core.TrashOperation().DeleteTrash(ctx.Kit, objID, option.IDs); err != nil {
+		blog.Errorf("DeleteTrash failed, object: %s, ids: %v, err: %+v, rid: %s", objID, option.IDs, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
@@ -0,0 +1,136 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tenantusage records the resource usage of each supplier account as metrics.
+package tenantusage
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/apimachinery/discovery"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metrics"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespacePrefix = "cmdb_tenant"
+	// collectInterval is the interval to count the resources of the tenants.
+	collectInterval = 5 * time.Minute
+)
+
+type instanceCount struct {
+	ID struct {
+		SupplierAccount string `bson:"bk_supplier_account"`
+		ObjectID        string `bson:"bk_obj_id"`
+	} `bson:"_id"`
+	Count int64 `bson:"count"`
+}
+
+type hostCount struct {
+	SupplierAccount string `bson:"_id"`
+	Count           int64  `bson:"count"`
+}
+
+// Collector counts the instances and hosts of all the tenants, so the db should not be scoped to a tenant.
+type Collector struct {
+	db        dal.DB
+	isMaster  discovery.ServiceManageInterface
+	instances *prometheus.GaugeVec
+	hosts     *prometheus.GaugeVec
+}
+
+// NewCollector register the tenant usage metrics and start to collect them, only the master collects the
+// usage so that the usage is not counted repeatedly by the coreservice instances.
+func NewCollector(db dal.DB, registry prometheus.Registerer, isMaster discovery.ServiceManageInterface) *Collector {
+	c := &Collector{
+		db:       db,
+		isMaster: isMaster,
+		instances: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: metricsNamespacePrefix + "_instance_count",
+				Help: "number of the custom object instances of each tenant.",
+			},
+			[]string{metrics.LabelSupplierAccount, common.BKObjIDField},
+		),
+		hosts: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: metricsNamespacePrefix + "_host_count",
+				Help: "number of the hosts of each tenant.",
+			},
+			[]string{metrics.LabelSupplierAccount},
+		),
+	}
+	registry.MustRegister(c.instances, c.hosts)
+
+	go c.run()
+	return c
+}
+
+func (c *Collector) run() {
+	for {
+		if c.isMaster.IsMaster() {
+			c.collect()
+		} else {
+			c.instances.Reset()
+			c.hosts.Reset()
+		}
+		time.Sleep(collectInterval)
+	}
+}
+
+func (c *Collector) collect() {
+	rid := util.GenerateRID()
+	ctx := context.WithValue(context.Background(), common.ContextRequestIDField, rid)
+
+	instPipeline := []map[string]interface{}{{
+		common.BKDBGroup: map[string]interface{}{
+			"_id": map[string]interface{}{
+				common.BkSupplierAccount: "$" + common.BkSupplierAccount,
+				common.BKObjIDField:      "$" + common.BKObjIDField,
+			},
+			"count": map[string]interface{}{common.BKDBSum: 1},
+		},
+	}}
+	instCounts := make([]instanceCount, 0)
+	if err := c.db.Table(common.BKTableNameBaseInst).AggregateAll(ctx, instPipeline, &instCounts); err != nil {
+		blog.Errorf("count the instances of the tenants failed, err: %v, rid: %s", err, rid)
+		return
+	}
+
+	hostPipeline := []map[string]interface{}{{
+		common.BKDBGroup: map[string]interface{}{
+			"_id":   "$" + common.BkSupplierAccount,
+			"count": map[string]interface{}{common.BKDBSum: 1},
+		},
+	}}
+	hostCounts := make([]hostCount, 0)
+	if err := c.db.Table(common.BKTableNameBaseHost).AggregateAll(ctx, hostPipeline, &hostCounts); err != nil {
+		blog.Errorf("count the hosts of the tenants failed, err: %v, rid: %s", err, rid)
+		return
+	}
+
+	// reset the gauges so that the deleted objects and tenants are not reported any more
+	c.instances.Reset()
+	for _, count := range instCounts {
+		c.instances.WithLabelValues(count.ID.SupplierAccount, count.ID.ObjectID).Set(float64(count.Count))
+	}
+	c.hosts.Reset()
+	for _, count := range hostCounts {
+		c.hosts.WithLabelValues(count.SupplierAccount).Set(float64(count.Count))
+	}
+}
@@ -205,7 +205,8 @@ type Find struct {
 	filter     types.Filter
 	start      int64
 	limit      int64
-	sort       map[string]interface{}
+	// sort keeps the order of the sort fields, so that the documents are sorted by the fields one by one
+	sort bson.D
 }
 
 // Fields 查询字段
@@ -223,22 +224,22 @@ func (f *Find) Fields(fields ...string) types.Find {
 func (f *Find) Sort(sort string) types.Find {
 	if sort != "" {
 		sortArr := strings.Split(sort, ",")
-		f.sort = make(map[string]interface{}, 0)
+		f.sort = make(bson.D, 0)
 		for _, sortItem := range sortArr {
 			sortItemArr := strings.Split(sortItem, ":")
 			sortKey := strings.TrimLeft(sortItemArr[0], "+-")
 			if len(sortItemArr) == 2 {
 				sortDescFlag := strings.TrimSpace(sortItemArr[1])
 				if sortDescFlag == "-1" {
-					f.sort[sortKey] = -1
+					f.sort = append(f.sort, bson.E{Key: sortKey, Value: -1})
 				} else {
-					f.sort[sortKey] = 1
+					f.sort = append(f.sort, bson.E{Key: sortKey, Value: 1})
 				}
 			} else {
 				if strings.HasPrefix(sortItemArr[0], "-") {
-					f.sort[sortKey] = -1
+					f.sort = append(f.sort, bson.E{Key: sortKey, Value: -1})
 				} else {
-					f.sort[sortKey] = 1
+					f.sort = append(f.sort, bson.E{Key: sortKey, Value: 1})
 				}
 			}
 		}
@@ -373,6 +374,64 @@ func (f *Find) Count(ctx context.Context) (uint64, error) {
 	}
 }
 
+// iterateBatchSize is the number of the documents fetched by the cursor at one time, so the memory used by
+// the iteration is bounded no matter how many documents are matched.
+const iterateBatchSize = 500
+
+// Iterate 通过游标逐条遍历查询结果，每条结果解码到result后调用handler，handler返回错误时停止遍历
+func (f *Find) Iterate(ctx context.Context, result interface{}, handler func() error) error {
+	rid := ctx.Value(common.ContextRequestIDField)
+	start := time.Now()
+	defer func() {
+		blog.V(4).InfoDepthf(2, "mongo find-iterate cost %dms, rid: %v", time.Since(start)/time.Millisecond, rid)
+	}()
+
+	resultv := reflect.ValueOf(result)
+	if resultv.Kind() != reflect.Ptr || resultv.IsNil() {
+		return errors.New("result argument must be a pointer")
+	}
+	elemt := resultv.Elem().Type()
+
+	findOpts := &options.FindOptions{}
+	findOpts.SetBatchSize(iterateBatchSize)
+	if len(f.projection) != 0 {
+		findOpts.Projection = f.projection
+	}
+	if f.start != 0 {
+		findOpts.SetSkip(f.start)
+	}
+	if f.limit != 0 {
+		findOpts.SetLimit(f.limit)
+	}
+	if len(f.sort) != 0 {
+		findOpts.SetSort(f.sort)
+	}
+	if f.filter == nil {
+		f.filter = bson.M{}
+	}
+
+	return f.tm.AutoRunWithTxn(ctx, f.dbc, func(ctx context.Context) error {
+		cursor, err := f.dbc.Database(f.dbname).Collection(f.collName).Find(ctx, f.filter, findOpts)
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		for cursor.Next(ctx) {
+			// decode into a new value, so that the fields of the previous document are not kept in the result
+			elemp := reflect.New(elemt)
+			if err := cursor.Decode(elemp.Interface()); err != nil {
+				return err
+			}
+			resultv.Elem().Set(elemp.Elem())
+			if err := handler(); err != nil {
+				return err
+			}
+		}
+		return cursor.Err()
+	})
+}
+
 // Insert 插入数据, docs 可以为 单个数据 或者 多个数据
 func (c *Collection) Insert(ctx context.Context, docs interface{}) error {
 	rid := ctx.Value(common.ContextRequestIDField)
@@ -0,0 +1,385 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package tenant isolates the data of the supplier accounts in the db layer, the tenant scoped tables
+// are always operated with the owner condition of the request's supplier account, so that a handler
+// which forgets to set the owner condition can not read or write the data of other tenants.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Errors defines
+var (
+	ErrCrossTenant         = errors.New("the supplier account condition is out of the request's tenant")
+	ErrUnsupportedFilter   = errors.New("the filter can not be scoped to the request's tenant")
+	ErrUnsupportedPipeline = errors.New("the aggregate pipeline can not be scoped to the request's tenant")
+)
+
+// GlobalTables are the tables shared by all the tenants, their documents have no supplier account
+// and they are opted out of the tenant isolation.
+var GlobalTables = []string{
+	common.BKTableNameSystem,
+	common.BKTableNameIDgenerator,
+	common.BKTableNameTransaction,
+	common.BKTableNameHistory,
+	common.BKTableNameDelArchive,
+	common.BKTableNameAPITask,
+	common.BKTableNameAPIToken,
+}
+
+var _ dal.DB = (*tenantDB)(nil)
+
+type tenantDB struct {
+	dal.DB
+	globalTables map[string]bool
+}
+
+// New wraps the db with the tenant isolation, tables except the global tables are scoped to the
+// supplier account in the context. The context without supplier account and the context of the super owner
+// are not scoped, the background jobs and system callers which work for all the tenants should use the super
+// owner, the default supplier account is scoped like any other tenant.
+func New(db dal.DB, globalTables ...string) dal.DB {
+	t := &tenantDB{
+		DB:           db,
+		globalTables: make(map[string]bool),
+	}
+	for _, table := range globalTables {
+		t.globalTables[table] = true
+	}
+	return t
+}
+
+// Table returns the tenant scoped table unless it's a global table
+func (t *tenantDB) Table(collection string) types.Table {
+	table := t.DB.Table(collection)
+	if t.globalTables[collection] {
+		return table
+	}
+	return &tenantTable{Table: table, name: collection}
+}
+
+type tenantTable struct {
+	types.Table
+	name string
+}
+
+func (t *tenantTable) Find(filter types.Filter) types.Find {
+	return &tenantFind{table: t, filter: filter}
+}
+
+func (t *tenantTable) AggregateOne(ctx context.Context, pipeline interface{}, result interface{}) error {
+	pipeline, err := t.scopePipeline(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return t.Table.AggregateOne(ctx, pipeline, result)
+}
+
+func (t *tenantTable) AggregateAll(ctx context.Context, pipeline interface{}, result interface{}) error {
+	pipeline, err := t.scopePipeline(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return t.Table.AggregateAll(ctx, pipeline, result)
+}
+
+func (t *tenantTable) Update(ctx context.Context, filter types.Filter, doc interface{}) error {
+	filter, err := t.scopeFilter(ctx, filter, true)
+	if err != nil {
+		return err
+	}
+	if err := t.validateDoc(ctx, doc); err != nil {
+		return err
+	}
+	return t.Table.Update(ctx, filter, doc)
+}
+
+func (t *tenantTable) Upsert(ctx context.Context, filter types.Filter, doc interface{}) error {
+	filter, err := t.scopeFilter(ctx, filter, true)
+	if err != nil {
+		return err
+	}
+	if err := t.validateDoc(ctx, doc); err != nil {
+		return err
+	}
+	return t.Table.Upsert(ctx, filter, doc)
+}
+
+func (t *tenantTable) UpdateMultiModel(ctx context.Context, filter types.Filter, updateModel ...types.ModeUpdate) error {
+	filter, err := t.scopeFilter(ctx, filter, true)
+	if err != nil {
+		return err
+	}
+	return t.Table.UpdateMultiModel(ctx, filter, updateModel...)
+}
+
+func (t *tenantTable) Delete(ctx context.Context, filter types.Filter) error {
+	filter, err := t.scopeFilter(ctx, filter, true)
+	if err != nil {
+		return err
+	}
+	return t.Table.Delete(ctx, filter)
+}
+
+func (t *tenantTable) DropColumns(ctx context.Context, filter types.Filter, fields []string) error {
+	filter, err := t.scopeFilter(ctx, filter, true)
+	if err != nil {
+		return err
+	}
+	return t.Table.DropColumns(ctx, filter, fields)
+}
+
+func (t *tenantTable) DropDocsColumn(ctx context.Context, field string, filter types.Filter) error {
+	filter, err := t.scopeFilter(ctx, filter, true)
+	if err != nil {
+		return err
+	}
+	return t.Table.DropDocsColumn(ctx, field, filter)
+}
+
+func (t *tenantTable) Distinct(ctx context.Context, field string, filter types.Filter, results interface{}) error {
+	filter, err := t.scopeFilter(ctx, filter, false)
+	if err != nil {
+		return err
+	}
+	return t.Table.Distinct(ctx, field, filter, results)
+}
+
+// scopeFilter adds the owner condition of the request's tenant to the filter, the data of the default
+// supplier account is shared by all tenants for read just like util.SetQueryOwner, but the writes are
+// limited to the request's tenant unless the default supplier account is set in the filter explicitly.
+func (t *tenantTable) scopeFilter(ctx context.Context, filter types.Filter, write bool) (types.Filter, error) {
+	owner, scoped := tenantOwner(ctx)
+	if !scoped {
+		return filter, nil
+	}
+
+	cond, err := toMap(filter)
+	if err != nil {
+		blog.Errorf("scope the filter of table %s to tenant %s failed, filter: %#v, err: %v, rid: %s", t.name, owner,
+			filter, err, util.ExtractRequestIDFromContext(ctx))
+		return nil, ErrUnsupportedFilter
+	}
+
+	if value, exists := cond[common.BkSupplierAccount]; exists {
+		if err := validateOwnerCond(value, owner); err != nil {
+			blog.Errorf("table %s is operated out of tenant %s, filter: %#v, rid: %s", t.name, owner, filter,
+				util.ExtractRequestIDFromContext(ctx))
+			return nil, err
+		}
+		return cond, nil
+	}
+
+	if write {
+		return util.SetModOwner(cond, owner), nil
+	}
+	return util.SetQueryOwner(cond, owner), nil
+}
+
+// scopePipeline prepends a match stage of the request's tenant to the aggregate pipeline
+func (t *tenantTable) scopePipeline(ctx context.Context, pipeline interface{}) (interface{}, error) {
+	owner, scoped := tenantOwner(ctx)
+	if !scoped {
+		return pipeline, nil
+	}
+
+	value := reflect.ValueOf(pipeline)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		blog.Errorf("scope the pipeline of table %s to tenant %s failed, pipeline: %#v, rid: %s", t.name, owner,
+			pipeline, util.ExtractRequestIDFromContext(ctx))
+		return nil, ErrUnsupportedPipeline
+	}
+
+	stages := make([]interface{}, 0, value.Len()+1)
+	stages = append(stages, map[string]interface{}{common.BKDBMatch: util.SetQueryOwner(nil, owner)})
+	for idx := 0; idx < value.Len(); idx++ {
+		stages = append(stages, value.Index(idx).Interface())
+	}
+	return stages, nil
+}
+
+// validateDoc makes sure the update doc does not move the data to other tenants
+func (t *tenantTable) validateDoc(ctx context.Context, doc interface{}) error {
+	owner, scoped := tenantOwner(ctx)
+	if !scoped || reflect.ValueOf(doc).Kind() != reflect.Map {
+		return nil
+	}
+
+	data, err := toMap(doc)
+	if err != nil {
+		return err
+	}
+	if value, exists := data[common.BkSupplierAccount]; exists && value != owner {
+		blog.Errorf("table %s is updated to other tenant %v by tenant %s, rid: %s", t.name, value, owner,
+			util.ExtractRequestIDFromContext(ctx))
+		return ErrCrossTenant
+	}
+	return nil
+}
+
+// tenantFind delays scoping the filter until the query is executed with the request's context
+type tenantFind struct {
+	table  *tenantTable
+	filter types.Filter
+	fields []string
+	sort   string
+	start  uint64
+	limit  uint64
+}
+
+func (f *tenantFind) Fields(fields ...string) types.Find {
+	f.fields = append(f.fields, fields...)
+	return f
+}
+
+func (f *tenantFind) Sort(sort string) types.Find {
+	f.sort = sort
+	return f
+}
+
+func (f *tenantFind) Start(start uint64) types.Find {
+	f.start = start
+	return f
+}
+
+func (f *tenantFind) Limit(limit uint64) types.Find {
+	f.limit = limit
+	return f
+}
+
+func (f *tenantFind) All(ctx context.Context, result interface{}) error {
+	find, err := f.find(ctx)
+	if err != nil {
+		return err
+	}
+	return find.All(ctx, result)
+}
+
+func (f *tenantFind) One(ctx context.Context, result interface{}) error {
+	find, err := f.find(ctx)
+	if err != nil {
+		return err
+	}
+	return find.One(ctx, result)
+}
+
+func (f *tenantFind) Count(ctx context.Context) (uint64, error) {
+	find, err := f.find(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return find.Count(ctx)
+}
+
+func (f *tenantFind) Iterate(ctx context.Context, result interface{}, handler func() error) error {
+	find, err := f.find(ctx)
+	if err != nil {
+		return err
+	}
+	return find.Iterate(ctx, result, handler)
+}
+
+func (f *tenantFind) find(ctx context.Context) (types.Find, error) {
+	filter, err := f.table.scopeFilter(ctx, f.filter, false)
+	if err != nil {
+		return nil, err
+	}
+	return f.table.Table.Find(filter).Fields(f.fields...).Sort(f.sort).Start(f.start).Limit(f.limit), nil
+}
+
+// tenantOwner returns the supplier account of the request and whether the request should be scoped
+func tenantOwner(ctx context.Context) (string, bool) {
+	owner := util.ExtractOwnerFromContext(ctx)
+	if owner == "" || owner == common.BKSuperOwnerID {
+		return owner, false
+	}
+	return owner, true
+}
+
+// validateOwnerCond checks the supplier account condition in the filter only matches the request's tenant
+// or the default supplier account.
+func validateOwnerCond(value interface{}, owner string) error {
+	switch val := value.(type) {
+	case string:
+		if val == owner || val == common.BKDefaultOwnerID {
+			return nil
+		}
+		return ErrCrossTenant
+	}
+
+	cond, err := toMap(value)
+	if err != nil || len(cond) == 0 {
+		return ErrCrossTenant
+	}
+	for operator, operand := range cond {
+		switch operator {
+		case common.BKDBEQ:
+			if err := validateOwnerCond(operand, owner); err != nil {
+				return err
+			}
+		case common.BKDBIN:
+			items := reflect.ValueOf(operand)
+			if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+				return ErrCrossTenant
+			}
+			for idx := 0; idx < items.Len(); idx++ {
+				if err := validateOwnerCond(items.Index(idx).Interface(), owner); err != nil {
+					return err
+				}
+			}
+		default:
+			return ErrCrossTenant
+		}
+	}
+	return nil
+}
+
+// toMap converts the filter to a new map, so that the caller's filter is not changed
+func toMap(filter interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if filter == nil {
+		return result, nil
+	}
+
+	value := reflect.ValueOf(filter)
+	if value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String {
+		if value.IsNil() {
+			return result, nil
+		}
+		iter := value.MapRange()
+		for iter.Next() {
+			result[iter.Key().String()] = iter.Value().Interface()
+		}
+		return result, nil
+	}
+
+	data, err := bson.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := bson.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
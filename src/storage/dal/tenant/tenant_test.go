@@ -0,0 +1,297 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tenant
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+)
+
+const testTable = "cc_ObjectBase"
+
+// memDB is an in memory db which supports the equal and $in conditions, it's enough to check the
+// documents visible to a tenant.
+type memDB struct {
+	dal.DB
+	docs      map[string][]map[string]interface{}
+	pipelines []interface{}
+}
+
+func newMemDB() *memDB {
+	docs := make([]map[string]interface{}, 0)
+	for _, owner := range []string{"tenant_a", "tenant_b", common.BKDefaultOwnerID} {
+		docs = append(docs, map[string]interface{}{"bk_inst_name": owner + "_inst", common.BkSupplierAccount: owner})
+	}
+	return &memDB{docs: map[string][]map[string]interface{}{testTable: docs, common.BKTableNameSystem: docs}}
+}
+
+func (m *memDB) Table(collection string) types.Table {
+	return &memTable{db: m, name: collection}
+}
+
+type memTable struct {
+	types.Table
+	db   *memDB
+	name string
+}
+
+func (t *memTable) Find(filter types.Filter) types.Find {
+	return &memFind{table: t, filter: filter}
+}
+
+func (t *memTable) AggregateAll(ctx context.Context, pipeline interface{}, result interface{}) error {
+	t.db.pipelines = append(t.db.pipelines, pipeline)
+	return nil
+}
+
+func (t *memTable) Delete(ctx context.Context, filter types.Filter) error {
+	kept := make([]map[string]interface{}, 0)
+	for _, doc := range t.db.docs[t.name] {
+		if !match(doc, filter) {
+			kept = append(kept, doc)
+		}
+	}
+	t.db.docs[t.name] = kept
+	return nil
+}
+
+func (t *memTable) Update(ctx context.Context, filter types.Filter, doc interface{}) error {
+	return nil
+}
+
+type memFind struct {
+	types.Find
+	table  *memTable
+	filter types.Filter
+}
+
+func (f *memFind) Fields(fields ...string) types.Find { return f }
+func (f *memFind) Sort(sort string) types.Find        { return f }
+func (f *memFind) Start(start uint64) types.Find      { return f }
+func (f *memFind) Limit(limit uint64) types.Find      { return f }
+
+func (f *memFind) All(ctx context.Context, result interface{}) error {
+	docs := make([]map[string]interface{}, 0)
+	for _, doc := range f.table.db.docs[f.table.name] {
+		if match(doc, f.filter) {
+			docs = append(docs, doc)
+		}
+	}
+	reflect.ValueOf(result).Elem().Set(reflect.ValueOf(docs))
+	return nil
+}
+
+func (f *memFind) Count(ctx context.Context) (uint64, error) {
+	docs := make([]map[string]interface{}, 0)
+	if err := f.All(ctx, &docs); err != nil {
+		return 0, err
+	}
+	return uint64(len(docs)), nil
+}
+
+func (f *memFind) Iterate(ctx context.Context, result interface{}, handler func() error) error {
+	docs := make([]map[string]interface{}, 0)
+	if err := f.All(ctx, &docs); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		reflect.ValueOf(result).Elem().Set(reflect.ValueOf(doc))
+		if err := handler(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func match(doc map[string]interface{}, filter types.Filter) bool {
+	cond, _ := toMap(filter)
+	for field, expected := range cond {
+		if in, ok := expected.(map[string]interface{}); ok {
+			found := false
+			for _, item := range in[common.BKDBIN].([]string) {
+				found = found || doc[field] == item
+			}
+			if !found {
+				return false
+			}
+			continue
+		}
+		if doc[field] != expected {
+			return false
+		}
+	}
+	return true
+}
+
+func tenantContext(owner string) context.Context {
+	return context.WithValue(context.Background(), common.ContextRequestOwnerField, owner)
+}
+
+func findOwners(t *testing.T, db dal.DB, ctx context.Context, table string, filter types.Filter) []string {
+	docs := make([]map[string]interface{}, 0)
+	if err := db.Table(table).Find(filter).All(ctx, &docs); err != nil {
+		t.Fatalf("find %s with filter %v failed, err: %v", table, filter, err)
+	}
+	owners := make([]string, 0)
+	for _, doc := range docs {
+		owners = append(owners, doc[common.BkSupplierAccount].(string))
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+func TestFindIsScopedToTenant(t *testing.T) {
+	db := New(newMemDB(), GlobalTables...)
+
+	cases := []struct {
+		ctx    context.Context
+		table  string
+		filter types.Filter
+		owners []string
+	}{
+		{ctx: tenantContext("tenant_a"), table: testTable, filter: nil, owners: []string{"0", "tenant_a"}},
+		{ctx: tenantContext("tenant_b"), table: testTable, filter: mapstr.MapStr{}, owners: []string{"0", "tenant_b"}},
+		{ctx: tenantContext("tenant_a"), table: testTable, filter: map[string]interface{}{common.BkSupplierAccount: "tenant_a"},
+			owners: []string{"tenant_a"}},
+		// global tables and the contexts without tenant are not scoped
+		{ctx: tenantContext("tenant_a"), table: common.BKTableNameSystem, filter: nil, owners: []string{"0", "tenant_a", "tenant_b"}},
+		{ctx: context.Background(), table: testTable, filter: nil, owners: []string{"0", "tenant_a", "tenant_b"}},
+		{ctx: tenantContext(common.BKSuperOwnerID), table: testTable, filter: nil, owners: []string{"0", "tenant_a", "tenant_b"}},
+	}
+
+	for _, ca := range cases {
+		owners := findOwners(t, db, ca.ctx, ca.table, ca.filter)
+		if !reflect.DeepEqual(owners, ca.owners) {
+			t.Errorf("find %s with filter %v, expected owners %v, got %v", ca.table, ca.filter, ca.owners, owners)
+		}
+	}
+
+	count, err := db.Table(testTable).Find(nil).Count(tenantContext("tenant_a"))
+	if err != nil || count != 2 {
+		t.Errorf("count tenant_a's instances, expected 2, got %d, err: %v", count, err)
+	}
+}
+
+func TestIterateIsScopedToTenant(t *testing.T) {
+	db := New(newMemDB(), GlobalTables...)
+
+	owners := make([]string, 0)
+	doc := make(map[string]interface{})
+	err := db.Table(testTable).Find(nil).Iterate(tenantContext("tenant_b"), &doc, func() error {
+		owners = append(owners, doc[common.BkSupplierAccount].(string))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("iterate tenant_b's instances failed, err: %v", err)
+	}
+	sort.Strings(owners)
+	if !reflect.DeepEqual(owners, []string{"0", "tenant_b"}) {
+		t.Errorf("iterate tenant_b's instances, expected owners %v, got %v", []string{"0", "tenant_b"}, owners)
+	}
+
+	filter := map[string]interface{}{common.BkSupplierAccount: "tenant_a"}
+	err = db.Table(testTable).Find(filter).Iterate(tenantContext("tenant_b"), &doc, func() error { return nil })
+	if err != ErrCrossTenant {
+		t.Errorf("iterate tenant_a's instances by tenant_b, expected cross tenant error, got err: %v", err)
+	}
+}
+
+func TestCrossTenantReadFails(t *testing.T) {
+	db := New(newMemDB(), GlobalTables...)
+	ctx := tenantContext("tenant_a")
+
+	filters := []types.Filter{
+		map[string]interface{}{common.BkSupplierAccount: "tenant_b"},
+		map[string]interface{}{common.BkSupplierAccount: map[string]interface{}{common.BKDBIN: []string{"tenant_a", "tenant_b"}}},
+		map[string]interface{}{common.BkSupplierAccount: map[string]interface{}{common.BKDBNE: "tenant_a"}},
+		mapstr.MapStr{common.BkSupplierAccount: mapstr.MapStr{common.BKDBEQ: "tenant_b"}},
+	}
+	for _, filter := range filters {
+		docs := make([]map[string]interface{}, 0)
+		if err := db.Table(testTable).Find(filter).All(ctx, &docs); err != ErrCrossTenant {
+			t.Errorf("find with filter %v, expected cross tenant error, got docs %v, err: %v", filter, docs, err)
+		}
+		if _, err := db.Table(testTable).Find(filter).Count(ctx); err != ErrCrossTenant {
+			t.Errorf("count with filter %v, expected cross tenant error, got err: %v", filter, err)
+		}
+		if err := db.Table(testTable).Delete(ctx, filter); err != ErrCrossTenant {
+			t.Errorf("delete with filter %v, expected cross tenant error, got err: %v", filter, err)
+		}
+	}
+}
+
+// the background jobs and the system callers, like the api token validation, read the data of any tenant
+// with the super owner, while the default supplier account is a tenant too.
+func TestSystemCrossTenantRead(t *testing.T) {
+	db := New(newMemDB(), GlobalTables...)
+	filter := map[string]interface{}{common.BkSupplierAccount: "tenant_a"}
+
+	owners := findOwners(t, db, tenantContext(common.BKSuperOwnerID), testTable, filter)
+	if !reflect.DeepEqual(owners, []string{"tenant_a"}) {
+		t.Errorf("read tenant_a's instances by the system, expected owners %v, got %v", []string{"tenant_a"}, owners)
+	}
+
+	docs := make([]map[string]interface{}, 0)
+	if err := db.Table(testTable).Find(filter).All(tenantContext(common.BKDefaultOwnerID), &docs); err != ErrCrossTenant {
+		t.Errorf("read tenant_a's instances by the default supplier account, expected cross tenant error, got docs %v, err: %v",
+			docs, err)
+	}
+}
+
+func TestWriteIsScopedToTenant(t *testing.T) {
+	db := New(newMemDB(), GlobalTables...)
+
+	if err := db.Table(testTable).Delete(tenantContext("tenant_a"), nil); err != nil {
+		t.Fatalf("delete tenant_a's instances failed, err: %v", err)
+	}
+	owners := findOwners(t, db, context.Background(), testTable, nil)
+	if !reflect.DeepEqual(owners, []string{"0", "tenant_b"}) {
+		t.Errorf("delete tenant_a's instances, expected the other tenants' instances are kept, got %v", owners)
+	}
+
+	doc := map[string]interface{}{common.BkSupplierAccount: "tenant_b"}
+	if err := db.Table(testTable).Update(tenantContext("tenant_a"), nil, doc); err != ErrCrossTenant {
+		t.Errorf("update instances to tenant_b by tenant_a, expected cross tenant error, got err: %v", err)
+	}
+}
+
+func TestAggregateIsScopedToTenant(t *testing.T) {
+	mem := newMemDB()
+	db := New(mem, GlobalTables...)
+
+	pipeline := []map[string]interface{}{{common.BKDBGroup: map[string]interface{}{"_id": "$bk_obj_id"}}}
+	if err := db.Table(testTable).AggregateAll(tenantContext("tenant_a"), pipeline, nil); err != nil {
+		t.Fatalf("aggregate failed, err: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{common.BKDBMatch: map[string]interface{}{
+			common.BkSupplierAccount: map[string]interface{}{common.BKDBIN: []string{common.BKDefaultOwnerID, "tenant_a"}},
+		}},
+		pipeline[0],
+	}
+	if len(mem.pipelines) != 1 || !reflect.DeepEqual(mem.pipelines[0], expected) {
+		t.Errorf("aggregate by tenant_a, expected pipeline %v, got %v", expected, mem.pipelines)
+	}
+
+	if err := db.Table(testTable).AggregateAll(tenantContext("tenant_a"), pipeline[0], nil); err != ErrUnsupportedPipeline {
+		t.Errorf("aggregate with invalid pipeline, expected unsupported pipeline error, got err: %v", err)
+	}
+}
@@ -92,6 +92,8 @@ type Find interface {
 	One(ctx context.Context, result interface{}) error
 	// Count 统计数量(非事务)
 	Count(ctx context.Context) (uint64, error)
+	// Iterate 通过游标逐条遍历查询结果，每条结果解码到result后调用handler，handler返回错误时停止遍历
+	Iterate(ctx context.Context, result interface{}, handler func() error) error
 }
 
 // ModeUpdate  根据不同的操作符去更新数据
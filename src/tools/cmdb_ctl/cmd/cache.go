@@ -0,0 +1,138 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/types"
+	"configcenter/src/source_controller/coreservice/cache/checker"
+	ccRedis "configcenter/src/storage/dal/redis"
+	"configcenter/src/tools/cmdb_ctl/app/config"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewCacheCommand())
+}
+
+type cacheCheckConf struct {
+	resources  string
+	sampleSize int64
+	repair     bool
+}
+
+func NewCacheCommand() *cobra.Command {
+	conf := new(cacheCheckConf)
+
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "cache operations",
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "check whether the cache in redis is consistent with mongodb, and repair the mismatched keys with --repair",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheCheck(conf)
+		},
+	}
+	conf.addFlags(checkCmd)
+	cmd.AddCommand(checkCmd)
+
+	return cmd
+}
+
+func (c *cacheCheckConf) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&c.resources, "resources", "", fmt.Sprintf("the cache resources to check, separated by comma, "+
+		"check all the resources if not set. supported resources: %s", strings.Join(metadata.CacheResources, ",")))
+	cmd.Flags().Int64Var(&c.sampleSize, "sample-size", 0, "the max count of keys checked for each resource, 0 means check all the keys")
+	cmd.Flags().BoolVar(&c.repair, "repair", false, "rewrite the mismatched keys with the data in mongodb, or delete them if the data not exist")
+}
+
+func (c *cacheCheckConf) option() *metadata.CheckCacheOption {
+	opt := &metadata.CheckCacheOption{
+		Resources:  make([]string, 0),
+		SampleSize: c.sampleSize,
+		Repair:     c.repair,
+	}
+	for _, resource := range strings.Split(c.resources, ",") {
+		if resource = strings.TrimSpace(resource); resource != "" {
+			opt.Resources = append(opt.Resources, resource)
+		}
+	}
+	return opt
+}
+
+func runCacheCheck(c *cacheCheckConf) error {
+	opt := c.option()
+	if _, err := opt.Validate(); err != nil {
+		return err
+	}
+
+	mongoService, err := config.NewMongoService(config.Conf.MongoURI, config.Conf.MongoRsName)
+	if err != nil {
+		return err
+	}
+
+	redisConfig, err := getRedisConfig(config.Conf.ZkAddr)
+	if err != nil {
+		return err
+	}
+	rds, err := ccRedis.NewFromConfig(redisConfig)
+	if err != nil {
+		return fmt.Errorf("connect redis [%s] failed: %v", redisConfig.Address, err)
+	}
+
+	results, err := checker.NewChecker(rds, mongoService.DbProxy, nil).Check(context.Background(), opt)
+	for _, result := range results {
+		out, _ := json.MarshalIndent(result, "", "    ")
+		if result.Mismatched == 0 {
+			fmt.Print(WithGreenColor(fmt.Sprintf("%s cache is consistent, checked %d keys", result.Resource, result.Checked)))
+		} else {
+			fmt.Print(WithRedColor(fmt.Sprintf("%s cache has %d mismatched keys", result.Resource, result.Mismatched)))
+		}
+		fmt.Println(string(out))
+	}
+	return err
+}
+
+// getRedisConfig gets the redis config of cmdb from zookeeper.
+func getRedisConfig(zkAddr string) (ccRedis.Config, error) {
+	service, err := config.NewZkService(zkAddr)
+	if err != nil {
+		return ccRedis.Config{}, err
+	}
+
+	path := fmt.Sprintf("%s/%s", types.CC_SERVCONF_BASEPATH, types.CCConfigureRedis)
+	strConf, err := service.ZkCli.Get(path)
+	if err != nil {
+		return ccRedis.Config{}, fmt.Errorf("get path [%s] from zk [%v] failed: %v", path, service.ZkCli.ZkHost, err)
+	}
+
+	procConf, err := configcenter.ParseConfigWithData([]byte(strConf))
+	if err != nil {
+		return ccRedis.Config{}, fmt.Errorf("get path [%s] from zk [%v] parse config failed: %v", path, service.ZkCli.ZkHost, err)
+	}
+
+	return ccRedis.ParseConfigFromKV("redis", procConf.ConfigMap), nil
+}
@@ -30,6 +30,9 @@ import (
 	"github.com/rentiansheng/xlsx"
 )
 
+// extFieldLabelsID is the export only column of the instance's labels
+const extFieldLabelsID = "cc_ext_field_labels"
+
 // BuildExcelFromData product excel from data
 func (lgc *Logics) BuildExcelFromData(ctx context.Context, objID string, fields map[string]Property, filter []string, data []mapstr.MapStr, xlsxFile *xlsx.File, header http.Header, meta *metadata.Metadata) error {
 	rid := util.GetHTTPCCRequestID(header)
@@ -42,6 +45,10 @@ func (lgc *Logics) BuildExcelFromData(ctx context.Context, objID string, fields
 		return err
 
 	}
+	extFields := []extField{
+		{ID: extFieldLabelsID, Name: ccLang.Language("web_ext_field_labels")},
+	}
+	fields = addExtFields(fields, extFields)
 	addSystemField(fields, common.BKInnerObjIDObject, ccLang)
 
 	if 0 == len(filter) {
@@ -64,6 +71,7 @@ func (lgc *Logics) BuildExcelFromData(ctx context.Context, objID string, fields
 			blog.Errorf("setExcelRowDataByIndex inst:%+v, not inst id key:%s, objID:%s, rid:%s", rowMap, instIDKey, objID, rid)
 			return ccErr.Errorf(common.CCErrCommInstFieldNotFound, "instIDKey", objID)
 		}
+		rowMap[extFieldLabelsID] = getLabelsString(rowMap)
 
 		primaryKeyArr := setExcelRowDataByIndex(rowMap, sheet, rowIndex, fields)
 
@@ -91,8 +99,9 @@ func (lgc *Logics) BuildHostExcelFromData(ctx context.Context, objID string, fie
 		return err
 	}
 	extFieldsTopoID := "cc_ext_field_topo"
-	extFields := map[string]string{
-		extFieldsTopoID: ccLang.Language("web_ext_field_topo"),
+	extFields := []extField{
+		{ID: extFieldsTopoID, Name: ccLang.Language("web_ext_field_topo")},
+		{ID: extFieldLabelsID, Name: ccLang.Language("web_ext_field_labels")},
 	}
 	fields = addExtFields(fields, extFields)
 	addSystemField(fields, common.BKInnerObjIDHost, ccLang)
@@ -115,6 +124,7 @@ func (lgc *Logics) BuildHostExcelFromData(ctx context.Context, objID string, fie
 			topo := util.GetStrValsFromArrMapInterfaceByKey(moduleMap, "TopModuleName")
 			rowMap[extFieldsTopoID] = strings.Join(topo, "\n")
 		}
+		rowMap[extFieldLabelsID] = getLabelsString(rowMap)
 
 		instIDKey := metadata.GetInstIDFieldByObjID(objID)
 		instID, err := rowMap.Int64(instIDKey)
@@ -19,6 +19,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/fieldpermission"
 	lang "configcenter/src/common/language"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
@@ -72,6 +73,31 @@ func (lgc *Logics) GetObjFieldIDs(objID string, filterFields []string, customFie
 	return ret, nil
 }
 
+// FilterExportFields filter the fields which can not be read by the user from the exported fields,
+// fields in strip hidden mode are not exported, fields in mask hidden mode are exported with the
+// masked values, the exported datas are already stripped or masked by the search apis.
+func (lgc *Logics) FilterExportFields(ctx context.Context, objID string, fields map[string]Property, header http.Header) error {
+	rid := util.GetHTTPCCRequestID(header)
+	permission, err := fieldpermission.NewFieldPermission(lgc.CoreAPI, header).GetObjectFieldPermission(ctx, objID)
+	if err != nil {
+		blog.Errorf("get object %s field permission failed, err: %v, rid: %s", objID, err, rid)
+		return err
+	}
+
+	for id, field := range fields {
+		if permission.CanRead(id) {
+			continue
+		}
+		// masked value can not be used as the instance's primary key.
+		field.IsOnly = false
+		if permission.Permissions[id].HiddenMode != metadata.FieldHiddenModeMask {
+			field.NotExport = true
+		}
+		fields[id] = field
+	}
+	return nil
+}
+
 func (lgc *Logics) getObjectGroup(objID string, header http.Header, meta *metadata.Metadata) ([]PropertyGroup, error) {
 	rid := util.GetHTTPCCRequestID(header)
 	ownerID := util.GetOwnerID(header)
@@ -14,9 +14,12 @@ package logics
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/selector"
 
 	"github.com/rentiansheng/xlsx"
 )
@@ -152,8 +155,14 @@ func getCellStyle(fgColor, fontColor string) *xlsx.Style {
 	return style
 }
 
-// addExtFields  add extra fields,
-func addExtFields(fields map[string]Property, extFields map[string]string) map[string]Property {
+// extField is an export only column which is not a property of the object
+type extField struct {
+	ID   string
+	Name string
+}
+
+// addExtFields  add extra fields after the object's fields, in the order of the extFields
+func addExtFields(fields map[string]Property, extFields []extField) map[string]Property {
 	excelColIndex := 0
 	for _, field := range fields {
 		if excelColIndex < field.ExcelColIndex {
@@ -161,11 +170,11 @@ func addExtFields(fields map[string]Property, extFields map[string]string) map[s
 		}
 	}
 	excelColIndex++
-	for extFieldID, extFieldName := range extFields {
+	for _, ext := range extFields {
 
-		fields[extFieldID] = Property{
+		fields[ext.ID] = Property{
 			ID:            "",
-			Name:          extFieldName,
+			Name:          ext.Name,
 			NotObjPropery: true,
 			ExcelColIndex: excelColIndex,
 		}
@@ -173,3 +182,18 @@ func addExtFields(fields map[string]Property, extFields map[string]string) map[s
 	}
 	return fields
 }
+
+// getLabelsString format the instance's labels as key1=value1,key2=value2, the labels are sorted by key
+func getLabelsString(rowMap mapstr.MapStr) string {
+	labels, err := rowMap.MapStr(selector.LabelsField)
+	if err != nil || len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0)
+	for key, value := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
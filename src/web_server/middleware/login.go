@@ -14,6 +14,7 @@ package middleware
 
 import (
 	"strings"
+	"time"
 
 	"configcenter/src/apimachinery/discovery"
 	"configcenter/src/common"
@@ -134,6 +135,13 @@ func isAuthed(c *gin.Context, config options.Config) bool {
 	if nil != err || bkToken != ccToken {
 		return user.LoginUser(c)
 	}
+
+	// the expired login session is refreshed by the login plugin
+	expireTime, ok := session.Get(common.WEBSessionExpireTimeKey).(int64)
+	if ok && expireTime > 0 && time.Now().Unix() >= expireTime {
+		blog.V(4).Infof("login session of user %s expired, refresh it, rid: %s", userName, rid)
+		return user.LoginUser(c)
+	}
 	return true
 
 }
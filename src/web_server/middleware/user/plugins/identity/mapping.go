@@ -0,0 +1,102 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package identity contains the helpers shared by the login plugins authenticating users with an external
+// identity system, such as the oidc and ldap login plugins.
+package identity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+)
+
+// Mapping maps the groups users belong to in the identity system to supplier accounts and roles of cmdb
+type Mapping struct {
+	SupplierAccounts map[string]string
+	Roles            map[string]string
+}
+
+// NewMapping parse the group mapping config items of the login plugin, the items are
+// <prefix>.group_supplier_accounts and <prefix>.group_roles, both in "group1:value1,group2:value2" format.
+func NewMapping(config map[string]string, prefix string) (*Mapping, error) {
+	supplierAccounts, err := ParseMapping(config[prefix+".group_supplier_accounts"])
+	if err != nil {
+		return nil, fmt.Errorf("%s.group_supplier_accounts is invalid, %v", prefix, err)
+	}
+	roles, err := ParseMapping(config[prefix+".group_roles"])
+	if err != nil {
+		return nil, fmt.Errorf("%s.group_roles is invalid, %v", prefix, err)
+	}
+	return &Mapping{SupplierAccounts: supplierAccounts, Roles: roles}, nil
+}
+
+// ParseMapping parse "key1:value1,key2:value2" into a map, an empty string means no mapping
+func ParseMapping(mapping string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, item := range strings.Split(mapping, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+		pair := strings.SplitN(item, ":", 2)
+		if len(pair) != 2 || len(strings.TrimSpace(pair[0])) == 0 || len(strings.TrimSpace(pair[1])) == 0 {
+			return nil, fmt.Errorf("item %s should be in key:value format", item)
+		}
+		result[strings.TrimSpace(pair[0])] = strings.TrimSpace(pair[1])
+	}
+	return result, nil
+}
+
+// Apply set the supplier accounts and role of the user by the groups the user belongs to. Users not in any
+// group mapped to a supplier account use the default supplier account. The current supplier account is kept
+// if the user still has it, otherwise the one of the first group is used.
+func (m *Mapping) Apply(user *metadata.LoginUserInfo, groups []string, currentOwnerID string) {
+	ownerIDs := make([]string, 0)
+	exists := make(map[string]bool)
+	roles := make([]string, 0)
+	for _, group := range groups {
+		if ownerID, ok := m.SupplierAccounts[group]; ok && !exists[ownerID] {
+			exists[ownerID] = true
+			ownerIDs = append(ownerIDs, ownerID)
+		}
+		if role, ok := m.Roles[group]; ok {
+			roles = append(roles, role)
+		}
+	}
+	if len(ownerIDs) == 0 {
+		ownerIDs = append(ownerIDs, common.BKDefaultOwnerID)
+	}
+
+	user.OnwerUin = ownerIDs[0]
+	if exists[currentOwnerID] {
+		user.OnwerUin = currentOwnerID
+	}
+	user.OwnerUinArr = make([]metadata.LoginUserInfoOwnerUinList, 0)
+	for _, ownerID := range ownerIDs {
+		// supplier accounts of cmdb are numeric, others use the default supplier id
+		supplierID, _ := strconv.ParseInt(ownerID, 10, 64)
+		if ownerID == user.OnwerUin {
+			user.SupplierID = supplierID
+		}
+		user.OwnerUinArr = append(user.OwnerUinArr, metadata.LoginUserInfoOwnerUinList{
+			OwnerID:    ownerID,
+			OwnerName:  ownerID,
+			SupplierID: supplierID,
+		})
+	}
+	user.MultiSupplier = len(ownerIDs) > 1
+	user.Role = strings.Join(roles, ",")
+}
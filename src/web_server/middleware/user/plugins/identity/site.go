@@ -0,0 +1,40 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package identity
+
+import (
+	"strings"
+
+	"configcenter/src/common"
+)
+
+// SiteURL returns the url of cmdb site without the trailing slash by the http scheme users visit it
+func SiteURL(config map[string]string, httpScheme string) string {
+	siteURL := config["site.domain_url"]
+	if common.LogoutHTTPSchemeHTTPS == httpScheme {
+		siteURL = config["site.https_domain_url"]
+	}
+	return strings.TrimSuffix(siteURL, "/")
+}
+
+// RedirectURL returns the url to redirect to after login, only the urls of cmdb site are allowed
+// to avoid open redirect, the site url is returned for the others.
+func RedirectURL(config map[string]string, redirectURL string) string {
+	for _, siteURL := range []string{SiteURL(config, common.LogoutHTTPSchemeHTTP),
+		SiteURL(config, common.LogoutHTTPSchemeHTTPS)} {
+		if len(siteURL) != 0 && (redirectURL == siteURL || strings.HasPrefix(redirectURL, siteURL+"/")) {
+			return redirectURL
+		}
+	}
+	return SiteURL(config, common.LogoutHTTPSchemeHTTP)
+}
@@ -0,0 +1,362 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/web_server/middleware/user/plugins/identity"
+
+	goldap "gopkg.in/ldap.v3"
+)
+
+var (
+	errInvalidCredentials = errors.New("invalid user name or password")
+	errUserNotFound       = errors.New("user not found")
+)
+
+// options is the ldap login plugin config
+type options struct {
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+	// the service account used to search users and groups, anonymous bind is used if it's empty
+	BindDN       string
+	BindPassword string
+
+	BaseDN        string
+	UserFilter    string
+	UserNameAttr  string
+	DisplayAttr   string
+	EmailAttr     string
+	PhoneAttr     string
+	GroupBaseDN   string
+	GroupFilter   string
+	GroupNameAttr string
+
+	// SessionTTL is how long the login session lasts before the user and groups are looked up again
+	SessionTTL time.Duration
+	Mapping    *identity.Mapping
+}
+
+func parseOptions(config map[string]string) (*options, error) {
+	opts := &options{
+		URL:           config["ldap.url"],
+		BindDN:        config["ldap.bind_dn"],
+		BindPassword:  config["ldap.bind_password"],
+		BaseDN:        config["ldap.base_dn"],
+		UserFilter:    "(uid=%s)",
+		UserNameAttr:  "uid",
+		DisplayAttr:   "cn",
+		EmailAttr:     "mail",
+		PhoneAttr:     "telephoneNumber",
+		GroupBaseDN:   config["ldap.group_base_dn"],
+		GroupFilter:   "(member=%s)",
+		GroupNameAttr: "cn",
+		Timeout:       10 * time.Second,
+		SessionTTL:    time.Hour,
+	}
+	if len(opts.URL) == 0 {
+		return nil, fmt.Errorf("ldap.url not set")
+	}
+	if len(opts.BaseDN) == 0 {
+		return nil, fmt.Errorf("ldap.base_dn not set")
+	}
+	if len(opts.GroupBaseDN) == 0 {
+		opts.GroupBaseDN = opts.BaseDN
+	}
+
+	for key, value := range map[string]*string{
+		"ldap.user_filter":          &opts.UserFilter,
+		"ldap.username_attribute":   &opts.UserNameAttr,
+		"ldap.display_attribute":    &opts.DisplayAttr,
+		"ldap.email_attribute":      &opts.EmailAttr,
+		"ldap.phone_attribute":      &opts.PhoneAttr,
+		"ldap.group_filter":         &opts.GroupFilter,
+		"ldap.group_name_attribute": &opts.GroupNameAttr,
+	} {
+		if len(config[key]) != 0 {
+			*value = config[key]
+		}
+	}
+	if strings.Count(opts.UserFilter, "%s") != 1 {
+		return nil, fmt.Errorf("ldap.user_filter should contain one %%s for the user name")
+	}
+	if strings.Count(opts.GroupFilter, "%s") != 1 {
+		return nil, fmt.Errorf("ldap.group_filter should contain one %%s for the user dn")
+	}
+
+	var err error
+	if opts.StartTLS, err = parseBool(config, "ldap.start_tls"); err != nil {
+		return nil, err
+	}
+	if opts.InsecureSkipVerify, err = parseBool(config, "ldap.insecure_skip_verify"); err != nil {
+		return nil, err
+	}
+	if ttl := config["ldap.session_ttl"]; len(ttl) != 0 {
+		seconds, err := strconv.ParseInt(ttl, 10, 64)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("ldap.session_ttl should be a positive number of seconds")
+		}
+		opts.SessionTTL = time.Duration(seconds) * time.Second
+	}
+
+	if opts.Mapping, err = identity.NewMapping(config, "ldap"); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+func parseBool(config map[string]string, key string) (bool, error) {
+	if len(config[key]) == 0 {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(config[key])
+	if err != nil {
+		return false, fmt.Errorf("%s should be true or false", key)
+	}
+	return value, nil
+}
+
+// directoryUser is the user and the groups the user belongs to in the directory
+type directoryUser struct {
+	DN          string
+	UserName    string
+	DisplayName string
+	Email       string
+	Phone       string
+	Groups      []string
+}
+
+type directory struct {
+	opts *options
+}
+
+// authenticate the user with the password by binding as the user
+func (d *directory) authenticate(userName, password string) (*directoryUser, error) {
+	// an empty password is an unauthenticated bind which always succeeds
+	if len(userName) == 0 || len(password) == 0 {
+		return nil, errInvalidCredentials
+	}
+
+	conn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := d.bindServiceAccount(conn); err != nil {
+		return nil, err
+	}
+	user, err := d.searchUser(conn, userName)
+	if err == errUserNotFound {
+		return nil, errInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(user.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, errInvalidCredentials
+		}
+		return nil, fmt.Errorf("bind as user %s failed, %v", user.DN, err)
+	}
+
+	// groups are searched by the service account, users may have no permission to search them
+	if err := d.bindServiceAccount(conn); err != nil {
+		return nil, err
+	}
+	if user.Groups, err = d.searchGroups(conn, user.DN); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// lookup the user and groups by the service account, it's used to refresh the login session
+func (d *directory) lookup(userName string) (*directoryUser, error) {
+	conn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := d.bindServiceAccount(conn); err != nil {
+		return nil, err
+	}
+	user, err := d.searchUser(conn, userName)
+	if err != nil {
+		return nil, err
+	}
+	if user.Groups, err = d.searchGroups(conn, user.DN); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (d *directory) dial() (*goldap.Conn, error) {
+	address, err := url.Parse(d.opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ldap url %s, %v", d.opts.URL, err)
+	}
+	host := address.Host
+	tlsConfig := &tls.Config{
+		ServerName:         address.Hostname(),
+		InsecureSkipVerify: d.opts.InsecureSkipVerify,
+	}
+
+	var conn *goldap.Conn
+	switch address.Scheme {
+	case "ldap":
+		if address.Port() == "" {
+			host = net.JoinHostPort(address.Hostname(), "389")
+		}
+		conn, err = goldap.Dial("tcp", host)
+	case "ldaps":
+		if address.Port() == "" {
+			host = net.JoinHostPort(address.Hostname(), "636")
+		}
+		conn, err = goldap.DialTLS("tcp", host, tlsConfig)
+	default:
+		return nil, fmt.Errorf("unsupported ldap url scheme %s", address.Scheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to ldap server %s failed, %v", d.opts.URL, err)
+	}
+	conn.SetTimeout(d.opts.Timeout)
+
+	if d.opts.StartTLS && address.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("start tls with ldap server %s failed, %v", d.opts.URL, err)
+		}
+	}
+	return conn, nil
+}
+
+func (d *directory) bindServiceAccount(conn *goldap.Conn) error {
+	if len(d.opts.BindDN) == 0 {
+		return nil
+	}
+	if err := conn.Bind(d.opts.BindDN, d.opts.BindPassword); err != nil {
+		return fmt.Errorf("bind as service account %s failed, %v", d.opts.BindDN, err)
+	}
+	return nil
+}
+
+func (d *directory) searchUser(conn *goldap.Conn, userName string) (*directoryUser, error) {
+	request := goldap.NewSearchRequest(
+		d.opts.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, int(d.opts.Timeout.Seconds()), false,
+		fmt.Sprintf(d.opts.UserFilter, goldap.EscapeFilter(userName)),
+		[]string{d.opts.UserNameAttr, d.opts.DisplayAttr, d.opts.EmailAttr, d.opts.PhoneAttr},
+		nil,
+	)
+	result, err := conn.Search(request)
+	if err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+			return nil, fmt.Errorf("more than one user matches %s", userName)
+		}
+		return nil, fmt.Errorf("search user %s failed, %v", userName, err)
+	}
+	if len(result.Entries) == 0 {
+		return nil, errUserNotFound
+	}
+	if len(result.Entries) > 1 {
+		return nil, fmt.Errorf("more than one user matches %s", userName)
+	}
+
+	entry := result.Entries[0]
+	user := &directoryUser{
+		DN:          entry.DN,
+		UserName:    entry.GetAttributeValue(d.opts.UserNameAttr),
+		DisplayName: entry.GetAttributeValue(d.opts.DisplayAttr),
+		Email:       entry.GetAttributeValue(d.opts.EmailAttr),
+		Phone:       entry.GetAttributeValue(d.opts.PhoneAttr),
+	}
+	if len(user.UserName) == 0 {
+		user.UserName = userName
+	}
+	if len(user.DisplayName) == 0 {
+		user.DisplayName = user.UserName
+	}
+	return user, nil
+}
+
+func (d *directory) searchGroups(conn *goldap.Conn, userDN string) ([]string, error) {
+	request := goldap.NewSearchRequest(
+		d.opts.GroupBaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, int(d.opts.Timeout.Seconds()), false,
+		fmt.Sprintf(d.opts.GroupFilter, goldap.EscapeFilter(userDN)),
+		[]string{d.opts.GroupNameAttr},
+		nil,
+	)
+	result, err := conn.Search(request)
+	if err != nil {
+		return nil, fmt.Errorf("search groups of user %s failed, %v", userDN, err)
+	}
+
+	groups := make([]string, 0)
+	for _, entry := range result.Entries {
+		if name := entry.GetAttributeValue(d.opts.GroupNameAttr); len(name) != 0 {
+			groups = append(groups, name)
+		}
+	}
+	return groups, nil
+}
+
+// listUsers list all the users matching the user filter
+func (d *directory) listUsers() ([]*directoryUser, error) {
+	conn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := d.bindServiceAccount(conn); err != nil {
+		return nil, err
+	}
+	request := goldap.NewSearchRequest(
+		d.opts.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, int(d.opts.Timeout.Seconds()), false,
+		fmt.Sprintf(d.opts.UserFilter, "*"),
+		[]string{d.opts.UserNameAttr, d.opts.DisplayAttr},
+		nil,
+	)
+	result, err := conn.SearchWithPaging(request, 500)
+	if err != nil {
+		return nil, fmt.Errorf("list users failed, %v", err)
+	}
+
+	users := make([]*directoryUser, 0)
+	for _, entry := range result.Entries {
+		user := &directoryUser{
+			DN:          entry.DN,
+			UserName:    entry.GetAttributeValue(d.opts.UserNameAttr),
+			DisplayName: entry.GetAttributeValue(d.opts.DisplayAttr),
+		}
+		if len(user.UserName) == 0 {
+			continue
+		}
+		if len(user.DisplayName) == 0 {
+			user.DisplayName = user.UserName
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
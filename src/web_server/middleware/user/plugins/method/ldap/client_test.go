@@ -0,0 +1,237 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	ber "gopkg.in/asn1-ber.v1"
+	goldap "gopkg.in/ldap.v3"
+)
+
+type fakeEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// fakeServer is a stand-in ldap server supporting simple bind and equality or presence search filters
+type fakeServer struct {
+	listener net.Listener
+	entries  []fakeEntry
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{
+		listener: listener,
+		entries: []fakeEntry{
+			{dn: "cn=admin,dc=example,dc=com", password: "admin-pass"},
+			{
+				dn:       "uid=alice,ou=users,dc=example,dc=com",
+				password: "alice-pass",
+				attributes: map[string][]string{
+					"objectClass": {"person"}, "uid": {"alice"}, "cn": {"Alice"}, "mail": {"alice@example.com"},
+				},
+			},
+			{
+				dn:       "uid=bob,ou=users,dc=example,dc=com",
+				password: "bob-pass",
+				attributes: map[string][]string{
+					"objectClass": {"person"}, "uid": {"bob"}, "cn": {"Bob"},
+				},
+			},
+			{
+				dn: "cn=ops,ou=groups,dc=example,dc=com",
+				attributes: map[string][]string{
+					"objectClass": {"groupOfNames"}, "cn": {"ops"},
+					"member": {"uid=alice,ou=users,dc=example,dc=com", "uid=bob,ou=users,dc=example,dc=com"},
+				},
+			},
+			{
+				dn: "cn=dev,ou=groups,dc=example,dc=com",
+				attributes: map[string][]string{
+					"objectClass": {"groupOfNames"}, "cn": {"dev"},
+					"member": {"uid=alice,ou=users,dc=example,dc=com"},
+				},
+			},
+		},
+	}
+	go s.serve()
+	return s
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		request := packet.Children[1]
+		switch request.Tag {
+		case goldap.ApplicationBindRequest:
+			dn := request.Children[1].Value.(string)
+			password := request.Children[2].Data.String()
+			code := goldap.LDAPResultInvalidCredentials
+			for _, entry := range s.entries {
+				if entry.dn == dn && entry.password == password && len(password) != 0 {
+					code = goldap.LDAPResultSuccess
+				}
+			}
+			conn.Write(response(messageID, goldap.ApplicationBindResponse, code).Bytes())
+		case goldap.ApplicationSearchRequest:
+			baseDN := request.Children[0].Value.(string)
+			filter, err := goldap.DecompileFilter(request.Children[6])
+			if err != nil {
+				conn.Write(response(messageID, goldap.ApplicationSearchResultDone, goldap.LDAPResultOperationsError).Bytes())
+				continue
+			}
+			for _, entry := range s.entries {
+				if strings.HasSuffix(entry.dn, baseDN) && matchFilter(entry, filter) {
+					conn.Write(searchEntry(messageID, entry).Bytes())
+				}
+			}
+			conn.Write(response(messageID, goldap.ApplicationSearchResultDone, goldap.LDAPResultSuccess).Bytes())
+		case goldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+// matchFilter match the entry with a filter in (attribute=value) format, value * matches any entry with the attribute
+func matchFilter(entry fakeEntry, filter string) bool {
+	pair := strings.SplitN(strings.Trim(filter, "()"), "=", 2)
+	if len(pair) != 2 {
+		return false
+	}
+	for _, value := range entry.attributes[pair[0]] {
+		if pair[1] == "*" || value == pair[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func response(messageID int64, tag ber.Tag, code int) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	packet.AppendChild(result)
+	return packet
+}
+
+func searchEntry(messageID int64, entry fakeEntry) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "Entry")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "DN"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range entry.attributes {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	result.AppendChild(attributes)
+	packet.AppendChild(result)
+	return packet
+}
+
+func newTestDirectory(t *testing.T, server *fakeServer) *directory {
+	opts, err := parseOptions(map[string]string{
+		"ldap.url":                     "ldap://" + server.listener.Addr().String(),
+		"ldap.bind_dn":                 "cn=admin,dc=example,dc=com",
+		"ldap.bind_password":           "admin-pass",
+		"ldap.base_dn":                 "ou=users,dc=example,dc=com",
+		"ldap.group_base_dn":           "ou=groups,dc=example,dc=com",
+		"ldap.group_supplier_accounts": "ops:0,dev:1",
+	})
+	if err != nil {
+		t.Fatalf("parse options failed, err: %v", err)
+	}
+	return &directory{opts: opts}
+}
+
+func TestAuthenticate(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.listener.Close()
+	dir := newTestDirectory(t, server)
+
+	user, err := dir.authenticate("alice", "alice-pass")
+	if err != nil {
+		t.Fatalf("authenticate failed, err: %v", err)
+	}
+	if user.DN != "uid=alice,ou=users,dc=example,dc=com" || user.DisplayName != "Alice" ||
+		user.Email != "alice@example.com" || strings.Join(user.Groups, ",") != "ops,dev" {
+		t.Fatalf("unexpected user %+v", user)
+	}
+
+	if _, err := dir.authenticate("alice", "wrong-pass"); err != errInvalidCredentials {
+		t.Errorf("wrong password should be rejected, err: %v", err)
+	}
+	if _, err := dir.authenticate("alice", ""); err != errInvalidCredentials {
+		t.Errorf("empty password should be rejected, err: %v", err)
+	}
+	if _, err := dir.authenticate("carol", "carol-pass"); err != errInvalidCredentials {
+		t.Errorf("unknown user should be rejected, err: %v", err)
+	}
+}
+
+func TestLookupAndListUsers(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.listener.Close()
+	dir := newTestDirectory(t, server)
+
+	user, err := dir.lookup("bob")
+	if err != nil {
+		t.Fatalf("lookup failed, err: %v", err)
+	}
+	if user.UserName != "bob" || strings.Join(user.Groups, ",") != "ops" {
+		t.Fatalf("unexpected user %+v", user)
+	}
+	if _, err := dir.lookup("carol"); err != errUserNotFound {
+		t.Errorf("lookup unknown user should return not found, err: %v", err)
+	}
+
+	users, err := dir.listUsers()
+	if err != nil {
+		t.Fatalf("list users failed, err: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expect 2 users, got %d", len(users))
+	}
+}
@@ -0,0 +1,191 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	webCommon "configcenter/src/web_server/common"
+	"configcenter/src/web_server/middleware/user/plugins/identity"
+	"configcenter/src/web_server/middleware/user/plugins/manager"
+
+	"github.com/gin-gonic/gin"
+	"github.com/holmeswang/contrib/sessions"
+)
+
+// sessionKey is the session key of the ldap login session
+const sessionKey = "ldap_login"
+
+func init() {
+	plugin := &metadata.LoginPluginInfo{
+		Name:       "ldap login system",
+		Version:    common.BKLDAPLoginPluginVersion,
+		HandleFunc: &user{},
+	}
+	manager.RegisterPlugin(plugin)
+}
+
+// loginSession is the ldap login of the user saved in session
+type loginSession struct {
+	Token      string   `json:"token"`
+	UserName   string   `json:"username"`
+	ChName     string   `json:"chname"`
+	Email      string   `json:"email"`
+	Phone      string   `json:"phone"`
+	Groups     []string `json:"groups"`
+	ExpireTime int64    `json:"expire_time"`
+}
+
+func (s *loginSession) update(user *directoryUser, ttl time.Duration) {
+	s.UserName = user.UserName
+	s.ChName = user.DisplayName
+	s.Email = user.Email
+	s.Phone = user.Phone
+	s.Groups = user.Groups
+	s.ExpireTime = time.Now().Add(ttl).Unix()
+}
+
+type user struct{}
+
+// LoginUser get the login user from the ldap login session, once the session expires the user and groups are
+// looked up again, the session ends if the user no longer exists.
+func (m *user) LoginUser(c *gin.Context, config map[string]string, isMultiOwner bool) (*metadata.LoginUserInfo, bool) {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	session := sessions.Default(c)
+
+	login := new(loginSession)
+	raw, ok := session.Get(sessionKey).(string)
+	if !ok || json.Unmarshal([]byte(raw), login) != nil {
+		blog.Infof("ldap login session not found, rid: %s", rid)
+		return nil, false
+	}
+
+	opts, err := parseOptions(config)
+	if err != nil {
+		blog.Errorf("ldap login config is invalid, err: %v, rid: %s", err, rid)
+		return nil, false
+	}
+
+	if time.Now().Unix() >= login.ExpireTime {
+		dirUser, err := (&directory{opts: opts}).lookup(login.UserName)
+		if err != nil {
+			blog.Warnf("refresh ldap login session of user %s failed, err: %v, rid: %s", login.UserName, err, rid)
+			session.Delete(sessionKey)
+			if err := session.Save(); err != nil {
+				blog.Warnf("save session failed, err: %s, rid: %s", err.Error(), rid)
+			}
+			return nil, false
+		}
+		login.update(dirUser, opts.SessionTTL)
+		setLoginSession(session, login)
+		blog.V(4).Infof("ldap login session of user %s is refreshed, rid: %s", login.UserName, rid)
+	}
+
+	user := &metadata.LoginUserInfo{
+		UserName:   login.UserName,
+		ChName:     login.ChName,
+		Phone:      login.Phone,
+		Email:      login.Email,
+		BkToken:    login.Token,
+		IsOwner:    false,
+		Language:   webCommon.GetLanguageByHTTPRequest(c),
+		ExpireTime: login.ExpireTime,
+	}
+	currentOwnerID, _ := session.Get(common.WEBSessionOwnerUinKey).(string)
+	opts.Mapping.Apply(user, login.Groups, currentOwnerID)
+	return user, true
+}
+
+// GetLoginUrl returns the login page of web server, users login with the ldap user name and password there
+func (m *user) GetLoginUrl(c *gin.Context, config map[string]string, input *metadata.LogoutRequestParams) string {
+	siteURL := identity.SiteURL(config, input.HTTPScheme)
+	return fmt.Sprintf("%s/login?c_url=%s", siteURL, url.QueryEscape(siteURL+c.Request.URL.String()))
+}
+
+func (m *user) GetUserList(c *gin.Context, config map[string]string) ([]*metadata.LoginSystemUserInfo, *errors.RawErrorInfo) {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	opts, err := parseOptions(config)
+	if err != nil {
+		blog.Errorf("ldap login config is invalid, err: %v, rid: %s", err, rid)
+		return nil, &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginConfigInvalid, Args: []interface{}{"ldap"}}
+	}
+
+	dirUsers, err := (&directory{opts: opts}).listUsers()
+	if err != nil {
+		blog.Errorf("list ldap users failed, err: %v, rid: %s", err, rid)
+		return nil, &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginSystemUnavailable}
+	}
+	users := make([]*metadata.LoginSystemUserInfo, 0)
+	for _, dirUser := range dirUsers {
+		users = append(users, &metadata.LoginSystemUserInfo{
+			CnName: dirUser.DisplayName,
+			EnName: dirUser.UserName,
+		})
+	}
+	return users, nil
+}
+
+// HandleLogin authenticates the user name and password posted by the login page with the ldap server
+func (m *user) HandleLogin(c *gin.Context, config map[string]string) (string, *errors.RawErrorInfo) {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	userName := c.PostForm("username")
+	password := c.PostForm("password")
+	if userName == "" || password == "" {
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebNeedFillinUsernamePasswd}
+	}
+
+	opts, err := parseOptions(config)
+	if err != nil {
+		blog.Errorf("ldap login config is invalid, err: %v, rid: %s", err, rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginConfigInvalid, Args: []interface{}{"ldap"}}
+	}
+
+	dirUser, err := (&directory{opts: opts}).authenticate(userName, password)
+	if err == errInvalidCredentials {
+		blog.Infof("ldap login failed, invalid credentials of user %s, rid: %s", userName, rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebUsernamePasswdWrong}
+	}
+	if err != nil {
+		blog.Errorf("ldap login failed, authenticate user %s failed, err: %v, rid: %s", userName, err, rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginSystemUnavailable}
+	}
+
+	login := new(loginSession)
+	if login.Token, err = identity.NewToken(); err != nil {
+		blog.Errorf("generate login token failed, err: %v, rid: %s", err, rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginSystemUnavailable}
+	}
+	login.update(dirUser, opts.SessionTTL)
+	setLoginSession(sessions.Default(c), login)
+	c.SetCookie(common.HTTPCookieBKToken, login.Token, 0, "/", "", false, true)
+	blog.Infof("user %s login by ldap, rid: %s", login.UserName, rid)
+
+	redirectURL := c.Query("c_url")
+	if redirectURL == "" {
+		redirectURL = c.PostForm("c_url")
+	}
+	return identity.RedirectURL(config, redirectURL), nil
+}
+
+func setLoginSession(session sessions.Session, login *loginSession) {
+	raw, _ := json.Marshal(login)
+	session.Set(sessionKey, string(raw))
+}
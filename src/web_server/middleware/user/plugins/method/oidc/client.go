@@ -0,0 +1,415 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/web_server/middleware/user/plugins/identity"
+)
+
+// options is the oidc login plugin config
+type options struct {
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	Scopes        []string
+	UserNameClaim string
+	GroupsClaim   string
+	Mapping       *identity.Mapping
+}
+
+func parseOptions(config map[string]string) (*options, error) {
+	opts := &options{
+		Issuer:        strings.TrimSuffix(config["oidc.issuer"], "/"),
+		ClientID:      config["oidc.client_id"],
+		ClientSecret:  config["oidc.client_secret"],
+		Scopes:        []string{"openid", "profile", "email"},
+		UserNameClaim: "preferred_username",
+		GroupsClaim:   "groups",
+	}
+	if len(opts.Issuer) == 0 {
+		return nil, fmt.Errorf("oidc.issuer not set")
+	}
+	// the id token signature is not verified, the provider must be trusted through tls
+	if !isHTTPSURL(opts.Issuer) {
+		return nil, fmt.Errorf("oidc.issuer %s is not an https url", opts.Issuer)
+	}
+	if len(opts.ClientID) == 0 {
+		return nil, fmt.Errorf("oidc.client_id not set")
+	}
+	if scopes := strings.Fields(strings.Replace(config["oidc.scopes"], ",", " ", -1)); len(scopes) != 0 {
+		opts.Scopes = scopes
+	}
+	if claim := config["oidc.username_claim"]; len(claim) != 0 {
+		opts.UserNameClaim = claim
+	}
+	if claim := config["oidc.groups_claim"]; len(claim) != 0 {
+		opts.GroupsClaim = claim
+	}
+
+	mapping, err := identity.NewMapping(config, "oidc")
+	if err != nil {
+		return nil, err
+	}
+	opts.Mapping = mapping
+	return opts, nil
+}
+
+// providerConfig is the openid provider metadata returned by the discovery endpoint
+type providerConfig struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// tokenResponse is the response of the token endpoint
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	IDToken      string `json:"id_token"`
+	Error        string `json:"error"`
+	ErrorDesc    string `json:"error_description"`
+}
+
+// httpTransport is the transport used to request the openid provider
+var httpTransport http.RoundTripper = http.DefaultTransport
+
+// the provider metadata rarely changes, it's discovered once for each issuer
+var providerCache = struct {
+	sync.RWMutex
+	providers map[string]*providerConfig
+}{providers: make(map[string]*providerConfig)}
+
+type client struct {
+	opts     *options
+	httpCli  *http.Client
+	provider *providerConfig
+}
+
+func newClient(ctx context.Context, opts *options) (*client, error) {
+	c := &client{
+		opts:    opts,
+		httpCli: &http.Client{Transport: httpTransport, Timeout: 30 * time.Second},
+	}
+
+	providerCache.RLock()
+	provider, exists := providerCache.providers[opts.Issuer]
+	providerCache.RUnlock()
+	if exists {
+		c.provider = provider
+		return c, nil
+	}
+
+	provider = new(providerConfig)
+	if err := c.getJSON(ctx, opts.Issuer+"/.well-known/openid-configuration", "", provider); err != nil {
+		return nil, fmt.Errorf("discover openid provider %s failed, %v", opts.Issuer, err)
+	}
+	if strings.TrimSuffix(provider.Issuer, "/") != opts.Issuer {
+		return nil, fmt.Errorf("openid provider issuer %s not match the configured issuer %s", provider.Issuer,
+			opts.Issuer)
+	}
+	if len(provider.AuthorizationEndpoint) == 0 || len(provider.TokenEndpoint) == 0 {
+		return nil, fmt.Errorf("openid provider %s has no authorization or token endpoint", opts.Issuer)
+	}
+	if !isHTTPSURL(provider.TokenEndpoint) {
+		return nil, fmt.Errorf("openid provider %s token endpoint %s is not an https url", opts.Issuer,
+			provider.TokenEndpoint)
+	}
+
+	providerCache.Lock()
+	providerCache.providers[opts.Issuer] = provider
+	providerCache.Unlock()
+	c.provider = provider
+	return c, nil
+}
+
+// authCodeURL returns the url of the authorization endpoint users are redirected to for login
+func (c *client) authCodeURL(redirectURL, state, nonce string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", c.opts.ClientID)
+	params.Set("redirect_uri", redirectURL)
+	params.Set("scope", strings.Join(c.opts.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	return appendQuery(c.provider.AuthorizationEndpoint, params)
+}
+
+// logoutURL returns the url of the end session endpoint, it's empty if the provider doesn't support logout
+func (c *client) logoutURL(idToken, postLogoutURL string) string {
+	if len(c.provider.EndSessionEndpoint) == 0 {
+		return ""
+	}
+	params := url.Values{}
+	if len(idToken) != 0 {
+		params.Set("id_token_hint", idToken)
+	}
+	params.Set("post_logout_redirect_uri", postLogoutURL)
+	params.Set("client_id", c.opts.ClientID)
+	return appendQuery(c.provider.EndSessionEndpoint, params)
+}
+
+// exchange the authorization code for tokens
+func (c *client) exchange(ctx context.Context, code, redirectURL string) (*tokenResponse, error) {
+	params := url.Values{}
+	params.Set("grant_type", "authorization_code")
+	params.Set("code", code)
+	params.Set("redirect_uri", redirectURL)
+	return c.requestToken(ctx, params)
+}
+
+// refresh the tokens with the refresh token
+func (c *client) refresh(ctx context.Context, refreshToken string) (*tokenResponse, error) {
+	params := url.Values{}
+	params.Set("grant_type", "refresh_token")
+	params.Set("refresh_token", refreshToken)
+	return c.requestToken(ctx, params)
+}
+
+func (c *client) requestToken(ctx context.Context, params url.Values) (*tokenResponse, error) {
+	req, err := http.NewRequest(http.MethodPost, c.provider.TokenEndpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.opts.ClientID), url.QueryEscape(c.opts.ClientSecret))
+
+	resp, err := c.httpCli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	token := new(tokenResponse)
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("decode token response failed, status: %d, err: %v", resp.StatusCode, err)
+	}
+	if len(token.Error) != 0 {
+		return nil, fmt.Errorf("token endpoint returns error %s: %s", token.Error, token.ErrorDesc)
+	}
+	if resp.StatusCode != http.StatusOK || len(token.AccessToken) == 0 {
+		return nil, fmt.Errorf("token endpoint returns status %d without access token", resp.StatusCode)
+	}
+	return token, nil
+}
+
+// parseIDToken decode the claims of the id token and validate them. The id token is received from the token
+// endpoint over tls directly, so the tls server validation is used in place of checking the token signature,
+// as section 3.1.3.7 of openid connect core allows. nonce is not checked if it's empty.
+func (c *client) parseIDToken(idToken, nonce string) (map[string]interface{}, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed id token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("decode id token payload failed, %v", err)
+	}
+
+	claims := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("decode id token claims failed, %v", err)
+	}
+
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != c.opts.Issuer {
+		return nil, fmt.Errorf("id token issuer %s not match %s", iss, c.opts.Issuer)
+	}
+	if !audienceContains(claims["aud"], c.opts.ClientID) {
+		return nil, fmt.Errorf("id token audience %v not contains client id %s", claims["aud"], c.opts.ClientID)
+	}
+	exp, ok := claims["exp"].(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("id token has no expiration")
+	}
+	expireTime, err := exp.Int64()
+	if err != nil || expireTime <= time.Now().Unix() {
+		return nil, fmt.Errorf("id token is expired")
+	}
+	if len(nonce) != 0 {
+		if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+			return nil, fmt.Errorf("id token nonce not match")
+		}
+	}
+	return claims, nil
+}
+
+// userInfo get the claims of the user from the userinfo endpoint, it's empty if the provider has no such endpoint
+func (c *client) userInfo(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	claims := make(map[string]interface{})
+	if len(c.provider.UserInfoEndpoint) == 0 {
+		return claims, nil
+	}
+	if err := c.getJSON(ctx, c.provider.UserInfoEndpoint, accessToken, &claims); err != nil {
+		return nil, fmt.Errorf("get user info failed, %v", err)
+	}
+	return claims, nil
+}
+
+func (c *client) getJSON(ctx context.Context, address, accessToken string, result interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if len(accessToken) != 0 {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := c.httpCli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returns status %d", address, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// updateSession update the login session with the tokens, the user's claims are updated if an id token
+// is returned, otherwise the previous claims are kept.
+func (c *client) updateSession(ctx context.Context, login *loginSession, token *tokenResponse, nonce string) error {
+	login.AccessToken = token.AccessToken
+	if len(token.RefreshToken) != 0 {
+		login.RefreshToken = token.RefreshToken
+	}
+
+	var expireTime int64
+	if len(token.IDToken) != 0 {
+		claims, err := c.parseIDToken(token.IDToken, nonce)
+		if err != nil {
+			return err
+		}
+		if err := c.setUserClaims(ctx, login, claims, token.AccessToken); err != nil {
+			return err
+		}
+		login.IDToken = token.IDToken
+		if exp, ok := claims["exp"].(json.Number); ok {
+			expireTime, _ = exp.Int64()
+		}
+	} else if len(login.UserName) == 0 {
+		return fmt.Errorf("token response has no id token")
+	}
+
+	if token.ExpiresIn > 0 {
+		expireTime = time.Now().Unix() + token.ExpiresIn
+	}
+	if expireTime <= 0 {
+		return fmt.Errorf("token response has no expiration")
+	}
+	login.ExpireTime = expireTime
+	return nil
+}
+
+// setUserClaims set the user of the login session with the id token claims, the claims missing in
+// id token are got from the userinfo endpoint.
+func (c *client) setUserClaims(ctx context.Context, login *loginSession, claims map[string]interface{}, accessToken string) error {
+	_, hasUserName := claims[c.opts.UserNameClaim]
+	_, hasGroups := claims[c.opts.GroupsClaim]
+	if !hasUserName || !hasGroups {
+		userInfo, err := c.userInfo(ctx, accessToken)
+		if err != nil {
+			return err
+		}
+		// the userinfo must belong to the user of id token
+		if sub, ok := userInfo["sub"]; ok && sub != claims["sub"] {
+			return fmt.Errorf("userinfo subject %v not match id token subject %v", sub, claims["sub"])
+		}
+		for key, value := range userInfo {
+			if _, exists := claims[key]; !exists {
+				claims[key] = value
+			}
+		}
+	}
+
+	login.UserName, _ = claims[c.opts.UserNameClaim].(string)
+	if len(login.UserName) == 0 {
+		return fmt.Errorf("claim %s of user name not found", c.opts.UserNameClaim)
+	}
+	login.ChName, _ = claims["name"].(string)
+	if len(login.ChName) == 0 {
+		login.ChName = login.UserName
+	}
+	login.Email, _ = claims["email"].(string)
+	login.Phone, _ = claims["phone_number"].(string)
+	login.Groups = claimStrings(claims[c.opts.GroupsClaim])
+	return nil
+}
+
+// claimStrings convert a claim of string or string array to string array
+func claimStrings(claim interface{}) []string {
+	values := make([]string, 0)
+	switch value := claim.(type) {
+	case string:
+		values = append(values, value)
+	case []interface{}:
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+	return values
+}
+
+func audienceContains(aud interface{}, clientID string) bool {
+	switch audience := aud.(type) {
+	case string:
+		return audience == clientID
+	case []interface{}:
+		for _, item := range audience {
+			if item == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isHTTPSURL check if the address is an absolute https url
+func isHTTPSURL(address string) bool {
+	u, err := url.Parse(address)
+	return err == nil && u.Scheme == "https" && len(u.Host) != 0
+}
+
+func appendQuery(address string, params url.Values) string {
+	if strings.Contains(address, "?") {
+		return address + "&" + params.Encode()
+	}
+	return address + "?" + params.Encode()
+}
@@ -0,0 +1,217 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"configcenter/src/common/metadata"
+)
+
+// fakeProvider is a stand-in openid provider serving over tls and issuing unsigned id tokens
+type fakeProvider struct {
+	server        *httptest.Server
+	nonce         string
+	audience      string
+	tokenEndpoint string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	p := &fakeProvider{audience: "cmdb"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(providerConfig{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.tokenEndpoint,
+			UserInfoEndpoint:      p.server.URL + "/userinfo",
+			EndSessionEndpoint:    p.server.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		if clientID != "cmdb" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_client"})
+			return
+		}
+		r.ParseForm()
+		switch {
+		case r.Form.Get("grant_type") == "authorization_code" && r.Form.Get("code") == "good-code":
+			json.NewEncoder(w).Encode(tokenResponse{
+				AccessToken:  "access-1",
+				RefreshToken: "refresh-1",
+				ExpiresIn:    60,
+				IDToken:      p.idToken(t, p.nonce),
+			})
+		case r.Form.Get("grant_type") == "refresh_token" && r.Form.Get("refresh_token") == "refresh-1":
+			json.NewEncoder(w).Encode(tokenResponse{AccessToken: "access-2", ExpiresIn: 120})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant"})
+		}
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub":    "user-1",
+			"groups": []string{"ops", "admins"},
+		})
+	})
+	p.server = httptest.NewTLSServer(mux)
+	p.tokenEndpoint = p.server.URL + "/token"
+	httpTransport = p.server.Client().Transport
+	return p
+}
+
+func (p *fakeProvider) idToken(t *testing.T, nonce string) string {
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":                p.server.URL,
+		"sub":                "user-1",
+		"aud":                p.audience,
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"nonce":              nonce,
+		"preferred_username": "alice",
+		"name":               "Alice",
+		"email":              "alice@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(claims) + "."
+}
+
+func (p *fakeProvider) config() map[string]string {
+	return map[string]string{
+		"oidc.issuer":                  p.server.URL,
+		"oidc.client_id":               "cmdb",
+		"oidc.client_secret":           "secret",
+		"oidc.group_supplier_accounts": "ops:0,dev:1",
+		"oidc.group_roles":             "admins:admin",
+	}
+}
+
+func TestLoginFlow(t *testing.T) {
+	provider := newFakeProvider(t)
+	defer provider.server.Close()
+	provider.nonce = "nonce-1"
+
+	opts, err := parseOptions(provider.config())
+	if err != nil {
+		t.Fatalf("parse options failed, err: %v", err)
+	}
+	cli, err := newClient(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("create client failed, err: %v", err)
+	}
+
+	authURL, err := url.Parse(cli.authCodeURL("http://cmdb/login/callback", "state-1", "nonce-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authURL.Path != "/authorize" || authURL.Query().Get("state") != "state-1" ||
+		authURL.Query().Get("scope") != "openid profile email" {
+		t.Fatalf("unexpected authorization url %s", authURL)
+	}
+
+	token, err := cli.exchange(context.Background(), "good-code", "http://cmdb/login/callback")
+	if err != nil {
+		t.Fatalf("exchange code failed, err: %v", err)
+	}
+	login := new(loginSession)
+	if err := cli.updateSession(context.Background(), login, token, "nonce-1"); err != nil {
+		t.Fatalf("update session failed, err: %v", err)
+	}
+	if login.UserName != "alice" || login.ChName != "Alice" || len(login.Groups) != 2 {
+		t.Fatalf("unexpected login session %+v", login)
+	}
+
+	user := new(metadata.LoginUserInfo)
+	opts.Mapping.Apply(user, login.Groups, "")
+	if user.OnwerUin != "0" || user.Role != "admin" || user.MultiSupplier {
+		t.Fatalf("unexpected mapped user %+v", user)
+	}
+
+	// the user claims are kept after refresh without a new id token
+	token, err = cli.refresh(context.Background(), login.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh failed, err: %v", err)
+	}
+	if err := cli.updateSession(context.Background(), login, token, ""); err != nil {
+		t.Fatalf("update session after refresh failed, err: %v", err)
+	}
+	if login.AccessToken != "access-2" || login.RefreshToken != "refresh-1" || login.UserName != "alice" {
+		t.Fatalf("unexpected refreshed session %+v", login)
+	}
+	if login.ExpireTime < time.Now().Unix()+100 {
+		t.Fatalf("expire time %d is not updated", login.ExpireTime)
+	}
+
+	if _, err := cli.refresh(context.Background(), "revoked"); err == nil {
+		t.Fatalf("refresh with revoked token should fail")
+	}
+}
+
+func TestParseIDToken(t *testing.T) {
+	provider := newFakeProvider(t)
+	defer provider.server.Close()
+
+	opts, err := parseOptions(provider.config())
+	if err != nil {
+		t.Fatalf("parse options failed, err: %v", err)
+	}
+	cli, err := newClient(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("create client failed, err: %v", err)
+	}
+
+	if _, err := cli.parseIDToken(provider.idToken(t, "nonce-1"), "nonce-2"); err == nil {
+		t.Errorf("id token with another nonce should be rejected")
+	}
+	provider.audience = "another-client"
+	if _, err := cli.parseIDToken(provider.idToken(t, "nonce-1"), "nonce-1"); err == nil {
+		t.Errorf("id token of another audience should be rejected")
+	}
+	if _, err := cli.parseIDToken("not-a-jwt", ""); err == nil {
+		t.Errorf("malformed id token should be rejected")
+	}
+}
+
+func TestRequireHTTPS(t *testing.T) {
+	config := map[string]string{"oidc.issuer": "http://sso.example.com", "oidc.client_id": "cmdb"}
+	if _, err := parseOptions(config); err == nil {
+		t.Errorf("http issuer should be rejected")
+	}
+
+	provider := newFakeProvider(t)
+	defer provider.server.Close()
+	provider.tokenEndpoint = "http://sso.example.com/token"
+
+	opts, err := parseOptions(provider.config())
+	if err != nil {
+		t.Fatalf("parse options failed, err: %v", err)
+	}
+	if _, err := newClient(context.Background(), opts); err == nil {
+		t.Errorf("http token endpoint should be rejected")
+	}
+}
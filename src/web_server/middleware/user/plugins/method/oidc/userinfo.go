@@ -0,0 +1,279 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	webCommon "configcenter/src/web_server/common"
+	"configcenter/src/web_server/middleware/user/plugins/identity"
+	"configcenter/src/web_server/middleware/user/plugins/manager"
+
+	"github.com/gin-gonic/gin"
+	"github.com/holmeswang/contrib/sessions"
+)
+
+const (
+	// sessionKey is the session key of the oidc login session
+	sessionKey = "oidc_login"
+	// stateSessionKey is the session key of the pending authorization request
+	stateSessionKey = "oidc_state"
+	// callbackPath is the path of web server the provider redirects users to after login
+	callbackPath = "/login/callback"
+)
+
+func init() {
+	plugin := &metadata.LoginPluginInfo{
+		Name:       "openid connect login system",
+		Version:    common.BKOIDCLoginPluginVersion,
+		HandleFunc: &user{},
+	}
+	manager.RegisterPlugin(plugin)
+}
+
+// authState is the pending authorization request saved in session until the provider calls back
+type authState struct {
+	State       string `json:"state"`
+	Nonce       string `json:"nonce"`
+	CallbackURL string `json:"callback_url"`
+	RedirectURL string `json:"redirect_url"`
+}
+
+// loginSession is the oidc login of the user saved in session
+type loginSession struct {
+	Token        string   `json:"token"`
+	UserName     string   `json:"username"`
+	ChName       string   `json:"chname"`
+	Email        string   `json:"email"`
+	Phone        string   `json:"phone"`
+	Groups       []string `json:"groups"`
+	IDToken      string   `json:"id_token"`
+	AccessToken  string   `json:"access_token"`
+	RefreshToken string   `json:"refresh_token"`
+	ExpireTime   int64    `json:"expire_time"`
+}
+
+type user struct{}
+
+// LoginUser get the login user from the oidc login session, the tokens are refreshed once they expire.
+func (m *user) LoginUser(c *gin.Context, config map[string]string, isMultiOwner bool) (*metadata.LoginUserInfo, bool) {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	session := sessions.Default(c)
+
+	login := new(loginSession)
+	if !getSessionValue(session, sessionKey, login) {
+		blog.Infof("oidc login session not found, rid: %s", rid)
+		return nil, false
+	}
+	opts, err := parseOptions(config)
+	if err != nil {
+		blog.Errorf("oidc login config is invalid, err: %v, rid: %s", err, rid)
+		return nil, false
+	}
+
+	if time.Now().Unix() >= login.ExpireTime {
+		if err := m.refreshSession(c, opts, login); err != nil {
+			blog.Warnf("refresh oidc login session of user %s failed, err: %v, rid: %s", login.UserName, err, rid)
+			session.Delete(sessionKey)
+			if err := session.Save(); err != nil {
+				blog.Warnf("save session failed, err: %s, rid: %s", err.Error(), rid)
+			}
+			return nil, false
+		}
+		setSessionValue(session, sessionKey, login)
+		blog.V(4).Infof("oidc login session of user %s is refreshed, rid: %s", login.UserName, rid)
+	}
+
+	user := &metadata.LoginUserInfo{
+		UserName:   login.UserName,
+		ChName:     login.ChName,
+		Phone:      login.Phone,
+		Email:      login.Email,
+		BkToken:    login.Token,
+		IsOwner:    false,
+		Language:   webCommon.GetLanguageByHTTPRequest(c),
+		ExpireTime: login.ExpireTime,
+	}
+	currentOwnerID, _ := session.Get(common.WEBSessionOwnerUinKey).(string)
+	opts.Mapping.Apply(user, login.Groups, currentOwnerID)
+	return user, true
+}
+
+// GetLoginUrl returns the url of the provider's authorization endpoint, the authorization request is saved
+// in session to be checked in the callback.
+func (m *user) GetLoginUrl(c *gin.Context, config map[string]string, input *metadata.LogoutRequestParams) string {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	siteURL := identity.SiteURL(config, input.HTTPScheme)
+
+	opts, err := parseOptions(config)
+	if err != nil {
+		blog.Errorf("oidc login config is invalid, err: %v, rid: %s", err, rid)
+		return siteURL
+	}
+	cli, err := newClient(c.Request.Context(), opts)
+	if err != nil {
+		blog.Errorf("create oidc client failed, err: %v, rid: %s", err, rid)
+		return siteURL
+	}
+
+	state := &authState{
+		CallbackURL: siteURL + callbackPath,
+		RedirectURL: siteURL + c.Request.URL.String(),
+	}
+	if state.State, err = identity.NewToken(); err != nil {
+		blog.Errorf("generate oidc state failed, err: %v, rid: %s", err, rid)
+		return siteURL
+	}
+	if state.Nonce, err = identity.NewToken(); err != nil {
+		blog.Errorf("generate oidc nonce failed, err: %v, rid: %s", err, rid)
+		return siteURL
+	}
+
+	session := sessions.Default(c)
+	setSessionValue(session, stateSessionKey, state)
+	if err := session.Save(); err != nil {
+		blog.Errorf("save oidc state to session failed, err: %v, rid: %s", err, rid)
+		return siteURL
+	}
+	return cli.authCodeURL(state.CallbackURL, state.State, state.Nonce)
+}
+
+func (m *user) GetUserList(c *gin.Context, config map[string]string) ([]*metadata.LoginSystemUserInfo, *errors.RawErrorInfo) {
+	// the provider has no standard api to list users, only the current user is returned
+	session := sessions.Default(c)
+	login := new(loginSession)
+	users := make([]*metadata.LoginSystemUserInfo, 0)
+	if getSessionValue(session, sessionKey, login) {
+		users = append(users, &metadata.LoginSystemUserInfo{
+			CnName: login.ChName,
+			EnName: login.UserName,
+		})
+	}
+	return users, nil
+}
+
+// HandleLogin handles the callback of the provider, the authorization code is exchanged for tokens whose
+// claims make up the login session.
+func (m *user) HandleLogin(c *gin.Context, config map[string]string) (string, *errors.RawErrorInfo) {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	session := sessions.Default(c)
+
+	state := new(authState)
+	if !getSessionValue(session, stateSessionKey, state) {
+		blog.Errorf("oidc callback failed, authorization state not found in session, rid: %s", rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginCallbackInvalid}
+	}
+	session.Delete(stateSessionKey)
+
+	if errCode := c.Query("error"); len(errCode) != 0 {
+		blog.Errorf("oidc callback failed, provider returns error %s: %s, rid: %s", errCode,
+			c.Query("error_description"), rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginCallbackInvalid}
+	}
+	if c.Query("state") != state.State || len(c.Query("code")) == 0 {
+		blog.Errorf("oidc callback failed, state not match or code is empty, rid: %s", rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginCallbackInvalid}
+	}
+
+	opts, err := parseOptions(config)
+	if err != nil {
+		blog.Errorf("oidc login config is invalid, err: %v, rid: %s", err, rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginConfigInvalid, Args: []interface{}{"oidc"}}
+	}
+	cli, err := newClient(c.Request.Context(), opts)
+	if err != nil {
+		blog.Errorf("create oidc client failed, err: %v, rid: %s", err, rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginSystemUnavailable}
+	}
+
+	token, err := cli.exchange(c.Request.Context(), c.Query("code"), state.CallbackURL)
+	if err != nil {
+		blog.Errorf("oidc callback failed, exchange code failed, err: %v, rid: %s", err, rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginCallbackInvalid}
+	}
+
+	login := new(loginSession)
+	if err := cli.updateSession(c.Request.Context(), login, token, state.Nonce); err != nil {
+		blog.Errorf("oidc callback failed, err: %v, rid: %s", err, rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginCallbackInvalid}
+	}
+	if login.Token, err = identity.NewToken(); err != nil {
+		blog.Errorf("generate login token failed, err: %v, rid: %s", err, rid)
+		return "", &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginSystemUnavailable}
+	}
+
+	setSessionValue(session, sessionKey, login)
+	c.SetCookie(common.HTTPCookieBKToken, login.Token, 0, "/", "", false, true)
+	blog.Infof("user %s login by oidc, rid: %s", login.UserName, rid)
+	return state.RedirectURL, nil
+}
+
+// Logout returns the url of the provider's end session endpoint, or the login url if the provider doesn't support it
+func (m *user) Logout(c *gin.Context, config map[string]string, input *metadata.LogoutRequestParams) string {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	siteURL := identity.SiteURL(config, input.HTTPScheme)
+
+	login := new(loginSession)
+	if getSessionValue(sessions.Default(c), sessionKey, login) {
+		opts, err := parseOptions(config)
+		if err != nil {
+			blog.Errorf("oidc login config is invalid, err: %v, rid: %s", err, rid)
+			return siteURL
+		}
+		cli, err := newClient(c.Request.Context(), opts)
+		if err != nil {
+			blog.Errorf("create oidc client failed, err: %v, rid: %s", err, rid)
+			return siteURL
+		}
+		if logoutURL := cli.logoutURL(login.IDToken, siteURL); len(logoutURL) != 0 {
+			return logoutURL
+		}
+	}
+	return siteURL
+}
+
+// refreshSession refresh the expired tokens of the login session
+func (m *user) refreshSession(c *gin.Context, opts *options, login *loginSession) error {
+	if len(login.RefreshToken) == 0 {
+		return fmt.Errorf("no refresh token")
+	}
+	cli, err := newClient(c.Request.Context(), opts)
+	if err != nil {
+		return err
+	}
+	token, err := cli.refresh(c.Request.Context(), login.RefreshToken)
+	if err != nil {
+		return err
+	}
+	return cli.updateSession(c.Request.Context(), login, token, "")
+}
+
+func getSessionValue(session sessions.Session, key string, value interface{}) bool {
+	raw, ok := session.Get(key).(string)
+	if !ok || len(raw) == 0 {
+		return false
+	}
+	return json.Unmarshal([]byte(raw), value) == nil
+}
+
+func setSessionValue(session sessions.Session, key string, value interface{}) {
+	raw, _ := json.Marshal(value)
+	session.Set(key, string(raw))
+}
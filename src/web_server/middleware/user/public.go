@@ -83,6 +83,7 @@ func (m *publicUser) LoginUser(c *gin.Context) bool {
 	session.Set(common.WEBSessionAvatarUrlKey, userInfo.AvatarUrl)
 	session.Set(common.WEBSessionOwnerUinListeKey, string(strOwnerUinList))
 	session.Set(common.WEBSessionSupplierID, strconv.FormatInt(userInfo.SupplierID, 10))
+	session.Set(common.WEBSessionExpireTimeKey, userInfo.ExpireTime)
 	if userInfo.MultiSupplier {
 		session.Set(common.WEBSessionMultiSupplierKey, common.LoginSystemMultiSupplierTrue)
 	} else {
@@ -96,7 +97,13 @@ func (m *publicUser) LoginUser(c *gin.Context) bool {
 }
 
 func (m *publicUser) GetLoginUrl(c *gin.Context) string {
+	params := m.getLogoutParams(c)
+	user := plugins.CurrentPlugin(c, m.config.LoginVersion)
+	return user.GetLoginUrl(c, m.config.ConfigMap, params)
+
+}
 
+func (m *publicUser) getLogoutParams(c *gin.Context) *metadata.LogoutRequestParams {
 	params := new(metadata.LogoutRequestParams)
 	err := json.NewDecoder(c.Request.Body).Decode(params)
 	if nil != err || (common.LogoutHTTPSchemeHTTP != params.HTTPScheme && common.LogoutHTTPSchemeHTTPS != params.HTTPScheme) {
@@ -105,10 +112,38 @@ func (m *publicUser) GetLoginUrl(c *gin.Context) string {
 			params.HTTPScheme = common.LogoutHTTPSchemeHTTP
 		}
 	}
+	return params
+}
 
+// HandleLogin let the login plugin authenticate the login request and saves the login user to session
+func (m *publicUser) HandleLogin(c *gin.Context) (string, bool, *errors.RawErrorInfo) {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
 	user := plugins.CurrentPlugin(c, m.config.LoginVersion)
-	return user.GetLoginUrl(c, m.config.ConfigMap, params)
+	handler, ok := user.(metadata.LoginUserHandlerPluginInterface)
+	if !ok {
+		return "", false, nil
+	}
 
+	redirectURL, rawErr := handler.HandleLogin(c, m.config.ConfigMap)
+	if rawErr != nil {
+		return "", true, rawErr
+	}
+	if !m.LoginUser(c) {
+		blog.Errorf("login user failed after the login plugin handled login, rid: %s", rid)
+		return "", true, &errors.RawErrorInfo{ErrCode: common.CCErrWebLoginCallbackInvalid}
+	}
+	return redirectURL, true, nil
+}
+
+// Logout returns the url to redirect to after logout, the login plugin ends the login session in the
+// login system if it supports, the session of web server should be cleared afterwards.
+func (m *publicUser) Logout(c *gin.Context) string {
+	params := m.getLogoutParams(c)
+	user := plugins.CurrentPlugin(c, m.config.LoginVersion)
+	if logout, ok := user.(metadata.LoginUserLogoutPluginInterface); ok {
+		return logout.Logout(c, m.config.ConfigMap, params)
+	}
+	return user.GetLoginUrl(c, m.config.ConfigMap, params)
 }
 
 func (m *publicUser) GetUserList(c *gin.Context) ([]*metadata.LoginSystemUserInfo, *errors.RawErrorInfo) {
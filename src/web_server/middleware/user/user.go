@@ -30,6 +30,10 @@ type LoginInterface interface {
 	GetLoginUrl(c *gin.Context) string
 	// 获取不同登录方式下对应的用户列表
 	GetUserList(c *gin.Context) ([]*metadata.LoginSystemUserInfo, *errors.RawErrorInfo)
+	// 由登录插件处理登录请求, 插件不处理登录请求时handled为false
+	HandleLogin(c *gin.Context) (redirectURL string, handled bool, err *errors.RawErrorInfo)
+	// 注销登录, 返回注销后跳转的URL
+	Logout(c *gin.Context) string
 }
 
 // NewUser return user instance by type
@@ -123,6 +123,12 @@ func (s *Service) ExportHost(c *gin.Context) {
 		_, _ = c.Writer.Write([]byte(reply))
 		return
 	}
+	if err := s.Logics.FilterExportFields(ctx, objID, fields, header); err != nil {
+		blog.Errorf("ExportHost failed, filter host fields by permission failed, err: %+v, rid: %s", err, rid)
+		reply := getReturnStr(common.CCErrCommExcelTemplateFailed, defErr.Errorf(common.CCErrCommExcelTemplateFailed, objID).Error(), nil)
+		_, _ = c.Writer.Write([]byte(reply))
+		return
+	}
 	err = s.Logics.BuildHostExcelFromData(context.Background(), objID, fields, nil, hostInfo, file, header, &metadata.Metadata{})
 	if nil != err {
 		blog.Errorf("ExportHost failed, BuildHostExcelFromData failed, object:%s, err:%+v, rid:%s", objID, err, rid)
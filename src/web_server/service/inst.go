@@ -139,6 +139,12 @@ func (s *Service) ExportInst(c *gin.Context) {
 		c.Writer.Write([]byte(reply))
 		return
 	}
+	if err := s.Logics.FilterExportFields(ctx, objID, fields, pheader); err != nil {
+		blog.Errorf("export object instance, but filter object:%s fields by permission failed, err: %v, rid: %s", objID, err, rid)
+		reply := getReturnStr(common.CCErrCommExcelTemplateFailed, defErr.Errorf(common.CCErrCommExcelTemplateFailed, objID).Error(), nil)
+		c.Writer.Write([]byte(reply))
+		return
+	}
 
 	err = s.Logics.BuildExcelFromData(ctx, objID, fields, nil, instInfo, file, pheader, metaInfo)
 	if nil != err {
@@ -18,6 +18,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	"configcenter/src/web_server/middleware/user"
@@ -28,11 +29,16 @@ import (
 
 // LogOutUser log out user
 func (s *Service) LogOutUser(c *gin.Context) {
-	session := sessions.Default(c)
-	session.Clear()
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
 	c.Request.URL.Path = ""
 	userManger := user.NewUser(*s.Config, s.Engine, s.CacheCli)
-	loginURL := userManger.GetLoginUrl(c)
+	// the login plugin reads the login session to end it in the login system
+	loginURL := userManger.Logout(c)
+	session := sessions.Default(c)
+	session.Clear()
+	if err := session.Save(); err != nil {
+		blog.Warnf("save session failed, err: %s, rid: %s", err.Error(), rid)
+	}
 	ret := metadata.LogoutResult{}
 	ret.BaseResp.Result = true
 	ret.Data.LogoutURL = loginURL
@@ -49,6 +55,12 @@ func (s *Service) Login(c *gin.Context) {
 func (s *Service) LoginUser(c *gin.Context) {
 	rid := util.GetHTTPCCRequestID(c.Request.Header)
 	defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(c.Request.Header))
+	userManger := user.NewUser(*s.Config, s.Engine, s.CacheCli)
+	if redirectURL, handled, rawErr := userManger.HandleLogin(c); handled {
+		s.loginRedirect(c, redirectURL, rawErr)
+		return
+	}
+
 	userName := c.PostForm("username")
 	password := c.PostForm("password")
 	if userName == "" || password == "" {
@@ -80,7 +92,6 @@ func (s *Service) LoginUser(c *gin.Context) {
 			if err := session.Save(); err != nil {
 				blog.Warnf("save session failed, err: %s, rid: %s", err.Error(), rid)
 			}
-			userManger := user.NewUser(*s.Config, s.Engine, s.CacheCli)
 			userManger.LoginUser(c)
 			var redirectURL string
 			if c.Param("c_url") != "" {
@@ -97,3 +108,32 @@ func (s *Service) LoginUser(c *gin.Context) {
 	})
 	return
 }
+
+// LoginCallback handles the callback of the login system after users login, such as an oidc provider
+func (s *Service) LoginCallback(c *gin.Context) {
+	userManger := user.NewUser(*s.Config, s.Engine, s.CacheCli)
+	redirectURL, handled, rawErr := userManger.HandleLogin(c)
+	if !handled {
+		c.Redirect(302, s.Config.Site.DomainUrl)
+		return
+	}
+	s.loginRedirect(c, redirectURL, rawErr)
+}
+
+// loginRedirect redirects users to the url after the login plugin handled login, the login page with the error
+// message is shown if login failed.
+func (s *Service) loginRedirect(c *gin.Context, redirectURL string, rawErr *errors.RawErrorInfo) {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	if rawErr != nil && rawErr.ErrCode != 0 {
+		defErr := s.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(c.Request.Header))
+		blog.Errorf("login user failed, err: %s, rid: %s", rawErr.ToCCError(defErr).Error(), rid)
+		c.HTML(200, "login.html", gin.H{
+			"error": rawErr.ToCCError(defErr).Error(),
+		})
+		return
+	}
+	if redirectURL == "" {
+		redirectURL = s.Config.Site.DomainUrl
+	}
+	c.Redirect(302, redirectURL)
+}